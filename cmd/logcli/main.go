@@ -1,6 +1,8 @@
 package main
 
 import (
+	"fmt"
+	"io"
 	"log"
 	"math"
 	"net/url"
@@ -18,6 +20,7 @@ import (
 	"github.com/grafana/loki/pkg/logcli/output"
 	"github.com/grafana/loki/pkg/logcli/query"
 	"github.com/grafana/loki/pkg/logcli/seriesquery"
+	"github.com/grafana/loki/pkg/logql"
 	_ "github.com/grafana/loki/pkg/util/build"
 )
 
@@ -109,6 +112,13 @@ Use the --analyze-labels flag to get a summary of the labels found in all stream
 This is helpful to find high cardinality labels.
 `)
 	seriesQuery = newSeriesQuery(seriesCmd)
+
+	fmtCmd = app.Command("fmt", `Format a LogQL query.
+
+The "fmt" command pretty-prints the query given as argument, or read from
+stdin when there's none, keeping its comments. Warnings about common
+mistakes in the query are written to stderr.`)
+	fmtQuery = fmtCmd.Arg("query", "eg '{foo=\"bar\",baz=~\".*blip\"} |~ \".*error.*\"'").String()
 )
 
 func main() {
@@ -213,7 +223,36 @@ func main() {
 		labelsQuery.DoLabels(queryClient)
 	case seriesCmd.FullCommand():
 		seriesQuery.DoSeries(queryClient)
+	case fmtCmd.FullCommand():
+		if err := formatQuery(*fmtQuery, os.Stdin, os.Stdout, os.Stderr); err != nil {
+			log.Fatalf("Unable to format query: %s", err)
+		}
+	}
+}
+
+// formatQuery writes the formatted query, read from in when empty, and the warnings of the linter about it.
+func formatQuery(query string, in io.Reader, out, warnings io.Writer) error {
+	if query == "" {
+		b, err := io.ReadAll(in)
+		if err != nil {
+			return err
+		}
+		query = string(b)
+	}
+
+	formatted, err := logql.Format(query)
+	if err != nil {
+		return err
+	}
+	lint, err := logql.Lint(query)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(out, formatted)
+	for _, w := range lint {
+		fmt.Fprintf(warnings, "warning: %s\n", w)
 	}
+	return nil
 }
 
 func newQueryClient(app *kingpin.Application) client.Client {
@@ -20,6 +20,11 @@ func (e *encbuf) putByte(c byte) { e.b = append(e.b, c) }
 func (e *encbuf) putBE64int(x int) { e.putBE64(uint64(x)) }
 func (e *encbuf) putUvarint(x int) { e.putUvarint64(uint64(x)) }
 
+func (e *encbuf) putUvarintStr(s string) {
+	e.putUvarint(len(s))
+	e.b = append(e.b, s...)
+}
+
 func (e *encbuf) putBE32(x uint32) {
 	binary.BigEndian.PutUint32(e.c[:], x)
 	e.b = append(e.b, e.c[:4]...)
@@ -31,6 +31,7 @@ const (
 	chunkFormatV1
 	chunkFormatV2
 	chunkFormatV3
+	chunkFormatV4 // adds the structured metadata of the entries to the blocks.
 
 	DefaultChunkFormat = chunkFormatV3 // the currently used chunk format
 
@@ -43,7 +44,7 @@ const (
 	defaultBlockSize = 256 * 1024
 )
 
-var HeadBlockFmts = []HeadBlockFmt{OrderedHeadBlockFmt, UnorderedHeadBlockFmt}
+var HeadBlockFmts = []HeadBlockFmt{OrderedHeadBlockFmt, UnorderedHeadBlockFmt, UnorderedWithStructuredMetadataHeadBlockFmt}
 
 type HeadBlockFmt byte
 
@@ -55,6 +56,8 @@ func (f HeadBlockFmt) String() string {
 		return "ordered"
 	case f == UnorderedHeadBlockFmt:
 		return "unordered"
+	case f == UnorderedWithStructuredMetadataHeadBlockFmt:
+		return "unordered with structured metadata"
 	default:
 		return fmt.Sprintf("unknown: %v", byte(f))
 	}
@@ -65,10 +68,19 @@ func (f HeadBlockFmt) NewBlock() HeadBlock {
 	case f < UnorderedHeadBlockFmt:
 		return &headBlock{}
 	default:
-		return newUnorderedHeadBlock()
+		return newUnorderedHeadBlock(f)
 	}
 }
 
+// ChunkFormat returns the format of the chunks whose head block has this format.
+// Only the chunks keeping the structured metadata of the entries need the V4 format.
+func (f HeadBlockFmt) ChunkFormat() byte {
+	if f >= UnorderedWithStructuredMetadataHeadBlockFmt {
+		return chunkFormatV4
+	}
+	return DefaultChunkFormat
+}
+
 const (
 	_ HeadBlockFmt = iota
 	// placeholders to start splitting chunk formats vs head block
@@ -77,6 +89,7 @@ const (
 	_
 	OrderedHeadBlockFmt
 	UnorderedHeadBlockFmt
+	UnorderedWithStructuredMetadataHeadBlockFmt
 )
 
 var magicNumber = uint32(0x12EE56A)
@@ -159,7 +172,9 @@ func (hb *headBlock) Reset() {
 
 func (hb *headBlock) Bounds() (int64, int64) { return hb.mint, hb.maxt }
 
-func (hb *headBlock) Append(ts int64, line string) error {
+// Append appends an entry to the head block.
+// The ordered head block doesn't keep the structured metadata of the entries.
+func (hb *headBlock) Append(ts int64, line string, _ labels.Labels) error {
 	if !hb.IsEmpty() && hb.maxt > ts {
 		return ErrOutOfOrder
 	}
@@ -174,35 +189,14 @@ func (hb *headBlock) Append(ts int64, line string) error {
 	return nil
 }
 
-func (hb *headBlock) Serialise(pool WriterPool) ([]byte, error) {
-	inBuf := serializeBytesBufferPool.Get().(*bytes.Buffer)
-	defer func() {
-		inBuf.Reset()
-		serializeBytesBufferPool.Put(inBuf)
-	}()
-	outBuf := &bytes.Buffer{}
+func (hb *headBlock) Serialise(pool WriterPool, format byte) ([]byte, error) {
+	enc := newBlockEncoder(format)
+	defer enc.release()
 
-	encBuf := make([]byte, binary.MaxVarintLen64)
-	compressedWriter := pool.GetWriter(outBuf)
-	defer pool.PutWriter(compressedWriter)
 	for _, logEntry := range hb.entries {
-		n := binary.PutVarint(encBuf, logEntry.t)
-		inBuf.Write(encBuf[:n])
-
-		n = binary.PutUvarint(encBuf, uint64(len(logEntry.s)))
-		inBuf.Write(encBuf[:n])
-
-		inBuf.WriteString(logEntry.s)
-	}
-
-	if _, err := compressedWriter.Write(inBuf.Bytes()); err != nil {
-		return nil, errors.Wrap(err, "appending entry")
-	}
-	if err := compressedWriter.Close(); err != nil {
-		return nil, errors.Wrap(err, "flushing pending compress buffer")
+		enc.add(logEntry.t, logEntry.s, nil)
 	}
-
-	return outBuf.Bytes(), nil
+	return enc.compress(pool)
 }
 
 // CheckpointBytes serializes a headblock to []byte. This is used by the WAL checkpointing,
@@ -315,10 +309,10 @@ func (hb *headBlock) Convert(version HeadBlockFmt) (HeadBlock, error) {
 	if version < UnorderedHeadBlockFmt {
 		return hb, nil
 	}
-	out := newUnorderedHeadBlock()
+	out := version.NewBlock()
 
 	for _, e := range hb.entries {
-		if err := out.Append(e.t, e.s); err != nil {
+		if err := out.Append(e.t, e.s, nil); err != nil {
 			return nil, err
 		}
 	}
@@ -337,7 +331,7 @@ func NewMemChunk(enc Encoding, head HeadBlockFmt, blockSize, targetSize int) *Me
 		targetSize: targetSize, // Desired chunk size in compressed bytes
 		blocks:     []block{},
 
-		format: DefaultChunkFormat,
+		format: head.ChunkFormat(),
 		head:   head.NewBlock(),
 
 		encoding: enc,
@@ -366,7 +360,7 @@ func NewByteChunk(b []byte, blockSize, targetSize int) (*MemChunk, error) {
 	switch version {
 	case chunkFormatV1:
 		bc.encoding = EncGZIP
-	case chunkFormatV2, chunkFormatV3:
+	case chunkFormatV2, chunkFormatV3, chunkFormatV4:
 		// format v2+ has a byte for block encoding.
 		enc := Encoding(db.byte())
 		if db.err() != nil {
@@ -376,6 +370,11 @@ func NewByteChunk(b []byte, blockSize, targetSize int) (*MemChunk, error) {
 	default:
 		return nil, errors.Errorf("invalid version %d", version)
 	}
+	if version >= chunkFormatV4 {
+		// Appending to a V4 chunk must keep the structured metadata of the entries.
+		bc.headFmt = UnorderedWithStructuredMetadataHeadBlockFmt
+		bc.head = bc.headFmt.NewBlock()
+	}
 
 	metasOffset := binary.BigEndian.Uint64(b[len(b)-8:])
 	mb := b[metasOffset : len(b)-(8+4)] // storing the metasOffset + checksum of meta
@@ -401,7 +400,7 @@ func NewByteChunk(b []byte, blockSize, targetSize int) (*MemChunk, error) {
 
 		// Read offset and length.
 		blk.offset = db.uvarint()
-		if version == chunkFormatV3 {
+		if version >= chunkFormatV3 {
 			blk.uncompressedSize = db.uvarint()
 		}
 		l := db.uvarint()
@@ -460,7 +459,7 @@ func (c *MemChunk) BytesSize() int {
 		size += binary.MaxVarintLen64 // mint
 		size += binary.MaxVarintLen64 // maxt
 		size += binary.MaxVarintLen32 // offset
-		if c.format == chunkFormatV3 {
+		if c.format >= chunkFormatV3 {
 			size += binary.MaxVarintLen32 // uncompressed size
 		}
 		size += binary.MaxVarintLen32 // len(b)
@@ -534,7 +533,7 @@ func (c *MemChunk) WriteTo(w io.Writer) (int64, error) {
 		eb.putVarint64(b.mint)
 		eb.putVarint64(b.maxt)
 		eb.putUvarint(b.offset)
-		if c.format == chunkFormatV3 {
+		if c.format >= chunkFormatV3 {
 			eb.putUvarint(b.uncompressedSize)
 		}
 		eb.putUvarint(len(b.b))
@@ -595,8 +594,7 @@ func MemchunkFromCheckpoint(chk, head []byte, desired HeadBlockFmt, blockSize in
 	}
 
 	mc.head = h
-	mc.headFmt = desired
-	return mc, nil
+	return mc, mc.ConvertHead(h.Format())
 }
 
 // Encoding implements Chunk.
@@ -616,6 +614,11 @@ func (c *MemChunk) Size() int {
 	return ne
 }
 
+// HeadFormat returns the format of the chunk's head block.
+func (c *MemChunk) HeadFormat() HeadBlockFmt {
+	return c.headFmt
+}
+
 // BlockCount implements Chunk.
 func (c *MemChunk) BlockCount() int {
 	return len(c.blocks)
@@ -626,7 +629,7 @@ func (c *MemChunk) SpaceFor(e *logproto.Entry) bool {
 	if c.targetSize > 0 {
 		// This is looking to see if the uncompressed lines will fit which is not
 		// a great check, but it will guarantee we are always under the target size
-		newHBSize := c.head.UncompressedSize() + len(e.Line)
+		newHBSize := c.head.UncompressedSize() + len(e.Line) + structuredMetadataSize(e.StructuredMetadata)
 		return (c.cutBlockSize + newHBSize) < c.targetSize
 	}
 	// if targetSize is not defined, default to the original behavior of fixed blocks per chunk
@@ -674,7 +677,7 @@ func (c *MemChunk) Append(entry *logproto.Entry) error {
 		return ErrOutOfOrder
 	}
 
-	if err := c.head.Append(entryTimestamp, entry.Line); err != nil {
+	if err := c.head.Append(entryTimestamp, entry.Line, entry.StructuredMetadata); err != nil {
 		return err
 	}
 
@@ -722,6 +725,7 @@ func (c *MemChunk) reorder() error {
 }
 
 func (c *MemChunk) ConvertHead(desired HeadBlockFmt) error {
+	desired = c.supportedHeadFmt(desired)
 	if c.head != nil && c.head.Format() != desired {
 		newH, err := c.head.Convert(desired)
 		if err != nil {
@@ -734,13 +738,27 @@ func (c *MemChunk) ConvertHead(desired HeadBlockFmt) error {
 	return nil
 }
 
+// supportedHeadFmt returns the head block format closest to the desired one that the chunk can hold.
+// Chunks without any block are upgraded to the V4 format to keep the structured metadata of the entries,
+// while the others fall back to a head block without structured metadata.
+func (c *MemChunk) supportedHeadFmt(desired HeadBlockFmt) HeadBlockFmt {
+	if desired < UnorderedWithStructuredMetadataHeadBlockFmt || c.format >= chunkFormatV4 {
+		return desired
+	}
+	if len(c.blocks) == 0 {
+		c.format = chunkFormatV4
+		return desired
+	}
+	return UnorderedHeadBlockFmt
+}
+
 // cut a new block and add it to finished blocks.
 func (c *MemChunk) cut() error {
 	if c.head.IsEmpty() {
 		return nil
 	}
 
-	b, err := c.head.Serialise(getWriterPool(c.encoding))
+	b, err := c.head.Serialise(getWriterPool(c.encoding), c.format)
 	if err != nil {
 		return err
 	}
@@ -797,7 +815,7 @@ func (c *MemChunk) Iterator(ctx context.Context, mintT, maxtT time.Time, directi
 		}
 		lastMax = b.maxt
 
-		blockItrs = append(blockItrs, encBlock{c.encoding, b}.Iterator(ctx, pipeline))
+		blockItrs = append(blockItrs, encBlock{c.encoding, c.format, b}.Iterator(ctx, pipeline))
 	}
 
 	if !c.head.IsEmpty() {
@@ -871,7 +889,7 @@ func (c *MemChunk) SampleIterator(ctx context.Context, from, through time.Time,
 			ordered = false
 		}
 		lastMax = b.maxt
-		its = append(its, encBlock{c.encoding, b}.SampleIterator(ctx, extractor))
+		its = append(its, encBlock{c.encoding, c.format, b}.SampleIterator(ctx, extractor))
 	}
 
 	if !c.head.IsEmpty() {
@@ -903,7 +921,7 @@ func (c *MemChunk) Blocks(mintT, maxtT time.Time) []Block {
 
 	for _, b := range c.blocks {
 		if maxt >= b.mint && b.maxt >= mint {
-			blocks = append(blocks, encBlock{c.encoding, b})
+			blocks = append(blocks, encBlock{c.encoding, c.format, b})
 		}
 	}
 	return blocks
@@ -952,7 +970,8 @@ func (c *MemChunk) Rebound(start, end time.Time) (Chunk, error) {
 // then allows us to bind a decoding context to a block when requested, but otherwise helps reduce the
 // chances of chunk<>block encoding drift in the codebase as the latter is parameterized by the former.
 type encBlock struct {
-	enc Encoding
+	enc    Encoding
+	format byte
 	block
 }
 
@@ -960,14 +979,14 @@ func (b encBlock) Iterator(ctx context.Context, pipeline log.StreamPipeline) ite
 	if len(b.b) == 0 {
 		return iter.NoopIterator
 	}
-	return newEntryIterator(ctx, getReaderPool(b.enc), b.b, pipeline)
+	return newEntryIterator(ctx, getReaderPool(b.enc), b.b, b.format, pipeline)
 }
 
 func (b encBlock) SampleIterator(ctx context.Context, extractor log.StreamSampleExtractor) iter.SampleIterator {
 	if len(b.b) == 0 {
 		return iter.NoopIterator
 	}
-	return newSampleIterator(ctx, getReaderPool(b.enc), b.b, extractor)
+	return newSampleIterator(ctx, getReaderPool(b.enc), b.b, b.format, extractor)
 }
 
 func (b block) Offset() int {
@@ -1006,7 +1025,7 @@ func (hb *headBlock) Iterator(ctx context.Context, direction logproto.Direction,
 			return
 		}
 		stats.AddHeadChunkBytes(int64(len(e.s)))
-		newLine, parsedLbs, ok := pipeline.ProcessString(e.s)
+		newLine, parsedLbs, ok := pipeline.ProcessString(e.t, e.s)
 		if !ok {
 			return
 		}
@@ -1053,7 +1072,7 @@ func (hb *headBlock) SampleIterator(ctx context.Context, mint, maxt int64, extra
 	series := map[uint64]*logproto.Series{}
 	for _, e := range hb.entries {
 		stats.AddHeadChunkBytes(int64(len(e.s)))
-		value, parsedLabels, ok := extractor.ProcessString(e.s)
+		value, parsedLabels, ok := extractor.ProcessString(e.t, e.s)
 		if !ok {
 			continue
 		}
@@ -1108,14 +1127,18 @@ type bufferedIterator struct {
 
 	err error
 
-	buf      []byte // The buffer for a single entry.
-	currLine []byte // the current line, this is the same as the buffer but sliced the the line size.
-	currTs   int64
+	format  byte
+	symbols []string // the symbols of the structured metadata of a V4 block.
+
+	buf                    []byte // The buffer for a single entry.
+	currLine               []byte // the current line, this is the same as the buffer but sliced the the line size.
+	currTs                 int64
+	currStructuredMetadata labels.Labels
 
 	closed bool
 }
 
-func newBufferedIterator(ctx context.Context, pool ReaderPool, b []byte) *bufferedIterator {
+func newBufferedIterator(ctx context.Context, pool ReaderPool, b []byte, format byte) *bufferedIterator {
 	stats := stats.FromContext(ctx)
 	stats.AddCompressedBytes(int64(len(b)))
 	return &bufferedIterator{
@@ -1124,6 +1147,7 @@ func newBufferedIterator(ctx context.Context, pool ReaderPool, b []byte) *buffer
 		reader:    nil, // will be initialized later
 		bufReader: nil, // will be initialized later
 		pool:      pool,
+		format:    format,
 	}
 }
 
@@ -1136,44 +1160,120 @@ func (si *bufferedIterator) Next() bool {
 		// initialize reader now, hopefully reusing one of the previous readers
 		si.reader = si.pool.GetReader(bytes.NewBuffer(si.origBytes))
 		si.bufReader = BufReaderPool.Get(si.reader)
+
+		if si.format >= chunkFormatV4 {
+			if err := si.readSymbols(); err != nil {
+				si.err = err
+				si.Close()
+				return false
+			}
+		}
+	}
+
+	// Stop processing once the query went over its bytes budget.
+	if err := si.stats.BytesLimitErr(); err != nil {
+		si.err = err
+		si.Close()
+		return false
 	}
 
-	ts, line, ok := si.moveNext()
+	ts, line, structuredMetadata, ok := si.moveNext()
 	if !ok {
 		si.Close()
 		return false
 	}
 	// we decode always the line length and ts as varint
-	si.stats.AddDecompressedBytes(int64(len(line)) + 2*binary.MaxVarintLen64)
+	si.stats.AddDecompressedBytes(int64(len(line)+structuredMetadataSize(structuredMetadata)) + 2*binary.MaxVarintLen64)
 	si.stats.AddDecompressedLines(1)
 
 	si.currTs = ts
 	si.currLine = line
+	si.currStructuredMetadata = structuredMetadata
 	return true
 }
 
+// readSymbols reads the symbol table written at the beginning of V4 blocks.
+func (si *bufferedIterator) readSymbols() error {
+	n, err := binary.ReadUvarint(si.bufReader)
+	if err != nil {
+		return errors.Wrap(err, "reading symbols count")
+	}
+	si.symbols = make([]string, 0, n)
+	for i := uint64(0); i < n; i++ {
+		l, err := binary.ReadUvarint(si.bufReader)
+		if err != nil {
+			return errors.Wrap(err, "reading symbol length")
+		}
+		if l >= maxLineLength {
+			return fmt.Errorf("symbol too long %d, maximum %d", l, maxLineLength)
+		}
+		symbol := make([]byte, l)
+		if _, err := io.ReadFull(si.bufReader, symbol); err != nil {
+			return errors.Wrap(err, "reading symbol")
+		}
+		si.symbols = append(si.symbols, string(symbol))
+	}
+	return nil
+}
+
+// readStructuredMetadata reads the symbol references of the structured metadata of an entry of a V4 block.
+func (si *bufferedIterator) readStructuredMetadata() (labels.Labels, error) {
+	n, err := binary.ReadUvarint(si.bufReader)
+	if err != nil {
+		return nil, err
+	}
+	if n == 0 {
+		return nil, nil
+	}
+	// A new slice is allocated for every entry since the entries are handed over to the callers.
+	structuredMetadata := make(labels.Labels, 0, n)
+	for i := uint64(0); i < n; i++ {
+		name, err := si.readSymbol()
+		if err != nil {
+			return nil, err
+		}
+		value, err := si.readSymbol()
+		if err != nil {
+			return nil, err
+		}
+		structuredMetadata = append(structuredMetadata, labels.Label{Name: name, Value: value})
+	}
+	return structuredMetadata, nil
+}
+
+func (si *bufferedIterator) readSymbol() (string, error) {
+	ref, err := binary.ReadUvarint(si.bufReader)
+	if err != nil {
+		return "", err
+	}
+	if ref >= uint64(len(si.symbols)) {
+		return "", fmt.Errorf("invalid symbol reference %d, %d symbols in the block", ref, len(si.symbols))
+	}
+	return si.symbols[ref], nil
+}
+
 // moveNext moves the buffer to the next entry
-func (si *bufferedIterator) moveNext() (int64, []byte, bool) {
+func (si *bufferedIterator) moveNext() (int64, []byte, labels.Labels, bool) {
 	ts, err := binary.ReadVarint(si.bufReader)
 	if err != nil {
 		if err != io.EOF {
 			si.err = err
 		}
-		return 0, nil, false
+		return 0, nil, nil, false
 	}
 
 	l, err := binary.ReadUvarint(si.bufReader)
 	if err != nil {
 		if err != io.EOF {
 			si.err = err
-			return 0, nil, false
+			return 0, nil, nil, false
 		}
 	}
 	lineSize := int(l)
 
 	if lineSize >= maxLineLength {
 		si.err = fmt.Errorf("line too long %d, maximum %d", lineSize, maxLineLength)
-		return 0, nil, false
+		return 0, nil, nil, false
 	}
 	// If the buffer is not yet initialize or too small, we get a new one.
 	if si.buf == nil || lineSize > cap(si.buf) {
@@ -1184,24 +1284,33 @@ func (si *bufferedIterator) moveNext() (int64, []byte, bool) {
 		si.buf = BytesBufferPool.Get(lineSize).([]byte)
 		if lineSize > cap(si.buf) {
 			si.err = fmt.Errorf("could not get a line buffer of size %d, actual %d", lineSize, cap(si.buf))
-			return 0, nil, false
+			return 0, nil, nil, false
 		}
 	}
 	// Then process reading the line.
 	n, err := si.bufReader.Read(si.buf[:lineSize])
 	if err != nil && err != io.EOF {
 		si.err = err
-		return 0, nil, false
+		return 0, nil, nil, false
 	}
 	for n < lineSize {
 		r, err := si.bufReader.Read(si.buf[n:lineSize])
 		if err != nil && err != io.EOF {
 			si.err = err
-			return 0, nil, false
+			return 0, nil, nil, false
 		}
 		n += r
 	}
-	return ts, si.buf[:lineSize], true
+
+	if si.format < chunkFormatV4 {
+		return ts, si.buf[:lineSize], nil, true
+	}
+	structuredMetadata, err := si.readStructuredMetadata()
+	if err != nil {
+		si.err = errors.Wrap(err, "reading structured metadata")
+		return 0, nil, nil, false
+	}
+	return ts, si.buf[:lineSize], structuredMetadata, true
 }
 
 func (si *bufferedIterator) Error() error { return si.err }
@@ -1229,11 +1338,12 @@ func (si *bufferedIterator) close() {
 		si.buf = nil
 	}
 	si.origBytes = nil
+	si.symbols = nil
 }
 
-func newEntryIterator(ctx context.Context, pool ReaderPool, b []byte, pipeline log.StreamPipeline) iter.EntryIterator {
+func newEntryIterator(ctx context.Context, pool ReaderPool, b []byte, format byte, pipeline log.StreamPipeline) iter.EntryIterator {
 	return &entryBufferedIterator{
-		bufferedIterator: newBufferedIterator(ctx, pool, b),
+		bufferedIterator: newBufferedIterator(ctx, pool, b, format),
 		pipeline:         pipeline,
 	}
 }
@@ -1254,21 +1364,22 @@ func (e *entryBufferedIterator) Labels() string { return e.currLabels.String() }
 
 func (e *entryBufferedIterator) Next() bool {
 	for e.bufferedIterator.Next() {
-		newLine, lbs, ok := e.pipeline.Process(e.currLine)
+		newLine, lbs, ok := e.pipeline.Process(e.currTs, e.currLine, e.currStructuredMetadata...)
 		if !ok {
 			continue
 		}
 		e.cur.Timestamp = time.Unix(0, e.currTs)
 		e.cur.Line = string(newLine)
+		e.cur.StructuredMetadata = e.currStructuredMetadata
 		e.currLabels = lbs
 		return true
 	}
 	return false
 }
 
-func newSampleIterator(ctx context.Context, pool ReaderPool, b []byte, extractor log.StreamSampleExtractor) iter.SampleIterator {
+func newSampleIterator(ctx context.Context, pool ReaderPool, b []byte, format byte, extractor log.StreamSampleExtractor) iter.SampleIterator {
 	it := &sampleBufferedIterator{
-		bufferedIterator: newBufferedIterator(ctx, pool, b),
+		bufferedIterator: newBufferedIterator(ctx, pool, b, format),
 		extractor:        extractor,
 	}
 	return it
@@ -1285,7 +1396,7 @@ type sampleBufferedIterator struct {
 
 func (e *sampleBufferedIterator) Next() bool {
 	for e.bufferedIterator.Next() {
-		val, labels, ok := e.extractor.Process(e.currLine)
+		val, labels, ok := e.extractor.Process(e.currTs, e.currLine, e.currStructuredMetadata...)
 		if !ok {
 			continue
 		}
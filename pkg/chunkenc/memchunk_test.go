@@ -677,8 +677,10 @@ func BenchmarkWrite(b *testing.B) {
 
 type nomatchPipeline struct{}
 
-func (nomatchPipeline) Process(line []byte) ([]byte, log.LabelsResult, bool) { return line, nil, false }
-func (nomatchPipeline) ProcessString(line string) (string, log.LabelsResult, bool) {
+func (nomatchPipeline) Process(_ int64, line []byte, _ ...labels.Label) ([]byte, log.LabelsResult, bool) {
+	return line, nil, false
+}
+func (nomatchPipeline) ProcessString(_ int64, line string, _ ...labels.Label) (string, log.LabelsResult, bool) {
 	return line, nil, false
 }
 
@@ -807,7 +809,7 @@ func BenchmarkHeadBlockIterator(b *testing.B) {
 			h := headBlock{}
 
 			for i := 0; i < j; i++ {
-				if err := h.Append(int64(i), "this is the append string"); err != nil {
+				if err := h.Append(int64(i), "this is the append string", nil); err != nil {
 					b.Fatal(err)
 				}
 			}
@@ -831,7 +833,7 @@ func BenchmarkHeadBlockSampleIterator(b *testing.B) {
 			h := headBlock{}
 
 			for i := 0; i < j; i++ {
-				if err := h.Append(int64(i), "this is the append string"); err != nil {
+				if err := h.Append(int64(i), "this is the append string", nil); err != nil {
 					b.Fatal(err)
 				}
 			}
@@ -1229,3 +1231,24 @@ func buildTestMemChunk(t *testing.T, from, through time.Time) *MemChunk {
 
 	return chk
 }
+
+func TestMemChunk_BytesLimit(t *testing.T) {
+	c := NewMemChunk(EncSnappy, DefaultHeadBlockFmt, testBlockSize, testTargetSize)
+	for i := 0; i < 100; i++ {
+		require.NoError(t, c.Append(&logproto.Entry{Timestamp: time.Unix(0, int64(i)), Line: "a line which is processed"}))
+	}
+	require.NoError(t, c.cut())
+
+	statsCtx, ctx := stats.NewContext(context.Background())
+	statsCtx.SetBytesLimit(500)
+
+	it, err := c.Iterator(ctx, time.Unix(0, 0), time.Unix(0, math.MaxInt64), logproto.FORWARD, noopStreamPipeline)
+	require.NoError(t, err)
+	n := 0
+	for it.Next() {
+		n++
+	}
+	require.ErrorIs(t, it.Error(), stats.ErrBytesLimit)
+	require.Less(t, n, 100)
+	require.NoError(t, it.Close())
+}
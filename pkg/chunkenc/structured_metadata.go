@@ -0,0 +1,118 @@
+package chunkenc
+
+import (
+	"bytes"
+	"encoding/binary"
+
+	"github.com/pkg/errors"
+	"github.com/prometheus/prometheus/model/labels"
+)
+
+// blockEncoder serializes the entries of a head block into a compressed block of the given chunk format.
+//
+// V4 blocks start with a symbol table holding the names and values of the structured metadata of the entries,
+// each entry then referencing its structured metadata by their index in the table:
+//
+//	| #symbols | len(symbol) | symbol | ... | ts | len(line) | line | #structured metadata | name ref | value ref | ... |
+type blockEncoder struct {
+	format byte
+
+	entries *bytes.Buffer
+	encBuf  []byte
+
+	symbols   []string
+	symbolRef map[string]uint64
+}
+
+func newBlockEncoder(format byte) *blockEncoder {
+	return &blockEncoder{
+		format:  format,
+		entries: serializeBytesBufferPool.Get().(*bytes.Buffer),
+		encBuf:  make([]byte, binary.MaxVarintLen64),
+	}
+}
+
+func (e *blockEncoder) putVarint(v int64) {
+	n := binary.PutVarint(e.encBuf, v)
+	e.entries.Write(e.encBuf[:n])
+}
+
+func (e *blockEncoder) putUvarint(v uint64) {
+	n := binary.PutUvarint(e.encBuf, v)
+	e.entries.Write(e.encBuf[:n])
+}
+
+// add adds an entry to the block. The structured metadata is dropped by the formats before V4.
+func (e *blockEncoder) add(ts int64, line string, structuredMetadata labels.Labels) {
+	e.putVarint(ts)
+	e.putUvarint(uint64(len(line)))
+	e.entries.WriteString(line)
+
+	if e.format < chunkFormatV4 {
+		return
+	}
+	e.putUvarint(uint64(len(structuredMetadata)))
+	for _, l := range structuredMetadata {
+		e.putUvarint(e.symbol(l.Name))
+		e.putUvarint(e.symbol(l.Value))
+	}
+}
+
+// symbol returns the reference of a string in the symbol table, adding it if needed.
+func (e *blockEncoder) symbol(s string) uint64 {
+	if e.symbolRef == nil {
+		e.symbolRef = map[string]uint64{}
+	}
+	ref, ok := e.symbolRef[s]
+	if !ok {
+		ref = uint64(len(e.symbols))
+		e.symbols = append(e.symbols, s)
+		e.symbolRef[s] = ref
+	}
+	return ref
+}
+
+// compress returns the compressed block of the added entries.
+func (e *blockEncoder) compress(pool WriterPool) ([]byte, error) {
+	outBuf := &bytes.Buffer{}
+	compressedWriter := pool.GetWriter(outBuf)
+	defer pool.PutWriter(compressedWriter)
+
+	if e.format >= chunkFormatV4 {
+		symbols := &bytes.Buffer{}
+		n := binary.PutUvarint(e.encBuf, uint64(len(e.symbols)))
+		symbols.Write(e.encBuf[:n])
+		for _, s := range e.symbols {
+			n = binary.PutUvarint(e.encBuf, uint64(len(s)))
+			symbols.Write(e.encBuf[:n])
+			symbols.WriteString(s)
+		}
+		if _, err := compressedWriter.Write(symbols.Bytes()); err != nil {
+			return nil, errors.Wrap(err, "appending symbols")
+		}
+	}
+
+	if _, err := compressedWriter.Write(e.entries.Bytes()); err != nil {
+		return nil, errors.Wrap(err, "appending entry")
+	}
+	if err := compressedWriter.Close(); err != nil {
+		return nil, errors.Wrap(err, "flushing pending compress buffer")
+	}
+
+	return outBuf.Bytes(), nil
+}
+
+// release gives the entries buffer back to its pool.
+func (e *blockEncoder) release() {
+	e.entries.Reset()
+	serializeBytesBufferPool.Put(e.entries)
+}
+
+// structuredMetadataSize returns the size in bytes of the names and values of the structured metadata.
+func structuredMetadataSize(structuredMetadata labels.Labels) int {
+	size := 0
+	for _, l := range structuredMetadata {
+		size += len(l.Name) + len(l.Value)
+	}
+	return size
+}
@@ -0,0 +1,159 @@
+package chunkenc
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/loki/pkg/logproto"
+	"github.com/grafana/loki/pkg/logql"
+)
+
+func structuredMetadataEntries() []logproto.Entry {
+	var entries []logproto.Entry
+	for i := 0; i < 100; i++ {
+		e := logproto.Entry{Timestamp: time.Unix(0, int64(i)), Line: fmt.Sprintf("line %d", i)}
+		if i%2 == 0 {
+			e.StructuredMetadata = labels.Labels{
+				{Name: "pod_uid", Value: "f2a5c8d4"},
+				{Name: "trace_id", Value: fmt.Sprintf("%d", i%10)},
+			}
+		}
+		entries = append(entries, e)
+	}
+	return entries
+}
+
+func requireEntries(t *testing.T, c *MemChunk, query string, expected []logproto.Entry) {
+	t.Helper()
+
+	expr, err := logql.ParseLogSelector(query, true)
+	require.NoError(t, err)
+	p, err := expr.Pipeline()
+	require.NoError(t, err)
+
+	it, err := c.Iterator(context.Background(), time.Unix(0, 0), time.Unix(0, math.MaxInt64), logproto.FORWARD, p.ForStream(labels.Labels{{Name: "app", Value: "foo"}}))
+	require.NoError(t, err)
+
+	var actual []logproto.Entry
+	for it.Next() {
+		actual = append(actual, it.Entry())
+	}
+	require.NoError(t, it.Close())
+	require.Equal(t, expected, actual)
+}
+
+func TestMemChunk_StructuredMetadata(t *testing.T) {
+	entries := structuredMetadataEntries()
+	var filtered []logproto.Entry
+	for _, e := range entries {
+		if e.StructuredMetadata.Get("trace_id") == "4" {
+			filtered = append(filtered, e)
+		}
+	}
+
+	for _, enc := range testEncoding {
+		t.Run(enc.String(), func(t *testing.T) {
+			c := NewMemChunk(enc, UnorderedWithStructuredMetadataHeadBlockFmt, testBlockSize, testTargetSize)
+			require.Equal(t, chunkFormatV4, c.format)
+			for i := range entries {
+				require.NoError(t, c.Append(&entries[i]))
+			}
+
+			// from the head block.
+			requireEntries(t, c, `{app="foo"}`, entries)
+			requireEntries(t, c, `{app="foo"} | trace_id="4"`, filtered)
+
+			// from the cut blocks.
+			require.NoError(t, c.Close())
+			requireEntries(t, c, `{app="foo"}`, entries)
+			requireEntries(t, c, `{app="foo"} | trace_id="4"`, filtered)
+
+			// from the stored chunk.
+			b, err := c.Bytes()
+			require.NoError(t, err)
+			loaded, err := NewByteChunk(b, testBlockSize, testTargetSize)
+			require.NoError(t, err)
+			require.Equal(t, chunkFormatV4, loaded.format)
+			require.Equal(t, UnorderedWithStructuredMetadataHeadBlockFmt, loaded.HeadFormat())
+			requireEntries(t, loaded, `{app="foo"}`, entries)
+			requireEntries(t, loaded, `{app="foo"} | trace_id="4"`, filtered)
+		})
+	}
+}
+
+func TestMemChunk_StructuredMetadataSampleIterator(t *testing.T) {
+	c := NewMemChunk(EncSnappy, UnorderedWithStructuredMetadataHeadBlockFmt, testBlockSize, testTargetSize)
+	for _, e := range structuredMetadataEntries() {
+		e := e
+		require.NoError(t, c.Append(&e))
+	}
+
+	expr, err := logql.ParseSampleExpr(`count_over_time({app="foo"} | trace_id="4" [1m])`)
+	require.NoError(t, err)
+	extractor, err := expr.Extractor()
+	require.NoError(t, err)
+
+	for _, cut := range []bool{false, true} {
+		if cut {
+			require.NoError(t, c.Close())
+		}
+		it := c.SampleIterator(context.Background(), time.Unix(0, 0), time.Unix(0, math.MaxInt64), extractor.ForStream(labels.Labels{{Name: "app", Value: "foo"}}))
+		var timestamps []int64
+		for it.Next() {
+			timestamps = append(timestamps, it.Sample().Timestamp)
+			require.Equal(t, `{app="foo", pod_uid="f2a5c8d4", trace_id="4"}`, it.Labels())
+		}
+		require.NoError(t, it.Close())
+		require.Equal(t, []int64{4, 14, 24, 34, 44, 54, 64, 74, 84, 94}, timestamps)
+	}
+}
+
+func TestMemChunk_StructuredMetadataCheckpoint(t *testing.T) {
+	entries := structuredMetadataEntries()
+	c := NewMemChunk(EncSnappy, UnorderedWithStructuredMetadataHeadBlockFmt, testBlockSize, testTargetSize)
+	for i := range entries[:50] {
+		require.NoError(t, c.Append(&entries[i]))
+	}
+	require.NoError(t, c.cut())
+	for i := range entries[50:] {
+		require.NoError(t, c.Append(&entries[50+i]))
+	}
+
+	var chk, head bytes.Buffer
+	require.NoError(t, c.SerializeForCheckpointTo(&chk, &head))
+
+	// replaying keeps the structured metadata even though unordered head blocks are requested.
+	loaded, err := MemchunkFromCheckpoint(chk.Bytes(), head.Bytes(), UnorderedHeadBlockFmt, testBlockSize, testTargetSize)
+	require.NoError(t, err)
+	require.Equal(t, UnorderedWithStructuredMetadataHeadBlockFmt, loaded.HeadFormat())
+	requireEntries(t, loaded, `{app="foo"}`, entries)
+}
+
+func TestMemChunk_ConvertHeadToStructuredMetadata(t *testing.T) {
+	entry := structuredMetadataEntries()[0]
+
+	// chunks without blocks are upgraded to the V4 format.
+	c := NewMemChunk(EncSnappy, UnorderedHeadBlockFmt, testBlockSize, testTargetSize)
+	require.NoError(t, c.ConvertHead(UnorderedWithStructuredMetadataHeadBlockFmt))
+	require.Equal(t, chunkFormatV4, c.format)
+	require.Equal(t, UnorderedWithStructuredMetadataHeadBlockFmt, c.HeadFormat())
+
+	// the others can't mix the block formats, and drop the structured metadata.
+	c = NewMemChunk(EncSnappy, UnorderedHeadBlockFmt, testBlockSize, testTargetSize)
+	require.NoError(t, c.Append(logprotoEntry(0, "line")))
+	require.NoError(t, c.cut())
+	require.NoError(t, c.ConvertHead(UnorderedWithStructuredMetadataHeadBlockFmt))
+	require.Equal(t, chunkFormatV3, c.format)
+	require.Equal(t, UnorderedHeadBlockFmt, c.HeadFormat())
+
+	require.NoError(t, c.Append(&entry))
+	entry.StructuredMetadata = nil
+	requireEntries(t, c, `{app="foo"}`, []logproto.Entry{*logprotoEntry(0, "line"), entry})
+}
@@ -28,13 +28,13 @@ type HeadBlock interface {
 	CheckpointBytes(b []byte) ([]byte, error)
 	CheckpointSize() int
 	LoadBytes(b []byte) error
-	Serialise(pool WriterPool) ([]byte, error)
+	Serialise(pool WriterPool, format byte) ([]byte, error)
 	Reset()
 	Bounds() (mint, maxt int64)
 	Entries() int
 	UncompressedSize() int
 	Convert(HeadBlockFmt) (HeadBlock, error)
-	Append(int64, string) error
+	Append(int64, string, labels.Labels) error
 	Iterator(
 		ctx context.Context,
 		direction logproto.Direction,
@@ -52,6 +52,8 @@ type HeadBlock interface {
 }
 
 type unorderedHeadBlock struct {
+	format HeadBlockFmt
+
 	// Opted for range tree over skiplist for space reduction.
 	// Inserts: O(log(n))
 	// Scans: (O(k+log(n))) where k=num_scanned_entries & n=total_entries
@@ -62,13 +64,18 @@ type unorderedHeadBlock struct {
 	mint, maxt int64 // upper and lower bounds
 }
 
-func newUnorderedHeadBlock() *unorderedHeadBlock {
+func newUnorderedHeadBlock(format HeadBlockFmt) *unorderedHeadBlock {
 	return &unorderedHeadBlock{
-		rt: rangetree.New(1),
+		format: format,
+		rt:     rangetree.New(1),
 	}
 }
 
-func (hb *unorderedHeadBlock) Format() HeadBlockFmt { return UnorderedHeadBlockFmt }
+func (hb *unorderedHeadBlock) Format() HeadBlockFmt { return hb.format }
+
+func (hb *unorderedHeadBlock) hasStructuredMetadata() bool {
+	return hb.format >= UnorderedWithStructuredMetadataHeadBlockFmt
+}
 
 func (hb *unorderedHeadBlock) IsEmpty() bool {
 	return hb.size == 0
@@ -87,21 +94,32 @@ func (hb *unorderedHeadBlock) UncompressedSize() int {
 }
 
 func (hb *unorderedHeadBlock) Reset() {
-	x := newUnorderedHeadBlock()
+	x := newUnorderedHeadBlock(hb.format)
 	*hb = *x
 }
 
 // collection of entries belonging to the same nanosecond
 type nsEntries struct {
 	ts      int64
-	entries []string
+	entries []nsEntry
+}
+
+type nsEntry struct {
+	line               string
+	structuredMetadata labels.Labels
 }
 
 func (e *nsEntries) ValueAtDimension(_ uint64) int64 {
 	return e.ts
 }
 
-func (hb *unorderedHeadBlock) Append(ts int64, line string) error {
+// Append appends an entry to the head block.
+// The structured metadata of the entry is only kept by the head blocks of the UnorderedWithStructuredMetadataHeadBlockFmt format.
+func (hb *unorderedHeadBlock) Append(ts int64, line string, structuredMetadata labels.Labels) error {
+	if !hb.hasStructuredMetadata() {
+		structuredMetadata = nil
+	}
+
 	// This is an allocation hack. The rangetree lib does not
 	// support the ability to pass a "mutate" function during an insert
 	// and instead will displace any existing entry at the specified timestamp.
@@ -116,9 +134,9 @@ func (hb *unorderedHeadBlock) Append(ts int64, line string) error {
 	}
 	displaced := hb.rt.Add(e)
 	if displaced[0] != nil {
-		e.entries = append(displaced[0].(*nsEntries).entries, line)
+		e.entries = append(displaced[0].(*nsEntries).entries, nsEntry{line, structuredMetadata})
 	} else {
-		e.entries = []string{line}
+		e.entries = []nsEntry{{line, structuredMetadata}}
 	}
 
 	// Update hb metdata
@@ -130,7 +148,7 @@ func (hb *unorderedHeadBlock) Append(ts int64, line string) error {
 		hb.maxt = ts
 	}
 
-	hb.size += len(line)
+	hb.size += len(line) + structuredMetadataSize(structuredMetadata)
 	hb.lines++
 
 	return nil
@@ -153,7 +171,7 @@ func (hb *unorderedHeadBlock) forEntries(
 	direction logproto.Direction,
 	mint,
 	maxt int64,
-	entryFn func(int64, string) error, // returning an error exits early
+	entryFn func(int64, string, labels.Labels) error, // returning an error exits early
 ) (err error) {
 	if hb.IsEmpty() || (maxt < hb.mint || hb.maxt < mint) {
 		return
@@ -182,9 +200,9 @@ func (hb *unorderedHeadBlock) forEntries(
 		}
 
 		for ; i < len(es.entries) && i >= 0; next() {
-			line := es.entries[i]
-			chunkStats.AddHeadChunkBytes(int64(len(line)))
-			err = entryFn(es.ts, line)
+			e := es.entries[i]
+			chunkStats.AddHeadChunkBytes(int64(len(e.line)))
+			err = entryFn(es.ts, e.line, e.structuredMetadata)
 
 		}
 	}
@@ -227,8 +245,8 @@ func (hb *unorderedHeadBlock) Iterator(
 		direction,
 		mint,
 		maxt,
-		func(ts int64, line string) error {
-			newLine, parsedLbs, ok := pipeline.ProcessString(line)
+		func(ts int64, line string, structuredMetadata labels.Labels) error {
+			newLine, parsedLbs, ok := pipeline.ProcessString(ts, line, structuredMetadata...)
 			if !ok {
 				return nil
 			}
@@ -243,8 +261,9 @@ func (hb *unorderedHeadBlock) Iterator(
 			}
 
 			stream.Entries = append(stream.Entries, logproto.Entry{
-				Timestamp: time.Unix(0, ts),
-				Line:      newLine,
+				Timestamp:          time.Unix(0, ts),
+				Line:               newLine,
+				StructuredMetadata: structuredMetadata,
 			})
 			return nil
 		},
@@ -275,8 +294,8 @@ func (hb *unorderedHeadBlock) SampleIterator(
 		logproto.FORWARD,
 		mint,
 		maxt,
-		func(ts int64, line string) error {
-			value, parsedLabels, ok := extractor.ProcessString(line)
+		func(ts int64, line string, structuredMetadata labels.Labels) error {
+			value, parsedLabels, ok := extractor.ProcessString(ts, line, structuredMetadata...)
 			if !ok {
 				return nil
 			}
@@ -318,47 +337,26 @@ func (hb *unorderedHeadBlock) SampleIterator(
 
 // nolint:unused
 // serialise is used in creating an ordered, compressed block from an unorderedHeadBlock
-func (hb *unorderedHeadBlock) Serialise(pool WriterPool) ([]byte, error) {
-	inBuf := serializeBytesBufferPool.Get().(*bytes.Buffer)
-	defer func() {
-		inBuf.Reset()
-		serializeBytesBufferPool.Put(inBuf)
-	}()
-	outBuf := &bytes.Buffer{}
-
-	encBuf := make([]byte, binary.MaxVarintLen64)
-	compressedWriter := pool.GetWriter(outBuf)
-	defer pool.PutWriter(compressedWriter)
+func (hb *unorderedHeadBlock) Serialise(pool WriterPool, format byte) ([]byte, error) {
+	enc := newBlockEncoder(format)
+	defer enc.release()
 
 	_ = hb.forEntries(
 		context.Background(),
 		logproto.FORWARD,
 		0,
 		math.MaxInt64,
-		func(ts int64, line string) error {
-			n := binary.PutVarint(encBuf, ts)
-			inBuf.Write(encBuf[:n])
-
-			n = binary.PutUvarint(encBuf, uint64(len(line)))
-			inBuf.Write(encBuf[:n])
-
-			inBuf.WriteString(line)
+		func(ts int64, line string, structuredMetadata labels.Labels) error {
+			enc.add(ts, line, structuredMetadata)
 			return nil
 		},
 	)
 
-	if _, err := compressedWriter.Write(inBuf.Bytes()); err != nil {
-		return nil, errors.Wrap(err, "appending entry")
-	}
-	if err := compressedWriter.Close(); err != nil {
-		return nil, errors.Wrap(err, "flushing pending compress buffer")
-	}
-
-	return outBuf.Bytes(), nil
+	return enc.compress(pool)
 }
 
 func (hb *unorderedHeadBlock) Convert(version HeadBlockFmt) (HeadBlock, error) {
-	if version > OrderedHeadBlockFmt {
+	if version == hb.format {
 		return hb, nil
 	}
 	out := version.NewBlock()
@@ -368,8 +366,8 @@ func (hb *unorderedHeadBlock) Convert(version HeadBlockFmt) (HeadBlock, error) {
 		logproto.FORWARD,
 		0,
 		math.MaxInt64,
-		func(ts int64, line string) error {
-			return out.Append(ts, line)
+		func(ts int64, line string, structuredMetadata labels.Labels) error {
+			return out.Append(ts, line, structuredMetadata)
 		},
 	)
 	return out, err
@@ -381,7 +379,21 @@ func (hb *unorderedHeadBlock) CheckpointSize() int {
 	size += binary.MaxVarintLen32 * 2                                  // total entries + total size
 	size += binary.MaxVarintLen64 * 2                                  // mint,maxt
 	size += (binary.MaxVarintLen64 + binary.MaxVarintLen32) * hb.lines // ts + len of log line.
-	size += hb.size                                                    // uncompressed bytes of lines
+	size += hb.size                                                    // uncompressed bytes of lines and structured metadata
+
+	if hb.hasStructuredMetadata() {
+		size += binary.MaxVarintLen32 * hb.lines // number of structured metadata of each entry.
+		_ = hb.forEntries(
+			context.Background(),
+			logproto.FORWARD,
+			0,
+			math.MaxInt64,
+			func(_ int64, _ string, structuredMetadata labels.Labels) error {
+				size += binary.MaxVarintLen32 * 2 * len(structuredMetadata) // len of names and values.
+				return nil
+			},
+		)
+	}
 	return size
 }
 
@@ -421,7 +433,7 @@ func (hb *unorderedHeadBlock) CheckpointTo(w io.Writer) error {
 		logproto.FORWARD,
 		0,
 		math.MaxInt64,
-		func(ts int64, line string) error {
+		func(ts int64, line string, structuredMetadata labels.Labels) error {
 			eb.putVarint64(ts)
 			eb.putUvarint(len(line))
 			_, err = w.Write(eb.get())
@@ -434,6 +446,20 @@ func (hb *unorderedHeadBlock) CheckpointTo(w io.Writer) error {
 			if err != nil {
 				return errors.Wrap(err, "write headblock entry line")
 			}
+
+			if !hb.hasStructuredMetadata() {
+				return nil
+			}
+			eb.putUvarint(len(structuredMetadata))
+			for _, l := range structuredMetadata {
+				eb.putUvarintStr(l.Name)
+				eb.putUvarintStr(l.Value)
+			}
+			_, err = w.Write(eb.get())
+			if err != nil {
+				return errors.Wrap(err, "write headblock entry structured metadata")
+			}
+			eb.reset()
 			return nil
 		},
 	)
@@ -443,7 +469,7 @@ func (hb *unorderedHeadBlock) CheckpointTo(w io.Writer) error {
 
 func (hb *unorderedHeadBlock) LoadBytes(b []byte) error {
 	// ensure it's empty
-	*hb = *newUnorderedHeadBlock()
+	*hb = *newUnorderedHeadBlock(hb.format)
 
 	if len(b) < 1 {
 		return nil
@@ -456,8 +482,11 @@ func (hb *unorderedHeadBlock) LoadBytes(b []byte) error {
 		return errors.Wrap(db.err(), "verifying headblock header")
 	}
 
-	if version != UnorderedHeadBlockFmt.Byte() {
-		return errors.Errorf("incompatible headBlock version (%v), only V4 is currently supported", version)
+	switch HeadBlockFmt(version) {
+	case UnorderedHeadBlockFmt, UnorderedWithStructuredMetadataHeadBlockFmt:
+		hb.format = HeadBlockFmt(version)
+	default:
+		return errors.Errorf("incompatible headBlock version (%v), only V4,V5 are currently supported", version)
 	}
 
 	n := db.uvarint()
@@ -470,7 +499,20 @@ func (hb *unorderedHeadBlock) LoadBytes(b []byte) error {
 		ts := db.varint64()
 		lineLn := db.uvarint()
 		line := string(db.bytes(lineLn))
-		if err := hb.Append(ts, line); err != nil {
+
+		var structuredMetadata labels.Labels
+		if hb.hasStructuredMetadata() {
+			if n := db.uvarint(); n > 0 {
+				structuredMetadata = make(labels.Labels, 0, n)
+				for j := 0; j < n && db.err() == nil; j++ {
+					name := string(db.bytes(db.uvarint()))
+					value := string(db.bytes(db.uvarint()))
+					structuredMetadata = append(structuredMetadata, labels.Label{Name: name, Value: value})
+				}
+			}
+		}
+
+		if err := hb.Append(ts, line, structuredMetadata); err != nil {
 			return err
 		}
 	}
@@ -497,9 +539,13 @@ func HeadFromCheckpoint(b []byte, desired HeadBlockFmt) (HeadBlock, error) {
 		return nil, errors.Wrap(db.err(), "verifying headblock header")
 	}
 	format := HeadBlockFmt(version)
-	if format > UnorderedHeadBlockFmt {
+	if format > UnorderedWithStructuredMetadataHeadBlockFmt {
 		return nil, fmt.Errorf("unexpected head block version: %v", format)
 	}
+	if format == UnorderedWithStructuredMetadataHeadBlockFmt && desired == UnorderedHeadBlockFmt {
+		// Keep the structured metadata of the entries, which would be lost by the conversion.
+		desired = format
+	}
 
 	decodedBlock := format.NewBlock()
 	if err := decodedBlock.LoadBytes(b); err != nil {
@@ -4,6 +4,7 @@ import (
 	"context"
 	"flag"
 	"net/http"
+	"sort"
 	"time"
 
 	"github.com/grafana/dskit/kv"
@@ -251,13 +252,14 @@ func (d *Distributor) Push(ctx context.Context, req *logproto.PushRequest) (*log
 
 		n := 0
 		for _, entry := range stream.Entries {
+			sort.Sort(entry.StructuredMetadata)
 			if err := d.validator.ValidateEntry(validationContext, stream.Labels, entry); err != nil {
 				validationErr = err
 				continue
 			}
 			stream.Entries[n] = entry
 			n++
-			validatedSamplesSize += len(entry.Line)
+			validatedSamplesSize += entrySize(entry)
 			validatedSamplesCount++
 		}
 		stream.Entries = stream.Entries[:n]
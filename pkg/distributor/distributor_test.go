@@ -20,6 +20,7 @@ import (
 	"github.com/grafana/dskit/services"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/model/labels"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"github.com/weaveworks/common/httpgrpc"
@@ -115,6 +116,22 @@ func Test_SortLabelsOnPush(t *testing.T) {
 	require.Equal(t, `{a="b", buzz="f"}`, ingester.pushed[0].Streams[0].Labels)
 }
 
+func Test_SortStructuredMetadataOnPush(t *testing.T) {
+	limits := &validation.Limits{}
+	flagext.DefaultValues(limits)
+	limits.EnforceMetricName = false
+	limits.AllowStructuredMetadata = true
+	ingester := &mockIngester{}
+	d := prepare(t, limits, nil, func(addr string) (ring_client.PoolClient, error) { return ingester, nil })
+	defer services.StopAndAwaitTerminated(context.Background(), d) //nolint:errcheck
+
+	request := makeWriteRequest(1, 10)
+	request.Streams[0].Entries[0].StructuredMetadata = labels.Labels{{Name: "trace_id", Value: "1"}, {Name: "pod_uid", Value: "2"}}
+	_, err := d.Push(ctx, request)
+	require.NoError(t, err)
+	require.Equal(t, labels.Labels{{Name: "pod_uid", Value: "2"}, {Name: "trace_id", Value: "1"}}, ingester.pushed[0].Streams[0].Entries[0].StructuredMetadata)
+}
+
 func Test_TruncateLogLines(t *testing.T) {
 	setup := func() (*validation.Limits, *mockIngester) {
 		limits := &validation.Limits{}
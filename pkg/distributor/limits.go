@@ -6,6 +6,7 @@ import "time"
 type Limits interface {
 	MaxLineSize(userID string) int
 	MaxLineSizeTruncate(userID string) bool
+	AllowStructuredMetadata(userID string) bool
 	EnforceMetricName(userID string) bool
 	MaxLabelNamesPerSeries(userID string) int
 	MaxLabelNameLength(userID string) int
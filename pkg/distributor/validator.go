@@ -6,6 +6,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/prometheus/common/model"
 	"github.com/prometheus/prometheus/model/labels"
 	"github.com/weaveworks/common/httpgrpc"
 
@@ -36,6 +37,8 @@ type validationContext struct {
 	maxLineSize         int
 	maxLineSizeTruncate bool
 
+	allowStructuredMetadata bool
+
 	maxLabelNamesPerSeries int
 	maxLabelNameLength     int
 	maxLabelValueLength    int
@@ -54,6 +57,8 @@ func (v Validator) getValidationContextForTime(now time.Time, userID string) val
 		maxLabelNamesPerSeries: v.MaxLabelNamesPerSeries(userID),
 		maxLabelNameLength:     v.MaxLabelNameLength(userID),
 		maxLabelValueLength:    v.MaxLabelValueLength(userID),
+
+		allowStructuredMetadata: v.AllowStructuredMetadata(userID),
 	}
 }
 
@@ -87,9 +92,38 @@ func (v Validator) ValidateEntry(ctx validationContext, labels string, entry log
 		return httpgrpc.Errorf(http.StatusBadRequest, validation.LineTooLongErrorMsg, maxSize, labels, len(entry.Line))
 	}
 
+	if len(entry.StructuredMetadata) > 0 {
+		if !ctx.allowStructuredMetadata {
+			validation.DiscardedSamples.WithLabelValues(validation.DisallowedStructuredMetadata, ctx.userID).Inc()
+			validation.DiscardedBytes.WithLabelValues(validation.DisallowedStructuredMetadata, ctx.userID).Add(float64(len(entry.Line)))
+			return httpgrpc.Errorf(http.StatusBadRequest, validation.DisallowedStructuredMetadataErrorMsg, labels)
+		}
+
+		// The structured metadata are expected sorted by name, like the labels of the streams, the distributor
+		// sorting them before validating the entry. Names out of order or duplicated are rejected.
+		lastName := ""
+		for _, l := range entry.StructuredMetadata {
+			if !model.LabelName(l.Name).IsValid() || l.Name <= lastName {
+				validation.DiscardedSamples.WithLabelValues(validation.InvalidStructuredMetadata, ctx.userID).Inc()
+				validation.DiscardedBytes.WithLabelValues(validation.InvalidStructuredMetadata, ctx.userID).Add(float64(len(entry.Line)))
+				return httpgrpc.Errorf(http.StatusBadRequest, validation.InvalidStructuredMetadataErrorMsg, labels, l.Name)
+			}
+			lastName = l.Name
+		}
+	}
+
 	return nil
 }
 
+// entrySize returns the size in bytes of the line and the structured metadata of the entry.
+func entrySize(entry logproto.Entry) int {
+	size := len(entry.Line)
+	for _, l := range entry.StructuredMetadata {
+		size += len(l.Name) + len(l.Value)
+	}
+	return size
+}
+
 // Validate labels returns an error if the labels are invalid
 func (v Validator) ValidateLabels(ctx validationContext, ls labels.Labels, stream logproto.Stream) error {
 	if len(ls) == 0 {
@@ -85,6 +85,57 @@ func TestValidator_ValidateEntry(t *testing.T) {
 			logproto.Entry{Timestamp: testTime, Line: "12345678901"},
 			httpgrpc.Errorf(http.StatusBadRequest, validation.LineTooLongErrorMsg, 10, testStreamLabels, 11),
 		},
+		{
+			"disallowed structured metadata",
+			"test",
+			nil,
+			logproto.Entry{Timestamp: testTime, Line: "test", StructuredMetadata: labels.Labels{{Name: "trace_id", Value: "3c0a8a8c"}}},
+			httpgrpc.Errorf(http.StatusBadRequest, validation.DisallowedStructuredMetadataErrorMsg, testStreamLabels),
+		},
+		{
+			"valid structured metadata",
+			"test",
+			fakeLimits{
+				&validation.Limits{
+					AllowStructuredMetadata: true,
+				},
+			},
+			logproto.Entry{Timestamp: testTime, Line: "test", StructuredMetadata: labels.Labels{{Name: "pod_uid", Value: "f2a5c8d4"}, {Name: "trace_id", Value: "3c0a8a8c"}}},
+			nil,
+		},
+		{
+			"invalid structured metadata name",
+			"test",
+			fakeLimits{
+				&validation.Limits{
+					AllowStructuredMetadata: true,
+				},
+			},
+			logproto.Entry{Timestamp: testTime, Line: "test", StructuredMetadata: labels.Labels{{Name: "trace-id", Value: "3c0a8a8c"}}},
+			httpgrpc.Errorf(http.StatusBadRequest, validation.InvalidStructuredMetadataErrorMsg, testStreamLabels, "trace-id"),
+		},
+		{
+			"duplicate structured metadata name",
+			"test",
+			fakeLimits{
+				&validation.Limits{
+					AllowStructuredMetadata: true,
+				},
+			},
+			logproto.Entry{Timestamp: testTime, Line: "test", StructuredMetadata: labels.Labels{{Name: "trace_id", Value: "1"}, {Name: "trace_id", Value: "2"}}},
+			httpgrpc.Errorf(http.StatusBadRequest, validation.InvalidStructuredMetadataErrorMsg, testStreamLabels, "trace_id"),
+		},
+		{
+			"unsorted structured metadata",
+			"test",
+			fakeLimits{
+				&validation.Limits{
+					AllowStructuredMetadata: true,
+				},
+			},
+			logproto.Entry{Timestamp: testTime, Line: "test", StructuredMetadata: labels.Labels{{Name: "trace_id", Value: "3c0a8a8c"}, {Name: "pod_uid", Value: "f2a5c8d4"}}},
+			httpgrpc.Errorf(http.StatusBadRequest, validation.InvalidStructuredMetadataErrorMsg, testStreamLabels, "pod_uid"),
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
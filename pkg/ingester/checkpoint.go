@@ -526,7 +526,8 @@ type Checkpointer struct {
 	writer  CheckpointWriter
 	metrics *ingesterMetrics
 
-	quit <-chan struct{}
+	trigger chan struct{}
+	quit    <-chan struct{}
 }
 
 func NewCheckpointer(dur time.Duration, iter SeriesIter, writer CheckpointWriter, metrics *ingesterMetrics, quit <-chan struct{}) *Checkpointer {
@@ -535,10 +536,20 @@ func NewCheckpointer(dur time.Duration, iter SeriesIter, writer CheckpointWriter
 		iter:    iter,
 		writer:  writer,
 		metrics: metrics,
+		trigger: make(chan struct{}, 1),
 		quit:    quit,
 	}
 }
 
+// Trigger requests a checkpoint to be performed as soon as possible.
+// It never blocks; requests made while one is already pending are merged.
+func (c *Checkpointer) Trigger() {
+	select {
+	case c.trigger <- struct{}{}:
+	default:
+	}
+}
+
 func (c *Checkpointer) PerformCheckpoint() (err error) {
 	noop, err := c.writer.Advance()
 	if err != nil {
@@ -617,6 +628,13 @@ func (c *Checkpointer) Run() {
 				level.Error(util_log.Logger).Log("msg", "error checkpointing series", "err", err)
 				continue
 			}
+		case <-c.trigger:
+			level.Info(util_log.Logger).Log("msg", "starting requested checkpoint")
+			if err := c.PerformCheckpoint(); err != nil {
+				level.Error(util_log.Logger).Log("msg", "error checkpointing series", "err", err)
+				continue
+			}
+			ticker.Reset(c.dur)
 		case <-c.quit:
 			return
 		}
@@ -240,6 +240,40 @@ func TestUnflushedChunks(t *testing.T) {
 	require.Equal(t, 1, len(unflushedChunks(chks)))
 }
 
+type countingCheckpointWriter struct {
+	advanced chan struct{}
+}
+
+func (w countingCheckpointWriter) Advance() (bool, error) {
+	w.advanced <- struct{}{}
+	return true, nil
+}
+func (countingCheckpointWriter) Write(*Series) error { return nil }
+func (countingCheckpointWriter) Close(bool) error    { return nil }
+
+func TestCheckpointerTrigger(t *testing.T) {
+	writer := countingCheckpointWriter{advanced: make(chan struct{}, 10)}
+	quit := make(chan struct{})
+	iter := newIngesterSeriesIter(ingesterInstancesFunc(func() []*instance { return nil }))
+	c := NewCheckpointer(time.Hour, iter, writer, NilMetrics, quit)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		c.Run()
+	}()
+
+	c.Trigger()
+	select {
+	case <-writer.advanced:
+	case <-time.After(5 * time.Second):
+		t.Fatal("triggered checkpoint was not performed")
+	}
+
+	close(quit)
+	<-done
+}
+
 func TestIngesterWALBackpressureSegments(t *testing.T) {
 	walDir, err := ioutil.TempDir(os.TempDir(), "loki-wal")
 	require.Nil(t, err)
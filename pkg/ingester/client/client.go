@@ -35,6 +35,7 @@ type ClosableHealthAndIngesterClient struct {
 	logproto.PusherClient
 	logproto.QuerierClient
 	logproto.IngesterClient
+	logproto.PatternClient
 	grpc_health_v1.HealthClient
 	io.Closer
 }
@@ -77,6 +78,7 @@ func New(cfg Config, addr string) (HealthAndIngesterClient, error) {
 		PusherClient:   logproto.NewPusherClient(conn),
 		QuerierClient:  logproto.NewQuerierClient(conn),
 		IngesterClient: logproto.NewIngesterClient(conn),
+		PatternClient:  logproto.NewPatternClient(conn),
 		HealthClient:   grpc_health_v1.NewHealthClient(conn),
 		Closer:         conn,
 	}, nil
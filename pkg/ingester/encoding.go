@@ -4,6 +4,7 @@ import (
 	"time"
 
 	"github.com/pkg/errors"
+	"github.com/prometheus/prometheus/model/labels"
 	"github.com/prometheus/prometheus/tsdb/chunks"
 	"github.com/prometheus/prometheus/tsdb/encoding"
 	"github.com/prometheus/prometheus/tsdb/record"
@@ -25,10 +26,14 @@ const (
 	// WALRecordEntriesV2 is the type for the WAL record for samples with an
 	// additional counter value for use in replaying without the ordering constraint.
 	WALRecordEntriesV2
+	// WALRecordEntriesV3 is the type for the WAL record for samples with the
+	// structured metadata of the entries.
+	WALRecordEntriesV3
 )
 
 // The current type of Entries that this distribution writes.
 // Loki can read in a backwards compatible manner, but will write the newest variant.
+// Records with structured metadata are written as WALRecordEntriesV3 instead, see entriesRecordType.
 const CurrentEntriesRec RecordType = WALRecordEntriesV2
 
 // WALRecord is a struct combining the series and samples record.
@@ -95,6 +100,20 @@ func (r *WALRecord) encodeSeries(b []byte) []byte {
 	return encoded
 }
 
+// entriesRecordType returns the type to encode the entries of the record with.
+// WALRecordEntriesV3 is only used when the entries carry structured metadata,
+// so that the WALs without any can still be replayed by older versions.
+func (r *WALRecord) entriesRecordType() RecordType {
+	for _, ref := range r.RefEntries {
+		for _, entry := range ref.Entries {
+			if len(entry.StructuredMetadata) > 0 {
+				return WALRecordEntriesV3
+			}
+		}
+	}
+	return CurrentEntriesRec
+}
+
 func (r *WALRecord) encodeEntries(version RecordType, b []byte) []byte {
 	buf := EncWith(b)
 	buf.PutByte(byte(version))
@@ -131,6 +150,14 @@ outer:
 			buf.PutVarint64(s.Timestamp.UnixNano() - first)
 			buf.PutUvarint(len(s.Line))
 			buf.PutString(s.Line)
+
+			if version >= WALRecordEntriesV3 {
+				buf.PutUvarint(len(s.StructuredMetadata))
+				for _, l := range s.StructuredMetadata {
+					buf.PutUvarintStr(l.Name)
+					buf.PutUvarintStr(l.Value)
+				}
+			}
 		}
 	}
 	return buf.Get()
@@ -161,9 +188,22 @@ func decodeEntries(b []byte, version RecordType, rec *WALRecord) error {
 			lineLength := dec.Uvarint()
 			line := dec.Bytes(lineLength)
 
+			var structuredMetadata labels.Labels
+			if version >= WALRecordEntriesV3 {
+				if n := dec.Uvarint(); n > 0 {
+					structuredMetadata = make(labels.Labels, 0, n)
+					for i := 0; i < n && dec.Err() == nil; i++ {
+						name := dec.UvarintStr()
+						value := dec.UvarintStr()
+						structuredMetadata = append(structuredMetadata, labels.Label{Name: name, Value: value})
+					}
+				}
+			}
+
 			refEntries.Entries = append(refEntries.Entries, logproto.Entry{
-				Timestamp: time.Unix(0, baseTime+timeOffset),
-				Line:      string(line),
+				Timestamp:          time.Unix(0, baseTime+timeOffset),
+				Line:               string(line),
+				StructuredMetadata: structuredMetadata,
 			})
 		}
 
@@ -198,7 +238,7 @@ func decodeWALRecord(b []byte, walRec *WALRecord) (err error) {
 	case WALRecordSeries:
 		userID = decbuf.UvarintStr()
 		rSeries, err = dec.Series(decbuf.B, walRec.Series)
-	case WALRecordEntriesV1, WALRecordEntriesV2:
+	case WALRecordEntriesV1, WALRecordEntriesV2, WALRecordEntriesV3:
 		userID = decbuf.UvarintStr()
 		err = decodeEntries(decbuf.B, t, walRec)
 	default:
@@ -129,6 +129,34 @@ func Test_Encoding_Entries(t *testing.T) {
 			},
 			version: WALRecordEntriesV2,
 		},
+		{
+			desc: "v3",
+			rec: &WALRecord{
+				entryIndexMap: make(map[uint64]int),
+				UserID:        "123",
+				RefEntries: []RefEntries{
+					{
+						Ref:     456,
+						Counter: 1,
+						Entries: []logproto.Entry{
+							{
+								Timestamp: time.Unix(1000, 0),
+								Line:      "first",
+								StructuredMetadata: labels.Labels{
+									{Name: "trace_id", Value: "3c0a8a8c"},
+									{Name: "user", Value: ""},
+								},
+							},
+							{
+								Timestamp: time.Unix(2000, 0),
+								Line:      "second",
+							},
+						},
+					},
+				},
+			},
+			version: WALRecordEntriesV3,
+		},
 	} {
 		decoded := recordPool.GetRecord()
 		buf := tc.rec.encodeEntries(tc.version, nil)
@@ -139,6 +167,19 @@ func Test_Encoding_Entries(t *testing.T) {
 	}
 }
 
+func Test_EntriesRecordType(t *testing.T) {
+	record := &WALRecord{entryIndexMap: make(map[uint64]int), UserID: "123"}
+	record.AddEntries(456, 1, logproto.Entry{Timestamp: time.Unix(1000, 0), Line: "first"})
+	require.Equal(t, CurrentEntriesRec, record.entriesRecordType())
+
+	record.AddEntries(789, 1, logproto.Entry{
+		Timestamp:          time.Unix(1000, 0),
+		Line:               "second",
+		StructuredMetadata: labels.Labels{{Name: "trace_id", Value: "3c0a8a8c"}},
+	})
+	require.Equal(t, WALRecordEntriesV3, record.entriesRecordType())
+}
+
 func Benchmark_EncodeEntries(b *testing.B) {
 	var entries []logproto.Entry
 	for i := int64(0); i < 10000; i++ {
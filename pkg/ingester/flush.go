@@ -2,8 +2,10 @@ package ingester
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
 	"net/http"
+	"strconv"
 	"sync"
 	"time"
 
@@ -18,10 +20,12 @@ import (
 	"github.com/weaveworks/common/user"
 
 	"github.com/grafana/loki/pkg/chunkenc"
+	"github.com/grafana/loki/pkg/logql"
 	"github.com/grafana/loki/pkg/storage/chunk"
 	"github.com/grafana/loki/pkg/tenant"
 	loki_util "github.com/grafana/loki/pkg/util"
 	util_log "github.com/grafana/loki/pkg/util/log"
+	serverutil "github.com/grafana/loki/pkg/util/server"
 )
 
 var (
@@ -143,6 +147,122 @@ func (i *Ingester) FlushHandler(w http.ResponseWriter, _ *http.Request) {
 	w.WriteHeader(http.StatusNoContent)
 }
 
+// StreamFlushProgress is written by FlushStreamsHandler for every stream it processed.
+type StreamFlushProgress struct {
+	Stream        string `json:"stream"`
+	FlushedChunks int    `json:"flushed_chunks"`
+	Forgotten     bool   `json:"forgotten,omitempty"`
+	Error         string `json:"error,omitempty"`
+}
+
+// StreamFlushSummary is written by FlushStreamsHandler once all streams were processed.
+type StreamFlushSummary struct {
+	Done             bool `json:"done"`
+	Streams          int  `json:"streams"`
+	FlushedChunks    int  `json:"flushed_chunks"`
+	ForgottenStreams int  `json:"forgotten_streams"`
+	Errors           int  `json:"errors"`
+}
+
+// FlushStreamsHandler synchronously flushes all in memory chunks of the streams of a single tenant
+// which match the optional `match` stream selector.
+// When `forget` is set, the flushed streams are also removed from memory and therefore from the next WAL
+// checkpoint, which is requested right away so the WAL segments containing them get truncated.
+// Progress is streamed back as newline delimited JSON, one line per stream followed by a summary.
+func (i *Ingester) FlushStreamsHandler(w http.ResponseWriter, r *http.Request) {
+	params := r.URL.Query()
+
+	tenantID := params.Get("tenant")
+	if tenantID == "" {
+		serverutil.JSONError(w, http.StatusBadRequest, "tenant not set")
+		return
+	}
+
+	var matchers []*labels.Matcher
+	if match := params.Get("match"); match != "" {
+		var err error
+		matchers, err = logql.ParseMatchers(match)
+		if err != nil {
+			serverutil.JSONError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+
+	var forget bool
+	if v := params.Get("forget"); v != "" {
+		var err error
+		forget, err = strconv.ParseBool(v)
+		if err != nil {
+			serverutil.JSONError(w, http.StatusBadRequest, "invalid forget parameter: %v", err)
+			return
+		}
+	}
+
+	instance, ok := i.getInstanceByID(tenantID)
+	if !ok {
+		serverutil.JSONError(w, http.StatusNotFound, "no streams found for tenant %s", tenantID)
+		return
+	}
+
+	// Collect the streams first, flushing them while iterating would hold the index for too long.
+	var streams []*stream
+	ctx := user.InjectOrgID(r.Context(), tenantID)
+	if err := instance.forMatchingStreams(ctx, matchers, nil, func(s *stream) error {
+		streams = append(streams, s)
+		return nil
+	}); err != nil {
+		serverutil.JSONError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.WriteHeader(http.StatusOK)
+	enc := json.NewEncoder(w)
+	flusher, _ := w.(http.Flusher)
+
+	summary := StreamFlushSummary{Streams: len(streams)}
+	for _, s := range streams {
+		if err := r.Context().Err(); err != nil {
+			level.Warn(util_log.Logger).Log("msg", "stream flush request cancelled", "tenant", tenantID, "err", err)
+			return
+		}
+
+		progress := StreamFlushProgress{Stream: s.labelsString}
+		n, err := i.flushStream(instance, s.fp, true)
+		progress.FlushedChunks = n
+		summary.FlushedChunks += n
+		if err != nil {
+			level.Error(util_log.WithUserID(tenantID, util_log.Logger)).Log("msg", "failed to flush stream", "stream", s.labelsString, "err", err)
+			progress.Error = err.Error()
+			summary.Errors++
+		} else if forget {
+			if progress.Forgotten = i.forgetStream(instance, s); progress.Forgotten {
+				summary.ForgottenStreams++
+			} else {
+				progress.Error = "stream received new data after being flushed and was not forgotten"
+				summary.Errors++
+			}
+		}
+
+		if err := enc.Encode(progress); err != nil {
+			level.Error(util_log.Logger).Log("msg", "error writing flush progress", "err", err)
+			return
+		}
+		if flusher != nil {
+			flusher.Flush()
+		}
+	}
+
+	if summary.ForgottenStreams > 0 {
+		i.wal.Checkpoint()
+	}
+
+	summary.Done = true
+	if err := enc.Encode(summary); err != nil {
+		level.Error(util_log.Logger).Log("msg", "error writing flush summary", "err", err)
+	}
+}
+
 type flushOp struct {
 	from      model.Time
 	userID    string
@@ -168,7 +288,11 @@ func (i *Ingester) sweepUsers(immediate, mayRemoveStreams bool) {
 }
 
 func (i *Ingester) sweepInstance(instance *instance, immediate, mayRemoveStreams bool) {
+	patternsRetainedFrom := time.Now().Add(-i.cfg.Patterns.RetainPeriod)
 	_ = instance.streams.ForEach(func(s *stream) (bool, error) {
+		if s.patterns != nil {
+			s.patterns.Prune(patternsRetainedFrom)
+		}
 		i.sweepStream(instance, s, immediate)
 		i.removeFlushedChunks(instance, s, mayRemoveStreams)
 		return true, nil
@@ -231,20 +355,26 @@ func (i *Ingester) flushUserSeries(userID string, fp model.Fingerprint, immediat
 		return nil
 	}
 
+	_, err := i.flushStream(instance, fp, immediate)
+	return err
+}
+
+// flushStream flushes the chunks of a single stream and returns how many chunks were flushed.
+func (i *Ingester) flushStream(instance *instance, fp model.Fingerprint, immediate bool) (int, error) {
 	chunks, labels, chunkMtx := i.collectChunksToFlush(instance, fp, immediate)
 	if len(chunks) < 1 {
-		return nil
+		return 0, nil
 	}
 
-	ctx := user.InjectOrgID(context.Background(), userID)
+	ctx := user.InjectOrgID(context.Background(), instance.instanceID)
 	ctx, cancel := context.WithTimeout(ctx, i.cfg.FlushOpTimeout)
 	defer cancel()
 	err := i.flushChunks(ctx, fp, labels, chunks, chunkMtx)
 	if err != nil {
-		return err
+		return 0, err
 	}
 
-	return nil
+	return len(chunks), nil
 }
 
 func (i *Ingester) collectChunksToFlush(instance *instance, fp model.Fingerprint, immediate bool) ([]*chunkDesc, labels.Labels, *sync.RWMutex) {
@@ -323,6 +453,27 @@ func (i *Ingester) removeFlushedChunks(instance *instance, stream *stream, mayRe
 	}
 }
 
+// forgetStream drops all chunks of a stream regardless of the retain period and removes the stream from the instance.
+// It refuses to do so if the stream holds chunks which haven't been flushed yet.
+func (i *Ingester) forgetStream(instance *instance, stream *stream) bool {
+	stream.chunkMtx.Lock()
+	defer stream.chunkMtx.Unlock()
+
+	var subtracted int
+	for _, c := range stream.chunks {
+		if c.flushed.IsZero() {
+			return false
+		}
+		subtracted += c.chunk.UncompressedSize()
+	}
+	memoryChunks.Sub(float64(len(stream.chunks)))
+	i.replayController.Sub(int64(subtracted))
+
+	stream.chunks = nil
+	instance.removeStream(stream)
+	return true
+}
+
 func (i *Ingester) flushChunks(ctx context.Context, fp model.Fingerprint, labelPairs labels.Labels, cs []*chunkDesc, chunkMtx sync.Locker) error {
 	userID, err := tenant.TenantID(ctx)
 	if err != nil {
@@ -1,8 +1,11 @@
 package ingester
 
 import (
+	"encoding/json"
 	"fmt"
 	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"sort"
 	"sync"
@@ -29,6 +32,7 @@ import (
 	"github.com/grafana/loki/pkg/logql/log"
 	"github.com/grafana/loki/pkg/runtime"
 	"github.com/grafana/loki/pkg/storage"
+	"github.com/grafana/loki/pkg/storage/bloom"
 	"github.com/grafana/loki/pkg/storage/chunk"
 	"github.com/grafana/loki/pkg/tenant"
 	"github.com/grafana/loki/pkg/validation"
@@ -66,6 +70,7 @@ type fullWAL struct{}
 func (fullWAL) Log(_ *WALRecord) error { return &os.PathError{Err: syscall.ENOSPC} }
 func (fullWAL) Start()                 {}
 func (fullWAL) Stop() error            { return nil }
+func (fullWAL) Checkpoint()            {}
 
 func Benchmark_FlushLoop(b *testing.B) {
 	var (
@@ -241,6 +246,92 @@ func TestFlushMaxAge(t *testing.T) {
 	require.NoError(t, services.StopAndAwaitTerminated(context.Background(), ing))
 }
 
+type checkpointRecorderWAL struct {
+	noopWAL
+	checkpoints int
+}
+
+func (w *checkpointRecorderWAL) Checkpoint() { w.checkpoints++ }
+
+func TestFlushStreamsHandler(t *testing.T) {
+	wal := &checkpointRecorderWAL{}
+	store, ing := newTestStore(t, defaultIngesterTestConfig(t), wal)
+	defer store.Stop()
+
+	now := time.Unix(0, 0)
+	req := &logproto.PushRequest{Streams: []logproto.Stream{
+		{Labels: model.LabelSet{"app": "l", "env": "prod"}.String(), Entries: entries(5, now)},
+		{Labels: model.LabelSet{"app": "l", "env": "dev"}.String(), Entries: entries(5, now)},
+		{Labels: model.LabelSet{"app": "m", "env": "prod"}.String(), Entries: entries(5, now)},
+	}}
+	for _, userID := range []string{"user1", "user2"} {
+		_, err := ing.Push(user.InjectOrgID(context.Background(), userID), req)
+		require.NoError(t, err)
+	}
+
+	for _, tc := range []struct {
+		query        string
+		expectedCode int
+	}{
+		{query: "", expectedCode: http.StatusBadRequest},
+		{query: "tenant=unknown", expectedCode: http.StatusNotFound},
+		{query: "tenant=user1&match=app", expectedCode: http.StatusBadRequest},
+		{query: "tenant=user1&forget=maybe", expectedCode: http.StatusBadRequest},
+	} {
+		rec := httptest.NewRecorder()
+		ing.FlushStreamsHandler(rec, httptest.NewRequest(http.MethodPost, "/ingester/flush?"+tc.query, nil))
+		require.Equal(t, tc.expectedCode, rec.Code, tc.query)
+	}
+	require.Empty(t, store.getChunksForUser("user1"))
+
+	// flush the matching streams of user1 without forgetting them.
+	rec := httptest.NewRecorder()
+	ing.FlushStreamsHandler(rec, httptest.NewRequest(http.MethodPost, `/ingester/flush?tenant=user1&match={app="l"}`, nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	dec := json.NewDecoder(rec.Body)
+	for j := 0; j < 2; j++ {
+		var progress StreamFlushProgress
+		require.NoError(t, dec.Decode(&progress))
+		require.Equal(t, 1, progress.FlushedChunks)
+		require.False(t, progress.Forgotten)
+		require.Empty(t, progress.Error)
+	}
+	var summary StreamFlushSummary
+	require.NoError(t, dec.Decode(&summary))
+	require.Equal(t, StreamFlushSummary{Done: true, Streams: 2, FlushedChunks: 2}, summary)
+
+	require.Len(t, store.getChunksForUser("user1"), 2)
+	require.Empty(t, store.getChunksForUser("user2"))
+	inst, ok := ing.getInstanceByID("user1")
+	require.True(t, ok)
+	require.Equal(t, 3, inst.numStreams())
+	require.Equal(t, 0, wal.checkpoints)
+
+	// flush and forget all streams of user1, already flushed chunks must not be flushed again.
+	rec = httptest.NewRecorder()
+	ing.FlushStreamsHandler(rec, httptest.NewRequest(http.MethodPost, "/ingester/flush?tenant=user1&forget=true", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	dec = json.NewDecoder(rec.Body)
+	for j := 0; j < 3; j++ {
+		var progress StreamFlushProgress
+		require.NoError(t, dec.Decode(&progress))
+		require.True(t, progress.Forgotten)
+	}
+	summary = StreamFlushSummary{}
+	require.NoError(t, dec.Decode(&summary))
+	require.Equal(t, StreamFlushSummary{Done: true, Streams: 3, FlushedChunks: 1, ForgottenStreams: 3}, summary)
+
+	require.Len(t, store.getChunksForUser("user1"), 3)
+	require.Equal(t, 0, inst.numStreams())
+	require.Equal(t, 1, wal.checkpoints)
+
+	inst, ok = ing.getInstanceByID("user2")
+	require.True(t, ok)
+	require.Equal(t, 3, inst.numStreams())
+}
+
 type testStore struct {
 	mtx sync.Mutex
 	// Chunks keyed by userID.
@@ -346,6 +437,8 @@ func (s *testStore) Stop() {}
 
 func (s *testStore) SetChunkFilterer(_ storage.RequestChunkFilterer) {}
 
+func (s *testStore) SetBloomStore(_ *bloom.Store) {}
+
 func pushTestSamples(t *testing.T, ing logproto.PusherServer) map[string][]logproto.Stream {
 	userIDs := []string{"1", "2", "3"}
 
@@ -11,6 +11,7 @@ import (
 	"time"
 
 	"github.com/cortexproject/cortex/pkg/util"
+	"github.com/dustin/go-humanize"
 	"github.com/go-kit/log/level"
 	"github.com/grafana/dskit/ring"
 	"github.com/grafana/dskit/services"
@@ -19,6 +20,7 @@ import (
 	"github.com/prometheus/client_golang/prometheus/promauto"
 	"github.com/prometheus/common/model"
 	"github.com/prometheus/prometheus/model/labels"
+	"github.com/weaveworks/common/httpgrpc"
 	"google.golang.org/grpc/health/grpc_health_v1"
 
 	"github.com/grafana/loki/pkg/chunkenc"
@@ -31,7 +33,6 @@ import (
 	"github.com/grafana/loki/pkg/runtime"
 	"github.com/grafana/loki/pkg/storage"
 	"github.com/grafana/loki/pkg/storage/chunk"
-	"github.com/grafana/loki/pkg/storage/stores/shipper"
 	"github.com/grafana/loki/pkg/tenant"
 	errUtil "github.com/grafana/loki/pkg/util"
 	util_log "github.com/grafana/loki/pkg/util/log"
@@ -91,12 +92,15 @@ type Config struct {
 	Wrapper Wrapper `yaml:"-"`
 
 	IndexShards int `yaml:"index_shards"`
+
+	Patterns PatternsConfig `yaml:"patterns"`
 }
 
 // RegisterFlags registers the flags.
 func (cfg *Config) RegisterFlags(f *flag.FlagSet) {
 	cfg.LifecyclerConfig.RegisterFlags(f)
 	cfg.WAL.RegisterFlags(f)
+	cfg.Patterns.RegisterFlags(f)
 
 	f.IntVar(&cfg.MaxTransferRetries, "ingester.max-transfer-retries", 0, "Number of times to try and transfer chunks before falling back to flushing. If set to 0 or negative value, transfers are disabled.")
 	f.IntVar(&cfg.ConcurrentFlushes, "ingester.concurrent-flushes", 32, "")
@@ -127,6 +131,10 @@ func (cfg *Config) Validate() error {
 		return err
 	}
 
+	if err = cfg.Patterns.Validate(); err != nil {
+		return err
+	}
+
 	if cfg.MaxTransferRetries > 0 && cfg.WAL.Enabled {
 		return errors.New("the use of the write ahead log (WAL) is incompatible with chunk transfers. It's suggested to use the WAL. Please try setting ingester.max-transfer-retries to 0 to disable transfers")
 	}
@@ -135,6 +143,10 @@ func (cfg *Config) Validate() error {
 		return fmt.Errorf("invalid ingester index shard factor: %d", cfg.IndexShards)
 	}
 
+	if cfg.LifecyclerConfig.RingConfig.ZoneAwarenessEnabled && cfg.LifecyclerConfig.Zone == "" {
+		return errors.New("zone-aware replication is enabled but the ingester has no availability zone configured, please set ingester.availability-zone")
+	}
+
 	return nil
 }
 
@@ -163,9 +175,12 @@ type Interface interface {
 	logproto.IngesterServer
 	logproto.PusherServer
 	logproto.QuerierServer
+	logproto.PatternServer
 	CheckReady(ctx context.Context) error
 	FlushHandler(w http.ResponseWriter, _ *http.Request)
+	FlushStreamsHandler(w http.ResponseWriter, r *http.Request)
 	ShutdownHandler(w http.ResponseWriter, r *http.Request)
+	StreamsHandler(w http.ResponseWriter, r *http.Request)
 	GetOrCreateInstance(instanceID string) *instance
 }
 
@@ -209,6 +224,8 @@ type Ingester struct {
 	metrics *ingesterMetrics
 
 	wal WAL
+	// Only set when the WAL is shipped to the object store on shutdown.
+	walShipper *walShipper
 
 	chunkFilter storage.RequestChunkFilterer
 	labelFilter LabelValueFilterer
@@ -247,6 +264,22 @@ func New(cfg Config, clientConfig client.Config, store ChunkStore, limits *valid
 
 			return nil, fmt.Errorf("creating WAL folder at %q: %w", path, err)
 		}
+
+		if cfg.WAL.Shipping.Enabled {
+			shipper, err := newWALShipper(cfg.WAL, cfg.LifecyclerConfig.ID, metrics)
+			if err != nil {
+				return nil, err
+			}
+			i.walShipper = shipper
+
+			// The shipped WAL has to be in place before the WAL is opened, otherwise segment indexes would clash.
+			ctx, cancel := context.WithTimeout(context.Background(), cfg.WAL.Shipping.Timeout)
+			_, err = shipper.Download(ctx)
+			cancel()
+			if err != nil {
+				return nil, fmt.Errorf("downloading shipped WAL: %w", err)
+			}
+		}
 	}
 
 	wal, err := newWAL(cfg.WAL, registerer, metrics, newIngesterSeriesIter(i))
@@ -441,6 +474,16 @@ func (i *Ingester) starting(ctx context.Context) error {
 
 		endReplay()
 
+		if i.walShipper != nil {
+			// The local WAL directory is authoritative after a successful replay, any shipped WAL
+			// has either been downloaded into it or is outdated.
+			deleteCtx, cancel := context.WithTimeout(ctx, i.cfg.WAL.Shipping.Timeout)
+			if err := i.walShipper.Delete(deleteCtx); err != nil {
+				level.Warn(util_log.Logger).Log("msg", "failed to delete shipped WAL from the object store", "err", err)
+			}
+			cancel()
+		}
+
 		i.wal.Start()
 	}
 
@@ -494,6 +537,16 @@ func (i *Ingester) stopping(_ error) error {
 	if i.flushOnShutdownSwitch.Get() {
 		i.lifecycler.SetFlushOnShutdown(true)
 	}
+
+	// There is no need to ship the WAL if all chunks are flushed anyway.
+	if i.walShipper != nil && !i.lifecycler.FlushOnShutdown() {
+		ctx, cancel := context.WithTimeout(context.Background(), i.cfg.WAL.Shipping.Timeout)
+		if err := i.walShipper.Upload(ctx); err != nil {
+			level.Error(util_log.Logger).Log("msg", "failed to ship WAL, flushing chunks instead", "err", err)
+			i.lifecycler.SetFlushOnShutdown(true)
+		}
+		cancel()
+	}
 	errs.Add(services.StopAndAwaitTerminated(context.Background(), i.lifecycler))
 
 	// Normally, flushers are stopped via lifecycler (in transferOut), but if lifecycler fails,
@@ -578,7 +631,9 @@ func (i *Ingester) Query(req *logproto.QueryRequest, queryServer logproto.Querie
 	instance := i.GetOrCreateInstance(instanceID)
 	itrs, err := instance.Query(ctx, logql.SelectLogParams{QueryRequest: req})
 	if err != nil {
-		return err
+		return i.queryError(ctx, err, func(s stats.Ingester) error {
+			return queryServer.Send(&logproto.QueryResponse{Stats: s})
+		})
 	}
 
 	if start, end, ok := buildStoreRequest(i.cfg, req.Start, req.End, time.Now()); ok {
@@ -602,7 +657,10 @@ func (i *Ingester) Query(req *logproto.QueryRequest, queryServer logproto.Querie
 
 	defer errUtil.LogErrorWithContext(ctx, "closing iterator", heapItr.Close)
 
-	return sendBatches(ctx, heapItr, queryServer, req.Limit)
+	err = sendBatches(ctx, heapItr, queryServer, req.Limit)
+	return i.queryError(ctx, err, func(s stats.Ingester) error {
+		return queryServer.Send(&logproto.QueryResponse{Stats: s})
+	})
 }
 
 // QuerySample the ingesters for series from logs matching a set of matchers.
@@ -618,7 +676,9 @@ func (i *Ingester) QuerySample(req *logproto.SampleQueryRequest, queryServer log
 	instance := i.GetOrCreateInstance(instanceID)
 	itrs, err := instance.QuerySample(ctx, logql.SelectSampleParams{SampleQueryRequest: req})
 	if err != nil {
-		return err
+		return i.queryError(ctx, err, func(s stats.Ingester) error {
+			return queryServer.Send(&logproto.SampleQueryResponse{Stats: s})
+		})
 	}
 
 	if start, end, ok := buildStoreRequest(i.cfg, req.Start, req.End, time.Now()); ok {
@@ -640,21 +700,40 @@ func (i *Ingester) QuerySample(req *logproto.SampleQueryRequest, queryServer log
 
 	defer errUtil.LogErrorWithContext(ctx, "closing iterator", heapItr.Close)
 
-	return sendSampleBatches(ctx, heapItr, queryServer)
+	err = sendSampleBatches(ctx, heapItr, queryServer)
+	return i.queryError(ctx, err, func(s stats.Ingester) error {
+		return queryServer.Send(&logproto.SampleQueryResponse{Stats: s})
+	})
+}
+
+// queryError turns a query exceeding the bytes limit of its tenant into a client error.
+// The statistics of the query are sent first, so the processed bytes are still reported to the querier.
+func (i *Ingester) queryError(ctx context.Context, err error, sendStats func(stats.Ingester) error) error {
+	if !errors.Is(err, stats.ErrBytesLimit) {
+		return err
+	}
+	i.metrics.queryBytesLimitedTotal.Inc()
+
+	statsCtx := stats.FromContext(ctx)
+	if sendErr := sendStats(statsCtx.Ingester()); sendErr != nil {
+		level.Warn(util_log.WithContext(ctx, util_log.Logger)).Log("msg", "failed to send query statistics", "err", sendErr)
+	}
+	return httpgrpc.Errorf(http.StatusBadRequest, "the query processed %s in the ingester, more than the limit of %s (max_ingester_query_bytes), add more label matchers or reduce the time range of the query",
+		humanize.Bytes(uint64(statsCtx.ProcessedBytes())), humanize.Bytes(uint64(statsCtx.BytesLimit())))
 }
 
-// boltdbShipperMaxLookBack returns a max look back period only if active index type is boltdb-shipper.
-// max look back is limited to from time of boltdb-shipper config.
-// It considers previous periodic config's from time if that also has index type set to boltdb-shipper.
+// boltdbShipperMaxLookBack returns a max look back period only if active index type is boltdb-shipper or tsdb.
+// max look back is limited to from time of boltdb-shipper or tsdb config.
+// It considers previous periodic config's from time if that also has index type set to boltdb-shipper or tsdb.
 func (i *Ingester) boltdbShipperMaxLookBack() time.Duration {
 	activePeriodicConfigIndex := storage.ActivePeriodConfig(i.periodicConfigs)
 	activePeriodicConfig := i.periodicConfigs[activePeriodicConfigIndex]
-	if activePeriodicConfig.IndexType != shipper.BoltDBShipperType {
+	if !storage.IsObjectStorageIndex(activePeriodicConfig.IndexType) {
 		return 0
 	}
 
 	startTime := activePeriodicConfig.From
-	if activePeriodicConfigIndex != 0 && i.periodicConfigs[activePeriodicConfigIndex-1].IndexType == shipper.BoltDBShipperType {
+	if activePeriodicConfigIndex != 0 && storage.IsObjectStorageIndex(i.periodicConfigs[activePeriodicConfigIndex-1].IndexType) {
 		startTime = i.periodicConfigs[activePeriodicConfigIndex-1].From
 	}
 
@@ -713,8 +792,16 @@ func (i *Ingester) Label(ctx context.Context, req *logproto.LabelRequest) (*logp
 		return nil, err
 	}
 
+	var matchers []*labels.Matcher
+	if req.Query != "" {
+		matchers, err = logql.ParseMatchers(req.Query)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	instance := i.GetOrCreateInstance(userID)
-	resp, err := instance.Label(ctx, req)
+	resp, err := instance.Label(ctx, req, matchers...)
 	if err != nil {
 		return nil, err
 	}
@@ -749,12 +836,12 @@ func (i *Ingester) Label(ctx context.Context, req *logproto.LabelRequest) (*logp
 	from, through := model.TimeFromUnixNano(start.UnixNano()), model.TimeFromUnixNano(req.End.UnixNano())
 	var storeValues []string
 	if req.Values {
-		storeValues, err = cs.LabelValuesForMetricName(ctx, userID, from, through, "logs", req.Name)
+		storeValues, err = cs.LabelValuesForMetricName(ctx, userID, from, through, "logs", req.Name, matchers...)
 		if err != nil {
 			return nil, err
 		}
 	} else {
-		storeValues, err = cs.LabelNamesForMetricName(ctx, userID, from, through, "logs")
+		storeValues, err = cs.LabelNamesForMetricName(ctx, userID, from, through, "logs", matchers...)
 		if err != nil {
 			return nil, err
 		}
@@ -844,7 +931,7 @@ func (i *Ingester) Tail(req *logproto.TailRequest, queryServer logproto.Querier_
 	}
 
 	instance := i.GetOrCreateInstance(instanceID)
-	tailer, err := newTailer(instanceID, req.Query, queryServer)
+	tailer, err := newTailer(instanceID, req.Query, queryServer, i.limiter.MaxTailBytes(instanceID))
 	if err != nil {
 		return err
 	}
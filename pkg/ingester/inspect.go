@@ -0,0 +1,162 @@
+package ingester
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/go-kit/log/level"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/weaveworks/common/user"
+
+	"github.com/grafana/loki/pkg/logql"
+	util_log "github.com/grafana/loki/pkg/util/log"
+	serverutil "github.com/grafana/loki/pkg/util/server"
+)
+
+// TenantStreamsStatus describes the streams held in memory by the ingester for a single tenant.
+type TenantStreamsStatus struct {
+	Tenant string `json:"tenant"`
+	// Streams is the total number of in-memory streams of the tenant, regardless of the given matchers.
+	Streams int `json:"streams"`
+	// MemoryBytes is the uncompressed size of all in-memory chunks of the matched streams.
+	MemoryBytes int64 `json:"memory_bytes"`
+	// WALBytes is the size the matched streams would take in a WAL checkpoint.
+	WALBytes int64          `json:"wal_bytes"`
+	Matched  []StreamStatus `json:"matched_streams"`
+}
+
+// StreamStatus describes a single in-memory stream.
+type StreamStatus struct {
+	Labels          string    `json:"labels"`
+	Fingerprint     string    `json:"fingerprint"`
+	Chunks          int       `json:"chunks"`
+	FlushedChunks   int       `json:"flushed_chunks"`
+	HeadBlockFormat string    `json:"head_block_format"`
+	Bytes           int64     `json:"bytes"`
+	Entries         int64     `json:"entries"`
+	LastPush        time.Time `json:"last_push"`
+	RateLimit       float64   `json:"rate_limit_bytes"`
+	RateBurst       int       `json:"rate_burst_bytes"`
+	Tailers         int       `json:"tailers"`
+}
+
+// StreamsHandler lists the in-memory streams of the ingester.
+// The optional `tenant` parameter restricts the result to a single tenant and the
+// optional `match` parameter restricts the listed streams to a LogQL stream selector.
+func (i *Ingester) StreamsHandler(w http.ResponseWriter, r *http.Request) {
+	params := r.URL.Query()
+
+	var matchers []*labels.Matcher
+	if match := params.Get("match"); match != "" {
+		var err error
+		matchers, err = logql.ParseMatchers(match)
+		if err != nil {
+			serverutil.JSONError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+
+	var instances []*instance
+	if tenantID := params.Get("tenant"); tenantID != "" {
+		inst, ok := i.getInstanceByID(tenantID)
+		if !ok {
+			serverutil.JSONError(w, http.StatusNotFound, "no streams found for tenant %s", tenantID)
+			return
+		}
+		instances = append(instances, inst)
+	} else {
+		instances = i.getInstances()
+	}
+	sort.Slice(instances, func(a, b int) bool { return instances[a].instanceID < instances[b].instanceID })
+
+	result := make([]TenantStreamsStatus, 0, len(instances))
+	for _, inst := range instances {
+		status, err := inst.streamsStatus(r.Context(), matchers)
+		if err != nil {
+			serverutil.JSONError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		result = append(result, status)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(result); err != nil {
+		level.Error(util_log.Logger).Log("msg", "error marshalling response", "err", err)
+	}
+}
+
+// streamsStatus returns the status of the streams matching the given matchers.
+// All streams are returned when no matchers are given.
+func (i *instance) streamsStatus(ctx context.Context, matchers []*labels.Matcher) (TenantStreamsStatus, error) {
+	status := TenantStreamsStatus{
+		Tenant:  i.instanceID,
+		Streams: i.numStreams(),
+		Matched: []StreamStatus{},
+	}
+
+	ctx = user.InjectOrgID(ctx, i.instanceID)
+	err := i.forMatchingStreams(ctx, matchers, nil, func(s *stream) error {
+		st, walBytes := s.status()
+		status.MemoryBytes += st.Bytes
+		status.WALBytes += walBytes
+		status.Matched = append(status.Matched, st)
+		return nil
+	})
+	if err != nil {
+		return TenantStreamsStatus{}, err
+	}
+
+	sort.Slice(status.Matched, func(a, b int) bool { return status.Matched[a].Labels < status.Matched[b].Labels })
+	return status, nil
+}
+
+// status returns the status of the stream alongside the size of its chunks in a WAL checkpoint.
+func (s *stream) status() (StreamStatus, int64) {
+	s.tailerMtx.RLock()
+	tailers := len(s.tailers)
+	s.tailerMtx.RUnlock()
+
+	s.chunkMtx.RLock()
+	defer s.chunkMtx.RUnlock()
+
+	limit, burst := s.limiter.Limit()
+	st := StreamStatus{
+		Labels:          s.labelsString,
+		Fingerprint:     s.fp.String(),
+		Chunks:          len(s.chunks),
+		HeadBlockFormat: headBlockType(s.unorderedWrites, s.structuredMetadata).String(),
+		RateLimit:       float64(limit),
+		RateBurst:       burst,
+		Tailers:         tailers,
+	}
+	if math.IsInf(st.RateLimit, 0) {
+		// JSON cannot represent infinity, zero means unlimited.
+		st.RateLimit = 0
+	}
+
+	var walBytes int64
+	for _, c := range s.chunks {
+		if !c.flushed.IsZero() {
+			st.FlushedChunks++
+		}
+		if c.lastUpdated.After(st.LastPush) {
+			st.LastPush = c.lastUpdated
+		}
+		if c.chunk == nil {
+			continue
+		}
+		st.Bytes += int64(c.chunk.UncompressedSize())
+		st.Entries += int64(c.chunk.Size())
+		chk, head := c.chunk.CheckpointSize()
+		walBytes += int64(chk + head)
+	}
+	if n := len(s.chunks); n > 0 && s.chunks[n-1].chunk != nil {
+		st.HeadBlockFormat = s.chunks[n-1].chunk.HeadFormat().String()
+	}
+
+	return st, walBytes
+}
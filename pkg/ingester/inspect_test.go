@@ -0,0 +1,114 @@
+package ingester
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/grafana/dskit/services"
+	"github.com/stretchr/testify/require"
+	"github.com/weaveworks/common/user"
+	"golang.org/x/net/context"
+
+	"github.com/grafana/loki/pkg/ingester/client"
+	"github.com/grafana/loki/pkg/logproto"
+	"github.com/grafana/loki/pkg/runtime"
+	"github.com/grafana/loki/pkg/storage/chunk"
+	"github.com/grafana/loki/pkg/validation"
+)
+
+func TestStreamsHandler(t *testing.T) {
+	ingesterConfig := defaultIngesterTestConfig(t)
+	limits, err := validation.NewOverrides(defaultLimitsTestConfig(), nil)
+	require.NoError(t, err)
+
+	store := &mockStore{
+		chunks: map[string][]chunk.Chunk{},
+	}
+
+	i, err := New(ingesterConfig, client.Config{}, store, limits, runtime.DefaultTenantConfigs(), nil)
+	require.NoError(t, err)
+	defer services.StopAndAwaitTerminated(context.Background(), i) //nolint:errcheck
+
+	req := logproto.PushRequest{
+		Streams: []logproto.Stream{
+			{Labels: `{foo="bar",bar="baz1"}`},
+			{Labels: `{foo="bar",bar="baz2"}`},
+			{Labels: `{foo="other"}`},
+		},
+	}
+	for j := 0; j < 10; j++ {
+		for k := range req.Streams {
+			req.Streams[k].Entries = append(req.Streams[k].Entries, logproto.Entry{
+				Timestamp: time.Unix(0, int64(j)),
+				Line:      fmt.Sprintf("line %d", j),
+			})
+		}
+	}
+	for _, tenantID := range []string{"test", "other"} {
+		_, err = i.Push(user.InjectOrgID(context.Background(), tenantID), &req)
+		require.NoError(t, err)
+	}
+
+	for _, tc := range []struct {
+		name            string
+		query           string
+		expectedCode    int
+		expectedTenants []string
+		expectedStreams int
+	}{
+		{
+			name:            "all tenants",
+			query:           "",
+			expectedCode:    http.StatusOK,
+			expectedTenants: []string{"other", "test"},
+			expectedStreams: 3,
+		},
+		{
+			name:            "single tenant with matcher",
+			query:           `tenant=test&match={foo="bar"}`,
+			expectedCode:    http.StatusOK,
+			expectedTenants: []string{"test"},
+			expectedStreams: 2,
+		},
+		{
+			name:         "unknown tenant",
+			query:        "tenant=unknown",
+			expectedCode: http.StatusNotFound,
+		},
+		{
+			name:         "invalid matcher",
+			query:        "match=foo",
+			expectedCode: http.StatusBadRequest,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			i.StreamsHandler(rec, httptest.NewRequest(http.MethodGet, "/ingester/streams?"+tc.query, nil))
+			require.Equal(t, tc.expectedCode, rec.Code)
+			if tc.expectedCode != http.StatusOK {
+				return
+			}
+
+			var resp []TenantStreamsStatus
+			require.NoError(t, json.NewDecoder(rec.Body).Decode(&resp))
+			require.Len(t, resp, len(tc.expectedTenants))
+			for j, status := range resp {
+				require.Equal(t, tc.expectedTenants[j], status.Tenant)
+				require.Equal(t, 3, status.Streams)
+				require.Len(t, status.Matched, tc.expectedStreams)
+				require.Greater(t, status.MemoryBytes, int64(0))
+				require.Greater(t, status.WALBytes, int64(0))
+				for _, s := range status.Matched {
+					require.Equal(t, 1, s.Chunks)
+					require.Equal(t, int64(10), s.Entries)
+					require.Equal(t, "unordered", s.HeadBlockFormat)
+					require.False(t, s.LastPush.IsZero())
+				}
+			}
+		})
+	}
+}
@@ -27,6 +27,7 @@ import (
 	"github.com/grafana/loki/pkg/querier/astmapper"
 	"github.com/grafana/loki/pkg/runtime"
 	"github.com/grafana/loki/pkg/storage"
+	"github.com/grafana/loki/pkg/storage/chunk"
 	util_log "github.com/grafana/loki/pkg/util/log"
 	"github.com/grafana/loki/pkg/util/math"
 	"github.com/grafana/loki/pkg/validation"
@@ -69,6 +70,9 @@ type instance struct {
 	streamsCreatedTotal prometheus.Counter
 	streamsRemovedTotal prometheus.Counter
 
+	// patternStreams is the number of streams detecting patterns, bounded by the max_pattern_streams_per_user limit.
+	patternStreams *atomic.Int64
+
 	tailers   map[uint32]*tailer
 	tailerMtx sync.RWMutex
 
@@ -96,6 +100,7 @@ func newInstance(cfg *Config, instanceID string, limiter *Limiter, configs *runt
 
 		streamsCreatedTotal: streamsCreatedTotal.WithLabelValues(instanceID),
 		streamsRemovedTotal: streamsRemovedTotal.WithLabelValues(instanceID),
+		patternStreams:      atomic.NewInt64(0),
 
 		tailers: map[uint32]*tailer{},
 		limiter: limiter,
@@ -118,7 +123,9 @@ func (i *instance) consumeChunk(ctx context.Context, ls labels.Labels, chunk *lo
 
 	s, loaded, _ := i.streams.LoadOrStoreNewByFP(fp, func() (*stream, error) {
 		sortedLabels := i.index.Add(logproto.FromLabelsToLabelAdapters(ls), fp)
-		return newStream(i.cfg, i.limiter, i.instanceID, fp, sortedLabels, i.limiter.UnorderedWrites(i.instanceID), i.metrics), nil
+		s := newStream(i.cfg, i.limiter, i.instanceID, fp, sortedLabels, i.limiter.UnorderedWrites(i.instanceID), i.limiter.AllowStructuredMetadata(i.instanceID), i.metrics)
+		i.limitPatterns(s)
+		return s, nil
 	})
 	if !loaded {
 		i.streamsCreatedTotal.Inc()
@@ -213,7 +220,8 @@ func (i *instance) createStream(pushReqStream logproto.Stream, record *WALRecord
 	fp := i.getHashForLabels(labels)
 
 	sortedLabels := i.index.Add(logproto.FromLabelsToLabelAdapters(labels), fp)
-	s := newStream(i.cfg, i.limiter, i.instanceID, fp, sortedLabels, i.limiter.UnorderedWrites(i.instanceID), i.metrics)
+	s := newStream(i.cfg, i.limiter, i.instanceID, fp, sortedLabels, i.limiter.UnorderedWrites(i.instanceID), i.limiter.AllowStructuredMetadata(i.instanceID), i.metrics)
+	i.limitPatterns(s)
 
 	// record will be nil when replaying the wal (we don't want to rewrite wal entries as we replay them).
 	if record != nil {
@@ -303,7 +311,23 @@ func (i *instance) removeStream(s *stream) {
 		i.index.Delete(s.labels, s.fp)
 		i.streamsRemovedTotal.Inc()
 		memoryStreams.WithLabelValues(i.instanceID).Dec()
+		if s.patterns != nil {
+			i.patternStreams.Dec()
+		}
+	}
+}
+
+// limitPatterns counts a new stream detecting patterns, or drops its patterns when the tenant already reached
+// its limit of streams detecting patterns. It is called while the stream is stored, under the lock of the streams.
+func (i *instance) limitPatterns(s *stream) {
+	if s.patterns == nil {
+		return
+	}
+	if limit := i.limiter.MaxPatternStreams(i.instanceID); limit > 0 && i.patternStreams.Load() >= int64(limit) {
+		s.patterns = nil
+		return
 	}
+	i.patternStreams.Inc()
 }
 
 func (i *instance) getHashForLabels(ls labels.Labels) model.Fingerprint {
@@ -332,6 +356,7 @@ func (i *instance) Query(ctx context.Context, req logql.SelectLogParams) ([]iter
 	}
 
 	stats := stats.FromContext(ctx)
+	stats.SetBytesLimit(int64(i.limiter.MaxQueryBytes(i.instanceID)))
 	var iters []iter.EntryIterator
 
 	shard, err := parseShardFromRequest(req.Shards)
@@ -349,7 +374,8 @@ func (i *instance) Query(ctx context.Context, req logql.SelectLogParams) ([]iter
 				return err
 			}
 			iters = append(iters, iter)
-			return nil
+			// head blocks are processed while creating the iterator.
+			return stats.BytesLimitErr()
 		},
 	)
 	if err != nil {
@@ -370,6 +396,7 @@ func (i *instance) QuerySample(ctx context.Context, req logql.SelectSampleParams
 	}
 
 	stats := stats.FromContext(ctx)
+	stats.SetBytesLimit(int64(i.limiter.MaxQueryBytes(i.instanceID)))
 	var iters []iter.SampleIterator
 
 	var shard *astmapper.ShardAnnotation
@@ -394,7 +421,8 @@ func (i *instance) QuerySample(ctx context.Context, req logql.SelectSampleParams
 				return err
 			}
 			iters = append(iters, iter)
-			return nil
+			// head blocks are processed while creating the iterator.
+			return stats.BytesLimitErr()
 		},
 	)
 	if err != nil {
@@ -437,15 +465,15 @@ func (i *instance) Label(ctx context.Context, req *logproto.LabelRequest, matche
 		}, nil
 	}
 
-	labels := make([]string, 0)
+	var labels chunk.UniqueStrings
 	err := i.forMatchingStreams(ctx, matchers, nil, func(s *stream) error {
 		for _, label := range s.labels {
 			if req.Values && label.Name == req.Name {
-				labels = append(labels, label.Value)
+				labels.Add(label.Value)
 				continue
 			}
 			if !req.Values {
-				labels = append(labels, label.Name)
+				labels.Add(label.Name)
 			}
 		}
 		return nil
@@ -455,7 +483,7 @@ func (i *instance) Label(ctx context.Context, req *logproto.LabelRequest, matche
 	}
 
 	return &logproto.LabelResponse{
-		Values: labels,
+		Values: labels.Strings(),
 	}, nil
 }
 
@@ -19,6 +19,7 @@ import (
 	"github.com/grafana/loki/pkg/iter"
 	"github.com/grafana/loki/pkg/logproto"
 	"github.com/grafana/loki/pkg/logql"
+	"github.com/grafana/loki/pkg/logqlmodel/stats"
 	loki_runtime "github.com/grafana/loki/pkg/runtime"
 	"github.com/grafana/loki/pkg/storage"
 	"github.com/grafana/loki/pkg/validation"
@@ -183,7 +184,7 @@ func setupTestStreams(t *testing.T) (*instance, time.Time, int) {
 	for _, testStream := range testStreams {
 		stream, err := instance.getOrCreateStream(testStream, recordPool.GetRecord())
 		require.NoError(t, err)
-		chunk := newStream(cfg, limiter, "fake", 0, nil, true, NilMetrics).NewChunk()
+		chunk := newStream(cfg, limiter, "fake", 0, nil, true, false, NilMetrics).NewChunk()
 		for _, entry := range testStream.Entries {
 			err = chunk.Append(&entry)
 			require.NoError(t, err)
@@ -200,6 +201,8 @@ func Test_LabelQuery(t *testing.T) {
 	end := &[]time.Time{currentTime.Add(12 * time.Nanosecond)}[0]
 	m, err := labels.NewMatcher(labels.MatchEqual, "app", "test")
 	require.NoError(t, err)
+	jobMatcher, err := labels.NewMatcher(labels.MatchEqual, "job", "varlogs")
+	require.NoError(t, err)
 
 	tests := []struct {
 		name             string
@@ -255,6 +258,30 @@ func Test_LabelQuery(t *testing.T) {
 			},
 			[]*labels.Matcher{m},
 		},
+		{
+			"label names - with matcher matching several streams",
+			&logproto.LabelRequest{
+				Start: start,
+				End:   end,
+			},
+			logproto.LabelResponse{
+				Values: []string{"app", "job"},
+			},
+			[]*labels.Matcher{jobMatcher},
+		},
+		{
+			"label values - with matcher matching several streams",
+			&logproto.LabelRequest{
+				Name:   "app",
+				Values: true,
+				Start:  start,
+				End:    end,
+			},
+			logproto.LabelResponse{
+				Values: []string{"test", "test2"},
+			},
+			[]*labels.Matcher{jobMatcher},
+		},
 	}
 
 	for _, tc := range tests {
@@ -421,7 +448,7 @@ func Benchmark_instance_addNewTailer(b *testing.B) {
 	ctx := context.Background()
 
 	inst := newInstance(&Config{}, "test", limiter, loki_runtime.DefaultTenantConfigs(), noopWAL{}, NilMetrics, &OnceSwitch{}, nil)
-	t, err := newTailer("foo", `{namespace="foo",pod="bar",instance=~"10.*"}`, nil)
+	t, err := newTailer("foo", `{namespace="foo",pod="bar",instance=~"10.*"}`, nil, 0)
 	require.NoError(b, err)
 	for i := 0; i < 10000; i++ {
 		require.NoError(b, inst.Push(ctx, &logproto.PushRequest{
@@ -436,7 +463,7 @@ func Benchmark_instance_addNewTailer(b *testing.B) {
 	lbs := makeRandomLabels()
 	b.Run("addTailersToNewStream", func(b *testing.B) {
 		for n := 0; n < b.N; n++ {
-			inst.addTailersToNewStream(newStream(nil, limiter, "fake", 0, lbs, true, NilMetrics))
+			inst.addTailersToNewStream(newStream(nil, limiter, "fake", 0, lbs, true, false, NilMetrics))
 		}
 	})
 }
@@ -607,3 +634,140 @@ func (f fakeQueryServer) Send(res *logproto.QueryResponse) error {
 	return f(res)
 }
 func (f fakeQueryServer) Context() context.Context { return context.TODO() }
+
+func Test_QueryBytesLimit(t *testing.T) {
+	ingesterConfig := defaultIngesterTestConfig(t)
+	limits := defaultLimitsTestConfig()
+	require.NoError(t, limits.MaxIngesterQueryBytes.Set("100B"))
+	overrides, err := validation.NewOverrides(limits, nil)
+	require.NoError(t, err)
+	instance := newInstance(&ingesterConfig, "fake", NewLimiter(overrides, NilMetrics, &ringCountMock{count: 1}, 1), loki_runtime.DefaultTenantConfigs(), noopWAL{}, NilMetrics, nil, nil)
+
+	for i := 0; i < 10; i++ {
+		require.NoError(t,
+			instance.Push(context.TODO(), &logproto.PushRequest{
+				Streams: []logproto.Stream{
+					{
+						Labels:  fmt.Sprintf(`{job="3", stream="%d"}`, i),
+						Entries: []logproto.Entry{{Timestamp: time.Unix(0, int64(i)), Line: "a line of exactly 30 bytes...."}},
+					},
+				},
+			}),
+		)
+	}
+
+	statsCtx, ctx := stats.NewContext(context.TODO())
+	_, err = instance.Query(ctx,
+		logql.SelectLogParams{
+			QueryRequest: &logproto.QueryRequest{
+				Selector:  `{job="3"} |= "nothing"`,
+				Limit:     10,
+				Start:     time.Unix(0, 0),
+				End:       time.Unix(0, 100),
+				Direction: logproto.FORWARD,
+			},
+		},
+	)
+	require.ErrorIs(t, err, stats.ErrBytesLimit)
+	require.Equal(t, int64(120), statsCtx.ProcessedBytes())
+
+	_, ctx = stats.NewContext(context.TODO())
+	_, err = instance.QuerySample(ctx,
+		logql.SelectSampleParams{
+			SampleQueryRequest: &logproto.SampleQueryRequest{
+				Selector: `count_over_time({job="3"}[1m])`,
+				Start:    time.Unix(0, 0),
+				End:      time.Unix(0, 100),
+			},
+		},
+	)
+	require.ErrorIs(t, err, stats.ErrBytesLimit)
+}
+
+func Test_QueryPatterns(t *testing.T) {
+	limits, err := validation.NewOverrides(defaultLimitsTestConfig(), nil)
+	require.NoError(t, err)
+	limiter := NewLimiter(limits, NilMetrics, &ringCountMock{count: 1}, 1)
+
+	cfg := defaultConfig()
+	cfg.Patterns = PatternsConfig{Enabled: true, MaxPatternsPerStream: 10, SimilarityThreshold: 0.4, RetainPeriod: time.Hour}
+	inst := newInstance(cfg, "test", limiter, loki_runtime.DefaultTenantConfigs(), noopWAL{}, NilMetrics, &OnceSwitch{}, nil)
+
+	now := time.Unix(3600, 0)
+	require.NoError(t, inst.Push(context.Background(), &logproto.PushRequest{Streams: []logproto.Stream{
+		{
+			Labels: `{app="foo", pod="a"}`,
+			Entries: []logproto.Entry{
+				{Timestamp: now, Line: "GET /api/users took 10ms"},
+				{Timestamp: now.Add(time.Second), Line: "GET /api/orders took 30ms"},
+			},
+		},
+		{
+			Labels: `{app="foo", pod="b"}`,
+			Entries: []logproto.Entry{
+				{Timestamp: now, Line: "GET /api/users took 10ms"},
+			},
+		},
+		{
+			Labels: `{app="bar"}`,
+			Entries: []logproto.Entry{
+				{Timestamp: now, Line: "flushing stream"},
+			},
+		},
+	}}))
+
+	resp, err := inst.QueryPatterns(context.Background(), &logproto.QueryPatternsRequest{
+		Query: `{app="foo"}`,
+		Start: now,
+		End:   now.Add(time.Minute),
+		Step:  time.Minute.Milliseconds(),
+	})
+	require.NoError(t, err)
+	require.ElementsMatch(t, []logproto.PatternSeries{
+		{
+			Pattern: "GET <var1> took <var2>",
+			Samples: []logproto.PatternSample{{Timestamp: now.UnixNano() / 1e6, Value: 2}},
+			Labels:  `{app="foo", pod="a"}`,
+		},
+		{
+			Pattern: "GET /api/users took 10ms<rest>",
+			Samples: []logproto.PatternSample{{Timestamp: now.UnixNano() / 1e6, Value: 1}},
+			Labels:  `{app="foo", pod="b"}`,
+		},
+	}, resp.Series)
+
+	_, err = inst.QueryPatterns(context.Background(), &logproto.QueryPatternsRequest{Query: `{app="foo"} |= "GET"`})
+	require.Error(t, err)
+}
+
+func Test_PatternStreamsLimit(t *testing.T) {
+	limitsCfg := defaultLimitsTestConfig()
+	limitsCfg.MaxPatternStreams = 1
+	limits, err := validation.NewOverrides(limitsCfg, nil)
+	require.NoError(t, err)
+	limiter := NewLimiter(limits, NilMetrics, &ringCountMock{count: 1}, 1)
+
+	cfg := defaultConfig()
+	cfg.Patterns = PatternsConfig{Enabled: true, MaxPatternsPerStream: 10, SimilarityThreshold: 0.4, RetainPeriod: time.Hour}
+	inst := newInstance(cfg, "test", limiter, loki_runtime.DefaultTenantConfigs(), noopWAL{}, NilMetrics, &OnceSwitch{}, nil)
+
+	push := func(ls string) *stream {
+		require.NoError(t, inst.Push(context.Background(), &logproto.PushRequest{Streams: []logproto.Stream{
+			{Labels: ls, Entries: []logproto.Entry{{Timestamp: time.Unix(3600, 0), Line: "GET /api/users took 10ms"}}},
+		}}))
+		s, ok := inst.streams.Load(ls)
+		require.True(t, ok)
+		return s
+	}
+
+	first := push(`{app="foo"}`)
+	require.NotNil(t, first.patterns)
+	require.Nil(t, push(`{app="bar"}`).patterns)
+	require.Equal(t, int64(1), inst.patternStreams.Load())
+
+	// removing the stream detecting patterns frees its slot for the next new stream.
+	inst.removeStream(first)
+	require.Equal(t, int64(0), inst.patternStreams.Load())
+	require.NotNil(t, push(`{app="buzz"}`).patterns)
+	require.Equal(t, int64(1), inst.patternStreams.Load())
+}
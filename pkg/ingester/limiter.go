@@ -67,6 +67,11 @@ func (l *Limiter) UnorderedWrites(userID string) bool {
 	return l.limits.UnorderedWrites(userID)
 }
 
+// AllowStructuredMetadata returns whether the chunks of the user keep the structured metadata of the entries.
+func (l *Limiter) AllowStructuredMetadata(userID string) bool {
+	return l.limits.AllowStructuredMetadata(userID)
+}
+
 // AssertMaxStreamsPerUser ensures limit has not been reached compared to the current
 // number of streams in input and returns an error if so.
 func (l *Limiter) AssertMaxStreamsPerUser(userID string, streams int) error {
@@ -130,6 +135,21 @@ func (l *Limiter) minNonZero(first, second int) int {
 	return first
 }
 
+// MaxQueryBytes returns the maximum number of bytes a query of the tenant may process, 0 means unlimited.
+func (l *Limiter) MaxQueryBytes(tenant string) int {
+	return l.limits.MaxIngesterQueryBytes(tenant)
+}
+
+// MaxTailBytes returns the maximum number of bytes a tailer of the tenant may process per second, 0 means unlimited.
+func (l *Limiter) MaxTailBytes(tenant string) int {
+	return l.limits.MaxIngesterTailBytes(tenant)
+}
+
+// MaxPatternStreams returns the maximum number of streams of the tenant detecting patterns, 0 means unlimited.
+func (l *Limiter) MaxPatternStreams(tenant string) int {
+	return l.limits.MaxPatternStreams(tenant)
+}
+
 type RateLimiterStrategy interface {
 	RateLimit(tenant string) validation.RateLimit
 }
@@ -180,3 +200,8 @@ func (l *StreamRateLimiter) AllowN(at time.Time, n int) bool {
 
 	return l.lim.AllowN(at, n)
 }
+
+// Limit returns the currently applied per-stream rate limit and burst.
+func (l *StreamRateLimiter) Limit() (rate.Limit, int) {
+	return l.lim.Limit(), l.lim.Burst()
+}
@@ -24,6 +24,9 @@ type ingesterMetrics struct {
 	walLoggedBytesTotal     prometheus.Counter
 	walRecordsLogged        prometheus.Counter
 
+	walShippingUploadedBytes   prometheus.Counter
+	walShippingDownloadedBytes prometheus.Counter
+
 	recoveredStreamsTotal prometheus.Counter
 	recoveredChunksTotal  prometheus.Counter
 	recoveredEntriesTotal prometheus.Counter
@@ -34,6 +37,8 @@ type ingesterMetrics struct {
 
 	limiterEnabled prometheus.Gauge
 
+	queryBytesLimitedTotal prometheus.Counter
+
 	autoForgetUnhealthyIngestersTotal prometheus.Counter
 }
 
@@ -104,6 +109,14 @@ func newIngesterMetrics(r prometheus.Registerer) *ingesterMetrics {
 			Name: "loki_ingester_wal_records_logged_total",
 			Help: "Total number of WAL records logged.",
 		}),
+		walShippingUploadedBytes: promauto.With(r).NewCounter(prometheus.CounterOpts{
+			Name: "loki_ingester_wal_shipping_uploaded_bytes_total",
+			Help: "Total number of WAL bytes uploaded to the object store on shutdown.",
+		}),
+		walShippingDownloadedBytes: promauto.With(r).NewCounter(prometheus.CounterOpts{
+			Name: "loki_ingester_wal_shipping_downloaded_bytes_total",
+			Help: "Total number of WAL bytes downloaded from the object store on startup.",
+		}),
 		checkpointLoggedBytesTotal: promauto.With(r).NewCounter(prometheus.CounterOpts{
 			Name: "loki_ingester_checkpoint_logged_bytes_total",
 			Help: "Total number of bytes written to disk for checkpointing.",
@@ -144,6 +157,10 @@ func newIngesterMetrics(r prometheus.Registerer) *ingesterMetrics {
 			Name: "loki_ingester_limiter_enabled",
 			Help: "Whether the ingester's limiter is enabled",
 		}),
+		queryBytesLimitedTotal: promauto.With(r).NewCounter(prometheus.CounterOpts{
+			Name: "loki_ingester_query_bytes_limited_total",
+			Help: "Total number of queries cancelled for processing more bytes than allowed by max_ingester_query_bytes.",
+		}),
 		autoForgetUnhealthyIngestersTotal: promauto.With(r).NewCounter(prometheus.CounterOpts{
 			Name: "loki_ingester_autoforget_unhealthy_ingesters_total",
 			Help: "Total number of ingesters automatically forgotten",
@@ -0,0 +1,88 @@
+package ingester
+
+import (
+	"context"
+	"flag"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/grafana/loki/pkg/logproto"
+	"github.com/grafana/loki/pkg/logql"
+	"github.com/grafana/loki/pkg/pattern/drain"
+	"github.com/grafana/loki/pkg/tenant"
+)
+
+// PatternsConfig configures the detection of the patterns of the lines pushed to the ingester.
+type PatternsConfig struct {
+	Enabled              bool          `yaml:"enabled"`
+	MaxPatternsPerStream int           `yaml:"max_patterns_per_stream"`
+	SimilarityThreshold  float64       `yaml:"similarity_threshold"`
+	RetainPeriod         time.Duration `yaml:"retain_period"`
+}
+
+// RegisterFlags adds the flags required to config this to the given FlagSet
+func (cfg *PatternsConfig) RegisterFlags(f *flag.FlagSet) {
+	defaults := drain.DefaultConfig()
+	f.BoolVar(&cfg.Enabled, "ingester.patterns.enabled", false, "Enable the detection of the patterns of the pushed lines, queried with the /loki/api/v1/patterns endpoint.")
+	f.IntVar(&cfg.MaxPatternsPerStream, "ingester.patterns.max-patterns-per-stream", defaults.MaxClusters, "Maximum number of patterns kept per stream, the least recently seen one being dropped first.")
+	f.Float64Var(&cfg.SimilarityThreshold, "ingester.patterns.similarity-threshold", defaults.SimilarityThreshold, "Minimum ratio of the words a line must share with a pattern to be counted in it, between 0 and 1.")
+	f.DurationVar(&cfg.RetainPeriod, "ingester.patterns.retain-period", 3*time.Hour, "How long the counts of the patterns are kept.")
+}
+
+func (cfg *PatternsConfig) Validate() error {
+	if !cfg.Enabled {
+		return nil
+	}
+	if cfg.MaxPatternsPerStream <= 0 {
+		return errors.Errorf("invalid maximum number of patterns per stream: %d", cfg.MaxPatternsPerStream)
+	}
+	if cfg.SimilarityThreshold <= 0 || cfg.SimilarityThreshold > 1 {
+		return errors.Errorf("invalid pattern similarity threshold: %v", cfg.SimilarityThreshold)
+	}
+	return nil
+}
+
+func (cfg *PatternsConfig) drainConfig() drain.Config {
+	drainCfg := drain.DefaultConfig()
+	drainCfg.MaxClusters = cfg.MaxPatternsPerStream
+	drainCfg.SimilarityThreshold = cfg.SimilarityThreshold
+	return drainCfg
+}
+
+// QueryPatterns returns the patterns of the in-memory streams matching the query.
+func (i *Ingester) QueryPatterns(ctx context.Context, req *logproto.QueryPatternsRequest) (*logproto.QueryPatternsResponse, error) {
+	instanceID, err := tenant.TenantID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	instance := i.GetOrCreateInstance(instanceID)
+	return instance.QueryPatterns(ctx, req)
+}
+
+// QueryPatterns returns the patterns of each stream matching the query, with their counts in each step.
+// The patterns are not merged across streams, for the querier to count the replicas of a stream once.
+func (i *instance) QueryPatterns(ctx context.Context, req *logproto.QueryPatternsRequest) (*logproto.QueryPatternsResponse, error) {
+	matchers, err := logql.ParseMatchers(req.Query)
+	if err != nil {
+		return nil, err
+	}
+	step := time.Duration(req.Step) * time.Millisecond
+
+	resp := &logproto.QueryPatternsResponse{}
+	err = i.forMatchingStreams(ctx, matchers, nil, func(s *stream) error {
+		if s.patterns == nil {
+			return nil
+		}
+		for _, series := range s.patterns.Series(req.Start, req.End, step) {
+			series.Labels = s.labelsString
+			resp.Series = append(resp.Series, series)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
@@ -20,6 +20,7 @@ import (
 	"github.com/grafana/loki/pkg/logproto"
 	"github.com/grafana/loki/pkg/logql/log"
 	"github.com/grafana/loki/pkg/logqlmodel/stats"
+	"github.com/grafana/loki/pkg/pattern/drain"
 	"github.com/grafana/loki/pkg/util/flagext"
 	util_log "github.com/grafana/loki/pkg/util/log"
 	"github.com/grafana/loki/pkg/validation"
@@ -97,6 +98,11 @@ type stream struct {
 	entryCt int64
 
 	unorderedWrites bool
+	// structuredMetadata is whether the chunks of the stream keep the structured metadata of the entries.
+	structuredMetadata bool
+
+	// patterns clusters the lines of the stream when pattern detection is enabled, nil otherwise.
+	patterns *drain.Drain
 }
 
 type chunkDesc struct {
@@ -113,8 +119,8 @@ type entryWithError struct {
 	e     error
 }
 
-func newStream(cfg *Config, limits RateLimiterStrategy, tenant string, fp model.Fingerprint, labels labels.Labels, unorderedWrites bool, metrics *ingesterMetrics) *stream {
-	return &stream{
+func newStream(cfg *Config, limits RateLimiterStrategy, tenant string, fp model.Fingerprint, labels labels.Labels, unorderedWrites, structuredMetadata bool, metrics *ingesterMetrics) *stream {
+	s := &stream{
 		limiter:         NewStreamRateLimiter(limits, tenant, 10*time.Second),
 		cfg:             cfg,
 		fp:              fp,
@@ -124,7 +130,13 @@ func newStream(cfg *Config, limits RateLimiterStrategy, tenant string, fp model.
 		metrics:         metrics,
 		tenant:          tenant,
 		unorderedWrites: unorderedWrites,
+
+		structuredMetadata: structuredMetadata,
 	}
+	if cfg != nil && cfg.Patterns.Enabled {
+		s.patterns = drain.New(cfg.Patterns.drainConfig())
+	}
+	return s
 }
 
 // consumeChunk manually adds a chunk to the stream that was received during
@@ -162,7 +174,7 @@ func (s *stream) setChunks(chunks []Chunk) (bytesAdded, entriesAdded int, err er
 }
 
 func (s *stream) NewChunk() *chunkenc.MemChunk {
-	return chunkenc.NewMemChunk(s.cfg.parsedEncoding, headBlockType(s.unorderedWrites), s.cfg.BlockSize, s.cfg.TargetChunkSize)
+	return chunkenc.NewMemChunk(s.cfg.parsedEncoding, headBlockType(s.unorderedWrites, s.structuredMetadata), s.cfg.BlockSize, s.cfg.TargetChunkSize)
 }
 
 func (s *stream) Push(
@@ -294,6 +306,12 @@ func (s *stream) Push(
 			s.metrics.recoveredEntriesTotal.Add(float64(len(storedEntries)))
 		}
 
+		if s.patterns != nil {
+			for _, e := range storedEntries {
+				s.patterns.Train(e.Line, e.Timestamp)
+			}
+		}
+
 		s.tailerMtx.RLock()
 		hasTailers := len(s.tailers) != 0
 		s.tailerMtx.RUnlock()
@@ -522,8 +540,12 @@ func (s *stream) resetCounter() {
 	s.entryCt = 0
 }
 
-func headBlockType(unorderedWrites bool) chunkenc.HeadBlockFmt {
+func headBlockType(unorderedWrites, structuredMetadata bool) chunkenc.HeadBlockFmt {
 	if unorderedWrites {
+		// Only the unordered head blocks keep the structured metadata of the entries.
+		if structuredMetadata {
+			return chunkenc.UnorderedWithStructuredMetadataHeadBlockFmt
+		}
 		return chunkenc.UnorderedHeadBlockFmt
 	}
 	return chunkenc.OrderedHeadBlockFmt
@@ -17,6 +17,7 @@ import (
 	"github.com/grafana/loki/pkg/chunkenc"
 	"github.com/grafana/loki/pkg/iter"
 	"github.com/grafana/loki/pkg/logproto"
+	"github.com/grafana/loki/pkg/logql"
 	"github.com/grafana/loki/pkg/logql/log"
 	"github.com/grafana/loki/pkg/util/flagext"
 	"github.com/grafana/loki/pkg/validation"
@@ -61,6 +62,7 @@ func TestMaxReturnedStreamsErrors(t *testing.T) {
 					{Name: "foo", Value: "bar"},
 				},
 				true,
+				false,
 				NilMetrics,
 			)
 
@@ -106,6 +108,7 @@ func TestPushDeduplication(t *testing.T) {
 			{Name: "foo", Value: "bar"},
 		},
 		true,
+		false,
 		NilMetrics,
 	)
 
@@ -135,6 +138,7 @@ func TestPushRejectOldCounter(t *testing.T) {
 			{Name: "foo", Value: "bar"},
 		},
 		true,
+		false,
 		NilMetrics,
 	)
 
@@ -229,6 +233,7 @@ func TestUnorderedPush(t *testing.T) {
 			{Name: "foo", Value: "bar"},
 		},
 		true,
+		false,
 		NilMetrics,
 	)
 
@@ -327,6 +332,7 @@ func TestPushRateLimit(t *testing.T) {
 			{Name: "foo", Value: "bar"},
 		},
 		true,
+		false,
 		NilMetrics,
 	)
 
@@ -356,6 +362,7 @@ func TestReplayAppendIgnoresValidityWindow(t *testing.T) {
 			{Name: "foo", Value: "bar"},
 		},
 		true,
+		false,
 		NilMetrics,
 	)
 
@@ -384,6 +391,47 @@ func TestReplayAppendIgnoresValidityWindow(t *testing.T) {
 
 }
 
+func TestPushStructuredMetadata(t *testing.T) {
+	limits, err := validation.NewOverrides(defaultLimitsTestConfig(), nil)
+	require.NoError(t, err)
+	limiter := NewLimiter(limits, NilMetrics, &ringCountMock{count: 1}, 1)
+
+	s := newStream(
+		defaultConfig(),
+		limiter,
+		"fake",
+		model.Fingerprint(0),
+		labels.Labels{
+			{Name: "foo", Value: "bar"},
+		},
+		true,
+		true,
+		NilMetrics,
+	)
+
+	entries := []logproto.Entry{
+		{Timestamp: time.Unix(1, 0), Line: "1", StructuredMetadata: labels.Labels{{Name: "trace_id", Value: "a"}}},
+		{Timestamp: time.Unix(2, 0), Line: "2"},
+		{Timestamp: time.Unix(3, 0), Line: "3", StructuredMetadata: labels.Labels{{Name: "trace_id", Value: "b"}}},
+	}
+	record := recordPool.GetRecord()
+	_, err = s.Push(context.Background(), entries, record, 0, true)
+	require.NoError(t, err)
+	require.Equal(t, chunkenc.UnorderedWithStructuredMetadataHeadBlockFmt, s.chunks[0].chunk.HeadFormat())
+	require.Equal(t, entries, record.RefEntries[0].Entries)
+
+	expr, err := logql.ParseLogSelector(`{foo="bar"} | trace_id="b"`, true)
+	require.NoError(t, err)
+	pipeline, err := expr.Pipeline()
+	require.NoError(t, err)
+	it, err := s.Iterator(context.Background(), nil, time.Unix(0, 0), time.Unix(10, 0), logproto.FORWARD, pipeline.ForStream(s.labels))
+	require.NoError(t, err)
+	require.True(t, it.Next())
+	require.Equal(t, entries[2], it.Entry())
+	require.Equal(t, `{foo="bar", trace_id="b"}`, it.Labels())
+	require.False(t, it.Next())
+}
+
 func iterEq(t *testing.T, exp []logproto.Entry, got iter.EntryIterator) {
 	var i int
 	for got.Next() {
@@ -406,8 +454,8 @@ func Benchmark_PushStream(b *testing.B) {
 	require.NoError(b, err)
 	limiter := NewLimiter(limits, NilMetrics, &ringCountMock{count: 1}, 1)
 
-	s := newStream(&Config{MaxChunkAge: 24 * time.Hour}, limiter, "fake", model.Fingerprint(0), ls, true, NilMetrics)
-	t, err := newTailer("foo", `{namespace="loki-dev"}`, &fakeTailServer{})
+	s := newStream(&Config{MaxChunkAge: 24 * time.Hour}, limiter, "fake", model.Fingerprint(0), ls, true, false, NilMetrics)
+	t, err := newTailer("foo", `{namespace="loki-dev"}`, &fakeTailServer{}, 0)
 	require.NoError(b, err)
 
 	go t.loop()
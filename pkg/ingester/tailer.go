@@ -43,10 +43,19 @@ type tailer struct {
 	blockedMtx     sync.RWMutex
 	droppedStreams []*logproto.DroppedStream
 
+	// maxBytesPerSecond bounds the bytes of the lines processed by the pipeline within each second, 0 means unlimited.
+	// windowStart and windowBytes track the bytes processed in the current second, they are guarded by pipelineMtx.
+	maxBytesPerSecond int
+	windowStart       time.Time
+	windowBytes       int
+
 	conn TailServer
 }
 
-func newTailer(orgID, query string, conn TailServer) (*tailer, error) {
+// newTailer creates a tailer for the given query.
+// maxBytesPerSecond limits the bytes the tailer processes per second, the entries pushed once it is reached are
+// dropped until the next second. 0 means unlimited.
+func newTailer(orgID, query string, conn TailServer, maxBytesPerSecond int) (*tailer, error) {
 	expr, err := logql.ParseLogSelector(query, true)
 	if err != nil {
 		return nil, err
@@ -58,15 +67,16 @@ func newTailer(orgID, query string, conn TailServer) (*tailer, error) {
 	matchers := expr.Matchers()
 
 	return &tailer{
-		orgID:          orgID,
-		matchers:       matchers,
-		pipeline:       pipeline,
-		sendChan:       make(chan *logproto.Stream, bufferSizeForTailResponse),
-		conn:           conn,
-		droppedStreams: []*logproto.DroppedStream{},
-		id:             generateUniqueID(orgID, query),
-		closeChan:      make(chan struct{}),
-		expr:           expr,
+		maxBytesPerSecond: maxBytesPerSecond,
+		orgID:             orgID,
+		matchers:          matchers,
+		pipeline:          pipeline,
+		sendChan:          make(chan *logproto.Stream, bufferSizeForTailResponse),
+		conn:              conn,
+		droppedStreams:    []*logproto.DroppedStream{},
+		id:                generateUniqueID(orgID, query),
+		closeChan:         make(chan struct{}),
+		expr:              expr,
 	}, nil
 }
 
@@ -119,7 +129,10 @@ func (t *tailer) send(stream logproto.Stream, lbs labels.Labels) {
 		return
 	}
 
-	streams := t.processStream(stream, lbs)
+	streams, skipped := t.processStream(stream, lbs)
+	if len(skipped) != 0 {
+		t.skipEntries(logproto.Stream{Labels: stream.Labels, Entries: skipped})
+	}
 	if len(streams) == 0 {
 		return
 	}
@@ -132,20 +145,35 @@ func (t *tailer) send(stream logproto.Stream, lbs labels.Labels) {
 	}
 }
 
-func (t *tailer) processStream(stream logproto.Stream, lbs labels.Labels) []*logproto.Stream {
+// processStream runs the pipeline of the tailer on the entries of the stream. It returns the resulting streams,
+// and the entries skipped once the lines processed within the current second exceed the bytes limit of the tailer.
+func (t *tailer) processStream(stream logproto.Stream, lbs labels.Labels) ([]*logproto.Stream, []logproto.Entry) {
 	// Optimization: skip filtering entirely, if no filter is set
 	if log.IsNoopPipeline(t.pipeline) {
-		return []*logproto.Stream{&stream}
+		return []*logproto.Stream{&stream}, nil
 	}
 	// pipeline are not thread safe and tailer can process multiple stream at once.
 	t.pipelineMtx.Lock()
 	defer t.pipelineMtx.Unlock()
 
+	if now := time.Now(); now.Sub(t.windowStart) >= time.Second {
+		t.windowStart = now
+		t.windowBytes = 0
+	}
+
 	streams := map[uint64]*logproto.Stream{}
+	var skipped []logproto.Entry
 
 	sp := t.pipeline.ForStream(lbs)
-	for _, e := range stream.Entries {
-		newLine, parsedLbs, ok := sp.ProcessString(e.Line)
+	for i, e := range stream.Entries {
+		// the first entry of each second is always processed, for streams with lines larger than the limit to be tailed.
+		if t.maxBytesPerSecond > 0 && t.windowBytes >= t.maxBytesPerSecond {
+			skipped = stream.Entries[i:]
+			break
+		}
+		t.windowBytes += len(e.Line)
+
+		newLine, parsedLbs, ok := sp.ProcessString(e.Timestamp.UnixNano(), e.Line, e.StructuredMetadata...)
 		if !ok {
 			continue
 		}
@@ -157,15 +185,16 @@ func (t *tailer) processStream(stream logproto.Stream, lbs labels.Labels) []*log
 			streams[parsedLbs.Hash()] = stream
 		}
 		stream.Entries = append(stream.Entries, logproto.Entry{
-			Timestamp: e.Timestamp,
-			Line:      newLine,
+			Timestamp:          e.Timestamp,
+			Line:               newLine,
+			StructuredMetadata: e.StructuredMetadata,
 		})
 	}
 	streamsResult := make([]*logproto.Stream, 0, len(streams))
 	for _, stream := range streams {
 		streamsResult = append(streamsResult, stream)
 	}
-	return streamsResult
+	return streamsResult, skipped
 }
 
 // isMatching returns true if lbs matches all matchers.
@@ -226,11 +255,24 @@ func (t *tailer) dropStream(stream logproto.Stream) {
 	})
 }
 
+// skipEntries reports entries the tailer did not process as dropped. Unlike dropStream,
+// the connection is not considered blocked.
+func (t *tailer) skipEntries(stream logproto.Stream) {
+	t.blockedMtx.Lock()
+	defer t.blockedMtx.Unlock()
+
+	t.droppedStreams = append(t.droppedStreams, &logproto.DroppedStream{
+		From:   stream.Entries[0].Timestamp,
+		To:     stream.Entries[len(stream.Entries)-1].Timestamp,
+		Labels: stream.Labels,
+	})
+}
+
 func (t *tailer) popDroppedStreams() []*logproto.DroppedStream {
 	t.blockedMtx.Lock()
 	defer t.blockedMtx.Unlock()
 
-	if t.blockedAt == nil {
+	if t.blockedAt == nil && len(t.droppedStreams) == 0 {
 		return nil
 	}
 
@@ -26,7 +26,7 @@ func TestTailer_sendRaceConditionOnSendWhileClosing(t *testing.T) {
 	}
 
 	for run := 0; run < runs; run++ {
-		tailer, err := newTailer("org-id", stream.Labels, nil)
+		tailer, err := newTailer("org-id", stream.Labels, nil, 0)
 		require.NoError(t, err)
 		require.NotNil(t, tailer)
 
@@ -55,7 +55,7 @@ func (f *fakeTailServer) Send(*logproto.TailResponse) error { return nil }
 func (f *fakeTailServer) Context() context.Context          { return context.Background() }
 
 func Test_TailerSendRace(t *testing.T) {
-	tail, err := newTailer("foo", `{app="foo"} |= "foo"`, &fakeTailServer{})
+	tail, err := newTailer("foo", `{app="foo"} |= "foo"`, &fakeTailServer{}, 0)
 	require.NoError(t, err)
 
 	var wg sync.WaitGroup
@@ -93,3 +93,38 @@ func Test_IsMatching(t *testing.T) {
 		})
 	}
 }
+
+func Test_TailerBytesLimit(t *testing.T) {
+	tail, err := newTailer("foo", `{app="foo"} |= "foo"`, &fakeTailServer{}, 10)
+	require.NoError(t, err)
+
+	lbs := makeRandomLabels()
+	stream := logproto.Stream{
+		Labels: lbs.String(),
+		Entries: []logproto.Entry{
+			{Timestamp: time.Unix(0, 1), Line: "foo foo"},
+			{Timestamp: time.Unix(0, 2), Line: "foo bar"},
+			{Timestamp: time.Unix(0, 3), Line: "foo buzz"},
+			{Timestamp: time.Unix(0, 4), Line: "foo fizz"},
+		},
+	}
+
+	// the lines after the first 10 bytes processed within a second are dropped.
+	tail.send(stream, lbs)
+	require.Equal(t, []*logproto.DroppedStream{{From: time.Unix(0, 3), To: time.Unix(0, 4), Labels: lbs.String()}}, tail.popDroppedStreams())
+	require.Nil(t, tail.blockedSince())
+	sent := <-tail.sendChan
+	require.Equal(t, stream.Entries[:2], sent.Entries)
+
+	// the budget is shared by all the pushes of the same second.
+	tail.send(stream, lbs)
+	require.Equal(t, []*logproto.DroppedStream{{From: time.Unix(0, 1), To: time.Unix(0, 4), Labels: lbs.String()}}, tail.popDroppedStreams())
+	require.Empty(t, tail.sendChan)
+
+	// a line larger than the limit is still processed in the next second.
+	tail.windowStart = tail.windowStart.Add(-time.Second)
+	large := logproto.Stream{Labels: lbs.String(), Entries: []logproto.Entry{{Timestamp: time.Unix(0, 5), Line: "foo foo foo foo"}}}
+	tail.send(large, lbs)
+	require.Empty(t, tail.popDroppedStreams())
+	require.Len(t, tail.sendChan, 1)
+}
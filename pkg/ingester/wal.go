@@ -29,13 +29,15 @@ type WALConfig struct {
 	CheckpointDuration  time.Duration    `yaml:"checkpoint_duration"`
 	FlushOnShutdown     bool             `yaml:"flush_on_shutdown"`
 	ReplayMemoryCeiling flagext.ByteSize `yaml:"replay_memory_ceiling"`
+
+	Shipping WALShippingConfig `yaml:"shipping"`
 }
 
 func (cfg *WALConfig) Validate() error {
 	if cfg.Enabled && cfg.CheckpointDuration < 1 {
 		return errors.Errorf("invalid checkpoint duration: %v", cfg.CheckpointDuration)
 	}
-	return nil
+	return cfg.Shipping.Validate(cfg.Enabled)
 }
 
 // RegisterFlags adds the flags required to config this to the given FlagSet
@@ -48,6 +50,8 @@ func (cfg *WALConfig) RegisterFlags(f *flag.FlagSet) {
 	// Need to set default here
 	cfg.ReplayMemoryCeiling = flagext.ByteSize(defaultCeiling)
 	f.Var(&cfg.ReplayMemoryCeiling, "ingester.wal-replay-memory-ceiling", "How much memory the WAL may use during replay before it needs to flush chunks to storage, i.e. 10GB. We suggest setting this to a high percentage (~75%) of available memory.")
+
+	cfg.Shipping.RegisterFlags(f)
 }
 
 // WAL interface allows us to have a no-op WAL when the WAL is disabled.
@@ -55,6 +59,9 @@ type WAL interface {
 	Start()
 	// Log marshalls the records and writes it into the WAL.
 	Log(*WALRecord) error
+	// Checkpoint requests a checkpoint to be created as soon as possible instead of
+	// waiting for the next checkpoint interval.
+	Checkpoint()
 	// Stop stops all the WAL operations.
 	Stop() error
 }
@@ -63,6 +70,7 @@ type noopWAL struct{}
 
 func (noopWAL) Start()               {}
 func (noopWAL) Log(*WALRecord) error { return nil }
+func (noopWAL) Checkpoint()          {}
 func (noopWAL) Stop() error          { return nil }
 
 type walWrapper struct {
@@ -71,6 +79,8 @@ type walWrapper struct {
 	metrics    *ingesterMetrics
 	seriesIter SeriesIter
 
+	checkpointer *Checkpointer
+
 	wait sync.WaitGroup
 	quit chan struct{}
 }
@@ -93,6 +103,13 @@ func newWAL(cfg WALConfig, registerer prometheus.Registerer, metrics *ingesterMe
 		metrics:    metrics,
 		seriesIter: seriesIter,
 	}
+	w.checkpointer = NewCheckpointer(
+		w.cfg.CheckpointDuration,
+		w.seriesIter,
+		w.checkpointWriter(),
+		w.metrics,
+		w.quit,
+	)
 
 	return w, nil
 }
@@ -126,7 +143,7 @@ func (w *walWrapper) Log(record *WALRecord) error {
 			buf = buf[:0]
 		}
 		if len(record.RefEntries) > 0 {
-			buf = record.encodeEntries(CurrentEntriesRec, buf)
+			buf = record.encodeEntries(record.entriesRecordType(), buf)
 			if err := w.wal.Log(buf); err != nil {
 				return err
 			}
@@ -156,15 +173,11 @@ func (w *walWrapper) run() {
 	level.Info(util_log.Logger).Log("msg", "started", "component", "wal")
 	defer w.wait.Done()
 
-	checkpointer := NewCheckpointer(
-		w.cfg.CheckpointDuration,
-		w.seriesIter,
-		w.checkpointWriter(),
-		w.metrics,
-		w.quit,
-	)
-	checkpointer.Run()
+	w.checkpointer.Run()
+}
 
+func (w *walWrapper) Checkpoint() {
+	w.checkpointer.Trigger()
 }
 
 type resettingPool struct {
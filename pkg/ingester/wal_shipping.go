@@ -0,0 +1,292 @@
+package ingester
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/go-kit/log/level"
+	"github.com/pkg/errors"
+
+	"github.com/grafana/loki/pkg/storage/chunk"
+	chunk_util "github.com/grafana/loki/pkg/storage/chunk/util"
+	shipper_util "github.com/grafana/loki/pkg/storage/stores/shipper/util"
+	util_log "github.com/grafana/loki/pkg/util/log"
+)
+
+const walShippingManifest = "manifest.json"
+
+// WALShippingConfig configures shipping of the WAL to the object store on shutdown, so that an ingester
+// which does not have a persistent disk can be replaced by one with the same ID without flushing or losing data.
+type WALShippingConfig struct {
+	Enabled              bool          `yaml:"enabled"`
+	SharedStoreType      string        `yaml:"shared_store"`
+	SharedStoreKeyPrefix string        `yaml:"shared_store_key_prefix"`
+	Timeout              time.Duration `yaml:"timeout"`
+	EncryptionKeyName    string        `yaml:"encryption_key_name"`
+
+	// ObjectClient is the client used to ship the WAL. It is built from SharedStoreType when the ingester module is initialised.
+	ObjectClient chunk.ObjectClient `yaml:"-"`
+}
+
+// RegisterFlags adds the flags required to config this to the given FlagSet
+func (cfg *WALShippingConfig) RegisterFlags(f *flag.FlagSet) {
+	f.BoolVar(&cfg.Enabled, "ingester.wal-shipping.enabled", false, "Upload the latest WAL checkpoint and segments to the object store on shutdown, and download them on startup when the WAL directory is empty. Requires the WAL to be enabled. Replacement ingesters need to reuse the ID of the ingester they replace.")
+	f.StringVar(&cfg.SharedStoreType, "ingester.wal-shipping.shared-store", "", "Shared store used for shipping the WAL. Supported types: gcs, s3, azure, swift, filesystem")
+	f.StringVar(&cfg.SharedStoreKeyPrefix, "ingester.wal-shipping.shared-store.key-prefix", "wal/", "Prefix to add to object keys in the shared store. Path separator(if any) should always be a '/'. Prefix should never start with a separator but should always end with it.")
+	f.DurationVar(&cfg.Timeout, "ingester.wal-shipping.timeout", 5*time.Minute, "Maximum time to spend uploading or downloading the WAL.")
+	f.StringVar(&cfg.EncryptionKeyName, "ingester.wal-shipping.encryption-key-name", "wal-shipping", "Name of the key of the store encryption key provider used to encrypt the shipped WAL when -store.encryption.enabled is set. The WAL holds the data of all the tenants, so it has its own key rather than a tenant one.")
+}
+
+func (cfg *WALShippingConfig) Validate(walEnabled bool) error {
+	if !cfg.Enabled {
+		return nil
+	}
+	if !walEnabled {
+		return errors.New("WAL shipping requires the WAL to be enabled")
+	}
+	if cfg.SharedStoreType == "" {
+		return errors.New("WAL shipping requires a shared store to be configured")
+	}
+	return shipper_util.ValidateSharedStoreKeyPrefix(cfg.SharedStoreKeyPrefix)
+}
+
+// walManifest lists the files of a shipped WAL. It is uploaded last, so a WAL without a manifest is incomplete and never replayed.
+type walManifest struct {
+	Files []walManifestFile `json:"files"`
+}
+
+type walManifestFile struct {
+	Name string `json:"name"`
+	Size int64  `json:"size"`
+}
+
+// walShipper uploads and downloads the WAL of a single ingester to and from the object store.
+type walShipper struct {
+	dir     string
+	prefix  string
+	client  chunk.ObjectClient
+	metrics *ingesterMetrics
+}
+
+func newWALShipper(cfg WALConfig, ingesterID string, metrics *ingesterMetrics) (*walShipper, error) {
+	if cfg.Shipping.ObjectClient == nil {
+		return nil, errors.New("WAL shipping is enabled but no object client was configured")
+	}
+	return &walShipper{
+		dir:     cfg.Dir,
+		prefix:  cfg.Shipping.SharedStoreKeyPrefix + ingesterID + "/",
+		client:  cfg.Shipping.ObjectClient,
+		metrics: metrics,
+	}, nil
+}
+
+// Upload uploads the WAL directory to the object store.
+// It must only be called after the WAL was stopped, so no more files get written.
+func (s *walShipper) Upload(ctx context.Context) error {
+	start := time.Now()
+
+	// Remove the manifest of a previous upload first, a partial upload must never be picked up.
+	if err := s.client.DeleteObject(ctx, s.prefix+walShippingManifest); err != nil && !s.client.IsObjectNotFoundErr(err) {
+		return errors.Wrap(err, "delete previous WAL manifest")
+	}
+
+	var manifest walManifest
+	err := filepath.Walk(s.dir, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			// Skip partially written checkpoints.
+			if strings.HasSuffix(info.Name(), ".tmp") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		name, err := filepath.Rel(s.dir, p)
+		if err != nil {
+			return err
+		}
+		name = filepath.ToSlash(name)
+
+		f, err := os.Open(p)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		if err := s.client.PutObject(ctx, s.prefix+name, f); err != nil {
+			return errors.Wrapf(err, "upload WAL file %s", name)
+		}
+
+		manifest.Files = append(manifest.Files, walManifestFile{Name: name, Size: info.Size()})
+		s.metrics.walShippingUploadedBytes.Add(float64(info.Size()))
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	buf, err := json.Marshal(manifest)
+	if err != nil {
+		return err
+	}
+	if err := s.client.PutObject(ctx, s.prefix+walShippingManifest, bytes.NewReader(buf)); err != nil {
+		return errors.Wrap(err, "upload WAL manifest")
+	}
+
+	// The segments and checkpoints of previous uploads which are not part of this one would never be removed otherwise.
+	keep := map[string]struct{}{walShippingManifest: {}}
+	for _, f := range manifest.Files {
+		keep[f.Name] = struct{}{}
+	}
+	if err := s.removeFiles(ctx, keep); err != nil {
+		level.Warn(util_log.Logger).Log("msg", "failed to remove stale WAL files from the object store", "prefix", s.prefix, "err", err)
+	}
+
+	level.Info(util_log.Logger).Log("msg", "uploaded WAL to the object store", "files", len(manifest.Files), "prefix", s.prefix, "time", time.Since(start).String())
+	return nil
+}
+
+// Download downloads a previously shipped WAL into the WAL directory.
+// It does nothing if the WAL directory is not empty or no complete WAL was shipped.
+// It returns whether a WAL was downloaded.
+func (s *walShipper) Download(ctx context.Context) (bool, error) {
+	start := time.Now()
+
+	files, err := ioutil.ReadDir(s.dir)
+	if err != nil {
+		return false, err
+	}
+	if len(files) > 0 {
+		level.Info(util_log.Logger).Log("msg", "WAL directory is not empty, not downloading shipped WAL", "dir", s.dir)
+		return false, nil
+	}
+
+	manifest, err := s.manifest(ctx)
+	if err != nil {
+		if s.client.IsObjectNotFoundErr(err) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	for _, f := range manifest.Files {
+		if err := s.downloadFile(ctx, f); err != nil {
+			// Do not replay a partially downloaded WAL.
+			if rmErr := s.cleanupDir(); rmErr != nil {
+				level.Error(util_log.Logger).Log("msg", "failed to clean up partially downloaded WAL", "err", rmErr)
+			}
+			return false, err
+		}
+	}
+
+	level.Info(util_log.Logger).Log("msg", "downloaded WAL from the object store", "files", len(manifest.Files), "prefix", s.prefix, "time", time.Since(start).String())
+	return true, nil
+}
+
+// Delete removes the shipped WAL from the object store once it has been replayed.
+func (s *walShipper) Delete(ctx context.Context) error {
+	if _, err := s.manifest(ctx); err != nil {
+		if s.client.IsObjectNotFoundErr(err) {
+			return nil
+		}
+		return err
+	}
+
+	// Delete the manifest first, the WAL must not be replayed again once it is partially gone.
+	if err := s.client.DeleteObject(ctx, s.prefix+walShippingManifest); err != nil {
+		return err
+	}
+	return s.removeFiles(ctx, nil)
+}
+
+// removeFiles deletes the files shipped under the prefix of the ingester, except the ones named in keep.
+func (s *walShipper) removeFiles(ctx context.Context, keep map[string]struct{}) error {
+	objects, _, err := s.client.List(ctx, s.prefix, "")
+	if err != nil {
+		return err
+	}
+	for _, object := range objects {
+		if _, ok := keep[strings.TrimPrefix(object.Key, s.prefix)]; ok {
+			continue
+		}
+		if err := s.client.DeleteObject(ctx, object.Key); err != nil && !s.client.IsObjectNotFoundErr(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *walShipper) manifest(ctx context.Context) (walManifest, error) {
+	var manifest walManifest
+
+	rc, _, err := s.client.GetObject(ctx, s.prefix+walShippingManifest)
+	if err != nil {
+		return manifest, err
+	}
+	defer rc.Close()
+
+	if err := json.NewDecoder(rc).Decode(&manifest); err != nil {
+		return manifest, errors.Wrap(err, "decode WAL manifest")
+	}
+	return manifest, nil
+}
+
+func (s *walShipper) downloadFile(ctx context.Context, f walManifestFile) error {
+	// The manifest is written by us, but never trust paths escaping the WAL directory.
+	name := path.Clean(f.Name)
+	if path.IsAbs(name) || strings.HasPrefix(name, "../") {
+		return fmt.Errorf("invalid WAL file name %q", f.Name)
+	}
+
+	dst := filepath.Join(s.dir, filepath.FromSlash(name))
+	if err := chunk_util.EnsureDirectory(filepath.Dir(dst)); err != nil {
+		return err
+	}
+
+	rc, _, err := s.client.GetObject(ctx, s.prefix+f.Name)
+	if err != nil {
+		return errors.Wrapf(err, "download WAL file %s", f.Name)
+	}
+	defer rc.Close()
+
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	n, err := io.Copy(out, rc)
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return errors.Wrapf(err, "download WAL file %s", f.Name)
+	}
+	if n != f.Size {
+		return fmt.Errorf("size mismatch for WAL file %s: expected %d bytes, got %d", f.Name, f.Size, n)
+	}
+
+	s.metrics.walShippingDownloadedBytes.Add(float64(n))
+	return nil
+}
+
+func (s *walShipper) cleanupDir() error {
+	files, err := ioutil.ReadDir(s.dir)
+	if err != nil {
+		return err
+	}
+	for _, f := range files {
+		if err := os.RemoveAll(filepath.Join(s.dir, f.Name())); err != nil {
+			return err
+		}
+	}
+	return nil
+}
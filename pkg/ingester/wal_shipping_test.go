@@ -0,0 +1,196 @@
+package ingester
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/grafana/dskit/services"
+	"github.com/stretchr/testify/require"
+	"github.com/weaveworks/common/user"
+
+	"github.com/grafana/loki/pkg/ingester/client"
+	"github.com/grafana/loki/pkg/logproto"
+	"github.com/grafana/loki/pkg/runtime"
+	"github.com/grafana/loki/pkg/storage/chunk"
+	"github.com/grafana/loki/pkg/storage/chunk/encryption"
+	"github.com/grafana/loki/pkg/storage/chunk/local"
+	"github.com/grafana/loki/pkg/validation"
+)
+
+func TestIngesterWALShipping(t *testing.T) {
+	tmpDir := t.TempDir()
+	objectClient, err := local.NewFSObjectClient(local.FSConfig{Directory: filepath.Join(tmpDir, "objects")})
+	require.NoError(t, err)
+
+	newConfig := func(walDir string) Config {
+		cfg := defaultIngesterTestConfigWithWAL(t, walDir)
+		cfg.WAL.CheckpointDuration = time.Hour
+		cfg.WAL.Shipping = WALShippingConfig{
+			Enabled:              true,
+			SharedStoreType:      "filesystem",
+			SharedStoreKeyPrefix: "wal/",
+			Timeout:              time.Minute,
+			ObjectClient:         objectClient,
+		}
+		require.NoError(t, cfg.Validate())
+		return cfg
+	}
+
+	limits, err := validation.NewOverrides(defaultLimitsTestConfig(), nil)
+	require.NoError(t, err)
+	store := &mockStore{
+		chunks: map[string][]chunk.Chunk{},
+	}
+
+	i, err := New(newConfig(filepath.Join(tmpDir, "wal-1")), client.Config{}, store, limits, runtime.DefaultTenantConfigs(), nil)
+	require.NoError(t, err)
+	require.Nil(t, services.StartAndAwaitRunning(context.Background(), i))
+
+	req := logproto.PushRequest{
+		Streams: []logproto.Stream{
+			{Labels: `{foo="bar",bar="baz1"}`},
+			{Labels: `{foo="bar",bar="baz2"}`},
+		},
+	}
+	start := time.Now()
+	steps := 10
+	end := start.Add(time.Second * time.Duration(steps))
+	for j := 0; j < steps; j++ {
+		for k := range req.Streams {
+			req.Streams[k].Entries = append(req.Streams[k].Entries, logproto.Entry{
+				Timestamp: start.Add(time.Duration(j) * time.Second),
+				Line:      fmt.Sprintf("line %d", j),
+			})
+		}
+	}
+
+	ctx := user.InjectOrgID(context.Background(), "test")
+	_, err = i.Push(ctx, &req)
+	require.NoError(t, err)
+
+	// stopping the ingester ships the WAL instead of flushing.
+	require.Nil(t, services.StopAndAwaitTerminated(context.Background(), i))
+	require.Empty(t, store.chunks)
+
+	shipper, err := newWALShipper(newConfig("").WAL, "localhost", NilMetrics)
+	require.NoError(t, err)
+	manifest, err := shipper.manifest(context.Background())
+	require.NoError(t, err)
+	require.NotEmpty(t, manifest.Files)
+
+	// a replacement ingester with the same ID and an empty disk recovers the shipped WAL.
+	walDir := filepath.Join(tmpDir, "wal-2")
+	i, err = New(newConfig(walDir), client.Config{}, store, limits, runtime.DefaultTenantConfigs(), nil)
+	require.NoError(t, err)
+	defer services.StopAndAwaitTerminated(context.Background(), i) //nolint:errcheck
+	require.Nil(t, services.StartAndAwaitRunning(context.Background(), i))
+
+	ensureIngesterData(ctx, t, start, end, i)
+
+	// the shipped WAL is removed once it has been replayed.
+	_, err = shipper.manifest(context.Background())
+	require.True(t, objectClient.IsObjectNotFoundErr(err))
+	files, err := ioutil.ReadDir(walDir)
+	require.NoError(t, err)
+	require.NotEmpty(t, files)
+}
+
+func TestWALShipperIgnoresIncompleteUploads(t *testing.T) {
+	tmpDir := t.TempDir()
+	objectClient := chunk.NewMockStorage()
+
+	walDir := filepath.Join(tmpDir, "wal")
+	require.NoError(t, os.MkdirAll(walDir, 0777))
+	shipper, err := newWALShipper(WALConfig{
+		Dir: walDir,
+		Shipping: WALShippingConfig{
+			SharedStoreKeyPrefix: "wal/",
+			ObjectClient:         objectClient,
+		},
+	}, "ingester-1", NilMetrics)
+	require.NoError(t, err)
+
+	// a segment without a manifest is not downloaded.
+	require.NoError(t, objectClient.PutObject(context.Background(), "wal/ingester-1/00000000", bytes.NewReader([]byte("segment"))))
+	downloaded, err := shipper.Download(context.Background())
+	require.NoError(t, err)
+	require.False(t, downloaded)
+
+	files, err := ioutil.ReadDir(walDir)
+	require.NoError(t, err)
+	require.Empty(t, files)
+}
+
+func TestWALShipperEncryptsAndRemovesStaleFiles(t *testing.T) {
+	tmpDir := t.TempDir()
+	keysDir := filepath.Join(tmpDir, "keys")
+	require.NoError(t, os.MkdirAll(keysDir, 0777))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(keysDir, "wal-shipping.key"), []byte(base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{1}, 32))), 0600))
+
+	store := chunk.NewMockStorage()
+	objectClient := encryption.NewObjectClient(store, encryption.NewEncrypter(encryption.NewKeyFileProvider(encryption.KeyFileConfig{Directory: keysDir})), func(string) (string, error) {
+		return "wal-shipping", nil
+	})
+
+	newShipper := func(walDir string) *walShipper {
+		require.NoError(t, os.MkdirAll(walDir, 0777))
+		shipper, err := newWALShipper(WALConfig{
+			Dir: walDir,
+			Shipping: WALShippingConfig{
+				SharedStoreKeyPrefix: "wal/",
+				ObjectClient:         objectClient,
+			},
+		}, "ingester-1", NilMetrics)
+		require.NoError(t, err)
+		return shipper
+	}
+
+	walDir := filepath.Join(tmpDir, "wal-1")
+	shipper := newShipper(walDir)
+	require.NoError(t, ioutil.WriteFile(filepath.Join(walDir, "00000000"), []byte("segment 0"), 0600))
+	require.NoError(t, shipper.Upload(context.Background()))
+
+	// the segment of the first upload is gone once a checkpoint replaced it.
+	require.NoError(t, os.Remove(filepath.Join(walDir, "00000000")))
+	require.NoError(t, os.MkdirAll(filepath.Join(walDir, "checkpoint.000001"), 0777))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(walDir, "checkpoint.000001", "00000000"), []byte("checkpoint"), 0600))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(walDir, "00000001"), []byte("segment 1"), 0600))
+	require.NoError(t, shipper.Upload(context.Background()))
+
+	objects, _, err := store.List(context.Background(), "wal/", "")
+	require.NoError(t, err)
+	var keys []string
+	for _, o := range objects {
+		keys = append(keys, o.Key)
+
+		// the files are encrypted in the object store.
+		rc, _, err := store.GetObject(context.Background(), o.Key)
+		require.NoError(t, err)
+		b, err := ioutil.ReadAll(rc)
+		require.NoError(t, err)
+		require.NoError(t, rc.Close())
+		require.True(t, encryption.IsEncrypted(b), o.Key)
+	}
+	require.Equal(t, []string{"wal/ingester-1/00000001", "wal/ingester-1/checkpoint.000001/00000000", "wal/ingester-1/" + walShippingManifest}, keys)
+
+	walDir = filepath.Join(tmpDir, "wal-2")
+	shipper = newShipper(walDir)
+	downloaded, err := shipper.Download(context.Background())
+	require.NoError(t, err)
+	require.True(t, downloaded)
+	b, err := ioutil.ReadFile(filepath.Join(walDir, "checkpoint.000001", "00000000"))
+	require.NoError(t, err)
+	require.Equal(t, "checkpoint", string(b))
+
+	require.NoError(t, shipper.Delete(context.Background()))
+	objects, _, err = store.List(context.Background(), "wal/", "")
+	require.NoError(t, err)
+	require.Empty(t, objects)
+}
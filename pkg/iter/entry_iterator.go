@@ -269,8 +269,8 @@ func (i *heapIterator) Next() bool {
 			i.requeue(i.tuples[j].EntryIterator, true)
 			continue
 		}
-		// we count as duplicates only if the tuple is not the one (t) used to fill the current entry
-		if i.tuples[j] != t {
+		// we count as duplicates only if the tuple is not the first one (t) used to fill the current entry
+		if j != 0 {
 			i.stats.AddDuplicates(1)
 		}
 		i.requeue(i.tuples[j].EntryIterator, false)
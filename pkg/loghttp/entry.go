@@ -1,6 +1,7 @@
 package loghttp
 
 import (
+	"sort"
 	"strconv"
 	"time"
 	"unsafe"
@@ -8,6 +9,7 @@ import (
 	"github.com/buger/jsonparser"
 	jsoniter "github.com/json-iterator/go"
 	"github.com/modern-go/reflect2"
+	"github.com/prometheus/prometheus/model/labels"
 )
 
 func init() {
@@ -15,9 +17,11 @@ func init() {
 }
 
 // Entry represents a log entry.  It includes a log message and the time it occurred at.
+// Its layout must match logproto.Entry, entries are converted without copies.
 type Entry struct {
-	Timestamp time.Time
-	Line      string
+	Timestamp          time.Time
+	Line               string
+	StructuredMetadata labels.Labels
 }
 
 func (e *Entry) UnmarshalJSON(data []byte) error {
@@ -41,6 +45,22 @@ func (e *Entry) UnmarshalJSON(data []byte) error {
 				return
 			}
 			e.Line = v
+		case 2: // structured metadata
+			var md labels.Labels
+			err := jsonparser.ObjectEach(value, func(key, val []byte, _ jsonparser.ValueType, _ int) error {
+				v, err := jsonparser.ParseString(val)
+				if err != nil {
+					return err
+				}
+				md = append(md, labels.Label{Name: string(key), Value: v})
+				return nil
+			})
+			if err != nil {
+				parseError = err
+				return
+			}
+			sort.Sort(md)
+			e.StructuredMetadata = md
 		}
 		i++
 	})
@@ -62,6 +82,7 @@ func (sliceEntryDecoder) Decode(ptr unsafe.Pointer, iter *jsoniter.Iterator) {
 		i := 0
 		var ts time.Time
 		var line string
+		var structuredMetadata labels.Labels
 		ok := iter.ReadArrayCB(func(iter *jsoniter.Iterator) bool {
 			var ok bool
 			switch i {
@@ -76,15 +97,27 @@ func (sliceEntryDecoder) Decode(ptr unsafe.Pointer, iter *jsoniter.Iterator) {
 					return false
 				}
 				return true
+			case 2:
+				iter.ReadMapCB(func(iter *jsoniter.Iterator, name string) bool {
+					structuredMetadata = append(structuredMetadata, labels.Label{Name: name, Value: iter.ReadString()})
+					return iter.Error == nil
+				})
+				i++
+				if iter.Error != nil {
+					return false
+				}
+				sort.Sort(structuredMetadata)
+				return true
 			default:
-				iter.ReportError("error reading entry", "array must contains 2 values")
+				iter.ReportError("error reading entry", "array must contains 2 or 3 values")
 				return false
 			}
 		})
 		if ok {
 			*((*[]Entry)(ptr)) = append(*((*[]Entry)(ptr)), Entry{
-				Timestamp: ts,
-				Line:      line,
+				Timestamp:          ts,
+				Line:               line,
+				StructuredMetadata: structuredMetadata,
 			})
 			return true
 		}
@@ -121,6 +154,18 @@ func (entryEncoder) Encode(ptr unsafe.Pointer, stream *jsoniter.Stream) {
 	stream.WriteRaw(`"`)
 	stream.WriteMore()
 	stream.WriteStringWithHTMLEscaped(e.Line)
+	if len(e.StructuredMetadata) > 0 {
+		stream.WriteMore()
+		stream.WriteObjectStart()
+		for i, l := range e.StructuredMetadata {
+			if i > 0 {
+				stream.WriteMore()
+			}
+			stream.WriteObjectField(l.Name)
+			stream.WriteStringWithHTMLEscaped(l.Value)
+		}
+		stream.WriteObjectEnd()
+	}
 	stream.WriteArrayEnd()
 }
 
@@ -0,0 +1,71 @@
+package loghttp
+
+import (
+	"net/http"
+
+	jsoniter "github.com/json-iterator/go"
+
+	"github.com/grafana/loki/pkg/logproto"
+)
+
+// PatternsResponse represents the http json response to a patterns query.
+type PatternsResponse struct {
+	Status string          `json:"status"`
+	Data   []PatternSeries `json:"data"`
+}
+
+// PatternSeries is a pattern of log lines with the number of lines matching it over time.
+type PatternSeries struct {
+	Pattern string          `json:"pattern"`
+	Samples []PatternSample `json:"samples"`
+}
+
+// PatternSample is the number of lines of a pattern in a step, starting at the timestamp in seconds.
+// It is encoded as [timestamp, count].
+type PatternSample struct {
+	Timestamp int64
+	Count     int64
+}
+
+func (s PatternSample) MarshalJSON() ([]byte, error) {
+	return jsoniter.Marshal([2]int64{s.Timestamp, s.Count})
+}
+
+func (s *PatternSample) UnmarshalJSON(data []byte) error {
+	var v [2]int64
+	if err := jsoniter.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	s.Timestamp, s.Count = v[0], v[1]
+	return nil
+}
+
+// ParsePatternsQuery parses a patterns request from an http request.
+func ParsePatternsQuery(r *http.Request) (*logproto.QueryPatternsRequest, error) {
+	start, end, err := bounds(r)
+	if err != nil {
+		return nil, err
+	}
+	if end.Before(start) {
+		return nil, errEndBeforeStart
+	}
+
+	step, err := step(r, start, end)
+	if err != nil {
+		return nil, err
+	}
+	if step <= 0 {
+		return nil, errNegativeStep
+	}
+	// For safety, limit the number of returned points per pattern, as for range queries.
+	if end.Sub(start)/step > 11000 {
+		return nil, errStepTooSmall
+	}
+
+	return &logproto.QueryPatternsRequest{
+		Query: query(r),
+		Start: start,
+		End:   end,
+		Step:  step.Milliseconds(),
+	}, nil
+}
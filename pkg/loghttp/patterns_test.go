@@ -0,0 +1,57 @@
+package loghttp
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	jsoniter "github.com/json-iterator/go"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/loki/pkg/logproto"
+)
+
+func TestParsePatternsQuery(t *testing.T) {
+	req, err := ParsePatternsQuery(withForm(url.Values{
+		"query": []string{`{app="foo"}`},
+		"start": []string{"1000"},
+		"end":   []string{"2000"},
+		"step":  []string{"1m"},
+	}))
+	require.NoError(t, err)
+	require.Equal(t, &logproto.QueryPatternsRequest{
+		Query: `{app="foo"}`,
+		Start: time.Unix(1000, 0),
+		End:   time.Unix(2000, 0),
+		Step:  time.Minute.Milliseconds(),
+	}, req)
+
+	_, err = ParsePatternsQuery(withForm(url.Values{
+		"query": []string{`{app="foo"}`},
+		"start": []string{"2000"},
+		"end":   []string{"1000"},
+	}))
+	require.Equal(t, errEndBeforeStart, err)
+
+	_, err = ParsePatternsQuery(withForm(url.Values{
+		"query": []string{`{app="foo"}`},
+		"step":  []string{"-1"},
+	}))
+	require.Equal(t, errNegativeStep, err)
+}
+
+func TestPatternsResponse_JSON(t *testing.T) {
+	resp := PatternsResponse{
+		Status: "success",
+		Data: []PatternSeries{
+			{Pattern: "GET <var1>", Samples: []PatternSample{{Timestamp: 10, Count: 2}, {Timestamp: 20, Count: 1}}},
+		},
+	}
+	b, err := jsoniter.Marshal(resp)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"status":"success","data":[{"pattern":"GET <var1>","samples":[[10,2],[20,1]]}]}`, string(b))
+
+	var decoded PatternsResponse
+	require.NoError(t, jsoniter.Unmarshal(b, &decoded))
+	require.Equal(t, resp, decoded)
+}
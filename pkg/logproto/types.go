@@ -4,6 +4,8 @@ import (
 	fmt "fmt"
 	io "io"
 	"time"
+
+	"github.com/prometheus/prometheus/model/labels"
 )
 
 // Stream contains a unique labels set as a string and a set of entries for it.
@@ -16,8 +18,9 @@ type Stream struct {
 
 // Entry is a log entry with a timestamp.
 type Entry struct {
-	Timestamp time.Time `protobuf:"bytes,1,opt,name=timestamp,proto3,stdtime" json:"ts"`
-	Line      string    `protobuf:"bytes,2,opt,name=line,proto3" json:"line"`
+	Timestamp          time.Time     `protobuf:"bytes,1,opt,name=timestamp,proto3,stdtime" json:"ts"`
+	Line               string        `protobuf:"bytes,2,opt,name=line,proto3" json:"line"`
+	StructuredMetadata labels.Labels `protobuf:"bytes,3,rep,name=structuredMetadata,proto3" json:"structuredMetadata,omitempty"`
 }
 
 func (m *Stream) Marshal() (dAtA []byte, err error) {
@@ -84,6 +87,18 @@ func (m *Entry) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	_ = i
 	var l int
 	_ = l
+	if len(m.StructuredMetadata) > 0 {
+		for iNdEx := len(m.StructuredMetadata) - 1; iNdEx >= 0; iNdEx-- {
+			{
+				size := labelPairSize(m.StructuredMetadata[iNdEx])
+				i -= size
+				marshalLabelPair(dAtA[i:i+size], m.StructuredMetadata[iNdEx])
+				i = encodeVarintLogproto(dAtA, i, uint64(size))
+			}
+			i--
+			dAtA[i] = 0x1a
+		}
+	}
 	if len(m.Line) > 0 {
 		i -= len(m.Line)
 		copy(dAtA[i:], m.Line)
@@ -316,6 +331,42 @@ func (m *Entry) Unmarshal(dAtA []byte) error {
 			}
 			m.Line = string(dAtA[iNdEx:postIndex])
 			iNdEx = postIndex
+		case 3:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field StructuredMetadata", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowLogproto
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthLogproto
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthLogproto
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			// Unmarshal through LabelPair to copy the name and the value, the entries outlive the request buffer.
+			var pair LabelPair
+			if err := pair.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			m.StructuredMetadata = append(m.StructuredMetadata, labels.Label{Name: pair.Name, Value: pair.Value})
+			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
 			skippy, err := skipLogproto(dAtA[iNdEx:])
@@ -372,9 +423,43 @@ func (m *Entry) Size() (n int) {
 	if l > 0 {
 		n += 1 + l + sovLogproto(uint64(l))
 	}
+	for _, lbl := range m.StructuredMetadata {
+		l = labelPairSize(lbl)
+		n += 1 + l + sovLogproto(uint64(l))
+	}
 	return n
 }
 
+// labelPairSize returns the size of a label marshalled as a LabelPair.
+func labelPairSize(lbl labels.Label) (n int) {
+	if l := len(lbl.Name); l > 0 {
+		n += 1 + l + sovLogproto(uint64(l))
+	}
+	if l := len(lbl.Value); l > 0 {
+		n += 1 + l + sovLogproto(uint64(l))
+	}
+	return n
+}
+
+// marshalLabelPair marshals a label as a LabelPair in a buffer of exactly its size.
+func marshalLabelPair(dAtA []byte, lbl labels.Label) {
+	i := len(dAtA)
+	if len(lbl.Value) > 0 {
+		i -= len(lbl.Value)
+		copy(dAtA[i:], lbl.Value)
+		i = encodeVarintLogproto(dAtA, i, uint64(len(lbl.Value)))
+		i--
+		dAtA[i] = 0x12
+	}
+	if len(lbl.Name) > 0 {
+		i -= len(lbl.Name)
+		copy(dAtA[i:], lbl.Name)
+		i = encodeVarintLogproto(dAtA, i, uint64(len(lbl.Name)))
+		i--
+		dAtA[i] = 0xa
+	}
+}
+
 func (m *Stream) Equal(that interface{}) bool {
 	if that == nil {
 		return m == nil
@@ -433,5 +518,8 @@ func (m *Entry) Equal(that interface{}) bool {
 	if m.Line != that1.Line {
 		return false
 	}
+	if !labels.Equal(m.StructuredMetadata, that1.StructuredMetadata) {
+		return false
+	}
 	return true
 }
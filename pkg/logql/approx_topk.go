@@ -0,0 +1,166 @@
+package logql
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/promql"
+
+	"github.com/grafana/loki/pkg/logql/sketch"
+)
+
+// CountMinSketchCounterLabel is the label holding the counter, as row:column, of the samples returned by approx_topk_sketch
+// for its count-min sketch, the value of each sample being the count of its counter.
+// The other samples returned by approx_topk_sketch are the top k series of the shard.
+const CountMinSketchCounterLabel = "__count_min_counter__"
+
+// ApproxTopKMergeExpr evaluates approx_topk from the count-min sketches and the top k series returned by the shards
+// of an approx_topk_sketch query: the merged sketch estimates the sum of each series across the shards.
+type ApproxTopKMergeExpr struct {
+	Left SampleExpr
+	K    int
+
+	implicit
+}
+
+func (e ApproxTopKMergeExpr) String() string {
+	return fmt.Sprintf("approx_topk_merge<%d, %s>", e.K, e.Left.String())
+}
+
+func (e *ApproxTopKMergeExpr) Selector() LogSelectorExpr { return e.Left.Selector() }
+
+func (e *ApproxTopKMergeExpr) Extractor() (SampleExtractor, error) { return e.Left.Extractor() }
+
+func (e *ApproxTopKMergeExpr) Shardable() bool { return false }
+
+func (e *ApproxTopKMergeExpr) Walk(f WalkFn) {
+	f(e)
+	e.Left.Walk(f)
+}
+
+// topK returns the k samples with the highest values, sorted by decreasing value.
+func topK(vec promql.Vector, k int) promql.Vector {
+	if k < 1 {
+		return promql.Vector{}
+	}
+	sort.SliceStable(vec, func(i, j int) bool { return vec[i].V > vec[j].V })
+	if len(vec) > k {
+		vec = vec[:k]
+	}
+	return vec
+}
+
+// approxTopKSketchEvaluator returns the top k series of each step along with the count-min sketch of all of them.
+func approxTopKSketchEvaluator(
+	ctx context.Context,
+	ev SampleEvaluator,
+	expr *VectorAggregationExpr,
+	q Params,
+) (StepEvaluator, error) {
+	nextEvaluator, err := ev.StepEvaluator(ctx, ev, expr.Left, q)
+	if err != nil {
+		return nil, err
+	}
+	var sketchErr error
+	return newStepEvaluator(func() (bool, int64, promql.Vector) {
+		next, ts, vec := nextEvaluator.Next()
+		if !next {
+			return false, 0, promql.Vector{}
+		}
+		cms, err := sketch.NewCountMinSketch(sketch.CountMinSketchDepth, sketch.CountMinSketchWidth)
+		if err != nil {
+			sketchErr = err
+			return false, 0, promql.Vector{}
+		}
+		for _, s := range vec {
+			cms.Add(s.Metric.Hash(), s.V)
+		}
+		result := append(promql.Vector{}, topK(vec, expr.Params)...)
+		cms.ForEach(func(row, column int, count float64) {
+			result = append(result, promql.Sample{
+				Point:  promql.Point{T: ts, V: count},
+				Metric: labels.Labels{{Name: CountMinSketchCounterLabel, Value: strconv.Itoa(row) + ":" + strconv.Itoa(column)}},
+			})
+		})
+		return true, ts, result
+	}, nextEvaluator.Close, func() error {
+		if sketchErr != nil {
+			return sketchErr
+		}
+		return nextEvaluator.Error()
+	})
+}
+
+// approxTopKMergeEvaluator merges the count-min sketches of the shards, and returns the k series
+// with the highest estimated sums among the top series of the shards.
+func approxTopKMergeEvaluator(
+	ctx context.Context,
+	ev SampleEvaluator,
+	expr *ApproxTopKMergeExpr,
+	q Params,
+) (StepEvaluator, error) {
+	nextEvaluator, err := ev.StepEvaluator(ctx, ev, expr.Left, q)
+	if err != nil {
+		return nil, err
+	}
+	var mergeErr error
+	return newStepEvaluator(func() (bool, int64, promql.Vector) {
+		next, ts, vec := nextEvaluator.Next()
+		if !next {
+			return false, 0, promql.Vector{}
+		}
+		cms, err := sketch.NewCountMinSketch(sketch.CountMinSketchDepth, sketch.CountMinSketchWidth)
+		if err != nil {
+			mergeErr = err
+			return false, 0, promql.Vector{}
+		}
+		var (
+			candidates = make(promql.Vector, 0, len(vec))
+			seen       = map[uint64]struct{}{}
+		)
+		for _, s := range vec {
+			counter := s.Metric.Get(CountMinSketchCounterLabel)
+			if counter == "" {
+				hash := s.Metric.Hash()
+				if _, ok := seen[hash]; !ok {
+					seen[hash] = struct{}{}
+					candidates = append(candidates, s)
+				}
+				continue
+			}
+			if err := addCounter(cms, counter, s.V); err != nil {
+				mergeErr = err
+				return false, 0, promql.Vector{}
+			}
+		}
+		for i := range candidates {
+			candidates[i].V = cms.Count(candidates[i].Metric.Hash())
+		}
+		return true, ts, topK(candidates, expr.K)
+	}, nextEvaluator.Close, func() error {
+		if mergeErr != nil {
+			return mergeErr
+		}
+		return nextEvaluator.Error()
+	})
+}
+
+func addCounter(cms *sketch.CountMinSketch, counter string, count float64) error {
+	i := strings.IndexByte(counter, ':')
+	if i < 0 {
+		return fmt.Errorf("invalid sketch counter %q", counter)
+	}
+	row, err := strconv.Atoi(counter[:i])
+	if err != nil {
+		return fmt.Errorf("invalid sketch counter %q: %w", counter, err)
+	}
+	column, err := strconv.Atoi(counter[i+1:])
+	if err != nil {
+		return fmt.Errorf("invalid sketch counter %q: %w", counter, err)
+	}
+	return cms.AddCounter(row, column, count)
+}
@@ -257,11 +257,41 @@ func (e *PipelineExpr) HasFilter() bool {
 	return false
 }
 
+// RequiredLineFilters returns the literals of the `|=` line filters every log line selected by the expression contains.
+// Line filters following a stage which rewrites the log line (line_format, unpack) are not applied to the original line and are ignored.
+func RequiredLineFilters(expr LogSelectorExpr) []string {
+	pipelineExpr, ok := expr.(*PipelineExpr)
+	if !ok {
+		return nil
+	}
+	var res []string
+	for _, stage := range pipelineExpr.MultiStages {
+		switch e := stage.(type) {
+		case *LineFilterExpr:
+			for curr := e; curr != nil; curr = curr.Left {
+				if curr.Ty == labels.MatchEqual && curr.Op == "" && curr.Match != "" && len(curr.Or) == 0 {
+					res = append(res, curr.Match)
+				}
+			}
+		case *LineFmtExpr:
+			return res
+		case *LabelParserExpr:
+			if e.Op == OpParserTypeUnpack {
+				return res
+			}
+		}
+	}
+	return res
+}
+
 type LineFilterExpr struct {
 	Left  *LineFilterExpr
 	Ty    labels.MatchType
 	Match string
-	Op    string
+	// Or are the alternatives to Match: the filter matches the lines matching any of them, or for negative filters
+	// the lines matching none of them.
+	Or []string
+	Op string
 	implicit
 }
 
@@ -273,11 +303,20 @@ func newLineFilterExpr(ty labels.MatchType, op, match string) *LineFilterExpr {
 	}
 }
 
+func newOrLineFilterExpr(ty labels.MatchType, matches []string) *LineFilterExpr {
+	return &LineFilterExpr{
+		Ty:    ty,
+		Match: matches[0],
+		Or:    matches[1:],
+	}
+}
+
 func newNestedLineFilterExpr(left *LineFilterExpr, right *LineFilterExpr) *LineFilterExpr {
 	return &LineFilterExpr{
 		Left:  left,
 		Ty:    right.Ty,
 		Match: right.Match,
+		Or:    right.Or,
 		Op:    right.Op,
 	}
 }
@@ -325,6 +364,10 @@ func (e *LineFilterExpr) String() string {
 	sb.WriteString(" ")
 	if e.Op == "" {
 		sb.WriteString(strconv.Quote(e.Match))
+		for _, m := range e.Or {
+			sb.WriteString(" or ")
+			sb.WriteString(strconv.Quote(m))
+		}
 		return sb.String()
 	}
 	sb.WriteString(e.Op)
@@ -339,14 +382,20 @@ func (e *LineFilterExpr) Filter() (log.Filterer, error) {
 	acc := make([]log.Filterer, 0)
 	for curr := e; curr != nil; curr = curr.Left {
 
-		switch curr.Op {
-		case OpFilterIP:
+		switch {
+		case curr.Op == OpFilterIP:
 			var err error
 			next, err := log.NewIPLineFilter(curr.Match, curr.Ty)
 			if err != nil {
 				return nil, err
 			}
 			acc = append(acc, next)
+		case len(curr.Or) > 0:
+			next, err := log.NewOrFilter(append([]string{curr.Match}, curr.Or...), curr.Ty)
+			if err != nil {
+				return nil, err
+			}
+			acc = append(acc, next)
 		default:
 			next, err := log.NewFilter(curr.Match, curr.Ty)
 			if err != nil {
@@ -406,6 +455,10 @@ func (e *LabelParserExpr) Stage() (log.Stage, error) {
 		return log.NewUnpackParser(), nil
 	case OpParserTypePattern:
 		return log.NewPatternParser(e.Param)
+	case OpParserTypeXML:
+		return log.NewXMLParser(), nil
+	case OpParserTypeCEF:
+		return log.NewCEFParser(), nil
 	default:
 		return nil, fmt.Errorf("unknown parser operator: %s", e.Op)
 	}
@@ -546,6 +599,41 @@ func (j *JSONExpressionParser) String() string {
 	return sb.String()
 }
 
+type XMLExpressionParser struct {
+	Expressions []log.XMLExpression
+
+	implicit
+}
+
+func newXMLExpressionParser(expressions []log.XMLExpression) *XMLExpressionParser {
+	return &XMLExpressionParser{
+		Expressions: expressions,
+	}
+}
+
+func (x *XMLExpressionParser) Shardable() bool { return true }
+
+func (x *XMLExpressionParser) Walk(f WalkFn) { f(x) }
+
+func (x *XMLExpressionParser) Stage() (log.Stage, error) {
+	return log.NewXMLExpressionParser(x.Expressions)
+}
+
+func (x *XMLExpressionParser) String() string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("%s %s ", OpPipe, OpParserTypeXML))
+	for i, exp := range x.Expressions {
+		sb.WriteString(exp.Identifier)
+		sb.WriteString("=")
+		sb.WriteString(strconv.Quote(exp.Expression))
+
+		if i+1 != len(x.Expressions) {
+			sb.WriteString(",")
+		}
+	}
+	return sb.String()
+}
+
 func mustNewMatcher(t labels.MatchType, n, v string) *labels.Matcher {
 	m, err := labels.NewMatcher(t, n, v)
 	if err != nil {
@@ -667,6 +755,12 @@ const (
 	OpTypeBottomK = "bottomk"
 	OpTypeTopK    = "topk"
 
+	// OpTypeApproxTopK is topk for sums of log line counts, which can be sharded with count-min sketches.
+	OpTypeApproxTopK = "approx_topk"
+	// OpTypeApproxTopKSketch returns the top k series of each shard of approx_topk along with a count-min sketch
+	// of all the series, each counter of the sketch being a sample labeled with CountMinSketchCounterLabel.
+	OpTypeApproxTopKSketch = "approx_topk_sketch"
+
 	// range vector ops
 	OpRangeTypeCount     = "count_over_time"
 	OpRangeTypeRate      = "rate"
@@ -683,6 +777,17 @@ const (
 	OpRangeTypeLast      = "last_over_time"
 	OpRangeTypeAbsent    = "absent_over_time"
 
+	// OpRangeTypeApproxCountDistinct estimates the number of distinct values of a label with HyperLogLog sketches.
+	OpRangeTypeApproxCountDistinct = "approx_count_distinct_over_time"
+	// OpRangeTypeApproxCountDistinctSketch returns the HyperLogLog sketches of approx_count_distinct_over_time
+	// to merge them across shards, each register of a sketch being a sample labeled with HyperLogLogRegisterLabel.
+	OpRangeTypeApproxCountDistinctSketch = "approx_count_distinct_sketch_over_time"
+
+	// OpRangeTypeQuantileSketch sketches the unwrapped values of each range, to compute quantile_over_time
+	// across shards. Its parameter is the relative accuracy of the sketch, and each bucket of the sketch is
+	// returned as a sample labeled with QuantileSketchBucketLabel.
+	OpRangeTypeQuantileSketch = "quantile_sketch_over_time"
+
 	// binops - logical/set
 	OpTypeOr     = "or"
 	OpTypeAnd    = "and"
@@ -710,6 +815,8 @@ const (
 	OpParserTypeRegexp  = "regexp"
 	OpParserTypeUnpack  = "unpack"
 	OpParserTypePattern = "pattern"
+	OpParserTypeXML     = "xml"
+	OpParserTypeCEF     = "cef"
 
 	OpFmtLine  = "line_format"
 	OpFmtLabel = "label_format"
@@ -766,34 +873,32 @@ type RangeAggregationExpr struct {
 	Left      *LogRange
 	Operation string
 
-	Params   *float64
+	Params *float64
+	// Label is the label whose distinct values are counted by approx_count_distinct_over_time.
+	Label    string
 	Grouping *Grouping
 	implicit
 }
 
 func newRangeAggregationExpr(left *LogRange, operation string, gr *Grouping, stringParams *string) SampleExpr {
-	var params *float64
-	if stringParams != nil {
-		if operation != OpRangeTypeQuantile {
-			panic(logqlmodel.NewParseError(fmt.Sprintf("parameter %s not supported for operation %s", *stringParams, operation), 0, 0))
-		}
-		var err error
-		params = new(float64)
-		*params, err = strconv.ParseFloat(*stringParams, 64)
-		if err != nil {
-			panic(logqlmodel.NewParseError(fmt.Sprintf("invalid parameter for operation %s: %s", operation, err), 0, 0))
-		}
-
-	} else {
-		if operation == OpRangeTypeQuantile {
-			panic(logqlmodel.NewParseError(fmt.Sprintf("parameter required for operation %s", operation), 0, 0))
-		}
+	e := &RangeAggregationExpr{
+		Left:      left,
+		Operation: operation,
+		Grouping:  gr,
+		Params:    mustParseRangeParams(operation, stringParams),
 	}
+	if err := e.validate(); err != nil {
+		panic(logqlmodel.NewParseError(err.Error(), 0, 0))
+	}
+	return e
+}
+
+func newLabelRangeAggregationExpr(left *LogRange, operation string, gr *Grouping, label string) SampleExpr {
 	e := &RangeAggregationExpr{
 		Left:      left,
 		Operation: operation,
 		Grouping:  gr,
-		Params:    params,
+		Label:     label,
 	}
 	if err := e.validate(); err != nil {
 		panic(logqlmodel.NewParseError(err.Error(), 0, 0))
@@ -801,6 +906,30 @@ func newRangeAggregationExpr(left *LogRange, operation string, gr *Grouping, str
 	return e
 }
 
+// countsDistinctValues tells if a range aggregation counts the distinct values of a label.
+func countsDistinctValues(operation string) bool {
+	return operation == OpRangeTypeApproxCountDistinct || operation == OpRangeTypeApproxCountDistinctSketch
+}
+
+// mustParseRangeParams parses the parameter of a range aggregation, which only the quantile operations require.
+func mustParseRangeParams(operation string, stringParams *string) *float64 {
+	requiresParams := operation == OpRangeTypeQuantile || operation == OpRangeTypeQuantileSketch
+	if stringParams == nil {
+		if requiresParams {
+			panic(logqlmodel.NewParseError(fmt.Sprintf("parameter required for operation %s", operation), 0, 0))
+		}
+		return nil
+	}
+	if !requiresParams {
+		panic(logqlmodel.NewParseError(fmt.Sprintf("parameter %s not supported for operation %s", *stringParams, operation), 0, 0))
+	}
+	params, err := strconv.ParseFloat(*stringParams, 64)
+	if err != nil {
+		panic(logqlmodel.NewParseError(fmt.Sprintf("invalid parameter for operation %s: %s", operation, err), 0, 0))
+	}
+	return &params
+}
+
 func (e *RangeAggregationExpr) Selector() LogSelectorExpr {
 	return e.Left.Left
 }
@@ -808,21 +937,31 @@ func (e *RangeAggregationExpr) Selector() LogSelectorExpr {
 func (e RangeAggregationExpr) validate() error {
 	if e.Grouping != nil {
 		switch e.Operation {
-		case OpRangeTypeAvg, OpRangeTypeStddev, OpRangeTypeStdvar, OpRangeTypeQuantile, OpRangeTypeMax, OpRangeTypeMin, OpRangeTypeFirst, OpRangeTypeLast:
+		case OpRangeTypeAvg, OpRangeTypeStddev, OpRangeTypeStdvar, OpRangeTypeQuantile, OpRangeTypeQuantileSketch, OpRangeTypeMax, OpRangeTypeMin, OpRangeTypeFirst, OpRangeTypeLast,
+			OpRangeTypeApproxCountDistinct, OpRangeTypeApproxCountDistinctSketch:
 		default:
 			return fmt.Errorf("grouping not allowed for %s aggregation", e.Operation)
 		}
 	}
+	if countsDistinctValues(e.Operation) != (e.Label != "") {
+		if e.Label == "" {
+			return fmt.Errorf("label required for operation %s", e.Operation)
+		}
+		return fmt.Errorf("label %s not supported for operation %s", e.Label, e.Operation)
+	}
+	if e.Operation == OpRangeTypeQuantileSketch && (*e.Params <= 0 || *e.Params >= 1) {
+		return fmt.Errorf("relative accuracy of %s must be between 0 and 1", e.Operation)
+	}
 	if e.Left.Unwrap != nil {
 		switch e.Operation {
-		case OpRangeTypeAvg, OpRangeTypeSum, OpRangeTypeMax, OpRangeTypeMin, OpRangeTypeStddev, OpRangeTypeStdvar, OpRangeTypeQuantile, OpRangeTypeRate, OpRangeTypeAbsent, OpRangeTypeFirst, OpRangeTypeLast:
+		case OpRangeTypeAvg, OpRangeTypeSum, OpRangeTypeMax, OpRangeTypeMin, OpRangeTypeStddev, OpRangeTypeStdvar, OpRangeTypeQuantile, OpRangeTypeQuantileSketch, OpRangeTypeRate, OpRangeTypeAbsent, OpRangeTypeFirst, OpRangeTypeLast:
 			return nil
 		default:
 			return fmt.Errorf("invalid aggregation %s with unwrap", e.Operation)
 		}
 	}
 	switch e.Operation {
-	case OpRangeTypeBytes, OpRangeTypeBytesRate, OpRangeTypeCount, OpRangeTypeRate, OpRangeTypeAbsent:
+	case OpRangeTypeBytes, OpRangeTypeBytesRate, OpRangeTypeCount, OpRangeTypeRate, OpRangeTypeAbsent, OpRangeTypeApproxCountDistinct, OpRangeTypeApproxCountDistinctSketch:
 		return nil
 	default:
 		return fmt.Errorf("invalid aggregation %s without unwrap", e.Operation)
@@ -838,6 +977,10 @@ func (e *RangeAggregationExpr) String() string {
 		sb.WriteString(strconv.FormatFloat(*e.Params, 'f', -1, 64))
 		sb.WriteString(",")
 	}
+	if e.Label != "" {
+		sb.WriteString(e.Label)
+		sb.WriteString(",")
+	}
 	sb.WriteString(e.Left.String())
 	sb.WriteString(")")
 	if e.Grouping != nil {
@@ -859,6 +1002,144 @@ func (e *RangeAggregationExpr) Walk(f WalkFn) {
 	e.Left.Walk(f)
 }
 
+// defaultSubqueryStep is the resolution of the subqueries without one, e.g. [1h:],
+// which is the default evaluation interval of Prometheus.
+const defaultSubqueryStep = time.Minute
+
+// subqueryRange is the range and the resolution of a subquery, e.g. [1h:5m].
+type subqueryRange struct {
+	interval, step time.Duration
+}
+
+// SubqueryExpr is a metric query evaluated over a range at a fixed resolution, e.g. rate({app="foo"}[1m])[1h:5m].
+// As in PromQL, the query is evaluated at the timestamps that are multiples of the step.
+type SubqueryExpr struct {
+	Left     SampleExpr
+	Interval time.Duration
+	Step     time.Duration
+	Offset   time.Duration
+
+	implicit
+}
+
+func newSubqueryExpr(left SampleExpr, r subqueryRange, o *OffsetExpr) *SubqueryExpr {
+	if r.interval <= 0 {
+		panic(logqlmodel.NewParseError("subquery range must be greater than zero", 0, 0))
+	}
+	var offset time.Duration
+	if o != nil {
+		offset = o.Offset
+	}
+	return &SubqueryExpr{
+		Left:     left,
+		Interval: r.interval,
+		Step:     r.step,
+		Offset:   offset,
+	}
+}
+
+// EvaluationStep returns the resolution the query of the subquery is evaluated at.
+func (e *SubqueryExpr) EvaluationStep() time.Duration {
+	if e.Step == 0 {
+		return defaultSubqueryStep
+	}
+	return e.Step
+}
+
+// impls Stringer
+func (e SubqueryExpr) String() string {
+	var sb strings.Builder
+	sb.WriteString(e.Left.String())
+	sb.WriteString("[")
+	sb.WriteString(model.Duration(e.Interval).String())
+	sb.WriteString(":")
+	if e.Step != 0 {
+		sb.WriteString(model.Duration(e.Step).String())
+	}
+	sb.WriteString("]")
+	if e.Offset != 0 {
+		offsetExpr := OffsetExpr{Offset: e.Offset}
+		sb.WriteString(offsetExpr.String())
+	}
+	return sb.String()
+}
+
+func (e *SubqueryExpr) Shardable() bool { return false }
+
+func (e *SubqueryExpr) Walk(f WalkFn) {
+	f(e)
+	if e.Left == nil {
+		return
+	}
+	e.Left.Walk(f)
+}
+
+// SubqueryAggregationExpr is a range aggregation over the samples of a subquery,
+// e.g. max_over_time(rate({app="foo"}[1m])[1h:5m]).
+type SubqueryAggregationExpr struct {
+	Left      *SubqueryExpr
+	Operation string
+
+	Params *float64
+	implicit
+}
+
+func newSubqueryAggregationExpr(left *SubqueryExpr, operation string, stringParams *string) SampleExpr {
+	e := &SubqueryAggregationExpr{
+		Left:      left,
+		Operation: operation,
+		Params:    mustParseRangeParams(operation, stringParams),
+	}
+	if err := e.validate(); err != nil {
+		panic(logqlmodel.NewParseError(err.Error(), 0, 0))
+	}
+	return e
+}
+
+func (e SubqueryAggregationExpr) validate() error {
+	switch e.Operation {
+	case OpRangeTypeAvg, OpRangeTypeSum, OpRangeTypeMax, OpRangeTypeMin, OpRangeTypeStddev, OpRangeTypeStdvar, OpRangeTypeQuantile,
+		OpRangeTypeRate, OpRangeTypeCount, OpRangeTypeAbsent, OpRangeTypeFirst, OpRangeTypeLast:
+		return nil
+	default:
+		return fmt.Errorf("invalid aggregation %s over a subquery", e.Operation)
+	}
+}
+
+func (e *SubqueryAggregationExpr) Selector() LogSelectorExpr {
+	return e.Left.Left.Selector()
+}
+
+func (e *SubqueryAggregationExpr) Extractor() (log.SampleExtractor, error) {
+	return e.Left.Left.Extractor()
+}
+
+// impls Stringer
+func (e *SubqueryAggregationExpr) String() string {
+	var sb strings.Builder
+	sb.WriteString(e.Operation)
+	sb.WriteString("(")
+	if e.Params != nil {
+		sb.WriteString(strconv.FormatFloat(*e.Params, 'f', -1, 64))
+		sb.WriteString(",")
+	}
+	sb.WriteString(e.Left.String())
+	sb.WriteString(")")
+	return sb.String()
+}
+
+// Shardable is false as the samples of a series can come from several shards when the subquery
+// aggregates series, the shard mapper shards the query of the subquery instead.
+func (e *SubqueryAggregationExpr) Shardable() bool { return false }
+
+func (e *SubqueryAggregationExpr) Walk(f WalkFn) {
+	f(e)
+	if e.Left == nil {
+		return
+	}
+	e.Left.Walk(f)
+}
+
 type Grouping struct {
 	Groups  []string
 	Without bool
@@ -895,13 +1176,16 @@ func mustNewVectorAggregationExpr(left SampleExpr, operation string, gr *Groupin
 	var p int
 	var err error
 	switch operation {
-	case OpTypeBottomK, OpTypeTopK:
+	case OpTypeBottomK, OpTypeTopK, OpTypeApproxTopK, OpTypeApproxTopKSketch:
 		if params == nil {
 			panic(logqlmodel.NewParseError(fmt.Sprintf("parameter required for operation %s", operation), 0, 0))
 		}
 		if p, err = strconv.Atoi(*params); err != nil {
 			panic(logqlmodel.NewParseError(fmt.Sprintf("invalid parameter %s(%s,", operation, *params), 0, 0))
 		}
+		if gr != nil && (operation == OpTypeApproxTopK || operation == OpTypeApproxTopKSketch) {
+			panic(logqlmodel.NewParseError(fmt.Sprintf("grouping not allowed for %s aggregation", operation), 0, 0))
+		}
 
 	default:
 		if params != nil {
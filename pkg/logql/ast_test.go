@@ -20,6 +20,8 @@ func Test_logSelectorExpr_String(t *testing.T) {
 		{`{foo="bar", bar!="baz"}`, false},
 		{`{foo="bar", bar!="baz"} != "bip" !~ ".+bop"`, true},
 		{`{foo="bar"} |= "baz" |~ "blip" != "flip" !~ "flap"`, true},
+		{`{foo="bar"} |= "baz" or "blip" != "flip" or "flap"`, true},
+		{`{foo="bar"} |~ "b.z" or "blip" |= ip("::1")`, true},
 		{`{foo="bar", bar!="baz"} |= ""`, false},
 		{`{foo="bar", bar!="baz"} |= "" |= ip("::1")`, true},
 		{`{foo="bar", bar!="baz"} |= "" != ip("127.0.0.1")`, true},
@@ -70,6 +72,7 @@ func Test_SampleExpr_String(t *testing.T) {
 		`sum without(a) ( rate ( ( {job="mysql"} |="error" !="timeout" ) [10s] ) )`,
 		`sum by(a) (rate( ( {job="mysql"} |="error" !="timeout" ) [10s] ) )`,
 		`sum(count_over_time({job="mysql"}[5m]))`,
+		`sum(count_over_time({job="mysql"} |= "error" or "timeout" != "debug" or "info" [5m]))`,
 		`sum(count_over_time({job="mysql"}[5m] offset 10m))`,
 		`sum(count_over_time({job="mysql"} | json [5m]))`,
 		`sum(count_over_time({job="mysql"} | json [5m] offset 10m))`,
@@ -77,6 +80,9 @@ func Test_SampleExpr_String(t *testing.T) {
 		`sum(count_over_time({job="mysql"} | logfmt [5m] offset 10m))`,
 		`sum(count_over_time({job="mysql"} | pattern "<foo> bar <buzz>" | json [5m]))`,
 		`sum(count_over_time({job="mysql"} | unpack | json [5m]))`,
+		`sum(count_over_time({job="mysql"} | xml [5m]))`,
+		`sum by (id) (count_over_time({job="mysql"} | xml id="Event/System/EventID", user="Event/EventData/Data[@Name='User']" [5m]))`,
+		`sum(count_over_time({job="mysql"} | cef [5m]))`,
 		`sum(count_over_time({job="mysql"} | regexp "(?P<foo>foo|bar)" [5m]))`,
 		`sum(count_over_time({job="mysql"} | regexp "(?P<foo>foo|bar)" [5m] offset 10m))`,
 		`topk(10,sum(rate({region="us-east1"}[5m])) by (name))`,
@@ -148,6 +154,11 @@ func Test_SampleExpr_String(t *testing.T) {
 		`,
 		`10 / (5/2)`,
 		`10 / (count_over_time({job="postgres"}[5m])/2)`,
+		`max_over_time(rate({job="mysql"}[1m])[1h:5m])`,
+		`max_over_time(rate({job="mysql"}[1m])[1h:])`,
+		`quantile_over_time(0.99, sum by (job) (rate({job="mysql"}[1m]))[1h:5m] offset 1h)`,
+		`rate(sum(sum_over_time({job="mysql"} | json | unwrap latency [1m]))[10m:1m])`,
+		`max_over_time(avg_over_time((sum(rate({job="mysql"}[1m])) / 2)[10m:1m])[1h:10m])`,
 		`{app="foo"} | json response_status="response.status.code", first_param="request.params[0]"`,
 		`label_replace(
 			sum by (job) (
@@ -190,7 +201,7 @@ func Test_NilFilterDoesntPanic(t *testing.T) {
 
 			p, err := expr.Pipeline()
 			require.Nil(t, err)
-			_, _, ok := p.ForStream(labelBar).Process([]byte("bleepbloop"))
+			_, _, ok := p.ForStream(labelBar).Process(0, []byte("bleepbloop"))
 
 			require.True(t, ok)
 		})
@@ -284,7 +295,7 @@ func Test_FilterMatcher(t *testing.T) {
 			} else {
 				sp := p.ForStream(labelBar)
 				for _, lc := range tt.lines {
-					_, _, ok := sp.Process([]byte(lc.l))
+					_, _, ok := sp.Process(0, []byte(lc.l))
 					assert.Equalf(t, lc.e, ok, "query for line '%s' was %v and not %v", lc.l, ok, lc.e)
 				}
 			}
@@ -292,6 +303,27 @@ func Test_FilterMatcher(t *testing.T) {
 	}
 }
 
+func TestRequiredLineFilters(t *testing.T) {
+	for _, tc := range []struct {
+		in       string
+		expected []string
+	}{
+		{`{app="foo"}`, nil},
+		{`{app="foo"} |= "foo"`, []string{"foo"}},
+		{`{app="foo"} |= "foo" |= "bar" != "baz" |~ "buzz"`, []string{"bar", "foo"}},
+		{`{app="foo"} |= ip("127.0.0.1")`, nil},
+		{`{app="foo"} |= "foo" | json | bar="baz" |= "bar"`, []string{"foo", "bar"}},
+		{`{app="foo"} |= "foo" | line_format "{{.bar}}" |= "bar"`, []string{"foo"}},
+		{`{app="foo"} | unpack |= "bar"`, nil},
+	} {
+		t.Run(tc.in, func(t *testing.T) {
+			expr, err := ParseLogSelector(tc.in, true)
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, RequiredLineFilters(expr))
+		})
+	}
+}
+
 func TestStringer(t *testing.T) {
 	for _, tc := range []struct {
 		in  string
@@ -389,7 +421,7 @@ func BenchmarkContainsFilter(b *testing.B) {
 			sp := p.ForStream(labelBar)
 			for i := 0; i < b.N; i++ {
 				for _, line := range lines {
-					sp.Process(line)
+					sp.Process(0, line)
 				}
 			}
 		})
@@ -408,6 +440,8 @@ func Test_parserExpr_Parser(t *testing.T) {
 		{"json", OpParserTypeJSON, "", log.NewJSONParser(), false},
 		{"unpack", OpParserTypeUnpack, "", log.NewUnpackParser(), false},
 		{"logfmt", OpParserTypeLogfmt, "", log.NewLogfmtParser(), false},
+		{"xml", OpParserTypeXML, "", log.NewXMLParser(), false},
+		{"cef", OpParserTypeCEF, "", log.NewCEFParser(), false},
 		{"pattern", OpParserTypePattern, "<foo> bar <buzz>", mustNewPatternParser("<foo> bar <buzz>"), false},
 		{"pattern err", OpParserTypePattern, "bar", nil, true},
 		{"regexp", OpParserTypeRegexp, "(?P<foo>foo)", mustNewRegexParser("(?P<foo>foo)"), false},
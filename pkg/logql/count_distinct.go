@@ -0,0 +1,197 @@
+package logql
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/promql"
+
+	"github.com/grafana/loki/pkg/logql/sketch"
+	"github.com/grafana/loki/pkg/logqlmodel"
+)
+
+// HyperLogLogRegisterLabel is the label holding the register of the samples returned by approx_count_distinct_sketch_over_time,
+// the value of each sample being the rank of its register.
+const HyperLogLogRegisterLabel = "__hll_register__"
+
+// CountDistinctMergeExpr estimates the number of distinct values from the sketches returned by the shards of an
+// approx_count_distinct_sketch_over_time query, the sketches of the same series being merged whatever the shard they come from.
+type CountDistinctMergeExpr struct {
+	Left SampleExpr
+
+	implicit
+}
+
+func (e CountDistinctMergeExpr) String() string {
+	return fmt.Sprintf("count_distinct_merge<%s>", e.Left.String())
+}
+
+func (e *CountDistinctMergeExpr) Selector() LogSelectorExpr { return e.Left.Selector() }
+
+func (e *CountDistinctMergeExpr) Extractor() (SampleExtractor, error) { return e.Left.Extractor() }
+
+func (e *CountDistinctMergeExpr) Shardable() bool { return false }
+
+func (e *CountDistinctMergeExpr) Walk(f WalkFn) {
+	f(e)
+	e.Left.Walk(f)
+}
+
+// countDistinctEvaluator counts the distinct values of a label in each range, grouping the series by their other labels.
+// When sketches is set, the registers of the sketch of each group are returned instead of the count.
+type countDistinctEvaluator struct {
+	iter     RangeVectorIterator
+	label    string
+	sketches bool
+
+	lb  *labels.Builder
+	buf []byte
+	err error
+}
+
+type seriesHyperLogLog struct {
+	metric labels.Labels
+	sketch *sketch.HyperLogLog
+}
+
+func (e *countDistinctEvaluator) Next() (bool, int64, promql.Vector) {
+	next := e.iter.Next()
+	if !next {
+		return false, 0, promql.Vector{}
+	}
+	// the series of the range are only counted, not aggregated.
+	ts, vec := e.iter.At(func([]promql.Point) float64 { return 0 })
+	if e.lb == nil {
+		e.lb = labels.NewBuilder(nil)
+	}
+	var (
+		series []*seriesHyperLogLog
+		byHash = map[uint64]*seriesHyperLogLog{}
+		hash   uint64
+	)
+	for _, s := range vec {
+		// Errors are not allowed in metrics.
+		if s.Metric.Has(logqlmodel.ErrorLabel) {
+			e.err = logqlmodel.NewPipelineErr(s.Metric)
+			return false, 0, promql.Vector{}
+		}
+		value := s.Metric.Get(e.label)
+		if value == "" {
+			continue
+		}
+		hash, e.buf = s.Metric.HashWithoutLabels(e.buf, e.label)
+		ss, ok := byHash[hash]
+		if !ok {
+			h, err := sketch.NewHyperLogLog(sketch.HyperLogLogPrecision)
+			if err != nil {
+				e.err = err
+				return false, 0, promql.Vector{}
+			}
+			e.lb.Reset(s.Metric)
+			e.lb.Del(e.label)
+			ss = &seriesHyperLogLog{metric: e.lb.Labels(), sketch: h}
+			byHash[hash] = ss
+			series = append(series, ss)
+		}
+		ss.sketch.Add(value)
+	}
+
+	result := make(promql.Vector, 0, len(series))
+	for _, ss := range series {
+		if !e.sketches {
+			result = append(result, promql.Sample{
+				Point:  promql.Point{T: ts, V: ss.sketch.Count()},
+				Metric: ss.metric,
+			})
+			continue
+		}
+		ss.sketch.ForEach(func(register int, rank uint8) {
+			e.lb.Reset(ss.metric)
+			e.lb.Set(HyperLogLogRegisterLabel, strconv.Itoa(register))
+			result = append(result, promql.Sample{
+				Point:  promql.Point{T: ts, V: float64(rank)},
+				Metric: e.lb.Labels(),
+			})
+		})
+	}
+	return true, ts, result
+}
+
+func (e *countDistinctEvaluator) Close() error { return e.iter.Close() }
+
+func (e *countDistinctEvaluator) Error() error {
+	if e.err != nil {
+		return e.err
+	}
+	return e.iter.Error()
+}
+
+// countDistinctMergeEvaluator rebuilds the sketches of each series from the samples of their registers,
+// and estimates the number of distinct values of the merged sketches.
+func countDistinctMergeEvaluator(
+	ctx context.Context,
+	ev SampleEvaluator,
+	expr *CountDistinctMergeExpr,
+	q Params,
+) (StepEvaluator, error) {
+	nextEvaluator, err := ev.StepEvaluator(ctx, ev, expr.Left, q)
+	if err != nil {
+		return nil, err
+	}
+	var (
+		mergeErr error
+		lb       = labels.NewBuilder(nil)
+		buf      = make([]byte, 0, 1024)
+	)
+	return newStepEvaluator(func() (bool, int64, promql.Vector) {
+		next, ts, vec := nextEvaluator.Next()
+		if !next {
+			return false, 0, promql.Vector{}
+		}
+		var (
+			series   []*seriesHyperLogLog
+			byHash   = map[uint64]*seriesHyperLogLog{}
+			seriesID uint64
+		)
+		for _, s := range vec {
+			seriesID, buf = s.Metric.HashWithoutLabels(buf, HyperLogLogRegisterLabel)
+			ss, ok := byHash[seriesID]
+			if !ok {
+				h, err := sketch.NewHyperLogLog(sketch.HyperLogLogPrecision)
+				if err != nil {
+					mergeErr = err
+					return false, 0, promql.Vector{}
+				}
+				lb.Reset(s.Metric)
+				lb.Del(HyperLogLogRegisterLabel)
+				ss = &seriesHyperLogLog{metric: lb.Labels(), sketch: h}
+				byHash[seriesID] = ss
+				series = append(series, ss)
+			}
+			register, err := strconv.Atoi(s.Metric.Get(HyperLogLogRegisterLabel))
+			if err != nil {
+				mergeErr = fmt.Errorf("invalid sketch register: %w", err)
+				return false, 0, promql.Vector{}
+			}
+			if err := ss.sketch.SetRegister(register, uint8(s.V)); err != nil {
+				mergeErr = err
+				return false, 0, promql.Vector{}
+			}
+		}
+		result := make(promql.Vector, 0, len(series))
+		for _, ss := range series {
+			result = append(result, promql.Sample{
+				Point:  promql.Point{T: ts, V: ss.sketch.Count()},
+				Metric: ss.metric,
+			})
+		}
+		return true, ts, result
+	}, nextEvaluator.Close, func() error {
+		if mergeErr != nil {
+			return mergeErr
+		}
+		return nextEvaluator.Error()
+	})
+}
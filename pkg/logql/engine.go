@@ -205,6 +205,7 @@ func (q *query) evalSample(ctx context.Context, expr SampleExpr) (promql_parser.
 
 	seriesIndex := map[uint64]*promql.Series{}
 	maxSeries := q.limits.MaxQuerySeries(userID)
+	sketches := newSketchSeriesCounter(expr)
 
 	next, ts, vec := stepEvaluator.Next()
 	if stepEvaluator.Error() != nil {
@@ -212,7 +213,11 @@ func (q *query) evalSample(ctx context.Context, expr SampleExpr) (promql_parser.
 	}
 
 	// fail fast for the first step or instant query
-	if len(vec) > maxSeries {
+	if sketches != nil {
+		if sketches.add(vec) > maxSeries {
+			return nil, logqlmodel.NewSeriesLimitError(maxSeries)
+		}
+	} else if len(vec) > maxSeries {
 		return nil, logqlmodel.NewSeriesLimitError(maxSeries)
 	}
 
@@ -248,7 +253,11 @@ func (q *query) evalSample(ctx context.Context, expr SampleExpr) (promql_parser.
 			})
 		}
 		// as we slowly build the full query for each steps, make sure we don't go over the limit of unique series.
-		if len(seriesIndex) > maxSeries {
+		if sketches != nil {
+			if sketches.add(vec) > maxSeries {
+				return nil, logqlmodel.NewSeriesLimitError(maxSeries)
+			}
+		} else if len(seriesIndex) > maxSeries {
 			return nil, logqlmodel.NewSeriesLimitError(maxSeries)
 		}
 		next, ts, vec = stepEvaluator.Next()
@@ -267,6 +276,46 @@ func (q *query) evalSample(ctx context.Context, expr SampleExpr) (promql_parser.
 	return result, stepEvaluator.Error()
 }
 
+// sketchSeriesCounter counts the series of the sketches sent to the frontend by the sharded queries,
+// each sample of a sketch being labeled with the cell of the sketch it holds.
+type sketchSeriesCounter struct {
+	hashes map[uint64]struct{}
+	buf    []byte
+}
+
+// sketchCellLabels are the labels of the sketch cells, which are sorted for HashWithoutLabels.
+var sketchCellLabels = []string{CountMinSketchCounterLabel, HyperLogLogRegisterLabel, QuantileSketchBucketLabel}
+
+// newSketchSeriesCounter returns a counter if the expression returns sketches, nil otherwise.
+func newSketchSeriesCounter(expr SampleExpr) *sketchSeriesCounter {
+	switch e := expr.(type) {
+	case *RangeAggregationExpr:
+		if e.Operation != OpRangeTypeQuantileSketch && e.Operation != OpRangeTypeApproxCountDistinctSketch {
+			return nil
+		}
+	case *VectorAggregationExpr:
+		if e.Operation != OpTypeApproxTopKSketch {
+			return nil
+		}
+	default:
+		return nil
+	}
+	return &sketchSeriesCounter{
+		hashes: map[uint64]struct{}{},
+		buf:    make([]byte, 0, 1024),
+	}
+}
+
+// add counts the series of the samples, the cells of a sketch counting as a single series, and returns the number of series so far.
+func (c *sketchSeriesCounter) add(vec promql.Vector) int {
+	var hash uint64
+	for _, s := range vec {
+		hash, c.buf = s.Metric.HashWithoutLabels(c.buf, sketchCellLabels...)
+		c.hashes[hash] = struct{}{}
+	}
+	return len(c.hashes)
+}
+
 func (q *query) evalLiteral(_ context.Context, expr *LiteralExpr) (promql_parser.Value, error) {
 	s := promql.Scalar{
 		T: q.params.Start().UnixNano() / int64(time.Millisecond),
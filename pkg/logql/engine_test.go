@@ -207,6 +207,30 @@ func TestEngine_LogsInstantQuery(t *testing.T) {
 			[]SelectSampleParams{},
 			promql.Vector{promql.Sample{Point: promql.Point{T: 5 * 60 * 1000, V: 1}, Metric: labels.Labels{labels.Label{Name: "app", Value: "foo"}}}},
 		},
+		{
+			// count_over_time is evaluated every 20s from 60s: 3 at 60s, 4 at 80s, 2 at 100s.
+			`max_over_time(count_over_time({app="foo"}[30s])[1m:20s])`, time.Unix(120, 0), logproto.FORWARD, 10,
+			[][]logproto.Series{
+				{
+					{
+						Labels: `{app="foo"}`,
+						Samples: []logproto.Sample{
+							{Timestamp: time.Unix(35, 0).UnixNano(), Hash: 1, Value: 1.},
+							{Timestamp: time.Unix(50, 0).UnixNano(), Hash: 2, Value: 1.},
+							{Timestamp: time.Unix(55, 0).UnixNano(), Hash: 3, Value: 1.},
+							{Timestamp: time.Unix(70, 0).UnixNano(), Hash: 4, Value: 1.},
+							{Timestamp: time.Unix(75, 0).UnixNano(), Hash: 5, Value: 1.},
+							{Timestamp: time.Unix(78, 0).UnixNano(), Hash: 6, Value: 1.},
+							{Timestamp: time.Unix(150, 0).UnixNano(), Hash: 7, Value: 1.},
+						},
+					},
+				},
+			},
+			[]SelectSampleParams{
+				{&logproto.SampleQueryRequest{Start: time.Unix(30, 0), End: time.Unix(120, 0), Selector: `count_over_time({app="foo"}[30s])`}},
+			},
+			promql.Vector{promql.Sample{Point: promql.Point{T: 120 * 1000, V: 4}, Metric: labels.Labels{labels.Label{Name: "app", Value: "foo"}}}},
+		},
 		{
 			`avg(count_over_time({app=~"foo|bar"} |~".+bar" [1m]))`, time.Unix(60, 0), logproto.FORWARD, 100,
 			[][]logproto.Series{
@@ -2022,6 +2046,59 @@ func TestEngine_RangeQuery(t *testing.T) {
 				},
 			},
 		},
+		{
+			// count_over_time is evaluated every 20s from 60s: 3 at 60s, 4 at 80s, 2 at 100s and 1 at 160s.
+			`max_over_time(count_over_time({app="foo"}[30s])[1m:20s])`, time.Unix(120, 0), time.Unix(180, 0), time.Minute, 0, logproto.FORWARD, 10,
+			[][]logproto.Series{
+				{
+					{
+						Labels: `{app="foo"}`,
+						Samples: []logproto.Sample{
+							{Timestamp: time.Unix(35, 0).UnixNano(), Hash: 1, Value: 1.},
+							{Timestamp: time.Unix(50, 0).UnixNano(), Hash: 2, Value: 1.},
+							{Timestamp: time.Unix(55, 0).UnixNano(), Hash: 3, Value: 1.},
+							{Timestamp: time.Unix(70, 0).UnixNano(), Hash: 4, Value: 1.},
+							{Timestamp: time.Unix(75, 0).UnixNano(), Hash: 5, Value: 1.},
+							{Timestamp: time.Unix(78, 0).UnixNano(), Hash: 6, Value: 1.},
+							{Timestamp: time.Unix(150, 0).UnixNano(), Hash: 7, Value: 1.},
+						},
+					},
+				},
+			},
+			[]SelectSampleParams{
+				{&logproto.SampleQueryRequest{Start: time.Unix(30, 0), End: time.Unix(180, 0), Selector: `count_over_time({app="foo"}[30s])`}},
+			},
+			promql.Matrix{
+				promql.Series{
+					Metric: labels.Labels{{Name: "app", Value: "foo"}},
+					Points: []promql.Point{{T: 120 * 1000, V: 4}, {T: 180 * 1000, V: 1}},
+				},
+			},
+		},
+		{
+			// the evaluation of the subquery is aligned on its step: 4 at 80s, 2 at 100s and nothing at 120s.
+			`max_over_time(count_over_time({app="foo"}[30s])[50s:20s] offset 10s)`, time.Unix(130, 0), time.Unix(130, 0), 0, 0, logproto.FORWARD, 10,
+			[][]logproto.Series{
+				{
+					{
+						Labels: `{app="foo"}`,
+						Samples: []logproto.Sample{
+							{Timestamp: time.Unix(35, 0).UnixNano(), Hash: 1, Value: 1.},
+							{Timestamp: time.Unix(50, 0).UnixNano(), Hash: 2, Value: 1.},
+							{Timestamp: time.Unix(55, 0).UnixNano(), Hash: 3, Value: 1.},
+							{Timestamp: time.Unix(70, 0).UnixNano(), Hash: 4, Value: 1.},
+							{Timestamp: time.Unix(75, 0).UnixNano(), Hash: 5, Value: 1.},
+							{Timestamp: time.Unix(78, 0).UnixNano(), Hash: 6, Value: 1.},
+							{Timestamp: time.Unix(150, 0).UnixNano(), Hash: 7, Value: 1.},
+						},
+					},
+				},
+			},
+			[]SelectSampleParams{
+				{&logproto.SampleQueryRequest{Start: time.Unix(50, 0), End: time.Unix(120, 0), Selector: `count_over_time({app="foo"}[30s])`}},
+			},
+			promql.Vector{promql.Sample{Point: promql.Point{T: 130 * 1000, V: 4}, Metric: labels.Labels{labels.Label{Name: "app", Value: "foo"}}}},
+		},
 	} {
 		test := test
 		t.Run(fmt.Sprintf("%s %s", test.qs, test.direction), func(t *testing.T) {
@@ -2185,6 +2262,47 @@ func TestEngine_MaxSeries(t *testing.T) {
 	}
 }
 
+func TestEngine_MaxSeries_Sketches(t *testing.T) {
+	querier := &querierRecorder{
+		series: map[string][]logproto.Series{
+			"": {
+				newSeries(1000, identity, `{app="foo",bar="foo"}`),
+				newSeries(1000, identity, `{app="foo",bar="bazz"}`),
+				newSeries(1000, identity, `{app="foo",bar="fuzz"}`),
+			},
+		},
+	}
+	eng := NewEngine(EngineOpts{}, querier, &fakeLimits{maxSeries: 1}, log.NewNopLogger())
+
+	for _, test := range []struct {
+		qs             string
+		expectLimitErr bool
+	}{
+		// the registers of a sketch count as a single series.
+		{`approx_count_distinct_sketch_over_time(bar, {app="foo"}[1m])`, false},
+		{`approx_count_distinct_sketch_over_time(app, {app="foo"}[1m])`, true},
+		{`approx_count_distinct_over_time(app, {app="foo"}[1m])`, true},
+	} {
+		t.Run(test.qs, func(t *testing.T) {
+			q := eng.Query(LiteralParams{
+				qs:        test.qs,
+				start:     time.Unix(0, 0),
+				end:       time.Unix(1000, 0),
+				step:      60 * time.Second,
+				direction: logproto.FORWARD,
+				limit:     1000,
+			})
+			res, err := q.Exec(user.InjectOrgID(context.Background(), "fake"))
+			if test.expectLimitErr {
+				require.True(t, errors.Is(err, logqlmodel.ErrLimit))
+				return
+			}
+			require.NoError(t, err)
+			require.Greater(t, len(res.Data.(promql.Matrix)), 1)
+		})
+	}
+}
+
 // go test -mod=vendor ./pkg/logql/ -bench=.  -benchmem -memprofile memprofile.out -cpuprofile cpuprofile.out
 func BenchmarkRangeQuery100000(b *testing.B) {
 	benchmarkRangeQuery(int64(100000), b)
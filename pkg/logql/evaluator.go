@@ -169,6 +169,13 @@ func (ev *DefaultEvaluator) StepEvaluator(
 ) (StepEvaluator, error) {
 	switch e := expr.(type) {
 	case *VectorAggregationExpr:
+		switch e.Operation {
+		case OpTypeApproxTopK:
+			// approx_topk is only approximated when sharded.
+			e = &VectorAggregationExpr{Left: e.Left, Grouping: e.Grouping, Params: e.Params, Operation: OpTypeTopK}
+		case OpTypeApproxTopKSketch:
+			return approxTopKSketchEvaluator(ctx, nextEv, e, q)
+		}
 		if rangExpr, ok := e.Left.(*RangeAggregationExpr); ok && e.Operation == OpTypeSum {
 			// if range expression is wrapped with a vector expression
 			// we should send the vector expression for allowing reducing labels at the source.
@@ -201,6 +208,14 @@ func (ev *DefaultEvaluator) StepEvaluator(
 			return nil, err
 		}
 		return rangeAggEvaluator(iter.NewPeekingSampleIterator(it), e, q, e.Left.Offset)
+	case *SubqueryAggregationExpr:
+		return subqueryAggEvaluator(ctx, nextEv, e, q)
+	case *QuantileSketchMergeExpr:
+		return quantileSketchMergeEvaluator(ctx, nextEv, e, q)
+	case *CountDistinctMergeExpr:
+		return countDistinctMergeEvaluator(ctx, nextEv, e, q)
+	case *ApproxTopKMergeExpr:
+		return approxTopKMergeEvaluator(ctx, nextEv, e, q)
 	case *BinOpExpr:
 		return binOpStepEvaluator(ctx, nextEv, e, q)
 	case *LabelReplaceExpr:
@@ -409,21 +424,33 @@ func rangeAggEvaluator(
 	q Params,
 	o time.Duration,
 ) (StepEvaluator, error) {
-	agg, err := expr.aggregator()
-	if err != nil {
-		return nil, err
-	}
 	iter := newRangeVectorIterator(
 		it,
 		expr.Left.Interval.Nanoseconds(),
 		q.Step().Nanoseconds(),
 		q.Start().UnixNano(), q.End().UnixNano(), o.Nanoseconds(),
 	)
-	if expr.Operation == OpRangeTypeAbsent {
+	switch expr.Operation {
+	case OpRangeTypeAbsent:
 		return &absentRangeVectorEvaluator{
 			iter: iter,
 			lbs:  absentLabels(expr),
 		}, nil
+	case OpRangeTypeQuantileSketch:
+		return &quantileSketchEvaluator{
+			iter:             iter,
+			relativeAccuracy: *expr.Params,
+		}, nil
+	case OpRangeTypeApproxCountDistinct, OpRangeTypeApproxCountDistinctSketch:
+		return &countDistinctEvaluator{
+			iter:     iter,
+			label:    expr.Label,
+			sketches: expr.Operation == OpRangeTypeApproxCountDistinctSketch,
+		}, nil
+	}
+	agg, err := expr.aggregator()
+	if err != nil {
+		return nil, err
 	}
 	return &rangeVectorEvaluator{
 		iter: iter,
@@ -507,6 +534,92 @@ func (r absentRangeVectorEvaluator) Error() error {
 	return r.iter.Error()
 }
 
+// subqueryAggEvaluator aggregates over a range the samples of the query of a subquery.
+// As in PromQL, the query is evaluated at the multiples of the subquery step, from the start
+// of the first range to the end of the last one.
+func subqueryAggEvaluator(
+	ctx context.Context,
+	ev SampleEvaluator,
+	expr *SubqueryAggregationExpr,
+	q Params,
+) (StepEvaluator, error) {
+	agg, err := expr.aggregator()
+	if err != nil {
+		return nil, err
+	}
+	sub := expr.Left
+	step := sub.EvaluationStep().Nanoseconds()
+	start := q.Start().Add(-sub.Interval).Add(-sub.Offset).UnixNano()
+	alignedStart := step * (start / step)
+	if alignedStart < start {
+		alignedStart += step
+	}
+	params := NewLiteralParams(
+		sub.Left.String(),
+		time.Unix(0, alignedStart),
+		q.End().Add(-sub.Offset),
+		sub.EvaluationStep(),
+		0,
+		q.Direction(),
+		q.Limit(),
+		q.Shards(),
+	)
+	nextEvaluator, err := ev.StepEvaluator(ctx, ev, sub.Left, params)
+	if err != nil {
+		return nil, err
+	}
+	iter := newRangeVectorIterator(
+		iter.NewPeekingSampleIterator(&stepEvaluatorSampleIterator{ev: nextEvaluator}),
+		sub.Interval.Nanoseconds(),
+		q.Step().Nanoseconds(),
+		q.Start().UnixNano(), q.End().UnixNano(), sub.Offset.Nanoseconds(),
+	)
+	if expr.Operation == OpRangeTypeAbsent {
+		return &absentRangeVectorEvaluator{
+			iter: iter,
+			lbs:  absentLabels(expr),
+		}, nil
+	}
+	return &rangeVectorEvaluator{
+		iter: iter,
+		agg:  agg,
+	}, nil
+}
+
+// stepEvaluatorSampleIterator iterates over the samples of the vectors of a step evaluator.
+type stepEvaluatorSampleIterator struct {
+	ev StepEvaluator
+
+	ts  int64
+	vec promql.Vector
+	cur int
+}
+
+func (it *stepEvaluatorSampleIterator) Next() bool {
+	it.cur++
+	for it.cur >= len(it.vec) {
+		next, ts, vec := it.ev.Next()
+		if !next {
+			it.vec = nil
+			return false
+		}
+		it.ts, it.vec, it.cur = ts, vec, 0
+	}
+	return true
+}
+
+func (it *stepEvaluatorSampleIterator) Sample() logproto.Sample {
+	// step evaluators work with milliseconds timestamps while sample iterators use nanoseconds.
+	return logproto.Sample{
+		Timestamp: it.ts * int64(time.Millisecond),
+		Value:     it.vec[it.cur].V,
+	}
+}
+
+func (it *stepEvaluatorSampleIterator) Labels() string { return it.vec[it.cur].Metric.String() }
+func (it *stepEvaluatorSampleIterator) Error() error   { return it.ev.Error() }
+func (it *stepEvaluatorSampleIterator) Close() error   { return it.ev.Close() }
+
 // binOpExpr explicitly does not handle when both legs are literals as
 // it makes the type system simpler and these are reduced in mustNewBinOpExpr
 func binOpStepEvaluator(
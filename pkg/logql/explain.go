@@ -0,0 +1,276 @@
+package logql
+
+import (
+	"fmt"
+
+	"github.com/grafana/loki/pkg/logql/log"
+)
+
+// Types of the nodes of an explained expression.
+const (
+	ExplainSelector            = "selector"
+	ExplainPipeline            = "pipeline"
+	ExplainLineFilter          = "line_filter"
+	ExplainParser              = "parser"
+	ExplainLabelFilter         = "label_filter"
+	ExplainLineFormat          = "line_format"
+	ExplainLabelFormat         = "label_format"
+	ExplainLogRange            = "log_range"
+	ExplainUnwrap              = "unwrap"
+	ExplainRangeAggregation    = "range_aggregation"
+	ExplainSubquery            = "subquery"
+	ExplainSubqueryAggregation = "subquery_aggregation"
+	ExplainVectorAggregation   = "vector_aggregation"
+	ExplainBinaryOperation     = "binary_operation"
+	ExplainLiteral             = "literal"
+	ExplainLabelReplace        = "label_replace"
+
+	// ExplainDownstream is a query sent to the queriers for a shard.
+	ExplainDownstream = "downstream"
+	// ExplainConcat concatenates the results of its downstream queries.
+	ExplainConcat = "concat"
+	// ExplainMerge merges the sketches returned by the shards of its downstream queries.
+	ExplainMerge = "merge"
+	// ExplainNotShardable is a part of a sharded query evaluated over all the streams at once.
+	ExplainNotShardable = "not_shardable"
+)
+
+// ExplainedExpr is a node of the tree of a LogQL expression.
+type ExplainedExpr struct {
+	Type      string          `json:"type"`
+	Operation string          `json:"operation,omitempty"`
+	Expr      string          `json:"expr"`
+	Shard     string          `json:"shard,omitempty"`
+	Children  []ExplainedExpr `json:"children,omitempty"`
+}
+
+// ParserHints are the hints given to the parsers of a query sent to the queriers.
+// Parsers extract all the labels when there are neither required labels nor NoLabels.
+type ParserHints struct {
+	Expr           string   `json:"expr"`
+	RequiredLabels []string `json:"required_labels,omitempty"`
+	NoLabels       bool     `json:"no_labels"`
+}
+
+// Explanation describes how the engine evaluates a query.
+type Explanation struct {
+	AST ExplainedExpr `json:"ast"`
+	// Optimized is the metric query evaluated in place of the query, empty for log queries.
+	Optimized   string        `json:"optimized,omitempty"`
+	ParserHints []ParserHints `json:"parser_hints"`
+}
+
+// Explain parses a query and describes how the engine evaluates it.
+func Explain(query string) (*Explanation, error) {
+	expr, err := ParseExpr(query)
+	if err != nil {
+		return nil, err
+	}
+	res := &Explanation{AST: explainer{}.explain(expr)}
+
+	if sampleExpr, ok := expr.(SampleExpr); ok {
+		optimized, err := optimizeSampleExpr(sampleExpr)
+		if err != nil {
+			return nil, err
+		}
+		res.Optimized = optimized.String()
+		expr = optimized
+	}
+
+	res.ParserHints, err = parserHints(expr, []ParserHints{})
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// ExplainShards returns the tree of an expression mapped by the ShardMapper, showing which of its parts are
+// downstreamed to the queriers for each shard and how their results are combined.
+func ExplainShards(mapped Expr) ExplainedExpr {
+	return explainer{shards: true}.explain(mapped)
+}
+
+type explainer struct {
+	// shards is set for expressions mapped for sharding, whose selectors and range aggregations are not shardable
+	// when they are not downstreamed.
+	shards bool
+}
+
+func (x explainer) explain(expr Expr) ExplainedExpr {
+	node := ExplainedExpr{Expr: expr.String()}
+	switch e := expr.(type) {
+	case *MatchersExpr:
+		node.Type = ExplainSelector
+	case *PipelineExpr:
+		node.Type = ExplainPipeline
+		node.Children = append(node.Children, x.explain(e.Left))
+		for _, stage := range e.MultiStages {
+			node.Children = append(node.Children, explainStage(stage))
+		}
+	case *LogRange:
+		node.Type = ExplainLogRange
+		node.Children = append(node.Children, x.explain(e.Left))
+		if e.Unwrap != nil {
+			node.Children = append(node.Children, ExplainedExpr{
+				Type:      ExplainUnwrap,
+				Operation: e.Unwrap.Operation,
+				Expr:      e.Unwrap.String(),
+			})
+		}
+	case *RangeAggregationExpr:
+		node.Type = ExplainRangeAggregation
+		node.Operation = e.Operation
+		node.Children = append(node.Children, x.explain(e.Left))
+	case *SubqueryExpr:
+		node.Type = ExplainSubquery
+		node.Children = append(node.Children, x.explain(e.Left))
+	case *SubqueryAggregationExpr:
+		node.Type = ExplainSubqueryAggregation
+		node.Operation = e.Operation
+		node.Children = append(node.Children, x.explain(e.Left))
+	case *VectorAggregationExpr:
+		node.Type = ExplainVectorAggregation
+		node.Operation = e.Operation
+		node.Children = append(node.Children, x.explain(e.Left))
+	case *BinOpExpr:
+		node.Type = ExplainBinaryOperation
+		node.Operation = e.Op
+		node.Children = append(node.Children, x.explain(e.SampleExpr), x.explain(e.RHS))
+	case *LiteralExpr:
+		node.Type = ExplainLiteral
+	case *LabelReplaceExpr:
+		node.Type = ExplainLabelReplace
+		node.Children = append(node.Children, x.explain(e.Left))
+	case DownstreamSampleExpr:
+		return explainDownstream(e.SampleExpr, e.shard.String())
+	case DownstreamLogSelectorExpr:
+		return explainDownstream(e.LogSelectorExpr, e.shard.String())
+	case *ConcatSampleExpr:
+		node.Type = ExplainConcat
+		for cur := e; cur != nil; cur = cur.next {
+			node.Children = append(node.Children, x.explain(cur.DownstreamSampleExpr))
+		}
+	case *ConcatLogSelectorExpr:
+		node.Type = ExplainConcat
+		for cur := e; cur != nil; cur = cur.next {
+			node.Children = append(node.Children, x.explain(cur.DownstreamLogSelectorExpr))
+		}
+	case *QuantileSketchMergeExpr:
+		node.Type = ExplainMerge
+		node.Operation = OpRangeTypeQuantileSketch
+		node.Children = append(node.Children, x.explain(e.Left))
+	case *CountDistinctMergeExpr:
+		node.Type = ExplainMerge
+		node.Operation = OpRangeTypeApproxCountDistinctSketch
+		node.Children = append(node.Children, x.explain(e.Left))
+	case *ApproxTopKMergeExpr:
+		node.Type = ExplainMerge
+		node.Operation = OpTypeApproxTopKSketch
+		node.Children = append(node.Children, x.explain(e.Left))
+	default:
+		node.Type = fmt.Sprintf("%T", expr)
+	}
+
+	if x.shards {
+		switch node.Type {
+		case ExplainSelector, ExplainPipeline, ExplainRangeAggregation:
+			return ExplainedExpr{Type: ExplainNotShardable, Expr: node.Expr}
+		}
+	}
+	return node
+}
+
+func explainDownstream(expr Expr, shard string) ExplainedExpr {
+	return ExplainedExpr{Type: ExplainDownstream, Expr: expr.String(), Shard: shard}
+}
+
+func explainStage(stage StageExpr) ExplainedExpr {
+	node := ExplainedExpr{Expr: stage.String()}
+	switch e := stage.(type) {
+	case *LineFilterExpr:
+		node.Type = ExplainLineFilter
+	case *LabelParserExpr:
+		node.Type = ExplainParser
+		node.Operation = e.Op
+	case *JSONExpressionParser:
+		node.Type = ExplainParser
+		node.Operation = OpParserTypeJSON
+	case *XMLExpressionParser:
+		node.Type = ExplainParser
+		node.Operation = OpParserTypeXML
+	case *LabelFilterExpr:
+		node.Type = ExplainLabelFilter
+	case *LineFmtExpr:
+		node.Type = ExplainLineFormat
+	case *LabelFmtExpr:
+		node.Type = ExplainLabelFormat
+	default:
+		node.Type = fmt.Sprintf("%T", stage)
+	}
+	return node
+}
+
+// parserHints appends the parser hints of each query the engine sends to the queriers to evaluate expr.
+func parserHints(expr Expr, hints []ParserHints) ([]ParserHints, error) {
+	err := walkQuerierExprs(expr, func(e Expr) error {
+		sampleExpr, ok := e.(SampleExpr)
+		if !ok {
+			// log queries extract all labels.
+			hints = append(hints, ParserHints{Expr: e.String()})
+			return nil
+		}
+		required, noLabels, err := extractorHints(sampleExpr)
+		if err != nil {
+			return err
+		}
+		hints = append(hints, ParserHints{
+			Expr:           sampleExpr.String(),
+			RequiredLabels: required,
+			NoLabels:       noLabels,
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return hints, nil
+}
+
+// walkQuerierExprs calls f with each of the queries the engine sends to the queriers to evaluate expr:
+// log selectors and range aggregations, possibly summed.
+func walkQuerierExprs(expr Expr, f func(Expr) error) error {
+	switch e := expr.(type) {
+	case *LiteralExpr:
+		return nil
+	case LogSelectorExpr:
+		return f(e)
+	case *VectorAggregationExpr:
+		// the evaluator sends the sum of a range aggregation for the queriers to reduce the labels.
+		if _, ok := e.Left.(*RangeAggregationExpr); ok && e.Operation == OpTypeSum {
+			return f(e)
+		}
+		return walkQuerierExprs(e.Left, f)
+	case *RangeAggregationExpr:
+		return f(e)
+	case *SubqueryAggregationExpr:
+		return walkQuerierExprs(e.Left.Left, f)
+	case *LabelReplaceExpr:
+		return walkQuerierExprs(e.Left, f)
+	case *BinOpExpr:
+		if err := walkQuerierExprs(e.SampleExpr, f); err != nil {
+			return err
+		}
+		return walkQuerierExprs(e.RHS, f)
+	default:
+		return nil
+	}
+}
+
+func extractorHints(expr SampleExpr) (requiredLabels []string, noLabels bool, err error) {
+	extractor, err := expr.Extractor()
+	if err != nil {
+		return nil, false, err
+	}
+	requiredLabels, noLabels = log.SampleExtractorHints(extractor)
+	return requiredLabels, noLabels, nil
+}
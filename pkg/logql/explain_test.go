@@ -0,0 +1,129 @@
+package logql
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestExplain(t *testing.T) {
+	res, err := Explain(`sum by (status) (rate({app="foo"} |= "err" | json | line_format "{{.msg}}" [5m])) / 2`)
+	require.NoError(t, err)
+
+	require.Equal(t, ExplainedExpr{
+		Type:      ExplainBinaryOperation,
+		Operation: OpTypeDiv,
+		Expr:      `(sum by(status)(rate({app="foo"} |= "err" | json | line_format "{{.msg}}"[5m])) / 2)`,
+		Children: []ExplainedExpr{
+			{
+				Type:      ExplainVectorAggregation,
+				Operation: OpTypeSum,
+				Expr:      `sum by(status)(rate({app="foo"} |= "err" | json | line_format "{{.msg}}"[5m]))`,
+				Children: []ExplainedExpr{
+					{
+						Type:      ExplainRangeAggregation,
+						Operation: OpRangeTypeRate,
+						Expr:      `rate({app="foo"} |= "err" | json | line_format "{{.msg}}"[5m])`,
+						Children: []ExplainedExpr{
+							{
+								Type: ExplainLogRange,
+								Expr: `{app="foo"} |= "err" | json | line_format "{{.msg}}"[5m]`,
+								Children: []ExplainedExpr{
+									{
+										Type: ExplainPipeline,
+										Expr: `{app="foo"} |= "err" | json | line_format "{{.msg}}"`,
+										Children: []ExplainedExpr{
+											{Type: ExplainSelector, Expr: `{app="foo"}`},
+											{Type: ExplainLineFilter, Expr: `|= "err"`},
+											{Type: ExplainParser, Operation: OpParserTypeJSON, Expr: `| json`},
+											{Type: ExplainLineFormat, Expr: `| line_format "{{.msg}}"`},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			{Type: ExplainLiteral, Expr: "2"},
+		},
+	}, res.AST)
+
+	// the line_format is removed, and only the status label is extracted by the json parser.
+	require.Equal(t, `(sum by(status)(rate({app="foo"} |= "err" | json[5m])) / 2)`, res.Optimized)
+	require.Equal(t, []ParserHints{
+		{Expr: `sum by(status)(rate({app="foo"} |= "err" | json[5m]))`, RequiredLabels: []string{"status"}},
+	}, res.ParserHints)
+}
+
+func TestExplain_ParserHints(t *testing.T) {
+	for _, tc := range []struct {
+		query    string
+		expected []ParserHints
+	}{
+		{
+			`{app="foo"} | logfmt`,
+			[]ParserHints{{Expr: `{app="foo"} | logfmt`}},
+		},
+		{
+			`sum(count_over_time({app="foo"} | logfmt [1m]))`,
+			[]ParserHints{{Expr: `sum(count_over_time({app="foo"} | logfmt[1m]))`, NoLabels: true}},
+		},
+		{
+			`max by (pod) (count_over_time({app="foo"} | logfmt [1m])) > count_over_time({app="bar"} | json | level="error" [1m])`,
+			[]ParserHints{
+				{Expr: `count_over_time({app="foo"} | logfmt[1m])`},
+				{Expr: `count_over_time({app="bar"} | json | level="error"[1m])`},
+			},
+		},
+		{
+			`avg_over_time({app="foo"} | logfmt | unwrap latency [1m]) by (pod)`,
+			[]ParserHints{{Expr: `avg_over_time({app="foo"} | logfmt | unwrap latency[1m]) by(pod)`, RequiredLabels: []string{"pod", "latency"}}},
+		},
+	} {
+		t.Run(tc.query, func(t *testing.T) {
+			res, err := Explain(tc.query)
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, res.ParserHints)
+		})
+	}
+}
+
+func TestExplainShards(t *testing.T) {
+	m, err := NewShardMapper(2, nilMetrics)
+	require.NoError(t, err)
+
+	_, mapped, err := m.Parse(`sum(rate({app="foo"}[1m])) / max(max_over_time({app="foo"} | unwrap latency [1m]))`)
+	require.NoError(t, err)
+
+	require.Equal(t, ExplainedExpr{
+		Type:      ExplainBinaryOperation,
+		Operation: OpTypeDiv,
+		Expr:      mapped.String(),
+		Children: []ExplainedExpr{
+			{
+				Type:      ExplainVectorAggregation,
+				Operation: OpTypeSum,
+				Expr:      `sum(downstream<sum(rate({app="foo"}[1m])), shard=0_of_2> ++ downstream<sum(rate({app="foo"}[1m])), shard=1_of_2>)`,
+				Children: []ExplainedExpr{
+					{
+						Type: ExplainConcat,
+						Expr: `downstream<sum(rate({app="foo"}[1m])), shard=0_of_2> ++ downstream<sum(rate({app="foo"}[1m])), shard=1_of_2>`,
+						Children: []ExplainedExpr{
+							{Type: ExplainDownstream, Expr: `sum(rate({app="foo"}[1m]))`, Shard: "0_of_2"},
+							{Type: ExplainDownstream, Expr: `sum(rate({app="foo"}[1m]))`, Shard: "1_of_2"},
+						},
+					},
+				},
+			},
+			{
+				Type:      ExplainVectorAggregation,
+				Operation: OpTypeMax,
+				Expr:      `max(max_over_time({app="foo"} | unwrap latency[1m]))`,
+				Children: []ExplainedExpr{
+					{Type: ExplainNotShardable, Expr: `max_over_time({app="foo"} | unwrap latency[1m])`},
+				},
+			},
+		},
+	}, ExplainShards(mapped))
+}
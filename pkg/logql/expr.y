@@ -12,7 +12,7 @@ import (
 %union{
   Expr                    Expr
   Filter                  labels.MatchType
-  Grouping                *grouping
+  Grouping                *Grouping
   Labels                  []string
   LogExpr                 LogSelectorExpr
   LogRangeExpr            *LogRange
@@ -32,6 +32,7 @@ import (
   bytes                   uint64
   str                     string
   duration                time.Duration
+  subqueryRange           subqueryRange
   LiteralExpr             *LiteralExpr
   BinOpModifier           *BinOpOptions
   BoolModifier            *BinOpOptions
@@ -39,6 +40,7 @@ import (
   LabelParser             *LabelParserExpr
   LineFilters             *LineFilterExpr
   LineFilter              *LineFilterExpr
+  OrMatches               []string
   PipelineExpr            MultiStageExpr
   PipelineStage           StageExpr
   BytesFilter             log.LabelFilterer
@@ -54,8 +56,12 @@ import (
   JSONExpressionParser    *JSONExpressionParser
   JSONExpression          log.JSONExpression
   JSONExpressionList      []log.JSONExpression
+  XMLExpressionParser     *XMLExpressionParser
+  XMLExpression           log.XMLExpression
+  XMLExpressionList       []log.XMLExpression
   UnwrapExpr              *UnwrapExpr
   OffsetExpr              *OffsetExpr
+  SubqueryExpr            *SubqueryExpr
 }
 
 %start root
@@ -91,6 +97,7 @@ import (
 %type <LabelFilter>           labelFilter
 %type <LineFilters>           lineFilters
 %type <LineFilter>            lineFilter
+%type <OrMatches>             orMatches
 %type <LineFormatExpr>        lineFormatExpr
 %type <LabelFormatExpr>       labelFormatExpr
 %type <LabelFormat>           labelFormat
@@ -98,19 +105,26 @@ import (
 %type <JSONExpressionParser>  jsonExpressionParser
 %type <JSONExpression>        jsonExpression
 %type <JSONExpressionList>    jsonExpressionList
+%type <XMLExpressionParser>   xmlExpressionParser
+%type <XMLExpression>         xmlExpression
+%type <XMLExpressionList>     xmlExpressionList
 %type <UnwrapExpr>            unwrapExpr
 %type <UnitFilter>            unitFilter
 %type <IPLabelFilter>         ipLabelFilter
 %type <OffsetExpr>            offsetExpr
+%type <SubqueryExpr>          subqueryExpr
 
 %token <bytes> BYTES
 %token <str>      IDENTIFIER STRING NUMBER
 %token <duration> DURATION RANGE
+%token <subqueryRange> SUBQUERY_RANGE
 %token <val>      MATCHERS LABELS EQ RE NRE OPEN_BRACE CLOSE_BRACE OPEN_BRACKET CLOSE_BRACKET COMMA DOT PIPE_MATCH PIPE_EXACT
                   OPEN_PARENTHESIS CLOSE_PARENTHESIS BY WITHOUT COUNT_OVER_TIME RATE SUM AVG MAX MIN COUNT STDDEV STDVAR BOTTOMK TOPK
                   BYTES_OVER_TIME BYTES_RATE BOOL JSON REGEXP LOGFMT PIPE LINE_FMT LABEL_FMT UNWRAP AVG_OVER_TIME SUM_OVER_TIME MIN_OVER_TIME
                   MAX_OVER_TIME STDVAR_OVER_TIME STDDEV_OVER_TIME QUANTILE_OVER_TIME BYTES_CONV DURATION_CONV DURATION_SECONDS_CONV
-                  FIRST_OVER_TIME LAST_OVER_TIME ABSENT_OVER_TIME LABEL_REPLACE UNPACK OFFSET PATTERN IP ON IGNORING GROUP_LEFT GROUP_RIGHT
+                  FIRST_OVER_TIME LAST_OVER_TIME ABSENT_OVER_TIME QUANTILE_SKETCH_OVER_TIME APPROX_COUNT_DISTINCT_OVER_TIME APPROX_COUNT_DISTINCT_SKETCH_OVER_TIME
+                  APPROX_TOPK APPROX_TOPK_SKETCH LABEL_REPLACE UNPACK OFFSET PATTERN IP ON IGNORING GROUP_LEFT GROUP_RIGHT
+                  XML CEF
 
 // Operators are listed with increasing precedence.
 %left <binOp> OR
@@ -190,6 +204,16 @@ rangeAggregationExpr:
     | rangeOp OPEN_PARENTHESIS NUMBER COMMA logRangeExpr CLOSE_PARENTHESIS           { $$ = newRangeAggregationExpr($5, $1, nil, &$3) }
     | rangeOp OPEN_PARENTHESIS logRangeExpr CLOSE_PARENTHESIS grouping               { $$ = newRangeAggregationExpr($3, $1, $5, nil) }
     | rangeOp OPEN_PARENTHESIS NUMBER COMMA logRangeExpr CLOSE_PARENTHESIS grouping  { $$ = newRangeAggregationExpr($5, $1, $7, &$3) }
+    | rangeOp OPEN_PARENTHESIS IDENTIFIER COMMA logRangeExpr CLOSE_PARENTHESIS           { $$ = newLabelRangeAggregationExpr($5, $1, nil, $3) }
+    | rangeOp OPEN_PARENTHESIS IDENTIFIER COMMA logRangeExpr CLOSE_PARENTHESIS grouping  { $$ = newLabelRangeAggregationExpr($5, $1, $7, $3) }
+    | rangeOp OPEN_PARENTHESIS subqueryExpr CLOSE_PARENTHESIS                        { $$ = newSubqueryAggregationExpr($3, $1, nil) }
+    | rangeOp OPEN_PARENTHESIS NUMBER COMMA subqueryExpr CLOSE_PARENTHESIS           { $$ = newSubqueryAggregationExpr($5, $1, &$3) }
+    ;
+
+subqueryExpr:
+      metricExpr SUBQUERY_RANGE                      { $$ = newSubqueryExpr($1, $2, nil) }
+    | metricExpr SUBQUERY_RANGE offsetExpr           { $$ = newSubqueryExpr($1, $2, $3) }
+    | OPEN_PARENTHESIS subqueryExpr CLOSE_PARENTHESIS { $$ = $2 }
     ;
 
 vectorAggregationExpr:
@@ -242,6 +266,7 @@ pipelineStage:
    lineFilters                   { $$ = $1 }
   | PIPE labelParser             { $$ = $2 }
   | PIPE jsonExpressionParser    { $$ = $2 }
+  | PIPE xmlExpressionParser     { $$ = $2 }
   | PIPE labelFilter             { $$ = &LabelFilterExpr{LabelFilterer: $2 }}
   | PIPE lineFormatExpr          { $$ = $2 }
   | PIPE labelFormatExpr         { $$ = $2 }
@@ -254,6 +279,12 @@ filterOp:
 lineFilter:
     filter STRING                                                   { $$ = newLineFilterExpr($1, "", $2) }
   | filter filterOp OPEN_PARENTHESIS STRING CLOSE_PARENTHESIS       { $$ = newLineFilterExpr($1, $2, $4) }
+  | filter orMatches                                                { $$ = newOrLineFilterExpr($1, $2) }
+  ;
+
+orMatches:
+    STRING OR STRING        { $$ = []string{ $1, $3 } }
+  | orMatches OR STRING     { $$ = append($1, $3) }
   ;
 
 lineFilters:
@@ -267,11 +298,16 @@ labelParser:
   | REGEXP STRING  { $$ = newLabelParserExpr(OpParserTypeRegexp, $2) }
   | UNPACK         { $$ = newLabelParserExpr(OpParserTypeUnpack, "") }
   | PATTERN STRING { $$ = newLabelParserExpr(OpParserTypePattern, $2) }
+  | XML            { $$ = newLabelParserExpr(OpParserTypeXML, "") }
+  | CEF            { $$ = newLabelParserExpr(OpParserTypeCEF, "") }
   ;
 
 jsonExpressionParser:
     JSON jsonExpressionList { $$ = newJSONExpressionParser($2) }
 
+xmlExpressionParser:
+    XML xmlExpressionList { $$ = newXMLExpressionParser($2) }
+
 lineFormatExpr: LINE_FMT STRING { $$ = newLineFmtExpr($2) };
 
 labelFormat:
@@ -307,6 +343,14 @@ jsonExpressionList:
   | jsonExpressionList COMMA jsonExpression { $$ = append($1, $3) }
   ;
 
+xmlExpression:
+    IDENTIFIER EQ STRING { $$ = log.NewXMLExpr($1, $3) }
+
+xmlExpressionList:
+    xmlExpression                         { $$ = []log.XMLExpression{$1} }
+  | xmlExpressionList COMMA xmlExpression { $$ = append($1, $3) }
+  ;
+
 ipLabelFilter:
     IDENTIFIER EQ IP OPEN_PARENTHESIS STRING CLOSE_PARENTHESIS { $$ = log.NewIPLabelFilter($5, $1,log.LabelFilterEqual) }
   | IDENTIFIER NEQ IP OPEN_PARENTHESIS STRING CLOSE_PARENTHESIS { $$ = log.NewIPLabelFilter($5, $1, log.LabelFilterNotEqual) }
@@ -451,6 +495,8 @@ vectorOp:
       | STDVAR  { $$ = OpTypeStdvar }
       | BOTTOMK { $$ = OpTypeBottomK }
       | TOPK    { $$ = OpTypeTopK }
+      | APPROX_TOPK        { $$ = OpTypeApproxTopK }
+      | APPROX_TOPK_SKETCH { $$ = OpTypeApproxTopKSketch }
       ;
 
 rangeOp:
@@ -468,6 +514,9 @@ rangeOp:
     | FIRST_OVER_TIME    { $$ = OpRangeTypeFirst }
     | LAST_OVER_TIME     { $$ = OpRangeTypeLast }
     | ABSENT_OVER_TIME   { $$ = OpRangeTypeAbsent }
+    | QUANTILE_SKETCH_OVER_TIME { $$ = OpRangeTypeQuantileSketch }
+    | APPROX_COUNT_DISTINCT_OVER_TIME        { $$ = OpRangeTypeApproxCountDistinct }
+    | APPROX_COUNT_DISTINCT_SKETCH_OVER_TIME { $$ = OpRangeTypeApproxCountDistinctSketch }
     ;
 
 offsetExpr:
@@ -479,9 +528,9 @@ labels:
     ;
 
 grouping:
-      BY OPEN_PARENTHESIS labels CLOSE_PARENTHESIS        { $$ = &grouping{ without: false , groups: $3 } }
-    | WITHOUT OPEN_PARENTHESIS labels CLOSE_PARENTHESIS   { $$ = &grouping{ without: true , groups: $3 } }
-    | BY OPEN_PARENTHESIS CLOSE_PARENTHESIS               { $$ = &grouping{ without: false , groups: nil } }
-    | WITHOUT OPEN_PARENTHESIS CLOSE_PARENTHESIS          { $$ = &grouping{ without: true , groups: nil } }
+      BY OPEN_PARENTHESIS labels CLOSE_PARENTHESIS        { $$ = &Grouping{ Without: false , Groups: $3 } }
+    | WITHOUT OPEN_PARENTHESIS labels CLOSE_PARENTHESIS   { $$ = &Grouping{ Without: true , Groups: $3 } }
+    | BY OPEN_PARENTHESIS CLOSE_PARENTHESIS               { $$ = &Grouping{ Without: false , Groups: nil } }
+    | WITHOUT OPEN_PARENTHESIS CLOSE_PARENTHESIS          { $$ = &Grouping{ Without: true , Groups: nil } }
     ;
 %%
@@ -35,6 +35,7 @@ type exprSymType struct {
 	bytes                 uint64
 	str                   string
 	duration              time.Duration
+	subqueryRange         subqueryRange
 	LiteralExpr           *LiteralExpr
 	BinOpModifier         *BinOpOptions
 	BoolModifier          *BinOpOptions
@@ -42,6 +43,7 @@ type exprSymType struct {
 	LabelParser           *LabelParserExpr
 	LineFilters           *LineFilterExpr
 	LineFilter            *LineFilterExpr
+	OrMatches             []string
 	PipelineExpr          MultiStageExpr
 	PipelineStage         StageExpr
 	BytesFilter           log.LabelFilterer
@@ -57,8 +59,12 @@ type exprSymType struct {
 	JSONExpressionParser  *JSONExpressionParser
 	JSONExpression        log.JSONExpression
 	JSONExpressionList    []log.JSONExpression
+	XMLExpressionParser   *XMLExpressionParser
+	XMLExpression         log.XMLExpression
+	XMLExpressionList     []log.XMLExpression
 	UnwrapExpr            *UnwrapExpr
 	OffsetExpr            *OffsetExpr
+	SubqueryExpr          *SubqueryExpr
 }
 
 const BYTES = 57346
@@ -67,81 +73,89 @@ const STRING = 57348
 const NUMBER = 57349
 const DURATION = 57350
 const RANGE = 57351
-const MATCHERS = 57352
-const LABELS = 57353
-const EQ = 57354
-const RE = 57355
-const NRE = 57356
-const OPEN_BRACE = 57357
-const CLOSE_BRACE = 57358
-const OPEN_BRACKET = 57359
-const CLOSE_BRACKET = 57360
-const COMMA = 57361
-const DOT = 57362
-const PIPE_MATCH = 57363
-const PIPE_EXACT = 57364
-const OPEN_PARENTHESIS = 57365
-const CLOSE_PARENTHESIS = 57366
-const BY = 57367
-const WITHOUT = 57368
-const COUNT_OVER_TIME = 57369
-const RATE = 57370
-const SUM = 57371
-const AVG = 57372
-const MAX = 57373
-const MIN = 57374
-const COUNT = 57375
-const STDDEV = 57376
-const STDVAR = 57377
-const BOTTOMK = 57378
-const TOPK = 57379
-const BYTES_OVER_TIME = 57380
-const BYTES_RATE = 57381
-const BOOL = 57382
-const JSON = 57383
-const REGEXP = 57384
-const LOGFMT = 57385
-const PIPE = 57386
-const LINE_FMT = 57387
-const LABEL_FMT = 57388
-const UNWRAP = 57389
-const AVG_OVER_TIME = 57390
-const SUM_OVER_TIME = 57391
-const MIN_OVER_TIME = 57392
-const MAX_OVER_TIME = 57393
-const STDVAR_OVER_TIME = 57394
-const STDDEV_OVER_TIME = 57395
-const QUANTILE_OVER_TIME = 57396
-const BYTES_CONV = 57397
-const DURATION_CONV = 57398
-const DURATION_SECONDS_CONV = 57399
-const FIRST_OVER_TIME = 57400
-const LAST_OVER_TIME = 57401
-const ABSENT_OVER_TIME = 57402
-const LABEL_REPLACE = 57403
-const UNPACK = 57404
-const OFFSET = 57405
-const PATTERN = 57406
-const IP = 57407
-const ON = 57408
-const IGNORING = 57409
-const GROUP_LEFT = 57410
-const GROUP_RIGHT = 57411
-const OR = 57412
-const AND = 57413
-const UNLESS = 57414
-const CMP_EQ = 57415
-const NEQ = 57416
-const LT = 57417
-const LTE = 57418
-const GT = 57419
-const GTE = 57420
-const ADD = 57421
-const SUB = 57422
-const MUL = 57423
-const DIV = 57424
-const MOD = 57425
-const POW = 57426
+const SUBQUERY_RANGE = 57352
+const MATCHERS = 57353
+const LABELS = 57354
+const EQ = 57355
+const RE = 57356
+const NRE = 57357
+const OPEN_BRACE = 57358
+const CLOSE_BRACE = 57359
+const OPEN_BRACKET = 57360
+const CLOSE_BRACKET = 57361
+const COMMA = 57362
+const DOT = 57363
+const PIPE_MATCH = 57364
+const PIPE_EXACT = 57365
+const OPEN_PARENTHESIS = 57366
+const CLOSE_PARENTHESIS = 57367
+const BY = 57368
+const WITHOUT = 57369
+const COUNT_OVER_TIME = 57370
+const RATE = 57371
+const SUM = 57372
+const AVG = 57373
+const MAX = 57374
+const MIN = 57375
+const COUNT = 57376
+const STDDEV = 57377
+const STDVAR = 57378
+const BOTTOMK = 57379
+const TOPK = 57380
+const BYTES_OVER_TIME = 57381
+const BYTES_RATE = 57382
+const BOOL = 57383
+const JSON = 57384
+const REGEXP = 57385
+const LOGFMT = 57386
+const PIPE = 57387
+const LINE_FMT = 57388
+const LABEL_FMT = 57389
+const UNWRAP = 57390
+const AVG_OVER_TIME = 57391
+const SUM_OVER_TIME = 57392
+const MIN_OVER_TIME = 57393
+const MAX_OVER_TIME = 57394
+const STDVAR_OVER_TIME = 57395
+const STDDEV_OVER_TIME = 57396
+const QUANTILE_OVER_TIME = 57397
+const BYTES_CONV = 57398
+const DURATION_CONV = 57399
+const DURATION_SECONDS_CONV = 57400
+const FIRST_OVER_TIME = 57401
+const LAST_OVER_TIME = 57402
+const ABSENT_OVER_TIME = 57403
+const QUANTILE_SKETCH_OVER_TIME = 57404
+const APPROX_COUNT_DISTINCT_OVER_TIME = 57405
+const APPROX_COUNT_DISTINCT_SKETCH_OVER_TIME = 57406
+const APPROX_TOPK = 57407
+const APPROX_TOPK_SKETCH = 57408
+const LABEL_REPLACE = 57409
+const UNPACK = 57410
+const OFFSET = 57411
+const PATTERN = 57412
+const IP = 57413
+const ON = 57414
+const IGNORING = 57415
+const GROUP_LEFT = 57416
+const GROUP_RIGHT = 57417
+const XML = 57418
+const CEF = 57419
+const OR = 57420
+const AND = 57421
+const UNLESS = 57422
+const CMP_EQ = 57423
+const NEQ = 57424
+const LT = 57425
+const LTE = 57426
+const GT = 57427
+const GTE = 57428
+const ADD = 57429
+const SUB = 57430
+const MUL = 57431
+const DIV = 57432
+const MOD = 57433
+const POW = 57434
 
 var exprToknames = [...]string{
 	"$end",
@@ -153,6 +167,7 @@ var exprToknames = [...]string{
 	"NUMBER",
 	"DURATION",
 	"RANGE",
+	"SUBQUERY_RANGE",
 	"MATCHERS",
 	"LABELS",
 	"EQ",
@@ -204,6 +219,11 @@ var exprToknames = [...]string{
 	"FIRST_OVER_TIME",
 	"LAST_OVER_TIME",
 	"ABSENT_OVER_TIME",
+	"QUANTILE_SKETCH_OVER_TIME",
+	"APPROX_COUNT_DISTINCT_OVER_TIME",
+	"APPROX_COUNT_DISTINCT_SKETCH_OVER_TIME",
+	"APPROX_TOPK",
+	"APPROX_TOPK_SKETCH",
 	"LABEL_REPLACE",
 	"UNPACK",
 	"OFFSET",
@@ -213,6 +233,8 @@ var exprToknames = [...]string{
 	"IGNORING",
 	"GROUP_LEFT",
 	"GROUP_RIGHT",
+	"XML",
+	"CEF",
 	"OR",
 	"AND",
 	"UNLESS",
@@ -244,133 +266,154 @@ var exprExca = [...]int{
 
 const exprPrivate = 57344
 
-const exprLast = 532
+const exprLast = 687
 
 var exprAct = [...]int{
 
-	248, 195, 76, 4, 176, 58, 164, 5, 169, 204,
-	67, 112, 50, 57, 122, 135, 69, 2, 45, 46,
-	47, 48, 49, 50, 72, 42, 43, 44, 51, 52,
-	55, 56, 53, 54, 45, 46, 47, 48, 49, 50,
-	43, 44, 51, 52, 55, 56, 53, 54, 45, 46,
-	47, 48, 49, 50, 47, 48, 49, 50, 131, 133,
-	134, 65, 320, 100, 178, 133, 134, 104, 63, 64,
-	148, 149, 228, 124, 188, 229, 227, 146, 147, 139,
-	251, 251, 137, 61, 256, 144, 51, 52, 55, 56,
-	53, 54, 45, 46, 47, 48, 49, 50, 253, 145,
-	294, 320, 294, 150, 151, 152, 153, 154, 155, 156,
-	157, 158, 159, 160, 161, 162, 163, 323, 119, 85,
-	132, 66, 340, 173, 335, 184, 179, 182, 183, 180,
-	181, 328, 166, 226, 191, 253, 116, 253, 317, 77,
-	78, 186, 265, 101, 265, 202, 198, 311, 191, 310,
-	254, 196, 206, 207, 199, 65, 286, 252, 75, 65,
-	77, 78, 63, 64, 194, 302, 63, 64, 327, 65,
-	260, 275, 214, 215, 216, 254, 63, 64, 325, 257,
-	65, 194, 252, 65, 165, 197, 65, 63, 64, 197,
-	63, 64, 253, 63, 64, 246, 249, 301, 255, 197,
-	258, 137, 100, 261, 104, 262, 265, 304, 250, 247,
-	197, 309, 259, 197, 285, 66, 197, 253, 263, 66,
-	269, 271, 274, 276, 119, 279, 277, 65, 82, 66,
-	206, 265, 251, 119, 63, 64, 308, 284, 166, 206,
-	66, 295, 116, 66, 191, 119, 66, 166, 200, 273,
-	287, 116, 289, 291, 126, 293, 100, 60, 272, 166,
-	292, 303, 288, 116, 219, 100, 192, 224, 305, 187,
-	225, 223, 86, 87, 88, 89, 90, 91, 92, 93,
-	94, 95, 96, 97, 98, 99, 206, 66, 125, 314,
-	315, 297, 298, 299, 100, 316, 119, 283, 167, 165,
-	206, 318, 319, 265, 265, 270, 206, 324, 267, 266,
-	167, 165, 213, 136, 116, 119, 15, 212, 12, 208,
-	330, 12, 331, 332, 12, 205, 138, 211, 222, 138,
-	210, 185, 6, 116, 336, 143, 19, 20, 33, 34,
-	36, 37, 35, 38, 39, 40, 41, 21, 22, 142,
-	141, 107, 109, 108, 81, 117, 118, 23, 24, 25,
-	26, 27, 28, 29, 74, 338, 334, 30, 31, 32,
-	18, 203, 110, 307, 111, 264, 220, 217, 209, 12,
-	201, 193, 130, 221, 218, 333, 322, 6, 16, 17,
-	128, 19, 20, 33, 34, 36, 37, 35, 38, 39,
-	40, 41, 21, 22, 127, 243, 321, 129, 244, 242,
-	80, 300, 23, 24, 25, 26, 27, 28, 29, 290,
-	281, 282, 30, 31, 32, 18, 140, 240, 79, 237,
-	241, 239, 238, 236, 12, 234, 339, 231, 235, 233,
-	232, 230, 6, 16, 17, 119, 19, 20, 33, 34,
-	36, 37, 35, 38, 39, 40, 41, 21, 22, 3,
-	337, 326, 313, 116, 312, 278, 68, 23, 24, 25,
-	26, 27, 28, 29, 268, 245, 190, 30, 31, 32,
-	18, 107, 109, 108, 189, 117, 118, 256, 280, 188,
-	187, 177, 113, 174, 172, 171, 329, 71, 16, 17,
-	73, 306, 110, 170, 111, 73, 177, 114, 168, 103,
-	175, 106, 105, 59, 120, 115, 121, 102, 84, 83,
-	11, 10, 9, 123, 14, 8, 296, 13, 7, 70,
-	62, 1,
+	280, 215, 4, 191, 186, 63, 62, 181, 226, 72,
+	176, 3, 147, 55, 81, 120, 202, 5, 73, 219,
+	50, 51, 52, 53, 54, 55, 200, 283, 77, 47,
+	48, 49, 56, 57, 60, 61, 58, 59, 50, 51,
+	52, 53, 54, 55, 48, 49, 56, 57, 60, 61,
+	58, 59, 50, 51, 52, 53, 54, 55, 52, 53,
+	54, 55, 160, 161, 286, 140, 142, 143, 105, 158,
+	159, 130, 285, 127, 362, 362, 110, 90, 380, 70,
+	74, 2, 150, 151, 66, 15, 68, 69, 178, 156,
+	278, 375, 124, 357, 12, 368, 70, 148, 283, 144,
+	82, 83, 6, 68, 69, 367, 19, 20, 36, 37,
+	39, 40, 38, 41, 42, 43, 44, 21, 22, 252,
+	297, 205, 253, 251, 365, 349, 216, 23, 24, 25,
+	26, 27, 28, 29, 141, 188, 133, 30, 31, 32,
+	33, 34, 35, 45, 46, 18, 71, 177, 80, 106,
+	82, 83, 217, 297, 203, 213, 224, 342, 348, 321,
+	319, 73, 220, 71, 229, 16, 17, 218, 157, 295,
+	326, 326, 162, 163, 164, 165, 166, 167, 168, 169,
+	170, 171, 172, 173, 174, 175, 250, 359, 236, 237,
+	238, 56, 57, 60, 61, 58, 59, 50, 51, 52,
+	53, 54, 55, 193, 142, 143, 285, 285, 291, 297,
+	70, 222, 208, 150, 347, 279, 281, 68, 69, 105,
+	289, 282, 292, 274, 272, 287, 294, 110, 148, 276,
+	273, 275, 228, 297, 127, 322, 212, 293, 346, 208,
+	216, 301, 303, 306, 308, 278, 311, 309, 313, 178,
+	214, 70, 307, 124, 243, 284, 70, 135, 68, 69,
+	228, 338, 320, 68, 69, 248, 288, 204, 249, 247,
+	228, 199, 194, 197, 198, 195, 196, 71, 134, 325,
+	305, 216, 327, 323, 329, 331, 216, 328, 105, 339,
+	304, 285, 214, 105, 127, 324, 332, 127, 70, 343,
+	318, 228, 333, 70, 284, 68, 69, 179, 177, 178,
+	68, 69, 178, 124, 297, 208, 124, 317, 71, 299,
+	341, 302, 208, 71, 221, 279, 289, 355, 216, 105,
+	356, 354, 246, 65, 105, 352, 70, 353, 290, 134,
+	285, 360, 361, 68, 69, 209, 297, 235, 234, 233,
+	146, 298, 145, 335, 336, 337, 339, 364, 232, 370,
+	105, 12, 372, 201, 373, 71, 216, 179, 177, 149,
+	71, 228, 376, 19, 20, 36, 37, 39, 40, 38,
+	41, 42, 43, 44, 21, 22, 155, 154, 153, 86,
+	283, 230, 79, 378, 23, 24, 25, 26, 27, 28,
+	29, 228, 137, 71, 30, 31, 32, 33, 34, 35,
+	45, 46, 18, 15, 374, 12, 245, 136, 345, 139,
+	138, 227, 12, 277, 296, 244, 242, 241, 127, 239,
+	149, 231, 16, 17, 19, 20, 36, 37, 39, 40,
+	38, 41, 42, 43, 44, 21, 22, 124, 223, 211,
+	210, 240, 221, 371, 363, 23, 24, 25, 26, 27,
+	28, 29, 358, 340, 330, 30, 31, 32, 33, 34,
+	35, 45, 46, 18, 225, 267, 85, 264, 268, 266,
+	265, 263, 261, 12, 258, 262, 260, 259, 257, 315,
+	316, 6, 84, 16, 17, 19, 20, 36, 37, 39,
+	40, 38, 41, 42, 43, 44, 21, 22, 255, 379,
+	369, 256, 254, 377, 366, 351, 23, 24, 25, 26,
+	27, 28, 29, 350, 312, 310, 30, 31, 32, 33,
+	34, 35, 45, 46, 18, 152, 314, 300, 271, 192,
+	121, 270, 269, 207, 12, 206, 205, 204, 189, 184,
+	183, 344, 6, 187, 16, 17, 19, 20, 36, 37,
+	39, 40, 38, 41, 42, 43, 44, 21, 22, 76,
+	182, 78, 78, 192, 122, 185, 109, 23, 24, 25,
+	26, 27, 28, 29, 127, 180, 108, 30, 31, 32,
+	33, 34, 35, 45, 46, 18, 190, 112, 127, 111,
+	132, 64, 128, 124, 123, 129, 107, 89, 88, 11,
+	10, 9, 131, 14, 8, 16, 17, 124, 334, 13,
+	7, 113, 115, 114, 87, 125, 126, 286, 75, 67,
+	1, 0, 0, 0, 0, 113, 115, 114, 0, 125,
+	126, 0, 0, 0, 0, 0, 0, 116, 0, 117,
+	0, 0, 0, 0, 0, 118, 119, 0, 0, 0,
+	0, 116, 0, 117, 0, 0, 0, 0, 0, 118,
+	119, 0, 0, 91, 92, 93, 94, 95, 96, 97,
+	98, 99, 100, 101, 102, 103, 104,
 }
 var exprPact = [...]int{
 
-	309, -1000, -45, -1000, -1000, 213, 309, -1000, -1000, -1000,
-	-1000, -1000, 495, 341, 135, -1000, 421, 403, 331, -1000,
+	78, -1000, -49, -1000, -1000, 288, 78, -1000, -1000, -1000,
+	-1000, -1000, 567, 368, 124, -1000, 485, 469, 365, -1000,
 	-1000, -1000, -1000, -1000, -1000, -1000, -1000, -1000, -1000, -1000,
 	-1000, -1000, -1000, -1000, -1000, -1000, -1000, -1000, -1000, -1000,
-	-1000, -1000, 79, 79, 79, 79, 79, 79, 79, 79,
-	79, 79, 79, 79, 79, 79, 79, 213, -1000, 47,
-	310, -1000, 8, -1000, -1000, -1000, -1000, 264, 230, -45,
-	388, 366, -1000, 46, 306, 419, 327, 326, 312, -1000,
-	-1000, 309, 309, 11, 2, -1000, 309, 309, 309, 309,
-	309, 309, 309, 309, 309, 309, 309, 309, 309, 309,
-	-1000, -1000, -1000, -1000, 228, -1000, -1000, 498, -1000, 489,
-	-1000, 488, -1000, -1000, -1000, -1000, 291, 487, 501, 52,
-	-1000, -1000, -1000, 308, -1000, -1000, -1000, -1000, -1000, 500,
-	-1000, 484, 483, 478, 470, 242, 362, 172, 303, 224,
-	361, 364, 301, 295, 359, -31, 307, 304, 294, 289,
-	13, 13, -27, -27, -72, -72, -72, -72, -61, -61,
-	-61, -61, -61, -61, 228, 291, 291, 291, 358, -1000,
-	372, -1000, -1000, 240, -1000, 357, -1000, 371, 263, 68,
-	433, 431, 425, 423, 401, 469, -1000, -1000, -1000, -1000,
-	-1000, -1000, 114, 303, 169, 148, 166, 440, 155, 146,
-	114, 309, 194, 356, 285, -1000, -1000, 284, -1000, 468,
-	281, 234, 225, 147, 219, 228, 113, 498, 459, -1000,
-	486, 415, 274, -1000, -1000, -1000, 214, -1000, -1000, -1000,
+	-1000, -1000, -1000, -1000, -1000, -1000, -1000, 36, 36, 36,
+	36, 36, 36, 36, 36, 36, 36, 36, 36, 36,
+	36, 36, 288, -1000, 64, 593, -1000, 65, -1000, -1000,
+	-1000, -1000, 253, 232, -49, 400, 402, -1000, 52, 345,
+	528, 364, 363, 362, -1000, -1000, 78, 78, -3, -12,
+	-1000, 78, 78, 78, 78, 78, 78, 78, 78, 78,
+	78, 78, 78, 78, 78, -1000, -1000, -1000, -1000, -1000,
+	289, -1000, -1000, 565, -1000, 544, -1000, 543, 548, -1000,
+	-1000, -1000, -1000, -1000, 423, 542, 568, 190, -1000, -1000,
+	-52, 339, -62, -1000, -1000, -1000, -1000, -1000, 566, -1000,
+	541, 540, 539, 537, 320, 430, 429, 211, 283, 406,
+	442, 186, 428, 467, 396, 366, 411, -35, 334, 325,
+	324, 323, 110, 110, -31, -31, -79, -79, -79, -79,
+	-67, -67, -67, -67, -67, -67, 289, 423, 423, 423,
+	409, -1000, 438, -1000, -1000, 407, -1000, 413, 229, -1000,
+	405, -1000, 403, 261, 115, 504, 480, 478, 473, 471,
+	536, 535, 532, -1000, -1000, -1000, -1000, -1000, -1000, 74,
+	406, 399, -1000, 81, 321, 246, 579, 314, 241, 313,
+	183, -42, 74, 78, 144, 404, 326, -1000, -1000, 294,
+	-1000, 531, 296, 265, 255, 227, 292, 289, 68, 565,
+	519, 548, 518, -1000, 534, 484, 293, -1000, -1000, -1000,
+	276, -1000, -1000, -1000, -1000, -1000, -1000, -1000, -1000, -1000,
 	-1000, -1000, -1000, -1000, -1000, -1000, -1000, -1000, -1000, -1000,
-	-1000, -1000, -1000, -1000, -1000, 190, -1000, 132, 145, 54,
-	145, 411, 17, 291, 17, 91, 236, 402, 173, 141,
-	-1000, -1000, 183, -1000, 309, 496, -1000, -1000, 354, 212,
-	-1000, 187, -1000, -1000, 125, -1000, 123, -1000, -1000, -1000,
-	-1000, -1000, -1000, 458, 456, -1000, 114, 54, 145, 54,
-	-1000, -1000, 228, -1000, 17, -1000, 115, -1000, -1000, -1000,
-	18, 397, 377, 93, 114, 154, -1000, 455, -1000, -1000,
-	-1000, -1000, 144, 107, -1000, 54, -1000, 491, 57, 54,
-	37, 17, 17, 376, -1000, -1000, 347, -1000, -1000, 100,
-	54, -1000, -1000, 17, 454, -1000, -1000, 346, 430, 98,
+	135, -1000, -1000, 237, 134, 210, 283, 399, -42, 161,
+	195, 27, 195, 456, -42, 423, 297, 236, 454, 295,
+	-1000, -1000, -1000, -1000, 132, -1000, 78, 546, -1000, -1000,
+	398, 213, -1000, 189, -1000, -1000, 133, -1000, 100, -1000,
+	-1000, -1000, -1000, -1000, -1000, -1000, -1000, 517, 509, -1000,
+	74, -1000, 74, 81, 241, -1000, -42, 27, 195, 27,
+	-1000, -1000, 289, -1000, 69, -1000, -1000, -1000, 453, 162,
+	29, 445, 74, 99, -1000, 508, -1000, -1000, -1000, -1000,
+	80, 70, -1000, -1000, 236, -1000, 27, 505, -42, 444,
+	30, 27, 16, -42, -1000, -1000, 394, -1000, -1000, 66,
+	-1000, -42, 27, -1000, 507, -1000, -1000, 373, 503, 53,
 	-1000,
 }
 var exprPgo = [...]int{
 
-	0, 531, 16, 530, 2, 9, 459, 3, 15, 11,
-	529, 528, 527, 526, 7, 525, 524, 523, 522, 521,
-	520, 228, 519, 518, 517, 13, 5, 516, 515, 514,
-	6, 513, 83, 512, 511, 4, 510, 509, 8, 508,
-	1, 507, 492, 0,
+	0, 630, 80, 629, 14, 8, 11, 2, 19, 15,
+	628, 620, 619, 618, 17, 614, 613, 612, 611, 610,
+	609, 624, 608, 607, 606, 6, 5, 605, 604, 602,
+	10, 601, 84, 600, 599, 597, 3, 596, 586, 7,
+	585, 576, 4, 575, 1, 574, 540, 0, 12,
 }
 var exprR1 = [...]int{
 
 	0, 1, 2, 2, 7, 7, 7, 7, 7, 7,
 	6, 6, 6, 8, 8, 8, 8, 8, 8, 8,
 	8, 8, 8, 8, 8, 8, 8, 8, 8, 8,
-	8, 8, 8, 8, 8, 8, 8, 8, 8, 40,
-	40, 40, 13, 13, 13, 11, 11, 11, 11, 15,
-	15, 15, 15, 15, 15, 20, 3, 3, 3, 3,
-	14, 14, 14, 10, 10, 9, 9, 9, 9, 25,
-	25, 26, 26, 26, 26, 26, 26, 17, 32, 32,
-	31, 31, 24, 24, 24, 24, 24, 37, 33, 35,
-	35, 36, 36, 36, 34, 30, 30, 30, 30, 30,
-	30, 30, 30, 30, 38, 39, 39, 42, 42, 41,
-	41, 29, 29, 29, 29, 29, 29, 29, 27, 27,
-	27, 27, 27, 27, 27, 28, 28, 28, 28, 28,
-	28, 28, 18, 18, 18, 18, 18, 18, 18, 18,
-	18, 18, 18, 18, 18, 18, 18, 22, 22, 23,
-	23, 23, 23, 21, 21, 21, 21, 21, 21, 21,
-	21, 19, 19, 19, 16, 16, 16, 16, 16, 16,
-	16, 16, 16, 12, 12, 12, 12, 12, 12, 12,
-	12, 12, 12, 12, 12, 12, 12, 43, 5, 5,
-	4, 4, 4, 4,
+	8, 8, 8, 8, 8, 8, 8, 8, 8, 44,
+	44, 44, 13, 13, 13, 11, 11, 11, 11, 11,
+	11, 11, 11, 48, 48, 48, 15, 15, 15, 15,
+	15, 15, 20, 3, 3, 3, 3, 14, 14, 14,
+	10, 10, 9, 9, 9, 9, 25, 25, 26, 26,
+	26, 26, 26, 26, 26, 17, 32, 32, 32, 33,
+	33, 31, 31, 24, 24, 24, 24, 24, 24, 24,
+	38, 41, 34, 36, 36, 37, 37, 37, 35, 30,
+	30, 30, 30, 30, 30, 30, 30, 30, 39, 40,
+	40, 42, 43, 43, 46, 46, 45, 45, 29, 29,
+	29, 29, 29, 29, 29, 27, 27, 27, 27, 27,
+	27, 27, 28, 28, 28, 28, 28, 28, 28, 18,
+	18, 18, 18, 18, 18, 18, 18, 18, 18, 18,
+	18, 18, 18, 18, 22, 22, 23, 23, 23, 23,
+	21, 21, 21, 21, 21, 21, 21, 21, 19, 19,
+	19, 16, 16, 16, 16, 16, 16, 16, 16, 16,
+	16, 16, 12, 12, 12, 12, 12, 12, 12, 12,
+	12, 12, 12, 12, 12, 12, 12, 12, 12, 47,
+	5, 5, 4, 4, 4, 4,
 }
 var exprR2 = [...]int{
 
@@ -378,98 +421,108 @@ var exprR2 = [...]int{
 	1, 2, 3, 2, 3, 4, 5, 3, 4, 5,
 	6, 3, 4, 5, 6, 3, 4, 5, 6, 4,
 	5, 6, 7, 3, 4, 4, 5, 3, 2, 3,
-	6, 3, 1, 1, 1, 4, 6, 5, 7, 4,
-	5, 5, 6, 7, 7, 12, 1, 1, 1, 1,
-	3, 3, 3, 1, 3, 3, 3, 3, 3, 1,
-	2, 1, 2, 2, 2, 2, 2, 1, 2, 5,
-	1, 2, 1, 1, 2, 1, 2, 2, 2, 3,
-	3, 1, 3, 3, 2, 1, 1, 1, 1, 3,
-	2, 3, 3, 3, 3, 1, 3, 6, 6, 1,
-	1, 3, 3, 3, 3, 3, 3, 3, 3, 3,
+	6, 3, 1, 1, 1, 4, 6, 5, 7, 6,
+	7, 4, 6, 2, 3, 3, 4, 5, 5, 6,
+	7, 7, 12, 1, 1, 1, 1, 3, 3, 3,
+	1, 3, 3, 3, 3, 3, 1, 2, 1, 2,
+	2, 2, 2, 2, 2, 1, 2, 5, 2, 3,
+	3, 1, 2, 1, 1, 2, 1, 2, 1, 1,
+	2, 2, 2, 3, 3, 1, 3, 3, 2, 1,
+	1, 1, 1, 3, 2, 3, 3, 3, 3, 1,
+	3, 3, 1, 3, 6, 6, 1, 1, 3, 3,
 	3, 3, 3, 3, 3, 3, 3, 3, 3, 3,
-	3, 3, 4, 4, 4, 4, 4, 4, 4, 4,
-	4, 4, 4, 4, 4, 4, 4, 0, 1, 5,
-	4, 5, 4, 1, 1, 2, 4, 5, 2, 4,
-	5, 1, 2, 2, 1, 1, 1, 1, 1, 1,
+	3, 3, 3, 3, 3, 3, 3, 3, 3, 4,
+	4, 4, 4, 4, 4, 4, 4, 4, 4, 4,
+	4, 4, 4, 4, 0, 1, 5, 4, 5, 4,
+	1, 1, 2, 4, 5, 2, 4, 5, 1, 2,
+	2, 1, 1, 1, 1, 1, 1, 1, 1, 1,
 	1, 1, 1, 1, 1, 1, 1, 1, 1, 1,
-	1, 1, 1, 1, 1, 1, 1, 2, 1, 3,
-	4, 4, 3, 3,
+	1, 1, 1, 1, 1, 1, 1, 1, 1, 2,
+	1, 3, 4, 4, 3, 3,
 }
 var exprChk = [...]int{
 
-	-1000, -1, -2, -6, -7, -14, 23, -11, -15, -18,
-	-19, -20, 15, -12, -16, 7, 79, 80, 61, 27,
-	28, 38, 39, 48, 49, 50, 51, 52, 53, 54,
-	58, 59, 60, 29, 30, 33, 31, 32, 34, 35,
-	36, 37, 70, 71, 72, 79, 80, 81, 82, 83,
-	84, 73, 74, 77, 78, 75, 76, -25, -26, -31,
-	44, -32, -3, 21, 22, 14, 74, -7, -6, -2,
-	-10, 2, -9, 5, 23, 23, -4, 25, 26, 7,
-	7, 23, -21, -22, -23, 40, -21, -21, -21, -21,
-	-21, -21, -21, -21, -21, -21, -21, -21, -21, -21,
-	-26, -32, -24, -37, -30, -33, -34, 41, 43, 42,
-	62, 64, -9, -42, -41, -28, 23, 45, 46, 5,
-	-29, -27, 6, -17, 65, 24, 24, 16, 2, 19,
-	16, 12, 74, 13, 14, -8, 7, -14, 23, -7,
-	7, 23, 23, 23, -7, -2, 66, 67, 68, 69,
-	-2, -2, -2, -2, -2, -2, -2, -2, -2, -2,
-	-2, -2, -2, -2, -30, 71, 19, 70, -39, -38,
-	5, 6, 6, -30, 6, -36, -35, 5, 12, 74,
-	77, 78, 75, 76, 73, 23, -9, 6, 6, 6,
-	6, 2, 24, 19, 9, -40, -25, 44, -14, -8,
-	24, 19, -7, 7, -5, 24, 5, -5, 24, 19,
-	23, 23, 23, 23, -30, -30, -30, 19, 12, 24,
-	19, 12, 65, 8, 4, 7, 65, 8, 4, 7,
-	8, 4, 7, 8, 4, 7, 8, 4, 7, 8,
-	4, 7, 8, 4, 7, 6, -4, -8, -43, -40,
-	-25, 63, 9, 44, 9, -40, 47, 24, -40, -25,
-	24, -4, -7, 24, 19, 19, 24, 24, 6, -5,
-	24, -5, 24, 24, -5, 24, -5, -38, 6, -35,
-	2, 5, 6, 23, 23, 24, 24, -40, -25, -40,
-	8, -43, -30, -43, 9, 5, -13, 55, 56, 57,
-	9, 24, 24, -40, 24, -7, 5, 19, 24, 24,
-	24, 24, 6, 6, -4, -40, -43, 23, -43, -40,
-	44, 9, 9, 24, -4, 24, 6, 24, 24, 5,
-	-40, -43, -43, 9, 19, 24, -43, 6, 19, 6,
-	24,
+	-1000, -1, -2, -6, -7, -14, 24, -11, -15, -18,
+	-19, -20, 16, -12, -16, 7, 87, 88, 67, 28,
+	29, 39, 40, 49, 50, 51, 52, 53, 54, 55,
+	59, 60, 61, 62, 63, 64, 30, 31, 34, 32,
+	33, 35, 36, 37, 38, 65, 66, 78, 79, 80,
+	87, 88, 89, 90, 91, 92, 81, 82, 85, 86,
+	83, 84, -25, -26, -31, 45, -32, -3, 22, 23,
+	15, 82, -7, -6, -2, -10, 2, -9, 5, 24,
+	24, -4, 26, 27, 7, 7, 24, -21, -22, -23,
+	41, -21, -21, -21, -21, -21, -21, -21, -21, -21,
+	-21, -21, -21, -21, -21, -26, -32, -24, -38, -41,
+	-30, -34, -35, 42, 44, 43, 68, 70, 76, 77,
+	-9, -46, -45, -28, 24, 46, 47, 5, -29, -27,
+	6, -17, -33, 71, 25, 25, 17, 2, 20, 17,
+	13, 82, 14, 15, -8, 7, 5, -48, -14, 24,
+	-7, -7, 7, 24, 24, 24, -7, -2, 72, 73,
+	74, 75, -2, -2, -2, -2, -2, -2, -2, -2,
+	-2, -2, -2, -2, -2, -2, -30, 79, 20, 78,
+	-40, -39, 5, 6, 6, -43, -42, 5, -30, 6,
+	-37, -36, 5, 13, 82, 85, 86, 83, 84, 81,
+	78, 24, 78, -9, 6, 6, 6, 6, 2, 25,
+	20, 20, 25, -25, 9, -44, 45, -7, -14, -8,
+	-48, 10, 25, 20, -7, 7, -5, 25, 5, -5,
+	25, 20, 24, 24, 24, 24, -30, -30, -30, 20,
+	13, 20, 13, 25, 20, 13, 71, 8, 4, 7,
+	71, 8, 4, 7, 8, 4, 7, 8, 4, 7,
+	8, 4, 7, 8, 4, 7, 8, 4, 7, 6,
+	6, 6, -4, -8, -48, -8, -14, 24, 9, -44,
+	-47, -44, -25, 69, 9, 45, 48, -25, 25, -44,
+	25, 25, -47, -4, -7, 25, 20, 20, 25, 25,
+	6, -5, 25, -5, 25, 25, -5, 25, -5, -39,
+	6, -42, 6, -36, 2, 5, 6, 24, 24, 25,
+	25, 25, 25, -25, -14, -47, 9, -44, -25, -44,
+	8, -47, -30, 5, -13, 56, 57, 58, 25, -44,
+	9, 25, 25, -7, 5, 20, 25, 25, 25, 25,
+	6, 6, -4, -4, -25, -47, -44, 24, 9, 25,
+	-47, -44, 45, 9, -4, 25, 6, 25, 25, 5,
+	-47, 9, -44, -47, 20, 25, -47, 6, 20, 6,
+	25,
 }
 var exprDef = [...]int{
 
 	0, -2, 1, 2, 3, 10, 0, 4, 5, 6,
-	7, 8, 0, 0, 0, 161, 0, 0, 0, 173,
-	174, 175, 176, 177, 178, 179, 180, 181, 182, 183,
-	184, 185, 186, 164, 165, 166, 167, 168, 169, 170,
-	171, 172, 147, 147, 147, 147, 147, 147, 147, 147,
-	147, 147, 147, 147, 147, 147, 147, 11, 69, 71,
-	0, 80, 0, 56, 57, 58, 59, 3, 2, 0,
-	0, 0, 63, 0, 0, 0, 0, 0, 0, 162,
-	163, 0, 0, 153, 154, 148, 0, 0, 0, 0,
-	0, 0, 0, 0, 0, 0, 0, 0, 0, 0,
-	70, 81, 72, 73, 74, 75, 76, 82, 83, 0,
-	85, 0, 95, 96, 97, 98, 0, 0, 0, 0,
-	109, 110, 78, 0, 77, 9, 12, 60, 61, 0,
-	62, 0, 0, 0, 0, 0, 0, 0, 0, 3,
-	161, 0, 0, 0, 3, 132, 0, 0, 155, 158,
-	133, 134, 135, 136, 137, 138, 139, 140, 141, 142,
-	143, 144, 145, 146, 100, 0, 0, 0, 87, 105,
-	0, 84, 86, 0, 88, 94, 91, 0, 0, 0,
-	0, 0, 0, 0, 0, 0, 64, 65, 66, 67,
-	68, 38, 45, 0, 13, 0, 0, 0, 0, 0,
-	49, 0, 3, 161, 0, 192, 188, 0, 193, 0,
-	0, 0, 0, 0, 101, 102, 103, 0, 0, 99,
-	0, 0, 0, 116, 123, 130, 0, 115, 122, 129,
-	111, 118, 125, 112, 119, 126, 113, 120, 127, 114,
-	121, 128, 117, 124, 131, 0, 47, 0, 14, 17,
-	33, 0, 21, 0, 25, 0, 0, 0, 0, 0,
-	37, 51, 3, 50, 0, 0, 190, 191, 0, 0,
-	150, 0, 152, 156, 0, 159, 0, 106, 104, 92,
-	93, 89, 90, 0, 0, 79, 46, 18, 34, 35,
-	187, 22, 41, 26, 29, 39, 0, 42, 43, 44,
-	15, 0, 0, 0, 52, 3, 189, 0, 149, 151,
-	157, 160, 0, 0, 48, 36, 30, 0, 16, 19,
-	0, 23, 27, 0, 53, 54, 0, 107, 108, 0,
-	20, 24, 28, 31, 0, 40, 32, 0, 0, 0,
-	55,
+	7, 8, 0, 0, 0, 178, 0, 0, 0, 192,
+	193, 194, 195, 196, 197, 198, 199, 200, 201, 202,
+	203, 204, 205, 206, 207, 208, 181, 182, 183, 184,
+	185, 186, 187, 188, 189, 190, 191, 164, 164, 164,
+	164, 164, 164, 164, 164, 164, 164, 164, 164, 164,
+	164, 164, 11, 76, 78, 0, 91, 0, 63, 64,
+	65, 66, 3, 2, 0, 0, 0, 70, 0, 0,
+	0, 0, 0, 0, 179, 180, 0, 0, 170, 171,
+	165, 0, 0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 77, 92, 79, 80, 81,
+	82, 83, 84, 93, 94, 0, 96, 0, 98, 99,
+	109, 110, 111, 112, 0, 0, 0, 0, 126, 127,
+	86, 0, 88, 85, 9, 12, 67, 68, 0, 69,
+	0, 0, 0, 0, 0, 178, 0, 0, 10, 0,
+	3, 3, 178, 0, 0, 0, 3, 149, 0, 0,
+	172, 175, 150, 151, 152, 153, 154, 155, 156, 157,
+	158, 159, 160, 161, 162, 163, 114, 0, 0, 0,
+	100, 119, 0, 95, 97, 101, 122, 0, 0, 102,
+	108, 105, 0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 71, 72, 73, 74, 75, 38, 45,
+	0, 0, 51, 11, 13, 0, 0, 3, 10, 0,
+	0, 53, 56, 0, 3, 178, 0, 214, 210, 0,
+	215, 0, 0, 0, 0, 0, 115, 116, 117, 0,
+	0, 0, 0, 113, 0, 0, 0, 133, 140, 147,
+	0, 132, 139, 146, 128, 135, 142, 129, 136, 143,
+	130, 137, 144, 131, 138, 145, 134, 141, 148, 89,
+	0, 90, 47, 0, 0, 0, 0, 0, 25, 0,
+	14, 17, 33, 0, 21, 0, 0, 11, 0, 0,
+	37, 55, 54, 58, 3, 57, 0, 0, 212, 213,
+	0, 0, 167, 0, 169, 173, 0, 176, 0, 120,
+	118, 123, 121, 106, 107, 103, 104, 0, 0, 87,
+	46, 52, 49, 0, 0, 26, 29, 18, 34, 35,
+	209, 22, 41, 39, 0, 42, 43, 44, 0, 0,
+	15, 0, 59, 3, 211, 0, 166, 168, 174, 177,
+	0, 0, 48, 50, 0, 30, 36, 0, 27, 0,
+	16, 19, 0, 23, 60, 61, 0, 124, 125, 0,
+	28, 31, 20, 24, 0, 40, 32, 0, 0, 0,
+	62,
 }
 var exprTok1 = [...]int{
 
@@ -485,7 +538,8 @@ var exprTok2 = [...]int{
 	52, 53, 54, 55, 56, 57, 58, 59, 60, 61,
 	62, 63, 64, 65, 66, 67, 68, 69, 70, 71,
 	72, 73, 74, 75, 76, 77, 78, 79, 80, 81,
-	82, 83, 84,
+	82, 83, 84, 85, 86, 87, 88, 89, 90, 91,
+	92,
 }
 var exprTok3 = [...]int{
 	0,
@@ -1063,732 +1117,842 @@ exprdefault:
 			exprVAL.RangeAggregationExpr = newRangeAggregationExpr(exprDollar[5].LogRangeExpr, exprDollar[1].RangeOp, exprDollar[7].Grouping, &exprDollar[3].str)
 		}
 	case 49:
+		exprDollar = exprS[exprpt-6 : exprpt+1]
+		{
+			exprVAL.RangeAggregationExpr = newLabelRangeAggregationExpr(exprDollar[5].LogRangeExpr, exprDollar[1].RangeOp, nil, exprDollar[3].str)
+		}
+	case 50:
+		exprDollar = exprS[exprpt-7 : exprpt+1]
+		{
+			exprVAL.RangeAggregationExpr = newLabelRangeAggregationExpr(exprDollar[5].LogRangeExpr, exprDollar[1].RangeOp, exprDollar[7].Grouping, exprDollar[3].str)
+		}
+	case 51:
+		exprDollar = exprS[exprpt-4 : exprpt+1]
+		{
+			exprVAL.RangeAggregationExpr = newSubqueryAggregationExpr(exprDollar[3].SubqueryExpr, exprDollar[1].RangeOp, nil)
+		}
+	case 52:
+		exprDollar = exprS[exprpt-6 : exprpt+1]
+		{
+			exprVAL.RangeAggregationExpr = newSubqueryAggregationExpr(exprDollar[5].SubqueryExpr, exprDollar[1].RangeOp, &exprDollar[3].str)
+		}
+	case 53:
+		exprDollar = exprS[exprpt-2 : exprpt+1]
+		{
+			exprVAL.SubqueryExpr = newSubqueryExpr(exprDollar[1].MetricExpr, exprDollar[2].subqueryRange, nil)
+		}
+	case 54:
+		exprDollar = exprS[exprpt-3 : exprpt+1]
+		{
+			exprVAL.SubqueryExpr = newSubqueryExpr(exprDollar[1].MetricExpr, exprDollar[2].subqueryRange, exprDollar[3].OffsetExpr)
+		}
+	case 55:
+		exprDollar = exprS[exprpt-3 : exprpt+1]
+		{
+			exprVAL.SubqueryExpr = exprDollar[2].SubqueryExpr
+		}
+	case 56:
 		exprDollar = exprS[exprpt-4 : exprpt+1]
 		{
 			exprVAL.VectorAggregationExpr = mustNewVectorAggregationExpr(exprDollar[3].MetricExpr, exprDollar[1].VectorOp, nil, nil)
 		}
-	case 50:
+	case 57:
 		exprDollar = exprS[exprpt-5 : exprpt+1]
 		{
 			exprVAL.VectorAggregationExpr = mustNewVectorAggregationExpr(exprDollar[4].MetricExpr, exprDollar[1].VectorOp, exprDollar[2].Grouping, nil)
 		}
-	case 51:
+	case 58:
 		exprDollar = exprS[exprpt-5 : exprpt+1]
 		{
 			exprVAL.VectorAggregationExpr = mustNewVectorAggregationExpr(exprDollar[3].MetricExpr, exprDollar[1].VectorOp, exprDollar[5].Grouping, nil)
 		}
-	case 52:
+	case 59:
 		exprDollar = exprS[exprpt-6 : exprpt+1]
 		{
 			exprVAL.VectorAggregationExpr = mustNewVectorAggregationExpr(exprDollar[5].MetricExpr, exprDollar[1].VectorOp, nil, &exprDollar[3].str)
 		}
-	case 53:
+	case 60:
 		exprDollar = exprS[exprpt-7 : exprpt+1]
 		{
 			exprVAL.VectorAggregationExpr = mustNewVectorAggregationExpr(exprDollar[5].MetricExpr, exprDollar[1].VectorOp, exprDollar[7].Grouping, &exprDollar[3].str)
 		}
-	case 54:
+	case 61:
 		exprDollar = exprS[exprpt-7 : exprpt+1]
 		{
 			exprVAL.VectorAggregationExpr = mustNewVectorAggregationExpr(exprDollar[6].MetricExpr, exprDollar[1].VectorOp, exprDollar[2].Grouping, &exprDollar[4].str)
 		}
-	case 55:
+	case 62:
 		exprDollar = exprS[exprpt-12 : exprpt+1]
 		{
 			exprVAL.LabelReplaceExpr = mustNewLabelReplaceExpr(exprDollar[3].MetricExpr, exprDollar[5].str, exprDollar[7].str, exprDollar[9].str, exprDollar[11].str)
 		}
-	case 56:
+	case 63:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
 		{
 			exprVAL.Filter = labels.MatchRegexp
 		}
-	case 57:
+	case 64:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
 		{
 			exprVAL.Filter = labels.MatchEqual
 		}
-	case 58:
+	case 65:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
 		{
 			exprVAL.Filter = labels.MatchNotRegexp
 		}
-	case 59:
+	case 66:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
 		{
 			exprVAL.Filter = labels.MatchNotEqual
 		}
-	case 60:
+	case 67:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
 		{
 			exprVAL.Selector = exprDollar[2].Matchers
 		}
-	case 61:
+	case 68:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
 		{
 			exprVAL.Selector = exprDollar[2].Matchers
 		}
-	case 62:
+	case 69:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
 		{
 		}
-	case 63:
+	case 70:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
 		{
 			exprVAL.Matchers = []*labels.Matcher{exprDollar[1].Matcher}
 		}
-	case 64:
+	case 71:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
 		{
 			exprVAL.Matchers = append(exprDollar[1].Matchers, exprDollar[3].Matcher)
 		}
-	case 65:
+	case 72:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
 		{
 			exprVAL.Matcher = mustNewMatcher(labels.MatchEqual, exprDollar[1].str, exprDollar[3].str)
 		}
-	case 66:
+	case 73:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
 		{
 			exprVAL.Matcher = mustNewMatcher(labels.MatchNotEqual, exprDollar[1].str, exprDollar[3].str)
 		}
-	case 67:
+	case 74:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
 		{
 			exprVAL.Matcher = mustNewMatcher(labels.MatchRegexp, exprDollar[1].str, exprDollar[3].str)
 		}
-	case 68:
+	case 75:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
 		{
 			exprVAL.Matcher = mustNewMatcher(labels.MatchNotRegexp, exprDollar[1].str, exprDollar[3].str)
 		}
-	case 69:
+	case 76:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
 		{
 			exprVAL.PipelineExpr = MultiStageExpr{exprDollar[1].PipelineStage}
 		}
-	case 70:
+	case 77:
 		exprDollar = exprS[exprpt-2 : exprpt+1]
 		{
 			exprVAL.PipelineExpr = append(exprDollar[1].PipelineExpr, exprDollar[2].PipelineStage)
 		}
-	case 71:
+	case 78:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
 		{
 			exprVAL.PipelineStage = exprDollar[1].LineFilters
 		}
-	case 72:
+	case 79:
 		exprDollar = exprS[exprpt-2 : exprpt+1]
 		{
 			exprVAL.PipelineStage = exprDollar[2].LabelParser
 		}
-	case 73:
+	case 80:
 		exprDollar = exprS[exprpt-2 : exprpt+1]
 		{
 			exprVAL.PipelineStage = exprDollar[2].JSONExpressionParser
 		}
-	case 74:
+	case 81:
+		exprDollar = exprS[exprpt-2 : exprpt+1]
+		{
+			exprVAL.PipelineStage = exprDollar[2].XMLExpressionParser
+		}
+	case 82:
 		exprDollar = exprS[exprpt-2 : exprpt+1]
 		{
 			exprVAL.PipelineStage = &LabelFilterExpr{LabelFilterer: exprDollar[2].LabelFilter}
 		}
-	case 75:
+	case 83:
 		exprDollar = exprS[exprpt-2 : exprpt+1]
 		{
 			exprVAL.PipelineStage = exprDollar[2].LineFormatExpr
 		}
-	case 76:
+	case 84:
 		exprDollar = exprS[exprpt-2 : exprpt+1]
 		{
 			exprVAL.PipelineStage = exprDollar[2].LabelFormatExpr
 		}
-	case 77:
+	case 85:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
 		{
 			exprVAL.FilterOp = OpFilterIP
 		}
-	case 78:
+	case 86:
 		exprDollar = exprS[exprpt-2 : exprpt+1]
 		{
 			exprVAL.LineFilter = newLineFilterExpr(exprDollar[1].Filter, "", exprDollar[2].str)
 		}
-	case 79:
+	case 87:
 		exprDollar = exprS[exprpt-5 : exprpt+1]
 		{
 			exprVAL.LineFilter = newLineFilterExpr(exprDollar[1].Filter, exprDollar[2].FilterOp, exprDollar[4].str)
 		}
-	case 80:
+	case 88:
+		exprDollar = exprS[exprpt-2 : exprpt+1]
+		{
+			exprVAL.LineFilter = newOrLineFilterExpr(exprDollar[1].Filter, exprDollar[2].OrMatches)
+		}
+	case 89:
+		exprDollar = exprS[exprpt-3 : exprpt+1]
+		{
+			exprVAL.OrMatches = []string{exprDollar[1].str, exprDollar[3].str}
+		}
+	case 90:
+		exprDollar = exprS[exprpt-3 : exprpt+1]
+		{
+			exprVAL.OrMatches = append(exprDollar[1].OrMatches, exprDollar[3].str)
+		}
+	case 91:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
 		{
 			exprVAL.LineFilters = exprDollar[1].LineFilter
 		}
-	case 81:
+	case 92:
 		exprDollar = exprS[exprpt-2 : exprpt+1]
 		{
 			exprVAL.LineFilters = newNestedLineFilterExpr(exprDollar[1].LineFilters, exprDollar[2].LineFilter)
 		}
-	case 82:
+	case 93:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
 		{
 			exprVAL.LabelParser = newLabelParserExpr(OpParserTypeJSON, "")
 		}
-	case 83:
+	case 94:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
 		{
 			exprVAL.LabelParser = newLabelParserExpr(OpParserTypeLogfmt, "")
 		}
-	case 84:
+	case 95:
 		exprDollar = exprS[exprpt-2 : exprpt+1]
 		{
 			exprVAL.LabelParser = newLabelParserExpr(OpParserTypeRegexp, exprDollar[2].str)
 		}
-	case 85:
+	case 96:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
 		{
 			exprVAL.LabelParser = newLabelParserExpr(OpParserTypeUnpack, "")
 		}
-	case 86:
+	case 97:
 		exprDollar = exprS[exprpt-2 : exprpt+1]
 		{
 			exprVAL.LabelParser = newLabelParserExpr(OpParserTypePattern, exprDollar[2].str)
 		}
-	case 87:
+	case 98:
+		exprDollar = exprS[exprpt-1 : exprpt+1]
+		{
+			exprVAL.LabelParser = newLabelParserExpr(OpParserTypeXML, "")
+		}
+	case 99:
+		exprDollar = exprS[exprpt-1 : exprpt+1]
+		{
+			exprVAL.LabelParser = newLabelParserExpr(OpParserTypeCEF, "")
+		}
+	case 100:
 		exprDollar = exprS[exprpt-2 : exprpt+1]
 		{
 			exprVAL.JSONExpressionParser = newJSONExpressionParser(exprDollar[2].JSONExpressionList)
 		}
-	case 88:
+	case 101:
+		exprDollar = exprS[exprpt-2 : exprpt+1]
+		{
+			exprVAL.XMLExpressionParser = newXMLExpressionParser(exprDollar[2].XMLExpressionList)
+		}
+	case 102:
 		exprDollar = exprS[exprpt-2 : exprpt+1]
 		{
 			exprVAL.LineFormatExpr = newLineFmtExpr(exprDollar[2].str)
 		}
-	case 89:
+	case 103:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
 		{
 			exprVAL.LabelFormat = log.NewRenameLabelFmt(exprDollar[1].str, exprDollar[3].str)
 		}
-	case 90:
+	case 104:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
 		{
 			exprVAL.LabelFormat = log.NewTemplateLabelFmt(exprDollar[1].str, exprDollar[3].str)
 		}
-	case 91:
+	case 105:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
 		{
 			exprVAL.LabelsFormat = []log.LabelFmt{exprDollar[1].LabelFormat}
 		}
-	case 92:
+	case 106:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
 		{
 			exprVAL.LabelsFormat = append(exprDollar[1].LabelsFormat, exprDollar[3].LabelFormat)
 		}
-	case 94:
+	case 108:
 		exprDollar = exprS[exprpt-2 : exprpt+1]
 		{
 			exprVAL.LabelFormatExpr = newLabelFmtExpr(exprDollar[2].LabelsFormat)
 		}
-	case 95:
+	case 109:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
 		{
 			exprVAL.LabelFilter = log.NewStringLabelFilter(exprDollar[1].Matcher)
 		}
-	case 96:
+	case 110:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
 		{
 			exprVAL.LabelFilter = exprDollar[1].IPLabelFilter
 		}
-	case 97:
+	case 111:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
 		{
 			exprVAL.LabelFilter = exprDollar[1].UnitFilter
 		}
-	case 98:
+	case 112:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
 		{
 			exprVAL.LabelFilter = exprDollar[1].NumberFilter
 		}
-	case 99:
+	case 113:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
 		{
 			exprVAL.LabelFilter = exprDollar[2].LabelFilter
 		}
-	case 100:
+	case 114:
 		exprDollar = exprS[exprpt-2 : exprpt+1]
 		{
 			exprVAL.LabelFilter = log.NewAndLabelFilter(exprDollar[1].LabelFilter, exprDollar[2].LabelFilter)
 		}
-	case 101:
+	case 115:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
 		{
 			exprVAL.LabelFilter = log.NewAndLabelFilter(exprDollar[1].LabelFilter, exprDollar[3].LabelFilter)
 		}
-	case 102:
+	case 116:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
 		{
 			exprVAL.LabelFilter = log.NewAndLabelFilter(exprDollar[1].LabelFilter, exprDollar[3].LabelFilter)
 		}
-	case 103:
+	case 117:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
 		{
 			exprVAL.LabelFilter = log.NewOrLabelFilter(exprDollar[1].LabelFilter, exprDollar[3].LabelFilter)
 		}
-	case 104:
+	case 118:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
 		{
 			exprVAL.JSONExpression = log.NewJSONExpr(exprDollar[1].str, exprDollar[3].str)
 		}
-	case 105:
+	case 119:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
 		{
 			exprVAL.JSONExpressionList = []log.JSONExpression{exprDollar[1].JSONExpression}
 		}
-	case 106:
+	case 120:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
 		{
 			exprVAL.JSONExpressionList = append(exprDollar[1].JSONExpressionList, exprDollar[3].JSONExpression)
 		}
-	case 107:
+	case 121:
+		exprDollar = exprS[exprpt-3 : exprpt+1]
+		{
+			exprVAL.XMLExpression = log.NewXMLExpr(exprDollar[1].str, exprDollar[3].str)
+		}
+	case 122:
+		exprDollar = exprS[exprpt-1 : exprpt+1]
+		{
+			exprVAL.XMLExpressionList = []log.XMLExpression{exprDollar[1].XMLExpression}
+		}
+	case 123:
+		exprDollar = exprS[exprpt-3 : exprpt+1]
+		{
+			exprVAL.XMLExpressionList = append(exprDollar[1].XMLExpressionList, exprDollar[3].XMLExpression)
+		}
+	case 124:
 		exprDollar = exprS[exprpt-6 : exprpt+1]
 		{
 			exprVAL.IPLabelFilter = log.NewIPLabelFilter(exprDollar[5].str, exprDollar[1].str, log.LabelFilterEqual)
 		}
-	case 108:
+	case 125:
 		exprDollar = exprS[exprpt-6 : exprpt+1]
 		{
 			exprVAL.IPLabelFilter = log.NewIPLabelFilter(exprDollar[5].str, exprDollar[1].str, log.LabelFilterNotEqual)
 		}
-	case 109:
+	case 126:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
 		{
 			exprVAL.UnitFilter = exprDollar[1].DurationFilter
 		}
-	case 110:
+	case 127:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
 		{
 			exprVAL.UnitFilter = exprDollar[1].BytesFilter
 		}
-	case 111:
+	case 128:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
 		{
 			exprVAL.DurationFilter = log.NewDurationLabelFilter(log.LabelFilterGreaterThan, exprDollar[1].str, exprDollar[3].duration)
 		}
-	case 112:
+	case 129:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
 		{
 			exprVAL.DurationFilter = log.NewDurationLabelFilter(log.LabelFilterGreaterThanOrEqual, exprDollar[1].str, exprDollar[3].duration)
 		}
-	case 113:
+	case 130:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
 		{
 			exprVAL.DurationFilter = log.NewDurationLabelFilter(log.LabelFilterLesserThan, exprDollar[1].str, exprDollar[3].duration)
 		}
-	case 114:
+	case 131:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
 		{
 			exprVAL.DurationFilter = log.NewDurationLabelFilter(log.LabelFilterLesserThanOrEqual, exprDollar[1].str, exprDollar[3].duration)
 		}
-	case 115:
+	case 132:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
 		{
 			exprVAL.DurationFilter = log.NewDurationLabelFilter(log.LabelFilterNotEqual, exprDollar[1].str, exprDollar[3].duration)
 		}
-	case 116:
+	case 133:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
 		{
 			exprVAL.DurationFilter = log.NewDurationLabelFilter(log.LabelFilterEqual, exprDollar[1].str, exprDollar[3].duration)
 		}
-	case 117:
+	case 134:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
 		{
 			exprVAL.DurationFilter = log.NewDurationLabelFilter(log.LabelFilterEqual, exprDollar[1].str, exprDollar[3].duration)
 		}
-	case 118:
+	case 135:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
 		{
 			exprVAL.BytesFilter = log.NewBytesLabelFilter(log.LabelFilterGreaterThan, exprDollar[1].str, exprDollar[3].bytes)
 		}
-	case 119:
+	case 136:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
 		{
 			exprVAL.BytesFilter = log.NewBytesLabelFilter(log.LabelFilterGreaterThanOrEqual, exprDollar[1].str, exprDollar[3].bytes)
 		}
-	case 120:
+	case 137:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
 		{
 			exprVAL.BytesFilter = log.NewBytesLabelFilter(log.LabelFilterLesserThan, exprDollar[1].str, exprDollar[3].bytes)
 		}
-	case 121:
+	case 138:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
 		{
 			exprVAL.BytesFilter = log.NewBytesLabelFilter(log.LabelFilterLesserThanOrEqual, exprDollar[1].str, exprDollar[3].bytes)
 		}
-	case 122:
+	case 139:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
 		{
 			exprVAL.BytesFilter = log.NewBytesLabelFilter(log.LabelFilterNotEqual, exprDollar[1].str, exprDollar[3].bytes)
 		}
-	case 123:
+	case 140:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
 		{
 			exprVAL.BytesFilter = log.NewBytesLabelFilter(log.LabelFilterEqual, exprDollar[1].str, exprDollar[3].bytes)
 		}
-	case 124:
+	case 141:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
 		{
 			exprVAL.BytesFilter = log.NewBytesLabelFilter(log.LabelFilterEqual, exprDollar[1].str, exprDollar[3].bytes)
 		}
-	case 125:
+	case 142:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
 		{
 			exprVAL.NumberFilter = log.NewNumericLabelFilter(log.LabelFilterGreaterThan, exprDollar[1].str, mustNewFloat(exprDollar[3].str))
 		}
-	case 126:
+	case 143:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
 		{
 			exprVAL.NumberFilter = log.NewNumericLabelFilter(log.LabelFilterGreaterThanOrEqual, exprDollar[1].str, mustNewFloat(exprDollar[3].str))
 		}
-	case 127:
+	case 144:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
 		{
 			exprVAL.NumberFilter = log.NewNumericLabelFilter(log.LabelFilterLesserThan, exprDollar[1].str, mustNewFloat(exprDollar[3].str))
 		}
-	case 128:
+	case 145:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
 		{
 			exprVAL.NumberFilter = log.NewNumericLabelFilter(log.LabelFilterLesserThanOrEqual, exprDollar[1].str, mustNewFloat(exprDollar[3].str))
 		}
-	case 129:
+	case 146:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
 		{
 			exprVAL.NumberFilter = log.NewNumericLabelFilter(log.LabelFilterNotEqual, exprDollar[1].str, mustNewFloat(exprDollar[3].str))
 		}
-	case 130:
+	case 147:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
 		{
 			exprVAL.NumberFilter = log.NewNumericLabelFilter(log.LabelFilterEqual, exprDollar[1].str, mustNewFloat(exprDollar[3].str))
 		}
-	case 131:
+	case 148:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
 		{
 			exprVAL.NumberFilter = log.NewNumericLabelFilter(log.LabelFilterEqual, exprDollar[1].str, mustNewFloat(exprDollar[3].str))
 		}
-	case 132:
+	case 149:
 		exprDollar = exprS[exprpt-4 : exprpt+1]
 		{
 			exprVAL.BinOpExpr = mustNewBinOpExpr("or", exprDollar[3].BinOpModifier, exprDollar[1].Expr, exprDollar[4].Expr)
 		}
-	case 133:
+	case 150:
 		exprDollar = exprS[exprpt-4 : exprpt+1]
 		{
 			exprVAL.BinOpExpr = mustNewBinOpExpr("and", exprDollar[3].BinOpModifier, exprDollar[1].Expr, exprDollar[4].Expr)
 		}
-	case 134:
+	case 151:
 		exprDollar = exprS[exprpt-4 : exprpt+1]
 		{
 			exprVAL.BinOpExpr = mustNewBinOpExpr("unless", exprDollar[3].BinOpModifier, exprDollar[1].Expr, exprDollar[4].Expr)
 		}
-	case 135:
+	case 152:
 		exprDollar = exprS[exprpt-4 : exprpt+1]
 		{
 			exprVAL.BinOpExpr = mustNewBinOpExpr("+", exprDollar[3].BinOpModifier, exprDollar[1].Expr, exprDollar[4].Expr)
 		}
-	case 136:
+	case 153:
 		exprDollar = exprS[exprpt-4 : exprpt+1]
 		{
 			exprVAL.BinOpExpr = mustNewBinOpExpr("-", exprDollar[3].BinOpModifier, exprDollar[1].Expr, exprDollar[4].Expr)
 		}
-	case 137:
+	case 154:
 		exprDollar = exprS[exprpt-4 : exprpt+1]
 		{
 			exprVAL.BinOpExpr = mustNewBinOpExpr("*", exprDollar[3].BinOpModifier, exprDollar[1].Expr, exprDollar[4].Expr)
 		}
-	case 138:
+	case 155:
 		exprDollar = exprS[exprpt-4 : exprpt+1]
 		{
 			exprVAL.BinOpExpr = mustNewBinOpExpr("/", exprDollar[3].BinOpModifier, exprDollar[1].Expr, exprDollar[4].Expr)
 		}
-	case 139:
+	case 156:
 		exprDollar = exprS[exprpt-4 : exprpt+1]
 		{
 			exprVAL.BinOpExpr = mustNewBinOpExpr("%", exprDollar[3].BinOpModifier, exprDollar[1].Expr, exprDollar[4].Expr)
 		}
-	case 140:
+	case 157:
 		exprDollar = exprS[exprpt-4 : exprpt+1]
 		{
 			exprVAL.BinOpExpr = mustNewBinOpExpr("^", exprDollar[3].BinOpModifier, exprDollar[1].Expr, exprDollar[4].Expr)
 		}
-	case 141:
+	case 158:
 		exprDollar = exprS[exprpt-4 : exprpt+1]
 		{
 			exprVAL.BinOpExpr = mustNewBinOpExpr("==", exprDollar[3].BinOpModifier, exprDollar[1].Expr, exprDollar[4].Expr)
 		}
-	case 142:
+	case 159:
 		exprDollar = exprS[exprpt-4 : exprpt+1]
 		{
 			exprVAL.BinOpExpr = mustNewBinOpExpr("!=", exprDollar[3].BinOpModifier, exprDollar[1].Expr, exprDollar[4].Expr)
 		}
-	case 143:
+	case 160:
 		exprDollar = exprS[exprpt-4 : exprpt+1]
 		{
 			exprVAL.BinOpExpr = mustNewBinOpExpr(">", exprDollar[3].BinOpModifier, exprDollar[1].Expr, exprDollar[4].Expr)
 		}
-	case 144:
+	case 161:
 		exprDollar = exprS[exprpt-4 : exprpt+1]
 		{
 			exprVAL.BinOpExpr = mustNewBinOpExpr(">=", exprDollar[3].BinOpModifier, exprDollar[1].Expr, exprDollar[4].Expr)
 		}
-	case 145:
+	case 162:
 		exprDollar = exprS[exprpt-4 : exprpt+1]
 		{
 			exprVAL.BinOpExpr = mustNewBinOpExpr("<", exprDollar[3].BinOpModifier, exprDollar[1].Expr, exprDollar[4].Expr)
 		}
-	case 146:
+	case 163:
 		exprDollar = exprS[exprpt-4 : exprpt+1]
 		{
 			exprVAL.BinOpExpr = mustNewBinOpExpr("<=", exprDollar[3].BinOpModifier, exprDollar[1].Expr, exprDollar[4].Expr)
 		}
-	case 147:
+	case 164:
 		exprDollar = exprS[exprpt-0 : exprpt+1]
 		{
 			exprVAL.BoolModifier = &BinOpOptions{VectorMatching: &VectorMatching{Card: CardOneToOne}}
 		}
-	case 148:
+	case 165:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
 		{
 			exprVAL.BoolModifier = &BinOpOptions{VectorMatching: &VectorMatching{Card: CardOneToOne}, ReturnBool: true}
 		}
-	case 149:
+	case 166:
 		exprDollar = exprS[exprpt-5 : exprpt+1]
 		{
 			exprVAL.OnOrIgnoringModifier = exprDollar[1].BoolModifier
 			exprVAL.OnOrIgnoringModifier.VectorMatching.On = true
 			exprVAL.OnOrIgnoringModifier.VectorMatching.MatchingLabels = exprDollar[4].Labels
 		}
-	case 150:
+	case 167:
 		exprDollar = exprS[exprpt-4 : exprpt+1]
 		{
 			exprVAL.OnOrIgnoringModifier = exprDollar[1].BoolModifier
 			exprVAL.OnOrIgnoringModifier.VectorMatching.On = true
 		}
-	case 151:
+	case 168:
 		exprDollar = exprS[exprpt-5 : exprpt+1]
 		{
 			exprVAL.OnOrIgnoringModifier = exprDollar[1].BoolModifier
 			exprVAL.OnOrIgnoringModifier.VectorMatching.MatchingLabels = exprDollar[4].Labels
 		}
-	case 152:
+	case 169:
 		exprDollar = exprS[exprpt-4 : exprpt+1]
 		{
 			exprVAL.OnOrIgnoringModifier = exprDollar[1].BoolModifier
 		}
-	case 153:
+	case 170:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
 		{
 			exprVAL.BinOpModifier = exprDollar[1].BoolModifier
 		}
-	case 154:
+	case 171:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
 		{
 			exprVAL.BinOpModifier = exprDollar[1].OnOrIgnoringModifier
 		}
-	case 155:
+	case 172:
 		exprDollar = exprS[exprpt-2 : exprpt+1]
 		{
 			exprVAL.BinOpModifier = exprDollar[1].OnOrIgnoringModifier
 			exprVAL.BinOpModifier.VectorMatching.Card = CardManyToOne
 		}
-	case 156:
+	case 173:
 		exprDollar = exprS[exprpt-4 : exprpt+1]
 		{
 			exprVAL.BinOpModifier = exprDollar[1].OnOrIgnoringModifier
 			exprVAL.BinOpModifier.VectorMatching.Card = CardManyToOne
 		}
-	case 157:
+	case 174:
 		exprDollar = exprS[exprpt-5 : exprpt+1]
 		{
 			exprVAL.BinOpModifier = exprDollar[1].OnOrIgnoringModifier
 			exprVAL.BinOpModifier.VectorMatching.Card = CardManyToOne
 			exprVAL.BinOpModifier.VectorMatching.Include = exprDollar[4].Labels
 		}
-	case 158:
+	case 175:
 		exprDollar = exprS[exprpt-2 : exprpt+1]
 		{
 			exprVAL.BinOpModifier = exprDollar[1].OnOrIgnoringModifier
 			exprVAL.BinOpModifier.VectorMatching.Card = CardOneToMany
 		}
-	case 159:
+	case 176:
 		exprDollar = exprS[exprpt-4 : exprpt+1]
 		{
 			exprVAL.BinOpModifier = exprDollar[1].OnOrIgnoringModifier
 			exprVAL.BinOpModifier.VectorMatching.Card = CardOneToMany
 		}
-	case 160:
+	case 177:
 		exprDollar = exprS[exprpt-5 : exprpt+1]
 		{
 			exprVAL.BinOpModifier = exprDollar[1].OnOrIgnoringModifier
 			exprVAL.BinOpModifier.VectorMatching.Card = CardOneToMany
 			exprVAL.BinOpModifier.VectorMatching.Include = exprDollar[4].Labels
 		}
-	case 161:
+	case 178:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
 		{
 			exprVAL.LiteralExpr = mustNewLiteralExpr(exprDollar[1].str, false)
 		}
-	case 162:
+	case 179:
 		exprDollar = exprS[exprpt-2 : exprpt+1]
 		{
 			exprVAL.LiteralExpr = mustNewLiteralExpr(exprDollar[2].str, false)
 		}
-	case 163:
+	case 180:
 		exprDollar = exprS[exprpt-2 : exprpt+1]
 		{
 			exprVAL.LiteralExpr = mustNewLiteralExpr(exprDollar[2].str, true)
 		}
-	case 164:
+	case 181:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
 		{
 			exprVAL.VectorOp = OpTypeSum
 		}
-	case 165:
+	case 182:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
 		{
 			exprVAL.VectorOp = OpTypeAvg
 		}
-	case 166:
+	case 183:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
 		{
 			exprVAL.VectorOp = OpTypeCount
 		}
-	case 167:
+	case 184:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
 		{
 			exprVAL.VectorOp = OpTypeMax
 		}
-	case 168:
+	case 185:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
 		{
 			exprVAL.VectorOp = OpTypeMin
 		}
-	case 169:
+	case 186:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
 		{
 			exprVAL.VectorOp = OpTypeStddev
 		}
-	case 170:
+	case 187:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
 		{
 			exprVAL.VectorOp = OpTypeStdvar
 		}
-	case 171:
+	case 188:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
 		{
 			exprVAL.VectorOp = OpTypeBottomK
 		}
-	case 172:
+	case 189:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
 		{
 			exprVAL.VectorOp = OpTypeTopK
 		}
-	case 173:
+	case 190:
+		exprDollar = exprS[exprpt-1 : exprpt+1]
+		{
+			exprVAL.VectorOp = OpTypeApproxTopK
+		}
+	case 191:
+		exprDollar = exprS[exprpt-1 : exprpt+1]
+		{
+			exprVAL.VectorOp = OpTypeApproxTopKSketch
+		}
+	case 192:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
 		{
 			exprVAL.RangeOp = OpRangeTypeCount
 		}
-	case 174:
+	case 193:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
 		{
 			exprVAL.RangeOp = OpRangeTypeRate
 		}
-	case 175:
+	case 194:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
 		{
 			exprVAL.RangeOp = OpRangeTypeBytes
 		}
-	case 176:
+	case 195:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
 		{
 			exprVAL.RangeOp = OpRangeTypeBytesRate
 		}
-	case 177:
+	case 196:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
 		{
 			exprVAL.RangeOp = OpRangeTypeAvg
 		}
-	case 178:
+	case 197:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
 		{
 			exprVAL.RangeOp = OpRangeTypeSum
 		}
-	case 179:
+	case 198:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
 		{
 			exprVAL.RangeOp = OpRangeTypeMin
 		}
-	case 180:
+	case 199:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
 		{
 			exprVAL.RangeOp = OpRangeTypeMax
 		}
-	case 181:
+	case 200:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
 		{
 			exprVAL.RangeOp = OpRangeTypeStdvar
 		}
-	case 182:
+	case 201:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
 		{
 			exprVAL.RangeOp = OpRangeTypeStddev
 		}
-	case 183:
+	case 202:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
 		{
 			exprVAL.RangeOp = OpRangeTypeQuantile
 		}
-	case 184:
+	case 203:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
 		{
 			exprVAL.RangeOp = OpRangeTypeFirst
 		}
-	case 185:
+	case 204:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
 		{
 			exprVAL.RangeOp = OpRangeTypeLast
 		}
-	case 186:
+	case 205:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
 		{
 			exprVAL.RangeOp = OpRangeTypeAbsent
 		}
-	case 187:
+	case 206:
+		exprDollar = exprS[exprpt-1 : exprpt+1]
+		{
+			exprVAL.RangeOp = OpRangeTypeQuantileSketch
+		}
+	case 207:
+		exprDollar = exprS[exprpt-1 : exprpt+1]
+		{
+			exprVAL.RangeOp = OpRangeTypeApproxCountDistinct
+		}
+	case 208:
+		exprDollar = exprS[exprpt-1 : exprpt+1]
+		{
+			exprVAL.RangeOp = OpRangeTypeApproxCountDistinctSketch
+		}
+	case 209:
 		exprDollar = exprS[exprpt-2 : exprpt+1]
 		{
 			exprVAL.OffsetExpr = newOffsetExpr(exprDollar[2].duration)
 		}
-	case 188:
+	case 210:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
 		{
 			exprVAL.Labels = []string{exprDollar[1].str}
 		}
-	case 189:
+	case 211:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
 		{
 			exprVAL.Labels = append(exprDollar[1].Labels, exprDollar[3].str)
 		}
-	case 190:
+	case 212:
 		exprDollar = exprS[exprpt-4 : exprpt+1]
 		{
 			exprVAL.Grouping = &Grouping{Without: false, Groups: exprDollar[3].Labels}
 		}
-	case 191:
+	case 213:
 		exprDollar = exprS[exprpt-4 : exprpt+1]
 		{
 			exprVAL.Grouping = &Grouping{Without: true, Groups: exprDollar[3].Labels}
 		}
-	case 192:
+	case 214:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
 		{
 			exprVAL.Grouping = &Grouping{Without: false, Groups: nil}
 		}
-	case 193:
+	case 215:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
 		{
 			exprVAL.Grouping = &Grouping{Without: true, Groups: nil}
@@ -0,0 +1,423 @@
+package logql
+
+import (
+	"strings"
+	"text/scanner"
+)
+
+const (
+	// formatMaxWidth is the width beyond which the formatter splits expressions over multiple lines.
+	formatMaxWidth = 100
+	formatIndent   = "  "
+)
+
+// Format pretty-prints a query keeping its comments.
+// Expressions too long to fit on a line are split: the operands of binary operations are indented
+// under their operator, the arguments of functions are written on their own lines and the stages of
+// pipelines go on a line each.
+func Format(query string) (string, error) {
+	if _, err := ParseExpr(query); err != nil {
+		return "", err
+	}
+	tokens, comments := lexFormatTokens(query)
+	f := newFormatter(tokens)
+	f.expr(0, len(tokens), 0)
+	for _, c := range comments {
+		f.newline(0)
+		f.write(c)
+	}
+	return f.sb.String(), nil
+}
+
+// formatToken is a token of a query with the comments around it.
+type formatToken struct {
+	typ  int
+	text string
+	// comments are the comments on their own lines before the token, trailing the comment following it on its line.
+	comments []string
+	trailing string
+
+	// depth is the number of parentheses and braces the token is in.
+	depth int
+	// match is the index of the closing parenthesis or brace of an opening one.
+	match    int
+	inBraces bool
+	// pipeline tells if the token is part of the pipeline of a log selector, stage if it starts one of its stages.
+	pipeline bool
+	stage    bool
+	unary    bool
+	grouping bool
+}
+
+// lexFormatTokens returns the tokens of a valid query and the comments following the last one.
+func lexFormatTokens(query string) ([]*formatToken, []string) {
+	l := &lexer{}
+	l.Init(strings.NewReader(query))
+	l.Scanner.Error = func(_ *scanner.Scanner, _ string) {}
+
+	var (
+		tokens []*formatToken
+		lval   exprSymType
+		end    int
+	)
+	// comments returns the comments of the text between two tokens, the one on the line of the previous
+	// token trailing it.
+	comments := func(gap string) (leading []string) {
+		for i, line := range strings.Split(gap, "\n") {
+			c := strings.TrimSpace(line)
+			if c == "" {
+				continue
+			}
+			if i == 0 && len(tokens) > 0 {
+				tokens[len(tokens)-1].trailing = c
+				continue
+			}
+			leading = append(leading, c)
+		}
+		return leading
+	}
+
+	for typ := l.Lex(&lval); typ != 0; typ = l.Lex(&lval) {
+		start := l.Position.Offset
+		tok := &formatToken{typ: typ, comments: comments(query[end:start])}
+		end = l.Pos().Offset
+		tok.text = query[start:end]
+		tokens = append(tokens, tok)
+	}
+	rest := comments(query[end:])
+
+	annotateFormatTokens(tokens)
+	return tokens, rest
+}
+
+// annotateFormatTokens sets the context of each token needed to lay them out.
+func annotateFormatTokens(tokens []*formatToken) {
+	var (
+		open     []int
+		braces   int
+		pipeline = -1
+	)
+	for i, t := range tokens {
+		var prev *formatToken
+		if i > 0 {
+			prev = tokens[i-1]
+		}
+		t.depth = len(open)
+		t.inBraces = braces > 0
+
+		if pipeline >= 0 && t.depth == pipeline && (t.typ == RANGE || t.typ == CLOSE_PARENTHESIS) {
+			pipeline = -1
+		}
+		if pipeline < 0 && prev != nil && prev.typ == CLOSE_BRACE && braces == 0 && isStageStart(t.typ) {
+			pipeline = t.depth
+		}
+		if pipeline >= 0 {
+			t.pipeline = true
+			// != and !~ are line filters unless they compare a label.
+			t.stage = t.depth == pipeline && isStageStart(t.typ) && !((t.typ == NEQ || t.typ == NRE) && prev.typ == IDENTIFIER)
+		}
+		t.unary = t.typ == SUB && (prev == nil || prev.typ == OPEN_PARENTHESIS || prev.typ == COMMA || prev.typ == BOOL || formatBinOpPrecedence(prev.typ) > 0)
+
+		switch t.typ {
+		case OPEN_BRACE:
+			braces++
+			open = append(open, i)
+		case OPEN_PARENTHESIS:
+			open = append(open, i)
+		case CLOSE_BRACE, CLOSE_PARENTHESIS:
+			if t.typ == CLOSE_BRACE {
+				braces--
+			}
+			if len(open) > 0 {
+				tokens[open[len(open)-1]].match = i
+				open = open[:len(open)-1]
+			}
+		}
+	}
+
+	// parentheses holding labels of a grouping or binary operation modifier.
+	for i, t := range tokens {
+		if t.typ != OPEN_PARENTHESIS || i == 0 {
+			continue
+		}
+		switch tokens[i-1].typ {
+		case BY, WITHOUT, ON, IGNORING:
+			t.grouping = true
+		case GROUP_LEFT, GROUP_RIGHT:
+			// group_left and group_right labels are optional, the parenthesis may start the right operand.
+			t.grouping = true
+			for _, label := range tokens[i+1 : t.match] {
+				if label.typ != IDENTIFIER && label.typ != COMMA {
+					t.grouping = false
+				}
+			}
+		}
+	}
+}
+
+func isStageStart(typ int) bool {
+	switch typ {
+	case PIPE, PIPE_EXACT, PIPE_MATCH, NEQ, NRE:
+		return true
+	}
+	return false
+}
+
+// formatBinOpPrecedence returns the precedence of binary operators, zero for other tokens.
+func formatBinOpPrecedence(typ int) int {
+	switch typ {
+	case OR:
+		return 1
+	case AND, UNLESS:
+		return 2
+	case CMP_EQ, NEQ, LT, LTE, GT, GTE:
+		return 3
+	case ADD, SUB:
+		return 4
+	case MUL, DIV, MOD:
+		return 5
+	case POW:
+		return 6
+	}
+	return 0
+}
+
+func isLabelOp(typ int) bool {
+	switch typ {
+	case EQ, NEQ, RE, NRE, CMP_EQ, LT, LTE, GT, GTE:
+		return true
+	}
+	return false
+}
+
+var functionTokenTypes = func() map[int]struct{} {
+	types := make(map[int]struct{}, len(functionTokens))
+	for _, typ := range functionTokens {
+		types[typ] = struct{}{}
+	}
+	return types
+}()
+
+type formatter struct {
+	tokens []*formatToken
+	sb     strings.Builder
+
+	prev *formatToken
+	// col is the width of the current line, empty when lineStart is set until a token is written at indent.
+	col       int
+	lineStart bool
+	indent    int
+	// breakLine is set after a comment for the next token to start a new line.
+	breakLine bool
+}
+
+func newFormatter(tokens []*formatToken) *formatter {
+	return &formatter{tokens: tokens, lineStart: true}
+}
+
+// expr lays out the tokens [i, j) of an expression at an indentation level.
+func (f *formatter) expr(i, j, indent int) {
+	if f.fits(i, j, indent) {
+		f.inline(i, j, indent)
+		return
+	}
+	if ops := f.binOps(i, j); len(ops) > 0 {
+		start := i
+		for _, op := range ops {
+			end := f.binOpModifiersEnd(op, j)
+			f.newline(indent + 1)
+			f.expr(start, op, indent+1)
+			f.newline(indent)
+			f.inline(op, end, indent)
+			start = end
+		}
+		f.newline(indent + 1)
+		f.expr(start, j, indent+1)
+		return
+	}
+	f.sequence(i, j, indent)
+}
+
+// sequence lays out the tokens [i, j) of an expression which isn't a binary operation,
+// splitting the arguments of its functions and the stages of its pipeline on multiple lines.
+func (f *formatter) sequence(i, j, indent int) {
+	for k := i; k < j; {
+		t := f.tokens[k]
+		switch {
+		case t.typ == OPEN_PARENTHESIS && !t.grouping && !t.pipeline && !f.fits(k, t.match+1, indent):
+			f.token(k, indent)
+			start := k + 1
+			for a := start; a < t.match; a++ {
+				if f.tokens[a].typ == COMMA && f.tokens[a].depth == t.depth+1 {
+					f.newline(indent + 1)
+					f.expr(start, a, indent+1)
+					f.token(a, indent+1)
+					start = a + 1
+				}
+			}
+			if start < t.match {
+				f.newline(indent + 1)
+				f.expr(start, t.match, indent+1)
+			}
+			f.newline(indent)
+			f.token(t.match, indent)
+			k = t.match + 1
+		case t.typ == OPEN_PARENTHESIS || t.typ == OPEN_BRACE:
+			f.inline(k, t.match+1, indent)
+			k = t.match + 1
+		case t.stage:
+			end := k + 1
+			for end < j && f.tokens[end].pipeline && !(f.tokens[end].stage && f.tokens[end].depth == t.depth) {
+				end++
+			}
+			f.newline(indent + 1)
+			f.inline(k, end, indent+1)
+			k = end
+		default:
+			f.token(k, indent)
+			k++
+		}
+	}
+}
+
+// binOps returns the binary operators of the lowest precedence splitting the tokens [i, j).
+func (f *formatter) binOps(i, j int) []int {
+	var (
+		ops        []int
+		precedence int
+	)
+	for k := i; k < j; k++ {
+		t := f.tokens[k]
+		if t.depth != f.tokens[i].depth || t.pipeline || t.inBraces || t.unary {
+			continue
+		}
+		p := formatBinOpPrecedence(t.typ)
+		switch {
+		case p == 0:
+		case len(ops) == 0 || p < precedence:
+			ops, precedence = []int{k}, p
+		case p == precedence:
+			ops = append(ops, k)
+		}
+	}
+	return ops
+}
+
+// binOpModifiersEnd returns the index following the modifiers of the binary operator op.
+func (f *formatter) binOpModifiersEnd(op, j int) int {
+	end := op + 1
+	if end < j && f.tokens[end].typ == BOOL {
+		end++
+	}
+	for _, modifier := range [][]int{{ON, IGNORING}, {GROUP_LEFT, GROUP_RIGHT}} {
+		if end < j && (f.tokens[end].typ == modifier[0] || f.tokens[end].typ == modifier[1]) {
+			end++
+			if end < j && f.tokens[end].grouping {
+				end = f.tokens[end].match + 1
+			}
+		}
+	}
+	return end
+}
+
+// fits tells if the tokens [i, j) fit on the current line without comments between them.
+func (f *formatter) fits(i, j, indent int) bool {
+	width := f.col
+	if f.lineStart {
+		width = len(formatIndent) * indent
+	}
+	for k := i; k < j; k++ {
+		t := f.tokens[k]
+		if (k > i && len(t.comments) > 0) || (k < j-1 && t.trailing != "") || strings.Contains(t.text, "\n") {
+			return false
+		}
+		if k > i && f.space(f.tokens[k-1], t) {
+			width++
+		}
+		width += len(t.text)
+	}
+	if !f.lineStart && f.prev != nil && f.space(f.prev, f.tokens[i]) {
+		width++
+	}
+	return width <= formatMaxWidth
+}
+
+// inline writes the tokens [i, j) on the current line, only starting new lines after comments.
+func (f *formatter) inline(i, j, indent int) {
+	for k := i; k < j; k++ {
+		if k == i+1 {
+			indent++
+		}
+		f.token(k, indent)
+	}
+}
+
+// token writes a token with its comments, which start a new line at indent.
+func (f *formatter) token(i, indent int) {
+	t := f.tokens[i]
+	if len(t.comments) > 0 {
+		f.newline(indent)
+		for _, c := range t.comments {
+			f.write(c)
+			f.newline(indent)
+		}
+	}
+	if f.breakLine {
+		f.newline(indent)
+	}
+	if !f.lineStart && f.space(f.prev, t) {
+		f.write(" ")
+	}
+	f.write(t.text)
+	f.prev = t
+	if t.trailing != "" {
+		f.write(" ")
+		f.write(t.trailing)
+		f.breakLine = true
+	}
+}
+
+// space tells if a space separates two consecutive tokens.
+func (f *formatter) space(prev, t *formatToken) bool {
+	switch {
+	case prev.typ == OPEN_PARENTHESIS || prev.typ == OPEN_BRACE || prev.unary:
+		return false
+	case t.typ == CLOSE_PARENTHESIS || t.typ == CLOSE_BRACE || t.typ == COMMA:
+		return false
+	case t.typ == OPEN_PARENTHESIS:
+		_, function := functionTokenTypes[prev.typ]
+		return !function && prev.typ != IDENTIFIER
+	case t.typ == RANGE || t.typ == SUBQUERY_RANGE:
+		return prev.typ != CLOSE_BRACE && prev.typ != CLOSE_PARENTHESIS
+	case t.inBraces || (t.pipeline && !t.stage):
+		// label matchers, label filters and label formats are written without spaces around their operator.
+		return !(isLabelOp(t.typ) || isLabelOp(prev.typ) && !prev.stage)
+	}
+	return true
+}
+
+// newline starts a new line at indent unless the current line is empty.
+func (f *formatter) newline(indent int) {
+	f.breakLine = false
+	f.indent = indent
+	if f.lineStart {
+		return
+	}
+	f.sb.WriteString("\n")
+	f.lineStart = true
+	f.col = 0
+}
+
+func (f *formatter) write(s string) {
+	if f.lineStart {
+		f.sb.WriteString(strings.Repeat(formatIndent, f.indent))
+		f.col = len(formatIndent) * f.indent
+		f.lineStart = false
+	}
+	f.sb.WriteString(s)
+	if i := strings.LastIndex(s, "\n"); i >= 0 {
+		f.col = len(s) - i - 1
+		return
+	}
+	f.col += len(s)
+}
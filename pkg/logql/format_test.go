@@ -0,0 +1,125 @@
+package logql
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormat(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		query    string
+		expected string
+	}{
+		{
+			name:     "whitespaces",
+			query:    "{app=\"foo\",   env=~\"prod|dev\"}\n\t|=   \"error\"   !=   \"timeout\" | json | level =   \"error\"",
+			expected: `{app="foo", env=~"prod|dev"} |= "error" != "timeout" | json | level="error"`,
+		},
+		{
+			name:     "grouping",
+			query:    `sum   by(app)(rate({app="foo"}[5m]))`,
+			expected: `sum by (app) (rate({app="foo"}[5m]))`,
+		},
+		{
+			name:     "range after a pipeline",
+			query:    `quantile_over_time(0.99,{app="foo"}|logfmt|unwrap duration(latency)|__error__=""[5m])by(app)`,
+			expected: `quantile_over_time(0.99, {app="foo"} | logfmt | unwrap duration(latency) | __error__="" [5m]) by (app)`,
+		},
+		{
+			name:  "or line filters",
+			query: `{app="foo"}|= "error"   or "warning" or "critical" != "timeout" or "deadline exceeded"|json|level="error"`,
+			expected: `{app="foo"}
+  |= "error" or "warning" or "critical"
+  != "timeout" or "deadline exceeded"
+  | json
+  | level="error"`,
+		},
+		{
+			name:  "long pipeline",
+			query: `{app="foo"} | label_format a=b, c="{{.d}}" | line_format "{{.a}}" | addr = ip("1.2.3.4") |= ip("1.2.3.0/24") != "x" | (a="b" or c!="d")`,
+			expected: `{app="foo"}
+  | label_format a=b, c="{{.d}}"
+  | line_format "{{.a}}"
+  | addr=ip("1.2.3.4")
+  |= ip("1.2.3.0/24")
+  != "x"
+  | (a="b" or c!="d")`,
+		},
+		{
+			name:  "binary operation",
+			query: `sum by (namespace, app) (rate({namespace="loki-ops", container="query-frontend"} |= "metrics.go" | logfmt | duration > 10s [5m])) / sum by (namespace, app) (rate({namespace="loki-ops", container="query-frontend"} |= "metrics.go" [5m]))`,
+			expected: `  sum by (namespace, app) (
+    rate(
+      {namespace="loki-ops", container="query-frontend"}
+        |= "metrics.go"
+        | logfmt
+        | duration>10s [5m]
+    )
+  )
+/
+  sum by (namespace, app) (
+    rate({namespace="loki-ops", container="query-frontend"} |= "metrics.go" [5m])
+  )`,
+		},
+		{
+			name:  "precedence and modifiers",
+			query: `sum(rate({app="foo"}[1m])) > bool -2 and on (x) group_left count(rate({app="bar"}[1m])) * 1000000000000000000000000000000000000000000000000000000000000000 + 2 ^ 3`,
+			expected: `  sum(rate({app="foo"}[1m])) > bool -2
+and on (x) group_left
+    count(rate({app="bar"}[1m])) * 1000000000000000000000000000000000000000000000000000000000000000
+  +
+    2 ^ 3`,
+		},
+		{
+			name:  "arguments",
+			query: `topk(10, sum by (path) (rate({job="nginx"} | pattern "<ip> - - <_> \"<method> <path> <_>\" <status> <_> <_> \"<_>\" <_>" | status >= 500 [1m])))`,
+			expected: `topk(
+  10,
+  sum by (path) (
+    rate(
+      {job="nginx"}
+        | pattern "<ip> - - <_> \"<method> <path> <_>\" <status> <_> <_> \"<_>\" <_>"
+        | status>=500 [1m]
+    )
+  )
+)`,
+		},
+		{
+			name:  "comments",
+			query: "# errors per app\nsum by (app) ( # by app\n  count_over_time({app=\"foo\"} |= \"error\" # only errors\n [5m])\n) # end\n# last",
+			expected: `# errors per app
+sum by (app) ( # by app
+  count_over_time(
+    {app="foo"}
+      |= "error" # only errors
+    [5m]
+  )
+) # end
+# last`,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			formatted, err := Format(tc.query)
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, formatted)
+
+			// formatting is idempotent and doesn't change the query.
+			again, err := Format(formatted)
+			require.NoError(t, err)
+			require.Equal(t, formatted, again)
+
+			expr, err := ParseExpr(tc.query)
+			require.NoError(t, err)
+			formattedExpr, err := ParseExpr(formatted)
+			require.NoError(t, err)
+			require.Equal(t, expr.String(), formattedExpr.String())
+		})
+	}
+}
+
+func TestFormat_ParseError(t *testing.T) {
+	_, err := Format(`sum(rate({app="foo"}[5m])`)
+	require.Error(t, err)
+}
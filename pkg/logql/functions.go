@@ -49,6 +49,17 @@ func (r RangeAggregationExpr) extractor(override *Grouping) (log.SampleExtractor
 		noLabels = true
 	}
 
+	// the label of approx_count_distinct_over_time must be kept to count its values,
+	// the series being grouped by the evaluator.
+	if countsDistinctValues(r.Operation) {
+		if without {
+			groups = removeString(groups, r.Label)
+		} else if r.Grouping != nil || override != nil {
+			groups = append(append(make([]string, 0, len(groups)+1), groups...), r.Label)
+			noLabels = false
+		}
+	}
+
 	sort.Strings(groups)
 
 	var stages []log.Stage
@@ -80,7 +91,7 @@ func (r RangeAggregationExpr) extractor(override *Grouping) (log.SampleExtractor
 	}
 	// otherwise we extract metrics from the log line.
 	switch r.Operation {
-	case OpRangeTypeRate, OpRangeTypeCount, OpRangeTypeAbsent:
+	case OpRangeTypeRate, OpRangeTypeCount, OpRangeTypeAbsent, OpRangeTypeApproxCountDistinct, OpRangeTypeApproxCountDistinctSketch:
 		return log.NewLineSampleExtractor(log.CountExtractor, stages, groups, without, noLabels)
 	case OpRangeTypeBytes, OpRangeTypeBytesRate:
 		return log.NewLineSampleExtractor(log.BytesExtractor, stages, groups, without, noLabels)
@@ -89,6 +100,16 @@ func (r RangeAggregationExpr) extractor(override *Grouping) (log.SampleExtractor
 	}
 }
 
+func removeString(values []string, s string) []string {
+	res := make([]string, 0, len(values))
+	for _, v := range values {
+		if v != s {
+			res = append(res, v)
+		}
+	}
+	return res
+}
+
 func (r RangeAggregationExpr) aggregator() (RangeVectorAggregator, error) {
 	switch r.Operation {
 	case OpRangeTypeRate:
@@ -122,6 +143,16 @@ func (r RangeAggregationExpr) aggregator() (RangeVectorAggregator, error) {
 	}
 }
 
+func (e SubqueryAggregationExpr) aggregator() (RangeVectorAggregator, error) {
+	switch e.Operation {
+	case OpRangeTypeRate:
+		// the samples of a subquery are values, as unwrapped ones.
+		return rateLogs(e.Left.Interval, true), nil
+	default:
+		return RangeAggregationExpr{Operation: e.Operation, Params: e.Params}.aggregator()
+	}
+}
+
 // rateLogs calculates the per-second rate of log lines.
 func rateLogs(selRange time.Duration, computeValues bool) func(samples []promql.Point) float64 {
 	return func(samples []promql.Point) float64 {
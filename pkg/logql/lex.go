@@ -73,6 +73,14 @@ var tokens = map[string]int{
 	OpFilterIP: IP,
 }
 
+// stageTokens are tokens only recognized as the first token of a pipeline stage, so that they remain usable as
+// label names everywhere else.
+var stageTokens = map[string]int{
+	// parsers
+	OpParserTypeXML: XML,
+	OpParserTypeCEF: CEF,
+}
+
 // functionTokens are tokens that needs to be suffixes with parenthesis
 var functionTokens = map[string]int{
 	// range vec ops
@@ -91,6 +99,12 @@ var functionTokens = map[string]int{
 	OpRangeTypeLast:      LAST_OVER_TIME,
 	OpRangeTypeAbsent:    ABSENT_OVER_TIME,
 
+	OpRangeTypeApproxCountDistinct: APPROX_COUNT_DISTINCT_OVER_TIME,
+
+	// range vec ops sent downstream by the frontend
+	OpRangeTypeQuantileSketch:            QUANTILE_SKETCH_OVER_TIME,
+	OpRangeTypeApproxCountDistinctSketch: APPROX_COUNT_DISTINCT_SKETCH_OVER_TIME,
+
 	// vec ops
 	OpTypeSum:      SUM,
 	OpTypeAvg:      AVG,
@@ -103,6 +117,9 @@ var functionTokens = map[string]int{
 	OpTypeTopK:     TOPK,
 	OpLabelReplace: LABEL_REPLACE,
 
+	OpTypeApproxTopK:       APPROX_TOPK,
+	OpTypeApproxTopKSketch: APPROX_TOPK_SKETCH,
+
 	// conversion Op
 	OpConvBytes:           BYTES_CONV,
 	OpConvDuration:        DURATION_CONV,
@@ -116,9 +133,16 @@ type lexer struct {
 	scanner.Scanner
 	errs    []logqlmodel.ParseError
 	builder strings.Builder
+	// last is the last token returned.
+	last int
 }
 
 func (l *lexer) Lex(lval *exprSymType) int {
+	l.last = l.lex(lval)
+	return l.last
+}
+
+func (l *lexer) lex(lval *exprSymType) int {
 	r := l.Scan()
 
 	switch r {
@@ -170,6 +194,10 @@ func (l *lexer) Lex(lval *exprSymType) int {
 		l.builder.Reset()
 		for r := l.Next(); r != scanner.EOF; r = l.Next() {
 			if r == ']' {
+				// subqueries ranges have a resolution, e.g [1h:5m], which may be omitted, e.g [1h:].
+				if i := strings.Index(l.builder.String(), ":"); i >= 0 {
+					return l.scanSubqueryRange(lval, l.builder.String()[:i], l.builder.String()[i+1:])
+				}
 				i, err := model.ParseDuration(l.builder.String())
 				if err != nil {
 					l.Error(err.Error())
@@ -213,10 +241,34 @@ func (l *lexer) Lex(lval *exprSymType) int {
 		return tok
 	}
 
+	// e.g `| xml` starts a stage while `| xml="foo"` filters on the xml label.
+	if tok, ok := stageTokens[tokenText]; ok && l.last == PIPE && !isLabelFilter(l.Scanner) {
+		return tok
+	}
+
 	lval.str = tokenText
 	return IDENTIFIER
 }
 
+func (l *lexer) scanSubqueryRange(lval *exprSymType, rng, step string) int {
+	i, err := model.ParseDuration(rng)
+	if err != nil {
+		l.Error(err.Error())
+		return 0
+	}
+	lval.subqueryRange.interval = time.Duration(i)
+	lval.subqueryRange.step = 0
+	if step != "" {
+		s, err := model.ParseDuration(step)
+		if err != nil {
+			l.Error(err.Error())
+			return 0
+		}
+		lval.subqueryRange.step = time.Duration(s)
+	}
+	return SUBQUERY_RANGE
+}
+
 func (l *lexer) Error(msg string) {
 	l.errs = append(l.errs, logqlmodel.NewParseError(msg, l.Line, l.Column))
 }
@@ -320,6 +372,16 @@ func isFunction(sc scanner.Scanner) bool {
 	return false
 }
 
+// isLabelFilter returns true if the next token is a label filter operator.
+func isLabelFilter(sc scanner.Scanner) bool {
+	sc = trimSpace(sc)
+	switch sc.Peek() {
+	case '=', '!', '>', '<':
+		return true
+	}
+	return false
+}
+
 func trimSpace(l scanner.Scanner) scanner.Scanner {
 	for n := l.Peek(); n != scanner.EOF; n = l.Peek() {
 		if unicode.IsSpace(n) {
@@ -62,6 +62,8 @@ func TestLex(t *testing.T) {
 		{`{foo="bar"}
 					# |~ "\\w+"
 					| json`, []int{OPEN_BRACE, IDENTIFIER, EQ, STRING, CLOSE_BRACE, PIPE, JSON}},
+		{`{xml="a"} | xml | xml="b"`, []int{OPEN_BRACE, IDENTIFIER, EQ, STRING, CLOSE_BRACE, PIPE, XML, PIPE, IDENTIFIER, EQ, STRING}},
+		{`{foo="bar"} | cef | cef != "b"`, []int{OPEN_BRACE, IDENTIFIER, EQ, STRING, CLOSE_BRACE, PIPE, CEF, PIPE, IDENTIFIER, NEQ, STRING}},
 		{`{foo="bar"} | json code="response.code", param="request.params[0]"`, []int{OPEN_BRACE, IDENTIFIER, EQ, STRING, CLOSE_BRACE, PIPE, JSON, IDENTIFIER, EQ, STRING, COMMA, IDENTIFIER, EQ, STRING}},
 	} {
 		t.Run(tc.input, func(t *testing.T) {
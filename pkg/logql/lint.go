@@ -0,0 +1,206 @@
+package logql
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/prometheus/prometheus/model/labels"
+
+	"github.com/grafana/loki/pkg/logql/log/pattern"
+)
+
+// Lint parses a query and returns warnings about the common mistakes it contains,
+// which are valid LogQL but slower or different from what was likely meant.
+func Lint(query string) ([]string, error) {
+	expr, err := ParseExpr(query)
+	if err != nil {
+		return nil, err
+	}
+	warnings := []string{}
+
+	expr.Walk(func(e interface{}) {
+		switch e := e.(type) {
+		case *PipelineExpr:
+			warnings = lintLineFilters(warnings, e)
+			warnings = lintLabelFilters(warnings, e)
+		case *RangeAggregationExpr:
+			warnings = lintLineFmt(warnings, e)
+		}
+	})
+
+	if _, ok := expr.(SampleExpr); ok {
+		if warnings, err = lintParsers(warnings, expr); err != nil {
+			return nil, err
+		}
+	}
+	return warnings, nil
+}
+
+// lintLineFilters warns about regex line filters without regex metacharacters, which are matched as such
+// while a |= or != filter would look for the substring.
+func lintLineFilters(warnings []string, e *PipelineExpr) []string {
+	for _, stage := range e.MultiStages {
+		last, ok := stage.(*LineFilterExpr)
+		if !ok {
+			continue
+		}
+		// chained line filters are linked from the last one.
+		var filters []*LineFilterExpr
+		for f := last; f != nil; f = f.Left {
+			filters = append([]*LineFilterExpr{f}, filters...)
+		}
+		for _, f := range filters {
+			if f.Op != "" {
+				continue
+			}
+			// alternatives are suggested as such, as long as none of them has metacharacters either.
+			literal := true
+			matches := make([]string, 0, len(f.Or)+1)
+			for _, m := range append([]string{f.Match}, f.Or...) {
+				literal = literal && regexp.QuoteMeta(m) == m
+				matches = append(matches, strconv.Quote(m))
+			}
+			if !literal {
+				continue
+			}
+			match := strings.Join(matches, " or ")
+			switch f.Ty {
+			case labels.MatchRegexp:
+				warnings = append(warnings, fmt.Sprintf("the regex of the line filter |~ %s has no metacharacters, use |= %s instead", match, match))
+			case labels.MatchNotRegexp:
+				warnings = append(warnings, fmt.Sprintf("the regex of the line filter !~ %s has no metacharacters, use != %s instead", match, match))
+			}
+		}
+	}
+	return warnings
+}
+
+// lintLabelFilters warns about label filters placed before the stage of the pipeline extracting their labels,
+// which filter on the labels of the streams instead.
+func lintLabelFilters(warnings []string, e *PipelineExpr) []string {
+	streamLabels := map[string]struct{}{}
+	for _, m := range e.Matchers() {
+		streamLabels[m.Name] = struct{}{}
+	}
+
+	for i, stage := range e.MultiStages {
+		filter, ok := stage.(*LabelFilterExpr)
+		if !ok {
+			continue
+		}
+		for _, name := range filter.RequiredLabelNames() {
+			if _, ok := streamLabels[name]; ok || strings.HasPrefix(name, "__") {
+				continue
+			}
+			if extractsLabel(e.MultiStages[:i], name) != "" {
+				continue
+			}
+			if op := extractsLabel(e.MultiStages[i+1:], name); op != "" {
+				warnings = append(warnings, fmt.Sprintf("the label filter %s is applied before the label %q is extracted by %s", filter.LabelFilterer.String(), name, op))
+			}
+		}
+	}
+	return warnings
+}
+
+// extractsLabel returns the operation of the first stage that may extract a label, or an empty string if none does.
+func extractsLabel(stages MultiStageExpr, name string) string {
+	for _, stage := range stages {
+		var names []string
+		switch s := stage.(type) {
+		case *LabelParserExpr:
+			switch s.Op {
+			case OpParserTypeRegexp:
+				re, err := regexp.Compile(s.Param)
+				if err != nil {
+					continue
+				}
+				names = re.SubexpNames()
+			case OpParserTypePattern:
+				m, err := pattern.New(s.Param)
+				if err != nil {
+					continue
+				}
+				names = m.Names()
+			default:
+				// json, logfmt, unpack, xml and cef extract any label.
+				return s.Op
+			}
+		case *JSONExpressionParser:
+			for _, exp := range s.Expressions {
+				names = append(names, exp.Identifier)
+			}
+		case *XMLExpressionParser:
+			for _, exp := range s.Expressions {
+				names = append(names, exp.Identifier)
+			}
+		case *LabelFmtExpr:
+			for _, f := range s.Formats {
+				names = append(names, f.Name)
+			}
+		}
+		for _, n := range names {
+			if n == name {
+				return stageOperation(stage)
+			}
+		}
+	}
+	return ""
+}
+
+func stageOperation(stage StageExpr) string {
+	switch s := stage.(type) {
+	case *LabelParserExpr:
+		return s.Op
+	case *JSONExpressionParser:
+		return OpParserTypeJSON
+	case *XMLExpressionParser:
+		return OpParserTypeXML
+	case *LabelFmtExpr:
+		return OpFmtLabel
+	default:
+		return fmt.Sprintf("%T", stage)
+	}
+}
+
+// lintLineFmt warns about line_format stages of range aggregations not using the formatted lines,
+// which the engine removes.
+func lintLineFmt(warnings []string, e *RangeAggregationExpr) []string {
+	if !lineFmtIgnored(e.Operation) {
+		return warnings
+	}
+	pipelineExpr, ok := e.Left.Left.(*PipelineExpr)
+	if !ok {
+		return warnings
+	}
+	for i, stage := range pipelineExpr.MultiStages {
+		if isUnusedLineFmt(pipelineExpr.MultiStages, i) {
+			warnings = append(warnings, fmt.Sprintf("%s has no effect on %s, which doesn't use the formatted lines", stage.String(), e.Operation))
+		}
+	}
+	return warnings
+}
+
+// lintParsers warns about the parsers of the queries sent to the queriers which extract labels that are not used.
+func lintParsers(warnings []string, expr Expr) ([]string, error) {
+	err := walkQuerierExprs(expr, func(e Expr) error {
+		sampleExpr, ok := e.(SampleExpr)
+		if !ok {
+			return nil
+		}
+		_, noLabels, err := extractorHints(sampleExpr)
+		if err != nil || !noLabels {
+			return err
+		}
+		sampleExpr.Walk(func(e interface{}) {
+			switch stage := e.(type) {
+			case *LabelParserExpr, *JSONExpressionParser, *XMLExpressionParser:
+				warnings = append(warnings, fmt.Sprintf("the labels extracted by %s are not used by %s", stageOperation(stage.(StageExpr)), sampleExpr.String()))
+			}
+		})
+		return nil
+	})
+	return warnings, err
+}
@@ -0,0 +1,88 @@
+package logql
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLint(t *testing.T) {
+	for _, tc := range []struct {
+		query    string
+		expected []string
+	}{
+		{
+			query:    `{app="foo"} |= "error" | json | level="error"`,
+			expected: []string{},
+		},
+		{
+			query: `{app="foo"} |~ "error" !~ "timeout" |~ "(?i)warn"`,
+			expected: []string{
+				`the regex of the line filter |~ "error" has no metacharacters, use |= "error" instead`,
+				`the regex of the line filter !~ "timeout" has no metacharacters, use != "timeout" instead`,
+			},
+		},
+		{
+			query: `{app="foo"} |~ "error" or "warn" !~ "timeout" or "dead.*" |~ "(?i)warn" or "crit"`,
+			expected: []string{
+				`the regex of the line filter |~ "error" or "warn" has no metacharacters, use |= "error" or "warn" instead`,
+			},
+		},
+		{
+			query: `{app="foo"} | level="error" | logfmt`,
+			expected: []string{
+				`the label filter level="error" is applied before the label "level" is extracted by logfmt`,
+			},
+		},
+		{
+			query: `{app="foo"} | status>=500 | pattern "<_> <status> <_>" | app="foo" | method="GET" | regexp "(?P<method>\\w+)"`,
+			expected: []string{
+				`the label filter status>=500 is applied before the label "status" is extracted by pattern`,
+				`the label filter method="GET" is applied before the label "method" is extracted by regexp`,
+			},
+		},
+		{
+			query:    `{app="foo"} | json | level="error" | __error__="" | label_format lvl=level | lvl="error"`,
+			expected: []string{},
+		},
+		{
+			query: `sum by (level) (count_over_time({app="foo"} | logfmt | line_format "{{.msg}}" [5m]))`,
+			expected: []string{
+				`| line_format "{{.msg}}" has no effect on count_over_time, which doesn't use the formatted lines`,
+			},
+		},
+		{
+			query:    `sum by (level) (bytes_over_time({app="foo"} | logfmt | line_format "{{.msg}}" [5m]))`,
+			expected: []string{},
+		},
+		{
+			query:    `sum by (level) (count_over_time({app="foo"} | line_format "{{.msg}}" | logfmt [5m]))`,
+			expected: []string{},
+		},
+		{
+			query: `sum(rate({app="foo"} | json [5m])) / sum(rate({app="foo"} | json | level="error" [5m]))`,
+			expected: []string{
+				`the labels extracted by json are not used by sum(rate({app="foo"} | json[5m]))`,
+			},
+		},
+		{
+			query:    `sum by (level) (rate({app="foo"} | json [5m]))`,
+			expected: []string{},
+		},
+		{
+			query:    `rate({app="foo"} | json [5m])`,
+			expected: []string{},
+		},
+	} {
+		t.Run(tc.query, func(t *testing.T) {
+			warnings, err := Lint(tc.query)
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, warnings)
+		})
+	}
+}
+
+func TestLint_ParseError(t *testing.T) {
+	_, err := Lint(`{app="foo"} |`)
+	require.Error(t, err)
+}
@@ -0,0 +1,174 @@
+package log
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"unicode/utf8"
+)
+
+var (
+	_ Stage = &CEFParser{}
+
+	cefPrefix = []byte("CEF:")
+
+	errCEFPrefix    = errors.New("missing CEF: prefix")
+	errCEFHeader    = errors.New("incomplete CEF header")
+	errCEFExtension = errors.New("invalid CEF extension")
+)
+
+// cefHeaderKeys are the labels of the fields of the header of a CEF event, following the version.
+var cefHeaderKeys = []string{
+	"cef_version",
+	"device_vendor",
+	"device_product",
+	"device_version",
+	"device_event_class_id",
+	"name",
+	"severity",
+}
+
+type CEFParser struct {
+	// pairs are the offsets of the keys and values of the extension.
+	pairs []cefPair
+	value []byte // buffer used to unescape values
+
+	keys internedStringSet
+}
+
+type cefPair struct {
+	keyStart, eq int
+}
+
+// NewCEFParser creates a parser that can extract labels from a log line in the ArcSight Common Event Format:
+// the fields of its header and the key=value pairs of its extension.
+// Anything before the CEF: prefix, such as a syslog header, is ignored.
+func NewCEFParser() *CEFParser {
+	return &CEFParser{
+		keys: internedStringSet{},
+	}
+}
+
+func (c *CEFParser) Process(_ int64, line []byte, lbs *LabelsBuilder) ([]byte, bool) {
+	if lbs.ParserLabelHints().NoLabels() {
+		return line, true
+	}
+	if err := c.parse(line, lbs); err != nil {
+		lbs.SetErr(errCEF)
+	}
+	return line, true
+}
+
+func (c *CEFParser) parse(line []byte, lbs *LabelsBuilder) error {
+	start := bytes.Index(line, cefPrefix)
+	if start < 0 {
+		return errCEFPrefix
+	}
+	rest := line[start+len(cefPrefix):]
+
+	// the header fields are separated by pipes, which are escaped with backslashes as are backslashes.
+	for _, key := range cefHeaderKeys {
+		end := -1
+		for i := 0; i < len(rest); i++ {
+			if rest[i] == '\\' {
+				i++
+				continue
+			}
+			if rest[i] == '|' {
+				end = i
+				break
+			}
+		}
+		if end < 0 {
+			return errCEFHeader
+		}
+		c.set(lbs, unsafeGetBytes(key), rest[:end])
+		rest = rest[end+1:]
+	}
+	return c.parseExtension(rest, lbs)
+}
+
+// parseExtension parses the key=value pairs of the extension. Values may contain spaces, a value ending at the space
+// preceding the next key. Equal signs in values are escaped with backslashes, as are backslashes.
+func (c *CEFParser) parseExtension(ext []byte, lbs *LabelsBuilder) error {
+	c.pairs = c.pairs[:0]
+	for i := 0; i < len(ext); i++ {
+		switch ext[i] {
+		case '\\':
+			i++
+		case '=':
+			keyStart := i
+			for keyStart > 0 && isCEFKeyChar(ext[keyStart-1]) {
+				keyStart--
+			}
+			if keyStart == i || (keyStart > 0 && ext[keyStart-1] != ' ') {
+				// an unescaped equal sign within a value.
+				continue
+			}
+			c.pairs = append(c.pairs, cefPair{keyStart: keyStart, eq: i})
+		}
+	}
+	if len(c.pairs) == 0 && len(bytes.TrimSpace(ext)) > 0 {
+		return errCEFExtension
+	}
+
+	for i, p := range c.pairs {
+		end := len(ext)
+		if i+1 < len(c.pairs) {
+			end = c.pairs[i+1].keyStart
+		}
+		c.set(lbs, ext[p.keyStart:p.eq], bytes.TrimRight(ext[p.eq+1:end], " "))
+	}
+	return nil
+}
+
+func isCEFKeyChar(b byte) bool {
+	return (b >= 'a' && b <= 'z') || (b >= 'A' && b <= 'Z') || (b >= '0' && b <= '9') || b == '_' || b == '.' || b == '[' || b == ']'
+}
+
+func (c *CEFParser) set(lbs *LabelsBuilder, k, v []byte) {
+	key, ok := c.keys.Get(k, func() (string, bool) {
+		sanitized := sanitizeLabelKey(string(k), true)
+		if !lbs.ParserLabelHints().ShouldExtract(sanitized) {
+			return "", false
+		}
+		if len(sanitized) == 0 {
+			return "", false
+		}
+		if lbs.BaseHas(sanitized) {
+			sanitized = fmt.Sprintf("%s%s", sanitized, duplicateSuffix)
+		}
+		return sanitized, true
+	})
+	if !ok {
+		return
+	}
+	c.value = unescapeCEF(c.value[:0], v)
+	// the rune error replacement is rejected by Prometheus, so we skip it.
+	if bytes.ContainsRune(c.value, utf8.RuneError) {
+		c.value = c.value[:0]
+	}
+	lbs.Set(key, string(c.value))
+}
+
+// unescapeCEF appends the unescaped value to dst: \n and \r are newlines and any other escaped character is itself.
+func unescapeCEF(dst, v []byte) []byte {
+	for i := 0; i < len(v); i++ {
+		if v[i] != '\\' || i+1 == len(v) {
+			dst = append(dst, v[i])
+			continue
+		}
+		i++
+		switch v[i] {
+		case 'n':
+			dst = append(dst, '\n')
+		case 'r':
+			dst = append(dst, '\r')
+		default:
+			dst = append(dst, v[i])
+		}
+	}
+	return dst
+}
+
+func (c *CEFParser) RequiredLabelNames() []string { return []string{} }
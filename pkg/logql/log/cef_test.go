@@ -0,0 +1,156 @@
+package log
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/loki/pkg/logqlmodel"
+)
+
+func Test_cefParser_Parse(t *testing.T) {
+	tests := []struct {
+		name string
+		line []byte
+		lbs  labels.Labels
+		want labels.Labels
+	}{
+		{
+			"header and extension",
+			[]byte(`CEF:0|Security|threatmanager|1.0|100|worm successfully stopped|10|src=10.0.0.1 dst=2.1.2.2 spt=1232`),
+			labels.Labels{},
+			labels.Labels{
+				{Name: "cef_version", Value: "0"},
+				{Name: "device_vendor", Value: "Security"},
+				{Name: "device_product", Value: "threatmanager"},
+				{Name: "device_version", Value: "1.0"},
+				{Name: "device_event_class_id", Value: "100"},
+				{Name: "name", Value: "worm successfully stopped"},
+				{Name: "severity", Value: "10"},
+				{Name: "src", Value: "10.0.0.1"},
+				{Name: "dst", Value: "2.1.2.2"},
+				{Name: "spt", Value: "1232"},
+			},
+		},
+		{
+			"syslog prefix",
+			[]byte(`Sep 19 08:26:10 host CEF:0|Vendor|Product|2.1|42|Blocked|5|act=blocked`),
+			labels.Labels{},
+			labels.Labels{
+				{Name: "cef_version", Value: "0"},
+				{Name: "device_vendor", Value: "Vendor"},
+				{Name: "device_product", Value: "Product"},
+				{Name: "device_version", Value: "2.1"},
+				{Name: "device_event_class_id", Value: "42"},
+				{Name: "name", Value: "Blocked"},
+				{Name: "severity", Value: "5"},
+				{Name: "act", Value: "blocked"},
+			},
+		},
+		{
+			"escapes and spaces",
+			[]byte(`CEF:0|Vendor\|Inc|Product\\X|1|1|a \| b|3|msg=user logged in  cs1=a\=b\\c fname=line1\nline2 cs1Label=Custom Label`),
+			labels.Labels{},
+			labels.Labels{
+				{Name: "cef_version", Value: "0"},
+				{Name: "device_vendor", Value: "Vendor|Inc"},
+				{Name: "device_product", Value: `Product\X`},
+				{Name: "device_version", Value: "1"},
+				{Name: "device_event_class_id", Value: "1"},
+				{Name: "name", Value: "a | b"},
+				{Name: "severity", Value: "3"},
+				{Name: "msg", Value: "user logged in"},
+				{Name: "cs1", Value: `a=b\c`},
+				{Name: "fname", Value: "line1\nline2"},
+				{Name: "cs1Label", Value: "Custom Label"},
+			},
+		},
+		{
+			"unescaped equal sign and sanitized key",
+			[]byte(`CEF:0|V|P|1|1|N|1|request=https://example.com/?a=b ad.user=foo`),
+			labels.Labels{},
+			labels.Labels{
+				{Name: "cef_version", Value: "0"},
+				{Name: "device_vendor", Value: "V"},
+				{Name: "device_product", Value: "P"},
+				{Name: "device_version", Value: "1"},
+				{Name: "device_event_class_id", Value: "1"},
+				{Name: "name", Value: "N"},
+				{Name: "severity", Value: "1"},
+				{Name: "request", Value: "https://example.com/?a=b"},
+				{Name: "ad_user", Value: "foo"},
+			},
+		},
+		{
+			"empty extension and duplicate",
+			[]byte(`CEF:1|V|P|1|1|N|1|`),
+			labels.Labels{{Name: "name", Value: "foo"}},
+			labels.Labels{
+				{Name: "cef_version", Value: "1"},
+				{Name: "device_vendor", Value: "V"},
+				{Name: "device_product", Value: "P"},
+				{Name: "device_version", Value: "1"},
+				{Name: "device_event_class_id", Value: "1"},
+				{Name: "name", Value: "foo"},
+				{Name: "name_extracted", Value: "N"},
+				{Name: "severity", Value: "1"},
+			},
+		},
+		{
+			"missing prefix",
+			[]byte(`0|V|P|1|1|N|1|src=10.0.0.1`),
+			labels.Labels{{Name: "app", Value: "foo"}},
+			labels.Labels{
+				{Name: "app", Value: "foo"},
+				{Name: logqlmodel.ErrorLabel, Value: errCEF},
+			},
+		},
+		{
+			"incomplete header",
+			[]byte(`CEF:0|V|P|1`),
+			labels.Labels{{Name: "app", Value: "foo"}},
+			labels.Labels{
+				{Name: "app", Value: "foo"},
+				{Name: "cef_version", Value: "0"},
+				{Name: "device_vendor", Value: "V"},
+				{Name: "device_product", Value: "P"},
+				{Name: logqlmodel.ErrorLabel, Value: errCEF},
+			},
+		},
+		{
+			"invalid extension",
+			[]byte(`CEF:0|V|P|1|1|N|1|foo bar`),
+			labels.Labels{},
+			labels.Labels{
+				{Name: "cef_version", Value: "0"},
+				{Name: "device_vendor", Value: "V"},
+				{Name: "device_product", Value: "P"},
+				{Name: "device_version", Value: "1"},
+				{Name: "device_event_class_id", Value: "1"},
+				{Name: "name", Value: "N"},
+				{Name: "severity", Value: "1"},
+				{Name: logqlmodel.ErrorLabel, Value: errCEF},
+			},
+		},
+	}
+	for _, tt := range tests {
+		p := NewCEFParser()
+		t.Run(tt.name, func(t *testing.T) {
+			b := NewBaseLabelsBuilder().ForLabels(tt.lbs, tt.lbs.Hash())
+			b.Reset()
+			_, _ = p.Process(0, tt.line, b)
+			sort.Sort(tt.want)
+			require.Equal(t, tt.want, b.Labels())
+		})
+	}
+}
+
+func Test_cefParser_Hints(t *testing.T) {
+	p := NewCEFParser()
+	b := NewBaseLabelsBuilderWithGrouping(nil, newParserHint(nil, []string{"severity", "src"}, false, false, ""), false, false).ForLabels(labels.Labels{}, 0)
+	b.Reset()
+	_, _ = p.Process(0, []byte(`CEF:0|Security|threatmanager|1.0|100|worm successfully stopped|10|src=10.0.0.1 dst=2.1.2.2`), b)
+	require.Equal(t, labels.Labels{{Name: "severity", Value: "10"}, {Name: "src", Value: "10.0.0.1"}}, b.Labels())
+}
@@ -42,7 +42,7 @@ func (n notFilter) Filter(line []byte) bool {
 
 func (n notFilter) ToStage() Stage {
 	return StageFunc{
-		process: func(line []byte, _ *LabelsBuilder) ([]byte, bool) {
+		process: func(_ int64, line []byte, _ *LabelsBuilder) ([]byte, bool) {
 			return line, n.Filter(line)
 		},
 	}
@@ -86,7 +86,7 @@ func (a andFilter) Filter(line []byte) bool {
 
 func (a andFilter) ToStage() Stage {
 	return StageFunc{
-		process: func(line []byte, _ *LabelsBuilder) ([]byte, bool) {
+		process: func(_ int64, line []byte, _ *LabelsBuilder) ([]byte, bool) {
 			return line, a.Filter(line)
 		},
 	}
@@ -163,7 +163,7 @@ func (a andFilters) Filter(line []byte) bool {
 
 func (a andFilters) ToStage() Stage {
 	return StageFunc{
-		process: func(line []byte, _ *LabelsBuilder) ([]byte, bool) {
+		process: func(_ int64, line []byte, _ *LabelsBuilder) ([]byte, bool) {
 			return line, a.Filter(line)
 		},
 	}
@@ -204,7 +204,7 @@ func (a orFilter) Filter(line []byte) bool {
 
 func (a orFilter) ToStage() Stage {
 	return StageFunc{
-		process: func(line []byte, _ *LabelsBuilder) ([]byte, bool) {
+		process: func(_ int64, line []byte, _ *LabelsBuilder) ([]byte, bool) {
 			return line, a.Filter(line)
 		},
 	}
@@ -234,7 +234,7 @@ func (r regexpFilter) Filter(line []byte) bool {
 
 func (r regexpFilter) ToStage() Stage {
 	return StageFunc{
-		process: func(line []byte, _ *LabelsBuilder) ([]byte, bool) {
+		process: func(_ int64, line []byte, _ *LabelsBuilder) ([]byte, bool) {
 			return line, r.Filter(line)
 		},
 	}
@@ -298,7 +298,7 @@ func containsLower(line, substr []byte) bool {
 
 func (l containsFilter) ToStage() Stage {
 	return StageFunc{
-		process: func(line []byte, _ *LabelsBuilder) ([]byte, bool) {
+		process: func(_ int64, line []byte, _ *LabelsBuilder) ([]byte, bool) {
 			return line, l.Filter(line)
 		},
 	}
@@ -345,12 +345,143 @@ func (f containsAllFilter) Filter(line []byte) bool {
 
 func (f containsAllFilter) ToStage() Stage {
 	return StageFunc{
-		process: func(line []byte, _ *LabelsBuilder) ([]byte, bool) {
+		process: func(_ int64, line []byte, _ *LabelsBuilder) ([]byte, bool) {
 			return line, f.Filter(line)
 		},
 	}
 }
 
+// containsAnyFilter matches the lines containing any of its matches. It uses an Aho-Corasick automaton to look for all
+// the matches in a single pass over the line.
+type containsAnyFilter struct {
+	matches [][]byte
+
+	// classes maps the bytes of the matches to their own class and any other byte to the class 0,
+	// reducing the size of the transition table.
+	classes  [256]uint16
+	nClasses int
+	// next is the transition table of the automaton, the next state from state s reading a byte of class c being
+	// next[s*nClasses+c]. The state 0 is the start state.
+	next []int32
+	// found tells if a match ends at each state.
+	found []bool
+}
+
+// newContainsAnyFilter creates a filter matching the lines containing any of the matches.
+func newContainsAnyFilter(matches [][]byte) Filterer {
+	for _, m := range matches {
+		if len(m) == 0 {
+			return TrueFilter
+		}
+	}
+	if len(matches) == 1 {
+		return newContainsFilter(matches[0], false)
+	}
+
+	f := &containsAnyFilter{matches: matches, nClasses: 1}
+	for _, m := range matches {
+		for _, b := range m {
+			if f.classes[b] == 0 {
+				f.classes[b] = uint16(f.nClasses)
+				f.nClasses++
+			}
+		}
+	}
+
+	// build the trie of the matches, a transition to the start state meaning there's no child.
+	f.next = make([]int32, f.nClasses)
+	f.found = []bool{false}
+	for _, m := range matches {
+		var s int32
+		for _, b := range m {
+			i := int(s)*f.nClasses + int(f.classes[b])
+			if f.next[i] == 0 {
+				f.next = append(f.next, make([]int32, f.nClasses)...)
+				f.found = append(f.found, false)
+				f.next[i] = int32(len(f.found) - 1)
+			}
+			s = f.next[i]
+		}
+		f.found[s] = true
+	}
+
+	// visit the trie breadth first to compute the failure links, the state of the longest proper suffix of each state,
+	// and replace the missing transitions with the transitions of the failure links.
+	fail := make([]int32, len(f.found))
+	queue := make([]int32, 0, len(f.found))
+	for c := 0; c < f.nClasses; c++ {
+		if s := f.next[c]; s != 0 {
+			queue = append(queue, s)
+		}
+	}
+	for len(queue) > 0 {
+		s := queue[0]
+		queue = queue[1:]
+		f.found[s] = f.found[s] || f.found[fail[s]]
+		for c := 0; c < f.nClasses; c++ {
+			i := int(s)*f.nClasses + c
+			failNext := f.next[int(fail[s])*f.nClasses+c]
+			if t := f.next[i]; t != 0 {
+				fail[t] = failNext
+				queue = append(queue, t)
+				continue
+			}
+			f.next[i] = failNext
+		}
+	}
+	return f
+}
+
+func (f *containsAnyFilter) Filter(line []byte) bool {
+	var s int32
+	for _, b := range line {
+		s = f.next[int(s)*f.nClasses+int(f.classes[b])]
+		if f.found[s] {
+			return true
+		}
+	}
+	return false
+}
+
+func (f *containsAnyFilter) ToStage() Stage {
+	return StageFunc{
+		process: func(_ int64, line []byte, _ *LabelsBuilder) ([]byte, bool) {
+			return line, f.Filter(line)
+		},
+	}
+}
+
+// mergeContainsFilters replaces a filter made of case sensitive contains filters joined with `or` operations,
+// such as the simplification of the regexp foo|bar|buzz, with a single filter looking for all of them at once.
+func mergeContainsFilters(f Filterer) Filterer {
+	matches, ok := containsMatches(f, nil)
+	if !ok || len(matches) < 2 {
+		return f
+	}
+	return newContainsAnyFilter(matches)
+}
+
+// containsMatches appends the matches of a filter made of case sensitive contains filters joined with `or` operations.
+func containsMatches(f Filterer, matches [][]byte) ([][]byte, bool) {
+	switch c := f.(type) {
+	case *containsFilter:
+		if c.caseInsensitive {
+			return nil, false
+		}
+		return append(matches, c.match), true
+	case *containsAnyFilter:
+		return append(matches, c.matches...), true
+	case orFilter:
+		matches, ok := containsMatches(c.left, matches)
+		if !ok {
+			return nil, false
+		}
+		return containsMatches(c.right, matches)
+	default:
+		return nil, false
+	}
+}
+
 // NewFilter creates a new line filter from a match string and type.
 func NewFilter(match string, mt labels.MatchType) (Filterer, error) {
 	switch mt {
@@ -367,6 +498,44 @@ func NewFilter(match string, mt labels.MatchType) (Filterer, error) {
 	}
 }
 
+// NewOrFilter creates a new line filter from multiple match strings and a type. The filter matches the lines matching
+// any of the strings, or for negative types the lines matching none of them.
+func NewOrFilter(matches []string, mt labels.MatchType) (Filterer, error) {
+	switch mt {
+	case labels.MatchEqual, labels.MatchNotEqual:
+		needles := make([][]byte, 0, len(matches))
+		for _, m := range matches {
+			needles = append(needles, []byte(m))
+		}
+		f := newContainsAnyFilter(needles)
+		if mt == labels.MatchNotEqual {
+			return newNotFilter(f), nil
+		}
+		return f, nil
+	case labels.MatchRegexp, labels.MatchNotRegexp:
+		var f Filterer
+		for _, m := range matches {
+			next, err := parseRegexpFilter(m, true)
+			if err != nil {
+				return nil, err
+			}
+			// a regexp matching any line makes the whole filter match any line.
+			if next == TrueFilter {
+				f = TrueFilter
+				break
+			}
+			f = chainOrFilter(f, next)
+		}
+		f = mergeContainsFilters(f)
+		if mt == labels.MatchNotRegexp {
+			return newNotFilter(f), nil
+		}
+		return f, nil
+	default:
+		return nil, fmt.Errorf("unknown matcher: %v", matches)
+	}
+}
+
 // parseRegexpFilter parses a regexp and attempt to simplify it with only literal filters.
 // If not possible it will returns the original regexp filter.
 func parseRegexpFilter(re string, match bool) (Filterer, error) {
@@ -382,6 +551,7 @@ func parseRegexpFilter(re string, match bool) (Filterer, error) {
 		allNonGreedy(reg)
 		return newRegexpFilter(reg.String(), match)
 	}
+	f = mergeContainsFilters(f)
 	if match {
 		return f, nil
 	}
@@ -4,13 +4,14 @@ import (
 	"fmt"
 	"testing"
 
+	"github.com/prometheus/prometheus/model/labels"
 	"github.com/stretchr/testify/require"
 )
 
 func Test_SimplifiedRegex(t *testing.T) {
 	fixtures := []string{
 		"foo", "foobar", "bar", "foobuzz", "buzz", "f", "  ", "fba", "foofoofoo", "b", "foob", "bfoo", "FoO",
-		"foo, 世界", allunicode(), "fooÏbar",
+		"foo, 世界", allunicode(), "fooÏbar", "abc", "xbcdab", "xcdaz", "fo",
 	}
 	for _, test := range []struct {
 		re         string
@@ -22,33 +23,33 @@ func Test_SimplifiedRegex(t *testing.T) {
 		{"foo", true, newContainsFilter([]byte("foo"), false), true},
 		{"not", true, newNotFilter(newContainsFilter([]byte("not"), false)), false},
 		{"(foo)", true, newContainsFilter([]byte("foo"), false), true},
-		{"(foo|ba)", true, newOrFilter(newContainsFilter([]byte("foo"), false), newContainsFilter([]byte("ba"), false)), true},
-		{"(foo|ba|ar)", true, newOrFilter(newOrFilter(newContainsFilter([]byte("foo"), false), newContainsFilter([]byte("ba"), false)), newContainsFilter([]byte("ar"), false)), true},
-		{"(foo|(ba|ar))", true, newOrFilter(newContainsFilter([]byte("foo"), false), newOrFilter(newContainsFilter([]byte("ba"), false), newContainsFilter([]byte("ar"), false))), true},
+		{"(foo|ba)", true, containsAny("foo", "ba"), true},
+		{"(foo|ba|ar)", true, containsAny("foo", "ba", "ar"), true},
+		{"(foo|(ba|ar))", true, containsAny("foo", "ba", "ar"), true},
 		{"foo.*", true, newContainsFilter([]byte("foo"), false), true},
 		{".*foo", true, newNotFilter(newContainsFilter([]byte("foo"), false)), false},
 		{".*foo.*", true, newContainsFilter([]byte("foo"), false), true},
 		{"(.*)(foo).*", true, newContainsFilter([]byte("foo"), false), true},
-		{"(foo.*|.*ba)", true, newOrFilter(newContainsFilter([]byte("foo"), false), newContainsFilter([]byte("ba"), false)), true},
-		{"(foo.*|.*bar.*)", true, newNotFilter(newOrFilter(newContainsFilter([]byte("foo"), false), newContainsFilter([]byte("bar"), false))), false},
-		{".*foo.*|bar", true, newNotFilter(newOrFilter(newContainsFilter([]byte("foo"), false), newContainsFilter([]byte("bar"), false))), false},
-		{".*foo|bar", true, newNotFilter(newOrFilter(newContainsFilter([]byte("foo"), false), newContainsFilter([]byte("bar"), false))), false},
+		{"(foo.*|.*ba)", true, containsAny("foo", "ba"), true},
+		{"(foo.*|.*bar.*)", true, newNotFilter(containsAny("foo", "bar")), false},
+		{".*foo.*|bar", true, newNotFilter(containsAny("foo", "bar")), false},
+		{".*foo|bar", true, newNotFilter(containsAny("foo", "bar")), false},
 		// This construct is similar to (...), but won't create a capture group.
-		{"(?:.*foo.*|bar)", true, newOrFilter(newContainsFilter([]byte("foo"), false), newContainsFilter([]byte("bar"), false)), true},
+		{"(?:.*foo.*|bar)", true, containsAny("foo", "bar"), true},
 		// named capture group
-		{"(?P<foo>.*foo.*|bar)", true, newOrFilter(newContainsFilter([]byte("foo"), false), newContainsFilter([]byte("bar"), false)), true},
+		{"(?P<foo>.*foo.*|bar)", true, containsAny("foo", "bar"), true},
 		// parsed as (?-s:.)*foo(?-s:.)*|b(?:ar|uzz)
-		{".*foo.*|bar|buzz", true, newOrFilter(newContainsFilter([]byte("foo"), false), newOrFilter(newContainsFilter([]byte("bar"), false), newContainsFilter([]byte("buzz"), false))), true},
+		{".*foo.*|bar|buzz", true, containsAny("foo", "bar", "buzz"), true},
 		// parsed as (?-s:.)*foo(?-s:.)*|bar|uzz
-		{".*foo.*|bar|uzz", true, newOrFilter(newOrFilter(newContainsFilter([]byte("foo"), false), newContainsFilter([]byte("bar"), false)), newContainsFilter([]byte("uzz"), false)), true},
+		{".*foo.*|bar|uzz", true, containsAny("foo", "bar", "uzz"), true},
 		// parsed as foo|b(?:ar|(?:)|uzz)|zz
-		{"foo|bar|b|buzz|zz", true, newOrFilter(newOrFilter(newContainsFilter([]byte("foo"), false), newOrFilter(newOrFilter(newContainsFilter([]byte("bar"), false), newContainsFilter([]byte("b"), false)), newContainsFilter([]byte("buzz"), false))), newContainsFilter([]byte("zz"), false)), true},
+		{"foo|bar|b|buzz|zz", true, containsAny("foo", "bar", "b", "buzz", "zz"), true},
 		// parsed as f(?:(?:)|oo(?:(?:)|bar))
-		{"f|foo|foobar", true, newOrFilter(newContainsFilter([]byte("f"), false), newOrFilter(newContainsFilter([]byte("foo"), false), newContainsFilter([]byte("foobar"), false))), true},
+		{"f|foo|foobar", true, containsAny("f", "foo", "foobar"), true},
 		// parsed as f(?:(?-s:.)*|oobar(?-s:.)*)|(?-s:.)*buzz
-		{"f.*|foobar.*|.*buzz", true, newOrFilter(newOrFilter(newContainsFilter([]byte("f"), false), newContainsFilter([]byte("foobar"), false)), newContainsFilter([]byte("buzz"), false)), true},
+		{"f.*|foobar.*|.*buzz", true, containsAny("f", "foobar", "buzz"), true},
 		// parsed as ((f(?-s:.)*)|foobar(?-s:.)*)|(?-s:.)*buzz
-		{"((f.*)|foobar.*)|.*buzz", true, newOrFilter(newOrFilter(newContainsFilter([]byte("f"), false), newContainsFilter([]byte("foobar"), false)), newContainsFilter([]byte("buzz"), false)), true},
+		{"((f.*)|foobar.*)|.*buzz", true, containsAny("f", "foobar", "buzz"), true},
 		{".*", true, TrueFilter, true},
 		{".*|.*", true, TrueFilter, true},
 		{".*||||", true, TrueFilter, true},
@@ -56,6 +57,8 @@ func Test_SimplifiedRegex(t *testing.T) {
 		{"(?i)foo", true, newContainsFilter([]byte("foo"), true), true},
 		{"(?i)界", true, newContainsFilter([]byte("界"), true), true},
 		{"(?i)ïB", true, newContainsFilter([]byte("ïB"), true), true},
+		{"(?i)foo|bar", true, newOrFilter(newContainsFilter([]byte("foo"), true), newContainsFilter([]byte("bar"), true)), true},
+		{"foo|ab|abcd|bc|cda", true, containsAny("foo", "ab", "abcd", "bc", "cda"), true},
 
 		// regex we are not supporting.
 		{"[a-z]+foo", true, nil, false},
@@ -98,6 +101,14 @@ func Test_SimplifiedRegex(t *testing.T) {
 	}
 }
 
+func containsAny(matches ...string) Filterer {
+	needles := make([][]byte, 0, len(matches))
+	for _, m := range matches {
+		needles = append(needles, []byte(m))
+	}
+	return newContainsAnyFilter(needles)
+}
+
 func allunicode() string {
 	var b []byte
 	for i := 0x00; i < 0x10FFFF; i++ {
@@ -139,6 +150,77 @@ func Test_TrueFilter(t *testing.T) {
 	}
 }
 
+func Test_containsAnyFilter(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		matches []string
+		line    string
+		want    bool
+	}{
+		{"overlapping he", []string{"he", "she", "his", "hers"}, "ushers", true},
+		{"overlapping his", []string{"he", "she", "his", "hers"}, "this", true},
+		{"overlapping none", []string{"he", "she", "his", "hers"}, "shirt", false},
+		{"suffix through failure link", []string{"abcd", "bce"}, "abce", true},
+		{"match at end", []string{"foo", "bar"}, "xxxbar", true},
+		{"match at start", []string{"foo", "bar"}, "fooxxx", true},
+		{"prefix only", []string{"foo", "bar"}, "fo ba", false},
+		{"empty line", []string{"foo", "bar"}, "", false},
+		{"unicode", []string{"世界", "Ï"}, "hello 世界", true},
+		{"case sensitive", []string{"foo", "bar"}, "FOO BAR", false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			f := containsAny(tc.matches...)
+			require.IsType(t, &containsAnyFilter{}, f)
+			require.Equal(t, tc.want, f.Filter([]byte(tc.line)))
+
+			_, ok := f.ToStage().Process(0, []byte(tc.line), nil)
+			require.Equal(t, tc.want, ok)
+		})
+	}
+
+	require.Equal(t, TrueFilter, containsAny("foo", ""))
+	require.Equal(t, newContainsFilter([]byte("foo"), false), containsAny("foo"))
+}
+
+func Test_NewOrFilter(t *testing.T) {
+	matches := []string{"foo", "bar", "buzz"}
+	for _, tc := range []struct {
+		mt   labels.MatchType
+		line string
+		want bool
+	}{
+		{labels.MatchEqual, "a foo b", true},
+		{labels.MatchEqual, "a buzz b", true},
+		{labels.MatchEqual, "a fizz b", false},
+		// != with alternatives keeps the lines containing none of them.
+		{labels.MatchNotEqual, "a fizz b", true},
+		{labels.MatchNotEqual, "a bar b", false},
+		{labels.MatchNotEqual, "foo bar", false},
+		{labels.MatchRegexp, "a buzz b", true},
+		{labels.MatchRegexp, "a fizz b", false},
+		{labels.MatchNotRegexp, "a fizz b", true},
+		{labels.MatchNotRegexp, "a foo b", false},
+	} {
+		t.Run(fmt.Sprintf("%s %s", tc.mt, tc.line), func(t *testing.T) {
+			f, err := NewOrFilter(matches, tc.mt)
+			require.NoError(t, err)
+			require.Equal(t, tc.want, f.Filter([]byte(tc.line)))
+		})
+	}
+
+	f, err := NewOrFilter([]string{"foo", "ba."}, labels.MatchRegexp)
+	require.NoError(t, err)
+	require.True(t, f.Filter([]byte("baz")))
+	require.False(t, f.Filter([]byte("ba")))
+
+	f, err = NewOrFilter([]string{"foo", ".*"}, labels.MatchRegexp)
+	require.NoError(t, err)
+	require.Equal(t, TrueFilter, f)
+
+	_, err = NewOrFilter([]string{"foo", "(bar"}, labels.MatchRegexp)
+	require.Error(t, err)
+}
+
 func Benchmark_LineFilter(b *testing.B) {
 	b.ReportAllocs()
 	logline := `level=bar ts=2020-02-22T14:57:59.398312973Z caller=logging.go:44 traceID=2107b6b551458908 msg="GET /buzz (200) 4.599635ms`
@@ -156,6 +238,7 @@ func Benchmark_LineFilter(b *testing.B) {
 		{".*foo.*|bar|uzz"},
 		{"((f.*)|foobar.*)|.*buzz"},
 		{"(?P<foo>.*foo.*|bar)"},
+		{"foo|bar|buzz|fizz|uzz|ms"},
 	} {
 		benchmarkRegex(b, test.re, logline, true)
 		benchmarkRegex(b, test.re, logline, false)
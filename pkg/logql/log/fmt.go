@@ -3,10 +3,12 @@ package log
 import (
 	"bytes"
 	"fmt"
+	"net/url"
 	"regexp"
 	"strings"
 	"text/template"
 	"text/template/parse"
+	"time"
 
 	"github.com/Masterminds/sprig/v3"
 
@@ -14,7 +16,8 @@ import (
 )
 
 const (
-	functionLineName = "__line__"
+	functionLineName      = "__line__"
+	functionTimestampName = "__timestamp__"
 )
 
 var (
@@ -41,6 +44,8 @@ var (
 			r := regexp.MustCompile(regex)
 			return r.ReplaceAllLiteralString(s, repl)
 		},
+		"urlencode": url.QueryEscape,
+		"urldecode": url.QueryUnescape,
 	}
 
 	// sprig template functions
@@ -84,6 +89,9 @@ var (
 		"toDate",
 		"now",
 		"unixEpoch",
+		"default",
+		"b64enc",
+		"b64dec",
 	}
 )
 
@@ -101,6 +109,7 @@ type LineFormatter struct {
 	buf *bytes.Buffer
 
 	currentLine []byte
+	currentTs   int64
 }
 
 // NewFormatter creates a new log line formatter from a given text template.
@@ -108,13 +117,7 @@ func NewFormatter(tmpl string) (*LineFormatter, error) {
 	lf := &LineFormatter{
 		buf: bytes.NewBuffer(make([]byte, 4096)),
 	}
-	functions := make(map[string]interface{}, len(functionMap)+1)
-	for k, v := range functionMap {
-		functions[k] = v
-	}
-	functions[functionLineName] = func() string {
-		return unsafeGetString(lf.currentLine)
-	}
+	functions := entryFunctions(&lf.currentLine, &lf.currentTs)
 	t, err := template.New("line").Option("missingkey=zero").Funcs(functions).Parse(tmpl)
 	if err != nil {
 		return nil, fmt.Errorf("invalid line template: %w", err)
@@ -123,9 +126,26 @@ func NewFormatter(tmpl string) (*LineFormatter, error) {
 	return lf, nil
 }
 
-func (lf *LineFormatter) Process(line []byte, lbs *LabelsBuilder) ([]byte, bool) {
+// entryFunctions returns the template functions with the functions giving access to the line and the timestamp
+// of the entry being formatted.
+func entryFunctions(line *[]byte, ts *int64) template.FuncMap {
+	functions := make(template.FuncMap, len(functionMap)+2)
+	for k, v := range functionMap {
+		functions[k] = v
+	}
+	functions[functionLineName] = func() string {
+		return unsafeGetString(*line)
+	}
+	functions[functionTimestampName] = func() time.Time {
+		return time.Unix(0, *ts)
+	}
+	return functions
+}
+
+func (lf *LineFormatter) Process(ts int64, line []byte, lbs *LabelsBuilder) ([]byte, bool) {
 	lf.buf.Reset()
 	lf.currentLine = line
+	lf.currentTs = ts
 
 	if err := lf.Template.Execute(lf.buf, lbs.Labels().Map()); err != nil {
 		lbs.SetErr(errTemplateFormat)
@@ -229,6 +249,9 @@ type labelFormatter struct {
 type LabelsFormatter struct {
 	formats []labelFormatter
 	buf     *bytes.Buffer
+
+	currentLine []byte
+	currentTs   int64
 }
 
 // NewLabelsFormatter creates a new formatter that can format multiple labels at once.
@@ -238,23 +261,24 @@ func NewLabelsFormatter(fmts []LabelFmt) (*LabelsFormatter, error) {
 	if err := validate(fmts); err != nil {
 		return nil, err
 	}
-	formats := make([]labelFormatter, 0, len(fmts))
+	lf := &LabelsFormatter{
+		formats: make([]labelFormatter, 0, len(fmts)),
+		buf:     bytes.NewBuffer(make([]byte, 1024)),
+	}
+	functions := entryFunctions(&lf.currentLine, &lf.currentTs)
 
 	for _, fm := range fmts {
 		toAdd := labelFormatter{LabelFmt: fm}
 		if !fm.Rename {
-			t, err := template.New("label").Option("missingkey=zero").Funcs(functionMap).Parse(fm.Value)
+			t, err := template.New("label").Option("missingkey=zero").Funcs(functions).Parse(fm.Value)
 			if err != nil {
 				return nil, fmt.Errorf("invalid template for label '%s': %s", fm.Name, err)
 			}
 			toAdd.tmpl = t
 		}
-		formats = append(formats, toAdd)
+		lf.formats = append(lf.formats, toAdd)
 	}
-	return &LabelsFormatter{
-		formats: formats,
-		buf:     bytes.NewBuffer(make([]byte, 1024)),
-	}, nil
+	return lf, nil
 }
 
 func validate(fmts []LabelFmt) error {
@@ -273,7 +297,10 @@ func validate(fmts []LabelFmt) error {
 	return nil
 }
 
-func (lf *LabelsFormatter) Process(l []byte, lbs *LabelsBuilder) ([]byte, bool) {
+func (lf *LabelsFormatter) Process(ts int64, l []byte, lbs *LabelsBuilder) ([]byte, bool) {
+	lf.currentLine = l
+	lf.currentTs = ts
+
 	var data interface{}
 	for _, f := range lf.formats {
 		if f.Rename {
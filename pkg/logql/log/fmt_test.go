@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"sort"
 	"testing"
+	"time"
 
 	"github.com/prometheus/prometheus/model/labels"
 	"github.com/stretchr/testify/require"
@@ -265,6 +266,94 @@ func Test_lineFormatter_Format(t *testing.T) {
 			labels.Labels{{Name: "bar", Value: "2"}},
 			[]byte("1"),
 		},
+		{
+			"timestamp",
+			newMustLineFormatter("{{ unixEpoch __timestamp__ }} {{ (__timestamp__).UTC.Format \"2006-01-02T15:04:05Z07:00\" }}"),
+			labels.Labels{},
+			[]byte("1635811200 2021-11-02T00:00:00Z"),
+			labels.Labels{},
+			nil,
+		},
+		{
+			"timestamp diff",
+			newMustLineFormatter("{{ sub (unixEpoch __timestamp__) (unixEpoch (toDate \"2006-01-02 MST\" .day)) }}"),
+			labels.Labels{{Name: "day", Value: "2021-11-01 UTC"}},
+			[]byte("86400"),
+			labels.Labels{{Name: "day", Value: "2021-11-01 UTC"}},
+			nil,
+		},
+		{
+			"math",
+			newMustLineFormatter("{{ add .a 1 }} {{ sub .a 1 }} {{ mul .a 2 }} {{ div .a 2 }} {{ round .b 1 }}"),
+			labels.Labels{{Name: "a", Value: "10"}, {Name: "b", Value: "1.25"}},
+			[]byte("11 9 20 5 1.3"),
+			labels.Labels{{Name: "a", Value: "10"}, {Name: "b", Value: "1.25"}},
+			nil,
+		},
+		{
+			"division by zero",
+			newMustLineFormatter("{{ div .a 0 }}"),
+			labels.Labels{{Name: "a", Value: "10"}},
+			[]byte("x"),
+			labels.Labels{{Name: "a", Value: "10"}, {Name: logqlmodel.ErrorLabel, Value: errTemplateFormat}},
+			[]byte("x"),
+		},
+		{
+			"base64",
+			newMustLineFormatter("{{ b64enc .foo }} {{ b64dec \"aGVsbG8gd29ybGQ=\" }}"),
+			labels.Labels{{Name: "foo", Value: "hello world"}},
+			[]byte("aGVsbG8gd29ybGQ= hello world"),
+			labels.Labels{{Name: "foo", Value: "hello world"}},
+			nil,
+		},
+		{
+			"url",
+			newMustLineFormatter("{{ urlencode .path }} {{ urldecode \"a%2Fb+c%3Fd\" }}"),
+			labels.Labels{{Name: "path", Value: "a/b c?d"}},
+			[]byte("a%2Fb+c%3Fd a/b c?d"),
+			labels.Labels{{Name: "path", Value: "a/b c?d"}},
+			nil,
+		},
+		{
+			"invalid url",
+			newMustLineFormatter("{{ urldecode .path }}"),
+			labels.Labels{{Name: "path", Value: "%zz"}},
+			[]byte("x"),
+			labels.Labels{{Name: "path", Value: "%zz"}, {Name: logqlmodel.ErrorLabel, Value: errTemplateFormat}},
+			[]byte("x"),
+		},
+		{
+			"default",
+			newMustLineFormatter("{{ .foo | default \"-\" }} {{ .bar | default \"-\" }}"),
+			labels.Labels{{Name: "foo", Value: "blip"}},
+			[]byte("blip -"),
+			labels.Labels{{Name: "foo", Value: "blip"}},
+			nil,
+		},
+		{
+			"contains and hasPrefix",
+			newMustLineFormatter("{{ if contains \"err\" .msg }}error{{ end }} {{ if hasPrefix \"GET\" .req }}get{{ end }}"),
+			labels.Labels{{Name: "msg", Value: "an error"}, {Name: "req", Value: "GET /"}},
+			[]byte("error get"),
+			labels.Labels{{Name: "msg", Value: "an error"}, {Name: "req", Value: "GET /"}},
+			nil,
+		},
+		{
+			"printf",
+			newMustLineFormatter("{{ printf \"%s took %05.1fs\" .op (float64 .duration) }}"),
+			labels.Labels{{Name: "op", Value: "query"}, {Name: "duration", Value: "1.25"}},
+			[]byte("query took 001.2s"),
+			labels.Labels{{Name: "op", Value: "query"}, {Name: "duration", Value: "1.25"}},
+			nil,
+		},
+		{
+			"fromJson",
+			newMustLineFormatter("{{ (fromJson __line__).user.id }} {{ (fromJson .bad).user }}"),
+			labels.Labels{{Name: "bad", Value: "{"}},
+			[]byte("42 <no value>"),
+			labels.Labels{{Name: "bad", Value: "{"}},
+			[]byte(`{"user":{"id":42}}`),
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -272,7 +361,7 @@ func Test_lineFormatter_Format(t *testing.T) {
 			sort.Sort(tt.wantLbs)
 			builder := NewBaseLabelsBuilder().ForLabels(tt.lbs, tt.lbs.Hash())
 			builder.Reset()
-			outLine, _ := tt.fmter.Process(tt.in, builder)
+			outLine, _ := tt.fmter.Process(time.Date(2021, 11, 2, 0, 0, 0, 0, time.UTC).UnixNano(), tt.in, builder)
 			require.Equal(t, tt.want, outLine)
 			require.Equal(t, tt.wantLbs, builder.Labels())
 		})
@@ -325,13 +414,22 @@ func Test_labelsFormatter_Format(t *testing.T) {
 			labels.Labels{{Name: "status", Value: "200"}},
 			labels.Labels{{Name: "status", Value: "2"}},
 		},
+		{
+			"line and timestamp",
+			mustNewLabelsFormatter([]LabelFmt{
+				NewTemplateLabelFmt("level", `{{ regexReplaceAll "level=(\\w+).*" __line__ "${1}" }}`),
+				NewTemplateLabelFmt("ts", "{{ unixEpoch __timestamp__ }}"),
+			}),
+			labels.Labels{{Name: "app", Value: "foo"}},
+			labels.Labels{{Name: "app", Value: "foo"}, {Name: "level", Value: "info"}, {Name: "ts", Value: "1635811200"}},
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			builder := NewBaseLabelsBuilder().ForLabels(tt.in, tt.in.Hash())
 			builder.Reset()
-			_, _ = tt.fmter.Process(nil, builder)
+			_, _ = tt.fmter.Process(time.Date(2021, 11, 2, 0, 0, 0, 0, time.UTC).UnixNano(), []byte("level=info msg=hello"), builder)
 			sort.Sort(tt.want)
 			require.Equal(t, tt.want, builder.Labels())
 		})
@@ -448,3 +546,71 @@ func TestLabelFormatter_RequiredLabelNames(t *testing.T) {
 		})
 	}
 }
+
+// Test_TemplateFunctionsCorpus runs the template functions of line_format and label_format on the inputs which made
+// them panic or misbehave when fuzzing, as the log line, as the value of a label and as the template itself.
+func Test_TemplateFunctionsCorpus(t *testing.T) {
+	templates := []string{
+		`{{ __line__ }}`,
+		`{{ __timestamp__ }}`,
+		`{{ unixEpoch __timestamp__ }}`,
+		`{{ date "2006-01-02T15:04:05" __timestamp__ }}`,
+		`{{ unixEpoch (toDate "2006-01-02" .foo) }}`,
+		`{{ div .foo 2 }}`,
+		`{{ div 2 .foo }}`,
+		`{{ div __line__ .foo }}`,
+		`{{ b64dec .foo }}`,
+		`{{ b64dec __line__ }}`,
+		`{{ urldecode .foo }}`,
+		`{{ urldecode __line__ }}`,
+		`{{ fromJson __line__ }}`,
+		`{{ range $k, $v := fromJson __line__ }}{{ $k }}={{ $v }} {{ end }}`,
+	}
+	ts := time.Date(2021, 11, 2, 0, 0, 0, 0, time.UTC).UnixNano()
+
+	for _, data := range []string{
+		"",
+		"0",
+		"-1",
+		"9223372036854775807",
+		"-9223372036854775808",
+		"1e308",
+		"NaN",
+		"2021-11-02",
+		"2021-11-02T15:04:05+01:00",
+		"aGVsbG8=",
+		"%",
+		"%zz",
+		"a+b%20c",
+		"\xff\xfe",
+		"{",
+		`{"foo":{"bar":[1,2,3]}}`,
+		`[1,"a",null]`,
+		"{{ .foo }}",
+		"{{ __line__ }}",
+		"{{ __timestamp__ }}",
+		"{{ div 1 0 }}",
+		"{{ template \"line\" }}",
+		"{{ printf \"%!\" }}",
+		"{{ b64dec \"!\" }}",
+		"{{ urldecode \"%zz\" }}",
+	} {
+		lbs := labels.Labels{{Name: "foo", Value: data}}
+		require.NotPanics(t, func() {
+			for _, tmpl := range append(templates, data) {
+				lf, err := NewFormatter(tmpl)
+				if err != nil {
+					continue
+				}
+				builder := NewBaseLabelsBuilder().ForLabels(lbs, lbs.Hash())
+				builder.Reset()
+				lf.Process(ts, []byte(data), builder)
+
+				lbf, err := NewLabelsFormatter([]LabelFmt{NewTemplateLabelFmt("bar", tmpl)})
+				require.NoError(t, err)
+				builder.Reset()
+				lbf.Process(ts, []byte(data), builder)
+			}
+		}, data)
+	}
+}
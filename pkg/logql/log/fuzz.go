@@ -0,0 +1,91 @@
+//go:build gofuzz
+// +build gofuzz
+
+package log
+
+import (
+	"time"
+
+	"github.com/prometheus/prometheus/model/labels"
+)
+
+// fuzzTemplates use each template function on the fuzzed line and label.
+var fuzzTemplates = []string{
+	`{{ __line__ }}`,
+	`{{ __timestamp__ }}`,
+	`{{ unixEpoch __timestamp__ }}`,
+	`{{ date "2006-01-02T15:04:05" __timestamp__ }}`,
+	`{{ unixEpoch (toDate "2006-01-02" .foo) }}`,
+	`{{ date "2006-01-02" (toDate "2006-01-02T15:04:05Z07:00" .foo) }}`,
+	`{{ add .foo 1 }}`,
+	`{{ sub .foo 1 }}`,
+	`{{ mul .foo 2 }}`,
+	`{{ div .foo 2 }}`,
+	`{{ div 2 .foo }}`,
+	`{{ round .foo 2 }}`,
+	`{{ b64enc .foo }}`,
+	`{{ b64dec .foo }}`,
+	`{{ b64dec __line__ }}`,
+	`{{ urlencode .foo }}`,
+	`{{ urldecode .foo }}`,
+	`{{ urldecode __line__ }}`,
+	`{{ .foo | default "-" }}`,
+	`{{ .missing | default .foo }}`,
+	`{{ contains "a" .foo }}`,
+	`{{ hasPrefix .foo __line__ }}`,
+	`{{ printf "%s %d %.2f %q" .foo (int .foo) (float64 .foo) __line__ }}`,
+	`{{ fromJson __line__ }}`,
+	`{{ (fromJson __line__).foo }}`,
+	`{{ range $k, $v := fromJson __line__ }}{{ $k }}={{ $v }} {{ end }}`,
+}
+
+var fuzzTimestamp = time.Date(2021, 11, 2, 0, 0, 0, 0, time.UTC).UnixNano()
+
+// FuzzTemplateFunctions formats the data, as the log line and as the value of a label, with templates using each
+// template function in line_format and label_format stages.
+func FuzzTemplateFunctions(data []byte) int {
+	lbs := labels.Labels{{Name: "foo", Value: string(data)}}
+	for _, tmpl := range fuzzTemplates {
+		lf, err := NewFormatter(tmpl)
+		if err != nil {
+			panic(err)
+		}
+		builder := NewBaseLabelsBuilder().ForLabels(lbs, lbs.Hash())
+		builder.Reset()
+		lf.Process(fuzzTimestamp, data, builder)
+
+		lbf, err := NewLabelsFormatter([]LabelFmt{NewTemplateLabelFmt("bar", tmpl)})
+		if err != nil {
+			panic(err)
+		}
+		builder.Reset()
+		lbf.Process(fuzzTimestamp, data, builder)
+	}
+	return 1
+}
+
+// FuzzLineFormat uses the data as a line_format template.
+func FuzzLineFormat(data []byte) int {
+	lf, err := NewFormatter(string(data))
+	if err != nil {
+		return 0
+	}
+	lbs := labels.Labels{{Name: "foo", Value: "bar"}}
+	builder := NewBaseLabelsBuilder().ForLabels(lbs, lbs.Hash())
+	builder.Reset()
+	lf.Process(fuzzTimestamp, []byte(`level=info msg="hello"`), builder)
+	return 1
+}
+
+// FuzzLabelFormat uses the data as a label_format template.
+func FuzzLabelFormat(data []byte) int {
+	lf, err := NewLabelsFormatter([]LabelFmt{NewTemplateLabelFmt("bar", string(data))})
+	if err != nil {
+		return 0
+	}
+	lbs := labels.Labels{{Name: "foo", Value: "bar"}}
+	builder := NewBaseLabelsBuilder().ForLabels(lbs, lbs.Hash())
+	builder.Reset()
+	lf.Process(fuzzTimestamp, []byte(`level=info msg="hello"`), builder)
+	return 1
+}
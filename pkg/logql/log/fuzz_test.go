@@ -0,0 +1,24 @@
+//go:build gofuzz
+// +build gofuzz
+
+package log
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const fuzzTestCaseEnvName = "FUZZ_TESTCASE_PATH"
+
+func Test_Fuzz(t *testing.T) {
+	fuzzTestPath := os.Getenv(fuzzTestCaseEnvName)
+	data, err := ioutil.ReadFile(fuzzTestPath)
+	require.NoError(t, err)
+	_ = FuzzTemplateFunctions(data)
+	_ = FuzzLineFormat(data)
+	_ = FuzzLabelFormat(data)
+}
+
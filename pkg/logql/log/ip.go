@@ -59,7 +59,7 @@ func (f *IPLineFilter) ToStage() Stage {
 }
 
 // `Process` implements `Stage` interface
-func (f *IPLineFilter) Process(line []byte, _ *LabelsBuilder) ([]byte, bool) {
+func (f *IPLineFilter) Process(_ int64, line []byte, _ *LabelsBuilder) ([]byte, bool) {
 	return line, f.filterTy(line, f.ty)
 }
 
@@ -103,7 +103,7 @@ func NewIPLabelFilter(pattern string, label string, ty LabelFilterType) *IPLabel
 }
 
 // `Process` implements `Stage` interface
-func (f *IPLabelFilter) Process(line []byte, lbs *LabelsBuilder) ([]byte, bool) {
+func (f *IPLabelFilter) Process(_ int64, line []byte, lbs *LabelsBuilder) ([]byte, bool) {
 	return line, f.filterTy(line, f.ty, lbs)
 }
 
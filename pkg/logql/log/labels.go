@@ -208,6 +208,18 @@ func (b *LabelsBuilder) Set(n, v string) *LabelsBuilder {
 	return b
 }
 
+// addStructuredMetadata adds the structured metadata of a line as labels.
+// Like with parsers, a name already used by the stream labels gets the _extracted suffix.
+func (b *LabelsBuilder) addStructuredMetadata(structuredMetadata []labels.Label) {
+	for _, l := range structuredMetadata {
+		name := l.Name
+		if b.BaseHas(name) {
+			name = name + duplicateSuffix
+		}
+		b.Set(name, l.Value)
+	}
+}
+
 // Labels returns the labels from the builder. If no modifications
 // were made, the original labels are returned.
 func (b *LabelsBuilder) Labels() labels.Labels {
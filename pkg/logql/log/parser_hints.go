@@ -99,3 +99,22 @@ func appendLabelHints(dst []string, src ...string) []string {
 	}
 	return dst
 }
+
+// SampleExtractorHints returns the hints given to the parsers of a sample extractor: the labels they are limited to
+// extracting, or noLabels when they don't need to extract any. Parsers extract all labels when there's neither.
+func SampleExtractorHints(ex SampleExtractor) (requiredLabels []string, noLabels bool) {
+	var builder *BaseLabelsBuilder
+	switch e := ex.(type) {
+	case *lineSampleExtractor:
+		builder = e.baseBuilder
+	case *labelSampleExtractor:
+		builder = e.baseBuilder
+	default:
+		return nil, false
+	}
+	hints, ok := builder.ParserLabelHints().(*parserHint)
+	if !ok {
+		return nil, false
+	}
+	return hints.requiredLabels, hints.noLabels
+}
@@ -12,12 +12,12 @@ import (
 
 func TestNoopPipeline(t *testing.T) {
 	lbs := labels.Labels{{Name: "foo", Value: "bar"}}
-	l, lbr, ok := NewNoopPipeline().ForStream(lbs).Process([]byte(""))
+	l, lbr, ok := NewNoopPipeline().ForStream(lbs).Process(0, []byte(""))
 	require.Equal(t, []byte(""), l)
 	require.Equal(t, NewLabelsResult(lbs, lbs.Hash()), lbr)
 	require.Equal(t, true, ok)
 
-	ls, lbr, ok := NewNoopPipeline().ForStream(lbs).ProcessString("")
+	ls, lbr, ok := NewNoopPipeline().ForStream(lbs).ProcessString(0, "")
 	require.Equal(t, "", ls)
 	require.Equal(t, NewLabelsResult(lbs, lbs.Hash()), lbr)
 	require.Equal(t, true, ok)
@@ -29,27 +29,51 @@ func TestPipeline(t *testing.T) {
 		NewStringLabelFilter(labels.MustNewMatcher(labels.MatchEqual, "foo", "bar")),
 		newMustLineFormatter("lbs {{.foo}}"),
 	})
-	l, lbr, ok := p.ForStream(lbs).Process([]byte("line"))
+	l, lbr, ok := p.ForStream(lbs).Process(0, []byte("line"))
 	require.Equal(t, []byte("lbs bar"), l)
 	require.Equal(t, NewLabelsResult(lbs, lbs.Hash()), lbr)
 	require.Equal(t, true, ok)
 
-	ls, lbr, ok := p.ForStream(lbs).ProcessString("line")
+	ls, lbr, ok := p.ForStream(lbs).ProcessString(0, "line")
 	require.Equal(t, "lbs bar", ls)
 	require.Equal(t, NewLabelsResult(lbs, lbs.Hash()), lbr)
 	require.Equal(t, true, ok)
 
-	l, lbr, ok = p.ForStream(labels.Labels{}).Process([]byte("line"))
+	l, lbr, ok = p.ForStream(labels.Labels{}).Process(0, []byte("line"))
 	require.Equal(t, []byte(nil), l)
 	require.Equal(t, nil, lbr)
 	require.Equal(t, false, ok)
 
-	ls, lbr, ok = p.ForStream(labels.Labels{}).ProcessString("line")
+	ls, lbr, ok = p.ForStream(labels.Labels{}).ProcessString(0, "line")
 	require.Equal(t, "", ls)
 	require.Equal(t, nil, lbr)
 	require.Equal(t, false, ok)
 }
 
+func TestPipelineWithStructuredMetadata(t *testing.T) {
+	lbs := labels.Labels{{Name: "foo", Value: "bar"}}
+	structuredMetadata := labels.Labels{{Name: "foo", Value: "baz"}, {Name: "trace_id", Value: "abc"}}
+	expected := labels.Labels{{Name: "foo", Value: "bar"}, {Name: "foo_extracted", Value: "baz"}, {Name: "trace_id", Value: "abc"}}
+
+	l, lbr, ok := NewNoopPipeline().ForStream(lbs).Process(0, []byte("line"), structuredMetadata...)
+	require.Equal(t, []byte("line"), l)
+	require.Equal(t, expected, lbr.Labels())
+	require.Equal(t, true, ok)
+
+	p := NewPipeline([]Stage{
+		NewStringLabelFilter(labels.MustNewMatcher(labels.MatchEqual, "trace_id", "abc")),
+		newMustLineFormatter("{{.trace_id}} {{__line__}}"),
+	})
+	ls, lbr, ok := p.ForStream(lbs).ProcessString(0, "line", structuredMetadata...)
+	require.Equal(t, "abc line", ls)
+	require.Equal(t, expected, lbr.Labels())
+	require.Equal(t, true, ok)
+
+	// the structured metadata of a line doesn't leak in the next line.
+	_, _, ok = p.ForStream(lbs).ProcessString(0, "line")
+	require.Equal(t, false, ok)
+}
+
 var (
 	resOK         bool
 	resLine       []byte
@@ -92,13 +116,13 @@ func Benchmark_Pipeline(b *testing.B) {
 	b.Run("pipeline bytes", func(b *testing.B) {
 		b.ResetTimer()
 		for n := 0; n < b.N; n++ {
-			resLine, resLbs, resOK = sp.Process(line)
+			resLine, resLbs, resOK = sp.Process(0, line)
 		}
 	})
 	b.Run("pipeline string", func(b *testing.B) {
 		b.ResetTimer()
 		for n := 0; n < b.N; n++ {
-			resLineString, resLbs, resOK = sp.ProcessString(lineString)
+			resLineString, resLbs, resOK = sp.ProcessString(0, lineString)
 		}
 	})
 
@@ -108,13 +132,13 @@ func Benchmark_Pipeline(b *testing.B) {
 	b.Run("line extractor bytes", func(b *testing.B) {
 		b.ResetTimer()
 		for n := 0; n < b.N; n++ {
-			resSample, resLbs, resOK = ex.Process(line)
+			resSample, resLbs, resOK = ex.Process(0, line)
 		}
 	})
 	b.Run("line extractor string", func(b *testing.B) {
 		b.ResetTimer()
 		for n := 0; n < b.N; n++ {
-			resSample, resLbs, resOK = ex.ProcessString(lineString)
+			resSample, resLbs, resOK = ex.ProcessString(0, lineString)
 		}
 	})
 
@@ -125,13 +149,13 @@ func Benchmark_Pipeline(b *testing.B) {
 	b.Run("label extractor bytes", func(b *testing.B) {
 		b.ResetTimer()
 		for n := 0; n < b.N; n++ {
-			resSample, resLbs, resOK = ex.Process(line)
+			resSample, resLbs, resOK = ex.Process(0, line)
 		}
 	})
 	b.Run("label extractor string", func(b *testing.B) {
 		b.ResetTimer()
 		for n := 0; n < b.N; n++ {
-			resSample, resLbs, resOK = ex.ProcessString(lineString)
+			resSample, resLbs, resOK = ex.ProcessString(0, lineString)
 		}
 	})
 }
@@ -164,7 +188,7 @@ func jsonBenchmark(b *testing.B, parser Stage) {
 	b.ResetTimer()
 	sp := p.ForStream(lbs)
 	for n := 0; n < b.N; n++ {
-		resLine, resLbs, resOK = sp.Process(line)
+		resLine, resLbs, resOK = sp.Process(0, line)
 
 		if !resOK {
 			b.Fatalf("resulting line not ok: %s\n", line)
@@ -187,7 +211,7 @@ func invalidJSONBenchmark(b *testing.B, parser Stage) {
 	b.ResetTimer()
 	sp := p.ForStream(labels.Labels{})
 	for n := 0; n < b.N; n++ {
-		resLine, resLbs, resOK = sp.Process(line)
+		resLine, resLbs, resOK = sp.Process(0, line)
 
 		if !resOK {
 			b.Fatalf("resulting line not ok: %s\n", line)
@@ -0,0 +1,365 @@
+package log
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/prometheus/common/model"
+)
+
+const xmlSpacer = '_'
+
+var (
+	_ Stage = &XMLParser{}
+	_ Stage = &XMLExpressionParser{}
+
+	errXMLNoElement = errors.New("no xml element")
+)
+
+type XMLParser struct {
+	reader *bytes.Reader
+	buf    []byte // buffer used to build element keys
+	text   []byte // character data of the current element
+
+	keys internedStringSet
+}
+
+// NewXMLParser creates a log stage that can parse a xml log line and add its elements and attributes as labels.
+// The label of an element or an attribute is its path from the root element joined with underscores,
+// e.g. Event_System_EventID. Elements are only added when they contain text.
+func NewXMLParser() *XMLParser {
+	return &XMLParser{
+		reader: bytes.NewReader(nil),
+		buf:    make([]byte, 0, 1024),
+		keys:   internedStringSet{},
+	}
+}
+
+func (x *XMLParser) Process(_ int64, line []byte, lbs *LabelsBuilder) ([]byte, bool) {
+	if lbs.ParserLabelHints().NoLabels() {
+		return line, true
+	}
+	x.reader.Reset(line)
+	dec := xml.NewDecoder(x.reader)
+
+	// reset the state.
+	x.buf = x.buf[:0]
+	// prefixes are the length of the key of the parent of each open element.
+	var prefixes []int
+	root := false
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			lbs.SetErr(errXML)
+			return line, true
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			root = true
+			parent := len(x.buf)
+			if parent > 0 {
+				x.buf = append(x.buf, xmlSpacer)
+			}
+			x.buf = append(x.buf, sanitizeLabelKey(t.Name.Local, parent == 0)...)
+			// If this element is not expected we skip it
+			if !lbs.ParserLabelHints().ShouldExtractPrefix(unsafeGetString(x.buf)) {
+				x.buf = x.buf[:parent]
+				if err := dec.Skip(); err != nil {
+					lbs.SetErr(errXML)
+					return line, true
+				}
+				continue
+			}
+			prefixes = append(prefixes, parent)
+			for _, attr := range t.Attr {
+				element := len(x.buf)
+				x.buf = append(x.buf, xmlSpacer)
+				x.buf = append(x.buf, sanitizeLabelKey(attr.Name.Local, false)...)
+				x.set(lbs, attr.Value)
+				x.buf = x.buf[:element]
+			}
+			x.text = x.text[:0]
+		case xml.CharData:
+			x.text = append(x.text, t...)
+		case xml.EndElement:
+			if len(prefixes) == 0 {
+				lbs.SetErr(errXML)
+				return line, true
+			}
+			if text := bytes.TrimSpace(x.text); len(text) > 0 {
+				x.set(lbs, string(text))
+			}
+			x.text = x.text[:0]
+			x.buf = x.buf[:prefixes[len(prefixes)-1]]
+			prefixes = prefixes[:len(prefixes)-1]
+		}
+	}
+	// a line without any element is not a xml document.
+	if !root {
+		lbs.SetErr(errXML)
+	}
+	return line, true
+}
+
+// set sets the label of the key in the buffer.
+func (x *XMLParser) set(lbs *LabelsBuilder, value string) {
+	key, ok := x.keys.Get(x.buf, func() (string, bool) {
+		key := string(x.buf)
+		if !lbs.ParserLabelHints().ShouldExtract(key) {
+			return "", false
+		}
+		if lbs.BaseHas(key) {
+			key = key + duplicateSuffix
+		}
+		return key, true
+	})
+	if !ok {
+		return
+	}
+	// the rune error replacement is rejected by Prometheus, so we skip it.
+	if strings.ContainsRune(value, utf8.RuneError) {
+		value = ""
+	}
+	lbs.Set(key, value)
+}
+
+func (x *XMLParser) RequiredLabelNames() []string { return []string{} }
+
+type XMLExpression struct {
+	Identifier string
+	Expression string
+}
+
+func NewXMLExpr(identifier, expression string) XMLExpression {
+	return XMLExpression{
+		Identifier: identifier,
+		Expression: expression,
+	}
+}
+
+// xmlPath is a parsed xml expression: the steps from the root element to an element, and possibly one of its attributes.
+type xmlPath struct {
+	steps []xmlStep
+	attr  string
+}
+
+// xmlStep matches an element by name, and by the value of one of its attributes when attr is set.
+type xmlStep struct {
+	name        string
+	attr, value string
+}
+
+func (s xmlStep) matches(e xml.StartElement) bool {
+	if s.name != "*" && s.name != e.Name.Local {
+		return false
+	}
+	if s.attr == "" {
+		return true
+	}
+	v, ok := xmlAttr(e, s.attr)
+	return ok && v == s.value
+}
+
+func xmlAttr(e xml.StartElement, name string) (string, bool) {
+	for _, attr := range e.Attr {
+		if attr.Name.Local == name {
+			return attr.Value, true
+		}
+	}
+	return "", false
+}
+
+// parseXMLPath parses a path of elements separated by slashes, starting from the root element and possibly ending with
+// an attribute, e.g. Event/System/Provider/@Name. Elements can be selected by an attribute value, e.g. Data[@Name='User'],
+// and * matches any element.
+func parseXMLPath(expr string) (xmlPath, error) {
+	var path xmlPath
+	parts := strings.Split(strings.TrimPrefix(expr, "/"), "/")
+	for i, part := range parts {
+		if strings.HasPrefix(part, "@") {
+			if i == 0 || i != len(parts)-1 {
+				return path, fmt.Errorf("attribute %s must follow the last element of the path", part)
+			}
+			path.attr = part[1:]
+			if path.attr == "" {
+				return path, fmt.Errorf("empty attribute name")
+			}
+			break
+		}
+		step := xmlStep{name: part}
+		if open := strings.Index(part, "["); open >= 0 {
+			step.name = part[:open]
+			predicate := part[open:]
+			if !strings.HasPrefix(predicate, "[@") || !strings.HasSuffix(predicate, "]") {
+				return path, fmt.Errorf("invalid predicate %s, expected [@attribute='value']", predicate)
+			}
+			predicate = predicate[2 : len(predicate)-1]
+			eq := strings.Index(predicate, "=")
+			if eq <= 0 {
+				return path, fmt.Errorf("invalid predicate [@%s], expected [@attribute='value']", predicate)
+			}
+			step.attr, step.value = predicate[:eq], predicate[eq+1:]
+			if len(step.value) < 2 || (step.value[0] != '\'' && step.value[0] != '"') || step.value[len(step.value)-1] != step.value[0] {
+				return path, fmt.Errorf("invalid predicate value %s, expected a quoted value", step.value)
+			}
+			step.value = step.value[1 : len(step.value)-1]
+		}
+		if step.name == "" {
+			return path, fmt.Errorf("empty element name")
+		}
+		path.steps = append(path.steps, step)
+	}
+	return path, nil
+}
+
+type XMLExpressionParser struct {
+	identifiers []string
+	paths       []xmlPath
+
+	reader *bytes.Reader
+	stack  []xml.StartElement
+	values []xmlValue
+
+	keys internedStringSet
+}
+
+// xmlValue is the value of an expression for a line.
+type xmlValue struct {
+	text []byte
+	// depth is the depth of the element whose text is being read, zero if it's not open.
+	depth int
+	found bool
+}
+
+// NewXMLExpressionParser creates a log stage extracting the elements or attributes matching paths into labels.
+// The value of an element is its text and the text of its children. The first matching element is extracted, an empty label
+// being added when there's none.
+func NewXMLExpressionParser(expressions []XMLExpression) (*XMLExpressionParser, error) {
+	p := &XMLExpressionParser{
+		reader: bytes.NewReader(nil),
+		values: make([]xmlValue, len(expressions)),
+		keys:   internedStringSet{},
+	}
+	for _, exp := range expressions {
+		path, err := parseXMLPath(exp.Expression)
+		if err != nil {
+			return nil, fmt.Errorf("cannot parse expression [%s]: %w", exp.Expression, err)
+		}
+
+		if !model.LabelName(exp.Identifier).IsValid() {
+			return nil, fmt.Errorf("invalid extracted label name '%s'", exp.Identifier)
+		}
+
+		p.identifiers = append(p.identifiers, exp.Identifier)
+		p.paths = append(p.paths, path)
+	}
+	return p, nil
+}
+
+func (x *XMLExpressionParser) Process(_ int64, line []byte, lbs *LabelsBuilder) ([]byte, bool) {
+	if lbs.ParserLabelHints().NoLabels() {
+		return line, true
+	}
+	if err := x.evaluate(line); err != nil {
+		lbs.SetErr(errXML)
+		return line, true
+	}
+
+	for i, identifier := range x.identifiers {
+		key, _ := x.keys.Get(unsafeGetBytes(identifier), func() (string, bool) {
+			if lbs.BaseHas(identifier) {
+				identifier = identifier + duplicateSuffix
+			}
+			return identifier, true
+		})
+		value := string(bytes.TrimSpace(x.values[i].text))
+		// the rune error replacement is rejected by Prometheus, so we skip it.
+		if strings.ContainsRune(value, utf8.RuneError) {
+			value = ""
+		}
+		lbs.Set(key, value)
+	}
+	return line, true
+}
+
+// evaluate sets the values of the expressions for a line.
+func (x *XMLExpressionParser) evaluate(line []byte) error {
+	x.reader.Reset(line)
+	dec := xml.NewDecoder(x.reader)
+
+	// reset the state.
+	x.stack = x.stack[:0]
+	for i := range x.values {
+		x.values[i] = xmlValue{text: x.values[i].text[:0]}
+	}
+
+	for root := false; ; {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			if !root {
+				return errXMLNoElement
+			}
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			root = true
+			x.stack = append(x.stack, t)
+			for i, path := range x.paths {
+				if x.values[i].found || !x.matches(path) {
+					continue
+				}
+				if path.attr == "" {
+					x.values[i].depth = len(x.stack)
+					continue
+				}
+				if v, ok := xmlAttr(t, path.attr); ok {
+					x.values[i].text = append(x.values[i].text, v...)
+					x.values[i].found = true
+				}
+			}
+		case xml.CharData:
+			for i := range x.values {
+				if x.values[i].depth > 0 {
+					x.values[i].text = append(x.values[i].text, t...)
+				}
+			}
+		case xml.EndElement:
+			for i := range x.values {
+				if x.values[i].depth == len(x.stack) {
+					x.values[i].depth = 0
+					x.values[i].found = true
+				}
+			}
+			if len(x.stack) > 0 {
+				x.stack = x.stack[:len(x.stack)-1]
+			}
+		}
+	}
+}
+
+// matches tells if the open elements match the steps of a path.
+func (x *XMLExpressionParser) matches(path xmlPath) bool {
+	if len(path.steps) != len(x.stack) {
+		return false
+	}
+	for i, step := range path.steps {
+		if !step.matches(x.stack[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func (x *XMLExpressionParser) RequiredLabelNames() []string { return []string{} }
@@ -0,0 +1,291 @@
+package log
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/loki/pkg/logqlmodel"
+)
+
+var xmlEventLine = []byte(`<Event xmlns="http://schemas.microsoft.com/win/2004/08/events/event">
+	<System>
+		<Provider Name="Microsoft-Windows-Security-Auditing"/>
+		<EventID>4625</EventID>
+		<Level>0</Level>
+	</System>
+	<EventData>
+		<Data Name="TargetUserName">admin</Data>
+		<Data Name="IpAddress">10.0.0.1</Data>
+	</EventData>
+</Event>`)
+
+func Test_xmlParser_Parse(t *testing.T) {
+	tests := []struct {
+		name string
+		line []byte
+		lbs  labels.Labels
+		want labels.Labels
+	}{
+		{
+			"elements and attributes",
+			[]byte(`<log level="info"><msg>hello world</msg><user id="1"><name>foo</name></user></log>`),
+			labels.Labels{},
+			labels.Labels{
+				{Name: "log_level", Value: "info"},
+				{Name: "log_msg", Value: "hello world"},
+				{Name: "log_user_id", Value: "1"},
+				{Name: "log_user_name", Value: "foo"},
+			},
+		},
+		{
+			"windows event",
+			xmlEventLine,
+			labels.Labels{},
+			labels.Labels{
+				{Name: "Event_xmlns", Value: "http://schemas.microsoft.com/win/2004/08/events/event"},
+				{Name: "Event_System_Provider_Name", Value: "Microsoft-Windows-Security-Auditing"},
+				{Name: "Event_System_EventID", Value: "4625"},
+				{Name: "Event_System_Level", Value: "0"},
+				{Name: "Event_EventData_Data_Name", Value: "IpAddress"},
+				{Name: "Event_EventData_Data", Value: "10.0.0.1"},
+			},
+		},
+		{
+			"sanitized keys",
+			[]byte(`<my-log><http.status code-class="2xx">200</http.status></my-log>`),
+			labels.Labels{},
+			labels.Labels{
+				{Name: "my_log_http_status", Value: "200"},
+				{Name: "my_log_http_status_code_class", Value: "2xx"},
+			},
+		},
+		{
+			"cdata and entities",
+			[]byte(`<log><msg><![CDATA[a < b]]></msg><path>a &amp; b</path></log>`),
+			labels.Labels{},
+			labels.Labels{
+				{Name: "log_msg", Value: "a < b"},
+				{Name: "log_path", Value: "a & b"},
+			},
+		},
+		{
+			"utf8 error rune",
+			[]byte(`<log><msg>�</msg><level>info</level></log>`),
+			labels.Labels{},
+			labels.Labels{
+				{Name: "log_msg", Value: ""},
+				{Name: "log_level", Value: "info"},
+			},
+		},
+		{
+			"duplicate",
+			[]byte(`<log><app>foo</app></log>`),
+			labels.Labels{{Name: "log_app", Value: "bar"}},
+			labels.Labels{
+				{Name: "log_app", Value: "bar"},
+				{Name: "log_app_extracted", Value: "foo"},
+			},
+		},
+		{
+			"bad xml",
+			[]byte(`<log><msg>foo</log>`),
+			labels.Labels{{Name: "app", Value: "foo"}},
+			labels.Labels{
+				{Name: "app", Value: "foo"},
+				{Name: logqlmodel.ErrorLabel, Value: errXML},
+			},
+		},
+		{
+			"not xml",
+			[]byte(`level=info msg=foo`),
+			labels.Labels{{Name: "app", Value: "foo"}},
+			labels.Labels{
+				{Name: "app", Value: "foo"},
+				{Name: logqlmodel.ErrorLabel, Value: errXML},
+			},
+		},
+	}
+	for _, tt := range tests {
+		j := NewXMLParser()
+		t.Run(tt.name, func(t *testing.T) {
+			b := NewBaseLabelsBuilder().ForLabels(tt.lbs, tt.lbs.Hash())
+			b.Reset()
+			_, _ = j.Process(0, tt.line, b)
+			sort.Sort(tt.want)
+			require.Equal(t, tt.want, b.Labels())
+		})
+	}
+}
+
+func Test_xmlParser_Hints(t *testing.T) {
+	p := NewXMLParser()
+	b := NewBaseLabelsBuilderWithGrouping(nil, newParserHint(nil, []string{"Event_System_EventID"}, false, false, ""), false, false).ForLabels(labels.Labels{}, 0)
+	b.Reset()
+	_, _ = p.Process(0, xmlEventLine, b)
+	require.Equal(t, labels.Labels{{Name: "Event_System_EventID", Value: "4625"}}, b.Labels())
+}
+
+func TestXMLExpressionParser(t *testing.T) {
+	tests := []struct {
+		name        string
+		line        []byte
+		expressions []XMLExpression
+		lbs         labels.Labels
+		want        labels.Labels
+	}{
+		{
+			"element",
+			xmlEventLine,
+			[]XMLExpression{
+				NewXMLExpr("id", "Event/System/EventID"),
+			},
+			labels.Labels{},
+			labels.Labels{
+				{Name: "id", Value: "4625"},
+			},
+		},
+		{
+			"leading slash and attribute",
+			xmlEventLine,
+			[]XMLExpression{
+				NewXMLExpr("provider", "/Event/System/Provider/@Name"),
+			},
+			labels.Labels{},
+			labels.Labels{
+				{Name: "provider", Value: "Microsoft-Windows-Security-Auditing"},
+			},
+		},
+		{
+			"predicates",
+			xmlEventLine,
+			[]XMLExpression{
+				NewXMLExpr("user", "Event/EventData/Data[@Name='TargetUserName']"),
+				NewXMLExpr("ip", `Event/EventData/Data[@Name="IpAddress"]`),
+			},
+			labels.Labels{},
+			labels.Labels{
+				{Name: "user", Value: "admin"},
+				{Name: "ip", Value: "10.0.0.1"},
+			},
+		},
+		{
+			"first match and wildcard",
+			xmlEventLine,
+			[]XMLExpression{
+				NewXMLExpr("data", "Event/*/Data"),
+			},
+			labels.Labels{},
+			labels.Labels{
+				{Name: "data", Value: "admin"},
+			},
+		},
+		{
+			"text of the children",
+			[]byte(`<log><msg>hello <b>world</b></msg></log>`),
+			[]XMLExpression{
+				NewXMLExpr("msg", "log/msg"),
+			},
+			labels.Labels{},
+			labels.Labels{
+				{Name: "msg", Value: "hello world"},
+			},
+		},
+		{
+			"missing",
+			xmlEventLine,
+			[]XMLExpression{
+				NewXMLExpr("missing", "Event/System/Missing"),
+				NewXMLExpr("attr", "Event/System/@Missing"),
+			},
+			labels.Labels{},
+			labels.Labels{
+				{Name: "missing", Value: ""},
+				{Name: "attr", Value: ""},
+			},
+		},
+		{
+			"duplicate",
+			xmlEventLine,
+			[]XMLExpression{
+				NewXMLExpr("app", "Event/System/EventID"),
+			},
+			labels.Labels{{Name: "app", Value: "foo"}},
+			labels.Labels{
+				{Name: "app", Value: "foo"},
+				{Name: "app_extracted", Value: "4625"},
+			},
+		},
+		{
+			"bad xml",
+			[]byte(`<log><msg>foo</log>`),
+			[]XMLExpression{
+				NewXMLExpr("msg", "log/msg"),
+			},
+			labels.Labels{{Name: "app", Value: "foo"}},
+			labels.Labels{
+				{Name: "app", Value: "foo"},
+				{Name: logqlmodel.ErrorLabel, Value: errXML},
+			},
+		},
+	}
+	for _, tt := range tests {
+		x, err := NewXMLExpressionParser(tt.expressions)
+		require.NoError(t, err)
+
+		t.Run(tt.name, func(t *testing.T) {
+			b := NewBaseLabelsBuilder().ForLabels(tt.lbs, tt.lbs.Hash())
+			b.Reset()
+			_, _ = x.Process(0, tt.line, b)
+			sort.Sort(tt.want)
+			require.Equal(t, tt.want, b.Labels())
+		})
+	}
+}
+
+func TestXMLExpressionParserFailures(t *testing.T) {
+	tests := []struct {
+		name       string
+		expression XMLExpression
+		error      string
+	}{
+		{
+			"attribute first",
+			NewXMLExpr("a", "@Name"),
+			"cannot parse expression [@Name]: attribute @Name must follow the last element of the path",
+		},
+		{
+			"attribute in the middle",
+			NewXMLExpr("a", "Event/@Name/System"),
+			"cannot parse expression [Event/@Name/System]: attribute @Name must follow the last element of the path",
+		},
+		{
+			"empty element",
+			NewXMLExpr("a", "Event//System"),
+			"cannot parse expression [Event//System]: empty element name",
+		},
+		{
+			"unquoted predicate",
+			NewXMLExpr("a", "Event/Data[@Name=User]"),
+			"cannot parse expression [Event/Data[@Name=User]]: invalid predicate value User, expected a quoted value",
+		},
+		{
+			"invalid predicate",
+			NewXMLExpr("a", "Event/Data[1]"),
+			"cannot parse expression [Event/Data[1]]: invalid predicate [1], expected [@attribute='value']",
+		},
+		{
+			"invalid label name",
+			NewXMLExpr("a-b", "Event/System"),
+			"invalid extracted label name 'a-b'",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewXMLExpressionParser([]XMLExpression{tt.expression})
+			require.EqualError(t, err, tt.error)
+		})
+	}
+}
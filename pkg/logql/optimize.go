@@ -1,5 +1,7 @@
 package logql
 
+import "strings"
+
 // optimizeSampleExpr Attempt to optimize the SampleExpr to another that will run faster but will produce the same result.
 func optimizeSampleExpr(expr SampleExpr) (SampleExpr, error) {
 	var skip bool
@@ -31,9 +33,7 @@ func removeLineformat(expr SampleExpr) {
 		if !ok {
 			return
 		}
-		// bytes operation count bytes of the log line so line_format changes the result.
-		if rangeExpr.Operation == OpRangeTypeBytes ||
-			rangeExpr.Operation == OpRangeTypeBytesRate {
+		if !lineFmtIgnored(rangeExpr.Operation) {
 			return
 		}
 		pipelineExpr, ok := rangeExpr.Left.Left.(*PipelineExpr)
@@ -42,26 +42,7 @@ func removeLineformat(expr SampleExpr) {
 		}
 		temp := pipelineExpr.MultiStages[:0]
 		for i, s := range pipelineExpr.MultiStages {
-			_, ok := s.(*LineFmtExpr)
-			if !ok {
-				temp = append(temp, s)
-				continue
-			}
-			// we found a lineFmtExpr, we need to check if it's followed by a labelParser or lineFilter
-			// in which case it could be useful for further processing.
-			var found bool
-			for j := i; j < len(pipelineExpr.MultiStages); j++ {
-				if _, ok := pipelineExpr.MultiStages[j].(*LabelParserExpr); ok {
-					found = true
-					break
-				}
-				if _, ok := pipelineExpr.MultiStages[j].(*LineFilterExpr); ok {
-					found = true
-					break
-				}
-			}
-			if found {
-				// we cannot remove safely the linefmtExpr.
+			if !isUnusedLineFmt(pipelineExpr.MultiStages, i) {
 				temp = append(temp, s)
 			}
 		}
@@ -74,3 +55,32 @@ func removeLineformat(expr SampleExpr) {
 		}
 	})
 }
+
+// lineFmtIgnored tells if the lines formatted by line_format are ignored by a range aggregation.
+// bytes operation count bytes of the log line so line_format changes the result.
+func lineFmtIgnored(operation string) bool {
+	return operation != OpRangeTypeBytes && operation != OpRangeTypeBytesRate
+}
+
+// isUnusedLineFmt tells if the stage i of the pipeline of a range aggregation ignoring formatted lines
+// is a line_format that can be removed.
+func isUnusedLineFmt(stages MultiStageExpr, i int) bool {
+	if _, ok := stages[i].(*LineFmtExpr); !ok {
+		return false
+	}
+	// we found a lineFmtExpr, we need to check if it's followed by a labelParser, lineFilter
+	// or a labelFmt using the line in which case it could be useful for further processing.
+	for j := i; j < len(stages); j++ {
+		switch s := stages[j].(type) {
+		case *LabelParserExpr, *JSONExpressionParser, *XMLExpressionParser, *LineFilterExpr:
+			return false
+		case *LabelFmtExpr:
+			for _, f := range s.Formats {
+				if !f.Rename && strings.Contains(f.Value, "__line__") {
+					return false
+				}
+			}
+		}
+	}
+	return true
+}
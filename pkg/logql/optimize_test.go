@@ -18,12 +18,15 @@ func Test_optimizeSampleExpr(t *testing.T) {
 		{`sum by(name)(bytes_over_time({region="us-east1"} | line_format "something else"[5m]))`, `sum by(name)(bytes_over_time({region="us-east1"} | line_format "something else"[5m]))`},
 		{`sum by(name)(rate({region="us-east1"} | json | line_format "something else" |= "something"[5m]))`, `sum by(name)(rate({region="us-east1"} | json | line_format "something else" |= "something"[5m]))`},
 		{`sum by(name)(rate({region="us-east1"} | json | line_format "something else" | logfmt[5m]))`, `sum by(name)(rate({region="us-east1"} | json | line_format "something else" | logfmt[5m]))`},
+		{`sum by(name)(rate({region="us-east1"} | line_format "{{.foo}}" | label_format name="{{__line__}}"[5m]))`, `sum by(name)(rate({region="us-east1"} | line_format "{{.foo}}" | label_format name="{{__line__}}"[5m]))`},
+		{`sum by(name)(rate({region="us-east1"} | line_format "{{.foo}}" | json name="bar"[5m]))`, `sum by(name)(rate({region="us-east1"} | line_format "{{.foo}}" | json name="bar"[5m]))`},
 
 		// remove line_format that is not required.
 		{`sum by(name)(rate({region="us-east1"} | line_format "something else"[5m]))`, `sum by(name)(rate({region="us-east1"}[5m]))`},
 		{`sum by(name)(rate({region="us-east1"} | json | line_format "something else" | unwrap foo[5m]))`, `sum by(name)(rate({region="us-east1"} | json | unwrap foo[5m]))`},
 		{`quantile_over_time(1,{region="us-east1"} | json | line_format "something else" | unwrap foo[5m])`, `quantile_over_time(1,{region="us-east1"} | json | unwrap foo[5m])`},
 		{`sum by(name)(count_over_time({region="us-east1"} | json | line_format "something else" | label_format foo=bar | line_format "boo"[5m]))`, `sum by(name)(count_over_time({region="us-east1"} | json | label_format foo=bar[5m]))`},
+		{`sum by(name)(count_over_time({region="us-east1"} | line_format "{{.foo}}" | label_format name="{{.bar}}"[5m]))`, `sum by(name)(count_over_time({region="us-east1"} | label_format name="{{.bar}}"[5m]))`},
 	}
 	for _, tt := range tests {
 		t.Run(tt.in, func(t *testing.T) {
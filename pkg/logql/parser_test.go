@@ -320,6 +320,89 @@ func TestParse(t *testing.T) {
 				Without: false,
 			}, nil),
 		},
+		{
+			in: `max_over_time(rate({ foo = "bar" }[1m])[1h:5m])`,
+			exp: &SubqueryAggregationExpr{
+				Left: &SubqueryExpr{
+					Left:     newRangeAggregationExpr(&LogRange{Left: newMatcherExpr([]*labels.Matcher{mustNewMatcher(labels.MatchEqual, "foo", "bar")}), Interval: time.Minute}, OpRangeTypeRate, nil, nil),
+					Interval: time.Hour,
+					Step:     5 * time.Minute,
+				},
+				Operation: OpRangeTypeMax,
+			},
+		},
+		{
+			in: `quantile_over_time(0.9, (sum(rate({ foo = "bar" }[1m]))[1h:] offset 10m))`,
+			exp: &SubqueryAggregationExpr{
+				Left: &SubqueryExpr{
+					Left: mustNewVectorAggregationExpr(
+						newRangeAggregationExpr(&LogRange{Left: newMatcherExpr([]*labels.Matcher{mustNewMatcher(labels.MatchEqual, "foo", "bar")}), Interval: time.Minute}, OpRangeTypeRate, nil, nil),
+						OpTypeSum, nil, nil,
+					),
+					Interval: time.Hour,
+					Offset:   10 * time.Minute,
+				},
+				Operation: OpRangeTypeQuantile,
+				Params:    func() *float64 { p := 0.9; return &p }(),
+			},
+		},
+		{
+			in: `quantile_sketch_over_time(0.01, { foo = "bar" } | unwrap latency [5m]) by (foo)`,
+			exp: newRangeAggregationExpr(
+				newLogRange(newMatcherExpr([]*labels.Matcher{mustNewMatcher(labels.MatchEqual, "foo", "bar")}), 5*time.Minute, newUnwrapExpr("latency", ""), nil),
+				OpRangeTypeQuantileSketch, &Grouping{Groups: []string{"foo"}}, NewStringLabelFilter("0.01"),
+			),
+		},
+		{
+			in: `approx_count_distinct_over_time(user, { foo = "bar" } | json [5m]) by (path)`,
+			exp: &RangeAggregationExpr{
+				Left: newLogRange(
+					newPipelineExpr(newMatcherExpr([]*labels.Matcher{mustNewMatcher(labels.MatchEqual, "foo", "bar")}), MultiStageExpr{newLabelParserExpr(OpParserTypeJSON, "")}),
+					5*time.Minute, nil, nil,
+				),
+				Operation: OpRangeTypeApproxCountDistinct,
+				Label:     "user",
+				Grouping:  &Grouping{Groups: []string{"path"}},
+			},
+		},
+		{
+			in:  `approx_count_distinct_over_time({ foo = "bar" }[5m])`,
+			err: logqlmodel.NewParseError("label required for operation approx_count_distinct_over_time", 0, 0),
+		},
+		{
+			in:  `count_over_time(user, { foo = "bar" }[5m])`,
+			err: logqlmodel.NewParseError("label user not supported for operation count_over_time", 0, 0),
+		},
+		{
+			in: `approx_topk(10, sum by (ip) (count_over_time({ foo = "bar" }[5m])))`,
+			exp: mustNewVectorAggregationExpr(
+				mustNewVectorAggregationExpr(
+					newRangeAggregationExpr(newLogRange(newMatcherExpr([]*labels.Matcher{mustNewMatcher(labels.MatchEqual, "foo", "bar")}), 5*time.Minute, nil, nil), OpRangeTypeCount, nil, nil),
+					OpTypeSum, &Grouping{Groups: []string{"ip"}}, nil,
+				),
+				OpTypeApproxTopK, nil, NewStringLabelFilter("10"),
+			),
+		},
+		{
+			in:  `approx_topk(10, count_over_time({ foo = "bar" }[5m])) by (foo)`,
+			err: logqlmodel.NewParseError("grouping not allowed for approx_topk aggregation", 0, 0),
+		},
+		{
+			in:  `quantile_sketch_over_time(1, { foo = "bar" } | unwrap latency [5m])`,
+			err: logqlmodel.NewParseError("relative accuracy of quantile_sketch_over_time must be between 0 and 1", 0, 0),
+		},
+		{
+			in:  `bytes_rate(rate({ foo = "bar" }[1m])[1h:5m])`,
+			err: logqlmodel.NewParseError("invalid aggregation bytes_rate over a subquery", 0, 0),
+		},
+		{
+			in:  `max_over_time(rate({ foo = "bar" }[1m])[0s:5m])`,
+			err: logqlmodel.NewParseError("subquery range must be greater than zero", 0, 0),
+		},
+		{
+			in:  `max_over_time(rate({ foo = "bar" }[1m])[1h:5minutes])`,
+			err: logqlmodel.NewParseError(`not a valid duration string: "5minutes"`, 0, 40),
+		},
 		{
 			in:  `unk({ foo = "bar" }[5m])`,
 			err: logqlmodel.NewParseError("syntax error: unexpected IDENTIFIER", 1, 1),
@@ -348,6 +431,34 @@ func TestParse(t *testing.T) {
 			in:  `min({ foo = "bar" }[5m])`,
 			err: logqlmodel.NewParseError("syntax error: unexpected RANGE", 0, 20),
 		},
+		{
+			in: `{foo="bar"} |= "a" or "b" or "c"`,
+			exp: newPipelineExpr(
+				newMatcherExpr([]*labels.Matcher{mustNewMatcher(labels.MatchEqual, "foo", "bar")}),
+				MultiStageExpr{
+					newOrLineFilterExpr(labels.MatchEqual, []string{"a", "b", "c"}),
+				},
+			),
+		},
+		{
+			in: `{foo="bar"} |= "baz" != "a" or "b" |~ "c.*" or "d"`,
+			exp: newPipelineExpr(
+				newMatcherExpr([]*labels.Matcher{mustNewMatcher(labels.MatchEqual, "foo", "bar")}),
+				MultiStageExpr{
+					newNestedLineFilterExpr(
+						newNestedLineFilterExpr(
+							newLineFilterExpr(labels.MatchEqual, "", "baz"),
+							newOrLineFilterExpr(labels.MatchNotEqual, []string{"a", "b"}),
+						),
+						newOrLineFilterExpr(labels.MatchRegexp, []string{"c.*", "d"}),
+					),
+				},
+			),
+		},
+		{
+			in:  `{foo="bar"} |= "a" or`,
+			err: logqlmodel.NewParseError("syntax error: unexpected $end, expecting STRING", 1, 22),
+		},
 		// line filter for ip-matcher
 		{
 			in: `{foo="bar"} |= "baz" |= ip("123.123.123.123")`,
@@ -2841,7 +2952,7 @@ func TestParse(t *testing.T) {
 		},
 		{
 			in:  `quantile_over_time(foo,{namespace="tns"} |= "level=error" | json |foo>=5,bar<25ms| unwrap latency [5m])`,
-			err: logqlmodel.NewParseError("syntax error: unexpected IDENTIFIER, expecting NUMBER or { or (", 1, 20),
+			err: logqlmodel.NewParseError("label foo not supported for operation quantile_over_time", 0, 0),
 		},
 		{
 			in: `{app="foo"}
@@ -2942,6 +3053,28 @@ func TestParse(t *testing.T) {
 				},
 			},
 		},
+		{
+			in: `{app="foo"} | xml | cef`,
+			exp: &PipelineExpr{
+				Left: newMatcherExpr([]*labels.Matcher{{Type: labels.MatchEqual, Name: "app", Value: "foo"}}),
+				MultiStages: MultiStageExpr{
+					newLabelParserExpr(OpParserTypeXML, ""),
+					newLabelParserExpr(OpParserTypeCEF, ""),
+				},
+			},
+		},
+		{
+			in: `{app="foo"} | xml id="Event/System/EventID", user="Event/EventData/Data[@Name='User']"`,
+			exp: &PipelineExpr{
+				Left: newMatcherExpr([]*labels.Matcher{{Type: labels.MatchEqual, Name: "app", Value: "foo"}}),
+				MultiStages: MultiStageExpr{
+					newXMLExpressionParser([]log.XMLExpression{
+						log.NewXMLExpr("id", `Event/System/EventID`),
+						log.NewXMLExpr("user", `Event/EventData/Data[@Name='User']`),
+					}),
+				},
+			},
+		},
 	} {
 		t.Run(tc.in, func(t *testing.T) {
 			ast, err := ParseExpr(tc.in)
@@ -3039,7 +3172,7 @@ func Test_PipelineCombined(t *testing.T) {
 	p, err := expr.Pipeline()
 	require.Nil(t, err)
 	sp := p.ForStream(labels.Labels{})
-	line, lbs, ok := sp.Process([]byte(`level=debug ts=2020-10-02T10:10:42.092268913Z caller=logging.go:66 traceID=a9d4d8a928d8db1 msg="POST /api/prom/api/v1/query_range (200) 1.5s"`))
+	line, lbs, ok := sp.Process(0, []byte(`level=debug ts=2020-10-02T10:10:42.092268913Z caller=logging.go:66 traceID=a9d4d8a928d8db1 msg="POST /api/prom/api/v1/query_range (200) 1.5s"`))
 	require.True(t, ok)
 	require.Equal(
 		t,
@@ -3228,3 +3361,22 @@ func mustMatcher(t labels.MatchType, n string, v string) *labels.Matcher {
 	}
 	return m
 }
+
+func TestParse_ParserNamesAsLabelNames(t *testing.T) {
+	for _, tc := range []struct {
+		in  string
+		exp string
+	}{
+		{`{xml="a", cef="b"}`, `{xml="a", cef="b"}`},
+		{`{app="foo"} | xml | xml="a" | cef != "b"`, `{app="foo"} | xml | xml="a" | cef!="b"`},
+		{`{app="foo"} | json xml="data.xml" | label_format cef=xml`, `{app="foo"} | json xml="data.xml" | label_format cef=xml`},
+		{`sum by (xml) (count_over_time({app="foo"} | cef [5m]))`, `sum by(xml)(count_over_time({app="foo"} | cef[5m]))`},
+		{`sum(sum_over_time({app="foo"} | xml | unwrap cef [5m])) without (cef)`, `sum without(cef)(sum_over_time({app="foo"} | xml | unwrap cef[5m]))`},
+	} {
+		t.Run(tc.in, func(t *testing.T) {
+			ast, err := ParseExpr(tc.in)
+			require.NoError(t, err)
+			require.Equal(t, tc.exp, ast.String())
+		})
+	}
+}
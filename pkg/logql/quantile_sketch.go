@@ -0,0 +1,171 @@
+package logql
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/promql"
+
+	"github.com/grafana/loki/pkg/logql/sketch"
+	"github.com/grafana/loki/pkg/logqlmodel"
+)
+
+// QuantileSketchBucketLabel is the label holding the bucket of the samples returned by quantile_sketch_over_time,
+// the value of each sample being the number of values of its bucket.
+const QuantileSketchBucketLabel = "__quantile_sketch_bucket__"
+
+// QuantileSketchMergeExpr evaluates a quantile from the sketches returned by the shards of a quantile_sketch_over_time query,
+// the sketches of the same series being merged whatever the shard they come from.
+type QuantileSketchMergeExpr struct {
+	Left             SampleExpr
+	Quantile         float64
+	RelativeAccuracy float64
+
+	implicit
+}
+
+func (e QuantileSketchMergeExpr) String() string {
+	return fmt.Sprintf("quantile_sketch_merge<%s, %s>", strconv.FormatFloat(e.Quantile, 'f', -1, 64), e.Left.String())
+}
+
+func (e *QuantileSketchMergeExpr) Selector() LogSelectorExpr { return e.Left.Selector() }
+
+func (e *QuantileSketchMergeExpr) Extractor() (SampleExtractor, error) { return e.Left.Extractor() }
+
+func (e *QuantileSketchMergeExpr) Shardable() bool { return false }
+
+func (e *QuantileSketchMergeExpr) Walk(f WalkFn) {
+	f(e)
+	e.Left.Walk(f)
+}
+
+// quantileSketchEvaluator sketches the values of each range, returning a sample per bucket of each sketch.
+type quantileSketchEvaluator struct {
+	iter             RangeVectorIterator
+	relativeAccuracy float64
+
+	lb  *labels.Builder
+	err error
+}
+
+func (e *quantileSketchEvaluator) Next() (bool, int64, promql.Vector) {
+	next := e.iter.Next()
+	if !next {
+		return false, 0, promql.Vector{}
+	}
+	// At aggregates the series in the order of the returned vector.
+	var sketches []*sketch.DDSketch
+	ts, vec := e.iter.At(func(points []promql.Point) float64 {
+		s, err := sketch.NewDDSketch(e.relativeAccuracy)
+		if err != nil {
+			e.err = err
+			return 0
+		}
+		for _, p := range points {
+			s.Add(p.V)
+		}
+		sketches = append(sketches, s)
+		return 0
+	})
+	if e.err != nil {
+		return false, 0, promql.Vector{}
+	}
+	if e.lb == nil {
+		e.lb = labels.NewBuilder(nil)
+	}
+	result := make(promql.Vector, 0, len(vec))
+	for i, s := range vec {
+		// Errors are not allowed in metrics.
+		if s.Metric.Has(logqlmodel.ErrorLabel) {
+			e.err = logqlmodel.NewPipelineErr(s.Metric)
+			return false, 0, promql.Vector{}
+		}
+		sketches[i].ForEach(func(key string, count float64) {
+			e.lb.Reset(s.Metric)
+			e.lb.Set(QuantileSketchBucketLabel, key)
+			result = append(result, promql.Sample{
+				Point:  promql.Point{T: ts, V: count},
+				Metric: e.lb.Labels(),
+			})
+		})
+	}
+	return true, ts, result
+}
+
+func (e *quantileSketchEvaluator) Close() error { return e.iter.Close() }
+
+func (e *quantileSketchEvaluator) Error() error {
+	if e.err != nil {
+		return e.err
+	}
+	return e.iter.Error()
+}
+
+// quantileSketchMergeEvaluator rebuilds the sketches of each series from the samples of their buckets,
+// and evaluates the quantile of the merged sketches.
+func quantileSketchMergeEvaluator(
+	ctx context.Context,
+	ev SampleEvaluator,
+	expr *QuantileSketchMergeExpr,
+	q Params,
+) (StepEvaluator, error) {
+	nextEvaluator, err := ev.StepEvaluator(ctx, ev, expr.Left, q)
+	if err != nil {
+		return nil, err
+	}
+	type seriesSketch struct {
+		metric labels.Labels
+		sketch *sketch.DDSketch
+	}
+	var (
+		mergeErr error
+		lb       = labels.NewBuilder(nil)
+		buf      = make([]byte, 0, 1024)
+	)
+	return newStepEvaluator(func() (bool, int64, promql.Vector) {
+		next, ts, vec := nextEvaluator.Next()
+		if !next {
+			return false, 0, promql.Vector{}
+		}
+		var (
+			series   []*seriesSketch
+			byHash   = map[uint64]*seriesSketch{}
+			seriesID uint64
+		)
+		for _, s := range vec {
+			seriesID, buf = s.Metric.HashWithoutLabels(buf, QuantileSketchBucketLabel)
+			ss, ok := byHash[seriesID]
+			if !ok {
+				sk, err := sketch.NewDDSketch(expr.RelativeAccuracy)
+				if err != nil {
+					mergeErr = err
+					return false, 0, promql.Vector{}
+				}
+				lb.Reset(s.Metric)
+				lb.Del(QuantileSketchBucketLabel)
+				ss = &seriesSketch{metric: lb.Labels(), sketch: sk}
+				byHash[seriesID] = ss
+				series = append(series, ss)
+			}
+			if err := ss.sketch.AddBucket(s.Metric.Get(QuantileSketchBucketLabel), s.V); err != nil {
+				mergeErr = err
+				return false, 0, promql.Vector{}
+			}
+		}
+		result := make(promql.Vector, 0, len(series))
+		for _, ss := range series {
+			result = append(result, promql.Sample{
+				Point:  promql.Point{T: ts, V: ss.sketch.Quantile(expr.Quantile)},
+				Metric: ss.metric,
+			})
+		}
+		return true, ts, result
+	}, nextEvaluator.Close, func() error {
+		if mergeErr != nil {
+			return mergeErr
+		}
+		return nextEvaluator.Error()
+	})
+}
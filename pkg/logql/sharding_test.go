@@ -3,6 +3,7 @@ package logql
 import (
 	"context"
 	"math"
+	"sort"
 	"testing"
 	"time"
 
@@ -48,6 +49,8 @@ func TestMappingEquivalence(t *testing.T) {
 		{`sum(max(rate({a=~".+"}[1s])))`, false},
 		{`max(count(rate({a=~".+"}[1s])))`, false},
 		{`max(sum by (cluster) (rate({a=~".+"}[1s]))) / count(rate({a=~".+"}[1s]))`, false},
+		{`max_over_time(sum(rate({a=~".+"}[1s]))[5s:2s])`, false},
+		{`sum by (a) (max_over_time(rate({a=~".+"}[1s])[4s:1s]))`, false},
 		// topk prefers already-seen values in tiebreakers. Since the test data generates
 		// the same log lines for each series & the resulting promql.Vectors aren't deterministically
 		// sorted by labels, we don't expect this to pass.
@@ -99,6 +102,200 @@ func TestMappingEquivalence(t *testing.T) {
 	}
 }
 
+func TestQuantileSketchMappingEquivalence(t *testing.T) {
+	var (
+		shards   = 3
+		nStreams = 60
+		rounds   = 20
+		streams  = randomStreams(nStreams, rounds+1, shards, []string{"a", "b", "c", "d"})
+		start    = time.Unix(0, 0)
+		end      = time.Unix(0, int64(time.Second*time.Duration(rounds)))
+		step     = time.Second
+		accuracy = 0.01
+	)
+
+	for _, query := range []string{
+		`quantile_over_time(0.9, {a=~".+"} | regexp "number: (?P<n>\\d+)" | unwrap n [5s])`,
+		`quantile_over_time(0.5, {a=~".+"} | regexp "number: (?P<n>\\d+)" | unwrap n [5s]) by (a)`,
+		`max by (b) (quantile_over_time(0.99, {a=~".+"} | regexp "number: (?P<n>\\d+)" | label_format a="x" | unwrap n [10s]) by (a, b))`,
+	} {
+		q := NewMockQuerier(shards, streams)
+		regular := NewEngine(EngineOpts{}, q, NoLimits, log.NewNopLogger())
+		sharded := NewShardedEngine(EngineOpts{}, MockDownstreamer{regular}, nilMetrics, NoLimits, log.NewNopLogger())
+
+		t.Run(query, func(t *testing.T) {
+			params := NewLiteralParams(query, start, end, step, 0, logproto.FORWARD, 100, nil)
+			ctx := user.InjectOrgID(context.Background(), "fake")
+
+			mapper, err := NewShardMapper(shards, nilMetrics)
+			require.NoError(t, err)
+			noop, mapped, err := mapper.WithQuantileSketches(accuracy).Parse(query)
+			require.NoError(t, err)
+			require.False(t, noop)
+
+			res, err := regular.Query(params).Exec(ctx)
+			require.NoError(t, err)
+			shardedRes, err := sharded.Query(params, mapped).Exec(ctx)
+			require.NoError(t, err)
+
+			expected, actual := res.Data.(promql.Matrix), shardedRes.Data.(promql.Matrix)
+			require.Equal(t, len(expected), len(actual))
+			for i := range expected {
+				require.Equal(t, expected[i].Metric, actual[i].Metric)
+				require.Equal(t, len(expected[i].Points), len(actual[i].Points))
+				for j, p := range expected[i].Points {
+					require.Equal(t, p.T, actual[i].Points[j].T)
+					require.InDelta(t, p.V, actual[i].Points[j].V, accuracy*p.V)
+				}
+			}
+		})
+	}
+}
+
+func TestCountDistinctMappingEquivalence(t *testing.T) {
+	var (
+		shards   = 3
+		nStreams = 60
+		rounds   = 20
+		streams  = randomStreams(nStreams, rounds+1, shards, []string{"a", "b", "c", "d"})
+		start    = time.Unix(0, 0)
+		end      = time.Unix(0, int64(time.Second*time.Duration(rounds)))
+		step     = time.Second
+	)
+
+	for _, tc := range []struct {
+		query string
+		exact string
+	}{
+		{
+			`approx_count_distinct_over_time(index, {a=~".+"}[5s]) by (a)`,
+			`count by (a) (count_over_time({a=~".+"}[5s]))`,
+		},
+		{
+			`approx_count_distinct_over_time(index, {a=~".+"} | label_format b="x" [5s]) without (c, d)`,
+			`count by (a, b) (count_over_time({a=~".+"} | label_format b="x" [5s]))`,
+		},
+		{
+			`max(approx_count_distinct_over_time(a, {a=~".+"}[5s]) by (b))`,
+			`max(count by (b) (count by (a, b) (count_over_time({a=~".+"}[5s]))))`,
+		},
+	} {
+		q := NewMockQuerier(shards, streams)
+		regular := NewEngine(EngineOpts{}, q, NoLimits, log.NewNopLogger())
+		sharded := NewShardedEngine(EngineOpts{}, MockDownstreamer{regular}, nilMetrics, NoLimits, log.NewNopLogger())
+
+		t.Run(tc.query, func(t *testing.T) {
+			params := NewLiteralParams(tc.query, start, end, step, 0, logproto.FORWARD, 100, nil)
+			ctx := user.InjectOrgID(context.Background(), "fake")
+
+			mapper, err := NewShardMapper(shards, nilMetrics)
+			require.NoError(t, err)
+			noop, mapped, err := mapper.Parse(tc.query)
+			require.NoError(t, err)
+			require.False(t, noop)
+
+			res, err := regular.Query(params).Exec(ctx)
+			require.NoError(t, err)
+			shardedRes, err := sharded.Query(params, mapped).Exec(ctx)
+			require.NoError(t, err)
+			// the merged sketches are the sketches of all the values.
+			approximatelyEquals(t, res.Data.(promql.Matrix), shardedRes.Data.(promql.Matrix))
+
+			exactParams := NewLiteralParams(tc.exact, start, end, step, 0, logproto.FORWARD, 100, nil)
+			exactRes, err := regular.Query(exactParams).Exec(ctx)
+			require.NoError(t, err)
+			expected, actual := exactRes.Data.(promql.Matrix), shardedRes.Data.(promql.Matrix)
+			require.Equal(t, len(expected), len(actual))
+			for i := range expected {
+				require.Equal(t, expected[i].Metric, actual[i].Metric)
+				require.Equal(t, len(expected[i].Points), len(actual[i].Points))
+				for j, p := range expected[i].Points {
+					require.InEpsilon(t, p.V, actual[i].Points[j].V, 0.05)
+				}
+			}
+		})
+	}
+}
+
+func TestApproxTopKMappingEquivalence(t *testing.T) {
+	var (
+		shards   = 3
+		nStreams = 60
+		rounds   = 20
+		streams  = randomStreams(nStreams, rounds+1, shards, []string{"a", "b", "c", "d"})
+		start    = time.Unix(0, 0)
+		end      = time.Unix(0, int64(time.Second*time.Duration(rounds)))
+		step     = time.Second
+	)
+
+	for _, tc := range []struct {
+		query string
+		sum   string
+		k     int
+	}{
+		{
+			`approx_topk(3, sum by (a, b) (count_over_time({a=~".+"}[5s])))`,
+			`sum by (a, b) (count_over_time({a=~".+"}[5s]))`,
+			3,
+		},
+		{
+			`approx_topk(2, sum by (c) (bytes_rate({a=~".+"} |= "number" [5s])))`,
+			`sum by (c) (bytes_rate({a=~".+"} |= "number" [5s]))`,
+			2,
+		},
+	} {
+		q := NewMockQuerier(shards, streams)
+		regular := NewEngine(EngineOpts{}, q, NoLimits, log.NewNopLogger())
+		sharded := NewShardedEngine(EngineOpts{}, MockDownstreamer{regular}, nilMetrics, NoLimits, log.NewNopLogger())
+
+		t.Run(tc.query, func(t *testing.T) {
+			ctx := user.InjectOrgID(context.Background(), "fake")
+
+			mapper, err := NewShardMapper(shards, nilMetrics)
+			require.NoError(t, err)
+			noop, mapped, err := mapper.Parse(tc.query)
+			require.NoError(t, err)
+			require.False(t, noop)
+
+			params := NewLiteralParams(tc.query, start, end, step, 0, logproto.FORWARD, 100, nil)
+			shardedRes, err := sharded.Query(params, mapped).Exec(ctx)
+			require.NoError(t, err)
+			sumParams := NewLiteralParams(tc.sum, start, end, step, 0, logproto.FORWARD, 100, nil)
+			sumRes, err := regular.Query(sumParams).Exec(ctx)
+			require.NoError(t, err)
+
+			// as the top k may have ties, the series returned at each step must have the top k values,
+			// and their values must be the exact sums given the small number of series.
+			exact := map[int64]map[uint64]float64{}
+			for _, series := range sumRes.Data.(promql.Matrix) {
+				for _, p := range series.Points {
+					if exact[p.T] == nil {
+						exact[p.T] = map[uint64]float64{}
+					}
+					exact[p.T][series.Metric.Hash()] = p.V
+				}
+			}
+			actual := map[int64][]float64{}
+			for _, series := range shardedRes.Data.(promql.Matrix) {
+				for _, p := range series.Points {
+					require.Equal(t, exact[p.T][series.Metric.Hash()], p.V)
+					actual[p.T] = append(actual[p.T], p.V)
+				}
+			}
+			require.Equal(t, len(exact), len(actual))
+			for ts, values := range exact {
+				expected := make([]float64, 0, len(values))
+				for _, v := range values {
+					expected = append(expected, v)
+				}
+				sort.Sort(sort.Reverse(sort.Float64Slice(expected)))
+				sort.Sort(sort.Reverse(sort.Float64Slice(actual[ts])))
+				require.Equal(t, expected[:tc.k], actual[ts])
+			}
+		})
+	}
+}
+
 // approximatelyEquals ensures two responses are approximately equal, up to 6 decimals precision per sample
 func approximatelyEquals(t *testing.T, as, bs promql.Matrix) {
 	require.Equal(t, len(as), len(bs))
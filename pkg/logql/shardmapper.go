@@ -95,6 +95,16 @@ func NewShardMapper(shards int, metrics *ShardingMetrics) (ShardMapper, error) {
 type ShardMapper struct {
 	shards  int
 	metrics *ShardingMetrics
+
+	// quantileSketchAccuracy is the relative accuracy of the sketches quantile_over_time is sharded with,
+	// quantile_over_time not being sharded when zero.
+	quantileSketchAccuracy float64
+}
+
+// WithQuantileSketches returns a copy of the mapper sharding quantile_over_time with sketches of the given relative accuracy.
+func (m ShardMapper) WithQuantileSketches(relativeAccuracy float64) ShardMapper {
+	m.quantileSketchAccuracy = relativeAccuracy
+	return m
 }
 
 func (m ShardMapper) Parse(query string) (noop bool, expr Expr, err error) {
@@ -137,6 +147,8 @@ func (m ShardMapper) Map(expr Expr, r *shardRecorder) (Expr, error) {
 		return m.mapLabelReplaceExpr(e, r)
 	case *RangeAggregationExpr:
 		return m.mapRangeAggregationExpr(e, r), nil
+	case *SubqueryAggregationExpr:
+		return m.mapSubqueryAggregationExpr(e, r)
 	case *BinOpExpr:
 		lhsMapped, err := m.Map(e.SampleExpr, r)
 		if err != nil {
@@ -203,6 +215,19 @@ func (m ShardMapper) mapSampleExpr(expr SampleExpr, r *shardRecorder) SampleExpr
 // technically, std{dev,var} are also parallelizable if there is no cross-shard merging
 // in descendent nodes in the AST. This optimization is currently avoided for simplicity.
 func (m ShardMapper) mapVectorAggregationExpr(expr *VectorAggregationExpr, r *shardRecorder) (SampleExpr, error) {
+	if expr.Operation == OpTypeApproxTopK && canShardApproxTopK(expr.Left) {
+		// approx_topk(k, sum by (x) (y)) -> approx_topk_merge<k, approx_topk_sketch(k, sum by (x) (y), shard=1) ++ approx_topk_sketch(k, sum by (x) (y), shard=2)...>
+		return &ApproxTopKMergeExpr{
+			Left: m.mapSampleExpr(&VectorAggregationExpr{
+				Left:      expr.Left,
+				Grouping:  &Grouping{},
+				Params:    expr.Params,
+				Operation: OpTypeApproxTopKSketch,
+			}, r),
+			K: expr.Params,
+		}, nil
+	}
+
 	// if this AST contains unshardable operations, don't shard this at this level,
 	// but attempt to shard a child node.
 	if !expr.Shardable() {
@@ -288,7 +313,52 @@ func (m ShardMapper) mapLabelReplaceExpr(expr *LabelReplaceExpr, r *shardRecorde
 	return &cpy, nil
 }
 
+// mapSubqueryAggregationExpr shards the query of the subquery, the aggregation over its range
+// being done on the merged results of the shards.
+func (m ShardMapper) mapSubqueryAggregationExpr(expr *SubqueryAggregationExpr, r *shardRecorder) (SampleExpr, error) {
+	subMapped, err := m.Map(expr.Left.Left, r)
+	if err != nil {
+		return nil, err
+	}
+	sampleExpr, ok := subMapped.(SampleExpr)
+	if !ok {
+		return nil, badASTMapping("SampleExpr", subMapped)
+	}
+	sub := *expr.Left
+	sub.Left = sampleExpr
+	cpy := *expr
+	cpy.Left = &sub
+	return &cpy, nil
+}
+
 func (m ShardMapper) mapRangeAggregationExpr(expr *RangeAggregationExpr, r *shardRecorder) SampleExpr {
+	if expr.Operation == OpRangeTypeQuantile && m.quantileSketchAccuracy > 0 {
+		// quantile_over_time(φ, x) -> quantile_sketch_merge<φ, quantile_sketch_over_time(x, shard=1) ++ quantile_sketch_over_time(x, shard=2)...>
+		// the sketches of a series are merged whatever the shards they come from, so labels can be modified.
+		accuracy := m.quantileSketchAccuracy
+		return &QuantileSketchMergeExpr{
+			Left: m.mapSampleExpr(&RangeAggregationExpr{
+				Left:      expr.Left,
+				Operation: OpRangeTypeQuantileSketch,
+				Params:    &accuracy,
+				Grouping:  expr.Grouping,
+			}, r),
+			Quantile:         *expr.Params,
+			RelativeAccuracy: accuracy,
+		}
+	}
+	if expr.Operation == OpRangeTypeApproxCountDistinct {
+		// approx_count_distinct_over_time(l, x) -> count_distinct_merge<approx_count_distinct_sketch_over_time(l, x, shard=1) ++ approx_count_distinct_sketch_over_time(l, x, shard=2)...>
+		// the sketches of a series are merged whatever the shards they come from, so labels can be modified.
+		return &CountDistinctMergeExpr{
+			Left: m.mapSampleExpr(&RangeAggregationExpr{
+				Left:      expr.Left,
+				Operation: OpRangeTypeApproxCountDistinctSketch,
+				Label:     expr.Label,
+				Grouping:  expr.Grouping,
+			}, r),
+		}
+	}
 	if hasLabelModifier(expr) {
 		// if an expr can modify labels this means multiple shards can returns the same labelset.
 		// When this happens the merge strategy needs to be different than a simple concatenation.
@@ -307,6 +377,25 @@ func (m ShardMapper) mapRangeAggregationExpr(expr *RangeAggregationExpr, r *shar
 	}
 }
 
+// canShardApproxTopK tells if the count-min sketches of approx_topk can be merged across shards,
+// which requires the sum of positive values of log lines: the sum of the shards is the sum of all the log lines.
+func canShardApproxTopK(expr SampleExpr) bool {
+	sum, ok := expr.(*VectorAggregationExpr)
+	if !ok || sum.Operation != OpTypeSum {
+		return false
+	}
+	rangeExpr, ok := sum.Left.(*RangeAggregationExpr)
+	if !ok || rangeExpr.Left.Unwrap != nil || !rangeExpr.Shardable() {
+		return false
+	}
+	switch rangeExpr.Operation {
+	case OpRangeTypeCount, OpRangeTypeRate, OpRangeTypeBytes, OpRangeTypeBytesRate:
+		return true
+	default:
+		return false
+	}
+}
+
 // hasLabelModifier tells if an expression contains pipelines that can modify stream labels
 // parsers introduce new labels but does not alter original one for instance.
 func hasLabelModifier(expr *RangeAggregationExpr) bool {
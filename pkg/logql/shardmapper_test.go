@@ -201,6 +201,27 @@ func TestMappingStrings(t *testing.T) {
 			in:  `sum by (cluster) (sum by (cluster) (rate({foo="bar"} [5m])) + ignoring(machine) sum by (cluster,machine) (rate({foo="bar"} [5m])))`,
 			out: `sumby(cluster)((sumby(cluster)(downstream<sumby(cluster)(rate({foo="bar"}[5m])),shard=0_of_2>++downstream<sumby(cluster)(rate({foo="bar"}[5m])),shard=1_of_2>)+ignoring(machine)sumby(cluster,machine)(downstream<sumby(cluster,machine)(rate({foo="bar"}[5m])),shard=0_of_2>++downstream<sumby(cluster,machine)(rate({foo="bar"}[5m])),shard=1_of_2>)))`,
 		},
+		{
+			in:  `max_over_time(sum(rate({foo="bar"}[5m]))[1h:5m])`,
+			out: `max_over_time(sum(downstream<sum(rate({foo="bar"}[5m])),shard=0_of_2>++downstream<sum(rate({foo="bar"}[5m])),shard=1_of_2>)[1h:5m])`,
+		},
+		{
+			in:  `sum(max_over_time(rate({foo="bar"}[5m])[1h:]))`,
+			out: `sum(max_over_time(downstream<rate({foo="bar"}[5m]),shard=0_of_2>++downstream<rate({foo="bar"}[5m]),shard=1_of_2>[1h:]))`,
+		},
+		{
+			in:  `approx_count_distinct_over_time(user, {foo="bar"} | json [5m]) by (path)`,
+			out: `count_distinct_merge<downstream<approx_count_distinct_sketch_over_time(user,{foo="bar"}|json[5m])by(path),shard=0_of_2>++downstream<approx_count_distinct_sketch_over_time(user,{foo="bar"}|json[5m])by(path),shard=1_of_2>>`,
+		},
+		{
+			in:  `approx_topk(10, sum by (ip) (count_over_time({foo="bar"} | logfmt [5m])))`,
+			out: `approx_topk_merge<10,downstream<approx_topk_sketch(10,sumby(ip)(count_over_time({foo="bar"}|logfmt[5m]))),shard=0_of_2>++downstream<approx_topk_sketch(10,sumby(ip)(count_over_time({foo="bar"}|logfmt[5m]))),shard=1_of_2>>`,
+		},
+		{
+			// only sums of log lines can be merged with count-min sketches.
+			in:  `approx_topk(10, sum by (ip) (sum_over_time({foo="bar"} | logfmt | unwrap bytes [5m])))`,
+			out: `approx_topk(10,sumby(ip)(downstream<sumby(ip)(sum_over_time({foo="bar"}|logfmt|unwrapbytes[5m])),shard=0_of_2>++downstream<sumby(ip)(sum_over_time({foo="bar"}|logfmt|unwrapbytes[5m])),shard=1_of_2>))`,
+		},
 	} {
 		t.Run(tc.in, func(t *testing.T) {
 			ast, err := ParseExpr(tc.in)
@@ -214,6 +235,45 @@ func TestMappingStrings(t *testing.T) {
 	}
 }
 
+func TestMappingStrings_QuantileSketches(t *testing.T) {
+	m, err := NewShardMapper(2, nilMetrics)
+	require.Nil(t, err)
+
+	// quantile_over_time isn't sharded without sketches.
+	noop, _, err := m.Parse(`quantile_over_time(0.99, {foo="bar"} | unwrap latency [5m])`)
+	require.Nil(t, err)
+	require.True(t, noop)
+
+	m = m.WithQuantileSketches(0.01)
+	for _, tc := range []struct {
+		in  string
+		out string
+	}{
+		{
+			in:  `quantile_over_time(0.99, {foo="bar"} | unwrap latency [5m])`,
+			out: `quantile_sketch_merge<0.99, downstream<quantile_sketch_over_time(0.01,{foo="bar"} | unwrap latency[5m]), shard=0_of_2> ++ downstream<quantile_sketch_over_time(0.01,{foo="bar"} | unwrap latency[5m]), shard=1_of_2>>`,
+		},
+		{
+			in:  `max(quantile_over_time(0.5, {foo="bar"} | label_format foo=bar | unwrap latency [5m]) by (foo))`,
+			out: `max(quantile_sketch_merge<0.5, downstream<quantile_sketch_over_time(0.01,{foo="bar"} | label_format foo=bar | unwrap latency[5m]) by (foo), shard=0_of_2> ++ downstream<quantile_sketch_over_time(0.01,{foo="bar"} | label_format foo=bar | unwrap latency[5m]) by (foo), shard=1_of_2>>)`,
+		},
+	} {
+		t.Run(tc.in, func(t *testing.T) {
+			_, mapped, err := m.Parse(tc.in)
+			require.Nil(t, err)
+			require.Equal(t, strings.ReplaceAll(tc.out, " ", ""), strings.ReplaceAll(mapped.String(), " ", ""))
+
+			// the downstream queries are parsed by the queriers.
+			mapped.Walk(func(e interface{}) {
+				if d, ok := e.(DownstreamSampleExpr); ok {
+					_, err := ParseSampleExpr(d.SampleExpr.String())
+					require.Nil(t, err)
+				}
+			})
+		})
+	}
+}
+
 func TestMapping(t *testing.T) {
 	m, err := NewShardMapper(2, nilMetrics)
 	require.Nil(t, err)
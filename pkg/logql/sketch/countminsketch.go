@@ -0,0 +1,101 @@
+package sketch
+
+import (
+	"fmt"
+	"math"
+)
+
+// The dimensions of the count-min sketches of approx_topk: the counts are overestimated by at most
+// e/CountMinSketchWidth ≈ 0.5% of the total count, with a probability of 1-e^-CountMinSketchDepth ≈ 98%.
+const (
+	CountMinSketchDepth = 4
+	CountMinSketchWidth = 512
+)
+
+// CountMinSketch estimates the sum of the values added for each key, without keeping the keys.
+//
+// Each row of the sketch has its own hash of the keys, selecting the counter of the row the values are added to.
+// As keys share counters, the sum of a key is overestimated and the lowest counter of the key is the best estimate.
+// Sketches of the same dimensions are merged by adding their counters. The values must not be negative.
+// See http://dimacs.rutgers.edu/~graham/pubs/papers/cm-full.pdf.
+type CountMinSketch struct {
+	depth, width int
+	counters     [][]float64
+}
+
+// NewCountMinSketch creates an empty CountMinSketch of the given dimensions.
+func NewCountMinSketch(depth, width int) (*CountMinSketch, error) {
+	if depth <= 0 || width <= 0 {
+		return nil, fmt.Errorf("invalid count-min sketch dimensions %dx%d", depth, width)
+	}
+	counters := make([][]float64, depth)
+	for i := range counters {
+		counters[i] = make([]float64, width)
+	}
+	return &CountMinSketch{
+		depth:    depth,
+		width:    width,
+		counters: counters,
+	}, nil
+}
+
+// Add adds a value to the sum of the key of the given hash, e.g. the hash of a series labels.
+func (s *CountMinSketch) Add(hash uint64, v float64) {
+	for row := range s.counters {
+		s.counters[row][s.column(hash, row)] += v
+	}
+}
+
+// Count returns the estimated sum of the key of the given hash.
+func (s *CountMinSketch) Count(hash uint64) float64 {
+	count := math.Inf(1)
+	for row := range s.counters {
+		count = math.Min(count, s.counters[row][s.column(hash, row)])
+	}
+	return count
+}
+
+// Merge adds the values of another sketch to the sketch.
+func (s *CountMinSketch) Merge(o *CountMinSketch) error {
+	if s.depth != o.depth || s.width != o.width {
+		return fmt.Errorf("can't merge sketches of different dimensions: %dx%d and %dx%d", s.depth, s.width, o.depth, o.width)
+	}
+	for row := range o.counters {
+		for column, c := range o.counters[row] {
+			s.counters[row][column] += c
+		}
+	}
+	return nil
+}
+
+// ForEach calls f with the position and the value of each non zero counter of the sketch.
+// A sketch is rebuilt from them with AddCounter.
+func (s *CountMinSketch) ForEach(f func(row, column int, count float64)) {
+	for row := range s.counters {
+		for column, c := range s.counters[row] {
+			if c != 0 {
+				f(row, column, c)
+			}
+		}
+	}
+}
+
+// AddCounter adds count to a counter of the sketch, as returned by ForEach.
+func (s *CountMinSketch) AddCounter(row, column int, count float64) error {
+	if row < 0 || row >= s.depth || column < 0 || column >= s.width {
+		return fmt.Errorf("invalid sketch counter %d:%d", row, column)
+	}
+	s.counters[row][column] += count
+	return nil
+}
+
+// column derives the hash of each row by mixing the key hash with the row, as with the finalizer of MurmurHash3.
+func (s *CountMinSketch) column(hash uint64, row int) int {
+	h := hash + uint64(row)*0x9e3779b97f4a7c15
+	h ^= h >> 33
+	h *= 0xff51afd7ed558ccd
+	h ^= h >> 33
+	h *= 0xc4ceb9fe1a85ec53
+	h ^= h >> 33
+	return int(h % uint64(s.width))
+}
@@ -0,0 +1,55 @@
+package sketch
+
+import (
+	"math/rand"
+	"strconv"
+	"testing"
+
+	"github.com/cespare/xxhash/v2"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCountMinSketch(t *testing.T) {
+	r := rand.New(rand.NewSource(42))
+	s, err := NewCountMinSketch(CountMinSketchDepth, CountMinSketchWidth)
+	require.NoError(t, err)
+	merged, err := NewCountMinSketch(CountMinSketchDepth, CountMinSketchWidth)
+	require.NoError(t, err)
+
+	// a few heavy hitters among many small keys.
+	exact := map[string]float64{}
+	var total float64
+	for shard := 0; shard < 3; shard++ {
+		shardSketch, err := NewCountMinSketch(CountMinSketchDepth, CountMinSketchWidth)
+		require.NoError(t, err)
+		for i := 0; i < 5000; i++ {
+			key := strconv.Itoa(r.Intn(2000))
+			v := 1.
+			if i%10 == 0 {
+				key = "heavy-" + strconv.Itoa(i%50)
+				v = 10
+			}
+			exact[key] += v
+			total += v
+			s.Add(xxhash.Sum64String(key), v)
+			shardSketch.Add(xxhash.Sum64String(key), v)
+		}
+		shardSketch.ForEach(func(row, column int, count float64) {
+			require.NoError(t, merged.AddCounter(row, column, count))
+		})
+	}
+	require.Equal(t, s, merged)
+
+	for key, count := range exact {
+		estimate := s.Count(xxhash.Sum64String(key))
+		require.GreaterOrEqual(t, estimate, count, key)
+		require.LessOrEqual(t, estimate-count, 0.01*total, key)
+	}
+
+	other, err := NewCountMinSketch(CountMinSketchDepth, 10)
+	require.NoError(t, err)
+	require.Error(t, merged.Merge(other))
+	require.Error(t, merged.AddCounter(CountMinSketchDepth, 0, 1))
+	_, err = NewCountMinSketch(0, 1)
+	require.Error(t, err)
+}
@@ -0,0 +1,194 @@
+package sketch
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+)
+
+// DDSketch is a quantile sketch with a relative accuracy guarantee: the quantiles it returns are
+// within the given relative error of the exact ones, whatever the distribution of the values.
+//
+// The values are counted in buckets with exponentially growing boundaries, the bucket of a value v being
+// ceil(log_gamma(|v|)) with gamma = (1+accuracy)/(1-accuracy). Sketches built with the same relative
+// accuracy are merged by adding the counts of their buckets.
+// See https://www.vldb.org/pvldb/vol12/p2195-masson.pdf.
+type DDSketch struct {
+	relativeAccuracy float64
+	gamma            float64
+	logGamma         float64
+
+	positive map[int]float64
+	negative map[int]float64
+	zero     float64
+	count    float64
+}
+
+// NewDDSketch creates an empty DDSketch with the given relative accuracy, which must be within (0, 1).
+func NewDDSketch(relativeAccuracy float64) (*DDSketch, error) {
+	if relativeAccuracy <= 0 || relativeAccuracy >= 1 {
+		return nil, fmt.Errorf("relative accuracy must be between 0 and 1, got %v", relativeAccuracy)
+	}
+	gamma := (1 + relativeAccuracy) / (1 - relativeAccuracy)
+	return &DDSketch{
+		relativeAccuracy: relativeAccuracy,
+		gamma:            gamma,
+		logGamma:         math.Log(gamma),
+		positive:         map[int]float64{},
+		negative:         map[int]float64{},
+	}, nil
+}
+
+// RelativeAccuracy returns the relative accuracy of the sketch.
+func (s *DDSketch) RelativeAccuracy() float64 {
+	return s.relativeAccuracy
+}
+
+// Count returns the number of values added to the sketch.
+func (s *DDSketch) Count() float64 {
+	return s.count
+}
+
+// Add adds a value to the sketch. NaN and infinite values can't be bucketed and are ignored.
+func (s *DDSketch) Add(v float64) {
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		return
+	}
+	switch {
+	case v > 0:
+		s.positive[s.index(v)]++
+	case v < 0:
+		s.negative[s.index(-v)]++
+	default:
+		s.zero++
+	}
+	s.count++
+}
+
+// Merge adds the values of another sketch to the sketch.
+func (s *DDSketch) Merge(o *DDSketch) error {
+	if s.relativeAccuracy != o.relativeAccuracy {
+		return fmt.Errorf("can't merge sketches of different relative accuracies: %v and %v", s.relativeAccuracy, o.relativeAccuracy)
+	}
+	for i, c := range o.positive {
+		s.positive[i] += c
+	}
+	for i, c := range o.negative {
+		s.negative[i] += c
+	}
+	s.zero += o.zero
+	s.count += o.count
+	return nil
+}
+
+// Quantile returns the φ-quantile (0 ≤ φ ≤ 1) of the values of the sketch.
+// As for quantile_over_time, the quantiles between two values are interpolated, and NaN is returned
+// for empty sketches, -Inf for φ < 0 and +Inf for φ > 1.
+func (s *DDSketch) Quantile(q float64) float64 {
+	if s.count == 0 {
+		return math.NaN()
+	}
+	if q < 0 {
+		return math.Inf(-1)
+	}
+	if q > 1 {
+		return math.Inf(+1)
+	}
+
+	buckets := s.sortedBuckets()
+	rank := q * (s.count - 1)
+	lowerRank := math.Max(0, math.Floor(rank))
+	upperRank := math.Min(s.count-1, lowerRank+1)
+	weight := rank - math.Floor(rank)
+	return valueAt(buckets, lowerRank)*(1-weight) + valueAt(buckets, upperRank)*weight
+}
+
+type bucket struct {
+	value, count float64
+}
+
+// sortedBuckets returns the value and the count of the buckets, ordered by value.
+func (s *DDSketch) sortedBuckets() []bucket {
+	buckets := make([]bucket, 0, len(s.negative)+len(s.positive)+1)
+	negative := sortedKeys(s.negative)
+	for i := len(negative) - 1; i >= 0; i-- {
+		buckets = append(buckets, bucket{value: -s.value(negative[i]), count: s.negative[negative[i]]})
+	}
+	if s.zero > 0 {
+		buckets = append(buckets, bucket{count: s.zero})
+	}
+	for _, i := range sortedKeys(s.positive) {
+		buckets = append(buckets, bucket{value: s.value(i), count: s.positive[i]})
+	}
+	return buckets
+}
+
+// valueAt returns the value of the given rank, the smallest value having the rank 0.
+func valueAt(buckets []bucket, rank float64) float64 {
+	var n float64
+	for _, b := range buckets {
+		n += b.count
+		if n > rank {
+			return b.value
+		}
+	}
+	return buckets[len(buckets)-1].value
+}
+
+// ForEach calls f with the key and the count of each non empty bucket of the sketch.
+// The keys identify the buckets across the sketches of the same relative accuracy,
+// a sketch being rebuilt from them with AddBucket.
+func (s *DDSketch) ForEach(f func(key string, count float64)) {
+	for i, c := range s.positive {
+		f("+"+strconv.Itoa(i), c)
+	}
+	for i, c := range s.negative {
+		f("-"+strconv.Itoa(i), c)
+	}
+	if s.zero > 0 {
+		f("0", s.zero)
+	}
+}
+
+// AddBucket adds count values to the bucket of the given key, as returned by ForEach.
+func (s *DDSketch) AddBucket(key string, count float64) error {
+	if key == "0" {
+		s.zero += count
+		s.count += count
+		return nil
+	}
+	if len(key) < 2 || (key[0] != '+' && key[0] != '-') {
+		return fmt.Errorf("invalid sketch bucket %q", key)
+	}
+	i, err := strconv.Atoi(key[1:])
+	if err != nil {
+		return fmt.Errorf("invalid sketch bucket %q: %w", key, err)
+	}
+	if key[0] == '+' {
+		s.positive[i] += count
+	} else {
+		s.negative[i] += count
+	}
+	s.count += count
+	return nil
+}
+
+// index returns the bucket of a positive value.
+func (s *DDSketch) index(v float64) int {
+	return int(math.Ceil(math.Log(v) / s.logGamma))
+}
+
+// value returns the value representing a bucket, which is within the relative accuracy of all the values of the bucket.
+func (s *DDSketch) value(i int) float64 {
+	return 2 * math.Pow(s.gamma, float64(i)) / (s.gamma + 1)
+}
+
+func sortedKeys(m map[int]float64) []int {
+	keys := make([]int, 0, len(m))
+	for i := range m {
+		keys = append(keys, i)
+	}
+	sort.Ints(keys)
+	return keys
+}
@@ -0,0 +1,100 @@
+package sketch
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func exactQuantile(q float64, values []float64) float64 {
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	rank := q * float64(len(sorted)-1)
+	lower := math.Floor(rank)
+	upper := math.Min(float64(len(sorted)-1), lower+1)
+	weight := rank - lower
+	return sorted[int(lower)]*(1-weight) + sorted[int(upper)]*weight
+}
+
+func TestDDSketch_Quantile(t *testing.T) {
+	r := rand.New(rand.NewSource(42))
+	for name, gen := range map[string]func() float64{
+		"uniform":     func() float64 { return r.Float64() * 100 },
+		"exponential": func() float64 { return r.ExpFloat64() },
+		"normal":      func() float64 { return r.NormFloat64() * 10 },
+		"lognormal":   func() float64 { return math.Exp(r.NormFloat64() * 3) },
+	} {
+		t.Run(name, func(t *testing.T) {
+			const accuracy = 0.01
+			s, err := NewDDSketch(accuracy)
+			require.NoError(t, err)
+
+			values := make([]float64, 10000)
+			for i := range values {
+				values[i] = gen()
+				s.Add(values[i])
+			}
+			require.Equal(t, float64(len(values)), s.Count())
+
+			for _, q := range []float64{0, 0.1, 0.5, 0.9, 0.99, 0.999, 1} {
+				exact := exactQuantile(q, values)
+				require.InEpsilon(t, exact, s.Quantile(q), accuracy, "quantile %v", q)
+			}
+		})
+	}
+}
+
+func TestDDSketch_Edges(t *testing.T) {
+	s, err := NewDDSketch(0.01)
+	require.NoError(t, err)
+	require.True(t, math.IsNaN(s.Quantile(0.5)))
+
+	s.Add(0)
+	s.Add(math.NaN())
+	s.Add(math.Inf(1))
+	require.Equal(t, float64(1), s.Count())
+	require.Equal(t, float64(0), s.Quantile(0.5))
+	require.Equal(t, math.Inf(-1), s.Quantile(-1))
+	require.Equal(t, math.Inf(1), s.Quantile(2))
+
+	_, err = NewDDSketch(0)
+	require.Error(t, err)
+	_, err = NewDDSketch(1)
+	require.Error(t, err)
+}
+
+func TestDDSketch_Merge(t *testing.T) {
+	r := rand.New(rand.NewSource(42))
+	all, err := NewDDSketch(0.02)
+	require.NoError(t, err)
+	merged, err := NewDDSketch(0.02)
+	require.NoError(t, err)
+
+	// the shards are merged through their buckets.
+	for shard := 0; shard < 4; shard++ {
+		s, err := NewDDSketch(0.02)
+		require.NoError(t, err)
+		for i := 0; i < 1000; i++ {
+			v := r.NormFloat64() * 100
+			s.Add(v)
+			all.Add(v)
+		}
+		s.ForEach(func(key string, count float64) {
+			require.NoError(t, merged.AddBucket(key, count))
+		})
+	}
+	require.Equal(t, all, merged)
+
+	other, err := NewDDSketch(0.01)
+	require.NoError(t, err)
+	require.Error(t, merged.Merge(other))
+	require.Error(t, merged.AddBucket("x1", 1))
+
+	merged2, err := NewDDSketch(0.02)
+	require.NoError(t, err)
+	require.NoError(t, merged2.Merge(all))
+	require.Equal(t, all.Quantile(0.9), merged2.Quantile(0.9))
+}
@@ -0,0 +1,114 @@
+package sketch
+
+import (
+	"fmt"
+	"math"
+	"math/bits"
+
+	"github.com/cespare/xxhash/v2"
+)
+
+// HyperLogLogPrecision is the precision of the HyperLogLog sketches counting distinct values:
+// they have 2^12 registers, for a standard error of 1.04/sqrt(2^12) ≈ 1.6%.
+const HyperLogLogPrecision = 12
+
+// HyperLogLog estimates the number of distinct values added to it.
+//
+// The hash of each value selects a register with its first bits, the register keeping the highest rank
+// of the first set bit in the remaining ones. Sketches of the same precision are merged by keeping the
+// highest rank of each register.
+// See http://algo.inria.fr/flajolet/Publications/FlFuGaMe07.pdf.
+type HyperLogLog struct {
+	precision uint8
+	registers []uint8
+}
+
+// NewHyperLogLog creates an empty HyperLogLog sketch with 2^precision registers, the precision being within [4, 18].
+func NewHyperLogLog(precision uint8) (*HyperLogLog, error) {
+	if precision < 4 || precision > 18 {
+		return nil, fmt.Errorf("precision must be between 4 and 18, got %d", precision)
+	}
+	return &HyperLogLog{
+		precision: precision,
+		registers: make([]uint8, 1<<precision),
+	}, nil
+}
+
+// Add adds a value to the sketch.
+func (h *HyperLogLog) Add(v string) {
+	hash := xxhash.Sum64String(v)
+	i := hash >> (64 - h.precision)
+	// the remaining bits are shifted in, the rank being at most 64-precision+1.
+	rank := uint8(bits.LeadingZeros64(hash<<h.precision|1<<(h.precision-1))) + 1
+	if rank > h.registers[i] {
+		h.registers[i] = rank
+	}
+}
+
+// Merge adds the values of another sketch to the sketch.
+func (h *HyperLogLog) Merge(o *HyperLogLog) error {
+	if h.precision != o.precision {
+		return fmt.Errorf("can't merge sketches of different precisions: %d and %d", h.precision, o.precision)
+	}
+	for i, rank := range o.registers {
+		if rank > h.registers[i] {
+			h.registers[i] = rank
+		}
+	}
+	return nil
+}
+
+// Count returns the estimated number of distinct values added to the sketch.
+func (h *HyperLogLog) Count() float64 {
+	m := float64(len(h.registers))
+	var (
+		sum   float64
+		zeros float64
+	)
+	for _, rank := range h.registers {
+		sum += math.Ldexp(1, -int(rank))
+		if rank == 0 {
+			zeros++
+		}
+	}
+	estimate := alpha(m) * m * m / sum
+	// small cardinalities are better estimated with the number of empty registers.
+	if estimate <= 2.5*m && zeros > 0 {
+		return m * math.Log(m/zeros)
+	}
+	return estimate
+}
+
+func alpha(m float64) float64 {
+	switch m {
+	case 16:
+		return 0.673
+	case 32:
+		return 0.697
+	case 64:
+		return 0.709
+	default:
+		return 0.7213 / (1 + 1.079/m)
+	}
+}
+
+// ForEach calls f with the index and the rank of each non empty register of the sketch.
+// A sketch is rebuilt from them with SetRegister.
+func (h *HyperLogLog) ForEach(f func(register int, rank uint8)) {
+	for i, rank := range h.registers {
+		if rank > 0 {
+			f(i, rank)
+		}
+	}
+}
+
+// SetRegister merges the rank of a register, as returned by ForEach, into the sketch.
+func (h *HyperLogLog) SetRegister(register int, rank uint8) error {
+	if register < 0 || register >= len(h.registers) {
+		return fmt.Errorf("invalid sketch register %d", register)
+	}
+	if rank > h.registers[register] {
+		h.registers[register] = rank
+	}
+	return nil
+}
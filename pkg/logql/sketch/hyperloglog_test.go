@@ -0,0 +1,65 @@
+package sketch
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestHyperLogLog_Count(t *testing.T) {
+	for _, n := range []int{0, 1, 10, 100, 1000, 10000, 100000} {
+		t.Run(strconv.Itoa(n), func(t *testing.T) {
+			h, err := NewHyperLogLog(HyperLogLogPrecision)
+			require.NoError(t, err)
+			for i := 0; i < n; i++ {
+				// duplicates are not counted.
+				h.Add("user-" + strconv.Itoa(i))
+				h.Add("user-" + strconv.Itoa(i))
+			}
+			if n == 0 {
+				require.Equal(t, float64(0), h.Count())
+				return
+			}
+			// 3 standard errors.
+			require.InEpsilon(t, float64(n), h.Count(), 0.05)
+		})
+	}
+
+	_, err := NewHyperLogLog(3)
+	require.Error(t, err)
+	_, err = NewHyperLogLog(19)
+	require.Error(t, err)
+}
+
+func TestHyperLogLog_Merge(t *testing.T) {
+	all, err := NewHyperLogLog(HyperLogLogPrecision)
+	require.NoError(t, err)
+	merged, err := NewHyperLogLog(HyperLogLogPrecision)
+	require.NoError(t, err)
+
+	// the shards share some of their values.
+	for shard := 0; shard < 4; shard++ {
+		h, err := NewHyperLogLog(HyperLogLogPrecision)
+		require.NoError(t, err)
+		for i := shard * 500; i < shard*500+1000; i++ {
+			h.Add(strconv.Itoa(i))
+			all.Add(strconv.Itoa(i))
+		}
+		h.ForEach(func(register int, rank uint8) {
+			require.NoError(t, merged.SetRegister(register, rank))
+		})
+	}
+	require.Equal(t, all, merged)
+	require.InEpsilon(t, 2500, merged.Count(), 0.05)
+
+	merged2, err := NewHyperLogLog(HyperLogLogPrecision)
+	require.NoError(t, err)
+	require.NoError(t, merged2.Merge(all))
+	require.Equal(t, all.Count(), merged2.Count())
+
+	other, err := NewHyperLogLog(10)
+	require.NoError(t, err)
+	require.Error(t, merged.Merge(other))
+	require.Error(t, merged.SetRegister(1<<HyperLogLogPrecision, 1))
+}
@@ -99,7 +99,7 @@ func processStream(in []logproto.Stream, pipeline log.Pipeline) []logproto.Strea
 	for _, stream := range in {
 		for _, e := range stream.Entries {
 			sp := pipeline.ForStream(mustParseLabels(stream.Labels))
-			if l, out, ok := sp.Process([]byte(e.Line)); ok {
+			if l, out, ok := sp.Process(e.Timestamp.UnixNano(), []byte(e.Line), e.StructuredMetadata...); ok {
 				var s *logproto.Stream
 				var found bool
 				s, found = resByStream[out.String()]
@@ -108,8 +108,9 @@ func processStream(in []logproto.Stream, pipeline log.Pipeline) []logproto.Strea
 					resByStream[out.String()] = s
 				}
 				s.Entries = append(s.Entries, logproto.Entry{
-					Timestamp: e.Timestamp,
-					Line:      string(l),
+					Timestamp:          e.Timestamp,
+					Line:               string(l),
+					StructuredMetadata: e.StructuredMetadata,
 				})
 			}
 		}
@@ -127,7 +128,7 @@ func processSeries(in []logproto.Stream, ex log.SampleExtractor) []logproto.Seri
 	for _, stream := range in {
 		for _, e := range stream.Entries {
 			exs := ex.ForStream(mustParseLabels(stream.Labels))
-			if f, lbs, ok := exs.Process([]byte(e.Line)); ok {
+			if f, lbs, ok := exs.Process(e.Timestamp.UnixNano(), []byte(e.Line), e.StructuredMetadata...); ok {
 				var s *logproto.Series
 				var found bool
 				s, found = resBySeries[lbs.String()]
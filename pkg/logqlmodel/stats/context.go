@@ -22,6 +22,7 @@ package stats
 
 import (
 	"context"
+	"errors"
 	"sync"
 	"sync/atomic" //lint:ignore faillint we can't use go.uber.org/atomic with a protobuf struct without wrapping it.
 	"time"
@@ -39,6 +40,9 @@ const (
 	statsKey ctxKeyType = "stats"
 )
 
+// ErrBytesLimit is returned by BytesLimitErr once more bytes were processed than allowed.
+var ErrBytesLimit = errors.New("the query processed more bytes than allowed")
+
 // Context is the statistics context. It is passed through the query path and accumulates statistics.
 type Context struct {
 	querier  Querier
@@ -49,6 +53,11 @@ type Context struct {
 	// result accumulates results for JoinResult.
 	result Result
 
+	// processedBytes is the total of bytes processed, it is not cleared by Reset.
+	processedBytes int64
+	// bytesLimit is the maximum of processed bytes, 0 means unlimited.
+	bytesLimit int64
+
 	mtx sync.Mutex
 }
 
@@ -90,6 +99,31 @@ func (c *Context) Reset() {
 	c.result.Reset()
 }
 
+// SetBytesLimit limits the number of bytes the query may process, 0 disables the limit.
+// Iterators processing data check it using BytesLimitErr.
+func (c *Context) SetBytesLimit(limit int64) {
+	atomic.StoreInt64(&c.bytesLimit, limit)
+}
+
+// BytesLimit returns the maximum number of bytes the query may process.
+func (c *Context) BytesLimit() int64 {
+	return atomic.LoadInt64(&c.bytesLimit)
+}
+
+// ProcessedBytes returns the number of bytes processed since the context was created.
+func (c *Context) ProcessedBytes() int64 {
+	return atomic.LoadInt64(&c.processedBytes)
+}
+
+// BytesLimitErr returns ErrBytesLimit if the query processed more bytes than its limit.
+func (c *Context) BytesLimitErr() error {
+	limit := c.BytesLimit()
+	if limit > 0 && c.ProcessedBytes() > limit {
+		return ErrBytesLimit
+	}
+	return nil
+}
+
 // Result calculates the summary based on store and ingester data.
 func (c *Context) Result(execTime time.Duration, queueTime time.Duration) Result {
 	r := c.result
@@ -148,6 +182,8 @@ func (s *Store) Merge(m Store) {
 	s.TotalChunksRef += m.TotalChunksRef
 	s.TotalChunksDownloaded += m.TotalChunksDownloaded
 	s.ChunksDownloadTime += m.ChunksDownloadTime
+	s.TotalBloomFiltersDownloaded += m.TotalBloomFiltersDownloaded
+	s.TotalChunksSkippedByBloom += m.TotalChunksSkippedByBloom
 	s.Chunk.HeadChunkBytes += m.Chunk.HeadChunkBytes
 	s.Chunk.HeadChunkLines += m.Chunk.HeadChunkLines
 	s.Chunk.DecompressedBytes += m.Chunk.DecompressedBytes
@@ -193,6 +229,10 @@ func (r Result) TotalChunksDownloaded() int64 {
 	return r.Querier.Store.TotalChunksDownloaded + r.Ingester.Store.TotalChunksDownloaded
 }
 
+func (r Result) TotalChunksSkippedByBloom() int64 {
+	return r.Querier.Store.TotalChunksSkippedByBloom + r.Ingester.Store.TotalChunksSkippedByBloom
+}
+
 func (r Result) TotalChunksRef() int64 {
 	return r.Querier.Store.TotalChunksRef + r.Ingester.Store.TotalChunksRef
 }
@@ -224,6 +264,7 @@ func (c *Context) AddHeadChunkLines(i int64) {
 
 func (c *Context) AddHeadChunkBytes(i int64) {
 	atomic.AddInt64(&c.store.Chunk.HeadChunkBytes, i)
+	atomic.AddInt64(&c.processedBytes, i)
 }
 
 func (c *Context) AddCompressedBytes(i int64) {
@@ -232,6 +273,7 @@ func (c *Context) AddCompressedBytes(i int64) {
 
 func (c *Context) AddDecompressedBytes(i int64) {
 	atomic.AddInt64(&c.store.Chunk.DecompressedBytes, i)
+	atomic.AddInt64(&c.processedBytes, i)
 }
 
 func (c *Context) AddDecompressedLines(i int64) {
@@ -250,6 +292,14 @@ func (c *Context) AddChunksDownloaded(i int64) {
 	atomic.AddInt64(&c.store.TotalChunksDownloaded, i)
 }
 
+func (c *Context) AddBloomFiltersDownloaded(i int64) {
+	atomic.AddInt64(&c.store.TotalBloomFiltersDownloaded, i)
+}
+
+func (c *Context) AddChunksSkippedByBloom(i int64) {
+	atomic.AddInt64(&c.store.TotalChunksSkippedByBloom, i)
+}
+
 func (c *Context) AddChunksRef(i int64) {
 	atomic.AddInt64(&c.store.TotalChunksRef, i)
 }
@@ -274,6 +324,8 @@ func (r Result) Log(log log.Logger) {
 		"Querier.TotalChunksRef", r.Querier.Store.TotalChunksRef,
 		"Querier.TotalChunksDownloaded", r.Querier.Store.TotalChunksDownloaded,
 		"Querier.ChunksDownloadTime", time.Duration(r.Querier.Store.ChunksDownloadTime),
+		"Querier.TotalBloomFiltersDownloaded", r.Querier.Store.TotalBloomFiltersDownloaded,
+		"Querier.TotalChunksSkippedByBloom", r.Querier.Store.TotalChunksSkippedByBloom,
 		"Querier.HeadChunkBytes", humanize.Bytes(uint64(r.Querier.Store.Chunk.HeadChunkBytes)),
 		"Querier.HeadChunkLines", r.Querier.Store.Chunk.HeadChunkLines,
 		"Querier.DecompressedBytes", humanize.Bytes(uint64(r.Querier.Store.Chunk.DecompressedBytes)),
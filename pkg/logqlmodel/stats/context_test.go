@@ -266,3 +266,19 @@ func TestIngester(t *testing.T) {
 		},
 	}, statsCtx.Ingester())
 }
+
+func TestBytesLimit(t *testing.T) {
+	stats, _ := NewContext(context.Background())
+	stats.AddHeadChunkBytes(100)
+	stats.AddDecompressedBytes(100)
+	require.NoError(t, stats.BytesLimitErr())
+
+	stats.SetBytesLimit(250)
+	require.NoError(t, stats.BytesLimitErr())
+
+	// processed bytes survive resets between batches.
+	stats.Reset()
+	stats.AddDecompressedBytes(100)
+	require.Equal(t, int64(300), stats.ProcessedBytes())
+	require.ErrorIs(t, stats.BytesLimitErr(), ErrBytesLimit)
+}
@@ -310,6 +310,10 @@ type Store struct {
 	// Time spent fetching chunks in nanoseconds.
 	ChunksDownloadTime int64 `protobuf:"varint,3,opt,name=chunksDownloadTime,proto3" json:"chunksDownloadTime"`
 	Chunk              Chunk `protobuf:"bytes,4,opt,name=chunk,proto3" json:"chunk"`
+	// Total number of bloom filters fetched.
+	TotalBloomFiltersDownloaded int64 `protobuf:"varint,5,opt,name=totalBloomFiltersDownloaded,proto3" json:"totalBloomFiltersDownloaded"`
+	// Total number of chunks skipped because their bloom filter ruled out the query line filters.
+	TotalChunksSkippedByBloom int64 `protobuf:"varint,6,opt,name=totalChunksSkippedByBloom,proto3" json:"totalChunksSkippedByBloom"`
 }
 
 func (m *Store) Reset()      { *m = Store{} }
@@ -372,6 +376,20 @@ func (m *Store) GetChunk() Chunk {
 	return Chunk{}
 }
 
+func (m *Store) GetTotalBloomFiltersDownloaded() int64 {
+	if m != nil {
+		return m.TotalBloomFiltersDownloaded
+	}
+	return 0
+}
+
+func (m *Store) GetTotalChunksSkippedByBloom() int64 {
+	if m != nil {
+		return m.TotalChunksSkippedByBloom
+	}
+	return 0
+}
+
 type Chunk struct {
 	// Total bytes processed but was already in memory. (found in the headchunk)
 	HeadChunkBytes int64 `protobuf:"varint,4,opt,name=headChunkBytes,proto3" json:"headChunkBytes"`
@@ -473,52 +491,55 @@ func init() {
 func init() { proto.RegisterFile("pkg/logqlmodel/stats/stats.proto", fileDescriptor_6cdfe5d2aea33ebb) }
 
 var fileDescriptor_6cdfe5d2aea33ebb = []byte{
-	// 714 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x8c, 0x55, 0x3d, 0x6f, 0xd3, 0x5c,
-	0x14, 0x8e, 0x93, 0xd7, 0x49, 0x7a, 0xdf, 0x7e, 0x71, 0xab, 0xd2, 0x00, 0x92, 0x5d, 0x65, 0xaa,
-	0x04, 0x34, 0xe2, 0x63, 0x01, 0xd1, 0xc5, 0xad, 0x90, 0x2a, 0x81, 0x28, 0xa7, 0xb0, 0xb0, 0x39,
-	0xce, 0x6d, 0x62, 0xd5, 0xf1, 0x4d, 0xfd, 0x21, 0xe8, 0xc6, 0xc6, 0x08, 0x3f, 0x82, 0x81, 0x85,
-	0x9f, 0xc0, 0xde, 0xb1, 0x63, 0x27, 0x8b, 0xba, 0x0b, 0xf2, 0xd4, 0x9f, 0x80, 0x7c, 0xae, 0x63,
-	0xd7, 0x37, 0x8e, 0xc4, 0x92, 0x9c, 0xf3, 0x3c, 0xe7, 0x39, 0xe7, 0xfa, 0x3c, 0xd7, 0x32, 0xd9,
-	0x9c, 0x1c, 0x0f, 0x7b, 0x0e, 0x1f, 0x9e, 0x38, 0x63, 0x3e, 0x60, 0x4e, 0xcf, 0x0f, 0xcc, 0xc0,
-	0x17, 0xbf, 0xdb, 0x13, 0x8f, 0x07, 0x9c, 0xaa, 0x98, 0xdc, 0x7d, 0x38, 0xb4, 0x83, 0x51, 0xd8,
-	0xdf, 0xb6, 0xf8, 0xb8, 0x37, 0xe4, 0x43, 0xde, 0x43, 0xb6, 0x1f, 0x1e, 0x61, 0x86, 0x09, 0x46,
-	0x42, 0xd5, 0xfd, 0xa5, 0x90, 0x26, 0x30, 0x3f, 0x74, 0x02, 0xfa, 0x8c, 0xb4, 0xfc, 0x70, 0x3c,
-	0x36, 0xbd, 0xd3, 0x8e, 0xb2, 0xa9, 0x6c, 0xfd, 0xff, 0x78, 0x79, 0x5b, 0xf4, 0x3f, 0x14, 0xa8,
-	0xb1, 0x72, 0x16, 0xe9, 0xb5, 0x24, 0xd2, 0xa7, 0x65, 0x30, 0x0d, 0x52, 0xe9, 0x49, 0xc8, 0x3c,
-	0x9b, 0x79, 0x9d, 0x7a, 0x49, 0xfa, 0x56, 0xa0, 0x85, 0x34, 0x2b, 0x83, 0x69, 0x40, 0x77, 0x48,
-	0xdb, 0x76, 0x87, 0xcc, 0x0f, 0x98, 0xd7, 0x69, 0xa0, 0x76, 0x25, 0xd3, 0xee, 0x67, 0xb0, 0xb1,
-	0x9a, 0x89, 0xf3, 0x42, 0xc8, 0xa3, 0xee, 0xf7, 0x06, 0x69, 0x65, 0xe7, 0xa3, 0xef, 0xc9, 0x46,
-	0xff, 0x34, 0x60, 0xfe, 0x81, 0xc7, 0x2d, 0xe6, 0xfb, 0x6c, 0x70, 0xc0, 0xbc, 0x43, 0x66, 0x71,
-	0x77, 0x80, 0x0f, 0xd4, 0x30, 0xee, 0x25, 0x91, 0x3e, 0xaf, 0x04, 0xe6, 0x11, 0x69, 0x5b, 0xc7,
-	0x76, 0x2b, 0xdb, 0xd6, 0x8b, 0xb6, 0x73, 0x4a, 0x60, 0x1e, 0x41, 0xf7, 0xc9, 0x5a, 0xc0, 0x03,
-	0xd3, 0x31, 0x4a, 0x63, 0x71, 0x07, 0x0d, 0x63, 0x23, 0x89, 0xf4, 0x2a, 0x1a, 0xaa, 0xc0, 0xbc,
-	0xd5, 0xab, 0xd2, 0xa8, 0xce, 0x7f, 0x52, 0xab, 0x32, 0x0d, 0x55, 0x20, 0xdd, 0x22, 0x6d, 0xf6,
-	0x89, 0x59, 0xef, 0xec, 0x31, 0xeb, 0xa8, 0x9b, 0xca, 0x96, 0x62, 0x2c, 0xa6, 0x9b, 0x9f, 0x62,
-	0x90, 0x47, 0xf4, 0x3e, 0x59, 0x38, 0x09, 0x59, 0xc8, 0xb0, 0xb4, 0x89, 0xa5, 0x4b, 0x49, 0xa4,
-	0x17, 0x20, 0x14, 0x61, 0xf7, 0x05, 0x69, 0x65, 0x57, 0x81, 0x3e, 0x22, 0xaa, 0x1f, 0x70, 0x8f,
-	0x65, 0x97, 0x6c, 0x71, 0x7a, 0xc9, 0x52, 0xcc, 0x58, 0xca, 0xac, 0x16, 0x25, 0x20, 0xfe, 0xba,
-	0x3f, 0xeb, 0xa4, 0x3d, 0xbd, 0x0d, 0xf4, 0x29, 0x59, 0xc4, 0x83, 0x03, 0x33, 0xad, 0x11, 0x13,
-	0xd6, 0xaa, 0xc6, 0x6a, 0x12, 0xe9, 0x25, 0x1c, 0x4a, 0x19, 0x7d, 0x49, 0x28, 0xe6, 0xbb, 0xa3,
-	0xd0, 0x3d, 0xf6, 0x5f, 0x9b, 0x01, 0x6a, 0x85, 0x7f, 0xb7, 0x93, 0x48, 0xaf, 0x60, 0xa1, 0x02,
-	0xcb, 0xa7, 0x1b, 0x98, 0xfb, 0x99, 0x5d, 0xc5, 0xf4, 0x0c, 0x87, 0x52, 0x46, 0x9f, 0x93, 0xe5,
-	0x62, 0xd9, 0x87, 0xcc, 0x0d, 0x32, 0x6f, 0x68, 0x12, 0xe9, 0x12, 0x03, 0x52, 0x5e, 0xec, 0x4b,
-	0xfd, 0xe7, 0x7d, 0x7d, 0xad, 0x13, 0x15, 0xf9, 0x7c, 0xb0, 0x78, 0x08, 0x60, 0x47, 0xd9, 0x9b,
-	0x50, 0x0c, 0xce, 0x19, 0x90, 0x72, 0xfa, 0x86, 0xac, 0xdf, 0x40, 0xf6, 0xf8, 0x47, 0xd7, 0xe1,
-	0xe6, 0x20, 0xdf, 0xda, 0x9d, 0x24, 0xd2, 0xab, 0x0b, 0xa0, 0x1a, 0x4e, 0x3d, 0xb0, 0x4a, 0x18,
-	0x5e, 0x9d, 0x46, 0xe1, 0xc1, 0x2c, 0x0b, 0x15, 0x58, 0xba, 0x11, 0x44, 0x71, 0x89, 0xc5, 0x46,
-	0x70, 0x5e, 0xb1, 0x11, 0x2c, 0x01, 0xf1, 0xd7, 0xfd, 0xd2, 0x20, 0x2a, 0xf2, 0xe9, 0x46, 0x46,
-	0xcc, 0x1c, 0x88, 0xe2, 0xf4, 0x35, 0xba, 0x69, 0x45, 0x99, 0x01, 0x29, 0x2f, 0x69, 0xd1, 0x20,
-	0xf4, 0x44, 0xd6, 0x22, 0x03, 0x52, 0x4e, 0x77, 0xc9, 0xad, 0x01, 0xb3, 0xf8, 0x78, 0xe2, 0xe1,
-	0x8b, 0x26, 0x46, 0x37, 0x51, 0xbe, 0x9e, 0x44, 0xfa, 0x2c, 0x09, 0xb3, 0x90, 0xdc, 0x44, 0x9c,
-	0xa1, 0x55, 0xdd, 0x44, 0x1c, 0x63, 0x16, 0xa2, 0x3b, 0x64, 0x45, 0x3e, 0x47, 0x1b, 0x5b, 0xac,
-	0x25, 0x91, 0x2e, 0x53, 0x20, 0x03, 0xa9, 0x1c, 0xed, 0xdd, 0x0b, 0x27, 0x8e, 0x6d, 0x99, 0xa9,
-	0x7c, 0xa1, 0x90, 0x4b, 0x14, 0xc8, 0x80, 0xd1, 0x3f, 0xbf, 0xd4, 0x6a, 0x17, 0x97, 0x5a, 0xed,
-	0xfa, 0x52, 0x53, 0x3e, 0xc7, 0x9a, 0xf2, 0x23, 0xd6, 0x94, 0xb3, 0x58, 0x53, 0xce, 0x63, 0x4d,
-	0xf9, 0x1d, 0x6b, 0xca, 0x9f, 0x58, 0xab, 0x5d, 0xc7, 0x9a, 0xf2, 0xed, 0x4a, 0xab, 0x9d, 0x5f,
-	0x69, 0xb5, 0x8b, 0x2b, 0xad, 0xf6, 0xe1, 0xc1, 0xcd, 0xaf, 0x9a, 0x67, 0x1e, 0x99, 0xae, 0xd9,
-	0x73, 0xf8, 0xb1, 0xdd, 0xab, 0xfa, 0x2c, 0xf6, 0x9b, 0xf8, 0x6d, 0x7b, 0xf2, 0x37, 0x00, 0x00,
-	0xff, 0xff, 0xf4, 0x8b, 0xe3, 0xd1, 0x35, 0x07, 0x00, 0x00,
+	// 765 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x8c, 0x55, 0x3b, 0x6f, 0xdb, 0x48,
+	0x10, 0x16, 0x4d, 0xd3, 0x92, 0xf7, 0xfc, 0xba, 0x35, 0x7c, 0xa6, 0xcf, 0x38, 0xd2, 0x50, 0x65,
+	0xe0, 0x12, 0x0b, 0x79, 0x34, 0x09, 0xe2, 0x86, 0x36, 0x0c, 0x18, 0x48, 0x10, 0x67, 0x94, 0x34,
+	0x49, 0x45, 0x51, 0x6b, 0x89, 0x10, 0xa5, 0x95, 0xf9, 0x40, 0xe2, 0x2e, 0x5d, 0xda, 0xfc, 0x88,
+	0x14, 0x69, 0xf2, 0x13, 0xd2, 0xbb, 0x8b, 0x4b, 0x57, 0x44, 0x2c, 0x37, 0x01, 0x2b, 0xff, 0x84,
+	0x80, 0xb3, 0x14, 0x29, 0x52, 0x94, 0x91, 0x46, 0xdc, 0xf9, 0x1e, 0x33, 0xbb, 0x33, 0x2b, 0x92,
+	0xec, 0x0c, 0x7b, 0x9d, 0x86, 0xc3, 0x3b, 0x67, 0x4e, 0x9f, 0xb7, 0x99, 0xd3, 0xf0, 0x7c, 0xd3,
+	0xf7, 0xc4, 0xef, 0xde, 0xd0, 0xe5, 0x3e, 0xa7, 0x0a, 0x06, 0xff, 0xde, 0xef, 0xd8, 0x7e, 0x37,
+	0x68, 0xed, 0x59, 0xbc, 0xdf, 0xe8, 0xf0, 0x0e, 0x6f, 0x20, 0xdb, 0x0a, 0x4e, 0x31, 0xc2, 0x00,
+	0x57, 0xc2, 0x55, 0xff, 0x2e, 0x91, 0x05, 0x60, 0x5e, 0xe0, 0xf8, 0xf4, 0x09, 0xa9, 0x7a, 0x41,
+	0xbf, 0x6f, 0xba, 0xe7, 0xaa, 0xb4, 0x23, 0xed, 0xfe, 0xf5, 0x70, 0x65, 0x4f, 0xe4, 0x6f, 0x0a,
+	0xd4, 0x58, 0xbd, 0x08, 0xf5, 0x4a, 0x14, 0xea, 0x63, 0x19, 0x8c, 0x17, 0xb1, 0xf5, 0x2c, 0x60,
+	0xae, 0xcd, 0x5c, 0x75, 0x2e, 0x67, 0x7d, 0x25, 0xd0, 0xcc, 0x9a, 0xc8, 0x60, 0xbc, 0xa0, 0xfb,
+	0xa4, 0x66, 0x0f, 0x3a, 0xcc, 0xf3, 0x99, 0xab, 0xca, 0xe8, 0x5d, 0x4d, 0xbc, 0xc7, 0x09, 0x6c,
+	0xac, 0x25, 0xe6, 0x54, 0x08, 0xe9, 0xaa, 0xfe, 0x45, 0x26, 0xd5, 0x64, 0x7f, 0xf4, 0x0d, 0xd9,
+	0x6c, 0x9d, 0xfb, 0xcc, 0x3b, 0x71, 0xb9, 0xc5, 0x3c, 0x8f, 0xb5, 0x4f, 0x98, 0xdb, 0x64, 0x16,
+	0x1f, 0xb4, 0xf1, 0x40, 0xb2, 0xb1, 0x1d, 0x85, 0xfa, 0x2c, 0x09, 0xcc, 0x22, 0xe2, 0xb4, 0x8e,
+	0x3d, 0x28, 0x4d, 0x3b, 0x97, 0xa5, 0x9d, 0x21, 0x81, 0x59, 0x04, 0x3d, 0x26, 0xeb, 0x3e, 0xf7,
+	0x4d, 0xc7, 0xc8, 0x95, 0xc5, 0x1e, 0xc8, 0xc6, 0x66, 0x14, 0xea, 0x65, 0x34, 0x94, 0x81, 0x69,
+	0xaa, 0xe7, 0xb9, 0x52, 0xea, 0x7c, 0x21, 0x55, 0x9e, 0x86, 0x32, 0x90, 0xee, 0x92, 0x1a, 0xfb,
+	0xc0, 0xac, 0xd7, 0x76, 0x9f, 0xa9, 0xca, 0x8e, 0xb4, 0x2b, 0x19, 0x4b, 0x71, 0xe7, 0xc7, 0x18,
+	0xa4, 0x2b, 0xfa, 0x3f, 0x59, 0x3c, 0x0b, 0x58, 0xc0, 0x50, 0xba, 0x80, 0xd2, 0xe5, 0x28, 0xd4,
+	0x33, 0x10, 0xb2, 0x65, 0xfd, 0x19, 0xa9, 0x26, 0x57, 0x81, 0x3e, 0x20, 0x8a, 0xe7, 0x73, 0x97,
+	0x25, 0x97, 0x6c, 0x69, 0x7c, 0xc9, 0x62, 0xcc, 0x58, 0x4e, 0x46, 0x2d, 0x24, 0x20, 0x1e, 0xf5,
+	0x6f, 0x73, 0xa4, 0x36, 0xbe, 0x0d, 0xf4, 0x31, 0x59, 0xc2, 0x8d, 0x03, 0x33, 0xad, 0x2e, 0x13,
+	0xa3, 0x55, 0x8c, 0xb5, 0x28, 0xd4, 0x73, 0x38, 0xe4, 0x22, 0x7a, 0x44, 0x28, 0xc6, 0x07, 0xdd,
+	0x60, 0xd0, 0xf3, 0x5e, 0x98, 0x3e, 0x7a, 0xc5, 0xfc, 0xfe, 0x89, 0x42, 0xbd, 0x84, 0x85, 0x12,
+	0x2c, 0xad, 0x6e, 0x60, 0xec, 0x25, 0xe3, 0xca, 0xaa, 0x27, 0x38, 0xe4, 0x22, 0xfa, 0x94, 0xac,
+	0x64, 0xcd, 0x6e, 0xb2, 0x81, 0x9f, 0xcc, 0x86, 0x46, 0xa1, 0x5e, 0x60, 0xa0, 0x10, 0x67, 0xfd,
+	0x52, 0xfe, 0xb8, 0x5f, 0x3f, 0x64, 0xa2, 0x20, 0x9f, 0x16, 0x16, 0x87, 0x00, 0x76, 0xaa, 0x4a,
+	0x85, 0xc2, 0x29, 0x03, 0x85, 0x98, 0xbe, 0x24, 0x1b, 0x13, 0xc8, 0x21, 0x7f, 0x3f, 0x70, 0xb8,
+	0xd9, 0x4e, 0xbb, 0xb6, 0x15, 0x85, 0x7a, 0xb9, 0x00, 0xca, 0xe1, 0x78, 0x06, 0x56, 0x0e, 0xc3,
+	0xab, 0x23, 0x67, 0x33, 0x98, 0x66, 0xa1, 0x04, 0x8b, 0x3b, 0x82, 0xa8, 0x3a, 0x9f, 0xeb, 0x08,
+	0xd6, 0xcb, 0x3a, 0x82, 0x12, 0x10, 0x0f, 0x6a, 0x92, 0x6d, 0x31, 0x10, 0x87, 0xf3, 0xfe, 0x91,
+	0xed, 0xf8, 0xcc, 0x9d, 0x3c, 0x91, 0x82, 0x7b, 0xd0, 0xa3, 0x50, 0xbf, 0x4b, 0x06, 0x77, 0x91,
+	0xf4, 0x1d, 0xd9, 0x9a, 0x38, 0x76, 0xb3, 0x67, 0x0f, 0x87, 0xac, 0x6d, 0x9c, 0xa3, 0x1a, 0xff,
+	0x1f, 0xb2, 0xf1, 0x5f, 0x14, 0xea, 0xb3, 0x45, 0x30, 0x9b, 0xaa, 0x7f, 0x92, 0x89, 0x82, 0x44,
+	0x3c, 0xd1, 0x2e, 0x33, 0xdb, 0xe2, 0xb0, 0xf1, 0x6b, 0x60, 0xf2, 0x2a, 0xe5, 0x19, 0x28, 0xc4,
+	0x39, 0x2f, 0x5e, 0x30, 0x55, 0x29, 0xf1, 0x22, 0x03, 0x85, 0x98, 0x1e, 0x90, 0xbf, 0xdb, 0xcc,
+	0xe2, 0xfd, 0xa1, 0x8b, 0x2f, 0x0a, 0x51, 0x5a, 0x1c, 0x6b, 0x23, 0x0a, 0xf5, 0x69, 0x12, 0xa6,
+	0xa1, 0x62, 0x12, 0xb1, 0x87, 0x6a, 0x79, 0x12, 0xb1, 0x8d, 0x69, 0x88, 0xee, 0x93, 0xd5, 0xe2,
+	0x3e, 0x6a, 0x98, 0x62, 0x3d, 0x0a, 0xf5, 0x22, 0x05, 0x45, 0x20, 0xb6, 0x63, 0x9f, 0x0f, 0x83,
+	0xa1, 0x63, 0x5b, 0x66, 0x6c, 0x5f, 0xcc, 0xec, 0x05, 0x0a, 0x8a, 0x80, 0xd1, 0xba, 0xbc, 0xd6,
+	0x2a, 0x57, 0xd7, 0x5a, 0xe5, 0xf6, 0x5a, 0x93, 0x3e, 0x8e, 0x34, 0xe9, 0xeb, 0x48, 0x93, 0x2e,
+	0x46, 0x9a, 0x74, 0x39, 0xd2, 0xa4, 0x9f, 0x23, 0x4d, 0xfa, 0x35, 0xd2, 0x2a, 0xb7, 0x23, 0x4d,
+	0xfa, 0x7c, 0xa3, 0x55, 0x2e, 0x6f, 0xb4, 0xca, 0xd5, 0x8d, 0x56, 0x79, 0x7b, 0x6f, 0xf2, 0xab,
+	0xec, 0x9a, 0xa7, 0xe6, 0xc0, 0x6c, 0x38, 0xbc, 0x67, 0x37, 0xca, 0x3e, 0xeb, 0xad, 0x05, 0xfc,
+	0x36, 0x3f, 0xfa, 0x3d, 0x00, 0xac, 0x25, 0x44, 0x1e, 0xf5, 0x07, 0x00, 0x00,
 }
 
 func (this *Result) Equal(that interface{}) bool {
@@ -681,6 +702,12 @@ func (this *Store) Equal(that interface{}) bool {
 	if !this.Chunk.Equal(&that1.Chunk) {
 		return false
 	}
+	if this.TotalBloomFiltersDownloaded != that1.TotalBloomFiltersDownloaded {
+		return false
+	}
+	if this.TotalChunksSkippedByBloom != that1.TotalChunksSkippedByBloom {
+		return false
+	}
 	return true
 }
 func (this *Chunk) Equal(that interface{}) bool {
@@ -777,12 +804,14 @@ func (this *Store) GoString() string {
 	if this == nil {
 		return "nil"
 	}
-	s := make([]string, 0, 8)
+	s := make([]string, 0, 10)
 	s = append(s, "&stats.Store{")
 	s = append(s, "TotalChunksRef: "+fmt.Sprintf("%#v", this.TotalChunksRef)+",\n")
 	s = append(s, "TotalChunksDownloaded: "+fmt.Sprintf("%#v", this.TotalChunksDownloaded)+",\n")
 	s = append(s, "ChunksDownloadTime: "+fmt.Sprintf("%#v", this.ChunksDownloadTime)+",\n")
 	s = append(s, "Chunk: "+strings.Replace(this.Chunk.GoString(), `&`, ``, 1)+",\n")
+	s = append(s, "TotalBloomFiltersDownloaded: "+fmt.Sprintf("%#v", this.TotalBloomFiltersDownloaded)+",\n")
+	s = append(s, "TotalChunksSkippedByBloom: "+fmt.Sprintf("%#v", this.TotalChunksSkippedByBloom)+",\n")
 	s = append(s, "}")
 	return strings.Join(s, "")
 }
@@ -1023,6 +1052,16 @@ func (m *Store) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	_ = i
 	var l int
 	_ = l
+	if m.TotalChunksSkippedByBloom != 0 {
+		i = encodeVarintStats(dAtA, i, uint64(m.TotalChunksSkippedByBloom))
+		i--
+		dAtA[i] = 0x30
+	}
+	if m.TotalBloomFiltersDownloaded != 0 {
+		i = encodeVarintStats(dAtA, i, uint64(m.TotalBloomFiltersDownloaded))
+		i--
+		dAtA[i] = 0x28
+	}
 	{
 		size, err := m.Chunk.MarshalToSizedBuffer(dAtA[:i])
 		if err != nil {
@@ -1208,6 +1247,12 @@ func (m *Store) Size() (n int) {
 	}
 	l = m.Chunk.Size()
 	n += 1 + l + sovStats(uint64(l))
+	if m.TotalBloomFiltersDownloaded != 0 {
+		n += 1 + sovStats(uint64(m.TotalBloomFiltersDownloaded))
+	}
+	if m.TotalChunksSkippedByBloom != 0 {
+		n += 1 + sovStats(uint64(m.TotalChunksSkippedByBloom))
+	}
 	return n
 }
 
@@ -1304,6 +1349,8 @@ func (this *Store) String() string {
 		`TotalChunksDownloaded:` + fmt.Sprintf("%v", this.TotalChunksDownloaded) + `,`,
 		`ChunksDownloadTime:` + fmt.Sprintf("%v", this.ChunksDownloadTime) + `,`,
 		`Chunk:` + strings.Replace(strings.Replace(this.Chunk.String(), "Chunk", "Chunk", 1), `&`, ``, 1) + `,`,
+		`TotalBloomFiltersDownloaded:` + fmt.Sprintf("%v", this.TotalBloomFiltersDownloaded) + `,`,
+		`TotalChunksSkippedByBloom:` + fmt.Sprintf("%v", this.TotalChunksSkippedByBloom) + `,`,
 		`}`,
 	}, "")
 	return s
@@ -2001,6 +2048,44 @@ func (m *Store) Unmarshal(dAtA []byte) error {
 				return err
 			}
 			iNdEx = postIndex
+		case 5:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field TotalBloomFiltersDownloaded", wireType)
+			}
+			m.TotalBloomFiltersDownloaded = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowStats
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.TotalBloomFiltersDownloaded |= int64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 6:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field TotalChunksSkippedByBloom", wireType)
+			}
+			m.TotalChunksSkippedByBloom = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowStats
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.TotalChunksSkippedByBloom |= int64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
 		default:
 			iNdEx = preIndex
 			skippy, err := skipStats(dAtA[iNdEx:])
@@ -88,3 +88,39 @@ func TestCrossComponentValidation(t *testing.T) {
 		}
 	}
 }
+
+func TestRetentionRejectedForTSDB(t *testing.T) {
+	for _, tc := range []struct {
+		desc             string
+		indexType        string
+		retentionEnabled bool
+		err              bool
+	}{
+		{desc: "boltdb-shipper with retention", indexType: "boltdb-shipper", retentionEnabled: true},
+		{desc: "tsdb without retention", indexType: "tsdb"},
+		{desc: "tsdb with retention", indexType: "tsdb", retentionEnabled: true, err: true},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			cfg := &Config{}
+			cfg.RegisterFlags(flag.NewFlagSet(tc.desc, 0))
+			cfg.CompactorConfig.RetentionEnabled = tc.retentionEnabled
+			cfg.SchemaConfig.Configs = []chunk.PeriodConfig{
+				{
+					Schema:    "v11",
+					IndexType: tc.indexType,
+					IndexTables: chunk.PeriodicTableConfig{
+						Period: 24 * time.Hour,
+					},
+					From: chunk.DayTime{Time: model.Now().Add(-48 * time.Hour)},
+				},
+			}
+
+			err := cfg.Validate()
+			if tc.err {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
@@ -42,9 +42,13 @@ import (
 	"github.com/grafana/loki/pkg/runtime"
 	"github.com/grafana/loki/pkg/scheduler"
 	"github.com/grafana/loki/pkg/storage"
+	"github.com/grafana/loki/pkg/storage/bloom"
 	"github.com/grafana/loki/pkg/storage/chunk"
+	"github.com/grafana/loki/pkg/storage/chunk/cache"
 	chunk_storage "github.com/grafana/loki/pkg/storage/chunk/storage"
 	"github.com/grafana/loki/pkg/storage/stores/shipper/compactor"
+	"github.com/grafana/loki/pkg/storage/stores/shipper/indexgateway"
+	"github.com/grafana/loki/pkg/storage/stores/tsdb"
 	"github.com/grafana/loki/pkg/tracing"
 	"github.com/grafana/loki/pkg/util/fakeauth"
 	util_log "github.com/grafana/loki/pkg/util/log"
@@ -79,6 +83,8 @@ type Config struct {
 	Tracing          tracing.Config           `yaml:"tracing"`
 	CompactorConfig  compactor.Config         `yaml:"compactor,omitempty"`
 	QueryScheduler   scheduler.Config         `yaml:"query_scheduler"`
+	CachePeers       cache.PeersConfig        `yaml:"cache_peers"`
+	IndexGateway     indexgateway.Config      `yaml:"index_gateway"`
 }
 
 // RegisterFlags registers flag.
@@ -115,6 +121,8 @@ func (c *Config) RegisterFlags(f *flag.FlagSet) {
 	c.Tracing.RegisterFlags(f)
 	c.CompactorConfig.RegisterFlags(f)
 	c.QueryScheduler.RegisterFlags(f)
+	c.CachePeers.RegisterFlags(f)
+	c.IndexGateway.RegisterFlags(f)
 }
 
 func (c *Config) registerServerFlagsWithChangedDefaultValues(fs *flag.FlagSet) {
@@ -192,6 +200,9 @@ func (c *Config) Validate() error {
 	if err := c.Ingester.Validate(); err != nil {
 		return errors.Wrap(err, "invalid ingester config")
 	}
+	if c.Querier.QueryIngestersSingleZone && !c.Ingester.LifecyclerConfig.RingConfig.ZoneAwarenessEnabled {
+		return errors.New("invalid querier config: querying a single zone of ingesters requires zone-aware replication to be enabled")
+	}
 	if err := c.LimitsConfig.Validate(); err != nil {
 		return errors.Wrap(err, "invalid limits config")
 	}
@@ -201,9 +212,18 @@ func (c *Config) Validate() error {
 	if err := c.StorageConfig.BoltDBShipperConfig.Validate(); err != nil {
 		return errors.Wrap(err, "invalid boltdb-shipper config")
 	}
+	if err := c.StorageConfig.TSDBShipperConfig.Validate(); err != nil {
+		return errors.Wrap(err, "invalid tsdb-shipper config")
+	}
+	if err := c.StorageConfig.BloomFilterConfig.Validate(); err != nil {
+		return errors.Wrap(err, "invalid bloom-filter config")
+	}
 	if err := c.CompactorConfig.Validate(); err != nil {
 		return errors.Wrap(err, "invalid compactor config")
 	}
+	if err := c.IndexGateway.Validate(); err != nil {
+		return errors.Wrap(err, "invalid index gateway config")
+	}
 	if err := c.ChunkStoreConfig.Validate(util_log.Logger); err != nil {
 		return errors.Wrap(err, "invalid chunk store config")
 	}
@@ -221,6 +241,11 @@ func (c *Config) Validate() error {
 				i,
 			)
 		}
+		// the compactor neither applies retention nor processes delete requests on the tsdb index yet,
+		// so it would silently keep the chunks of those periods.
+		if sc.IndexType == tsdb.TSDBType && c.CompactorConfig.RetentionEnabled {
+			return fmt.Errorf("retention and deletion are not supported for the tsdb index used by period config at index (%d), disable compactor retention", i)
+		}
 	}
 	return nil
 }
@@ -262,8 +287,11 @@ type Loki struct {
 	runtimeConfig            *runtimeconfig.Manager
 	MemberlistKV             *memberlist.KVInitService
 	compactor                *compactor.Compactor
+	bloomStore               *bloom.Store
 	QueryFrontEndTripperware basetripper.Tripperware
 	queryScheduler           *scheduler.Scheduler
+	cachePeers               *cache.Peers
+	indexGatewayRingManager  *indexgateway.RingManager
 
 	clientMetrics chunk_storage.ClientMetrics
 
@@ -300,6 +328,8 @@ func (t *Loki) setupAuthMiddleware() {
 			"/schedulerpb.SchedulerForFrontend/FrontendLoop",
 			"/schedulerpb.SchedulerForQuerier/QuerierLoop",
 			"/schedulerpb.SchedulerForQuerier/NotifyQuerierShutdown",
+			"/peerpb.PeerCache/Fetch",
+			"/peerpb.PeerCache/Store",
 		})
 }
 
@@ -500,6 +530,8 @@ func (t *Loki) setupModuleManager() error {
 	mm.RegisterModule(Compactor, t.initCompactor)
 	mm.RegisterModule(IndexGateway, t.initIndexGateway)
 	mm.RegisterModule(QueryScheduler, t.initQueryScheduler)
+	mm.RegisterModule(CachePeers, t.initCachePeers, modules.UserInvisibleModule)
+	mm.RegisterModule(IndexGatewayRing, t.initIndexGatewayRing, modules.UserInvisibleModule)
 
 	mm.RegisterModule(All, nil)
 	mm.RegisterModule(Read, nil)
@@ -512,17 +544,19 @@ func (t *Loki) setupModuleManager() error {
 		OverridesExporter:        {Overrides, Server},
 		TenantConfigs:            {RuntimeConfig},
 		Distributor:              {Ring, Server, Overrides, TenantConfigs},
-		Store:                    {Overrides},
+		Store:                    {Overrides, IndexGatewayRing},
 		Ingester:                 {Store, Server, MemberlistKV, TenantConfigs},
-		Querier:                  {Store, Ring, Server, IngesterQuerier, TenantConfigs},
-		QueryFrontendTripperware: {Server, Overrides, TenantConfigs},
+		Querier:                  {Store, Ring, Server, IngesterQuerier, TenantConfigs, CachePeers},
+		QueryFrontendTripperware: {Server, Overrides, TenantConfigs, CachePeers},
 		QueryFrontend:            {QueryFrontendTripperware},
 		QueryScheduler:           {Server, Overrides, MemberlistKV},
 		Ruler:                    {Ring, Server, Store, RulerStorage, IngesterQuerier, Overrides, TenantConfigs},
 		TableManager:             {Server},
 		Compactor:                {Server, Overrides, MemberlistKV},
-		IndexGateway:             {Server},
+		IndexGateway:             {Server, IndexGatewayRing},
 		IngesterQuerier:          {Ring},
+		CachePeers:               {Server, MemberlistKV},
+		IndexGatewayRing:         {Server, MemberlistKV},
 		All:                      {QueryScheduler, QueryFrontend, Querier, Ingester, Distributor, Ruler, Compactor},
 		Read:                     {QueryScheduler, QueryFrontend, Querier, Ruler, Compactor},
 		Write:                    {Ingester, Distributor},
@@ -533,6 +567,12 @@ func (t *Loki) setupModuleManager() error {
 		deps[Store] = append(deps[Store], IngesterQuerier)
 	}
 
+	// Add CachePeers as a dependency for store when target is either querier or read, so that the store caches get
+	// the peers. Other targets using the store, like ingesters, don't join the cache peers ring.
+	if t.Cfg.isModuleEnabled(Querier) || t.Cfg.isModuleEnabled(Read) || t.Cfg.isModuleEnabled(All) {
+		deps[Store] = append(deps[Store], CachePeers)
+	}
+
 	// If the query scheduler and querier are running together, make sure the scheduler goes
 	// first to initialize the ring that will also be used by the querier
 	if (t.Cfg.isModuleEnabled(Querier) && t.Cfg.isModuleEnabled(QueryScheduler)) || t.Cfg.isModuleEnabled(Read) || t.Cfg.isModuleEnabled(All) {
@@ -74,6 +74,11 @@ func TestLoki_isModuleEnabled(t1 *testing.T) {
 		{name: "Multi target includes querier", target: flagext.StringSliceCSV{"query-frontend", "query-scheduler", "querier"}, module: Querier, want: true},
 		{name: "Multi target does not include distributor", target: flagext.StringSliceCSV{"query-frontend", "query-scheduler", "querier"}, module: Distributor, want: false},
 		{name: "Test recursive dep, Ingester -> TenantConfigs -> RuntimeConfig", target: flagext.StringSliceCSV{"ingester"}, module: RuntimeConfig, want: true},
+		{name: "Querier joins the cache peers", target: flagext.StringSliceCSV{"querier"}, module: CachePeers, want: true},
+		{name: "Query Frontend joins the cache peers", target: flagext.StringSliceCSV{"query-frontend"}, module: CachePeers, want: true},
+		{name: "Ingester does not join the cache peers", target: flagext.StringSliceCSV{"ingester"}, module: CachePeers, want: false},
+		{name: "Ruler does not join the cache peers", target: flagext.StringSliceCSV{"ruler"}, module: CachePeers, want: false},
+		{name: "Compactor does not join the cache peers", target: flagext.StringSliceCSV{"compactor"}, module: CachePeers, want: false},
 	}
 	for _, tt := range tests {
 		t1.Run(tt.name, func(t1 *testing.T) {
@@ -40,8 +40,11 @@ import (
 	"github.com/grafana/loki/pkg/scheduler"
 	"github.com/grafana/loki/pkg/scheduler/schedulerpb"
 	loki_storage "github.com/grafana/loki/pkg/storage"
+	"github.com/grafana/loki/pkg/storage/bloom"
 	"github.com/grafana/loki/pkg/storage/chunk"
 	"github.com/grafana/loki/pkg/storage/chunk/cache"
+	"github.com/grafana/loki/pkg/storage/chunk/cache/peerpb"
+	"github.com/grafana/loki/pkg/storage/chunk/encryption"
 	"github.com/grafana/loki/pkg/storage/chunk/storage"
 	chunk_storage "github.com/grafana/loki/pkg/storage/chunk/storage"
 	chunk_util "github.com/grafana/loki/pkg/storage/chunk/util"
@@ -50,6 +53,7 @@ import (
 	"github.com/grafana/loki/pkg/storage/stores/shipper/indexgateway"
 	"github.com/grafana/loki/pkg/storage/stores/shipper/indexgateway/indexgatewaypb"
 	"github.com/grafana/loki/pkg/storage/stores/shipper/uploads"
+	"github.com/grafana/loki/pkg/storage/stores/tsdb"
 	"github.com/grafana/loki/pkg/util/httpreq"
 	util_log "github.com/grafana/loki/pkg/util/log"
 	serverutil "github.com/grafana/loki/pkg/util/server"
@@ -80,6 +84,8 @@ const (
 	Compactor                string = "compactor"
 	IndexGateway             string = "index-gateway"
 	QueryScheduler           string = "query-scheduler"
+	CachePeers               string = "cache-peers"
+	IndexGatewayRing         string = "index-gateway-ring"
 	All                      string = "all"
 	Read                     string = "read"
 	Write                    string = "write"
@@ -246,6 +252,9 @@ func (t *Loki) initQuerier() (services.Service, error) {
 		"/loki/api/v1/labels":              http.HandlerFunc(t.Querier.LabelHandler),
 		"/loki/api/v1/label/{name}/values": http.HandlerFunc(t.Querier.LabelHandler),
 		"/loki/api/v1/series":              http.HandlerFunc(t.Querier.SeriesHandler),
+		"/loki/api/v1/patterns":            http.HandlerFunc(t.Querier.PatternsHandler),
+		"/loki/api/v1/explain":             t.Querier.ExplainHandler(t.Cfg.QueryRange),
+		"/loki/api/v1/format_query":        http.HandlerFunc(querier.FormatQueryHandler),
 
 		"/api/prom/query":               httpMiddleware.Wrap(http.HandlerFunc(t.Querier.LogQueryHandler)),
 		"/api/prom/label":               http.HandlerFunc(t.Querier.LabelHandler),
@@ -277,6 +286,21 @@ func (t *Loki) initIngester() (_ services.Service, err error) {
 	t.Cfg.Ingester.LifecyclerConfig.RingConfig.KVStore.MemberlistKV = t.MemberlistKV.GetMemberlistKV
 	t.Cfg.Ingester.LifecyclerConfig.ListenPort = t.Cfg.Server.GRPCListenPort
 
+	if t.Cfg.Ingester.WAL.Enabled && t.Cfg.Ingester.WAL.Shipping.Enabled {
+		shippingCfg := t.Cfg.Ingester.WAL.Shipping
+		objectClient, err := chunk_storage.NewObjectClient(shippingCfg.SharedStoreType, t.Cfg.StorageConfig.Config, t.clientMetrics)
+		if err != nil {
+			return nil, err
+		}
+		// the WAL holds the data of every tenant, so it is encrypted with a key of its own.
+		t.Cfg.Ingester.WAL.Shipping.ObjectClient, err = encryption.WrapObjectClient(t.Cfg.StorageConfig.Encryption, objectClient, func(string) (string, error) {
+			return shippingCfg.EncryptionKeyName, nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	t.Ingester, err = ingester.New(t.Cfg.Ingester, t.Cfg.IngesterClient, t.Store, t.overrides, t.tenantConfigs, prometheus.DefaultRegisterer)
 	if err != nil {
 		return
@@ -289,12 +313,15 @@ func (t *Loki) initIngester() (_ services.Service, err error) {
 	logproto.RegisterPusherServer(t.Server.GRPC, t.Ingester)
 	logproto.RegisterQuerierServer(t.Server.GRPC, t.Ingester)
 	logproto.RegisterIngesterServer(t.Server.GRPC, t.Ingester)
+	logproto.RegisterPatternServer(t.Server.GRPC, t.Ingester)
 
 	httpMiddleware := middleware.Merge(
 		serverutil.RecoveryHTTPMiddleware,
 	)
 	t.Server.HTTP.Path("/flush").Methods("GET", "POST").Handler(httpMiddleware.Wrap(http.HandlerFunc(t.Ingester.FlushHandler)))
 	t.Server.HTTP.Methods("POST").Path("/ingester/flush_shutdown").Handler(httpMiddleware.Wrap(http.HandlerFunc(t.Ingester.ShutdownHandler)))
+	t.Server.HTTP.Methods("POST").Path("/ingester/flush").Handler(httpMiddleware.Wrap(http.HandlerFunc(t.Ingester.FlushStreamsHandler)))
+	t.Server.HTTP.Methods("GET").Path("/ingester/streams").Handler(httpMiddleware.Wrap(http.HandlerFunc(t.Ingester.StreamsHandler)))
 
 	return t.Ingester, nil
 }
@@ -347,6 +374,13 @@ func (t *Loki) initStore() (_ services.Service, err error) {
 		t.Cfg.ChunkStoreConfig.WriteDedupeCacheConfig = cache.Config{}
 	}
 
+	// The peer caches are only used by the read path, the targets not joining the cache peers ring go without them.
+	if t.cachePeers == nil {
+		t.Cfg.ChunkStoreConfig.ChunkCacheConfig.EnablePeerCache = false
+		t.Cfg.ChunkStoreConfig.WriteDedupeCacheConfig.EnablePeerCache = false
+		t.Cfg.StorageConfig.IndexQueriesCacheConfig.EnablePeerCache = false
+	}
+
 	if loki_storage.UsingBoltdbShipper(t.Cfg.SchemaConfig.Configs) {
 		t.Cfg.StorageConfig.BoltDBShipperConfig.IngesterName = t.Cfg.Ingester.LifecyclerConfig.ID
 		switch true {
@@ -380,13 +414,29 @@ func (t *Loki) initStore() (_ services.Service, err error) {
 		}
 	}
 
+	if loki_storage.UsingTSDB(t.Cfg.SchemaConfig.Configs) {
+		t.Cfg.StorageConfig.TSDBShipperConfig.IngesterName = t.Cfg.Ingester.LifecyclerConfig.ID
+		switch true {
+		case t.Cfg.isModuleEnabled(Ingester), t.Cfg.isModuleEnabled(Write):
+			// We do not want ingester to unnecessarily keep downloading files
+			t.Cfg.StorageConfig.TSDBShipperConfig.Mode = shipper.ModeWriteOnly
+			t.Cfg.StorageConfig.TSDBShipperConfig.IngesterHeadRetainPeriod = tsdbShipperQuerierIndexUpdateDelay(t.Cfg) + 2*time.Minute
+		case t.Cfg.isModuleEnabled(Querier), t.Cfg.isModuleEnabled(Ruler), t.Cfg.isModuleEnabled(Read):
+			// We do not want query to do any updates to index
+			t.Cfg.StorageConfig.TSDBShipperConfig.Mode = shipper.ModeReadOnly
+		default:
+			t.Cfg.StorageConfig.TSDBShipperConfig.Mode = shipper.ModeReadWrite
+			t.Cfg.StorageConfig.TSDBShipperConfig.IngesterHeadRetainPeriod = tsdbShipperQuerierIndexUpdateDelay(t.Cfg) + 2*time.Minute
+		}
+	}
+
 	chunkStore, err := chunk_storage.NewStore(t.Cfg.StorageConfig.Config, t.Cfg.ChunkStoreConfig.StoreConfig, t.Cfg.SchemaConfig.SchemaConfig, t.overrides, t.clientMetrics, prometheus.DefaultRegisterer, nil, util_log.Logger)
 	if err != nil {
 		return
 	}
 
-	if loki_storage.UsingBoltdbShipper(t.Cfg.SchemaConfig.Configs) {
-		boltdbShipperMinIngesterQueryStoreDuration := boltdbShipperMinIngesterQueryStoreDuration(t.Cfg)
+	if loki_storage.UsingBoltdbShipper(t.Cfg.SchemaConfig.Configs) || loki_storage.UsingTSDB(t.Cfg.SchemaConfig.Configs) {
+		boltdbShipperMinIngesterQueryStoreDuration := minIngesterQueryStoreDuration(t.Cfg)
 		switch true {
 		case t.Cfg.isModuleEnabled(Querier), t.Cfg.isModuleEnabled(Ruler), t.Cfg.isModuleEnabled(Read):
 			// Do not use the AsyncStore if the querier is configured with QueryStoreOnly set to true
@@ -404,7 +454,7 @@ func (t *Loki) initStore() (_ services.Service, err error) {
 			// ToDo: See if we can avoid doing this when not running loki in clustered mode.
 			t.Cfg.Ingester.QueryStore = true
 			boltdbShipperConfigIdx := loki_storage.ActivePeriodConfig(t.Cfg.SchemaConfig.Configs)
-			if t.Cfg.SchemaConfig.Configs[boltdbShipperConfigIdx].IndexType != shipper.BoltDBShipperType {
+			if !loki_storage.IsObjectStorageIndex(t.Cfg.SchemaConfig.Configs[boltdbShipperConfigIdx].IndexType) {
 				boltdbShipperConfigIdx++
 			}
 			mlb, err := calculateMaxLookBack(t.Cfg.SchemaConfig.Configs[boltdbShipperConfigIdx], t.Cfg.Ingester.QueryStoreMaxLookBackPeriod,
@@ -421,14 +471,48 @@ func (t *Loki) initStore() (_ services.Service, err error) {
 		return
 	}
 
+	if t.Cfg.StorageConfig.BloomFilterConfig.Enabled {
+		bloomStore, err := t.getBloomStore()
+		if err != nil {
+			return nil, err
+		}
+		t.Store.SetBloomStore(bloomStore)
+	}
+
 	return services.NewIdleService(nil, func(_ error) error {
 		t.Store.Stop()
 		return nil
 	}), nil
 }
 
+// getBloomStore returns the store of the chunk bloom filters, kept by default in the object store of the active schema.
+// It is shared by the modules writing chunks, which must keep the filters in sync with them.
+func (t *Loki) getBloomStore() (*bloom.Store, error) {
+	if t.bloomStore != nil {
+		return t.bloomStore, nil
+	}
+
+	cfg := t.Cfg.StorageConfig.BloomFilterConfig
+	sharedStoreType := cfg.SharedStoreType
+	if sharedStoreType == "" {
+		sharedStoreType = t.Cfg.SchemaConfig.Configs[loki_storage.ActivePeriodConfig(t.Cfg.SchemaConfig.Configs)].ObjectType
+	}
+
+	objectClient, err := chunk_storage.NewObjectClient(sharedStoreType, t.Cfg.StorageConfig.Config, t.clientMetrics)
+	if err != nil {
+		return nil, err
+	}
+	// the filters reveal the content of the chunks, so they are encrypted like them.
+	objectClient, err = encryption.WrapObjectClient(t.Cfg.StorageConfig.Encryption, objectClient, bloom.TenantFromObjectKey(cfg.SharedStoreKeyPrefix))
+	if err != nil {
+		return nil, err
+	}
+	t.bloomStore = bloom.NewStore(cfg, objectClient, prometheus.DefaultRegisterer)
+	return t.bloomStore, nil
+}
+
 func (t *Loki) initIngesterQuerier() (_ services.Service, err error) {
-	t.ingesterQuerier, err = querier.NewIngesterQuerier(t.Cfg.IngesterClient, t.ring, t.Cfg.Querier.ExtraQueryDelay)
+	t.ingesterQuerier, err = querier.NewIngesterQuerier(t.Cfg.IngesterClient, t.ring, t.Cfg.Querier.ExtraQueryDelay, t.Cfg.Querier.QueryIngestersSingleZone)
 	if err != nil {
 		return nil, err
 	}
@@ -538,6 +622,9 @@ func (t *Loki) initQueryFrontend() (_ services.Service, err error) {
 	t.Server.HTTP.Path("/loki/api/v1/labels").Methods("GET", "POST").Handler(frontendHandler)
 	t.Server.HTTP.Path("/loki/api/v1/label/{name}/values").Methods("GET", "POST").Handler(frontendHandler)
 	t.Server.HTTP.Path("/loki/api/v1/series").Methods("GET", "POST").Handler(frontendHandler)
+	t.Server.HTTP.Path("/loki/api/v1/patterns").Methods("GET", "POST").Handler(frontendHandler)
+	t.Server.HTTP.Path("/loki/api/v1/explain").Methods("GET", "POST").Handler(frontendHandler)
+	t.Server.HTTP.Path("/loki/api/v1/format_query").Methods("GET", "POST").Handler(frontendHandler)
 	t.Server.HTTP.Path("/api/prom/query").Methods("GET", "POST").Handler(frontendHandler)
 	t.Server.HTTP.Path("/api/prom/label").Methods("GET", "POST").Handler(frontendHandler)
 	t.Server.HTTP.Path("/api/prom/label/{name}/values").Methods("GET", "POST").Handler(frontendHandler)
@@ -692,8 +779,8 @@ func (t *Loki) initCompactor() (services.Service, error) {
 	t.Cfg.CompactorConfig.CompactorRing.ListenPort = t.Cfg.Server.GRPCListenPort
 	t.Cfg.CompactorConfig.CompactorRing.KVStore.MemberlistKV = t.MemberlistKV.GetMemberlistKV
 
-	if !loki_storage.UsingBoltdbShipper(t.Cfg.SchemaConfig.Configs) {
-		level.Info(util_log.Logger).Log("msg", "Not using boltdb-shipper index, not starting compactor")
+	if !loki_storage.UsingBoltdbShipper(t.Cfg.SchemaConfig.Configs) && !loki_storage.UsingTSDB(t.Cfg.SchemaConfig.Configs) {
+		level.Info(util_log.Logger).Log("msg", "Not using boltdb-shipper or tsdb index, not starting compactor")
 		return nil, nil
 	}
 
@@ -701,7 +788,15 @@ func (t *Loki) initCompactor() (services.Service, error) {
 	if err != nil {
 		return nil, err
 	}
-	t.compactor, err = compactor.NewCompactor(t.Cfg.CompactorConfig, t.Cfg.StorageConfig.Config, t.Cfg.SchemaConfig, t.overrides, t.clientMetrics, prometheus.DefaultRegisterer)
+	var bloomStore *bloom.Store
+	if t.Cfg.StorageConfig.BloomFilterConfig.Enabled {
+		bloomStore, err = t.getBloomStore()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	t.compactor, err = compactor.NewCompactor(t.Cfg.CompactorConfig, t.Cfg.StorageConfig.Config, t.Cfg.SchemaConfig, t.overrides, t.clientMetrics, bloomStore, prometheus.DefaultRegisterer)
 	if err != nil {
 		return nil, err
 	}
@@ -717,22 +812,82 @@ func (t *Loki) initCompactor() (services.Service, error) {
 }
 
 func (t *Loki) initIndexGateway() (services.Service, error) {
-	t.Cfg.StorageConfig.BoltDBShipperConfig.Mode = shipper.ModeReadOnly
-	objectClient, err := storage.NewObjectClient(t.Cfg.StorageConfig.BoltDBShipperConfig.SharedStoreType, t.Cfg.StorageConfig.Config, t.clientMetrics)
-	if err != nil {
-		return nil, err
+	var (
+		boltdbShipper *shipper.Shipper
+		tsdbShipper   *tsdb.Shipper
+	)
+
+	// Serve boltdb-shipper queries unless tsdb is the only object storage index type in use.
+	if !loki_storage.UsingTSDB(t.Cfg.SchemaConfig.Configs) || loki_storage.UsingBoltdbShipper(t.Cfg.SchemaConfig.Configs) {
+		t.Cfg.StorageConfig.BoltDBShipperConfig.Mode = shipper.ModeReadOnly
+		if t.indexGatewayRingManager != nil {
+			// only keep the index of the tenants this gateway owns.
+			t.Cfg.StorageConfig.BoltDBShipperConfig.OwnsTenant = t.indexGatewayRingManager.OwnsTenant
+		}
+		objectClient, err := storage.NewObjectClient(t.Cfg.StorageConfig.BoltDBShipperConfig.SharedStoreType, t.Cfg.StorageConfig.Config, t.clientMetrics)
+		if err != nil {
+			return nil, err
+		}
+
+		shipperIndexClient, err := shipper.NewShipper(t.Cfg.StorageConfig.BoltDBShipperConfig, objectClient, prometheus.DefaultRegisterer)
+		if err != nil {
+			return nil, err
+		}
+		boltdbShipper = shipperIndexClient.(*shipper.Shipper)
 	}
 
-	shipperIndexClient, err := shipper.NewShipper(t.Cfg.StorageConfig.BoltDBShipperConfig, objectClient, prometheus.DefaultRegisterer)
-	if err != nil {
-		return nil, err
+	if loki_storage.UsingTSDB(t.Cfg.SchemaConfig.Configs) {
+		t.Cfg.StorageConfig.TSDBShipperConfig.Mode = shipper.ModeReadOnly
+		objectClient, err := storage.NewObjectClient(t.Cfg.StorageConfig.TSDBShipperConfig.SharedStoreType, t.Cfg.StorageConfig.Config, t.clientMetrics)
+		if err != nil {
+			return nil, err
+		}
+
+		tsdbShipper, err = tsdb.NewShipper(t.Cfg.StorageConfig.TSDBShipperConfig, objectClient, prometheus.DefaultRegisterer)
+		if err != nil {
+			if boltdbShipper != nil {
+				boltdbShipper.Stop()
+			}
+			return nil, err
+		}
 	}
 
-	gateway := indexgateway.NewIndexGateway(shipperIndexClient.(*shipper.Shipper))
+	gateway := indexgateway.NewIndexGateway(boltdbShipper, tsdbShipper)
 	indexgatewaypb.RegisterIndexGatewayServer(t.Server.GRPC, gateway)
 	return gateway, nil
 }
 
+func (t *Loki) initIndexGatewayRing() (services.Service, error) {
+	if t.Cfg.IndexGateway.Mode != indexgateway.RingMode {
+		return nil, nil
+	}
+
+	managerMode := indexgateway.ClientMode
+	switch {
+	case t.Cfg.isModuleEnabled(IndexGateway):
+		managerMode = indexgateway.ServerMode
+	case t.Cfg.isModuleEnabled(Querier), t.Cfg.isModuleEnabled(Ruler), t.Cfg.isModuleEnabled(Read):
+	default:
+		// only the components querying the index need to know the index gateways.
+		return nil, nil
+	}
+
+	// Set some config sections from other config sections in the config struct
+	t.Cfg.IndexGateway.Ring.ListenPort = t.Cfg.Server.GRPCListenPort
+	t.Cfg.IndexGateway.Ring.KVStore.MemberlistKV = t.MemberlistKV.GetMemberlistKV
+
+	rm, err := indexgateway.NewRingManager(managerMode, t.Cfg.IndexGateway, util_log.Logger, prometheus.DefaultRegisterer)
+	if err != nil {
+		return nil, err
+	}
+
+	// the boltdb-shipper index queries of each tenant are sent to the index gateways owning it.
+	t.Cfg.StorageConfig.BoltDBShipperConfig.IndexGatewayClientConfig.Ring = rm.Ring
+	t.Server.HTTP.Path("/indexgateway/ring").Methods("GET", "POST").Handler(rm)
+	t.indexGatewayRingManager = rm
+	return rm, nil
+}
+
 func (t *Loki) initQueryScheduler() (services.Service, error) {
 	// Set some config sections from other config sections in the config struct
 	t.Cfg.QueryScheduler.SchedulerRing.ListenPort = t.Cfg.Server.GRPCListenPort
@@ -750,6 +905,38 @@ func (t *Loki) initQueryScheduler() (services.Service, error) {
 	return s, nil
 }
 
+func (t *Loki) initCachePeers() (services.Service, error) {
+	cacheConfigs := []*cache.Config{
+		&t.Cfg.ChunkStoreConfig.ChunkCacheConfig,
+		&t.Cfg.ChunkStoreConfig.WriteDedupeCacheConfig,
+		&t.Cfg.StorageConfig.IndexQueriesCacheConfig,
+		&t.Cfg.QueryRange.ResultsCacheConfig.CacheConfig,
+	}
+	enabled := false
+	for _, cfg := range cacheConfigs {
+		enabled = enabled || cfg.EnablePeerCache
+	}
+	if !enabled {
+		return nil, nil
+	}
+
+	// Set some config sections from other config sections in the config struct
+	t.Cfg.CachePeers.Ring.ListenPort = t.Cfg.Server.GRPCListenPort
+	t.Cfg.CachePeers.Ring.KVStore.MemberlistKV = t.MemberlistKV.GetMemberlistKV
+
+	peers, err := cache.NewPeers(t.Cfg.CachePeers, prometheus.DefaultRegisterer, util_log.Logger)
+	if err != nil {
+		return nil, err
+	}
+
+	peerpb.RegisterPeerCacheServer(t.Server.GRPC, peers)
+	for _, cfg := range cacheConfigs {
+		cfg.Peers = peers
+	}
+	t.cachePeers = peers
+	return peers, nil
+}
+
 func calculateMaxLookBack(pc chunk.PeriodConfig, maxLookBackConfig, minDuration time.Duration) (time.Duration, error) {
 	if pc.ObjectType != shipper.FilesystemObjectStoreType && maxLookBackConfig.Nanoseconds() != 0 {
 		return 0, errors.New("it is an error to specify a non zero `query_store_max_look_back_period` value when using any object store other than `filesystem`")
@@ -797,6 +984,30 @@ func boltdbShipperMinIngesterQueryStoreDuration(cfg Config) time.Duration {
 	return cfg.Ingester.MaxChunkAge + boltdbShipperIngesterIndexUploadDelay() + boltdbShipperQuerierIndexUpdateDelay(cfg) + 2*time.Minute
 }
 
+// tsdbShipperQuerierIndexUpdateDelay returns duration it could take for queriers to serve the tsdb index since it was uploaded.
+func tsdbShipperQuerierIndexUpdateDelay(cfg Config) time.Duration {
+	return cfg.StorageConfig.TSDBShipperConfig.ResyncInterval
+}
+
+// tsdbShipperMinIngesterQueryStoreDuration returns minimum duration(with some buffer) ingesters should query their stores to
+// avoid missing any logs or chunk refs due to async nature of TSDB Shipper.
+func tsdbShipperMinIngesterQueryStoreDuration(cfg Config) time.Duration {
+	return cfg.Ingester.MaxChunkAge + tsdb.UploadInterval + tsdbShipperQuerierIndexUpdateDelay(cfg) + 2*time.Minute
+}
+
+// minIngesterQueryStoreDuration returns minimum duration ingesters should query their stores considering
+// all the object storage index types in use.
+func minIngesterQueryStoreDuration(cfg Config) time.Duration {
+	var minDuration time.Duration
+	if loki_storage.UsingBoltdbShipper(cfg.SchemaConfig.Configs) {
+		minDuration = boltdbShipperMinIngesterQueryStoreDuration(cfg)
+	}
+	if loki_storage.UsingTSDB(cfg.SchemaConfig.Configs) && tsdbShipperMinIngesterQueryStoreDuration(cfg) > minDuration {
+		minDuration = tsdbShipperMinIngesterQueryStoreDuration(cfg)
+	}
+	return minDuration
+}
+
 // NewServerService constructs service from Server component.
 // servicesToWaitFor is called when server is stopping, and should return all
 // services that need to terminate before server actually stops.
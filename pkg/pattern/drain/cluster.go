@@ -0,0 +1,96 @@
+package drain
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/grafana/loki/pkg/logproto"
+)
+
+// Cluster is a group of similar lines, with the number of lines received over time.
+type Cluster struct {
+	id     int
+	tokens []string
+	// samples are sorted by timestamp, one per sample interval.
+	samples []logproto.PatternSample
+}
+
+// merge turns the tokens of the cluster differing from the given ones into wildcards.
+func (c *Cluster) merge(tokens []string) {
+	for i, t := range tokens {
+		if t != c.tokens[i] {
+			c.tokens[i] = wildcard
+		}
+	}
+}
+
+// add counts a line received at ts in the sample of its interval.
+func (c *Cluster) add(ts time.Time, interval time.Duration) {
+	t := ts.Truncate(interval).UnixNano() / int64(time.Millisecond)
+	// lines mostly arrive in order, the last sample being the usual one.
+	i := sort.Search(len(c.samples), func(i int) bool { return c.samples[i].Timestamp >= t })
+	if i < len(c.samples) && c.samples[i].Timestamp == t {
+		c.samples[i].Value++
+		return
+	}
+	c.samples = append(c.samples, logproto.PatternSample{})
+	copy(c.samples[i+1:], c.samples[i:])
+	c.samples[i] = logproto.PatternSample{Timestamp: t, Value: 1}
+}
+
+// prune drops the samples before the given time, and returns whether the cluster is left empty.
+func (c *Cluster) prune(before time.Time) bool {
+	t := before.UnixNano() / int64(time.Millisecond)
+	i := sort.Search(len(c.samples), func(i int) bool { return c.samples[i].Timestamp >= t })
+	c.samples = append(c.samples[:0], c.samples[i:]...)
+	return len(c.samples) == 0
+}
+
+// samplesBetween sums the samples within [from, through] by step, each sum being at the start of its step.
+func (c *Cluster) samplesBetween(from, through time.Time, step time.Duration) []logproto.PatternSample {
+	start, end := from.UnixNano()/int64(time.Millisecond), through.UnixNano()/int64(time.Millisecond)
+	stepMs := step.Milliseconds()
+	if stepMs <= 0 {
+		stepMs = 1
+	}
+	var result []logproto.PatternSample
+	for i := sort.Search(len(c.samples), func(i int) bool { return c.samples[i].Timestamp >= start }); i < len(c.samples); i++ {
+		s := c.samples[i]
+		if s.Timestamp > end {
+			break
+		}
+		t := start + (s.Timestamp-start)/stepMs*stepMs
+		if len(result) > 0 && result[len(result)-1].Timestamp == t {
+			result[len(result)-1].Value += s.Value
+			continue
+		}
+		result = append(result, logproto.PatternSample{Timestamp: t, Value: s.Value})
+	}
+	return result
+}
+
+// String returns the pattern of the cluster, valid for the pattern parser: wildcards are named captures, var1, var2...
+// A pattern without wildcards ends with a capture of the rest of the line, as the parser requires one.
+func (c *Cluster) String() string {
+	var (
+		sb   strings.Builder
+		vars int
+	)
+	for i, t := range c.tokens {
+		if i > 0 {
+			sb.WriteByte(' ')
+		}
+		if t != wildcard {
+			sb.WriteString(t)
+			continue
+		}
+		vars++
+		sb.WriteString("<var" + strconv.Itoa(vars) + ">")
+	}
+	if vars == 0 {
+		sb.WriteString("<rest>")
+	}
+	return sb.String()
+}
@@ -0,0 +1,285 @@
+// Package drain clusters log lines into patterns with the Drain algorithm.
+//
+// Lines are split into tokens on spaces and routed through a fixed depth prefix tree, keyed by their number
+// of tokens then by their first tokens, to a few candidate clusters. A line joins the most similar candidate,
+// whose differing tokens become wildcards, or starts a new cluster.
+// See https://jiemingzhu.github.io/pub/pjhe_icws2017.pdf.
+package drain
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/hashicorp/golang-lru/simplelru"
+
+	"github.com/grafana/loki/pkg/logproto"
+)
+
+// wildcard is the token of the parts of the lines varying within a cluster.
+const wildcard = "<_>"
+
+// capture matches the captures of the pattern parser, tokens holding one are wildcards for the pattern to stay valid.
+var capture = regexp.MustCompile(`<[a-zA-Z_][a-zA-Z0-9_]*>`)
+
+// Config configures the clustering of the lines of a stream.
+type Config struct {
+	// Depth of the prefix tree, counting the root and the clusters below the leaves: lines are routed by their first Depth-3 tokens.
+	Depth int
+	// SimilarityThreshold is the minimum ratio of tokens a line must share with a cluster to join it.
+	SimilarityThreshold float64
+	// MaxChildren is the maximum number of children of a node of the prefix tree, further tokens going to the wildcard child.
+	MaxChildren int
+	// MaxClusters is the maximum number of clusters, the least recently matched one being evicted first.
+	MaxClusters int
+	// SampleInterval is the resolution of the counts of lines kept by the clusters.
+	SampleInterval time.Duration
+}
+
+// DefaultConfig returns the configuration used by the ingesters.
+func DefaultConfig() Config {
+	return Config{
+		Depth:               4,
+		SimilarityThreshold: 0.4,
+		MaxChildren:         100,
+		MaxClusters:         300,
+		SampleInterval:      10 * time.Second,
+	}
+}
+
+// Drain clusters the lines of a stream. It is safe for concurrent use.
+type Drain struct {
+	cfg Config
+
+	mtx      sync.Mutex
+	root     *node
+	clusters *simplelru.LRU
+	nextID   int
+}
+
+type node struct {
+	children   map[string]*node
+	clusterIDs []int
+}
+
+func newNode() *node {
+	return &node{children: map[string]*node{}}
+}
+
+// New creates an empty Drain.
+func New(cfg Config) *Drain {
+	// the size is positive, simplelru only fails otherwise.
+	if cfg.MaxClusters <= 0 {
+		cfg.MaxClusters = DefaultConfig().MaxClusters
+	}
+	clusters, _ := simplelru.NewLRU(cfg.MaxClusters, nil)
+	return &Drain{
+		cfg:      cfg,
+		root:     newNode(),
+		clusters: clusters,
+	}
+}
+
+// Train adds a line received at ts to its cluster.
+func (d *Drain) Train(line string, ts time.Time) {
+	tokens := tokenize(line)
+
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	c := d.search(tokens)
+	if c == nil {
+		d.nextID++
+		c = &Cluster{id: d.nextID, tokens: cloneTokens(tokens)}
+		d.clusters.Add(c.id, c)
+		d.addToTree(c)
+	} else {
+		c.merge(tokens)
+		// marks the cluster as recently used.
+		d.clusters.Get(c.id)
+	}
+	c.add(ts, d.cfg.SampleInterval)
+}
+
+// Prune drops the counts of the lines received before the given time, and the clusters without any count left.
+func (d *Drain) Prune(before time.Time) {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	for _, k := range d.clusters.Keys() {
+		v, ok := d.clusters.Peek(k)
+		if !ok {
+			continue
+		}
+		if c := v.(*Cluster); c.prune(before) {
+			// the tree drops the ids of removed clusters when its leaves are updated.
+			d.clusters.Remove(k)
+		}
+	}
+}
+
+// Series returns the pattern of each cluster with the number of lines received in each step of [from, through].
+// Clusters without lines in the range are skipped.
+func (d *Drain) Series(from, through time.Time, step time.Duration) []logproto.PatternSeries {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	var series []logproto.PatternSeries
+	for _, k := range d.clusters.Keys() {
+		v, ok := d.clusters.Peek(k)
+		if !ok {
+			continue
+		}
+		c := v.(*Cluster)
+		samples := c.samplesBetween(from, through, step)
+		if len(samples) == 0 {
+			continue
+		}
+		series = append(series, logproto.PatternSeries{
+			Pattern: c.String(),
+			Samples: samples,
+		})
+	}
+	return series
+}
+
+// search returns the cluster of the tokens, or nil if the leaf they are routed to holds no similar enough cluster.
+func (d *Drain) search(tokens []string) *Cluster {
+	n, ok := d.root.children[strconv.Itoa(len(tokens))]
+	if !ok {
+		return nil
+	}
+	for depth := 1; depth < d.maxNodeDepth() && depth < len(tokens); depth++ {
+		next, ok := n.children[tokens[depth-1]]
+		if !ok {
+			if next, ok = n.children[wildcard]; !ok {
+				return nil
+			}
+		}
+		n = next
+	}
+	return d.bestMatch(n.clusterIDs, tokens)
+}
+
+// bestMatch returns the most similar cluster with the tokens, preferring the ones with more wildcards for equal similarities.
+func (d *Drain) bestMatch(ids []int, tokens []string) *Cluster {
+	var (
+		best          *Cluster
+		bestSim       = -1.0
+		bestWildcards = -1
+	)
+	for _, id := range ids {
+		v, ok := d.clusters.Peek(id)
+		if !ok {
+			continue
+		}
+		c := v.(*Cluster)
+		sim, wildcards := similarity(c.tokens, tokens)
+		if sim > bestSim || (sim == bestSim && wildcards > bestWildcards) {
+			best, bestSim, bestWildcards = c, sim, wildcards
+		}
+	}
+	if bestSim < d.cfg.SimilarityThreshold {
+		return nil
+	}
+	return best
+}
+
+// addToTree adds a new cluster to the leaf of its tokens, creating the missing nodes on the way.
+// Tokens with digits are routed to the wildcard child, as are the tokens of a full node.
+func (d *Drain) addToTree(c *Cluster) {
+	key := strconv.Itoa(len(c.tokens))
+	n, ok := d.root.children[key]
+	if !ok {
+		n = newNode()
+		d.root.children[key] = n
+	}
+	for depth := 1; depth < d.maxNodeDepth() && depth < len(c.tokens); depth++ {
+		token := c.tokens[depth-1]
+		if hasDigit(token) {
+			token = wildcard
+		}
+		if next, ok := n.children[token]; ok {
+			n = next
+			continue
+		}
+		_, hasWildcard := n.children[wildcard]
+		switch {
+		case hasWildcard && len(n.children) >= d.cfg.MaxChildren:
+			token = wildcard
+		case !hasWildcard && len(n.children)+1 >= d.cfg.MaxChildren:
+			// the last child left is kept for the wildcard.
+			token = wildcard
+		}
+		next, ok := n.children[token]
+		if !ok {
+			next = newNode()
+			n.children[token] = next
+		}
+		n = next
+	}
+
+	ids := make([]int, 0, len(n.clusterIDs)+1)
+	for _, id := range n.clusterIDs {
+		if d.clusters.Contains(id) {
+			ids = append(ids, id)
+		}
+	}
+	n.clusterIDs = append(ids, c.id)
+}
+
+// maxNodeDepth is the depth of the leaves holding the clusters, the root being at depth 0 and the nodes of the numbers of tokens at depth 1.
+func (d *Drain) maxNodeDepth() int {
+	return d.cfg.Depth - 2
+}
+
+// similarity returns the ratio of the tokens of the cluster equal to the tokens of the line,
+// and the number of wildcards of the cluster.
+func similarity(clusterTokens, tokens []string) (float64, int) {
+	if len(clusterTokens) == 0 {
+		return 1, 0
+	}
+	var same, wildcards int
+	for i, t := range clusterTokens {
+		if t == wildcard {
+			wildcards++
+			continue
+		}
+		if t == tokens[i] {
+			same++
+		}
+	}
+	return float64(same) / float64(len(clusterTokens)), wildcards
+}
+
+func tokenize(line string) []string {
+	tokens := strings.Split(line, " ")
+	for i, t := range tokens {
+		// most tokens have no capture, the regexp only runs on the ones that may hold one.
+		if strings.IndexByte(t, '<') >= 0 && capture.MatchString(t) {
+			tokens[i] = wildcard
+		}
+	}
+	return tokens
+}
+
+// cloneTokens copies the tokens kept by a cluster, for them not to retain the memory of the whole line.
+func cloneTokens(tokens []string) []string {
+	cloned := make([]string, len(tokens))
+	for i, t := range tokens {
+		cloned[i] = string([]byte(t))
+	}
+	return cloned
+}
+
+func hasDigit(s string) bool {
+	for _, r := range s {
+		if unicode.IsDigit(r) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,160 @@
+package drain
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/loki/pkg/logproto"
+	"github.com/grafana/loki/pkg/logql/log/pattern"
+)
+
+func patterns(d *Drain, from, through time.Time) []string {
+	var result []string
+	for _, s := range d.Series(from, through, time.Hour) {
+		result = append(result, s.Pattern)
+	}
+	return result
+}
+
+func TestDrain_Train(t *testing.T) {
+	now := time.Unix(0, 0)
+	for _, tc := range []struct {
+		name     string
+		lines    []string
+		expected []string
+	}{
+		{
+			name: "variable tokens",
+			lines: []string{
+				"GET /api/users took 10ms",
+				"GET /api/orders took 250ms",
+				"GET /api/users took 12ms",
+			},
+			expected: []string{"GET <var1> took <var2>"},
+		},
+		{
+			name: "different lengths",
+			lines: []string{
+				"connection accepted from 10.0.0.1",
+				"connection accepted from 10.0.0.2",
+				"connection closed",
+			},
+			expected: []string{"connection accepted from <var1>", "connection closed<rest>"},
+		},
+		{
+			name: "dissimilar lines",
+			lines: []string{
+				"starting server on port 8080",
+				"flushing chunks to the store",
+			},
+			expected: []string{"starting server on port 8080<rest>", "flushing chunks to the store<rest>"},
+		},
+		{
+			name: "captures in lines",
+			lines: []string{
+				"level=info <foo> done",
+				"level=info <bar> done",
+			},
+			expected: []string{"level=info <var1> done"},
+		},
+		{
+			name: "spaces are kept",
+			lines: []string{
+				"a  b 1",
+				"a  b 2",
+			},
+			expected: []string{"a  b <var1>"},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			d := New(DefaultConfig())
+			for _, l := range tc.lines {
+				d.Train(l, now)
+			}
+			require.ElementsMatch(t, tc.expected, patterns(d, now, now))
+		})
+	}
+}
+
+func TestDrain_PatternsAreValid(t *testing.T) {
+	d := New(DefaultConfig())
+	now := time.Unix(0, 0)
+	for i := 0; i < 100; i++ {
+		d.Train(fmt.Sprintf("ts=%d caller=flush.go:%d msg=\"flushing stream\" <id> user=%d", i, i%7, i%3), now)
+		d.Train(fmt.Sprintf("GET /api/v1/query_range %d", i), now)
+		d.Train("constant line", now)
+		d.Train(fmt.Sprintf("<_> %d <%d> <a%d>", i, i, i), now)
+	}
+	series := d.Series(now, now, time.Minute)
+	require.NotEmpty(t, series)
+	for _, s := range series {
+		_, err := pattern.New(s.Pattern)
+		require.NoError(t, err, s.Pattern)
+	}
+}
+
+func TestDrain_Series(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.SampleInterval = 10 * time.Second
+	d := New(cfg)
+	start := time.Unix(1000, 0)
+	for i := 0; i < 6; i++ {
+		// two lines every 10s.
+		ts := start.Add(time.Duration(i) * 10 * time.Second)
+		d.Train(fmt.Sprintf("request %d done", i), ts)
+		d.Train(fmt.Sprintf("request %d done", i), ts.Add(time.Second))
+	}
+
+	require.Equal(t, []logproto.PatternSeries{
+		{
+			Pattern: "request <var1> done",
+			Samples: []logproto.PatternSample{
+				{Timestamp: 1000000, Value: 6},
+				{Timestamp: 1030000, Value: 4},
+			},
+		},
+	}, d.Series(start, start.Add(45*time.Second), 30*time.Second))
+
+	d.Prune(start.Add(30 * time.Second))
+	require.Equal(t, []logproto.PatternSeries{
+		{
+			Pattern: "request <var1> done",
+			Samples: []logproto.PatternSample{
+				{Timestamp: 1000000, Value: 6},
+			},
+		},
+	}, d.Series(start, start.Add(time.Hour), time.Minute))
+
+	d.Prune(start.Add(time.Hour))
+	require.Empty(t, d.Series(start, start.Add(time.Hour), time.Minute))
+	require.Equal(t, 0, d.clusters.Len())
+}
+
+func TestDrain_MaxClusters(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.MaxClusters = 2
+	d := New(cfg)
+	now := time.Unix(0, 0)
+	d.Train("a b c", now)
+	d.Train("d e f g", now)
+	d.Train("a b c", now)
+	// evicts the least recently used cluster.
+	d.Train("h i j k l", now)
+	require.ElementsMatch(t, []string{"a b c<rest>", "h i j k l<rest>"}, patterns(d, now, now))
+}
+
+func TestDrain_OutOfOrder(t *testing.T) {
+	d := New(DefaultConfig())
+	for _, ts := range []int64{30, 10, 20, 10, 40} {
+		d.Train("line", time.Unix(ts, 0))
+	}
+	require.Equal(t, []logproto.PatternSample{
+		{Timestamp: 10000, Value: 2},
+		{Timestamp: 20000, Value: 1},
+		{Timestamp: 30000, Value: 1},
+		{Timestamp: 40000, Value: 1},
+	}, d.Series(time.Unix(0, 0), time.Unix(100, 0), time.Second)[0].Samples)
+}
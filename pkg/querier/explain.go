@@ -0,0 +1,151 @@
+package querier
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	jsoniter "github.com/json-iterator/go"
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/weaveworks/common/httpgrpc"
+
+	"github.com/grafana/loki/pkg/loghttp"
+	"github.com/grafana/loki/pkg/logql"
+	"github.com/grafana/loki/pkg/querier/queryrange"
+	"github.com/grafana/loki/pkg/storage/chunk"
+	"github.com/grafana/loki/pkg/tenant"
+	serverutil "github.com/grafana/loki/pkg/util/server"
+)
+
+// ExplainResponse is the http json response to an explain request.
+type ExplainResponse struct {
+	Status string      `json:"status"`
+	Data   Explanation `json:"data"`
+}
+
+// Explanation describes how a range query is executed: how the engine evaluates it and how the frontend splits and shards it.
+type Explanation struct {
+	logql.Explanation
+	SplitInterval model.Duration `json:"split_interval"`
+	Splits        []ExplainSplit `json:"splits"`
+}
+
+// ExplainSplit is a split of a query with the number of chunks it reads from the store.
+type ExplainSplit struct {
+	queryrange.SplitPlan
+	// ChunkRefs is the number of chunks of the store the selectors of the query match over the split,
+	// the chunks still in the ingesters not being counted.
+	ChunkRefs int `json:"chunk_refs"`
+}
+
+// ExplainHandler returns a handler explaining range queries, split and sharded with the given frontend configuration.
+func (q *Querier) ExplainHandler(queryRangeCfg queryrange.Config) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		req, err := loghttp.ParseRangeQuery(r)
+		if err != nil {
+			serverutil.WriteError(httpgrpc.Errorf(http.StatusBadRequest, err.Error()), w)
+			return
+		}
+
+		explanation, err := q.Explain(r.Context(), queryRangeCfg, &queryrange.LokiRequest{
+			Query:     req.Query,
+			Limit:     req.Limit,
+			Direction: req.Direction,
+			StartTs:   req.Start.UTC(),
+			EndTs:     req.End.UTC(),
+			Step:      req.Step.Milliseconds(),
+			Path:      r.URL.Path,
+		})
+		if err != nil {
+			serverutil.WriteError(err, w)
+			return
+		}
+
+		err = jsoniter.NewEncoder(w).Encode(ExplainResponse{Status: loghttp.QueryStatusSuccess, Data: *explanation})
+		if err != nil {
+			serverutil.WriteError(err, w)
+			return
+		}
+	}
+}
+
+// Explain describes how a range query is executed, estimating the chunks read by each of its splits from the index.
+func (q *Querier) Explain(ctx context.Context, queryRangeCfg queryrange.Config, req *queryrange.LokiRequest) (*Explanation, error) {
+	userID, err := tenant.TenantID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.StartTs, req.EndTs, err = validateQueryTimeRangeLimits(ctx, userID, q.limits, req.StartTs, req.EndTs); err != nil {
+		return nil, err
+	}
+
+	explanation, err := logql.Explain(req.Query)
+	if err != nil {
+		return nil, httpgrpc.Errorf(http.StatusBadRequest, err.Error())
+	}
+	expr, err := logql.ParseExpr(req.Query)
+	if err != nil {
+		return nil, httpgrpc.Errorf(http.StatusBadRequest, err.Error())
+	}
+
+	schema := chunk.SchemaConfig{Configs: q.store.GetSchemaConfigs()}
+	plan, err := queryrange.ExplainRangeQuery(ctx, queryRangeCfg, schema, q.limits, req, nowFunc())
+	if err != nil {
+		return nil, err
+	}
+
+	// Enforce the query timeout while querying the index
+	ctx, cancel := context.WithDeadline(ctx, time.Now().Add(q.cfg.QueryTimeout))
+	defer cancel()
+
+	res := &Explanation{
+		Explanation:   *explanation,
+		SplitInterval: plan.SplitInterval,
+		Splits:        make([]ExplainSplit, 0, len(plan.Splits)),
+	}
+	selectors := appendSelectors(nil, expr, 0)
+	for _, split := range plan.Splits {
+		refs := 0
+		for _, s := range selectors {
+			chunks, _, err := q.store.GetChunkRefs(ctx, userID, model.TimeFromUnixNano(split.Start.Add(-s.lookback).UnixNano()), model.TimeFromUnixNano(split.End.UnixNano()), s.matchers...)
+			if err != nil {
+				return nil, err
+			}
+			for _, group := range chunks {
+				refs += len(group)
+			}
+		}
+		res.Splits = append(res.Splits, ExplainSplit{SplitPlan: split, ChunkRefs: refs})
+	}
+	return res, nil
+}
+
+// selector is a stream selector of a query, whose streams are read over the range of the query extended by lookback.
+type selector struct {
+	matchers []*labels.Matcher
+	lookback time.Duration
+}
+
+// appendSelectors appends the stream selectors of expr, the ranges of range aggregations and subqueries being looked back over.
+func appendSelectors(selectors []selector, expr logql.Expr, lookback time.Duration) []selector {
+	switch e := expr.(type) {
+	case *logql.LiteralExpr:
+		return selectors
+	case logql.LogSelectorExpr:
+		return append(selectors, selector{matchers: e.Matchers(), lookback: lookback})
+	case *logql.RangeAggregationExpr:
+		return appendSelectors(selectors, e.Left.Left, lookback+e.Left.Interval+e.Left.Offset)
+	case *logql.SubqueryAggregationExpr:
+		return appendSelectors(selectors, e.Left.Left, lookback+e.Left.Interval+e.Left.Offset)
+	case *logql.VectorAggregationExpr:
+		return appendSelectors(selectors, e.Left, lookback)
+	case *logql.LabelReplaceExpr:
+		return appendSelectors(selectors, e.Left, lookback)
+	case *logql.BinOpExpr:
+		return appendSelectors(appendSelectors(selectors, e.SampleExpr, lookback), e.RHS, lookback)
+	default:
+		return selectors
+	}
+}
@@ -0,0 +1,40 @@
+package querier
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/loki/pkg/logql"
+)
+
+func Test_appendSelectors(t *testing.T) {
+	for _, tc := range []struct {
+		query    string
+		expected []selector
+	}{
+		{
+			`{app="foo"} |= "err"`,
+			[]selector{{matchers: []*labels.Matcher{labels.MustNewMatcher(labels.MatchEqual, "app", "foo")}}},
+		},
+		{
+			`sum(rate({app="foo"}[5m] offset 1m)) / 2 > count_over_time({app="bar"}[1h])`,
+			[]selector{
+				{matchers: []*labels.Matcher{labels.MustNewMatcher(labels.MatchEqual, "app", "foo")}, lookback: 6 * time.Minute},
+				{matchers: []*labels.Matcher{labels.MustNewMatcher(labels.MatchEqual, "app", "bar")}, lookback: time.Hour},
+			},
+		},
+		{
+			`max_over_time(rate({app="foo"}[5m])[1h:1m])`,
+			[]selector{{matchers: []*labels.Matcher{labels.MustNewMatcher(labels.MatchEqual, "app", "foo")}, lookback: time.Hour + 5*time.Minute}},
+		},
+	} {
+		t.Run(tc.query, func(t *testing.T) {
+			expr, err := logql.ParseExpr(tc.query)
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, appendSelectors(nil, expr, 0))
+		})
+	}
+}
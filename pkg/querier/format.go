@@ -0,0 +1,49 @@
+package querier
+
+import (
+	"net/http"
+
+	jsoniter "github.com/json-iterator/go"
+	"github.com/weaveworks/common/httpgrpc"
+
+	"github.com/grafana/loki/pkg/loghttp"
+	"github.com/grafana/loki/pkg/logql"
+	serverutil "github.com/grafana/loki/pkg/util/server"
+)
+
+// FormatQueryResponse is the http json response to a format query request.
+type FormatQueryResponse struct {
+	Status   string   `json:"status"`
+	Data     string   `json:"data"`
+	Warnings []string `json:"warnings"`
+}
+
+// FormatQueryHandler pretty-prints a query, returning the warnings of the linter about it.
+func FormatQueryHandler(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		serverutil.WriteError(httpgrpc.Errorf(http.StatusBadRequest, err.Error()), w)
+		return
+	}
+	query := r.Form.Get("query")
+
+	formatted, err := logql.Format(query)
+	if err != nil {
+		serverutil.WriteError(httpgrpc.Errorf(http.StatusBadRequest, err.Error()), w)
+		return
+	}
+	warnings, err := logql.Lint(query)
+	if err != nil {
+		serverutil.WriteError(httpgrpc.Errorf(http.StatusBadRequest, err.Error()), w)
+		return
+	}
+
+	err = jsoniter.NewEncoder(w).Encode(FormatQueryResponse{
+		Status:   loghttp.QueryStatusSuccess,
+		Data:     formatted,
+		Warnings: warnings,
+	})
+	if err != nil {
+		serverutil.WriteError(err, w)
+		return
+	}
+}
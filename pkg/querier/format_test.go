@@ -0,0 +1,33 @@
+package querier
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	jsoniter "github.com/json-iterator/go"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormatQueryHandler(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/loki/api/v1/format_query?query="+url.QueryEscape(`sum(rate({app="foo"}|~"error"[5m]))`), nil)
+	w := httptest.NewRecorder()
+	FormatQueryHandler(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var resp FormatQueryResponse
+	require.NoError(t, jsoniter.Unmarshal(w.Body.Bytes(), &resp))
+	require.Equal(t, FormatQueryResponse{
+		Status: "success",
+		Data:   `sum(rate({app="foo"} |~ "error" [5m]))`,
+		Warnings: []string{
+			`the regex of the line filter |~ "error" has no metacharacters, use |= "error" instead`,
+		},
+	}, resp)
+
+	req = httptest.NewRequest(http.MethodGet, "/loki/api/v1/format_query?query="+url.QueryEscape(`sum(`), nil)
+	w = httptest.NewRecorder()
+	FormatQueryHandler(w, req)
+	require.Equal(t, http.StatusBadRequest, w.Code)
+}
@@ -2,10 +2,13 @@ package querier
 
 import (
 	"context"
+	"math/rand"
 	"net/http"
+	"sort"
 	"strings"
 	"time"
 
+	"github.com/go-kit/log/level"
 	"github.com/grafana/dskit/ring"
 	ring_client "github.com/grafana/dskit/ring/client"
 	"github.com/grafana/dskit/services"
@@ -33,23 +36,26 @@ type IngesterQuerier struct {
 	ring            ring.ReadRing
 	pool            *ring_client.Pool
 	extraQueryDelay time.Duration
+	// querySingleZone restricts queries to a single zone of ingesters whenever possible.
+	querySingleZone bool
 }
 
-func NewIngesterQuerier(clientCfg client.Config, ring ring.ReadRing, extraQueryDelay time.Duration) (*IngesterQuerier, error) {
+func NewIngesterQuerier(clientCfg client.Config, ring ring.ReadRing, extraQueryDelay time.Duration, querySingleZone bool) (*IngesterQuerier, error) {
 	factory := func(addr string) (ring_client.PoolClient, error) {
 		return client.New(clientCfg, addr)
 	}
 
-	return newIngesterQuerier(clientCfg, ring, extraQueryDelay, factory)
+	return newIngesterQuerier(clientCfg, ring, extraQueryDelay, querySingleZone, factory)
 }
 
 // newIngesterQuerier creates a new IngesterQuerier and allows to pass a custom ingester client factory
 // used for testing purposes
-func newIngesterQuerier(clientCfg client.Config, ring ring.ReadRing, extraQueryDelay time.Duration, clientFactory ring_client.PoolFactory) (*IngesterQuerier, error) {
+func newIngesterQuerier(clientCfg client.Config, ring ring.ReadRing, extraQueryDelay time.Duration, querySingleZone bool, clientFactory ring_client.PoolFactory) (*IngesterQuerier, error) {
 	iq := IngesterQuerier{
 		ring:            ring,
 		pool:            clientpool.NewPool(clientCfg.PoolConfig, ring, clientFactory, util_log.Logger),
 		extraQueryDelay: extraQueryDelay,
+		querySingleZone: querySingleZone,
 	}
 
 	err := services.StartAndAwaitRunning(context.Background(), iq.pool)
@@ -71,6 +77,66 @@ func (q *IngesterQuerier) forAllIngesters(ctx context.Context, f func(logproto.Q
 	return q.forGivenIngesters(ctx, replicationSet, f)
 }
 
+// forQueryIngesters runs f, in parallel, for the ingesters required to answer a query.
+// Those are all ingesters, unless querying a single zone is enabled and a zone holding a full copy of the data is healthy.
+// As every ingester of the zone is required, f runs again for all ingesters when any of them fails.
+func (q *IngesterQuerier) forQueryIngesters(ctx context.Context, f func(logproto.QuerierClient) (interface{}, error)) ([]responseFromIngesters, error) {
+	replicationSet, err := q.ring.GetReplicationSetForOperation(ring.Read)
+	if err != nil {
+		return nil, err
+	}
+
+	if q.querySingleZone {
+		if zoneSet, ok := singleZoneReplicationSet(replicationSet, q.ring.ReplicationFactor()); ok {
+			responses, err := q.forGivenIngesters(ctx, zoneSet, f)
+			if err == nil || ctx.Err() != nil {
+				return responses, err
+			}
+			level.Warn(util_log.WithContext(ctx, util_log.Logger)).Log("msg", "querying a single zone of ingesters failed, querying all zones", "zone", zoneSet.Instances[0].Zone, "err", err)
+		}
+	}
+
+	return q.forGivenIngesters(ctx, replicationSet, f)
+}
+
+// singleZoneReplicationSet picks a random zone of the given replication set whose ingesters are all ACTIVE.
+// With zone-aware replication and as many zones as replicas, every zone holds a full copy of the data.
+// The ring already excludes zones with unhealthy ingesters from the replication set for reads.
+// It returns false if the replication set is not zone-aware or no zone can be used on its own.
+func singleZoneReplicationSet(replicationSet ring.ReplicationSet, replicationFactor int) (ring.ReplicationSet, bool) {
+	zones := map[string][]ring.InstanceDesc{}
+	for _, instance := range replicationSet.Instances {
+		if instance.Zone == "" {
+			return ring.ReplicationSet{}, false
+		}
+		zones[instance.Zone] = append(zones[instance.Zone], instance)
+	}
+	if len(zones) > replicationFactor {
+		// Not every zone holds a replica of every stream.
+		return ring.ReplicationSet{}, false
+	}
+
+	candidates := make([]string, 0, len(zones))
+outer:
+	for zone, instances := range zones {
+		for _, instance := range instances {
+			// Joining and leaving ingesters may not hold all the data of their zone.
+			if instance.State != ring.ACTIVE {
+				continue outer
+			}
+		}
+		candidates = append(candidates, zone)
+	}
+	if len(candidates) == 0 {
+		return ring.ReplicationSet{}, false
+	}
+	sort.Strings(candidates)
+
+	return ring.ReplicationSet{
+		Instances: zones[candidates[rand.Intn(len(candidates))]],
+	}, true
+}
+
 // forGivenIngesters runs f, in parallel, for given ingesters
 // TODO taken from Cortex, see if we can refactor out an usable interface.
 func (q *IngesterQuerier) forGivenIngesters(ctx context.Context, replicationSet ring.ReplicationSet, f func(logproto.QuerierClient) (interface{}, error)) ([]responseFromIngesters, error) {
@@ -100,7 +166,7 @@ func (q *IngesterQuerier) forGivenIngesters(ctx context.Context, replicationSet
 }
 
 func (q *IngesterQuerier) SelectLogs(ctx context.Context, params logql.SelectLogParams) ([]iter.EntryIterator, error) {
-	resps, err := q.forAllIngesters(ctx, func(client logproto.QuerierClient) (interface{}, error) {
+	resps, err := q.forQueryIngesters(ctx, func(client logproto.QuerierClient) (interface{}, error) {
 		stats.FromContext(ctx).AddIngesterReached(1)
 		return client.Query(ctx, params.QueryRequest)
 	})
@@ -116,7 +182,7 @@ func (q *IngesterQuerier) SelectLogs(ctx context.Context, params logql.SelectLog
 }
 
 func (q *IngesterQuerier) SelectSample(ctx context.Context, params logql.SelectSampleParams) ([]iter.SampleIterator, error) {
-	resps, err := q.forAllIngesters(ctx, func(client logproto.QuerierClient) (interface{}, error) {
+	resps, err := q.forQueryIngesters(ctx, func(client logproto.QuerierClient) (interface{}, error) {
 		stats.FromContext(ctx).AddIngesterReached(1)
 		return client.QuerySample(ctx, params.SampleQueryRequest)
 	})
@@ -132,7 +198,7 @@ func (q *IngesterQuerier) SelectSample(ctx context.Context, params logql.SelectS
 }
 
 func (q *IngesterQuerier) Label(ctx context.Context, req *logproto.LabelRequest) ([][]string, error) {
-	resps, err := q.forAllIngesters(ctx, func(client logproto.QuerierClient) (interface{}, error) {
+	resps, err := q.forQueryIngesters(ctx, func(client logproto.QuerierClient) (interface{}, error) {
 		return client.Label(ctx, req)
 	})
 	if err != nil {
@@ -213,7 +279,7 @@ func (q *IngesterQuerier) TailDisconnectedIngesters(ctx context.Context, req *lo
 }
 
 func (q *IngesterQuerier) Series(ctx context.Context, req *logproto.SeriesRequest) ([][]logproto.SeriesIdentifier, error) {
-	resps, err := q.forAllIngesters(ctx, func(client logproto.QuerierClient) (interface{}, error) {
+	resps, err := q.forQueryIngesters(ctx, func(client logproto.QuerierClient) (interface{}, error) {
 		return client.Series(ctx, req)
 	})
 	if err != nil {
@@ -227,6 +293,27 @@ func (q *IngesterQuerier) Series(ctx context.Context, req *logproto.SeriesReques
 	return acc, nil
 }
 
+// Patterns returns the patterns of the streams matching the request, as returned by each ingester.
+func (q *IngesterQuerier) Patterns(ctx context.Context, req *logproto.QueryPatternsRequest) ([]*logproto.QueryPatternsResponse, error) {
+	resps, err := q.forQueryIngesters(ctx, func(client logproto.QuerierClient) (interface{}, error) {
+		patternClient, ok := client.(logproto.PatternClient)
+		if !ok {
+			return nil, errors.New("ingester client does not support patterns")
+		}
+		return patternClient.QueryPatterns(ctx, req)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]*logproto.QueryPatternsResponse, 0, len(resps))
+	for _, resp := range resps {
+		results = append(results, resp.response.(*logproto.QueryPatternsResponse))
+	}
+
+	return results, nil
+}
+
 func (q *IngesterQuerier) TailersCount(ctx context.Context) ([]uint32, error) {
 	replicationSet, err := q.ring.GetAllHealthy(ring.Read)
 	if err != nil {
@@ -268,7 +355,7 @@ func (q *IngesterQuerier) TailersCount(ctx context.Context) ([]uint32, error) {
 }
 
 func (q *IngesterQuerier) GetChunkIDs(ctx context.Context, from, through model.Time, matchers ...*labels.Matcher) ([]string, error) {
-	resps, err := q.forAllIngesters(ctx, func(querierClient logproto.QuerierClient) (interface{}, error) {
+	resps, err := q.forQueryIngesters(ctx, func(querierClient logproto.QuerierClient) (interface{}, error) {
 		return querierClient.GetChunkIDs(ctx, &logproto.GetChunkIDsRequest{
 			Matchers: convertMatchersToString(matchers),
 			Start:    from.Time(),
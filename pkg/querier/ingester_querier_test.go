@@ -2,14 +2,17 @@ package querier
 
 import (
 	"context"
+	"errors"
 	"testing"
 	"time"
 
 	"github.com/grafana/dskit/ring"
+	ring_client "github.com/grafana/dskit/ring/client"
 	"github.com/prometheus/prometheus/model/labels"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
+	"go.uber.org/atomic"
 
 	"github.com/grafana/loki/pkg/logproto"
 )
@@ -84,6 +87,7 @@ func TestQuerier_tailDisconnectedIngesters(t *testing.T) {
 				mockIngesterClientConfig(),
 				newReadRingMock(testData.ringIngesters),
 				mockQuerierConfig().ExtraQueryDelay,
+				false,
 				newIngesterClientMockFactory(ingesterClient),
 			)
 			require.NoError(t, err)
@@ -129,3 +133,125 @@ func TestConvertMatchersToString(t *testing.T) {
 		})
 	}
 }
+
+func TestIngesterQuerier_SingleZoneFallback(t *testing.T) {
+	req := &logproto.LabelRequest{Name: "app"}
+
+	// a1 is the only ingester of the only fully active zone, so it's queried first and its failure makes
+	// the querier fall back to all zones, in which one failure is allowed.
+	var failures, failuresBeforeHealthy atomic.Int32
+	failing := newQuerierClientMock()
+	failing.On("Label", mock.Anything, req, mock.Anything).Return((*logproto.LabelResponse)(nil), errors.New("ingester unavailable")).Run(func(mock.Arguments) {
+		failures.Inc()
+	})
+	healthy := newQuerierClientMock()
+	healthy.On("Label", mock.Anything, req, mock.Anything).Return(&logproto.LabelResponse{Values: []string{"foo"}}, nil).Run(func(mock.Arguments) {
+		failuresBeforeHealthy.Store(failures.Load())
+	})
+
+	readRing := newReadRingMock([]ring.InstanceDesc{
+		{Addr: "a1", Zone: "a", State: ring.ACTIVE},
+		{Addr: "b1", Zone: "b", State: ring.LEAVING},
+	})
+	readRing.replicationSet.MaxErrors = 1
+	readRing.replicationFactor = 2
+
+	ingesterQuerier, err := newIngesterQuerier(
+		mockIngesterClientConfig(),
+		readRing,
+		0,
+		true,
+		func(addr string) (ring_client.PoolClient, error) {
+			if addr == "a1" {
+				return failing, nil
+			}
+			return healthy, nil
+		},
+	)
+	require.NoError(t, err)
+
+	values, err := ingesterQuerier.Label(context.Background(), req)
+	require.NoError(t, err)
+	require.Equal(t, [][]string{{"foo"}}, values)
+	// the healthy ingester of the other zone is only queried once the single zone failed.
+	healthy.AssertNumberOfCalls(t, "Label", 1)
+	require.GreaterOrEqual(t, failuresBeforeHealthy.Load(), int32(1))
+
+	// a failure of the other zones too is returned.
+	readRing.replicationSet.MaxErrors = 0
+	_, err = ingesterQuerier.Label(context.Background(), req)
+	require.Error(t, err)
+}
+
+func TestSingleZoneReplicationSet(t *testing.T) {
+	instance := func(addr, zone string, state ring.InstanceState) ring.InstanceDesc {
+		return ring.InstanceDesc{Addr: addr, Zone: zone, State: state}
+	}
+
+	for _, tc := range []struct {
+		name              string
+		instances         []ring.InstanceDesc
+		replicationFactor int
+		expectedZones     []string
+	}{
+		{
+			name: "all zones healthy",
+			instances: []ring.InstanceDesc{
+				instance("1", "a", ring.ACTIVE), instance("2", "a", ring.ACTIVE),
+				instance("3", "b", ring.ACTIVE), instance("4", "c", ring.ACTIVE),
+			},
+			replicationFactor: 3,
+			expectedZones:     []string{"a", "b", "c"},
+		},
+		{
+			name: "zone with a joining ingester is skipped",
+			instances: []ring.InstanceDesc{
+				instance("1", "a", ring.ACTIVE), instance("2", "a", ring.JOINING),
+				instance("3", "b", ring.ACTIVE), instance("4", "c", ring.LEAVING),
+			},
+			replicationFactor: 3,
+			expectedZones:     []string{"b"},
+		},
+		{
+			name: "no zone is fully active",
+			instances: []ring.InstanceDesc{
+				instance("1", "a", ring.JOINING), instance("2", "b", ring.LEAVING),
+			},
+			replicationFactor: 2,
+		},
+		{
+			name: "ingesters without zones",
+			instances: []ring.InstanceDesc{
+				instance("1", "", ring.ACTIVE), instance("2", "", ring.ACTIVE),
+			},
+			replicationFactor: 2,
+		},
+		{
+			name: "more zones than replicas",
+			instances: []ring.InstanceDesc{
+				instance("1", "a", ring.ACTIVE), instance("2", "b", ring.ACTIVE), instance("3", "c", ring.ACTIVE),
+			},
+			replicationFactor: 2,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			set, ok := singleZoneReplicationSet(ring.ReplicationSet{Instances: tc.instances}, tc.replicationFactor)
+			if len(tc.expectedZones) == 0 {
+				require.False(t, ok)
+				return
+			}
+			require.True(t, ok)
+			require.NotEmpty(t, set.Instances)
+			zone := set.Instances[0].Zone
+			require.Contains(t, tc.expectedZones, zone)
+			for _, inst := range tc.instances {
+				if inst.Zone == zone {
+					require.Contains(t, set.Instances, inst)
+				}
+			}
+			for _, inst := range set.Instances {
+				require.Equal(t, zone, inst.Zone)
+			}
+		})
+	}
+}
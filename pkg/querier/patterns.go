@@ -0,0 +1,107 @@
+package querier
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/grafana/loki/pkg/logproto"
+	"github.com/grafana/loki/pkg/logql"
+	"github.com/grafana/loki/pkg/tenant"
+)
+
+// Patterns returns the patterns of the lines of the streams matching the request, most common first,
+// with the number of lines of each pattern in each step. Patterns are only kept by the ingesters.
+func (q *Querier) Patterns(ctx context.Context, req *logproto.QueryPatternsRequest) (*logproto.QueryPatternsResponse, error) {
+	userID, err := tenant.TenantID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Start, req.End, err = validateQueryTimeRangeLimits(ctx, userID, q.limits, req.Start, req.End); err != nil {
+		return nil, err
+	}
+
+	if _, err := logql.ParseMatchers(req.Query); err != nil {
+		return nil, err
+	}
+
+	if q.cfg.QueryStoreOnly {
+		return &logproto.QueryPatternsResponse{}, nil
+	}
+
+	// Enforce the query timeout while querying backends
+	ctx, cancel := context.WithDeadline(ctx, time.Now().Add(q.cfg.QueryTimeout))
+	defer cancel()
+
+	resps, err := q.ingesterQuerier.Patterns(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return mergePatterns(resps), nil
+}
+
+type patternSeries struct {
+	pattern string
+	total   int64
+	samples map[int64]int64
+}
+
+// mergePatterns sums the counts of the patterns across the streams, sorting the patterns by decreasing total count.
+// The counts of the replicas of a stream are only counted once, the highest count being kept for each step.
+func mergePatterns(resps []*logproto.QueryPatternsResponse) *logproto.QueryPatternsResponse {
+	byStream := map[string]*patternSeries{}
+	for _, resp := range resps {
+		for _, s := range resp.Series {
+			key := s.Labels + "\xff" + s.Pattern
+			ps, ok := byStream[key]
+			if !ok {
+				ps = &patternSeries{pattern: s.Pattern, samples: map[int64]int64{}}
+				byStream[key] = ps
+			}
+			for _, sample := range s.Samples {
+				if sample.Value > ps.samples[sample.Timestamp] {
+					ps.samples[sample.Timestamp] = sample.Value
+				}
+			}
+		}
+	}
+
+	byPattern := map[string]*patternSeries{}
+	for _, s := range byStream {
+		ps, ok := byPattern[s.pattern]
+		if !ok {
+			ps = &patternSeries{pattern: s.pattern, samples: map[int64]int64{}}
+			byPattern[s.pattern] = ps
+		}
+		for ts, v := range s.samples {
+			ps.samples[ts] += v
+			ps.total += v
+		}
+	}
+
+	series := make([]*patternSeries, 0, len(byPattern))
+	for _, ps := range byPattern {
+		series = append(series, ps)
+	}
+	sort.Slice(series, func(i, j int) bool {
+		if series[i].total != series[j].total {
+			return series[i].total > series[j].total
+		}
+		return series[i].pattern < series[j].pattern
+	})
+
+	result := &logproto.QueryPatternsResponse{Series: make([]logproto.PatternSeries, 0, len(series))}
+	for _, ps := range series {
+		samples := make([]logproto.PatternSample, 0, len(ps.samples))
+		for ts, v := range ps.samples {
+			samples = append(samples, logproto.PatternSample{Timestamp: ts, Value: v})
+		}
+		sort.Slice(samples, func(i, j int) bool { return samples[i].Timestamp < samples[j].Timestamp })
+		result.Series = append(result.Series, logproto.PatternSeries{
+			Pattern: ps.pattern,
+			Samples: samples,
+		})
+	}
+	return result
+}
@@ -0,0 +1,38 @@
+package querier
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/loki/pkg/logproto"
+)
+
+func Test_mergePatterns(t *testing.T) {
+	// the stream {pod="a"} is replicated on both ingesters, the second replica having missed a line.
+	resps := []*logproto.QueryPatternsResponse{
+		{
+			Series: []logproto.PatternSeries{
+				{Pattern: "GET <var1>", Labels: `{pod="a"}`, Samples: []logproto.PatternSample{{Timestamp: 0, Value: 2}, {Timestamp: 10, Value: 3}}},
+				{Pattern: "done<rest>", Labels: `{pod="a"}`, Samples: []logproto.PatternSample{{Timestamp: 0, Value: 1}}},
+			},
+		},
+		{
+			Series: []logproto.PatternSeries{
+				{Pattern: "GET <var1>", Labels: `{pod="a"}`, Samples: []logproto.PatternSample{{Timestamp: 0, Value: 2}, {Timestamp: 10, Value: 2}}},
+				{Pattern: "GET <var1>", Labels: `{pod="b"}`, Samples: []logproto.PatternSample{{Timestamp: 10, Value: 1}, {Timestamp: 20, Value: 4}}},
+				{Pattern: "error<rest>", Labels: `{pod="b"}`, Samples: []logproto.PatternSample{{Timestamp: 20, Value: 1}}},
+			},
+		},
+	}
+
+	require.Equal(t, &logproto.QueryPatternsResponse{
+		Series: []logproto.PatternSeries{
+			{Pattern: "GET <var1>", Samples: []logproto.PatternSample{{Timestamp: 0, Value: 2}, {Timestamp: 10, Value: 4}, {Timestamp: 20, Value: 4}}},
+			{Pattern: "done<rest>", Samples: []logproto.PatternSample{{Timestamp: 0, Value: 1}}},
+			{Pattern: "error<rest>", Samples: []logproto.PatternSample{{Timestamp: 20, Value: 1}}},
+		},
+	}, mergePatterns(resps))
+
+	require.Equal(t, &logproto.QueryPatternsResponse{Series: []logproto.PatternSeries{}}, mergePatterns(nil))
+}
@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/model/labels"
 	"github.com/weaveworks/common/httpgrpc"
 	"google.golang.org/grpc/health/grpc_health_v1"
 
@@ -50,6 +51,7 @@ type Config struct {
 	MaxConcurrent                 int              `yaml:"max_concurrent"`
 	QueryStoreOnly                bool             `yaml:"query_store_only"`
 	QueryIngesterOnly             bool             `yaml:"query_ingester_only"`
+	QueryIngestersSingleZone      bool             `yaml:"query_ingesters_single_zone"`
 }
 
 // RegisterFlags register flags.
@@ -62,6 +64,7 @@ func (cfg *Config) RegisterFlags(f *flag.FlagSet) {
 	f.IntVar(&cfg.MaxConcurrent, "querier.max-concurrent", 10, "The maximum number of concurrent queries.")
 	f.BoolVar(&cfg.QueryStoreOnly, "querier.query-store-only", false, "Queriers should only query the store and not try to query any ingesters")
 	f.BoolVar(&cfg.QueryIngesterOnly, "querier.query-ingester-only", false, "Queriers should only query the ingesters and not try to query any store")
+	f.BoolVar(&cfg.QueryIngestersSingleZone, "querier.query-ingesters-single-zone", false, "Query only the ingesters of a single zone when all of them are healthy, falling back to all zones otherwise or when an ingester of the zone fails. Requires zone-aware replication with as many zones as the replication factor.")
 }
 
 // Validate validates the config.
@@ -275,6 +278,14 @@ func (q *Querier) Label(ctx context.Context, req *logproto.LabelRequest) (*logpr
 		return nil, err
 	}
 
+	var matchers []*labels.Matcher
+	if req.Query != "" {
+		matchers, err = logql.ParseMatchers(req.Query)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	// Enforce the query timeout while querying backends
 	ctx, cancel := context.WithDeadline(ctx, time.Now().Add(q.cfg.QueryTimeout))
 	defer cancel()
@@ -291,12 +302,12 @@ func (q *Querier) Label(ctx context.Context, req *logproto.LabelRequest) (*logpr
 	if !q.cfg.QueryIngesterOnly {
 		from, through := model.TimeFromUnixNano(req.Start.UnixNano()), model.TimeFromUnixNano(req.End.UnixNano())
 		if req.Values {
-			storeValues, err = q.store.LabelValuesForMetricName(ctx, userID, from, through, "logs", req.Name)
+			storeValues, err = q.store.LabelValuesForMetricName(ctx, userID, from, through, "logs", req.Name, matchers...)
 			if err != nil {
 				return nil, err
 			}
 		} else {
-			storeValues, err = q.store.LabelNamesForMetricName(ctx, userID, from, through, "logs")
+			storeValues, err = q.store.LabelNamesForMetricName(ctx, userID, from, through, "logs", matchers...)
 			if err != nil {
 				return nil, err
 			}
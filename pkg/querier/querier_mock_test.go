@@ -22,6 +22,7 @@ import (
 	"github.com/grafana/loki/pkg/logproto"
 	"github.com/grafana/loki/pkg/logql"
 	"github.com/grafana/loki/pkg/storage"
+	"github.com/grafana/loki/pkg/storage/bloom"
 	"github.com/grafana/loki/pkg/storage/chunk"
 	"github.com/grafana/loki/pkg/util"
 )
@@ -209,6 +210,8 @@ func newStoreMock() *storeMock {
 
 func (s *storeMock) SetChunkFilterer(storage.RequestChunkFilterer) {}
 
+func (s *storeMock) SetBloomStore(*bloom.Store) {}
+
 func (s *storeMock) SelectLogs(ctx context.Context, req logql.SelectLogParams) (iter.EntryIterator, error) {
 	args := s.Called(ctx, req)
 	res := args.Get(0)
@@ -250,7 +253,7 @@ func (s *storeMock) LabelValuesForMetricName(ctx context.Context, userID string,
 	return args.Get(0).([]string), args.Error(1)
 }
 
-func (s *storeMock) LabelNamesForMetricName(ctx context.Context, userID string, from, through model.Time, metricName string) ([]string, error) {
+func (s *storeMock) LabelNamesForMetricName(ctx context.Context, userID string, from, through model.Time, metricName string, matchers ...*labels.Matcher) ([]string, error) {
 	args := s.Called(ctx, userID, from, through, metricName)
 	return args.Get(0).([]string), args.Error(1)
 }
@@ -286,7 +289,8 @@ func (s *storeMock) Stop() {
 // readRingMock is a mocked version of a ReadRing, used in querier unit tests
 // to control the pool of ingesters available
 type readRingMock struct {
-	replicationSet ring.ReplicationSet
+	replicationSet    ring.ReplicationSet
+	replicationFactor int
 }
 
 func newReadRingMock(ingesters []ring.InstanceDesc) *readRingMock {
@@ -329,7 +333,10 @@ func (r *readRingMock) GetReplicationSetForOperation(op ring.Operation) (ring.Re
 }
 
 func (r *readRingMock) ReplicationFactor() int {
-	return 1
+	if r.replicationFactor == 0 {
+		return 1
+	}
+	return r.replicationFactor
 }
 
 func (r *readRingMock) InstancesCount() int {
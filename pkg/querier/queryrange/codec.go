@@ -173,19 +173,17 @@ func (r *LokiLabelNamesRequest) WithStartEnd(s int64, e int64) queryrangebase.Re
 
 func (r *LokiLabelNamesRequest) WithQuery(query string) queryrangebase.Request {
 	new := *r
+	new.Query = query
 	return &new
 }
 
-func (r *LokiLabelNamesRequest) GetQuery() string {
-	return ""
-}
-
 func (r *LokiLabelNamesRequest) GetStep() int64 {
 	return 0
 }
 
 func (r *LokiLabelNamesRequest) LogToSpan(sp opentracing.Span) {
 	sp.LogFields(
+		otlog.String("query", r.GetQuery()),
 		otlog.String("start", timestamp.Time(r.GetStart()).String()),
 		otlog.String("end", timestamp.Time(r.GetEnd()).String()),
 	)
@@ -249,6 +247,7 @@ func (Codec) DecodeRequest(_ context.Context, r *http.Request, forwardHeaders []
 			StartTs: *req.Start,
 			EndTs:   *req.End,
 			Path:    r.URL.Path,
+			Query:   req.Query,
 		}, nil
 	default:
 		return nil, httpgrpc.Errorf(http.StatusBadRequest, fmt.Sprintf("unknown request path: %s", r.URL.Path))
@@ -317,6 +316,9 @@ func (Codec) EncodeRequest(ctx context.Context, r queryrangebase.Request) (*http
 			"start": []string{fmt.Sprintf("%d", request.StartTs.UnixNano())},
 			"end":   []string{fmt.Sprintf("%d", request.EndTs.UnixNano())},
 		}
+		if request.Query != "" {
+			params["query"] = []string{request.Query}
+		}
 
 		u := &url.URL{
 			Path:     "/loki/api/v1/labels",
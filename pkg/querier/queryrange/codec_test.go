@@ -78,6 +78,15 @@ func Test_codec_DecodeRequest(t *testing.T) {
 			StartTs: start,
 			EndTs:   end,
 		}, false},
+		{"labels with query", func() (*http.Request, error) {
+			return http.NewRequest(http.MethodGet,
+				fmt.Sprintf(`/label?start=%d&end=%d&query={foo="bar"}`, start.UnixNano(), end.UnixNano()), nil)
+		}, &LokiLabelNamesRequest{
+			Path:    "/label",
+			StartTs: start,
+			EndTs:   end,
+			Query:   `{foo="bar"}`,
+		}, false},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -286,6 +295,7 @@ func Test_codec_labels_EncodeRequest(t *testing.T) {
 		Path:    "/loki/api/v1/labels",
 		StartTs: start,
 		EndTs:   end,
+		Query:   `{foo="bar"}`,
 	}
 	got, err := LokiCodec.EncodeRequest(ctx, toEncode)
 	require.NoError(t, err)
@@ -293,6 +303,7 @@ func Test_codec_labels_EncodeRequest(t *testing.T) {
 	require.Equal(t, "/loki/api/v1/labels", got.URL.Path)
 	require.Equal(t, fmt.Sprintf("%d", start.UnixNano()), got.URL.Query().Get("start"))
 	require.Equal(t, fmt.Sprintf("%d", end.UnixNano()), got.URL.Query().Get("end"))
+	require.Equal(t, `{foo="bar"}`, got.URL.Query().Get("query"))
 
 	// testing a full roundtrip
 	req, err := LokiCodec.DecodeRequest(context.TODO(), got, nil)
@@ -300,6 +311,7 @@ func Test_codec_labels_EncodeRequest(t *testing.T) {
 	require.Equal(t, toEncode.StartTs, req.(*LokiLabelNamesRequest).StartTs)
 	require.Equal(t, toEncode.EndTs, req.(*LokiLabelNamesRequest).EndTs)
 	require.Equal(t, "/loki/api/v1/labels", req.(*LokiLabelNamesRequest).Path)
+	require.Equal(t, toEncode.Query, req.(*LokiLabelNamesRequest).Query)
 }
 
 func Test_codec_EncodeResponse(t *testing.T) {
@@ -878,7 +890,9 @@ var (
 				},
 				"chunksDownloadTime": 0,
 				"totalChunksRef": 0,
-				"totalChunksDownloaded": 0
+				"totalChunksDownloaded": 0,
+				"totalBloomFiltersDownloaded": 0,
+				"totalChunksSkippedByBloom": 0
 			},
 			"totalBatches": 6,
 			"totalChunksMatched": 7,
@@ -897,7 +911,9 @@ var (
 				},
 				"chunksDownloadTime": 16,
 				"totalChunksRef": 17,
-				"totalChunksDownloaded": 18
+				"totalChunksDownloaded": 18,
+				"totalBloomFiltersDownloaded": 0,
+				"totalChunksSkippedByBloom": 0
 			}
 		},
 		"summary": {
@@ -0,0 +1,112 @@
+package queryrange
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/prometheus/common/model"
+
+	"github.com/grafana/loki/pkg/logql"
+	"github.com/grafana/loki/pkg/querier/queryrange/queryrangebase"
+	"github.com/grafana/loki/pkg/storage/chunk"
+	"github.com/grafana/loki/pkg/tenant"
+)
+
+// explainShardingMetrics are the metrics of the shard mappers used to explain queries, which are not registered
+// for the explained queries not to be counted as executed ones.
+var explainShardingMetrics = logql.NewShardingMetrics(nil)
+
+// SplitPlan is the query the frontend sends for a part of the range of a query.
+type SplitPlan struct {
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+	// Shards is the plan of the sharded query of the split, nil when the split is not sharded for NotShardedReason.
+	Shards           *logql.ExplainedExpr `json:"shards,omitempty"`
+	NotShardedReason string               `json:"not_sharded_reason,omitempty"`
+}
+
+// Plan describes how the frontend splits a range query and shards its splits.
+type Plan struct {
+	// SplitInterval is the split interval of the tenant, zero when the query is not split.
+	// Metric queries with longer range vectors are split by their range instead.
+	SplitInterval model.Duration `json:"split_interval"`
+	Splits        []SplitPlan    `json:"splits"`
+}
+
+// ExplainRangeQuery returns how the frontend splits and shards a range query. It makes the same decisions as the
+// middlewares of the tripperware, with the functions they share.
+func ExplainRangeQuery(ctx context.Context, cfg Config, schema chunk.SchemaConfig, limits Limits, req *LokiRequest, now time.Time) (*Plan, error) {
+	userID, err := tenant.TenantID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	expr, err := logql.ParseExpr(req.Query)
+	if err != nil {
+		return nil, err
+	}
+	limits = WithDefaultLimits(limits, cfg.Config)
+
+	plan := &Plan{}
+	splitter, ok := rangeQuerySplitter(expr)
+	if !ok {
+		plan.Splits = append(plan.Splits, SplitPlan{
+			Start:            req.StartTs,
+			End:              req.EndTs,
+			NotShardedReason: "log queries without line filters are sent to a querier as is",
+		})
+		return plan, nil
+	}
+
+	var r queryrangebase.Request = req
+	if _, ok := expr.(logql.SampleExpr); ok && cfg.AlignQueriesWithStep {
+		r = queryrangebase.StepAlign(r)
+	}
+	reqs, split, err := splitRequest(limits, userID, splitter, r)
+	if err != nil {
+		return nil, err
+	}
+	if split {
+		plan.SplitInterval = model.Duration(limits.QuerySplitDuration(userID))
+	}
+	if len(reqs) == 0 {
+		reqs = []queryrangebase.Request{r}
+	}
+
+	for _, r := range reqs {
+		split, err := explainShards(cfg, schema, limits, userID, r.(*LokiRequest), now)
+		if err != nil {
+			return nil, err
+		}
+		plan.Splits = append(plan.Splits, split)
+	}
+	return plan, nil
+}
+
+// explainShards returns how a split is sharded by the shardSplitter and the astMapperware.
+func explainShards(cfg Config, schema chunk.SchemaConfig, limits Limits, userID string, req *LokiRequest, now time.Time) (SplitPlan, error) {
+	split := SplitPlan{Start: req.StartTs, End: req.EndTs}
+
+	confs := ShardingConfigs(schema.Configs)
+	if !cfg.ShardedQueries || !hasShards(confs) {
+		split.NotShardedReason = "sharding is disabled"
+		return split, nil
+	}
+	if withinMinShardingLookback(limits, userID, req, now) {
+		split.NotShardedReason = "the split ends within the min sharding lookback"
+		return split, nil
+	}
+
+	mapped, err := shardQuery(confs, req, explainShardingMetrics, cfg.quantileSketchAccuracy())
+	var notSharded notShardedError
+	switch {
+	case errors.As(err, &notSharded):
+		split.NotShardedReason = notSharded.Error()
+		return split, nil
+	case err != nil:
+		return split, err
+	}
+	shards := logql.ExplainShards(mapped)
+	split.Shards = &shards
+	return split, nil
+}
@@ -0,0 +1,140 @@
+package queryrange
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+	"github.com/weaveworks/common/user"
+
+	"github.com/grafana/loki/pkg/logql"
+	"github.com/grafana/loki/pkg/querier/queryrange/queryrangebase"
+	"github.com/grafana/loki/pkg/storage/chunk"
+)
+
+func Test_ExplainRangeQuery(t *testing.T) {
+	ctx := user.InjectOrgID(context.Background(), "1")
+	start := time.Unix(0, 0).UTC()
+	now := start.Add(24 * time.Hour)
+	cfg := Config{Config: queryrangebase.Config{ShardedQueries: true}}
+	schema := chunk.SchemaConfig{Configs: []chunk.PeriodConfig{{RowShards: 2}}}
+	limits := fakeLimits{splits: map[string]time.Duration{"1": time.Hour}, minShardingLookback: 3 * time.Hour}
+
+	for _, tc := range []struct {
+		name     string
+		cfg      Config
+		req      *LokiRequest
+		expected *Plan
+	}{
+		{
+			name: "metric query",
+			cfg:  cfg,
+			req: &LokiRequest{
+				Query:   `sum(rate({app="foo"}[1m]))`,
+				StartTs: start.Add(20 * time.Hour),
+				EndTs:   start.Add(22 * time.Hour),
+				Step:    (30 * time.Minute).Milliseconds(),
+			},
+			expected: &Plan{
+				SplitInterval: model.Duration(time.Hour),
+				Splits: []SplitPlan{
+					{
+						Start: start.Add(20 * time.Hour),
+						End:   start.Add(20*time.Hour + 30*time.Minute),
+						Shards: &logql.ExplainedExpr{
+							Type:      logql.ExplainVectorAggregation,
+							Operation: logql.OpTypeSum,
+							Expr:      `sum(downstream<sum(rate({app="foo"}[1m])), shard=0_of_2> ++ downstream<sum(rate({app="foo"}[1m])), shard=1_of_2>)`,
+							Children: []logql.ExplainedExpr{
+								{
+									Type: logql.ExplainConcat,
+									Expr: `downstream<sum(rate({app="foo"}[1m])), shard=0_of_2> ++ downstream<sum(rate({app="foo"}[1m])), shard=1_of_2>`,
+									Children: []logql.ExplainedExpr{
+										{Type: logql.ExplainDownstream, Expr: `sum(rate({app="foo"}[1m]))`, Shard: "0_of_2"},
+										{Type: logql.ExplainDownstream, Expr: `sum(rate({app="foo"}[1m]))`, Shard: "1_of_2"},
+									},
+								},
+							},
+						},
+					},
+					{
+						Start:            start.Add(21 * time.Hour),
+						End:              start.Add(22 * time.Hour),
+						NotShardedReason: "the split ends within the min sharding lookback",
+					},
+				},
+			},
+		},
+		{
+			name: "not shardable",
+			cfg:  cfg,
+			req: &LokiRequest{
+				Query:   `max_over_time({app="foo"} | unwrap latency [1h])`,
+				StartTs: start,
+				EndTs:   start.Add(time.Hour),
+				Step:    (30 * time.Minute).Milliseconds(),
+			},
+			expected: &Plan{
+				SplitInterval: model.Duration(time.Hour),
+				Splits: []SplitPlan{
+					{Start: start, End: start.Add(time.Hour), NotShardedReason: "the query is not shardable"},
+				},
+			},
+		},
+		{
+			name: "sharding disabled",
+			req: &LokiRequest{
+				Query:   `{app="foo"} |= "err"`,
+				StartTs: start.Add(30 * time.Minute),
+				EndTs:   start.Add(90 * time.Minute),
+			},
+			expected: &Plan{
+				SplitInterval: model.Duration(time.Hour),
+				Splits: []SplitPlan{
+					{Start: start.Add(30 * time.Minute), End: start.Add(time.Hour), NotShardedReason: "sharding is disabled"},
+					{Start: start.Add(time.Hour), End: start.Add(90 * time.Minute), NotShardedReason: "sharding is disabled"},
+				},
+			},
+		},
+		{
+			name: "step aligned metric query with a range longer than the split interval",
+			cfg:  Config{Config: queryrangebase.Config{AlignQueriesWithStep: true}},
+			req: &LokiRequest{
+				Query:   `count_over_time({app="foo"}[2h])`,
+				StartTs: start.Add(10 * time.Minute),
+				EndTs:   start.Add(5*time.Hour + 10*time.Minute),
+				Step:    time.Hour.Milliseconds(),
+			},
+			expected: &Plan{
+				SplitInterval: model.Duration(time.Hour),
+				Splits: []SplitPlan{
+					{Start: start, End: start.Add(time.Hour), NotShardedReason: "sharding is disabled"},
+					{Start: start.Add(2 * time.Hour), End: start.Add(3 * time.Hour), NotShardedReason: "sharding is disabled"},
+					{Start: start.Add(4 * time.Hour), End: start.Add(5 * time.Hour), NotShardedReason: "sharding is disabled"},
+				},
+			},
+		},
+		{
+			name: "log query without filter",
+			cfg:  cfg,
+			req: &LokiRequest{
+				Query:   `{app="foo"}`,
+				StartTs: start,
+				EndTs:   start.Add(2 * time.Hour),
+			},
+			expected: &Plan{
+				Splits: []SplitPlan{
+					{Start: start, End: start.Add(2 * time.Hour), NotShardedReason: "log queries without line filters are sent to a querier as is"},
+				},
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			plan, err := ExplainRangeQuery(ctx, tc.cfg, schema, limits, tc.req, now)
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, plan)
+		})
+	}
+}
@@ -18,6 +18,8 @@ var emptyStats = `"stats": {
 			"chunksDownloadTime": 0,
 			"totalChunksRef": 0,
 			"totalChunksDownloaded": 0,
+			"totalBloomFiltersDownloaded": 0,
+			"totalChunksSkippedByBloom": 0,
 			"chunk" :{
 				"compressedBytes": 0,
 				"decompressedBytes": 0,
@@ -37,6 +39,8 @@ var emptyStats = `"stats": {
 			"chunksDownloadTime": 0,
 			"totalChunksRef": 0,
 			"totalChunksDownloaded": 0,
+			"totalBloomFiltersDownloaded": 0,
+			"totalChunksSkippedByBloom": 0,
 			"chunk" :{
 				"compressedBytes": 0,
 				"decompressedBytes": 0,
@@ -455,6 +455,7 @@ type LokiLabelNamesRequest struct {
 	StartTs time.Time `protobuf:"bytes,1,opt,name=startTs,proto3,stdtime" json:"startTs"`
 	EndTs   time.Time `protobuf:"bytes,2,opt,name=endTs,proto3,stdtime" json:"endTs"`
 	Path    string    `protobuf:"bytes,3,opt,name=path,proto3" json:"path,omitempty"`
+	Query   string    `protobuf:"bytes,4,opt,name=query,proto3" json:"query,omitempty"`
 }
 
 func (m *LokiLabelNamesRequest) Reset()      { *m = LokiLabelNamesRequest{} }
@@ -510,6 +511,13 @@ func (m *LokiLabelNamesRequest) GetPath() string {
 	return ""
 }
 
+func (m *LokiLabelNamesRequest) GetQuery() string {
+	if m != nil {
+		return m.Query
+	}
+	return ""
+}
+
 type LokiLabelNamesResponse struct {
 	Status  string                                                                                   `protobuf:"bytes,1,opt,name=Status,proto3" json:"status"`
 	Data    []string                                                                                 `protobuf:"bytes,2,rep,name=Data,proto3" json:"data,omitempty"`
@@ -683,64 +691,64 @@ func init() {
 }
 
 var fileDescriptor_51b9d53b40d11902 = []byte{
-	// 905 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xdc, 0x54, 0x4f, 0x6f, 0x23, 0x35,
-	0x14, 0x8f, 0xf3, 0xb7, 0x71, 0xd9, 0x02, 0xee, 0xb2, 0x3b, 0x2a, 0xd2, 0x4c, 0x94, 0x03, 0x04,
-	0xc1, 0x4e, 0x44, 0x17, 0x38, 0x20, 0x40, 0xec, 0xa8, 0x20, 0x56, 0x5a, 0x21, 0xe4, 0x8d, 0xb8,
-	0x22, 0x27, 0x71, 0x27, 0xa3, 0xce, 0x8c, 0xa7, 0xb6, 0x83, 0xd4, 0x1b, 0x5f, 0x00, 0x69, 0x3f,
-	0x03, 0x20, 0x81, 0x38, 0xf3, 0x01, 0x38, 0xf6, 0xd8, 0xe3, 0x6a, 0x25, 0x06, 0x9a, 0x5e, 0x20,
-	0xa7, 0xfd, 0x08, 0xc8, 0xf6, 0x4c, 0xe2, 0x2c, 0x2d, 0x6d, 0xb6, 0x17, 0xc4, 0x25, 0xf1, 0x7b,
-	0x7e, 0xcf, 0xf3, 0x7e, 0xbf, 0xf7, 0x7b, 0x0f, 0xbe, 0x9e, 0x1d, 0x84, 0xfd, 0xc3, 0x29, 0xe5,
-	0x11, 0xe5, 0xfa, 0xff, 0x88, 0x93, 0x34, 0xa4, 0xd6, 0xd1, 0xcf, 0x38, 0x93, 0x0c, 0xc1, 0xa5,
-	0x67, 0xe7, 0x4e, 0x18, 0xc9, 0xc9, 0x74, 0xe8, 0x8f, 0x58, 0xd2, 0x0f, 0x59, 0xc8, 0xfa, 0x3a,
-	0x64, 0x38, 0xdd, 0xd7, 0x96, 0x36, 0xf4, 0xc9, 0xa4, 0xee, 0xbc, 0xaa, 0xbe, 0x11, 0xb3, 0xd0,
-	0x5c, 0x94, 0x87, 0xe2, 0xb2, 0x53, 0x5c, 0x1e, 0xc6, 0x09, 0x1b, 0xd3, 0xb8, 0x2f, 0x24, 0x91,
-	0xc2, 0xfc, 0x16, 0x11, 0xef, 0x5d, 0x5a, 0xe2, 0x90, 0x88, 0x7f, 0x56, 0xbc, 0xe3, 0x85, 0x8c,
-	0x85, 0x31, 0x5d, 0x16, 0x27, 0xa3, 0x84, 0x0a, 0x49, 0x92, 0xcc, 0x04, 0x74, 0x7f, 0xa9, 0xc2,
-	0xcd, 0x07, 0xec, 0x20, 0xc2, 0xf4, 0x70, 0x4a, 0x85, 0x44, 0x37, 0x61, 0x43, 0x3f, 0xe2, 0x80,
-	0x0e, 0xe8, 0xb5, 0xb1, 0x31, 0x94, 0x37, 0x8e, 0x92, 0x48, 0x3a, 0xd5, 0x0e, 0xe8, 0xdd, 0xc0,
-	0xc6, 0x40, 0x08, 0xd6, 0x85, 0xa4, 0x99, 0x53, 0xeb, 0x80, 0x5e, 0x0d, 0xeb, 0x33, 0xfa, 0x08,
-	0xb6, 0x84, 0x24, 0x5c, 0x0e, 0x84, 0x53, 0xef, 0x80, 0xde, 0xe6, 0xee, 0x8e, 0x6f, 0x4a, 0xf0,
-	0xcb, 0x12, 0xfc, 0x41, 0x59, 0x42, 0xb0, 0x71, 0x9c, 0x7b, 0x95, 0x47, 0xbf, 0x7b, 0x00, 0x97,
-	0x49, 0xe8, 0x7d, 0xd8, 0xa0, 0xe9, 0x78, 0x20, 0x9c, 0xc6, 0x1a, 0xd9, 0x26, 0x05, 0xbd, 0x0d,
-	0xdb, 0xe3, 0x88, 0xd3, 0x91, 0x8c, 0x58, 0xea, 0x34, 0x3b, 0xa0, 0xb7, 0xb5, 0xbb, 0xed, 0x2f,
-	0xa8, 0xde, 0x2b, 0xaf, 0xf0, 0x32, 0x4a, 0x41, 0xc8, 0x88, 0x9c, 0x38, 0x2d, 0x8d, 0x56, 0x9f,
-	0x51, 0x17, 0x36, 0xc5, 0x84, 0xf0, 0xb1, 0x70, 0x36, 0x3a, 0xb5, 0x5e, 0x3b, 0x80, 0xf3, 0xdc,
-	0x2b, 0x3c, 0xb8, 0xf8, 0xef, 0xfe, 0x05, 0x20, 0x52, 0xb4, 0xdd, 0x4f, 0x85, 0x24, 0xa9, 0x7c,
-	0x1e, 0xf6, 0x3e, 0x80, 0x4d, 0xd5, 0x8c, 0x81, 0xd0, 0xfc, 0x5d, 0x15, 0x6a, 0x91, 0xb3, 0x8a,
-	0xb5, 0xbe, 0x16, 0xd6, 0xc6, 0xb9, 0x58, 0x9b, 0x17, 0x62, 0xfd, 0xae, 0x0e, 0x5f, 0x30, 0x12,
-	0x11, 0x19, 0x4b, 0x05, 0x55, 0x49, 0x0f, 0x25, 0x91, 0x53, 0x61, 0x60, 0x16, 0x49, 0xda, 0x83,
-	0x8b, 0x1b, 0xf4, 0x31, 0xac, 0xef, 0x11, 0x49, 0x34, 0xe4, 0xcd, 0xdd, 0x9b, 0xbe, 0xa5, 0x4c,
-	0xf5, 0x96, 0xba, 0x0b, 0x6e, 0x29, 0x54, 0xf3, 0xdc, 0xdb, 0x1a, 0x13, 0x49, 0xde, 0x62, 0x49,
-	0x24, 0x69, 0x92, 0xc9, 0x23, 0xac, 0x33, 0xd1, 0xbb, 0xb0, 0xfd, 0x09, 0xe7, 0x8c, 0x0f, 0x8e,
-	0x32, 0xaa, 0x29, 0x6a, 0x07, 0xb7, 0xe7, 0xb9, 0xb7, 0x4d, 0x4b, 0xa7, 0x95, 0xb1, 0x8c, 0x44,
-	0x6f, 0xc0, 0x86, 0x36, 0x34, 0x29, 0xed, 0x60, 0x7b, 0x9e, 0x7b, 0x2f, 0xea, 0x14, 0x2b, 0xdc,
-	0x44, 0xac, 0x72, 0xd8, 0xb8, 0x12, 0x87, 0x8b, 0x56, 0x36, 0xed, 0x56, 0x3a, 0xb0, 0xf5, 0x35,
-	0xe5, 0x42, 0x3d, 0xd3, 0xd2, 0xfe, 0xd2, 0x44, 0xf7, 0x20, 0x54, 0xc4, 0x44, 0x42, 0x46, 0x23,
-	0xa5, 0x27, 0x45, 0xc6, 0x0d, 0xdf, 0x4c, 0x36, 0xa6, 0x62, 0x1a, 0xcb, 0x00, 0x15, 0x2c, 0x58,
-	0x81, 0xd8, 0x3a, 0xa3, 0xef, 0x01, 0x6c, 0x7d, 0x46, 0xc9, 0x98, 0x72, 0xe1, 0xb4, 0x3b, 0xb5,
-	0xde, 0xe6, 0x6e, 0xcf, 0x5f, 0x1d, 0x7b, 0xff, 0x0b, 0xce, 0x12, 0x2a, 0x27, 0x74, 0x2a, 0xca,
-	0x1e, 0x99, 0x84, 0xe0, 0xab, 0x27, 0xb9, 0xf7, 0xa5, 0xbd, 0xa8, 0x38, 0xd9, 0x27, 0x29, 0xe9,
-	0xc7, 0xec, 0x20, 0xea, 0x5f, 0x69, 0xa5, 0x5c, 0xf8, 0xf6, 0x3c, 0xf7, 0xc0, 0x1d, 0x5c, 0x56,
-	0xd6, 0xfd, 0x0d, 0xc0, 0x97, 0x55, 0x63, 0x1f, 0xaa, 0xf7, 0x84, 0x35, 0x0f, 0x09, 0x91, 0xa3,
-	0x89, 0x03, 0x94, 0xba, 0xb0, 0x31, 0xec, 0x1d, 0x51, 0xbd, 0xd6, 0x8e, 0xa8, 0xad, 0xbf, 0x23,
-	0xca, 0x21, 0xa8, 0x9f, 0x3b, 0x04, 0x8d, 0x0b, 0x87, 0xe0, 0xd7, 0xaa, 0x19, 0xf8, 0x12, 0xdf,
-	0x1a, 0xa3, 0xf0, 0xe9, 0x62, 0x14, 0x6a, 0xba, 0xda, 0x85, 0xc2, 0xcc, 0x5b, 0xf7, 0xc7, 0x34,
-	0x95, 0xd1, 0x7e, 0x44, 0xf9, 0x25, 0x03, 0x61, 0xa9, 0xac, 0xb6, 0xaa, 0x32, 0x5b, 0x22, 0xf5,
-	0xff, 0xac, 0x44, 0x7e, 0x04, 0xf0, 0x15, 0x45, 0xe1, 0x03, 0x32, 0xa4, 0xf1, 0xe7, 0x24, 0x59,
-	0xca, 0xc4, 0x12, 0x04, 0xb8, 0x96, 0x20, 0xaa, 0xcf, 0x2f, 0x88, 0xda, 0x52, 0x10, 0xdd, 0x1f,
-	0xaa, 0xf0, 0xd6, 0xb3, 0x95, 0xae, 0xd1, 0xf0, 0xd7, 0xac, 0x86, 0xb7, 0x03, 0xf4, 0xbf, 0x6d,
-	0xe8, 0xcf, 0x00, 0x6e, 0x94, 0xcb, 0x1c, 0xf9, 0x10, 0x9a, 0x85, 0xa6, 0xf7, 0xb5, 0x21, 0x67,
-	0x4b, 0xad, 0x35, 0xbe, 0xf0, 0x62, 0x2b, 0x02, 0xa5, 0xb0, 0x69, 0xac, 0x62, 0x2e, 0x6e, 0x5b,
-	0x73, 0x21, 0x39, 0x25, 0xc9, 0xbd, 0x31, 0xc9, 0x24, 0xe5, 0xc1, 0x87, 0xaa, 0x63, 0x4f, 0x72,
-	0xef, 0xcd, 0x7f, 0xc3, 0xf4, 0x4c, 0xae, 0x6a, 0x8a, 0xf9, 0x2e, 0x2e, 0xbe, 0xd2, 0xfd, 0x16,
-	0xc0, 0x97, 0x54, 0xb1, 0x0a, 0xdb, 0xa2, 0x9b, 0x7b, 0x70, 0x83, 0x17, 0xe7, 0x42, 0x79, 0xdd,
-	0xcb, 0x79, 0x0e, 0xea, 0xc7, 0xb9, 0x07, 0xf0, 0x22, 0x13, 0xdd, 0x5d, 0x59, 0xf2, 0xd5, 0xf3,
-	0x96, 0xbc, 0x4a, 0xa9, 0xd8, 0x6b, 0x3d, 0x78, 0xe7, 0xe4, 0xd4, 0xad, 0x3c, 0x3e, 0x75, 0x2b,
-	0x4f, 0x4f, 0x5d, 0xf0, 0xcd, 0xcc, 0x05, 0x3f, 0xcd, 0x5c, 0x70, 0x3c, 0x73, 0xc1, 0xc9, 0xcc,
-	0x05, 0x7f, 0xcc, 0x5c, 0xf0, 0xe7, 0xcc, 0xad, 0x3c, 0x9d, 0xb9, 0xe0, 0xd1, 0x99, 0x5b, 0x39,
-	0x39, 0x73, 0x2b, 0x8f, 0xcf, 0xdc, 0xca, 0xb0, 0xa9, 0x51, 0xde, 0xfd, 0x3b, 0x00, 0x00, 0xff,
-	0xff, 0x23, 0x71, 0xde, 0xa2, 0xb3, 0x0a, 0x00, 0x00,
+	// 907 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xdc, 0x54, 0x41, 0x6f, 0x1b, 0x45,
+	0x14, 0xf6, 0x78, 0xd7, 0x76, 0x3c, 0xa1, 0x01, 0x26, 0xa5, 0x5d, 0x05, 0x69, 0xd7, 0xf2, 0x01,
+	0x8c, 0xa0, 0x6b, 0x91, 0x02, 0x07, 0x04, 0x88, 0xae, 0x02, 0xa2, 0x52, 0x85, 0xd0, 0xd4, 0xe2,
+	0x8a, 0xc6, 0xf1, 0xc4, 0x5e, 0xc5, 0xbb, 0xb3, 0x99, 0x19, 0x23, 0xe5, 0xc6, 0x1f, 0x40, 0xea,
+	0x6f, 0x00, 0x0e, 0x88, 0x33, 0x57, 0x24, 0x8e, 0x39, 0xe6, 0x58, 0x55, 0x62, 0x21, 0xce, 0x05,
+	0x7c, 0xea, 0x4f, 0x40, 0x33, 0xb3, 0xbb, 0x1e, 0x97, 0x84, 0xd4, 0xed, 0x05, 0x71, 0xb1, 0xe7,
+	0xbd, 0x79, 0x6f, 0xf6, 0x7d, 0xef, 0x7d, 0xef, 0x83, 0xaf, 0x67, 0x87, 0xe3, 0xfe, 0xd1, 0x8c,
+	0xf2, 0x98, 0x72, 0xfd, 0x7f, 0xcc, 0x49, 0x3a, 0xa6, 0xd6, 0x31, 0xcc, 0x38, 0x93, 0x0c, 0xc1,
+	0xa5, 0x67, 0xe7, 0xd6, 0x38, 0x96, 0x93, 0xd9, 0x30, 0xdc, 0x67, 0x49, 0x7f, 0xcc, 0xc6, 0xac,
+	0xaf, 0x43, 0x86, 0xb3, 0x03, 0x6d, 0x69, 0x43, 0x9f, 0x4c, 0xea, 0xce, 0xab, 0xea, 0x1b, 0x53,
+	0x36, 0x36, 0x17, 0xe5, 0xa1, 0xb8, 0xec, 0x14, 0x97, 0x47, 0xd3, 0x84, 0x8d, 0xe8, 0xb4, 0x2f,
+	0x24, 0x91, 0xc2, 0xfc, 0x16, 0x11, 0xef, 0x5d, 0x59, 0xe2, 0x90, 0x88, 0x7f, 0x56, 0xbc, 0x13,
+	0x8c, 0x19, 0x1b, 0x4f, 0xe9, 0xb2, 0x38, 0x19, 0x27, 0x54, 0x48, 0x92, 0x64, 0x26, 0xa0, 0xfb,
+	0x73, 0x1d, 0x6e, 0xde, 0x63, 0x87, 0x31, 0xa6, 0x47, 0x33, 0x2a, 0x24, 0xba, 0x0e, 0x1b, 0xfa,
+	0x11, 0x0f, 0x74, 0x40, 0xaf, 0x8d, 0x8d, 0xa1, 0xbc, 0xd3, 0x38, 0x89, 0xa5, 0x57, 0xef, 0x80,
+	0xde, 0x35, 0x6c, 0x0c, 0x84, 0xa0, 0x2b, 0x24, 0xcd, 0x3c, 0xa7, 0x03, 0x7a, 0x0e, 0xd6, 0x67,
+	0xf4, 0x11, 0x6c, 0x09, 0x49, 0xb8, 0x1c, 0x08, 0xcf, 0xed, 0x80, 0xde, 0xe6, 0xee, 0x4e, 0x68,
+	0x4a, 0x08, 0xcb, 0x12, 0xc2, 0x41, 0x59, 0x42, 0xb4, 0x71, 0x92, 0x07, 0xb5, 0x07, 0xbf, 0x07,
+	0x00, 0x97, 0x49, 0xe8, 0x7d, 0xd8, 0xa0, 0xe9, 0x68, 0x20, 0xbc, 0xc6, 0x1a, 0xd9, 0x26, 0x05,
+	0xbd, 0x0d, 0xdb, 0xa3, 0x98, 0xd3, 0x7d, 0x19, 0xb3, 0xd4, 0x6b, 0x76, 0x40, 0x6f, 0x6b, 0x77,
+	0x3b, 0xac, 0x5a, 0xbd, 0x57, 0x5e, 0xe1, 0x65, 0x94, 0x82, 0x90, 0x11, 0x39, 0xf1, 0x5a, 0x1a,
+	0xad, 0x3e, 0xa3, 0x2e, 0x6c, 0x8a, 0x09, 0xe1, 0x23, 0xe1, 0x6d, 0x74, 0x9c, 0x5e, 0x3b, 0x82,
+	0x8b, 0x3c, 0x28, 0x3c, 0xb8, 0xf8, 0xef, 0xfe, 0x05, 0x20, 0x52, 0x6d, 0xbb, 0x9b, 0x0a, 0x49,
+	0x52, 0xf9, 0x2c, 0xdd, 0xfb, 0x00, 0x36, 0xd5, 0x30, 0x06, 0xc2, 0x73, 0xd6, 0x80, 0x5a, 0xe4,
+	0xac, 0x62, 0x75, 0xd7, 0xc2, 0xda, 0xb8, 0x10, 0x6b, 0xf3, 0x52, 0xac, 0xdf, 0xb9, 0xf0, 0x05,
+	0x43, 0x11, 0x91, 0xb1, 0x54, 0x50, 0x95, 0x74, 0x5f, 0x12, 0x39, 0x13, 0x06, 0x66, 0x91, 0xa4,
+	0x3d, 0xb8, 0xb8, 0x41, 0x1f, 0x43, 0x77, 0x8f, 0x48, 0xa2, 0x21, 0x6f, 0xee, 0x5e, 0x0f, 0x2d,
+	0x66, 0xaa, 0xb7, 0xd4, 0x5d, 0x74, 0x43, 0xa1, 0x5a, 0xe4, 0xc1, 0xd6, 0x88, 0x48, 0xf2, 0x16,
+	0x4b, 0x62, 0x49, 0x93, 0x4c, 0x1e, 0x63, 0x9d, 0x89, 0xde, 0x85, 0xed, 0x4f, 0x38, 0x67, 0x7c,
+	0x70, 0x9c, 0x51, 0xdd, 0xa2, 0x76, 0x74, 0x73, 0x91, 0x07, 0xdb, 0xb4, 0x74, 0x5a, 0x19, 0xcb,
+	0x48, 0xf4, 0x06, 0x6c, 0x68, 0x43, 0x37, 0xa5, 0x1d, 0x6d, 0x2f, 0xf2, 0xe0, 0x45, 0x9d, 0x62,
+	0x85, 0x9b, 0x88, 0xd5, 0x1e, 0x36, 0x9e, 0xaa, 0x87, 0xd5, 0x28, 0x9b, 0xf6, 0x28, 0x3d, 0xd8,
+	0xfa, 0x9a, 0x72, 0xa1, 0x9e, 0x69, 0x69, 0x7f, 0x69, 0xa2, 0x3b, 0x10, 0xaa, 0xc6, 0xc4, 0x42,
+	0xc6, 0xfb, 0x8a, 0x4f, 0xaa, 0x19, 0xd7, 0x42, 0xb3, 0xd9, 0x98, 0x8a, 0xd9, 0x54, 0x46, 0xa8,
+	0xe8, 0x82, 0x15, 0x88, 0xad, 0x33, 0xfa, 0x1e, 0xc0, 0xd6, 0x67, 0x94, 0x8c, 0x28, 0x17, 0x5e,
+	0xbb, 0xe3, 0xf4, 0x36, 0x77, 0x7b, 0xe1, 0xea, 0xda, 0x87, 0x5f, 0x70, 0x96, 0x50, 0x39, 0xa1,
+	0x33, 0x51, 0xce, 0xc8, 0x24, 0x44, 0x5f, 0x3d, 0xca, 0x83, 0x2f, 0x6d, 0xa1, 0xe2, 0xe4, 0x80,
+	0xa4, 0xa4, 0x3f, 0x65, 0x87, 0x71, 0xff, 0xa9, 0x24, 0xe5, 0xd2, 0xb7, 0x17, 0x79, 0x00, 0x6e,
+	0xe1, 0xb2, 0xb2, 0xee, 0x6f, 0x00, 0xbe, 0xac, 0x06, 0x7b, 0x5f, 0xbd, 0x27, 0xac, 0x7d, 0x48,
+	0x88, 0xdc, 0x9f, 0x78, 0x40, 0xb1, 0x0b, 0x1b, 0xc3, 0xd6, 0x88, 0xfa, 0x73, 0x69, 0x84, 0xb3,
+	0xbe, 0x46, 0x94, 0x4b, 0xe0, 0x5e, 0xb8, 0x04, 0x8d, 0x4b, 0x97, 0xe0, 0xd7, 0x3a, 0x44, 0x36,
+	0xbe, 0x35, 0x56, 0xe1, 0xd3, 0x6a, 0x15, 0x1c, 0x5d, 0x6d, 0xc5, 0x30, 0xf3, 0xd6, 0xdd, 0x11,
+	0x4d, 0x65, 0x7c, 0x10, 0x53, 0x7e, 0xc5, 0x42, 0x58, 0x2c, 0x73, 0x56, 0x59, 0x66, 0x53, 0xc4,
+	0xfd, 0xcf, 0x52, 0xe4, 0x17, 0x00, 0x5f, 0x51, 0x2d, 0xbc, 0x47, 0x86, 0x74, 0xfa, 0x39, 0x49,
+	0x96, 0x34, 0xb1, 0x08, 0x01, 0x9e, 0x8b, 0x10, 0xf5, 0x67, 0x27, 0x84, 0x63, 0x11, 0xa2, 0x92,
+	0x71, 0xd7, 0x92, 0xf1, 0xee, 0x0f, 0x75, 0x78, 0xe3, 0xc9, 0xfa, 0xd7, 0xa0, 0xc1, 0x6b, 0x16,
+	0x0d, 0xda, 0x11, 0xfa, 0xdf, 0x8e, 0xf9, 0x27, 0x00, 0x37, 0x4a, 0x89, 0x47, 0x21, 0x84, 0x46,
+	0xe6, 0xb4, 0x8a, 0x9b, 0xe6, 0x6c, 0x29, 0xb1, 0xe3, 0x95, 0x17, 0x5b, 0x11, 0x28, 0x85, 0x4d,
+	0x63, 0x15, 0xdb, 0x72, 0xd3, 0xda, 0x16, 0xc9, 0x29, 0x49, 0xee, 0x8c, 0x48, 0x26, 0x29, 0x8f,
+	0x3e, 0x54, 0x73, 0x7c, 0x94, 0x07, 0x6f, 0xfe, 0x1b, 0xa6, 0x27, 0x72, 0xd5, 0x50, 0xcc, 0x77,
+	0x71, 0xf1, 0x95, 0xee, 0xb7, 0x00, 0xbe, 0xa4, 0x8a, 0x55, 0xd8, 0xaa, 0x69, 0xee, 0xc1, 0x0d,
+	0x5e, 0x9c, 0x0b, 0x3e, 0x76, 0xaf, 0xee, 0x73, 0xe4, 0x9e, 0xe4, 0x01, 0xc0, 0x55, 0x26, 0xba,
+	0xbd, 0x22, 0xfd, 0xf5, 0x8b, 0xa4, 0x5f, 0xa5, 0xd4, 0x6c, 0xb1, 0x8f, 0xde, 0x39, 0x3d, 0xf3,
+	0x6b, 0x0f, 0xcf, 0xfc, 0xda, 0xe3, 0x33, 0x1f, 0x7c, 0x33, 0xf7, 0xc1, 0x8f, 0x73, 0x1f, 0x9c,
+	0xcc, 0x7d, 0x70, 0x3a, 0xf7, 0xc1, 0x1f, 0x73, 0x1f, 0xfc, 0x39, 0xf7, 0x6b, 0x8f, 0xe7, 0x3e,
+	0x78, 0x70, 0xee, 0xd7, 0x4e, 0xcf, 0xfd, 0xda, 0xc3, 0x73, 0xbf, 0x36, 0x6c, 0x6a, 0x94, 0xb7,
+	0xff, 0x1e, 0x00, 0x50, 0xfa, 0xe1, 0x2a, 0xc9, 0x0a, 0x00, 0x00,
 }
 
 func (this *LokiRequest) Equal(that interface{}) bool {
@@ -1007,6 +1015,9 @@ func (this *LokiLabelNamesRequest) Equal(that interface{}) bool {
 	if this.Path != that1.Path {
 		return false
 	}
+	if this.Query != that1.Query {
+		return false
+	}
 	return true
 }
 func (this *LokiLabelNamesResponse) Equal(that interface{}) bool {
@@ -1198,11 +1209,12 @@ func (this *LokiLabelNamesRequest) GoString() string {
 	if this == nil {
 		return "nil"
 	}
-	s := make([]string, 0, 7)
+	s := make([]string, 0, 8)
 	s = append(s, "&queryrange.LokiLabelNamesRequest{")
 	s = append(s, "StartTs: "+fmt.Sprintf("%#v", this.StartTs)+",\n")
 	s = append(s, "EndTs: "+fmt.Sprintf("%#v", this.EndTs)+",\n")
 	s = append(s, "Path: "+fmt.Sprintf("%#v", this.Path)+",\n")
+	s = append(s, "Query: "+fmt.Sprintf("%#v", this.Query)+",\n")
 	s = append(s, "}")
 	return strings.Join(s, "")
 }
@@ -1632,6 +1644,13 @@ func (m *LokiLabelNamesRequest) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	_ = i
 	var l int
 	_ = l
+	if len(m.Query) > 0 {
+		i -= len(m.Query)
+		copy(dAtA[i:], m.Query)
+		i = encodeVarintQueryrange(dAtA, i, uint64(len(m.Query)))
+		i--
+		dAtA[i] = 0x22
+	}
 	if len(m.Path) > 0 {
 		i -= len(m.Path)
 		copy(dAtA[i:], m.Path)
@@ -1994,6 +2013,10 @@ func (m *LokiLabelNamesRequest) Size() (n int) {
 	if l > 0 {
 		n += 1 + l + sovQueryrange(uint64(l))
 	}
+	l = len(m.Query)
+	if l > 0 {
+		n += 1 + l + sovQueryrange(uint64(l))
+	}
 	return n
 }
 
@@ -2155,6 +2178,7 @@ func (this *LokiLabelNamesRequest) String() string {
 		`StartTs:` + strings.Replace(strings.Replace(fmt.Sprintf("%v", this.StartTs), "Timestamp", "types.Timestamp", 1), `&`, ``, 1) + `,`,
 		`EndTs:` + strings.Replace(strings.Replace(fmt.Sprintf("%v", this.EndTs), "Timestamp", "types.Timestamp", 1), `&`, ``, 1) + `,`,
 		`Path:` + fmt.Sprintf("%v", this.Path) + `,`,
+		`Query:` + fmt.Sprintf("%v", this.Query) + `,`,
 		`}`,
 	}, "")
 	return s
@@ -3514,6 +3538,38 @@ func (m *LokiLabelNamesRequest) Unmarshal(dAtA []byte) error {
 			}
 			m.Path = string(dAtA[iNdEx:postIndex])
 			iNdEx = postIndex
+		case 4:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Query", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowQueryrange
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthQueryrange
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthQueryrange
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Query = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
 			skippy, err := skipQueryrange(dAtA[iNdEx:])
@@ -17,7 +17,12 @@ type stepAlign struct {
 }
 
 func (s stepAlign) Do(ctx context.Context, r Request) (Response, error) {
+	return s.next.Do(ctx, StepAlign(r))
+}
+
+// StepAlign returns the request with its start and end rounded down to its step.
+func StepAlign(r Request) Request {
 	start := (r.GetStart() / r.GetStep()) * r.GetStep()
 	end := (r.GetEnd() / r.GetStep()) * r.GetStep()
-	return s.next.Do(ctx, r.WithStartEnd(start, end))
+	return r.WithStartEnd(start, end)
 }
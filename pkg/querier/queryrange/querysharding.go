@@ -33,6 +33,7 @@ func NewQueryShardMiddleware(
 	middlewareMetrics *queryrangebase.InstrumentMiddlewareMetrics,
 	shardingMetrics *logql.ShardingMetrics,
 	limits Limits,
+	quantileSketchAccuracy float64,
 ) queryrangebase.Middleware {
 
 	noshards := !hasShards(confs)
@@ -47,7 +48,7 @@ func NewQueryShardMiddleware(
 	}
 
 	mapperware := queryrangebase.MiddlewareFunc(func(next queryrangebase.Handler) queryrangebase.Handler {
-		return newASTMapperware(confs, next, logger, shardingMetrics, limits)
+		return newASTMapperware(confs, next, logger, shardingMetrics, limits, quantileSketchAccuracy)
 	})
 
 	return queryrangebase.MiddlewareFunc(func(next queryrangebase.Handler) queryrangebase.Handler {
@@ -69,13 +70,15 @@ func newASTMapperware(
 	logger log.Logger,
 	metrics *logql.ShardingMetrics,
 	limits logql.Limits,
+	quantileSketchAccuracy float64,
 ) *astMapperware {
 	return &astMapperware{
-		confs:   confs,
-		logger:  log.With(logger, "middleware", "QueryShard.astMapperware"),
-		next:    next,
-		ng:      logql.NewShardedEngine(logql.EngineOpts{}, DownstreamHandler{next}, metrics, limits, logger),
-		metrics: metrics,
+		confs:                  confs,
+		logger:                 log.With(logger, "middleware", "QueryShard.astMapperware"),
+		next:                   next,
+		ng:                     logql.NewShardedEngine(logql.EngineOpts{}, DownstreamHandler{next}, metrics, limits, logger),
+		metrics:                metrics,
+		quantileSketchAccuracy: quantileSketchAccuracy,
 	}
 }
 
@@ -85,34 +88,27 @@ type astMapperware struct {
 	next    queryrangebase.Handler
 	ng      *logql.ShardedEngine
 	metrics *logql.ShardingMetrics
+
+	// quantileSketchAccuracy is the relative accuracy of the sketches quantile_over_time is sharded with, zero disabling it.
+	quantileSketchAccuracy float64
 }
 
 func (ast *astMapperware) Do(ctx context.Context, r queryrangebase.Request) (queryrangebase.Response, error) {
-	conf, err := ast.confs.GetConf(r)
 	logger := util_log.WithContext(ctx, ast.logger)
-	// cannot shard with this timerange
-	if err != nil {
-		level.Warn(logger).Log("err", err.Error(), "msg", "skipped AST mapper for request")
+	parsed, err := shardQuery(ast.confs, r, ast.metrics, ast.quantileSketchAccuracy)
+	var notSharded notShardedError
+	switch {
+	case errors.As(err, &notSharded):
+		if !errors.Is(err, errQueryNotShardable) {
+			level.Warn(logger).Log("err", err.Error(), "msg", "skipped AST mapper for request")
+		}
+		// bypass the sharding engine.
 		return ast.next.Do(ctx, r)
-	}
-
-	mapper, err := logql.NewShardMapper(int(conf.RowShards), ast.metrics)
-	if err != nil {
-		return nil, err
-	}
-
-	noop, parsed, err := mapper.Parse(r.GetQuery())
-	if err != nil {
+	case err != nil:
 		level.Warn(logger).Log("msg", "failed mapping AST", "err", err.Error(), "query", r.GetQuery())
 		return nil, err
 	}
-	level.Debug(logger).Log("no-op", noop, "mapped", parsed.String())
-
-	if noop {
-		// the ast can't be mapped to a sharded equivalent
-		// so we can bypass the sharding engine.
-		return ast.next.Do(ctx, r)
-	}
+	level.Debug(logger).Log("mapped", parsed.String())
 
 	params, err := paramsFromRequest(r)
 	if err != nil {
@@ -195,18 +191,62 @@ func (splitter *shardSplitter) Do(ctx context.Context, r queryrangebase.Request)
 	if err != nil {
 		return nil, httpgrpc.Errorf(http.StatusBadRequest, err.Error())
 	}
-	minShardingLookback := splitter.limits.MinShardingLookback(userid)
-	if minShardingLookback == 0 {
-		return splitter.shardingware.Do(ctx, r)
-	}
-	cutoff := splitter.now().Add(-minShardingLookback)
-	// Only attempt to shard queries which are older than the sharding lookback (the period for which ingesters are also queried).
-	if !cutoff.After(util.TimeFromMillis(r.GetEnd())) {
+	if withinMinShardingLookback(splitter.limits, userid, r, splitter.now()) {
 		return splitter.next.Do(ctx, r)
 	}
 	return splitter.shardingware.Do(ctx, r)
 }
 
+// withinMinShardingLookback tells if a request ends within the min sharding lookback of the tenant.
+// Only queries which are older than the sharding lookback (the period for which ingesters are also queried) are sharded.
+func withinMinShardingLookback(limits Limits, userID string, r queryrangebase.Request, now time.Time) bool {
+	minShardingLookback := limits.MinShardingLookback(userID)
+	if minShardingLookback == 0 {
+		return false
+	}
+	cutoff := now.Add(-minShardingLookback)
+	return !cutoff.After(util.TimeFromMillis(r.GetEnd()))
+}
+
+// errQueryNotShardable tells the query of a request has no sharded equivalent.
+var errQueryNotShardable = notShardedError{errors.New("the query is not shardable")}
+
+// notShardedError tells why a request is sent as is instead of being sharded.
+type notShardedError struct {
+	reason error
+}
+
+func (e notShardedError) Error() string {
+	return e.reason.Error()
+}
+
+// shardQuery maps the query of a request to its sharded equivalent, with the shard factor of the schema period of
+// the request. It returns a notShardedError when the request spans several periods, its period has too few shards
+// or its query can't be mapped to a sharded equivalent.
+func shardQuery(confs ShardingConfigs, r queryrangebase.Request, metrics *logql.ShardingMetrics, quantileSketchAccuracy float64) (logql.Expr, error) {
+	conf, err := confs.GetConf(r)
+	// cannot shard with this timerange
+	if err != nil {
+		return nil, notShardedError{err}
+	}
+
+	mapper, err := logql.NewShardMapper(int(conf.RowShards), metrics)
+	if err != nil {
+		return nil, err
+	}
+	mapper = mapper.WithQuantileSketches(quantileSketchAccuracy)
+
+	noop, parsed, err := mapper.Parse(r.GetQuery())
+	if err != nil {
+		return nil, err
+	}
+	if noop {
+		// the ast can't be mapped to a sharded equivalent.
+		return nil, errQueryNotShardable
+	}
+	return parsed, nil
+}
+
 func hasShards(confs ShardingConfigs) bool {
 	for _, conf := range confs {
 		if conf.RowShards > 0 {
@@ -18,6 +18,7 @@ import (
 	"github.com/grafana/loki/pkg/loghttp"
 	"github.com/grafana/loki/pkg/logproto"
 	"github.com/grafana/loki/pkg/logql"
+	"github.com/grafana/loki/pkg/logql/sketch"
 	"github.com/grafana/loki/pkg/querier/queryrange/queryrangebase"
 	"github.com/grafana/loki/pkg/storage/chunk"
 )
@@ -157,6 +158,7 @@ func Test_astMapper(t *testing.T) {
 		log.NewNopLogger(),
 		nilShardingMetrics,
 		fakeLimits{maxSeries: math.MaxInt32, maxQueryParallelism: 1},
+		0,
 	)
 
 	resp, err := mware.Do(context.Background(), defaultReq().WithQuery(`{food="bar"}`))
@@ -186,6 +188,7 @@ func Test_ShardingByPass(t *testing.T) {
 		log.NewNopLogger(),
 		nilShardingMetrics,
 		fakeLimits{maxSeries: math.MaxInt32, maxQueryParallelism: 1},
+		0,
 	)
 
 	_, err := mware.Do(context.Background(), defaultReq().WithQuery(`1+1`))
@@ -257,7 +260,9 @@ func Test_InstantSharding(t *testing.T) {
 		fakeLimits{
 			maxSeries:           math.MaxInt32,
 			maxQueryParallelism: 10,
-		})
+		},
+		0,
+	)
 	response, err := sharding.Wrap(queryrangebase.HandlerFunc(func(c context.Context, r queryrangebase.Request) (queryrangebase.Response, error) {
 		lock.Lock()
 		defer lock.Unlock()
@@ -303,6 +308,83 @@ func Test_InstantSharding(t *testing.T) {
 	require.Equal(t, loghttp.QueryStatusSuccess, response.(*LokiPromResponse).Response.Status)
 }
 
+func Test_InstantQuantileSharding(t *testing.T) {
+	ctx := user.InjectOrgID(context.Background(), "1")
+
+	// each shard has its own values for the series.
+	shardValues := map[string][]float64{
+		"0_of_3": {1, 2, 3, 4},
+		"1_of_3": {10, 20, 30},
+		"2_of_3": {100, 200, 300},
+	}
+	all, err := sketch.NewDDSketch(0.01)
+	require.NoError(t, err)
+	for _, values := range shardValues {
+		for _, v := range values {
+			all.Add(v)
+		}
+	}
+
+	var lock sync.Mutex
+	var queries []string
+	sharding := NewQueryShardMiddleware(log.NewNopLogger(), ShardingConfigs{
+		chunk.PeriodConfig{
+			RowShards: 3,
+		},
+	}, queryrangebase.NewInstrumentMiddlewareMetrics(nil),
+		nilShardingMetrics,
+		fakeLimits{
+			maxSeries:           math.MaxInt32,
+			maxQueryParallelism: 10,
+		},
+		0.01,
+	)
+	response, err := sharding.Wrap(queryrangebase.HandlerFunc(func(c context.Context, r queryrangebase.Request) (queryrangebase.Response, error) {
+		lock.Lock()
+		defer lock.Unlock()
+		queries = append(queries, r.GetQuery())
+
+		s, err := sketch.NewDDSketch(0.01)
+		require.NoError(t, err)
+		for _, v := range shardValues[r.(*LokiInstantRequest).Shards[0]] {
+			s.Add(v)
+		}
+		var result []queryrangebase.SampleStream
+		s.ForEach(func(key string, count float64) {
+			result = append(result, queryrangebase.SampleStream{
+				Labels:  []logproto.LabelAdapter{{Name: logql.QuantileSketchBucketLabel, Value: key}, {Name: "foo", Value: "bar"}},
+				Samples: []logproto.Sample{{Value: count, Timestamp: 10}},
+			})
+		})
+		return &LokiPromResponse{Response: &queryrangebase.PrometheusResponse{
+			Data: queryrangebase.PrometheusData{
+				ResultType: loghttp.ResultTypeVector,
+				Result:     result,
+			},
+		}}, nil
+	})).Do(ctx, &LokiInstantRequest{
+		Query:  `quantile_over_time(0.9, {app="foo"} | unwrap latency [1m]) by (foo)`,
+		TimeTs: util.TimeFromMillis(10),
+		Path:   "/v1/query",
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		`quantile_sketch_over_time(0.01,{app="foo"} | unwrap latency[1m]) by(foo)`,
+		`quantile_sketch_over_time(0.01,{app="foo"} | unwrap latency[1m]) by(foo)`,
+		`quantile_sketch_over_time(0.01,{app="foo"} | unwrap latency[1m]) by(foo)`,
+	}, queries)
+	require.Equal(t, queryrangebase.PrometheusData{
+		ResultType: loghttp.ResultTypeVector,
+		Result: []queryrangebase.SampleStream{
+			{
+				Labels:  []logproto.LabelAdapter{{Name: "foo", Value: "bar"}},
+				Samples: []logproto.Sample{{Value: all.Quantile(0.9), Timestamp: 10}},
+			},
+		},
+	}, response.(*LokiPromResponse).Response.Data)
+	require.InEpsilon(t, 210, all.Quantile(0.9), 0.01)
+}
+
 func Test_SeriesShardingHandler(t *testing.T) {
 	sharding := NewSeriesQueryShardMiddleware(log.NewNopLogger(), ShardingConfigs{
 		chunk.PeriodConfig{
@@ -24,11 +24,16 @@ import (
 // Config is the configuration for the queryrange tripperware
 type Config struct {
 	queryrangebase.Config `yaml:",inline"`
+
+	ShardQuantileOverTime          bool    `yaml:"shard_quantile_over_time"`
+	QuantileSketchRelativeAccuracy float64 `yaml:"quantile_sketch_relative_accuracy"`
 }
 
 // RegisterFlags adds the flags required to configure this flag set.
 func (cfg *Config) RegisterFlags(f *flag.FlagSet) {
 	cfg.Config.RegisterFlags(f)
+	f.BoolVar(&cfg.ShardQuantileOverTime, "querier.shard-quantile-over-time", false, "Shard quantile_over_time queries by merging sketches of the values of each shard, the quantiles being approximated within the relative accuracy of the sketches. Requires the parallelisation of shardable queries.")
+	f.Float64Var(&cfg.QuantileSketchRelativeAccuracy, "querier.quantile-sketch-relative-accuracy", 0.01, "Relative accuracy of the sketches of sharded quantile_over_time queries, e.g. 0.01 for quantiles within 1% of the exact ones. More accurate sketches have more buckets, sent by the queriers as samples.")
 }
 
 // Validate validates the config.
@@ -38,9 +43,21 @@ func (cfg *Config) Validate() error {
 			return errors.Wrap(err, "invalid ResultsCache config")
 		}
 	}
+	if cfg.ShardQuantileOverTime && (cfg.QuantileSketchRelativeAccuracy <= 0 || cfg.QuantileSketchRelativeAccuracy >= 1) {
+		return errors.New("quantile sketch relative accuracy must be between 0 and 1")
+	}
 	return nil
 }
 
+// quantileSketchAccuracy returns the relative accuracy of the sketches quantile_over_time is sharded with,
+// zero when quantile_over_time is not sharded.
+func (cfg Config) quantileSketchAccuracy() float64 {
+	if !cfg.ShardQuantileOverTime {
+		return 0
+	}
+	return cfg.QuantileSketchRelativeAccuracy
+}
+
 // Stopper gracefully shutdown resources created
 type Stopper interface {
 	Stop()
@@ -146,8 +163,7 @@ func (r roundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
 			if err := validateLimits(req, rangeQuery.Limit, r.limits); err != nil {
 				return nil, err
 			}
-			// Only filter expressions are query sharded
-			if !expr.HasFilter() {
+			if _, ok := rangeQuerySplitter(expr); !ok {
 				return r.next.RoundTrip(req)
 			}
 			return r.log.RoundTrip(req)
@@ -187,6 +203,20 @@ func (r roundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
 	}
 }
 
+// rangeQuerySplitter returns the splitter of the tripperware handling a range query,
+// or false when the query is sent to the queriers as is.
+func rangeQuerySplitter(expr logql.Expr) (Splitter, bool) {
+	switch e := expr.(type) {
+	case logql.SampleExpr:
+		return splitMetricByTime, true
+	case logql.LogSelectorExpr:
+		// Only filter expressions are split and query sharded
+		return splitByTime, e.HasFilter()
+	default:
+		return nil, false
+	}
+}
+
 // transformRegexQuery backport the old regexp params into the v1 query format
 func transformRegexQuery(req *http.Request, expr logql.LogSelectorExpr) (logql.LogSelectorExpr, error) {
 	regexp := req.Form.Get("regexp")
@@ -270,6 +300,7 @@ func NewLogFilterTripperware(
 				instrumentMetrics, // instrumentation is included in the sharding middleware
 				shardingMetrics,
 				limits,
+				cfg.quantileSketchAccuracy(),
 			),
 		)
 	}
@@ -426,6 +457,7 @@ func NewMetricTripperware(
 				instrumentMetrics, // instrumentation is included in the sharding middleware
 				shardingMetrics,
 				limits,
+				cfg.quantileSketchAccuracy(),
 			),
 		)
 	}
@@ -475,6 +507,7 @@ func NewInstantMetricTripperware(
 				instrumentMetrics, // instrumentation is included in the sharding middleware
 				shardingMetrics,
 				limits,
+				cfg.quantileSketchAccuracy(),
 			),
 		)
 	}
@@ -166,16 +166,13 @@ func (h *splitByInterval) Do(ctx context.Context, r queryrangebase.Request) (que
 		return nil, httpgrpc.Errorf(http.StatusBadRequest, err.Error())
 	}
 
-	interval := h.limits.QuerySplitDuration(userid)
-	// skip split by if unset
-	if interval == 0 {
-		return h.next.Do(ctx, r)
-	}
-
-	intervals, err := h.splitter(r, interval)
+	intervals, split, err := splitRequest(h.limits, userid, h.splitter, r)
 	if err != nil {
 		return nil, err
 	}
+	if !split {
+		return h.next.Do(ctx, r)
+	}
 	h.metrics.splits.Observe(float64(len(intervals)))
 
 	// no interval should not be processed by the frontend.
@@ -222,6 +219,21 @@ func (h *splitByInterval) Do(ctx context.Context, r queryrangebase.Request) (que
 	return h.merger.MergeResponse(resps...)
 }
 
+// splitRequest splits a request by the split interval of the tenant. It returns false when the tenant has no split interval,
+// the request being sent as is.
+func splitRequest(limits Limits, userID string, splitter Splitter, r queryrangebase.Request) ([]queryrangebase.Request, bool, error) {
+	interval := limits.QuerySplitDuration(userID)
+	// skip split by if unset
+	if interval == 0 {
+		return nil, false, nil
+	}
+	reqs, err := splitter(r, interval)
+	if err != nil {
+		return nil, false, err
+	}
+	return reqs, true, nil
+}
+
 func splitByTime(req queryrangebase.Request, interval time.Duration) ([]queryrangebase.Request, error) {
 	var reqs []queryrangebase.Request
 
@@ -254,6 +266,7 @@ func splitByTime(req queryrangebase.Request, interval time.Duration) ([]queryran
 				Path:    r.Path,
 				StartTs: start,
 				EndTs:   end,
+				Query:   r.Query,
 			})
 		})
 	default:
@@ -297,13 +310,26 @@ func maxRangeVectorDuration(q string) (time.Duration, error) {
 	if err != nil {
 		return 0, err
 	}
+	return maxRange(expr), nil
+}
+
+// maxRange returns the maximum duration looked back over by an expression.
+// Subqueries look back over their range in addition to the ranges of their query.
+func maxRange(expr logql.SampleExpr) time.Duration {
 	var max time.Duration
 	expr.Walk(func(e interface{}) {
-		if r, ok := e.(*logql.LogRange); ok && r.Interval > max {
-			max = r.Interval
+		var d time.Duration
+		switch r := e.(type) {
+		case *logql.LogRange:
+			d = r.Interval
+		case *logql.SubqueryExpr:
+			d = r.Interval + maxRange(r.Left)
+		}
+		if d > max {
+			max = d
 		}
 	})
-	return max, nil
+	return max
 }
 
 // reduceSplitIntervalForRangeVector reduces the split interval for a range query based on the duration of the range vector.
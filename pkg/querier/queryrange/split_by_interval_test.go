@@ -48,6 +48,7 @@ func Test_splitQuery(t *testing.T) {
 			StartTs: start,
 			EndTs:   end,
 			Path:    "/labels",
+			Query:   `{foo="bar"}`,
 		}
 	}
 
@@ -545,6 +546,30 @@ func Test_splitMetricQuery(t *testing.T) {
 			},
 			interval: 15 * time.Minute,
 		},
+		// subqueries look back over their range and the ranges of their query: split by 6h instead of 1h
+		{
+			input: &LokiRequest{
+				StartTs: time.Unix(2*3600, 0),
+				EndTs:   time.Unix(3*3*3600, 0),
+				Step:    15 * seconds,
+				Query:   `max_over_time(rate({app="foo"}[1h])[5h:5m])`,
+			},
+			expected: []queryrangebase.Request{
+				&LokiRequest{
+					StartTs: time.Unix(2*3600, 0),
+					EndTs:   time.Unix((6*3600)-15, 0),
+					Step:    15 * seconds,
+					Query:   `max_over_time(rate({app="foo"}[1h])[5h:5m])`,
+				},
+				&LokiRequest{
+					StartTs: time.Unix(6*3600, 0),
+					EndTs:   time.Unix(3*3*3600, 0),
+					Step:    15 * seconds,
+					Query:   `max_over_time(rate({app="foo"}[1h])[5h:5m])`,
+				},
+			},
+			interval: 1 * time.Hour,
+		},
 	} {
 		t.Run(strconv.Itoa(i), func(t *testing.T) {
 			splits, err := splitMetricByTime(tc.input, tc.interval)
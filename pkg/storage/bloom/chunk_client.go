@@ -0,0 +1,41 @@
+package bloom
+
+import (
+	"context"
+
+	"github.com/grafana/loki/pkg/storage/chunk"
+)
+
+type chunkClient struct {
+	chunk.Client
+
+	store     *Store
+	schemaCfg chunk.SchemaConfig
+}
+
+// NewChunkClient wraps a chunk.Client so that the bloom filters of the chunks follow them: filters are built for
+// the chunks put in it and deleted along with the chunks deleted from it.
+func NewChunkClient(client chunk.Client, store *Store, schemaCfg chunk.SchemaConfig) chunk.Client {
+	return &chunkClient{
+		Client:    client,
+		store:     store,
+		schemaCfg: schemaCfg,
+	}
+}
+
+func (c *chunkClient) PutChunks(ctx context.Context, chunks []chunk.Chunk) error {
+	if err := c.Client.PutChunks(ctx, chunks); err != nil {
+		return err
+	}
+	c.store.PutChunks(ctx, c.schemaCfg, chunks)
+	return nil
+}
+
+// DeleteChunk deletes the filter first: a chunk left without its filter is only never skipped, while a filter
+// left behind would never be deleted once its chunk is gone.
+func (c *chunkClient) DeleteChunk(ctx context.Context, userID, chunkID string) error {
+	if err := c.store.DeleteChunk(ctx, chunkID); err != nil {
+		return err
+	}
+	return c.Client.DeleteChunk(ctx, userID, chunkID)
+}
@@ -0,0 +1,61 @@
+package bloom
+
+import (
+	"context"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/loki/pkg/storage/chunk"
+	"github.com/grafana/loki/pkg/storage/chunk/local"
+	"github.com/grafana/loki/pkg/storage/chunk/objectclient"
+)
+
+func TestChunkClient(t *testing.T) {
+	chunksClient, err := local.NewFSObjectClient(local.FSConfig{Directory: t.TempDir()})
+	require.NoError(t, err)
+	bloomsClient, err := local.NewFSObjectClient(local.FSConfig{Directory: t.TempDir()})
+	require.NoError(t, err)
+
+	s := NewStore(Config{
+		Enabled:              true,
+		SharedStoreKeyPrefix: "blooms/",
+		NGramLength:          4,
+		FalsePositiveRate:    0.01,
+		MaxConcurrency:       2,
+	}, bloomsClient, prometheus.NewRegistry())
+	defer s.Stop()
+
+	schemaCfg := chunk.SchemaConfig{}
+	client := NewChunkClient(objectclient.NewClient(chunksClient, objectclient.Base64Encoder, schemaCfg), s, schemaCfg)
+	ctx := context.Background()
+
+	foo := newTestChunk(t, labels.FromStrings("app", "foo"), "GET /api/foo 200")
+	bar := newTestChunk(t, labels.FromStrings("app", "bar"), "POST /api/bar 200")
+	require.NoError(t, client.PutChunks(ctx, []chunk.Chunk{foo, bar}))
+
+	keys := []string{schemaCfg.ExternalKey(foo), schemaCfg.ExternalKey(bar)}
+	filters, err := s.GetChunks(ctx, keys)
+	require.NoError(t, err)
+	require.NotNil(t, filters[0])
+	require.NotNil(t, filters[1])
+
+	// the filter goes away with its chunk.
+	require.NoError(t, client.DeleteChunk(ctx, "user", keys[0]))
+	filters, err = s.GetChunks(ctx, keys)
+	require.NoError(t, err)
+	require.Nil(t, filters[0])
+	require.NotNil(t, filters[1])
+	_, err = client.GetChunks(ctx, []chunk.Chunk{foo})
+	require.Error(t, err)
+
+	// the filter of a chunk already deleted is deleted all the same.
+	require.NoError(t, chunksClient.DeleteObject(ctx, objectclient.Base64Encoder(keys[1])))
+	err = client.DeleteChunk(ctx, "user", keys[1])
+	require.True(t, client.IsChunkNotFoundErr(err))
+	filters, err = s.GetChunks(ctx, keys[1:])
+	require.NoError(t, err)
+	require.Nil(t, filters[0])
+}
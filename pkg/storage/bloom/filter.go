@@ -0,0 +1,178 @@
+package bloom
+
+import (
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"math"
+
+	"github.com/cespare/xxhash/v2"
+)
+
+const filterFormatV1 = 1
+
+var (
+	errInvalidFilter  = errors.New("invalid bloom filter")
+	errInvalidVersion = errors.New("invalid bloom filter version")
+	errInvalidCRC     = errors.New("bloom filter checksum mismatch")
+
+	castagnoliTable = crc32.MakeTable(crc32.Castagnoli)
+)
+
+// Filter is a bloom filter of all the n-grams found in the lines of a chunk.
+// A literal can only be part of a line of the chunk if all its n-grams are in the filter.
+type Filter struct {
+	ngramLength int
+	hashes      uint32
+	bits        []uint64
+}
+
+// NewFilter creates a filter sized to hold the given number of distinct n-grams with the given false positive rate.
+func NewFilter(ngramLength, ngrams int, falsePositiveRate float64) *Filter {
+	if ngrams < 1 {
+		ngrams = 1
+	}
+	// optimal number of bits and hash functions for the expected amount of items.
+	m := math.Ceil(-float64(ngrams) * math.Log(falsePositiveRate) / (math.Ln2 * math.Ln2))
+	k := math.Round(m / float64(ngrams) * math.Ln2)
+	if k < 1 {
+		k = 1
+	}
+	return &Filter{
+		ngramLength: ngramLength,
+		hashes:      uint32(k),
+		bits:        make([]uint64, int(math.Ceil(m/64))),
+	}
+}
+
+// NGramLength returns the length of the n-grams indexed by the filter.
+func (f *Filter) NGramLength() int {
+	return f.ngramLength
+}
+
+func (f *Filter) add(h uint64) {
+	m := uint64(len(f.bits)) * 64
+	h1, h2 := h&math.MaxUint32, h>>32
+	for i := uint64(0); i < uint64(f.hashes); i++ {
+		bit := (h1 + i*h2) % m
+		f.bits[bit/64] |= 1 << (bit % 64)
+	}
+}
+
+func (f *Filter) test(h uint64) bool {
+	m := uint64(len(f.bits)) * 64
+	h1, h2 := h&math.MaxUint32, h>>32
+	for i := uint64(0); i < uint64(f.hashes); i++ {
+		bit := (h1 + i*h2) % m
+		if f.bits[bit/64]&(1<<(bit%64)) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// MayContain returns false if none of the lines of the chunk contains the literal.
+// Literals shorter than the n-grams of the filter can't be ruled out.
+func (f *Filter) MayContain(literal string) bool {
+	if len(literal) < f.ngramLength {
+		return true
+	}
+	for i := 0; i+f.ngramLength <= len(literal); i++ {
+		if !f.test(xxhash.Sum64String(literal[i : i+f.ngramLength])) {
+			return false
+		}
+	}
+	return true
+}
+
+// Encode serializes the filter.
+func (f *Filter) Encode() []byte {
+	b := make([]byte, 0, 1+3*binary.MaxVarintLen64+len(f.bits)*8+4)
+	b = append(b, filterFormatV1)
+	b = appendUvarint(b, uint64(f.ngramLength))
+	b = appendUvarint(b, uint64(f.hashes))
+	b = appendUvarint(b, uint64(len(f.bits)))
+	for _, w := range f.bits {
+		b = append(b, 0, 0, 0, 0, 0, 0, 0, 0)
+		binary.LittleEndian.PutUint64(b[len(b)-8:], w)
+	}
+	b = append(b, 0, 0, 0, 0)
+	binary.BigEndian.PutUint32(b[len(b)-4:], crc32.Checksum(b[:len(b)-4], castagnoliTable))
+	return b
+}
+
+// DecodeFilter deserializes a filter serialized with Encode.
+func DecodeFilter(b []byte) (*Filter, error) {
+	if len(b) < 5 {
+		return nil, errInvalidFilter
+	}
+	data, sum := b[:len(b)-4], binary.BigEndian.Uint32(b[len(b)-4:])
+	if crc32.Checksum(data, castagnoliTable) != sum {
+		return nil, errInvalidCRC
+	}
+	if data[0] != filterFormatV1 {
+		return nil, errInvalidVersion
+	}
+	data = data[1:]
+
+	var fields [3]uint64
+	for i := range fields {
+		v, n := binary.Uvarint(data)
+		if n <= 0 {
+			return nil, errInvalidFilter
+		}
+		fields[i], data = v, data[n:]
+	}
+	ngramLength, hashes, words := fields[0], fields[1], fields[2]
+	if ngramLength == 0 || hashes == 0 || words == 0 || uint64(len(data)) != words*8 {
+		return nil, errInvalidFilter
+	}
+
+	f := &Filter{
+		ngramLength: int(ngramLength),
+		hashes:      uint32(hashes),
+		bits:        make([]uint64, words),
+	}
+	for i := range f.bits {
+		f.bits[i] = binary.LittleEndian.Uint64(data[i*8:])
+	}
+	return f, nil
+}
+
+func appendUvarint(b []byte, v uint64) []byte {
+	var buf [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(buf[:], v)
+	return append(b, buf[:n]...)
+}
+
+// Builder collects the distinct n-grams of log lines to build a Filter sized for them.
+type Builder struct {
+	ngramLength       int
+	falsePositiveRate float64
+	ngrams            map[uint64]struct{}
+}
+
+// NewBuilder creates a Builder of filters indexing n-grams of the given length.
+func NewBuilder(ngramLength int, falsePositiveRate float64) *Builder {
+	return &Builder{
+		ngramLength:       ngramLength,
+		falsePositiveRate: falsePositiveRate,
+		ngrams:            map[uint64]struct{}{},
+	}
+}
+
+// AddLine adds all the n-grams of the line.
+func (b *Builder) AddLine(line string) {
+	for i := 0; i+b.ngramLength <= len(line); i++ {
+		b.ngrams[xxhash.Sum64String(line[i:i+b.ngramLength])] = struct{}{}
+	}
+}
+
+// Build returns a filter containing all the n-grams added so far.
+func (b *Builder) Build() *Filter {
+	f := NewFilter(b.ngramLength, len(b.ngrams), b.falsePositiveRate)
+	for h := range b.ngrams {
+		f.add(h)
+	}
+	return f
+}
@@ -0,0 +1,70 @@
+package bloom
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFilter(t *testing.T) {
+	b := NewBuilder(4, 0.01)
+	b.AddLine("level=info msg=\"request done\" trace_id=4bf92f3577b34da6")
+	b.AddLine("level=error msg=\"request failed\" trace_id=00f067aa0ba902b7")
+	f := b.Build()
+
+	for _, tc := range []struct {
+		literal  string
+		expected bool
+	}{
+		{"trace_id=4bf92f3577b34da6", true},
+		{"00f067aa0ba902b7", true},
+		{"request failed", true},
+		// literals shorter than the n-grams can't be ruled out.
+		{"xyz", true},
+		{"trace_id=a3ce929d0e0e4736", false},
+		{"level=debug", false},
+		// n-grams are not indexed across lines.
+		{"4da600f0", false},
+	} {
+		t.Run(tc.literal, func(t *testing.T) {
+			require.Equal(t, tc.expected, f.MayContain(tc.literal))
+		})
+	}
+}
+
+func TestFilter_FalsePositiveRate(t *testing.T) {
+	b := NewBuilder(4, 0.01)
+	for i := 0; i < 10000; i++ {
+		b.AddLine(fmt.Sprintf("%08x", i*7919))
+	}
+	f := b.Build()
+
+	var falsePositives int
+	for i := 0; i < 10000; i++ {
+		// none of these 4-grams were added.
+		if f.MayContain(fmt.Sprintf("zz%02x", i%256) + fmt.Sprintf("%d", i)) {
+			falsePositives++
+		}
+	}
+	require.Less(t, falsePositives, 200)
+}
+
+func TestFilter_Encoding(t *testing.T) {
+	b := NewBuilder(3, 0.05)
+	b.AddLine("foo bar")
+	f := b.Build()
+
+	encoded := f.Encode()
+	decoded, err := DecodeFilter(encoded)
+	require.NoError(t, err)
+	require.Equal(t, f, decoded)
+	require.Equal(t, 3, decoded.NGramLength())
+
+	encoded[len(encoded)/2] ^= 0xff
+	_, err = DecodeFilter(encoded)
+	require.Equal(t, errInvalidCRC, err)
+
+	_, err = DecodeFilter([]byte{1, 2})
+	require.Equal(t, errInvalidFilter, err)
+}
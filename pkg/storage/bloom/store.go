@@ -0,0 +1,230 @@
+package bloom
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"flag"
+	"io/ioutil"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/go-kit/log/level"
+	"github.com/grafana/dskit/concurrency"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"github.com/grafana/loki/pkg/chunkenc"
+	"github.com/grafana/loki/pkg/logproto"
+	"github.com/grafana/loki/pkg/logql/log"
+	"github.com/grafana/loki/pkg/storage/chunk"
+	"github.com/grafana/loki/pkg/storage/chunk/encryption"
+	shipper_util "github.com/grafana/loki/pkg/storage/stores/shipper/util"
+	util_log "github.com/grafana/loki/pkg/util/log"
+)
+
+const objectSuffix = ".bloom"
+
+// Config configures the bloom filters built for every flushed chunk and used by queries to skip chunks.
+type Config struct {
+	Enabled              bool    `yaml:"enabled"`
+	SharedStoreType      string  `yaml:"shared_store"`
+	SharedStoreKeyPrefix string  `yaml:"shared_store_key_prefix"`
+	NGramLength          int     `yaml:"ngram_length"`
+	FalsePositiveRate    float64 `yaml:"false_positive_rate"`
+	MaxConcurrency       int     `yaml:"max_concurrency"`
+}
+
+// RegisterFlags registers flags.
+func (cfg *Config) RegisterFlags(f *flag.FlagSet) {
+	f.BoolVar(&cfg.Enabled, "store.bloom-filter.enabled", false, "Build an n-gram bloom filter for every flushed chunk and use them to skip chunks which can't contain the literal of a `|=` line filter.")
+	f.StringVar(&cfg.SharedStoreType, "store.bloom-filter.shared-store", "", "Shared store for keeping the bloom filters. Supported types: gcs, s3, azure, filesystem")
+	f.StringVar(&cfg.SharedStoreKeyPrefix, "store.bloom-filter.shared-store.key-prefix", "blooms/", "Prefix to add to Object Keys in Shared store. Path separator(if any) should always be a '/'. Prefix should never start with a separator but should always end with it")
+	f.IntVar(&cfg.NGramLength, "store.bloom-filter.ngram-length", 4, "Length of the n-grams of the log lines added to the bloom filters. Literals shorter than it can't be used to skip chunks.")
+	f.Float64Var(&cfg.FalsePositiveRate, "store.bloom-filter.false-positive-rate", 0.01, "False positive rate of the bloom filters, per n-gram.")
+	f.IntVar(&cfg.MaxConcurrency, "store.bloom-filter.max-concurrency", 32, "Maximum number of bloom filters fetched in parallel by a query.")
+}
+
+// Validate validates the config.
+func (cfg *Config) Validate() error {
+	if !cfg.Enabled {
+		return nil
+	}
+	if cfg.NGramLength < 1 {
+		return errors.New("ngram length must be greater than 0")
+	}
+	if cfg.FalsePositiveRate <= 0 || cfg.FalsePositiveRate >= 1 {
+		return errors.New("false positive rate must be between 0 and 1")
+	}
+	if cfg.MaxConcurrency < 1 {
+		return errors.New("max concurrency must be greater than 0")
+	}
+	return shipper_util.ValidateSharedStoreKeyPrefix(cfg.SharedStoreKeyPrefix)
+}
+
+type metrics struct {
+	filtersBuilt      prometheus.Counter
+	filtersBuildFails prometheus.Counter
+	filterSizeBytes   prometheus.Histogram
+}
+
+func newMetrics(r prometheus.Registerer) *metrics {
+	return &metrics{
+		filtersBuilt: promauto.With(r).NewCounter(prometheus.CounterOpts{
+			Namespace: "loki",
+			Name:      "chunk_bloom_filters_built_total",
+			Help:      "Total number of chunk bloom filters built and uploaded.",
+		}),
+		filtersBuildFails: promauto.With(r).NewCounter(prometheus.CounterOpts{
+			Namespace: "loki",
+			Name:      "chunk_bloom_filters_failures_total",
+			Help:      "Total number of chunk bloom filters which could not be built or uploaded.",
+		}),
+		filterSizeBytes: promauto.With(r).NewHistogram(prometheus.HistogramOpts{
+			Namespace: "loki",
+			Name:      "chunk_bloom_filter_size_bytes",
+			Help:      "Size of the chunk bloom filters.",
+			Buckets:   prometheus.ExponentialBuckets(1024, 4, 8),
+		}),
+	}
+}
+
+// Store keeps a bloom filter for every chunk, as an object next to it in the configured object store.
+// Chunks without a filter, e.g. flushed before filters were enabled, are never skipped.
+type Store struct {
+	cfg     Config
+	client  chunk.ObjectClient
+	metrics *metrics
+}
+
+// NewStore creates a Store keeping bloom filters in the given object store.
+func NewStore(cfg Config, client chunk.ObjectClient, r prometheus.Registerer) *Store {
+	return &Store{
+		cfg:     cfg,
+		client:  client,
+		metrics: newMetrics(r),
+	}
+}
+
+func (s *Store) objectKey(chunkKey string) string {
+	return s.cfg.SharedStoreKeyPrefix + chunkKey + objectSuffix
+}
+
+// PutChunks builds and uploads the bloom filters of the given chunks.
+// Failures are only logged since a chunk without a filter still gets queried.
+func (s *Store) PutChunks(ctx context.Context, schemaCfg chunk.SchemaConfig, chunks []chunk.Chunk) {
+	logger := util_log.WithContext(ctx, util_log.Logger)
+	for _, c := range chunks {
+		key := schemaCfg.ExternalKey(c)
+		if err := s.putChunk(ctx, key, c); err != nil {
+			s.metrics.filtersBuildFails.Inc()
+			level.Warn(logger).Log("msg", "failed to store chunk bloom filter", "chunk", key, "err", err)
+			continue
+		}
+		s.metrics.filtersBuilt.Inc()
+	}
+}
+
+func (s *Store) putChunk(ctx context.Context, key string, c chunk.Chunk) error {
+	facade, ok := c.Data.(*chunkenc.Facade)
+	if !ok || facade.LokiChunk() == nil {
+		return errors.New("chunk data is not a log chunk")
+	}
+
+	f, err := BuildFilter(ctx, facade.LokiChunk(), s.cfg.NGramLength, s.cfg.FalsePositiveRate)
+	if err != nil {
+		return err
+	}
+
+	b := f.Encode()
+	s.metrics.filterSizeBytes.Observe(float64(len(b)))
+	return s.client.PutObject(ctx, s.objectKey(key), bytes.NewReader(b))
+}
+
+// DeleteChunk deletes the bloom filter of the chunk with the given external key, if any.
+func (s *Store) DeleteChunk(ctx context.Context, chunkKey string) error {
+	if err := s.client.DeleteObject(ctx, s.objectKey(chunkKey)); err != nil && !s.client.IsObjectNotFoundErr(err) {
+		return err
+	}
+	return nil
+}
+
+// BuildFilter builds the bloom filter of all the lines of the chunk.
+func BuildFilter(ctx context.Context, c chunkenc.Chunk, ngramLength int, falsePositiveRate float64) (*Filter, error) {
+	it, err := c.Iterator(ctx, time.Unix(0, 0), time.Unix(0, math.MaxInt64), logproto.FORWARD, log.NewNoopPipeline().ForStream(nil))
+	if err != nil {
+		return nil, err
+	}
+	defer it.Close()
+
+	b := NewBuilder(ngramLength, falsePositiveRate)
+	for it.Next() {
+		b.AddLine(it.Entry().Line)
+	}
+	if err := it.Error(); err != nil {
+		return nil, err
+	}
+	return b.Build(), nil
+}
+
+// GetChunks fetches the bloom filters of the chunks with the given external keys.
+// The filter is nil for the chunks which don't have one.
+func (s *Store) GetChunks(ctx context.Context, keys []string) ([]*Filter, error) {
+	filters := make([]*Filter, len(keys))
+	jobs := make([]interface{}, len(keys))
+	for i := range keys {
+		jobs[i] = i
+	}
+
+	err := concurrency.ForEach(ctx, jobs, s.cfg.MaxConcurrency, func(ctx context.Context, job interface{}) error {
+		i := job.(int)
+		f, err := s.getChunk(ctx, keys[i])
+		if err != nil {
+			return err
+		}
+		filters[i] = f
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return filters, nil
+}
+
+func (s *Store) getChunk(ctx context.Context, key string) (*Filter, error) {
+	r, _, err := s.client.GetObject(ctx, s.objectKey(key))
+	if err != nil {
+		if s.client.IsObjectNotFoundErr(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer r.Close()
+
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := DecodeFilter(b)
+	if err != nil {
+		// a corrupted filter must not fail the queries, the chunk just won't be skipped.
+		level.Warn(util_log.WithContext(ctx, util_log.Logger)).Log("msg", "failed to decode chunk bloom filter", "chunk", key, "err", err)
+		return nil, nil
+	}
+	return f, nil
+}
+
+// TenantFromObjectKey returns the encryption.TenantFunc of the bloom filters stored under the given key prefix,
+// which are encrypted with the key of the tenant owning their chunk.
+func TenantFromObjectKey(keyPrefix string) encryption.TenantFunc {
+	return func(key string) (string, error) {
+		return encryption.TenantFromChunkKey(strings.TrimPrefix(key, keyPrefix))
+	}
+}
+
+// Stop stops the underlying object client.
+func (s *Store) Stop() {
+	s.client.Stop()
+}
@@ -0,0 +1,79 @@
+package bloom
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/loki/pkg/chunkenc"
+	"github.com/grafana/loki/pkg/logproto"
+	"github.com/grafana/loki/pkg/storage/chunk"
+	"github.com/grafana/loki/pkg/storage/chunk/local"
+)
+
+func newTestChunk(t *testing.T, ls labels.Labels, lines ...string) chunk.Chunk {
+	memChunk := chunkenc.NewMemChunk(chunkenc.EncGZIP, chunkenc.UnorderedHeadBlockFmt, 256*1024, 0)
+	for i, line := range lines {
+		require.NoError(t, memChunk.Append(&logproto.Entry{Timestamp: time.Unix(0, int64(i)), Line: line}))
+	}
+	require.NoError(t, memChunk.Close())
+
+	c := chunk.NewChunk("user", model.Fingerprint(ls.Hash()), ls, chunkenc.NewFacade(memChunk, 0, 0), 0, model.Time(len(lines)))
+	require.NoError(t, c.Encode())
+	return c
+}
+
+func TestStore(t *testing.T) {
+	objectClient, err := local.NewFSObjectClient(local.FSConfig{Directory: t.TempDir()})
+	require.NoError(t, err)
+
+	s := NewStore(Config{
+		Enabled:              true,
+		SharedStoreKeyPrefix: "blooms/",
+		NGramLength:          4,
+		FalsePositiveRate:    0.01,
+		MaxConcurrency:       2,
+	}, objectClient, prometheus.NewRegistry())
+	defer s.Stop()
+
+	schemaCfg := chunk.SchemaConfig{}
+	foo := newTestChunk(t, labels.FromStrings("app", "foo"), "GET /api/foo 200", "GET /api/foo 500")
+	bar := newTestChunk(t, labels.FromStrings("app", "bar"), "POST /api/bar 200")
+	missing := newTestChunk(t, labels.FromStrings("app", "buzz"), "GET /api/buzz 200")
+	s.PutChunks(context.Background(), schemaCfg, []chunk.Chunk{foo, bar})
+
+	// a corrupted filter is ignored.
+	corrupted := newTestChunk(t, labels.FromStrings("app", "corrupted"), "GET /api/corrupted 200")
+	require.NoError(t, objectClient.PutObject(context.Background(), s.objectKey(schemaCfg.ExternalKey(corrupted)), bytes.NewReader([]byte("corrupted"))))
+
+	filters, err := s.GetChunks(context.Background(), []string{
+		schemaCfg.ExternalKey(foo),
+		schemaCfg.ExternalKey(bar),
+		schemaCfg.ExternalKey(missing),
+		schemaCfg.ExternalKey(corrupted),
+	})
+	require.NoError(t, err)
+	require.Len(t, filters, 4)
+
+	require.True(t, filters[0].MayContain("/api/foo 500"))
+	require.False(t, filters[0].MayContain("/api/bar"))
+	require.True(t, filters[1].MayContain("POST /api/bar"))
+	require.False(t, filters[1].MayContain("GET /api"))
+	require.Nil(t, filters[2])
+	require.Nil(t, filters[3])
+}
+
+func TestTenantFromObjectKey(t *testing.T) {
+	tenant, err := TenantFromObjectKey("blooms/")("blooms/user/1a2b3c:17e9b6b3c5a:17e9b6b6b53:3b2c9c9b.bloom")
+	require.NoError(t, err)
+	require.Equal(t, "user", tenant)
+
+	_, err = TenantFromObjectKey("blooms/")("blooms/1a2b3c.bloom")
+	require.Error(t, err)
+}
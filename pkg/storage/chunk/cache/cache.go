@@ -27,6 +27,7 @@ type Cache interface {
 // Config for building Caches.
 type Config struct {
 	EnableFifoCache bool `yaml:"enable_fifocache"`
+	EnablePeerCache bool `yaml:"enable_peercache"`
 
 	DefaultValidity time.Duration `yaml:"default_validity"`
 
@@ -35,6 +36,8 @@ type Config struct {
 	MemcacheClient MemcachedClientConfig `yaml:"memcached_client"`
 	Redis          RedisConfig           `yaml:"redis"`
 	Fifocache      FifoCacheConfig       `yaml:"fifocache"`
+	Diskcache      DiskCacheConfig       `yaml:"diskcache"`
+	PeerCache      PeerCacheConfig       `yaml:"peercache"`
 
 	// This is to name the cache metrics properly.
 	Prefix string `yaml:"prefix" doc:"hidden"`
@@ -42,6 +45,9 @@ type Config struct {
 	// For tests to inject specific implementations.
 	Cache Cache `yaml:"-"`
 
+	// Injected internally, the peers sharing the peer cache.
+	Peers *Peers `yaml:"-"`
+
 	// AsyncCacheWriteBackConcurrency specifies the number of goroutines to use when asynchronously writing chunks fetched from the store to the chunk cache.
 	AsyncCacheWriteBackConcurrency int `yaml:"async_cache_write_back_concurrency"`
 	// AsyncCacheWriteBackBufferSize specifies the maximum number of fetched chunks to buffer for writing back to the chunk cache.
@@ -55,16 +61,28 @@ func (cfg *Config) RegisterFlagsWithPrefix(prefix string, description string, f
 	cfg.MemcacheClient.RegisterFlagsWithPrefix(prefix, description, f)
 	cfg.Redis.RegisterFlagsWithPrefix(prefix, description, f)
 	cfg.Fifocache.RegisterFlagsWithPrefix(prefix, description, f)
+	cfg.Diskcache.RegisterFlagsWithPrefix(prefix, description, f)
+	cfg.PeerCache.RegisterFlagsWithPrefix(prefix, description, f)
 	f.IntVar(&cfg.AsyncCacheWriteBackConcurrency, prefix+"max-async-cache-write-back-concurrency", 16, "The maximum number of concurrent asynchronous writeback cache can occur.")
 	f.IntVar(&cfg.AsyncCacheWriteBackBufferSize, prefix+"max-async-cache-write-back-buffer-size", 500, "The maximum number of enqueued asynchronous writeback cache allowed.")
 	f.DurationVar(&cfg.DefaultValidity, prefix+"default-validity", time.Hour, description+"The default validity of entries for caches unless overridden.")
 	f.BoolVar(&cfg.EnableFifoCache, prefix+"cache.enable-fifocache", false, description+"Enable in-memory cache (auto-enabled for the chunks & query results cache if no other cache is configured).")
+	f.BoolVar(&cfg.EnablePeerCache, prefix+"cache.enable-peercache", false, description+"Enable the cache sharded across the instances joining the cache peers ring.")
 
 	cfg.Prefix = prefix
 }
 
 func (cfg *Config) Validate() error {
-	return cfg.Fifocache.Validate()
+	if err := cfg.Fifocache.Validate(); err != nil {
+		return err
+	}
+	if err := cfg.Diskcache.Validate(); err != nil {
+		return err
+	}
+	if cfg.EnablePeerCache {
+		return cfg.PeerCache.Validate()
+	}
+	return nil
 }
 
 // IsMemcacheSet returns whether a non empty Memcache config is set or not, based on the configured
@@ -82,6 +100,11 @@ func IsRedisSet(cfg Config) bool {
 	return cfg.Redis.Endpoint != ""
 }
 
+// IsDiskcacheSet returns whether the disk cache is enabled, based on the configured directory.
+func IsDiskcacheSet(cfg Config) bool {
+	return cfg.Diskcache.Directory != ""
+}
+
 // New creates a new Cache using Config.
 func New(cfg Config, reg prometheus.Registerer, logger log.Logger) (Cache, error) {
 	if cfg.Cache != nil {
@@ -100,7 +123,26 @@ func New(cfg Config, reg prometheus.Registerer, logger log.Logger) (Cache, error
 		}
 	}
 
-	if IsMemcacheSet(cfg) && IsRedisSet(cfg) {
+	// The disk cache sits behind the in-memory cache and in front of the remote ones.
+	if IsDiskcacheSet(cfg) {
+		if cfg.Diskcache.Validity == 0 && cfg.DefaultValidity != 0 {
+			cfg.Diskcache.Validity = cfg.DefaultValidity
+		}
+
+		cache, err := NewDiskCache(cfg.Prefix+"diskcache", cfg.Diskcache, reg, logger)
+		if err != nil {
+			return nil, fmt.Errorf("disk cache setup failed: %w", err)
+		}
+		caches = append(caches, Instrument(cfg.Prefix+"diskcache", cache, reg))
+	}
+
+	remotes := 0
+	for _, set := range []bool{IsMemcacheSet(cfg), IsRedisSet(cfg), cfg.EnablePeerCache} {
+		if set {
+			remotes++
+		}
+	}
+	if remotes > 1 {
 		return nil, errors.New("use of multiple cache storage systems is not supported")
 	}
 
@@ -129,6 +171,15 @@ func New(cfg Config, reg prometheus.Registerer, logger log.Logger) (Cache, error
 		caches = append(caches, NewBackground(cacheName, cfg.Background, Instrument(cacheName, cache, reg), reg))
 	}
 
+	if cfg.EnablePeerCache {
+		if cfg.Peers == nil {
+			return nil, errors.New("peer cache enabled but no cache peers are available")
+		}
+		cacheName := cfg.Prefix + "peercache"
+		cache := NewPeerCache(cacheName, cfg.PeerCache, cfg.Peers, reg, logger)
+		caches = append(caches, NewBackground(cacheName, cfg.Background, Instrument(cacheName, cache, reg), reg))
+	}
+
 	cache := NewTiered(caches)
 	if len(caches) > 1 {
 		cache = Instrument(cfg.Prefix+"tiered", cache, reg)
@@ -0,0 +1,471 @@
+package cache
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"flag"
+	"hash/crc32"
+	"io/fs"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// The disk cache keeps every entry in its own file, named after the hash of its key:
+//
+//	magic | updated (unix nanoseconds) | key length | value length | crc32 of key and value | key | value
+//
+// Files are written to a temporary file first and then renamed, so a crash never leaves a partially
+// written entry behind. The index of the cache is rebuilt from the files found on disk at startup,
+// using their modification time, which is refreshed on every hit, to restore the LRU order.
+
+const (
+	diskCacheTmpSuffix  = ".tmp"
+	diskCacheHeaderSize = 4 + 8 + 4 + 4 + 4
+	// hex encoded 128 bits of the sha256 of the key.
+	diskCacheFileNameLen = 32
+)
+
+var (
+	diskCacheMagic = []byte{'L', 'D', 'C', 1}
+	castagnoli     = crc32.MakeTable(crc32.Castagnoli)
+
+	errDiskCacheCorrupted = errors.New("corrupted disk cache entry")
+)
+
+// DiskCacheConfig holds config for the DiskCache.
+type DiskCacheConfig struct {
+	Directory    string        `yaml:"directory"`
+	MaxSizeBytes string        `yaml:"max_size_bytes"`
+	Validity     time.Duration `yaml:"validity"`
+}
+
+// RegisterFlagsWithPrefix adds the flags required to config this to the given FlagSet
+func (cfg *DiskCacheConfig) RegisterFlagsWithPrefix(prefix, description string, f *flag.FlagSet) {
+	f.StringVar(&cfg.Directory, prefix+"diskcache.directory", "", description+"Directory to store the disk cache in. The disk cache is disabled if empty. Each cache needs its own directory.")
+	f.StringVar(&cfg.MaxSizeBytes, prefix+"diskcache.max-size-bytes", "10GB", description+"Maximum size of the disk cache in bytes. A unit suffix (KB, MB, GB) may be applied.")
+	f.DurationVar(&cfg.Validity, prefix+"diskcache.duration", 24*time.Hour, description+"The expiry duration for the disk cache.")
+}
+
+func (cfg *DiskCacheConfig) Validate() error {
+	maxSizeBytes, err := parsebytes(cfg.MaxSizeBytes)
+	if err != nil {
+		return errors.Wrap(err, "invalid DiskCache config")
+	}
+	if cfg.Directory != "" && maxSizeBytes == 0 {
+		return errors.New("invalid DiskCache config: max size must be set")
+	}
+	return nil
+}
+
+// DiskCache is a size bounded cache persisting its entries on the local disk, evicting the least recently used ones.
+// Entries are checksummed and the ones failing verification are dropped.
+type DiskCache struct {
+	dir          string
+	maxSizeBytes uint64
+	validity     time.Duration
+	logger       log.Logger
+
+	lock          sync.Mutex
+	currSizeBytes uint64
+	entries       map[string]*list.Element
+	lru           *list.List
+
+	entriesAdded     prometheus.Counter
+	entriesAddedNew  prometheus.Counter
+	entriesEvicted   prometheus.Counter
+	entriesCurrent   prometheus.Gauge
+	totalGets        prometheus.Counter
+	totalMisses      prometheus.Counter
+	staleGets        prometheus.Counter
+	corruptedEntries prometheus.Counter
+	diskBytes        prometheus.Gauge
+}
+
+type diskCacheEntry struct {
+	name string
+	size uint64
+}
+
+// NewDiskCache returns a new DiskCache, loading the entries left in its directory by a previous run.
+func NewDiskCache(name string, cfg DiskCacheConfig, reg prometheus.Registerer, logger log.Logger) (*DiskCache, error) {
+	maxSizeBytes, err := parsebytes(cfg.MaxSizeBytes)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(cfg.Directory, 0o750); err != nil {
+		return nil, errors.Wrap(err, "failed to create disk cache directory")
+	}
+
+	c := &DiskCache{
+		dir:          cfg.Directory,
+		maxSizeBytes: maxSizeBytes,
+		validity:     cfg.Validity,
+		logger:       log.With(logger, "cache", name),
+		entries:      make(map[string]*list.Element),
+		lru:          list.New(),
+
+		entriesAdded: promauto.With(reg).NewCounter(prometheus.CounterOpts{
+			Namespace:   "querier",
+			Subsystem:   "cache",
+			Name:        "added_total",
+			Help:        "The total number of Put calls on the cache",
+			ConstLabels: prometheus.Labels{"cache": name},
+		}),
+
+		entriesAddedNew: promauto.With(reg).NewCounter(prometheus.CounterOpts{
+			Namespace:   "querier",
+			Subsystem:   "cache",
+			Name:        "added_new_total",
+			Help:        "The total number of new entries added to the cache",
+			ConstLabels: prometheus.Labels{"cache": name},
+		}),
+
+		entriesEvicted: promauto.With(reg).NewCounter(prometheus.CounterOpts{
+			Namespace:   "querier",
+			Subsystem:   "cache",
+			Name:        "evicted_total",
+			Help:        "The total number of evicted entries",
+			ConstLabels: prometheus.Labels{"cache": name},
+		}),
+
+		entriesCurrent: promauto.With(reg).NewGauge(prometheus.GaugeOpts{
+			Namespace:   "querier",
+			Subsystem:   "cache",
+			Name:        "entries",
+			Help:        "The total number of entries",
+			ConstLabels: prometheus.Labels{"cache": name},
+		}),
+
+		totalGets: promauto.With(reg).NewCounter(prometheus.CounterOpts{
+			Namespace:   "querier",
+			Subsystem:   "cache",
+			Name:        "gets_total",
+			Help:        "The total number of Get calls",
+			ConstLabels: prometheus.Labels{"cache": name},
+		}),
+
+		totalMisses: promauto.With(reg).NewCounter(prometheus.CounterOpts{
+			Namespace:   "querier",
+			Subsystem:   "cache",
+			Name:        "misses_total",
+			Help:        "The total number of Get calls that had no valid entry",
+			ConstLabels: prometheus.Labels{"cache": name},
+		}),
+
+		staleGets: promauto.With(reg).NewCounter(prometheus.CounterOpts{
+			Namespace:   "querier",
+			Subsystem:   "cache",
+			Name:        "stale_gets_total",
+			Help:        "The total number of Get calls that had an entry which expired",
+			ConstLabels: prometheus.Labels{"cache": name},
+		}),
+
+		corruptedEntries: promauto.With(reg).NewCounter(prometheus.CounterOpts{
+			Namespace:   "querier",
+			Subsystem:   "cache",
+			Name:        "corrupted_total",
+			Help:        "The total number of entries dropped because they failed checksum verification",
+			ConstLabels: prometheus.Labels{"cache": name},
+		}),
+
+		diskBytes: promauto.With(reg).NewGauge(prometheus.GaugeOpts{
+			Namespace:   "querier",
+			Subsystem:   "cache",
+			Name:        "disk_bytes",
+			Help:        "The current size of the cache on disk in bytes",
+			ConstLabels: prometheus.Labels{"cache": name},
+		}),
+	}
+
+	if err := c.load(); err != nil {
+		return nil, errors.Wrap(err, "failed to load disk cache")
+	}
+	return c, nil
+}
+
+// load rebuilds the index from the entries found on disk, removing the leftovers of interrupted writes.
+func (c *DiskCache) load() error {
+	type file struct {
+		name    string
+		size    uint64
+		modTime time.Time
+	}
+	var files []file
+
+	err := filepath.WalkDir(c.dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		if strings.HasSuffix(path, diskCacheTmpSuffix) {
+			return os.Remove(path)
+		}
+		name := d.Name()
+		if len(name) != diskCacheFileNameLen || c.path(name) != path {
+			// not a cache entry.
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		files = append(files, file{name: name, size: uint64(info.Size()), modTime: info.ModTime()})
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	// oldest first, so that the most recently used entries end up at the front.
+	sort.Slice(files, func(i, j int) bool { return files[i].modTime.Before(files[j].modTime) })
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	for _, f := range files {
+		c.entries[f.name] = c.lru.PushFront(&diskCacheEntry{name: f.name, size: f.size})
+		c.currSizeBytes += f.size
+	}
+	c.evict(0)
+	c.entriesCurrent.Set(float64(len(c.entries)))
+	c.diskBytes.Set(float64(c.currSizeBytes))
+
+	level.Info(c.logger).Log("msg", "loaded disk cache", "entries", len(c.entries), "bytes", c.currSizeBytes)
+	return nil
+}
+
+// Fetch implements Cache.
+func (c *DiskCache) Fetch(ctx context.Context, keys []string) (found []string, bufs [][]byte, missing []string, err error) {
+	found, missing, bufs = make([]string, 0, len(keys)), make([]string, 0, len(keys)), make([][]byte, 0, len(keys))
+	for _, key := range keys {
+		val, ok := c.Get(ctx, key)
+		if !ok {
+			missing = append(missing, key)
+			continue
+		}
+
+		found = append(found, key)
+		bufs = append(bufs, val)
+	}
+	return
+}
+
+// Store implements Cache.
+func (c *DiskCache) Store(ctx context.Context, keys []string, values [][]byte) error {
+	c.entriesAdded.Inc()
+
+	for i := range keys {
+		if err := c.put(keys[i], values[i]); err != nil {
+			level.Warn(c.logger).Log("msg", "failed to store entry in disk cache", "key", keys[i], "err", err)
+		}
+	}
+	return nil
+}
+
+// Stop implements Cache. The entries are kept on disk to be reused on the next start.
+func (c *DiskCache) Stop() {}
+
+func (c *DiskCache) put(key string, value []byte) error {
+	entry := encodeDiskCacheEntry(key, value, time.Now())
+	size := uint64(len(entry))
+	if size > c.maxSizeBytes {
+		// Cannot keep this item in the cache.
+		return nil
+	}
+
+	name := diskCacheFileName(key)
+	path := c.path(name)
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return err
+	}
+	tmp, err := writeDiskCacheTmpFile(filepath.Dir(path), entry)
+	if err != nil {
+		return err
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if err := os.Rename(tmp, path); err != nil {
+		_ = os.Remove(tmp)
+		return err
+	}
+
+	element, ok := c.entries[name]
+	if ok {
+		c.currSizeBytes -= element.Value.(*diskCacheEntry).size
+		c.lru.Remove(element)
+		delete(c.entries, name)
+	} else {
+		c.entriesAddedNew.Inc()
+	}
+
+	c.evict(size)
+	c.entries[name] = c.lru.PushFront(&diskCacheEntry{name: name, size: size})
+	c.currSizeBytes += size
+	c.entriesCurrent.Set(float64(len(c.entries)))
+	c.diskBytes.Set(float64(c.currSizeBytes))
+	return nil
+}
+
+// evict removes the least recently used entries until there is room for the given number of bytes.
+// It must be called with the lock held.
+func (c *DiskCache) evict(size uint64) {
+	for c.currSizeBytes+size > c.maxSizeBytes {
+		last := c.lru.Back()
+		if last == nil {
+			return
+		}
+		c.remove(last.Value.(*diskCacheEntry).name)
+		c.entriesEvicted.Inc()
+	}
+}
+
+// remove deletes an entry from the index and the disk. It must be called with the lock held.
+func (c *DiskCache) remove(name string) {
+	if element, ok := c.entries[name]; ok {
+		c.currSizeBytes -= element.Value.(*diskCacheEntry).size
+		c.lru.Remove(element)
+		delete(c.entries, name)
+	}
+	if err := os.Remove(c.path(name)); err != nil && !os.IsNotExist(err) {
+		level.Warn(c.logger).Log("msg", "failed to remove disk cache entry", "name", name, "err", err)
+	}
+	c.entriesCurrent.Set(float64(len(c.entries)))
+	c.diskBytes.Set(float64(c.currSizeBytes))
+}
+
+// removeIfUnchanged removes the entry unless it got replaced since element was read from the index, so that a Get
+// dropping the entry it read doesn't remove a fresh one stored concurrently.
+func (c *DiskCache) removeIfUnchanged(name string, element *list.Element) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if current, ok := c.entries[name]; ok && current == element {
+		c.remove(name)
+	}
+}
+
+// Get returns the stored value against the key.
+func (c *DiskCache) Get(ctx context.Context, key string) ([]byte, bool) {
+	c.totalGets.Inc()
+
+	name := diskCacheFileName(key)
+	path := c.path(name)
+
+	c.lock.Lock()
+	element, ok := c.entries[name]
+	c.lock.Unlock()
+	if !ok {
+		c.totalMisses.Inc()
+		return nil, false
+	}
+
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		// The entry may have been evicted in the meantime.
+		if !os.IsNotExist(err) {
+			level.Warn(c.logger).Log("msg", "failed to read disk cache entry", "key", key, "err", err)
+		}
+		c.totalMisses.Inc()
+		return nil, false
+	}
+
+	entryKey, value, updated, err := decodeDiskCacheEntry(b)
+	if err != nil {
+		level.Warn(c.logger).Log("msg", "dropping disk cache entry", "key", key, "err", err)
+		c.corruptedEntries.Inc()
+		c.totalMisses.Inc()
+		c.removeIfUnchanged(name, element)
+		return nil, false
+	}
+	if entryKey != key {
+		// hash collision, the entry belongs to another key.
+		c.totalMisses.Inc()
+		return nil, false
+	}
+	if c.validity > 0 && time.Since(updated) >= c.validity {
+		c.totalMisses.Inc()
+		c.staleGets.Inc()
+		c.removeIfUnchanged(name, element)
+		return nil, false
+	}
+
+	c.lock.Lock()
+	if element, ok := c.entries[name]; ok {
+		c.lru.MoveToFront(element)
+	}
+	c.lock.Unlock()
+
+	// Persist the recency of the entry for the next start, best effort.
+	now := time.Now()
+	_ = os.Chtimes(path, now, now)
+
+	return value, true
+}
+
+func (c *DiskCache) path(name string) string {
+	return filepath.Join(c.dir, name[:2], name)
+}
+
+func diskCacheFileName(key string) string {
+	h := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(h[:16])
+}
+
+func writeDiskCacheTmpFile(dir string, b []byte) (string, error) {
+	f, err := ioutil.TempFile(dir, "*"+diskCacheTmpSuffix)
+	if err != nil {
+		return "", err
+	}
+	if _, err := f.Write(b); err != nil {
+		_ = f.Close()
+		_ = os.Remove(f.Name())
+		return "", err
+	}
+	if err := f.Close(); err != nil {
+		_ = os.Remove(f.Name())
+		return "", err
+	}
+	return f.Name(), nil
+}
+
+func encodeDiskCacheEntry(key string, value []byte, updated time.Time) []byte {
+	b := make([]byte, diskCacheHeaderSize, diskCacheHeaderSize+len(key)+len(value))
+	copy(b, diskCacheMagic)
+	binary.BigEndian.PutUint64(b[4:], uint64(updated.UnixNano()))
+	binary.BigEndian.PutUint32(b[12:], uint32(len(key)))
+	binary.BigEndian.PutUint32(b[16:], uint32(len(value)))
+	b = append(b, key...)
+	b = append(b, value...)
+	binary.BigEndian.PutUint32(b[20:], crc32.Checksum(b[diskCacheHeaderSize:], castagnoli))
+	return b
+}
+
+func decodeDiskCacheEntry(b []byte) (key string, value []byte, updated time.Time, err error) {
+	if len(b) < diskCacheHeaderSize || string(b[:4]) != string(diskCacheMagic) {
+		return "", nil, time.Time{}, errDiskCacheCorrupted
+	}
+	keyLen := int(binary.BigEndian.Uint32(b[12:]))
+	valueLen := int(binary.BigEndian.Uint32(b[16:]))
+	if len(b) != diskCacheHeaderSize+keyLen+valueLen {
+		return "", nil, time.Time{}, errDiskCacheCorrupted
+	}
+	if crc32.Checksum(b[diskCacheHeaderSize:], castagnoli) != binary.BigEndian.Uint32(b[20:]) {
+		return "", nil, time.Time{}, errDiskCacheCorrupted
+	}
+	updated = time.Unix(0, int64(binary.BigEndian.Uint64(b[4:])))
+	return string(b[diskCacheHeaderSize : diskCacheHeaderSize+keyLen]), b[diskCacheHeaderSize+keyLen:], updated, nil
+}
@@ -0,0 +1,150 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestDiskCache(t *testing.T, dir string, maxSizeBytes int, validity time.Duration) *DiskCache {
+	t.Helper()
+	c, err := NewDiskCache("test", DiskCacheConfig{Directory: dir, MaxSizeBytes: fmt.Sprint(maxSizeBytes), Validity: validity}, nil, log.NewNopLogger())
+	require.NoError(t, err)
+	return c
+}
+
+func diskCacheEntrySize(key string, value []byte) int {
+	return diskCacheHeaderSize + len(key) + len(value)
+}
+
+func TestDiskCache(t *testing.T) {
+	const cnt = 10
+	dir := t.TempDir()
+	ctx := context.Background()
+	value := []byte("value")
+	c := newTestDiskCache(t, dir, cnt*diskCacheEntrySize("00", value), time.Hour)
+
+	keys := []string{}
+	values := [][]byte{}
+	for i := 0; i < cnt; i++ {
+		keys = append(keys, fmt.Sprintf("%02d", i))
+		values = append(values, value)
+	}
+	require.NoError(t, c.Store(ctx, keys, values))
+	require.Equal(t, float64(cnt), testutil.ToFloat64(c.entriesCurrent))
+	require.Equal(t, float64(cnt*diskCacheEntrySize("00", value)), testutil.ToFloat64(c.diskBytes))
+
+	found, bufs, missing, err := c.Fetch(ctx, append(keys, "missing"))
+	require.NoError(t, err)
+	require.Equal(t, keys, found)
+	require.Equal(t, values, bufs)
+	require.Equal(t, []string{"missing"}, missing)
+
+	// using the first entry makes the second one the least recently used.
+	_, ok := c.Get(ctx, "00")
+	require.True(t, ok)
+	require.NoError(t, c.Store(ctx, []string{"10"}, [][]byte{value}))
+	require.Equal(t, float64(1), testutil.ToFloat64(c.entriesEvicted))
+	_, ok = c.Get(ctx, "01")
+	require.False(t, ok)
+	_, ok = c.Get(ctx, "00")
+	require.True(t, ok)
+
+	// entries larger than the cache are not kept.
+	require.NoError(t, c.Store(ctx, []string{"large"}, [][]byte{make([]byte, cnt*diskCacheEntrySize("00", value))}))
+	_, ok = c.Get(ctx, "large")
+	require.False(t, ok)
+	c.Stop()
+
+	// entries survive restarts, with their recency.
+	c = newTestDiskCache(t, dir, cnt*diskCacheEntrySize("00", value), time.Hour)
+	require.Equal(t, float64(cnt), testutil.ToFloat64(c.entriesCurrent))
+	found, _, missing, err = c.Fetch(ctx, []string{"00", "01", "10"})
+	require.NoError(t, err)
+	require.Equal(t, []string{"00", "10"}, found)
+	require.Equal(t, []string{"01"}, missing)
+
+	// a smaller cache evicts the excess entries on start.
+	c = newTestDiskCache(t, dir, 2*diskCacheEntrySize("00", value), time.Hour)
+	require.Equal(t, float64(2), testutil.ToFloat64(c.entriesCurrent))
+}
+
+func TestDiskCacheCorruption(t *testing.T) {
+	dir := t.TempDir()
+	ctx := context.Background()
+	c := newTestDiskCache(t, dir, 1<<20, time.Hour)
+
+	require.NoError(t, c.Store(ctx, []string{"a", "b"}, [][]byte{[]byte("value a"), []byte("value b")}))
+
+	path := c.path(diskCacheFileName("a"))
+	b, err := ioutil.ReadFile(path)
+	require.NoError(t, err)
+	b[len(b)-1] ^= 1
+	require.NoError(t, ioutil.WriteFile(path, b, 0o640))
+
+	found, _, missing, err := c.Fetch(ctx, []string{"a", "b"})
+	require.NoError(t, err)
+	require.Equal(t, []string{"b"}, found)
+	require.Equal(t, []string{"a"}, missing)
+	require.Equal(t, float64(1), testutil.ToFloat64(c.corruptedEntries))
+	require.Equal(t, float64(1), testutil.ToFloat64(c.entriesCurrent))
+	_, err = os.Stat(path)
+	require.True(t, os.IsNotExist(err))
+
+	// leftovers of interrupted writes are removed on start.
+	tmp := filepath.Join(filepath.Dir(c.path(diskCacheFileName("b"))), "123"+diskCacheTmpSuffix)
+	require.NoError(t, ioutil.WriteFile(tmp, []byte("partial"), 0o640))
+	c = newTestDiskCache(t, dir, 1<<20, time.Hour)
+	require.Equal(t, float64(1), testutil.ToFloat64(c.entriesCurrent))
+	_, err = os.Stat(tmp)
+	require.True(t, os.IsNotExist(err))
+}
+
+func TestDiskCacheExpiry(t *testing.T) {
+	ctx := context.Background()
+	c := newTestDiskCache(t, t.TempDir(), 1<<20, 5*time.Millisecond)
+
+	require.NoError(t, c.Store(ctx, []string{"a"}, [][]byte{[]byte("value")}))
+	_, ok := c.Get(ctx, "a")
+	require.True(t, ok)
+
+	time.Sleep(10 * time.Millisecond)
+	_, ok = c.Get(ctx, "a")
+	require.False(t, ok)
+	require.Equal(t, float64(1), testutil.ToFloat64(c.staleGets))
+	require.Equal(t, float64(0), testutil.ToFloat64(c.entriesCurrent))
+}
+
+func TestDiskCacheStaleEntryReplaced(t *testing.T) {
+	ctx := context.Background()
+	c := newTestDiskCache(t, t.TempDir(), 1<<20, time.Hour)
+
+	require.NoError(t, c.Store(ctx, []string{"a"}, [][]byte{[]byte("stale")}))
+	name := diskCacheFileName("a")
+	c.lock.Lock()
+	stale := c.entries[name]
+	c.lock.Unlock()
+
+	// a Get dropping the entry it found stale must not remove the one stored concurrently.
+	require.NoError(t, c.Store(ctx, []string{"a"}, [][]byte{[]byte("fresh")}))
+	c.removeIfUnchanged(name, stale)
+
+	value, ok := c.Get(ctx, "a")
+	require.True(t, ok)
+	require.Equal(t, []byte("fresh"), value)
+
+	c.lock.Lock()
+	fresh := c.entries[name]
+	c.lock.Unlock()
+	c.removeIfUnchanged(name, fresh)
+	_, ok = c.Get(ctx, "a")
+	require.False(t, ok)
+}
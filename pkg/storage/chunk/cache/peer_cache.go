@@ -0,0 +1,300 @@
+package cache
+
+import (
+	"context"
+	"flag"
+	"sync"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/grafana/dskit/concurrency"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"github.com/grafana/loki/pkg/storage/chunk/cache/peerpb"
+)
+
+// PeerCacheConfig holds config for the PeerCache.
+type PeerCacheConfig struct {
+	MaxSizeBytes         string        `yaml:"max_size_bytes"`
+	Validity             time.Duration `yaml:"validity"`
+	Timeout              time.Duration `yaml:"timeout"`
+	HotKeyThreshold      int           `yaml:"hot_key_threshold"`
+	HotKeyWindow         time.Duration `yaml:"hot_key_window"`
+	HotCacheMaxSizeBytes string        `yaml:"hot_cache_max_size_bytes"`
+}
+
+// RegisterFlagsWithPrefix adds the flags required to config this to the given FlagSet
+func (cfg *PeerCacheConfig) RegisterFlagsWithPrefix(prefix, description string, f *flag.FlagSet) {
+	f.StringVar(&cfg.MaxSizeBytes, prefix+"peercache.max-size-bytes", "1GB", description+"Maximum memory size of the share of the cache owned by each peer. A unit suffix (KB, MB, GB) may be applied.")
+	f.DurationVar(&cfg.Validity, prefix+"peercache.duration", time.Hour, description+"The expiry duration for the peer cache.")
+	f.DurationVar(&cfg.Timeout, prefix+"peercache.timeout", 500*time.Millisecond, description+"Maximum time to wait before giving up on requests to cache peers.")
+	f.IntVar(&cfg.HotKeyThreshold, prefix+"peercache.hot-key-threshold", 0, description+"Number of times a key has to be fetched from another peer within the hot key window to be replicated locally. 0 disables the replication of hot keys.")
+	f.DurationVar(&cfg.HotKeyWindow, prefix+"peercache.hot-key-window", time.Minute, description+"Window in which the fetches of a key from other peers are counted.")
+	f.StringVar(&cfg.HotCacheMaxSizeBytes, prefix+"peercache.hot-cache-max-size-bytes", "100MB", description+"Maximum memory size of the local replicas of hot keys. A unit suffix (KB, MB, GB) may be applied.")
+}
+
+func (cfg *PeerCacheConfig) Validate() error {
+	maxSizeBytes, err := parsebytes(cfg.MaxSizeBytes)
+	if err != nil {
+		return err
+	}
+	if maxSizeBytes == 0 {
+		return errors.New("invalid PeerCache config: max size must be set")
+	}
+	_, err = parsebytes(cfg.HotCacheMaxSizeBytes)
+	return err
+}
+
+// PeerCache is a cache sharded across Peers: every key is owned by the peer the ring assigns its hash to,
+// which keeps it in memory. Keys owned by other peers are fetched and stored over gRPC.
+//
+// Keys frequently fetched from other peers are replicated in a local cache of hot keys, so that
+// the owner of a hot key doesn't get all the load.
+type PeerCache struct {
+	name    string
+	peers   *Peers
+	share   *FifoCache
+	timeout time.Duration
+	logger  log.Logger
+
+	hot             *FifoCache
+	hotKeyThreshold int
+	hotKeyWindow    time.Duration
+	hotKeysMtx      sync.Mutex
+	hotKeys         map[string]int
+	hotKeysReset    time.Time
+
+	peerRequests *prometheus.CounterVec
+	hotKeysAdded prometheus.Counter
+}
+
+// NewPeerCache creates the named PeerCache and registers the share of the cache owned by this instance in the Peers.
+// All the peers must use the same name for a given cache.
+func NewPeerCache(name string, cfg PeerCacheConfig, peers *Peers, reg prometheus.Registerer, logger log.Logger) *PeerCache {
+	c := &PeerCache{
+		name:    name,
+		peers:   peers,
+		timeout: cfg.Timeout,
+		logger:  log.With(logger, "cache", name),
+		share: NewFifoCache(name+".share", FifoCacheConfig{
+			MaxSizeBytes: cfg.MaxSizeBytes,
+			Validity:     cfg.Validity,
+		}, reg, logger),
+		hotKeyThreshold: cfg.HotKeyThreshold,
+		hotKeyWindow:    cfg.HotKeyWindow,
+		hotKeys:         map[string]int{},
+		hotKeysReset:    time.Now(),
+
+		peerRequests: promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
+			Namespace:   "querier",
+			Subsystem:   "cache",
+			Name:        "peer_requests_total",
+			Help:        "The total number of requests to the peers owning the keys, by operation and whether the owner is this instance.",
+			ConstLabels: prometheus.Labels{"cache": name},
+		}, []string{"operation", "owner", "status"}),
+		hotKeysAdded: promauto.With(reg).NewCounter(prometheus.CounterOpts{
+			Namespace:   "querier",
+			Subsystem:   "cache",
+			Name:        "hot_keys_replicated_total",
+			Help:        "The total number of hot keys replicated locally",
+			ConstLabels: prometheus.Labels{"cache": name},
+		}),
+	}
+	if cfg.HotKeyThreshold > 0 {
+		c.hot = NewFifoCache(name+".hot", FifoCacheConfig{
+			MaxSizeBytes: cfg.HotCacheMaxSizeBytes,
+			Validity:     cfg.Validity,
+		}, reg, logger)
+	}
+
+	peers.register(name, c.share)
+	return c
+}
+
+// Fetch implements Cache.
+func (c *PeerCache) Fetch(ctx context.Context, keys []string) ([]string, [][]byte, []string, error) {
+	var (
+		found []string
+		bufs  [][]byte
+	)
+	if c.hot != nil {
+		found, bufs, keys, _ = c.hot.Fetch(ctx, keys)
+	}
+
+	byOwner, err := c.peers.keysByOwner(keys)
+	if err != nil {
+		level.Debug(c.logger).Log("msg", "failed to find the cache peers owning the keys", "err", err)
+		return found, bufs, keys, nil
+	}
+
+	var mtx sync.Mutex
+	foundKeys := make(map[string][]byte, len(keys))
+	_ = concurrency.ForEach(ctx, ownersJobs(byOwner), len(byOwner), func(ctx context.Context, job interface{}) error {
+		addr := job.(string)
+		ownerKeys := selectKeys(keys, byOwner[addr])
+
+		f, b, remote, err := c.fetchFrom(ctx, addr, ownerKeys)
+		c.observe("fetch", remote, err)
+		if err != nil {
+			level.Debug(c.logger).Log("msg", "failed to fetch keys from cache peer", "peer", addr, "err", err)
+			return nil
+		}
+
+		if remote {
+			c.recordRemoteHits(ctx, f, b)
+		}
+		mtx.Lock()
+		for i := range f {
+			foundKeys[f[i]] = b[i]
+		}
+		mtx.Unlock()
+		return nil
+	})
+
+	missing := make([]string, 0, len(keys)-len(foundKeys))
+	for _, key := range keys {
+		if buf, ok := foundKeys[key]; ok {
+			found = append(found, key)
+			bufs = append(bufs, buf)
+			continue
+		}
+		missing = append(missing, key)
+	}
+	return found, bufs, missing, nil
+}
+
+func (c *PeerCache) fetchFrom(ctx context.Context, addr string, keys []string) (found []string, bufs [][]byte, remote bool, err error) {
+	if c.peers.isLocal(addr) {
+		found, bufs, _, err = c.share.Fetch(ctx, keys)
+		return found, bufs, false, err
+	}
+
+	client, err := c.peers.client(addr)
+	if err != nil {
+		return nil, nil, true, err
+	}
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+	resp, err := client.Fetch(ctx, &peerpb.FetchRequest{Cache: c.name, Keys: keys})
+	if err != nil {
+		return nil, nil, true, err
+	}
+	return resp.Found, resp.Bufs, true, nil
+}
+
+// Store implements Cache.
+func (c *PeerCache) Store(ctx context.Context, keys []string, bufs [][]byte) error {
+	byOwner, err := c.peers.keysByOwner(keys)
+	if err != nil {
+		return err
+	}
+
+	var (
+		mtx     sync.Mutex
+		lastErr error
+	)
+	_ = concurrency.ForEach(ctx, ownersJobs(byOwner), len(byOwner), func(ctx context.Context, job interface{}) error {
+		addr := job.(string)
+		ownerKeys, ownerBufs := selectKeys(keys, byOwner[addr]), selectBufs(bufs, byOwner[addr])
+
+		remote, err := c.storeTo(ctx, addr, ownerKeys, ownerBufs)
+		c.observe("store", remote, err)
+		if err != nil {
+			mtx.Lock()
+			lastErr = err
+			mtx.Unlock()
+		}
+		return nil
+	})
+	return lastErr
+}
+
+func (c *PeerCache) storeTo(ctx context.Context, addr string, keys []string, bufs [][]byte) (remote bool, err error) {
+	if c.peers.isLocal(addr) {
+		return false, c.share.Store(ctx, keys, bufs)
+	}
+
+	client, err := c.peers.client(addr)
+	if err != nil {
+		return true, err
+	}
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+	_, err = client.Store(ctx, &peerpb.StoreRequest{Cache: c.name, Keys: keys, Bufs: bufs})
+	return true, err
+}
+
+// Stop implements Cache.
+func (c *PeerCache) Stop() {
+	c.share.Stop()
+	if c.hot != nil {
+		c.hot.Stop()
+	}
+}
+
+// recordRemoteHits counts the keys fetched from other peers and replicates the ones reaching the threshold locally.
+func (c *PeerCache) recordRemoteHits(ctx context.Context, keys []string, bufs [][]byte) {
+	if c.hot == nil {
+		return
+	}
+
+	var hotKeys []string
+	var hotBufs [][]byte
+
+	c.hotKeysMtx.Lock()
+	if time.Since(c.hotKeysReset) > c.hotKeyWindow {
+		c.hotKeys = map[string]int{}
+		c.hotKeysReset = time.Now()
+	}
+	for i, key := range keys {
+		c.hotKeys[key]++
+		if c.hotKeys[key] == c.hotKeyThreshold {
+			hotKeys = append(hotKeys, key)
+			hotBufs = append(hotBufs, bufs[i])
+		}
+	}
+	c.hotKeysMtx.Unlock()
+
+	if len(hotKeys) > 0 {
+		c.hotKeysAdded.Add(float64(len(hotKeys)))
+		_ = c.hot.Store(ctx, hotKeys, hotBufs)
+	}
+}
+
+func (c *PeerCache) observe(operation string, remote bool, err error) {
+	owner, status := "local", "success"
+	if remote {
+		owner = "remote"
+	}
+	if err != nil {
+		status = "error"
+	}
+	c.peerRequests.WithLabelValues(operation, owner, status).Inc()
+}
+
+func ownersJobs(byOwner map[string][]int) []interface{} {
+	jobs := make([]interface{}, 0, len(byOwner))
+	for addr := range byOwner {
+		jobs = append(jobs, addr)
+	}
+	return jobs
+}
+
+func selectKeys(keys []string, idxs []int) []string {
+	selected := make([]string, 0, len(idxs))
+	for _, i := range idxs {
+		selected = append(selected, keys[i])
+	}
+	return selected
+}
+
+func selectBufs(bufs [][]byte, idxs []int) [][]byte {
+	selected := make([][]byte, 0, len(idxs))
+	for _, i := range idxs {
+		selected = append(selected, bufs[i])
+	}
+	return selected
+}
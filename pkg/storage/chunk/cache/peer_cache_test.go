@@ -0,0 +1,113 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/grafana/dskit/flagext"
+	"github.com/grafana/dskit/kv"
+	"github.com/grafana/dskit/kv/consul"
+	"github.com/grafana/dskit/ring"
+	"github.com/grafana/dskit/services"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+
+	"github.com/grafana/loki/pkg/storage/chunk/cache/peerpb"
+)
+
+// newTestPeers starts a peer serving its caches on a local gRPC server and joining the ring stored in the KV.
+func newTestPeers(t *testing.T, id string, ringStore kv.Client) (*Peers, *grpc.Server) {
+	t.Helper()
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	var cfg PeersConfig
+	flagext.DefaultValues(&cfg)
+	cfg.Ring.KVStore.Mock = ringStore
+	cfg.Ring.InstanceID = id
+	cfg.Ring.InstanceAddr = "127.0.0.1"
+	cfg.Ring.InstancePort = lis.Addr().(*net.TCPAddr).Port
+
+	peers, err := NewPeers(cfg, nil, log.NewNopLogger())
+	require.NoError(t, err)
+
+	server := grpc.NewServer()
+	peerpb.RegisterPeerCacheServer(server, peers)
+	go func() { _ = server.Serve(lis) }()
+	t.Cleanup(server.Stop)
+
+	require.NoError(t, services.StartAndAwaitRunning(context.Background(), peers))
+	t.Cleanup(func() { _ = services.StopAndAwaitTerminated(context.Background(), peers) })
+	return peers, server
+}
+
+func newTestPeerCache(peers *Peers, hotKeyThreshold int) *PeerCache {
+	return NewPeerCache("test", PeerCacheConfig{
+		MaxSizeBytes:         "1MB",
+		Validity:             time.Hour,
+		Timeout:              time.Second,
+		HotKeyThreshold:      hotKeyThreshold,
+		HotKeyWindow:         time.Minute,
+		HotCacheMaxSizeBytes: "1MB",
+	}, peers, nil, log.NewNopLogger())
+}
+
+func TestPeerCache(t *testing.T) {
+	ringStore, closer := consul.NewInMemoryClient(ring.GetCodec(), log.NewNopLogger(), nil)
+	t.Cleanup(func() { _ = closer.Close() })
+
+	peersA, serverA := newTestPeers(t, "a", ringStore)
+	peersB, _ := newTestPeers(t, "b", ringStore)
+	for _, p := range []*Peers{peersA, peersB} {
+		p := p
+		require.Eventually(t, func() bool { return p.ring.InstancesCount() == 2 }, 5*time.Second, 10*time.Millisecond)
+	}
+	cacheA, cacheB := newTestPeerCache(peersA, 2), newTestPeerCache(peersB, 0)
+	ctx := context.Background()
+
+	keys := []string{}
+	bufs := [][]byte{}
+	for i := 0; i < 100; i++ {
+		keys = append(keys, fmt.Sprintf("key-%d", i))
+		bufs = append(bufs, []byte(fmt.Sprintf("value-%d", i)))
+	}
+	require.NoError(t, cacheA.Store(ctx, keys, bufs))
+
+	// every key is kept once, by its owner.
+	require.Equal(t, float64(len(keys)), testutil.ToFloat64(cacheA.share.entriesCurrent)+testutil.ToFloat64(cacheB.share.entriesCurrent))
+	require.NotZero(t, testutil.ToFloat64(cacheA.share.entriesCurrent))
+	require.NotZero(t, testutil.ToFloat64(cacheB.share.entriesCurrent))
+
+	// keys are found whichever the peer they are fetched from.
+	for _, c := range []*PeerCache{cacheA, cacheB} {
+		found, fetched, missing, err := c.Fetch(ctx, append(keys, "missing"))
+		require.NoError(t, err)
+		require.ElementsMatch(t, keys, found)
+		require.Len(t, fetched, len(keys))
+		for i, key := range found {
+			require.Equal(t, "value-"+key[len("key-"):], string(fetched[i]))
+		}
+		require.Equal(t, []string{"missing"}, missing)
+	}
+
+	// keys fetched twice from peer b have been replicated in peer a.
+	require.Zero(t, testutil.ToFloat64(cacheA.hotKeysAdded))
+	_, _, _, err := cacheA.Fetch(ctx, keys)
+	require.NoError(t, err)
+	remoteKeys := testutil.ToFloat64(cacheB.share.entriesCurrent)
+	require.Equal(t, remoteKeys, testutil.ToFloat64(cacheA.hotKeysAdded))
+	require.Equal(t, remoteKeys, testutil.ToFloat64(cacheA.hot.entriesCurrent))
+
+	// the keys owned by unreachable peers are reported as missing.
+	serverA.Stop()
+	found, _, missing, err := cacheB.Fetch(ctx, keys)
+	require.NoError(t, err)
+	require.Len(t, found, int(remoteKeys))
+	require.Len(t, missing, len(keys)-int(remoteKeys))
+}
@@ -0,0 +1,306 @@
+package cache
+
+import (
+	"context"
+	"flag"
+	"hash/fnv"
+	"sync"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/grafana/dskit/grpcclient"
+	"github.com/grafana/dskit/kv"
+	dsmiddleware "github.com/grafana/dskit/middleware"
+	"github.com/grafana/dskit/ring"
+	ring_client "github.com/grafana/dskit/ring/client"
+	"github.com/grafana/dskit/services"
+	otgrpc "github.com/opentracing-contrib/go-grpc"
+	"github.com/opentracing/opentracing-go"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health/grpc_health_v1"
+
+	"github.com/grafana/loki/pkg/storage/chunk/cache/peerpb"
+	"github.com/grafana/loki/pkg/util"
+)
+
+const (
+	// peersRingKey is the key under which we store the cache peers ring in the KVStore.
+	peersRingKey = "cache-peers"
+
+	// peersRingName is the name of the ring used by the cache peers.
+	peersRingName = "cache-peers"
+
+	// peersRingNumTokens is the number of tokens of each peer, enough to spread the keys evenly.
+	peersRingNumTokens = 128
+
+	// peersRingAutoForgetUnhealthyPeriods is how many consecutive timeout periods an unhealthy peer
+	// in the ring will be automatically removed.
+	peersRingAutoForgetUnhealthyPeriods = 10
+)
+
+// peersOp selects the peer owning a key. Keys owned by unhealthy peers are not cached until they come back
+// or get forgotten.
+var peersOp = ring.NewOp([]ring.InstanceState{ring.ACTIVE}, nil)
+
+// PeersConfig configures the ring the peer caches use to shard their keys across the instances running them.
+type PeersConfig struct {
+	Ring             util.RingConfig   `yaml:"ring"`
+	GRPCClientConfig grpcclient.Config `yaml:"grpc_client_config"`
+}
+
+// RegisterFlags adds the flags required to config this to the given FlagSet
+func (cfg *PeersConfig) RegisterFlags(f *flag.FlagSet) {
+	cfg.Ring.RegisterFlagsWithPrefix("cache-peers.", "collectors/", f)
+	cfg.GRPCClientConfig.RegisterFlagsWithPrefix("cache-peers.grpc-client", f)
+}
+
+// Peers is the set of instances sharing their caches. Every peer joins a ring and owns the keys
+// whose hash falls in its token ranges, serving them from its share of the caches over gRPC.
+type Peers struct {
+	services.Service
+
+	logger log.Logger
+
+	lifecycler *ring.BasicLifecycler
+	ring       *ring.Ring
+	pool       *ring_client.Pool
+
+	subservices        *services.Manager
+	subservicesWatcher *services.FailureWatcher
+
+	mtx    sync.RWMutex
+	shares map[string]Cache
+}
+
+// NewPeers creates the Peers, registering this instance in the ring once started.
+func NewPeers(cfg PeersConfig, reg prometheus.Registerer, logger log.Logger) (*Peers, error) {
+	p := &Peers{
+		logger: logger,
+		shares: map[string]Cache{},
+	}
+
+	ringStore, err := kv.NewClient(
+		cfg.Ring.KVStore,
+		ring.GetCodec(),
+		kv.RegistererWithKVName(prometheus.WrapRegistererWithPrefix("loki_", reg), "cache-peers"),
+		logger,
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, "create KV store client")
+	}
+
+	lifecyclerCfg, err := cfg.Ring.ToLifecyclerConfig(peersRingNumTokens, logger)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid ring lifecycler config")
+	}
+
+	// Define lifecycler delegates in reverse order (last to be called defined first because they're
+	// chained via "next delegate").
+	delegate := ring.BasicLifecyclerDelegate(p)
+	delegate = ring.NewLeaveOnStoppingDelegate(delegate, logger)
+	delegate = ring.NewTokensPersistencyDelegate(cfg.Ring.TokensFilePath, ring.ACTIVE, delegate, logger)
+	delegate = ring.NewAutoForgetDelegate(peersRingAutoForgetUnhealthyPeriods*cfg.Ring.HeartbeatTimeout, delegate, logger)
+
+	p.lifecycler, err = ring.NewBasicLifecycler(lifecyclerCfg, peersRingName, peersRingKey, ringStore, delegate, logger, reg)
+	if err != nil {
+		return nil, errors.Wrap(err, "create ring lifecycler")
+	}
+
+	p.ring, err = ring.NewWithStoreClientAndStrategy(cfg.Ring.ToRingConfig(1), peersRingName, peersRingKey, ringStore, ring.NewIgnoreUnhealthyInstancesReplicationStrategy(), prometheus.WrapRegistererWithPrefix("cortex_", reg), logger)
+	if err != nil {
+		return nil, errors.Wrap(err, "create ring client")
+	}
+
+	p.pool = newPeersClientPool(cfg.GRPCClientConfig, p.ring, reg, logger)
+
+	p.subservices, err = services.NewManager(p.lifecycler, p.ring, p.pool)
+	if err != nil {
+		return nil, err
+	}
+	p.subservicesWatcher = services.NewFailureWatcher()
+	p.subservicesWatcher.WatchManager(p.subservices)
+
+	p.Service = services.NewBasicService(p.starting, p.running, p.stopping)
+	return p, nil
+}
+
+func (p *Peers) starting(ctx context.Context) error {
+	return services.StartManagerAndAwaitHealthy(ctx, p.subservices)
+}
+
+func (p *Peers) running(ctx context.Context) error {
+	select {
+	case <-ctx.Done():
+		return nil
+	case err := <-p.subservicesWatcher.Chan():
+		return errors.Wrap(err, "cache peers subservice failed")
+	}
+}
+
+func (p *Peers) stopping(_ error) error {
+	return services.StopManagerAndAwaitStopped(context.Background(), p.subservices)
+}
+
+// register makes the share of the named cache owned by this instance available to the other peers.
+func (p *Peers) register(name string, share Cache) {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	p.shares[name] = share
+}
+
+func (p *Peers) share(name string) (Cache, bool) {
+	p.mtx.RLock()
+	defer p.mtx.RUnlock()
+	share, ok := p.shares[name]
+	return share, ok
+}
+
+// keysByOwner groups the keys by the address of the peer owning them.
+func (p *Peers) keysByOwner(keys []string) (map[string][]int, error) {
+	bufDescs, bufHosts, bufZones := ring.MakeBuffersForGet()
+	byOwner := map[string][]int{}
+	for i, key := range keys {
+		rs, err := p.ring.Get(peerToken(key), peersOp, bufDescs, bufHosts, bufZones)
+		if err != nil {
+			return nil, err
+		}
+		addr := rs.Instances[0].Addr
+		byOwner[addr] = append(byOwner[addr], i)
+	}
+	return byOwner, nil
+}
+
+// isLocal returns whether the address is the one of this instance.
+func (p *Peers) isLocal(addr string) bool {
+	return addr == p.lifecycler.GetInstanceAddr()
+}
+
+func (p *Peers) client(addr string) (peerpb.PeerCacheClient, error) {
+	c, err := p.pool.GetClientFor(addr)
+	if err != nil {
+		return nil, err
+	}
+	return c.(peerpb.PeerCacheClient), nil
+}
+
+// Fetch implements peerpb.PeerCacheServer.
+func (p *Peers) Fetch(ctx context.Context, req *peerpb.FetchRequest) (*peerpb.FetchResponse, error) {
+	share, ok := p.share(req.Cache)
+	if !ok {
+		return &peerpb.FetchResponse{}, nil
+	}
+	found, bufs, _, err := share.Fetch(ctx, req.Keys)
+	if err != nil {
+		return nil, err
+	}
+	return &peerpb.FetchResponse{Found: found, Bufs: bufs}, nil
+}
+
+// Store implements peerpb.PeerCacheServer.
+func (p *Peers) Store(ctx context.Context, req *peerpb.StoreRequest) (*peerpb.StoreResponse, error) {
+	if len(req.Keys) != len(req.Bufs) {
+		return nil, errors.New("the number of keys and buffers differ")
+	}
+	share, ok := p.share(req.Cache)
+	if !ok {
+		level.Debug(p.logger).Log("msg", "dropping entries of an unknown cache", "cache", req.Cache)
+		return &peerpb.StoreResponse{}, nil
+	}
+	if err := share.Store(ctx, req.Keys, req.Bufs); err != nil {
+		return nil, err
+	}
+	return &peerpb.StoreResponse{}, nil
+}
+
+// OnRingInstanceRegister implements ring.BasicLifecyclerDelegate.
+func (p *Peers) OnRingInstanceRegister(_ *ring.BasicLifecycler, ringDesc ring.Desc, instanceExists bool, _ string, instanceDesc ring.InstanceDesc) (ring.InstanceState, ring.Tokens) {
+	// Peers have no state to hand over, whatever is the state we set it ACTIVE, while we keep existing
+	// tokens (if any).
+	var tokens []uint32
+	if instanceExists {
+		tokens = instanceDesc.GetTokens()
+	}
+
+	takenTokens := ringDesc.GetTokens()
+	newTokens := ring.GenerateTokens(peersRingNumTokens-len(tokens), takenTokens)
+
+	// Tokens sorting will be enforced by the parent caller.
+	tokens = append(tokens, newTokens...)
+
+	return ring.ACTIVE, tokens
+}
+
+func (p *Peers) OnRingInstanceTokens(_ *ring.BasicLifecycler, _ ring.Tokens) {}
+func (p *Peers) OnRingInstanceStopping(_ *ring.BasicLifecycler)              {}
+func (p *Peers) OnRingInstanceHeartbeat(_ *ring.BasicLifecycler, _ *ring.Desc, _ *ring.InstanceDesc) {
+}
+
+func peerToken(key string) uint32 {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(key))
+	return h.Sum32()
+}
+
+func newPeersClientPool(clientCfg grpcclient.Config, peersRing ring.ReadRing, reg prometheus.Registerer, logger log.Logger) *ring_client.Pool {
+	// We prefer sane defaults instead of exposing further config options.
+	poolCfg := ring_client.PoolConfig{
+		CheckInterval:      time.Minute,
+		HealthCheckEnabled: true,
+		HealthCheckTimeout: 10 * time.Second,
+	}
+
+	clientsCount := promauto.With(reg).NewGauge(prometheus.GaugeOpts{
+		Namespace: "loki",
+		Name:      "cache_peers_clients",
+		Help:      "The current number of cache peer clients in the pool.",
+	})
+	requestDuration := promauto.With(reg).NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "loki",
+		Name:      "cache_peers_client_request_duration_seconds",
+		Help:      "Time spent executing requests to the cache peers.",
+		Buckets:   prometheus.ExponentialBuckets(0.001, 4, 7),
+	}, []string{"operation", "status_code"})
+
+	factory := func(addr string) (ring_client.PoolClient, error) {
+		// Requests are not tied to a tenant so, unlike grpcclient.Instrument, the org ID is not propagated.
+		opts, err := clientCfg.DialOption(
+			[]grpc.UnaryClientInterceptor{
+				otgrpc.OpenTracingClientInterceptor(opentracing.GlobalTracer()),
+				dsmiddleware.PrometheusGRPCUnaryInstrumentation(requestDuration),
+			}, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		conn, err := grpc.Dial(addr, opts...)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to dial cache peer %s", addr)
+		}
+
+		return &peerClient{
+			PeerCacheClient: peerpb.NewPeerCacheClient(conn),
+			HealthClient:    grpc_health_v1.NewHealthClient(conn),
+			conn:            conn,
+		}, nil
+	}
+
+	return ring_client.NewPool("cache-peers", poolCfg, ring_client.NewRingServiceDiscovery(peersRing), factory, clientsCount, logger)
+}
+
+type peerClient struct {
+	peerpb.PeerCacheClient
+	grpc_health_v1.HealthClient
+	conn *grpc.ClientConn
+}
+
+func (c *peerClient) Close() error {
+	return c.conn.Close()
+}
+
+func (c *peerClient) String() string {
+	return c.conn.Target()
+}
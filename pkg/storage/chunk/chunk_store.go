@@ -76,6 +76,11 @@ func (cfg *StoreConfig) RegisterFlags(f *flag.FlagSet) {
 	f.Var(&cfg.CacheLookupsOlderThan, "store.cache-lookups-older-than", "Cache index entries older than this period. 0 to disable.")
 }
 
+// ChunkCacheStubs returns true if only stub entries should be written to the chunks cache.
+func (cfg *StoreConfig) ChunkCacheStubs() bool {
+	return cfg.chunkCacheStubs
+}
+
 // Validate validates the store config.
 func (cfg *StoreConfig) Validate(logger log.Logger) error {
 	if err := cfg.ChunkCacheConfig.Validate(); err != nil {
@@ -260,8 +265,8 @@ func (c *baseStore) LabelValuesForMetricName(ctx context.Context, userID string,
 
 }
 
-// LabelNamesForMetricName retrieves all label names for a metric name.
-func (c *store) LabelNamesForMetricName(ctx context.Context, userID string, from, through model.Time, metricName string) ([]string, error) {
+// LabelNamesForMetricName retrieves all label names for a metric name, restricted to the chunks matching the matchers.
+func (c *store) LabelNamesForMetricName(ctx context.Context, userID string, from, through model.Time, metricName string, allMatchers ...*labels.Matcher) ([]string, error) {
 	log, ctx := spanlogger.New(ctx, "ChunkStore.LabelNamesForMetricName")
 	defer log.Span.Finish()
 	level.Debug(log).Log("from", from, "through", through, "metricName", metricName)
@@ -273,7 +278,8 @@ func (c *store) LabelNamesForMetricName(ctx context.Context, userID string, from
 		return nil, nil
 	}
 
-	chunks, err := c.lookupChunksByMetricName(ctx, userID, from, through, nil, metricName)
+	filters, matchers := util.SplitFiltersAndMatchers(allMatchers)
+	chunks, err := c.lookupChunksByMetricName(ctx, userID, from, through, matchers, metricName)
 	if err != nil {
 		return nil, err
 	}
@@ -290,7 +296,9 @@ func (c *store) LabelNamesForMetricName(ctx context.Context, userID string, from
 		level.Error(log).Log("msg", "FetchChunks", "err", err)
 		return nil, err
 	}
-	return labelNamesFromChunks(allChunks), nil
+
+	// Filter out chunks based on the empty matchers in the query.
+	return labelNamesFromChunks(filterChunksByMatchers(allChunks, filters)), nil
 }
 
 func (c *baseStore) validateQueryTimeRange(ctx context.Context, userID string, from *model.Time, through *model.Time) (bool, error) {
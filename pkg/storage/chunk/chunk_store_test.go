@@ -396,20 +396,33 @@ func TestChunkStore_LabelNamesForMetricName(t *testing.T) {
 
 	for _, tc := range []struct {
 		metricName string
+		matchers   []*labels.Matcher
 		expect     []string
 	}{
 		{
 			`foo`,
+			nil,
 			[]string{labels.MetricName, "bar", "flip", "toms"},
 		},
 		{
 			`bar`,
+			nil,
+			[]string{labels.MetricName, "bar", "toms"},
+		},
+		{
+			`foo`,
+			[]*labels.Matcher{labels.MustNewMatcher(labels.MatchEqual, "bar", "beep")},
 			[]string{labels.MetricName, "bar", "toms"},
 		},
+		{
+			`foo`,
+			[]*labels.Matcher{labels.MustNewMatcher(labels.MatchRegexp, "bar", "baz|bop")},
+			[]string{labels.MetricName, "bar", "flip", "toms"},
+		},
 	} {
 		for _, schema := range schemas {
 			for _, storeCase := range stores {
-				t.Run(fmt.Sprintf("%s / %s / %s ", tc.metricName, schema, storeCase.name), func(t *testing.T) {
+				t.Run(fmt.Sprintf("%s %s / %s / %s ", tc.metricName, tc.matchers, schema, storeCase.name), func(t *testing.T) {
 					t.Log("========= Running labelNames with metricName", tc.metricName, "with schema", schema)
 					storeCfg := storeCase.configFn()
 					store, _ := newTestChunkStoreConfig(t, schema, storeCfg)
@@ -427,7 +440,7 @@ func TestChunkStore_LabelNamesForMetricName(t *testing.T) {
 					}
 
 					// Query with ordinary time-range
-					labelNames1, err := store.LabelNamesForMetricName(ctx, userID, now.Add(-time.Hour), now, tc.metricName)
+					labelNames1, err := store.LabelNamesForMetricName(ctx, userID, now.Add(-time.Hour), now, tc.metricName, tc.matchers...)
 					require.NoError(t, err)
 
 					if !reflect.DeepEqual(tc.expect, labelNames1) {
@@ -435,7 +448,7 @@ func TestChunkStore_LabelNamesForMetricName(t *testing.T) {
 					}
 
 					// Pushing end of time-range into future should yield exact same resultset
-					labelNames2, err := store.LabelNamesForMetricName(ctx, userID, now.Add(-time.Hour), now.Add(time.Hour*24*10), tc.metricName)
+					labelNames2, err := store.LabelNamesForMetricName(ctx, userID, now.Add(-time.Hour), now.Add(time.Hour*24*10), tc.metricName, tc.matchers...)
 					require.NoError(t, err)
 
 					if !reflect.DeepEqual(tc.expect, labelNames2) {
@@ -443,7 +456,7 @@ func TestChunkStore_LabelNamesForMetricName(t *testing.T) {
 					}
 
 					// Query with both begin & end of time-range in future should yield empty resultset
-					labelNames3, err := store.LabelNamesForMetricName(ctx, userID, now.Add(time.Hour), now.Add(time.Hour*2), tc.metricName)
+					labelNames3, err := store.LabelNamesForMetricName(ctx, userID, now.Add(time.Hour), now.Add(time.Hour*2), tc.metricName, tc.matchers...)
 					require.NoError(t, err)
 					if len(labelNames3) != 0 {
 						t.Fatalf("%s: future query should yield empty resultset ... actually got %v label names: %#v",
@@ -31,7 +31,7 @@ type Store interface {
 	// using the corresponding Fetcher (fetchers[i].FetchChunks(ctx, chunks[i], ...)
 	GetChunkRefs(ctx context.Context, userID string, from, through model.Time, matchers ...*labels.Matcher) ([][]Chunk, []*Fetcher, error)
 	LabelValuesForMetricName(ctx context.Context, userID string, from, through model.Time, metricName string, labelName string, matchers ...*labels.Matcher) ([]string, error)
-	LabelNamesForMetricName(ctx context.Context, userID string, from, through model.Time, metricName string) ([]string, error)
+	LabelNamesForMetricName(ctx context.Context, userID string, from, through model.Time, metricName string, matchers ...*labels.Matcher) ([]string, error)
 	GetChunkFetcher(tm model.Time) *Fetcher
 
 	// DeleteChunk deletes a chunks index entry and then deletes the actual chunk from chunk storage.
@@ -74,6 +74,11 @@ func (c *CompositeStore) AddPeriod(storeCfg StoreConfig, cfg PeriodConfig, index
 	return c.addSchema(storeCfg, SchemaConfig{Configs: []PeriodConfig{cfg}}, schema, cfg.From.Time, index, chunks, limits, chunksCache, writeDedupeCache)
 }
 
+// AddStore adds a Store, which is not built from an index and chunks client, for the period of time starting at start to the CompositeStore.
+func (c *CompositeStore) AddStore(start model.Time, store Store) {
+	c.stores = append(c.stores, compositeStoreEntry{start: start, Store: store})
+}
+
 func (c *CompositeStore) addSchema(storeCfg StoreConfig, schemaCfg SchemaConfig, schema BaseSchema, start model.Time, index IndexClient, chunks Client, limits StoreLimits, chunksCache, writeDedupeCache cache.Cache) error {
 	var (
 		err   error
@@ -140,11 +145,11 @@ func (c compositeStore) LabelValuesForMetricName(ctx context.Context, userID str
 	return result.Strings(), err
 }
 
-// LabelNamesForMetricName retrieves all label names for a metric name.
-func (c compositeStore) LabelNamesForMetricName(ctx context.Context, userID string, from, through model.Time, metricName string) ([]string, error) {
+// LabelNamesForMetricName retrieves all label names for a metric name, restricted to the series matching the matchers.
+func (c compositeStore) LabelNamesForMetricName(ctx context.Context, userID string, from, through model.Time, metricName string, matchers ...*labels.Matcher) ([]string, error) {
 	var result UniqueStrings
 	err := c.forStores(ctx, userID, from, through, func(innerCtx context.Context, from, through model.Time, store Store) error {
-		labelNames, err := store.LabelNamesForMetricName(innerCtx, userID, from, through, metricName)
+		labelNames, err := store.LabelNamesForMetricName(innerCtx, userID, from, through, metricName, matchers...)
 		if err != nil {
 			return err
 		}
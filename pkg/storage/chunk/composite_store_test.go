@@ -34,7 +34,7 @@ func (m mockStore) GetChunkRefs(tx context.Context, userID string, from, through
 	return nil, nil, nil
 }
 
-func (m mockStore) LabelNamesForMetricName(ctx context.Context, userID string, from, through model.Time, metricName string) ([]string, error) {
+func (m mockStore) LabelNamesForMetricName(ctx context.Context, userID string, from, through model.Time, metricName string, matchers ...*labels.Matcher) ([]string, error) {
 	return nil, nil
 }
 
@@ -188,7 +188,7 @@ func (m mockStoreLabel) LabelValuesForMetricName(ctx context.Context, userID str
 	return m.values, nil
 }
 
-func (m mockStoreLabel) LabelNamesForMetricName(ctx context.Context, userID string, from, through model.Time, metricName string) ([]string, error) {
+func (m mockStoreLabel) LabelNamesForMetricName(ctx context.Context, userID string, from, through model.Time, metricName string, matchers ...*labels.Matcher) ([]string, error) {
 	return m.values, nil
 }
 
@@ -0,0 +1,73 @@
+package encryption
+
+import (
+	"context"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+
+	"github.com/grafana/loki/pkg/storage/chunk/cache"
+)
+
+type encryptedCache struct {
+	next          cache.Cache
+	encrypter     *Encrypter
+	tenantFromKey TenantFunc
+	logger        log.Logger
+}
+
+// NewCache makes a new cache wrapper keeping the entries encrypted with the key of the tenant owning them.
+// Entries which can't be decrypted, e.g. because the key of the tenant was deleted, are reported as missing.
+func NewCache(next cache.Cache, encrypter *Encrypter, tenantFromKey TenantFunc, logger log.Logger) cache.Cache {
+	return &encryptedCache{
+		next:          next,
+		encrypter:     encrypter,
+		tenantFromKey: tenantFromKey,
+		logger:        logger,
+	}
+}
+
+func (c *encryptedCache) Store(ctx context.Context, keys []string, bufs [][]byte) error {
+	encKeys := make([]string, 0, len(keys))
+	encBufs := make([][]byte, 0, len(bufs))
+	for i, key := range keys {
+		tenant, err := c.tenantFromKey(key)
+		if err != nil {
+			level.Warn(c.logger).Log("msg", "failed to encrypt cache entry", "key", key, "err", err)
+			continue
+		}
+		enc, err := c.encrypter.Encrypt(ctx, tenant, bufs[i])
+		if err != nil {
+			level.Warn(c.logger).Log("msg", "failed to encrypt cache entry", "key", key, "err", err)
+			continue
+		}
+		encKeys = append(encKeys, key)
+		encBufs = append(encBufs, enc)
+	}
+	return c.next.Store(ctx, encKeys, encBufs)
+}
+
+func (c *encryptedCache) Fetch(ctx context.Context, keys []string) ([]string, [][]byte, []string, error) {
+	found, bufs, missing, err := c.next.Fetch(ctx, keys)
+
+	decFound := make([]string, 0, len(found))
+	decBufs := make([][]byte, 0, len(bufs))
+	for i, key := range found {
+		tenant, decErr := c.tenantFromKey(key)
+		if decErr == nil {
+			var buf []byte
+			if buf, decErr = c.encrypter.Decrypt(ctx, tenant, bufs[i]); decErr == nil {
+				decFound = append(decFound, key)
+				decBufs = append(decBufs, buf)
+				continue
+			}
+		}
+		level.Debug(c.logger).Log("msg", "failed to decrypt cache entry", "key", key, "err", decErr)
+		missing = append(missing, key)
+	}
+	return decFound, decBufs, missing, err
+}
+
+func (c *encryptedCache) Stop() {
+	c.next.Stop()
+}
@@ -0,0 +1,48 @@
+package encryption
+
+import (
+	"flag"
+	"fmt"
+
+	"github.com/pkg/errors"
+)
+
+// KeyProviderKeyFile reads the tenant keys from local files.
+const KeyProviderKeyFile = "keyfile"
+
+// Config configures the client-side encryption of the chunks written to object stores.
+type Config struct {
+	Enabled     bool          `yaml:"enabled"`
+	KeyProvider string        `yaml:"key_provider"`
+	KeyFile     KeyFileConfig `yaml:"keyfile"`
+}
+
+// RegisterFlags registers flags.
+func (cfg *Config) RegisterFlags(f *flag.FlagSet) {
+	f.BoolVar(&cfg.Enabled, "store.encryption.enabled", false, "Encrypt the chunks with AES-GCM using a per-tenant key before writing them to the object store. Chunks written before enabling it are still readable.")
+	f.StringVar(&cfg.KeyProvider, "store.encryption.key-provider", KeyProviderKeyFile, fmt.Sprintf("Provider of the per-tenant keys. Supported values: %s.", KeyProviderKeyFile))
+	cfg.KeyFile.RegisterFlags(f)
+}
+
+// Validate validates the config.
+func (cfg *Config) Validate() error {
+	if !cfg.Enabled {
+		return nil
+	}
+	switch cfg.KeyProvider {
+	case KeyProviderKeyFile:
+		return cfg.KeyFile.Validate()
+	default:
+		return errors.Errorf("unsupported key provider %q", cfg.KeyProvider)
+	}
+}
+
+// NewKeyProvider creates the configured KeyProvider.
+func NewKeyProvider(cfg Config) (KeyProvider, error) {
+	switch cfg.KeyProvider {
+	case KeyProviderKeyFile:
+		return NewKeyFileProvider(cfg.KeyFile), nil
+	default:
+		return nil, errors.Errorf("unsupported key provider %q", cfg.KeyProvider)
+	}
+}
@@ -0,0 +1,124 @@
+package encryption
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"io"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	keySize   = 32
+	nonceSize = 12
+	tagSize   = 16
+
+	wrappedKeySize = nonceSize + keySize + tagSize
+)
+
+var (
+	// magic prefixes the envelopes. Encoded chunks start with the length of their metadata
+	// which can never be this large, so unencrypted chunks are told apart from encrypted ones.
+	magic = []byte{'L', 'K', 'E', 1}
+
+	errInvalidEnvelope = errors.New("invalid encryption envelope")
+)
+
+// Encrypter envelope-encrypts data: every payload is encrypted with a random data key,
+// itself encrypted with the key of the tenant.
+//
+// Envelope format:
+//
+//	magic | nonce | data key encrypted with the tenant key | nonce | payload encrypted with the data key
+//
+// The tenant is authenticated as additional data, so that the data of a tenant can't be decrypted as another's.
+type Encrypter struct {
+	keys KeyProvider
+}
+
+// NewEncrypter creates an Encrypter using the tenant keys of the given KeyProvider.
+func NewEncrypter(keys KeyProvider) *Encrypter {
+	return &Encrypter{keys: keys}
+}
+
+// Encrypt encrypts the plaintext for the tenant.
+func (e *Encrypter) Encrypt(ctx context.Context, tenant string, plaintext []byte) ([]byte, error) {
+	tenantKey, err := e.keys.TenantKey(ctx, tenant)
+	if err != nil {
+		return nil, err
+	}
+
+	dataKey := make([]byte, keySize)
+	if _, err := io.ReadFull(rand.Reader, dataKey); err != nil {
+		return nil, err
+	}
+
+	out := make([]byte, 0, len(magic)+wrappedKeySize+nonceSize+len(plaintext)+tagSize)
+	out = append(out, magic...)
+	if out, err = seal(out, tenantKey, dataKey, tenant); err != nil {
+		return nil, err
+	}
+	return seal(out, dataKey, plaintext, tenant)
+}
+
+// Decrypt decrypts data encrypted for the tenant with Encrypt.
+func (e *Encrypter) Decrypt(ctx context.Context, tenant string, ciphertext []byte) ([]byte, error) {
+	if !IsEncrypted(ciphertext) {
+		return nil, errInvalidEnvelope
+	}
+	ciphertext = ciphertext[len(magic):]
+	if len(ciphertext) < wrappedKeySize+nonceSize+tagSize {
+		return nil, errInvalidEnvelope
+	}
+
+	tenantKey, err := e.keys.TenantKey(ctx, tenant)
+	if err != nil {
+		return nil, err
+	}
+
+	dataKey, err := open(tenantKey, ciphertext[:wrappedKeySize], tenant)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decrypt data key")
+	}
+	return open(dataKey, ciphertext[wrappedKeySize:], tenant)
+}
+
+// IsEncrypted returns whether the data is an envelope built by an Encrypter.
+func IsEncrypted(b []byte) bool {
+	return bytes.HasPrefix(b, magic)
+}
+
+// seal appends a random nonce and the plaintext encrypted with the key to dst.
+func seal(dst, key, plaintext []byte, tenant string) ([]byte, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, nonceSize)
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+	dst = append(dst, nonce...)
+	return aead.Seal(dst, nonce, plaintext, []byte(tenant)), nil
+}
+
+// open decrypts a nonce followed by a ciphertext built by seal.
+func open(key, b []byte, tenant string) ([]byte, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	return aead.Open(nil, b[:nonceSize], b[nonceSize:], []byte(tenant))
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
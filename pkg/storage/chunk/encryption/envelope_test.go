@@ -0,0 +1,87 @@
+package encryption
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// newTestKeyProvider creates a keyfile provider with a random key for each of the given tenants.
+func newTestKeyProvider(t *testing.T, tenants ...string) (KeyProvider, string) {
+	t.Helper()
+	dir := t.TempDir()
+	for _, tenant := range tenants {
+		key := make([]byte, keySize)
+		_, err := rand.Read(key)
+		require.NoError(t, err)
+		require.NoError(t, ioutil.WriteFile(filepath.Join(dir, tenant+keyFileSuffix), []byte(base64.StdEncoding.EncodeToString(key)+"\n"), 0o600))
+	}
+	return NewKeyFileProvider(KeyFileConfig{Directory: dir}), dir
+}
+
+func TestEncrypter(t *testing.T) {
+	keys, dir := newTestKeyProvider(t, "tenant-a", "tenant-b")
+	e := NewEncrypter(keys)
+	ctx := context.Background()
+	plaintext := []byte("some chunk bytes")
+
+	ciphertext, err := e.Encrypt(ctx, "tenant-a", plaintext)
+	require.NoError(t, err)
+	require.True(t, IsEncrypted(ciphertext))
+	require.NotContains(t, string(ciphertext), string(plaintext))
+
+	decrypted, err := e.Decrypt(ctx, "tenant-a", ciphertext)
+	require.NoError(t, err)
+	require.Equal(t, plaintext, decrypted)
+
+	// every payload gets its own data key and nonces.
+	other, err := e.Encrypt(ctx, "tenant-a", plaintext)
+	require.NoError(t, err)
+	require.NotEqual(t, ciphertext, other)
+
+	// the data of a tenant can't be decrypted as another tenant's.
+	_, err = e.Decrypt(ctx, "tenant-b", ciphertext)
+	require.Error(t, err)
+
+	// tampering is detected.
+	tampered := append([]byte{}, ciphertext...)
+	tampered[len(tampered)-1] ^= 1
+	_, err = e.Decrypt(ctx, "tenant-a", tampered)
+	require.Error(t, err)
+
+	_, err = e.Decrypt(ctx, "tenant-a", ciphertext[:len(magic)+10])
+	require.Equal(t, errInvalidEnvelope, err)
+
+	// unknown tenants can't write data.
+	_, err = e.Encrypt(ctx, "tenant-c", plaintext)
+	require.Equal(t, ErrTenantKeyNotFound, err)
+
+	// deleting the key of a tenant makes its data unreadable.
+	require.NoError(t, os.Remove(filepath.Join(dir, "tenant-a"+keyFileSuffix)))
+	_, err = e.Decrypt(ctx, "tenant-a", ciphertext)
+	require.Equal(t, ErrTenantKeyNotFound, err)
+}
+
+func TestKeyFileProvider(t *testing.T) {
+	keys, dir := newTestKeyProvider(t, "tenant")
+	ctx := context.Background()
+
+	key, err := keys.TenantKey(ctx, "tenant")
+	require.NoError(t, err)
+	require.Len(t, key, keySize)
+
+	for _, tenant := range []string{"", "..", "../tenant"} {
+		_, err = keys.TenantKey(ctx, tenant)
+		require.Error(t, err, tenant)
+	}
+
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "short"+keyFileSuffix), []byte(base64.StdEncoding.EncodeToString([]byte("too short"))), 0o600))
+	_, err = keys.TenantKey(ctx, "short")
+	require.Error(t, err)
+}
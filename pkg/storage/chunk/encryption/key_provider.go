@@ -0,0 +1,75 @@
+package encryption
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"flag"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+)
+
+const keyFileSuffix = ".key"
+
+// ErrTenantKeyNotFound is returned when there is no key for a tenant, e.g. because it has been deleted.
+var ErrTenantKeyNotFound = errors.New("tenant encryption key not found")
+
+// KeyProvider provides the key of each tenant used to encrypt the data keys of its chunks.
+type KeyProvider interface {
+	// TenantKey returns the 32 bytes AES-256 key of the tenant or ErrTenantKeyNotFound.
+	TenantKey(ctx context.Context, tenant string) ([]byte, error)
+}
+
+// KeyFileConfig configures the KeyProvider reading the tenant keys from local files.
+type KeyFileConfig struct {
+	Directory string `yaml:"directory"`
+}
+
+// RegisterFlags registers flags.
+func (cfg *KeyFileConfig) RegisterFlags(f *flag.FlagSet) {
+	f.StringVar(&cfg.Directory, "store.encryption.keyfile.directory", "", "Directory holding a <tenant>.key file per tenant, containing its base64 encoded 32 bytes key. Deleting the file of a tenant makes all its chunks unreadable.")
+}
+
+// Validate validates the config.
+func (cfg *KeyFileConfig) Validate() error {
+	if cfg.Directory == "" {
+		return errors.New("keyfile directory must be set")
+	}
+	return nil
+}
+
+type keyFileProvider struct {
+	directory string
+}
+
+// NewKeyFileProvider creates a KeyProvider reading the key of a tenant from <directory>/<tenant>.key.
+// Files are read on every lookup so that deleting a key takes effect immediately.
+func NewKeyFileProvider(cfg KeyFileConfig) KeyProvider {
+	return &keyFileProvider{directory: cfg.Directory}
+}
+
+func (p *keyFileProvider) TenantKey(_ context.Context, tenant string) ([]byte, error) {
+	if tenant == "" || tenant != filepath.Base(tenant) {
+		return nil, errors.Errorf("invalid tenant %q", tenant)
+	}
+
+	b, err := ioutil.ReadFile(filepath.Join(p.directory, tenant+keyFileSuffix))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, ErrTenantKeyNotFound
+		}
+		return nil, err
+	}
+
+	key, err := base64.StdEncoding.DecodeString(string(bytes.TrimSpace(b)))
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to decode key of tenant %s", tenant)
+	}
+	if len(key) != keySize {
+		return nil, errors.Errorf("key of tenant %s must be %d bytes long", tenant, keySize)
+	}
+	return key, nil
+}
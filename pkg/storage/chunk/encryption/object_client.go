@@ -0,0 +1,108 @@
+package encryption
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"io"
+	"io/ioutil"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/grafana/loki/pkg/storage/chunk"
+)
+
+// TenantFunc returns the tenant owning the object with the given key.
+type TenantFunc func(key string) (string, error)
+
+// TenantFromChunkKey returns the tenant of a chunk from its external key, which is always prefixed by the tenant.
+func TenantFromChunkKey(key string) (string, error) {
+	idx := strings.IndexByte(key, '/')
+	if idx <= 0 {
+		return "", errors.Errorf("no tenant found in chunk key %q", key)
+	}
+	return key[:idx], nil
+}
+
+// TenantFromBase64ChunkKey returns the tenant of a chunk from its base64 encoded external key.
+func TenantFromBase64ChunkKey(key string) (string, error) {
+	decoded, err := base64.StdEncoding.DecodeString(key)
+	if err != nil {
+		return "", errors.Wrapf(err, "invalid chunk key %q", key)
+	}
+	return TenantFromChunkKey(string(decoded))
+}
+
+type objectClient struct {
+	chunk.ObjectClient
+
+	encrypter     *Encrypter
+	tenantFromKey TenantFunc
+}
+
+// NewObjectClient wraps an object client to encrypt the objects put in it with the key of the tenant owning them.
+// Objects which are not encrypted, e.g. written before enabling encryption, are returned as is.
+// Object keys are left untouched.
+func NewObjectClient(client chunk.ObjectClient, encrypter *Encrypter, tenantFromKey TenantFunc) chunk.ObjectClient {
+	return &objectClient{
+		ObjectClient:  client,
+		encrypter:     encrypter,
+		tenantFromKey: tenantFromKey,
+	}
+}
+
+// WrapObjectClient wraps the object client storing chunks with NewObjectClient when encryption is enabled.
+func WrapObjectClient(cfg Config, client chunk.ObjectClient, tenantFromKey TenantFunc) (chunk.ObjectClient, error) {
+	if !cfg.Enabled {
+		return client, nil
+	}
+	keys, err := NewKeyProvider(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return NewObjectClient(client, NewEncrypter(keys), tenantFromKey), nil
+}
+
+func (c *objectClient) PutObject(ctx context.Context, objectKey string, object io.ReadSeeker) error {
+	tenant, err := c.tenantFromKey(objectKey)
+	if err != nil {
+		return err
+	}
+
+	plaintext, err := ioutil.ReadAll(object)
+	if err != nil {
+		return err
+	}
+
+	ciphertext, err := c.encrypter.Encrypt(ctx, tenant, plaintext)
+	if err != nil {
+		return errors.Wrapf(err, "failed to encrypt object %s", objectKey)
+	}
+	return c.ObjectClient.PutObject(ctx, objectKey, bytes.NewReader(ciphertext))
+}
+
+func (c *objectClient) GetObject(ctx context.Context, objectKey string) (io.ReadCloser, int64, error) {
+	rc, _, err := c.ObjectClient.GetObject(ctx, objectKey)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rc.Close()
+
+	b, err := ioutil.ReadAll(rc)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if IsEncrypted(b) {
+		tenant, err := c.tenantFromKey(objectKey)
+		if err != nil {
+			return nil, 0, err
+		}
+		b, err = c.encrypter.Decrypt(ctx, tenant, b)
+		if err != nil {
+			return nil, 0, errors.Wrapf(err, "failed to decrypt object %s", objectKey)
+		}
+	}
+	return ioutil.NopCloser(bytes.NewReader(b)), int64(len(b)), nil
+}
@@ -0,0 +1,90 @@
+package encryption
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-kit/log"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/loki/pkg/storage/chunk"
+	"github.com/grafana/loki/pkg/storage/chunk/cache"
+	"github.com/grafana/loki/pkg/storage/chunk/local"
+	"github.com/grafana/loki/pkg/storage/chunk/objectclient"
+)
+
+func readObject(t *testing.T, client chunk.ObjectClient, key string) []byte {
+	t.Helper()
+	rc, size, err := client.GetObject(context.Background(), key)
+	require.NoError(t, err)
+	defer rc.Close()
+
+	b, err := ioutil.ReadAll(rc)
+	require.NoError(t, err)
+	require.Equal(t, int64(len(b)), size)
+	return b
+}
+
+func TestObjectClient(t *testing.T) {
+	keys, dir := newTestKeyProvider(t, "tenant")
+	fsClient, err := local.NewFSObjectClient(local.FSConfig{Directory: t.TempDir()})
+	require.NoError(t, err)
+	client := NewObjectClient(fsClient, NewEncrypter(keys), TenantFromBase64ChunkKey)
+	ctx := context.Background()
+
+	key := objectclient.Base64Encoder("tenant/1a2b3c:17e9b6b3c5a:17e9b6b6b53:3b2c9c9b")
+	plaintext := []byte("chunk data")
+	require.NoError(t, client.PutObject(ctx, key, bytes.NewReader(plaintext)))
+
+	// the object is encrypted in the store but its key is left untouched.
+	stored := readObject(t, fsClient, key)
+	require.True(t, IsEncrypted(stored))
+	require.Equal(t, plaintext, readObject(t, client, key))
+
+	// objects written before enabling encryption are still readable.
+	legacyKey := objectclient.Base64Encoder("tenant/legacy")
+	require.NoError(t, fsClient.PutObject(ctx, legacyKey, bytes.NewReader(plaintext)))
+	require.Equal(t, plaintext, readObject(t, client, legacyKey))
+
+	// objects of tenants without a key can't be written.
+	require.Error(t, client.PutObject(ctx, objectclient.Base64Encoder("other/1a2b3c"), bytes.NewReader(plaintext)))
+
+	require.NoError(t, os.Remove(filepath.Join(dir, "tenant"+keyFileSuffix)))
+	_, _, err = client.GetObject(ctx, key)
+	require.Error(t, err)
+}
+
+func TestCache(t *testing.T) {
+	keys, dir := newTestKeyProvider(t, "tenant")
+	next := cache.NewMockCache()
+	c := NewCache(next, NewEncrypter(keys), TenantFromChunkKey, log.NewNopLogger())
+	ctx := context.Background()
+
+	require.NoError(t, c.Store(ctx, []string{"tenant/a", "tenant/b", "other/c"}, [][]byte{[]byte("a"), []byte("b"), []byte("c")}))
+
+	// entries are stored encrypted, and the ones which can't be encrypted are dropped.
+	found, bufs, missing, err := next.Fetch(ctx, []string{"tenant/a", "tenant/b", "other/c"})
+	require.NoError(t, err)
+	require.Equal(t, []string{"tenant/a", "tenant/b"}, found)
+	require.Equal(t, []string{"other/c"}, missing)
+	for _, buf := range bufs {
+		require.True(t, IsEncrypted(buf))
+	}
+
+	found, bufs, missing, err = c.Fetch(ctx, []string{"tenant/a", "tenant/b", "tenant/d"})
+	require.NoError(t, err)
+	require.Equal(t, []string{"tenant/a", "tenant/b"}, found)
+	require.Equal(t, [][]byte{[]byte("a"), []byte("b")}, bufs)
+	require.Equal(t, []string{"tenant/d"}, missing)
+
+	// entries of tenants whose key was deleted are reported as missing.
+	require.NoError(t, os.Remove(filepath.Join(dir, "tenant"+keyFileSuffix)))
+	found, _, missing, err = c.Fetch(ctx, []string{"tenant/a", "tenant/b"})
+	require.NoError(t, err)
+	require.Empty(t, found)
+	require.ElementsMatch(t, []string{"tenant/a", "tenant/b"}, missing)
+}
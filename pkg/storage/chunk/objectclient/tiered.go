@@ -0,0 +1,66 @@
+package objectclient
+
+import (
+	"context"
+	"io"
+
+	"github.com/grafana/loki/pkg/storage/chunk"
+)
+
+// TieredObjectClient reads objects from a hot object store and falls back to a cold one for the objects
+// not found in the hot store, e.g. chunks moved to a cheaper bucket by the compactor once old enough.
+// Objects are always written to the hot store, and deleted from both.
+type TieredObjectClient struct {
+	hot, cold chunk.ObjectClient
+}
+
+// NewTieredObjectClient makes a TieredObjectClient over the given hot and cold object stores.
+func NewTieredObjectClient(hot, cold chunk.ObjectClient) *TieredObjectClient {
+	return &TieredObjectClient{hot: hot, cold: cold}
+}
+
+// PutObject puts the object in the hot store.
+func (t *TieredObjectClient) PutObject(ctx context.Context, objectKey string, object io.ReadSeeker) error {
+	return t.hot.PutObject(ctx, objectKey, object)
+}
+
+// GetObject gets the object from the hot store, or from the cold store if it isn't found in the hot one.
+func (t *TieredObjectClient) GetObject(ctx context.Context, objectKey string) (io.ReadCloser, int64, error) {
+	rc, size, err := t.hot.GetObject(ctx, objectKey)
+	if err == nil || !t.hot.IsObjectNotFoundErr(err) {
+		return rc, size, err
+	}
+	return t.cold.GetObject(ctx, objectKey)
+}
+
+// List lists the objects of the hot store only.
+func (t *TieredObjectClient) List(ctx context.Context, prefix, delimiter string) ([]chunk.StorageObject, []chunk.StorageCommonPrefix, error) {
+	return t.hot.List(ctx, prefix, delimiter)
+}
+
+// DeleteObject deletes the object from both stores. It returns a not found error only when neither of them had it.
+func (t *TieredObjectClient) DeleteObject(ctx context.Context, objectKey string) error {
+	hotErr := t.hot.DeleteObject(ctx, objectKey)
+	if hotErr != nil && !t.hot.IsObjectNotFoundErr(hotErr) {
+		return hotErr
+	}
+	coldErr := t.cold.DeleteObject(ctx, objectKey)
+	if coldErr != nil && !t.cold.IsObjectNotFoundErr(coldErr) {
+		return coldErr
+	}
+	if hotErr != nil && coldErr != nil {
+		return hotErr
+	}
+	return nil
+}
+
+// IsObjectNotFoundErr returns true if the error was returned because an object wasn't found in either store.
+func (t *TieredObjectClient) IsObjectNotFoundErr(err error) bool {
+	return t.hot.IsObjectNotFoundErr(err) || t.cold.IsObjectNotFoundErr(err)
+}
+
+// Stop stops both stores.
+func (t *TieredObjectClient) Stop() {
+	t.hot.Stop()
+	t.cold.Stop()
+}
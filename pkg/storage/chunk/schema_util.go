@@ -53,6 +53,12 @@ func labelsSeriesID(ls labels.Labels) []byte {
 	return encodeBase64Bytes(h[:])
 }
 
+// LabelsSeriesIDHash returns the hash of the series ID of the labels, by which the v10+ schemas shard the index rows
+// and the ingesters shard their in-memory index. Query shards must select series with it to match both.
+func LabelsSeriesIDHash(ls labels.Labels) uint32 {
+	return binary.BigEndian.Uint32(labelsSeriesID(ls))
+}
+
 func sha256bytes(s string) []byte {
 	h := sha256.Sum256([]byte(s))
 	return encodeBase64Bytes(h[:])
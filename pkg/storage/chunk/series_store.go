@@ -196,8 +196,8 @@ func (c *seriesStore) GetChunkRefs(ctx context.Context, userID string, from, thr
 	return [][]Chunk{chunks}, []*Fetcher{c.baseStore.fetcher}, nil
 }
 
-// LabelNamesForMetricName retrieves all label names for a metric name.
-func (c *seriesStore) LabelNamesForMetricName(ctx context.Context, userID string, from, through model.Time, metricName string) ([]string, error) {
+// LabelNamesForMetricName retrieves all label names for a metric name, restricted to the series matching the matchers.
+func (c *seriesStore) LabelNamesForMetricName(ctx context.Context, userID string, from, through model.Time, metricName string, matchers ...*labels.Matcher) ([]string, error) {
 	log, ctx := spanlogger.New(ctx, "SeriesStore.LabelNamesForMetricName")
 	defer log.Span.Finish()
 
@@ -210,7 +210,7 @@ func (c *seriesStore) LabelNamesForMetricName(ctx context.Context, userID string
 	level.Debug(log).Log("metric", metricName)
 
 	// Fetch the series IDs from the index
-	seriesIDs, err := c.lookupSeriesByMetricNameMatchers(ctx, from, through, userID, metricName, nil)
+	seriesIDs, err := c.lookupSeriesByMetricNameMatchers(ctx, from, through, userID, metricName, matchers)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,86 @@
+package storage
+
+import (
+	"flag"
+	"fmt"
+
+	"github.com/pkg/errors"
+
+	"github.com/grafana/loki/pkg/storage/chunk"
+	"github.com/grafana/loki/pkg/storage/chunk/aws"
+	"github.com/grafana/loki/pkg/storage/chunk/azure"
+	"github.com/grafana/loki/pkg/storage/chunk/gcp"
+	"github.com/grafana/loki/pkg/storage/chunk/local"
+	"github.com/grafana/loki/pkg/storage/chunk/objectclient"
+	"github.com/grafana/loki/pkg/storage/chunk/openstack"
+)
+
+// ColdTierConfig configures the object store the compactor moves the chunks to once they are older than
+// the cold_tier_after limit of their tenant. Chunks are read from it when they are not found in the primary store.
+type ColdTierConfig struct {
+	ObjectStore string                  `yaml:"object_store"`
+	S3          aws.S3Config            `yaml:"s3"`
+	GCS         gcp.GCSConfig           `yaml:"gcs"`
+	Azure       azure.BlobStorageConfig `yaml:"azure"`
+	Swift       openstack.SwiftConfig   `yaml:"swift"`
+	Filesystem  local.FSConfig          `yaml:"filesystem"`
+}
+
+// RegisterFlags adds the flags required to configure this flag set.
+func (cfg *ColdTierConfig) RegisterFlags(f *flag.FlagSet) {
+	f.StringVar(&cfg.ObjectStore, "store.cold-tier.object-store", "", "Object store the old chunks are moved to. Supported types: s3, gcs, azure, swift, filesystem. Empty disables the cold tier.")
+	cfg.S3.RegisterFlagsWithPrefix("store.cold-tier.", f)
+	cfg.GCS.RegisterFlagsWithPrefix("store.cold-tier.", f)
+	cfg.Azure.RegisterFlagsWithPrefix("store.cold-tier.", f)
+	cfg.Swift.RegisterFlagsWithPrefix("store.cold-tier.", f)
+	cfg.Filesystem.RegisterFlagsWithPrefix("store.cold-tier.", f)
+}
+
+// Validate config and returns error on failure
+func (cfg *ColdTierConfig) Validate() error {
+	switch cfg.ObjectStore {
+	case "", StorageTypeGCS, StorageTypeFileSystem:
+		return nil
+	case StorageTypeAWS, StorageTypeS3:
+		return cfg.S3.Validate()
+	case StorageTypeAzure:
+		return cfg.Azure.Validate()
+	case StorageTypeSwift:
+		return cfg.Swift.Validate()
+	default:
+		return fmt.Errorf("unsupported cold tier object store %q", cfg.ObjectStore)
+	}
+}
+
+// IsEnabled returns whether an object store is configured for the cold tier.
+func (cfg *ColdTierConfig) IsEnabled() bool {
+	return cfg.ObjectStore != ""
+}
+
+// NewColdTierObjectClient makes the client of the object store configured for the cold tier.
+func NewColdTierObjectClient(cfg Config, clientMetrics ClientMetrics) (chunk.ObjectClient, error) {
+	if !cfg.ColdTier.IsEnabled() {
+		return nil, errors.New("no object store configured for the cold tier")
+	}
+
+	// the cold tier has its own object store configs, the other settings are shared with the primary store.
+	coldCfg := cfg
+	coldCfg.AWSStorageConfig.S3Config = cfg.ColdTier.S3
+	coldCfg.GCSConfig = cfg.ColdTier.GCS
+	coldCfg.AzureStorageConfig = cfg.ColdTier.Azure
+	coldCfg.Swift = cfg.ColdTier.Swift
+	coldCfg.FSConfig = cfg.ColdTier.Filesystem
+	return NewObjectClient(cfg.ColdTier.ObjectStore, coldCfg, clientMetrics)
+}
+
+// wrapColdTier makes the chunks of the primary object store fall back to the cold tier when enabled.
+func wrapColdTier(cfg Config, hot chunk.ObjectClient, clientMetrics ClientMetrics) (chunk.ObjectClient, error) {
+	if !cfg.ColdTier.IsEnabled() {
+		return hot, nil
+	}
+	cold, err := NewColdTierObjectClient(cfg, clientMetrics)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create the cold tier object client")
+	}
+	return objectclient.NewTieredObjectClient(hot, cold), nil
+}
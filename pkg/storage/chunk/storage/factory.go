@@ -17,6 +17,7 @@ import (
 	"github.com/grafana/loki/pkg/storage/chunk/azure"
 	"github.com/grafana/loki/pkg/storage/chunk/cache"
 	"github.com/grafana/loki/pkg/storage/chunk/cassandra"
+	"github.com/grafana/loki/pkg/storage/chunk/encryption"
 	"github.com/grafana/loki/pkg/storage/chunk/gcp"
 	"github.com/grafana/loki/pkg/storage/chunk/grpc"
 	"github.com/grafana/loki/pkg/storage/chunk/hedging"
@@ -70,6 +71,18 @@ func RegisterIndexStore(name string, indexClientFactory IndexClientFactoryFunc,
 	customIndexStores[name] = indexStoreFactories{indexClientFactory, tableClientFactory}
 }
 
+// StoreFactoryFunc defines signature of function which creates chunk.Store for a period using a custom index type,
+// which does not store its index in a chunk.IndexClient.
+type StoreFactoryFunc func(storeCfg chunk.StoreConfig, schemaCfg chunk.SchemaConfig, periodCfg chunk.PeriodConfig, chunks chunk.Client, limits StoreLimits, chunksCache cache.Cache) (chunk.Store, error)
+
+var customStores = map[string]StoreFactoryFunc{}
+
+// RegisterStore is used for registering a custom index type which builds its own chunk.Store for the periods using it.
+// It takes precedence over the index client registered for the same index type in NewStore.
+func RegisterStore(name string, storeFactory StoreFactoryFunc) {
+	customStores[name] = storeFactory
+}
+
 // StoreLimits helps get Limits specific to Queries for Stores
 type StoreLimits interface {
 	CardinalityLimit(userID string) int
@@ -98,6 +111,10 @@ type Config struct {
 	GrpcConfig grpc.Config `yaml:"grpc_store"`
 
 	Hedging hedging.Config `yaml:"hedging"`
+
+	Encryption encryption.Config `yaml:"encryption"`
+
+	ColdTier ColdTierConfig `yaml:"cold_tier"`
 }
 
 type ClientMetrics struct {
@@ -126,6 +143,8 @@ func (cfg *Config) RegisterFlags(f *flag.FlagSet) {
 	cfg.Swift.RegisterFlags(f)
 	cfg.GrpcConfig.RegisterFlags(f)
 	cfg.Hedging.RegisterFlagsWithPrefix("store.", f)
+	cfg.Encryption.RegisterFlags(f)
+	cfg.ColdTier.RegisterFlags(f)
 
 	f.StringVar(&cfg.Engine, "store.engine", "chunks", "The storage engine to use: chunks or blocks.")
 	cfg.IndexQueriesCacheConfig.RegisterFlagsWithPrefix("store.index-cache-read.", "Cache config for index entry reading.", f)
@@ -157,6 +176,12 @@ func (cfg *Config) Validate() error {
 	if err := cfg.AWSStorageConfig.Validate(); err != nil {
 		return errors.Wrap(err, "invalid AWS Storage config")
 	}
+	if err := cfg.Encryption.Validate(); err != nil {
+		return errors.Wrap(err, "invalid Encryption config")
+	}
+	if err := cfg.ColdTier.Validate(); err != nil {
+		return errors.Wrap(err, "invalid Cold Tier config")
+	}
 	return nil
 }
 
@@ -190,6 +215,15 @@ func NewStore(
 		return nil, err
 	}
 
+	// chunks are kept encrypted in the cache as well as in the object store.
+	if cfg.Encryption.Enabled && !cache.IsEmptyTieredCache(chunksCache) {
+		keys, err := encryption.NewKeyProvider(cfg.Encryption)
+		if err != nil {
+			return nil, err
+		}
+		chunksCache = encryption.NewCache(chunksCache, encryption.NewEncrypter(keys), encryption.TenantFromChunkKey, logger)
+	}
+
 	// Cache is shared by multiple stores, which means they will try and Stop
 	// it more than once.  Wrap in a StopOnce to prevent this.
 	indexReadCache = cache.StopOnce(indexReadCache)
@@ -209,15 +243,6 @@ func NewStore(
 	stores := chunk.NewCompositeStore(cacheGenNumLoader)
 
 	for _, s := range schemaCfg.Configs {
-		indexClientReg := prometheus.WrapRegistererWith(
-			prometheus.Labels{"component": "index-store-" + s.From.String()}, reg)
-
-		index, err := NewIndexClient(s.IndexType, cfg, schemaCfg, indexClientReg)
-		if err != nil {
-			return nil, errors.Wrap(err, "error creating index client")
-		}
-		index = newCachingIndexClient(index, indexReadCache, cfg.IndexCacheValidity, limits, logger, cfg.DisableBroadIndexQueries)
-
 		objectStoreType := s.ObjectType
 		if objectStoreType == "" {
 			objectStoreType = s.IndexType
@@ -233,6 +258,24 @@ func NewStore(
 
 		chunks = newMetricsChunkClient(chunks, chunkMetrics)
 
+		if storeFactory, ok := customStores[s.IndexType]; ok {
+			store, err := storeFactory(storeCfg, schemaCfg, s, chunks, limits, chunksCache)
+			if err != nil {
+				return nil, errors.Wrap(err, "error creating store")
+			}
+			stores.AddStore(s.From.Time, store)
+			continue
+		}
+
+		indexClientReg := prometheus.WrapRegistererWith(
+			prometheus.Labels{"component": "index-store-" + s.From.String()}, reg)
+
+		index, err := NewIndexClient(s.IndexType, cfg, schemaCfg, indexClientReg)
+		if err != nil {
+			return nil, errors.Wrap(err, "error creating index client")
+		}
+		index = newCachingIndexClient(index, indexReadCache, cfg.IndexCacheValidity, limits, logger, cfg.DisableBroadIndexQueries)
+
 		err = stores.AddPeriod(storeCfg, s, index, chunks, limits, chunksCache, writeDedupeCache)
 		if err != nil {
 			return nil, err
@@ -291,7 +334,7 @@ func NewChunkClient(name string, cfg Config, schemaCfg chunk.SchemaConfig, clien
 		if err != nil {
 			return nil, err
 		}
-		return objectclient.NewClientWithMaxParallel(c, nil, cfg.MaxParallelGetChunk, schemaCfg), nil
+		return newObjectChunkClient(cfg, c, nil, schemaCfg, clientMetrics)
 	case StorageTypeAWSDynamo:
 		if cfg.AWSStorageConfig.DynamoDB.URL == nil {
 			return nil, fmt.Errorf("Must set -dynamodb.url in aws mode")
@@ -306,7 +349,7 @@ func NewChunkClient(name string, cfg Config, schemaCfg chunk.SchemaConfig, clien
 		if err != nil {
 			return nil, err
 		}
-		return objectclient.NewClientWithMaxParallel(c, nil, cfg.MaxParallelGetChunk, schemaCfg), nil
+		return newObjectChunkClient(cfg, c, nil, schemaCfg, clientMetrics)
 	case StorageTypeGCP:
 		return gcp.NewBigtableObjectClient(context.Background(), cfg.GCPStorageConfig, schemaCfg)
 	case StorageTypeGCPColumnKey, StorageTypeBigTable, StorageTypeBigTableHashed:
@@ -316,13 +359,13 @@ func NewChunkClient(name string, cfg Config, schemaCfg chunk.SchemaConfig, clien
 		if err != nil {
 			return nil, err
 		}
-		return objectclient.NewClientWithMaxParallel(c, nil, cfg.MaxParallelGetChunk, schemaCfg), nil
+		return newObjectChunkClient(cfg, c, nil, schemaCfg, clientMetrics)
 	case StorageTypeSwift:
 		c, err := openstack.NewSwiftObjectClient(cfg.Swift, cfg.Hedging)
 		if err != nil {
 			return nil, err
 		}
-		return objectclient.NewClientWithMaxParallel(c, nil, cfg.MaxParallelGetChunk, schemaCfg), nil
+		return newObjectChunkClient(cfg, c, nil, schemaCfg, clientMetrics)
 	case StorageTypeCassandra:
 		return cassandra.NewObjectClient(cfg.CassandraStorageConfig, schemaCfg, registerer, cfg.MaxParallelGetChunk)
 	case StorageTypeFileSystem:
@@ -330,7 +373,7 @@ func NewChunkClient(name string, cfg Config, schemaCfg chunk.SchemaConfig, clien
 		if err != nil {
 			return nil, err
 		}
-		return objectclient.NewClientWithMaxParallel(store, objectclient.Base64Encoder, cfg.MaxParallelGetChunk, schemaCfg), nil
+		return newObjectChunkClient(cfg, store, objectclient.Base64Encoder, schemaCfg, clientMetrics)
 	case StorageTypeGrpc:
 		return grpc.NewStorageClient(cfg.GrpcConfig, schemaCfg)
 	default:
@@ -338,6 +381,24 @@ func NewChunkClient(name string, cfg Config, schemaCfg chunk.SchemaConfig, clien
 	}
 }
 
+// newObjectChunkClient makes a chunk.Client storing the chunks in the given object store, encrypting them if enabled.
+// Chunks not found in the object store are read from the cold tier if enabled.
+func newObjectChunkClient(cfg Config, store chunk.ObjectClient, encoder objectclient.KeyEncoder, schemaCfg chunk.SchemaConfig, clientMetrics ClientMetrics) (chunk.Client, error) {
+	tenantFromKey := encryption.TenantFromChunkKey
+	if encoder != nil {
+		tenantFromKey = encryption.TenantFromBase64ChunkKey
+	}
+	store, err := wrapColdTier(cfg, store, clientMetrics)
+	if err != nil {
+		return nil, err
+	}
+	store, err = encryption.WrapObjectClient(cfg.Encryption, store, tenantFromKey)
+	if err != nil {
+		return nil, err
+	}
+	return objectclient.NewClientWithMaxParallel(store, encoder, cfg.MaxParallelGetChunk, schemaCfg), nil
+}
+
 // NewTableClient makes a new table client based on the configuration.
 func NewTableClient(name string, cfg Config, registerer prometheus.Registerer) (chunk.TableClient, error) {
 	if indexClientFactory, ok := customIndexStores[name]; ok {
@@ -1,8 +1,11 @@
 package storage
 
 import (
+	"context"
+	"encoding/base64"
 	"io/ioutil"
 	"os"
+	"path/filepath"
 	"reflect"
 	"testing"
 	"time"
@@ -15,7 +18,9 @@ import (
 
 	"github.com/grafana/loki/pkg/storage/chunk"
 	"github.com/grafana/loki/pkg/storage/chunk/cassandra"
+	"github.com/grafana/loki/pkg/storage/chunk/encryption"
 	"github.com/grafana/loki/pkg/storage/chunk/local"
+	"github.com/grafana/loki/pkg/storage/chunk/testutils"
 )
 
 func TestFactoryStop(t *testing.T) {
@@ -201,3 +206,40 @@ func TestCassandraInMultipleSchemas(t *testing.T) {
 func unregisterAllCustomIndexStores() {
 	customIndexStores = map[string]indexStoreFactories{}
 }
+
+func TestEncryptedChunkClient(t *testing.T) {
+	keyDir := t.TempDir()
+	require.NoError(t, ioutil.WriteFile(filepath.Join(keyDir, "userID.key"), []byte(base64.StdEncoding.EncodeToString(make([]byte, 32))), 0o600))
+
+	chunksDir := t.TempDir()
+	cfg := Config{
+		FSConfig: local.FSConfig{Directory: chunksDir},
+		Encryption: encryption.Config{
+			Enabled:     true,
+			KeyProvider: encryption.KeyProviderKeyFile,
+			KeyFile:     encryption.KeyFileConfig{Directory: keyDir},
+		},
+	}
+	schemaCfg := testutils.DefaultSchemaConfig("filesystem")
+
+	client, err := NewChunkClient(StorageTypeFileSystem, cfg, schemaCfg, ClientMetrics{}, nil)
+	require.NoError(t, err)
+
+	_, chunks, err := testutils.CreateChunks(schemaCfg, 0, 1, model.Now().Add(-time.Hour), model.Now())
+	require.NoError(t, err)
+	require.NoError(t, client.PutChunks(context.Background(), chunks))
+
+	// chunks are encrypted at rest.
+	files, err := ioutil.ReadDir(chunksDir)
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+	b, err := ioutil.ReadFile(filepath.Join(chunksDir, files[0].Name()))
+	require.NoError(t, err)
+	require.True(t, encryption.IsEncrypted(b))
+
+	fetched, err := client.GetChunks(context.Background(), chunks)
+	require.NoError(t, err)
+	require.Len(t, fetched, 1)
+	require.Equal(t, chunks[0].Metric, fetched[0].Metric)
+	require.Equal(t, chunks[0].Checksum, fetched[0].Checksum)
+}
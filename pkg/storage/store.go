@@ -19,10 +19,13 @@ import (
 	"github.com/grafana/loki/pkg/logql"
 	"github.com/grafana/loki/pkg/logqlmodel/stats"
 	"github.com/grafana/loki/pkg/querier/astmapper"
+	"github.com/grafana/loki/pkg/storage/bloom"
 	"github.com/grafana/loki/pkg/storage/chunk"
+	"github.com/grafana/loki/pkg/storage/chunk/cache"
 	chunk_local "github.com/grafana/loki/pkg/storage/chunk/local"
 	"github.com/grafana/loki/pkg/storage/chunk/storage"
 	"github.com/grafana/loki/pkg/storage/stores/shipper"
+	"github.com/grafana/loki/pkg/storage/stores/tsdb"
 	"github.com/grafana/loki/pkg/tenant"
 	"github.com/grafana/loki/pkg/util"
 )
@@ -38,12 +41,16 @@ type Config struct {
 	storage.Config      `yaml:",inline"`
 	MaxChunkBatchSize   int            `yaml:"max_chunk_batch_size"`
 	BoltDBShipperConfig shipper.Config `yaml:"boltdb_shipper"`
+	TSDBShipperConfig   tsdb.Config    `yaml:"tsdb_shipper"`
+	BloomFilterConfig   bloom.Config   `yaml:"bloom_filter"`
 }
 
 // RegisterFlags adds the flags required to configure this flag set.
 func (cfg *Config) RegisterFlags(f *flag.FlagSet) {
 	cfg.Config.RegisterFlags(f)
 	cfg.BoltDBShipperConfig.RegisterFlags(f)
+	cfg.TSDBShipperConfig.RegisterFlags(f)
+	cfg.BloomFilterConfig.RegisterFlags(f)
 	f.IntVar(&cfg.MaxChunkBatchSize, "store.max-chunk-batch-size", 50, "The maximum number of chunks to fetch per batch.")
 }
 
@@ -102,6 +109,7 @@ type Store interface {
 	GetSeries(ctx context.Context, req logql.SelectLogParams) ([]logproto.SeriesIdentifier, error)
 	GetSchemaConfigs() []chunk.PeriodConfig
 	SetChunkFilterer(chunkFilter RequestChunkFilterer)
+	SetBloomStore(bloomStore *bloom.Store)
 }
 
 // RequestChunkFilterer creates ChunkFilterer for a given request context.
@@ -121,6 +129,7 @@ type store struct {
 	schemaCfg    SchemaConfig
 
 	chunkFilterer RequestChunkFilterer
+	bloomStore    *bloom.Store
 }
 
 // NewStore creates a new Loki Store using configuration supplied.
@@ -195,6 +204,71 @@ func (s *store) SetChunkFilterer(chunkFilterer RequestChunkFilterer) {
 	s.chunkFilterer = chunkFilterer
 }
 
+// SetBloomStore sets the store of the chunk bloom filters, built when chunks are put and used by queries to skip chunks.
+func (s *store) SetBloomStore(bloomStore *bloom.Store) {
+	s.bloomStore = bloomStore
+}
+
+// Put stores the chunks along with their bloom filters.
+func (s *store) Put(ctx context.Context, chunks []chunk.Chunk) error {
+	if err := s.Store.Put(ctx, chunks); err != nil {
+		return err
+	}
+	if s.bloomStore != nil {
+		s.bloomStore.PutChunks(ctx, s.schemaCfg.SchemaConfig, chunks)
+	}
+	return nil
+}
+
+func (s *store) Stop() {
+	s.Store.Stop()
+	if s.bloomStore != nil {
+		s.bloomStore.Stop()
+	}
+}
+
+// filterChunksByBloom removes the chunks which bloom filter rules out one of the literals all the lines of the query contain.
+func (s *store) filterChunksByBloom(ctx context.Context, chunks []*LazyChunk, expr logql.LogSelectorExpr) ([]*LazyChunk, error) {
+	if s.bloomStore == nil || len(chunks) == 0 {
+		return chunks, nil
+	}
+	literals := logql.RequiredLineFilters(expr)
+	if len(literals) == 0 {
+		return chunks, nil
+	}
+
+	keys := make([]string, 0, len(chunks))
+	for _, c := range chunks {
+		keys = append(keys, s.schemaCfg.ExternalKey(c.Chunk))
+	}
+	filters, err := s.bloomStore.GetChunks(ctx, keys)
+	if err != nil {
+		return nil, err
+	}
+
+	var downloaded int64
+	filtered := chunks[:0]
+outer:
+	for i, c := range chunks {
+		if filters[i] == nil {
+			filtered = append(filtered, c)
+			continue
+		}
+		downloaded++
+		for _, literal := range literals {
+			if !filters[i].MayContain(literal) {
+				continue outer
+			}
+		}
+		filtered = append(filtered, c)
+	}
+
+	stats := stats.FromContext(ctx)
+	stats.AddBloomFiltersDownloaded(downloaded)
+	stats.AddChunksSkippedByBloom(int64(len(chunks) - len(filtered)))
+	return filtered, nil
+}
+
 // lazyChunks is an internal function used to resolve a set of lazy chunks from the store without actually loading them. It's used internally by `LazyQuery` and `GetSeries`
 func (s *store) lazyChunks(ctx context.Context, matchers []*labels.Matcher, from, through model.Time) ([]*LazyChunk, error) {
 	userID, err := tenant.TenantID(ctx)
@@ -349,6 +423,11 @@ func (s *store) SelectLogs(ctx context.Context, req logql.SelectLogParams) (iter
 		return nil, err
 	}
 
+	lazyChunks, err = s.filterChunksByBloom(ctx, lazyChunks, expr)
+	if err != nil {
+		return nil, err
+	}
+
 	if len(lazyChunks) == 0 {
 		return iter.NoopIterator, nil
 	}
@@ -381,6 +460,11 @@ func (s *store) SelectSamples(ctx context.Context, req logql.SelectSampleParams)
 		return nil, err
 	}
 
+	lazyChunks, err = s.filterChunksByBloom(ctx, lazyChunks, expr.Selector())
+	if err != nil {
+		return nil, err
+	}
+
 	if len(lazyChunks) == 0 {
 		return iter.NoopIterator, nil
 	}
@@ -418,7 +502,7 @@ func RegisterCustomIndexClients(cfg *Config, cm storage.ClientMetrics, registere
 			return boltDBIndexClientWithShipper, nil
 		}
 
-		if cfg.BoltDBShipperConfig.Mode == shipper.ModeReadOnly && cfg.BoltDBShipperConfig.IndexGatewayClientConfig.Address != "" {
+		if cfg.BoltDBShipperConfig.Mode == shipper.ModeReadOnly && (cfg.BoltDBShipperConfig.IndexGatewayClientConfig.Address != "" || cfg.BoltDBShipperConfig.IndexGatewayClientConfig.Ring != nil) {
 			gateway, err := shipper.NewGatewayClient(cfg.BoltDBShipperConfig.IndexGatewayClientConfig, registerer)
 			if err != nil {
 				return nil, err
@@ -444,6 +528,49 @@ func RegisterCustomIndexClients(cfg *Config, cm storage.ClientMetrics, registere
 
 		return shipper.NewBoltDBShipperTableClient(objectClient, cfg.BoltDBShipperConfig.SharedStoreKeyPrefix), nil
 	})
+
+	// TSDB Shipper is a singleton as well, shared by the stores of all the periods using it.
+	var (
+		tsdbIndexClient tsdb.IndexClient
+		tsdbIndexWriter tsdb.IndexWriter
+	)
+
+	storage.RegisterStore(tsdb.TSDBType, func(storeCfg chunk.StoreConfig, schemaCfg chunk.SchemaConfig, periodCfg chunk.PeriodConfig, chunks chunk.Client, limits storage.StoreLimits, chunksCache cache.Cache) (chunk.Store, error) {
+		if tsdbIndexClient == nil {
+			if cfg.TSDBShipperConfig.Mode == shipper.ModeReadOnly && cfg.TSDBShipperConfig.IndexGatewayClientConfig.Address != "" {
+				gateway, err := tsdb.NewGatewayClient(cfg.TSDBShipperConfig.IndexGatewayClientConfig, registerer)
+				if err != nil {
+					return nil, err
+				}
+				tsdbIndexClient = gateway
+			} else {
+				objectClient, err := storage.NewObjectClient(cfg.TSDBShipperConfig.SharedStoreType, cfg.Config, cm)
+				if err != nil {
+					return nil, err
+				}
+
+				tsdbShipper, err := tsdb.NewShipper(cfg.TSDBShipperConfig, objectClient, registerer)
+				if err != nil {
+					return nil, err
+				}
+				tsdbIndexClient = tsdbShipper
+				if cfg.TSDBShipperConfig.Mode != shipper.ModeReadOnly {
+					tsdbIndexWriter = tsdbShipper
+				}
+			}
+		}
+
+		return tsdb.NewStore(storeCfg, schemaCfg, periodCfg, tsdbIndexClient, tsdbIndexWriter, chunks, limits, chunksCache)
+	})
+
+	storage.RegisterIndexStore(tsdb.TSDBType, nil, func() (client chunk.TableClient, e error) {
+		objectClient, err := storage.NewObjectClient(cfg.TSDBShipperConfig.SharedStoreType, cfg.Config, cm)
+		if err != nil {
+			return nil, err
+		}
+
+		return tsdb.NewTableClient(objectClient, cfg.TSDBShipperConfig.SharedStoreKeyPrefix), nil
+	})
 }
 
 // ActivePeriodConfig returns index of active PeriodicConfig which would be applicable to logs that would be pushed starting now.
@@ -469,3 +596,20 @@ func UsingBoltdbShipper(configs []chunk.PeriodConfig) bool {
 
 	return false
 }
+
+// UsingTSDB checks whether current or the next index type is tsdb, returns true if yes.
+func UsingTSDB(configs []chunk.PeriodConfig) bool {
+	activePCIndex := ActivePeriodConfig(configs)
+	if configs[activePCIndex].IndexType == tsdb.TSDBType ||
+		(len(configs)-1 > activePCIndex && configs[activePCIndex+1].IndexType == tsdb.TSDBType) {
+		return true
+	}
+
+	return false
+}
+
+// IsObjectStorageIndex returns true if the index type is one which ships the index built by ingesters
+// to the object store, i.e. boltdb-shipper or tsdb.
+func IsObjectStorageIndex(indexType string) bool {
+	return indexType == shipper.BoltDBShipperType || indexType == tsdb.TSDBType
+}
@@ -25,7 +25,9 @@ import (
 	"github.com/grafana/loki/pkg/iter"
 	"github.com/grafana/loki/pkg/logproto"
 	"github.com/grafana/loki/pkg/logql"
+	"github.com/grafana/loki/pkg/logqlmodel/stats"
 	"github.com/grafana/loki/pkg/querier/astmapper"
+	"github.com/grafana/loki/pkg/storage/bloom"
 	"github.com/grafana/loki/pkg/storage/chunk"
 	chunk_local "github.com/grafana/loki/pkg/storage/chunk/local"
 	"github.com/grafana/loki/pkg/storage/chunk/storage"
@@ -675,6 +677,72 @@ func Test_ChunkFilterer(t *testing.T) {
 	}
 }
 
+func Test_BloomFilters(t *testing.T) {
+	streams := []*logproto.Stream{
+		{Labels: `{app="foo"}`, Entries: []logproto.Entry{{Timestamp: from, Line: "msg=done trace_id=4bf92f3577b34da6"}}},
+		{Labels: `{app="bar"}`, Entries: []logproto.Entry{{Timestamp: from, Line: "msg=done trace_id=00f067aa0ba902b7"}}},
+		// no bloom filter is stored for this chunk.
+		{Labels: `{app="buzz"}`, Entries: []logproto.Entry{{Timestamp: from, Line: "msg=done trace_id=a3ce929d0e0e4736"}}},
+	}
+	chunkStore := newMockChunkStore(streams)
+
+	objectClient, err := chunk_local.NewFSObjectClient(chunk_local.FSConfig{Directory: t.TempDir()})
+	require.NoError(t, err)
+
+	s := &store{
+		Store: chunkStore,
+		cfg: Config{
+			MaxChunkBatchSize: 10,
+		},
+		chunkMetrics: NilMetrics,
+	}
+	s.SetBloomStore(bloom.NewStore(bloom.Config{
+		Enabled:              true,
+		SharedStoreKeyPrefix: "blooms/",
+		NGramLength:          4,
+		FalsePositiveRate:    0.01,
+		MaxConcurrency:       2,
+	}, objectClient, nil))
+	require.NoError(t, s.Put(ctx, chunkStore.chunks[:2]))
+
+	for _, tc := range []struct {
+		query           string
+		expectedApps    []string
+		expectedSkipped int64
+	}{
+		{`{app=~".+"} |= "trace_id=4bf92f3577b34da6"`, []string{"foo"}, 1},
+		{`{app=~".+"} |= "trace_id=a3ce929d0e0e4736"`, []string{"buzz"}, 2},
+		{`{app=~".+"} |= "msg=done" |= "00f067aa"`, []string{"bar"}, 1},
+		{`{app=~".+"} |= "trace_id" != "4bf92f3577b34da6"`, []string{"bar", "buzz"}, 0},
+		{`{app=~".+"} | line_format "{{.app}}" |= "trace_id=4bf92f3577b34da6"`, nil, 0},
+	} {
+		t.Run(tc.query, func(t *testing.T) {
+			statsCtx, ctx := stats.NewContext(user.InjectOrgID(context.Background(), "fake"))
+			it, err := s.SelectLogs(ctx, logql.SelectLogParams{QueryRequest: newQuery(tc.query, from, from.Add(time.Hour), nil)})
+			require.NoError(t, err)
+			defer it.Close()
+
+			var apps []string
+			for it.Next() {
+				apps = append(apps, mustParseLabels(it.Labels())["app"])
+			}
+			require.NoError(t, it.Error())
+			require.ElementsMatch(t, tc.expectedApps, apps)
+			require.Equal(t, tc.expectedSkipped, statsCtx.Result(0, 0).Querier.Store.TotalChunksSkippedByBloom)
+		})
+	}
+
+	statsCtx, ctx := stats.NewContext(user.InjectOrgID(context.Background(), "fake"))
+	it, err := s.SelectSamples(ctx, logql.SelectSampleParams{SampleQueryRequest: newSampleQuery(`count_over_time({app=~".+"} |= "4bf92f3577b34da6"[1h])`, from, from.Add(time.Hour))})
+	require.NoError(t, err)
+	defer it.Close()
+	for it.Next() {
+		require.Equal(t, "foo", mustParseLabels(it.Labels())["app"])
+	}
+	require.Equal(t, int64(2), statsCtx.Result(0, 0).Querier.Store.TotalBloomFiltersDownloaded)
+	require.Equal(t, int64(1), statsCtx.Result(0, 0).Querier.Store.TotalChunksSkippedByBloom)
+}
+
 func Test_store_GetSeries(t *testing.T) {
 	tests := []struct {
 		name      string
@@ -4,6 +4,7 @@ import (
 	"context"
 	"flag"
 	"fmt"
+	"hash/fnv"
 	"net/http"
 	"path/filepath"
 	"sync"
@@ -18,6 +19,9 @@ import (
 	"github.com/prometheus/common/model"
 
 	loki_storage "github.com/grafana/loki/pkg/storage"
+	"github.com/grafana/loki/pkg/storage/bloom"
+	"github.com/grafana/loki/pkg/storage/chunk"
+	"github.com/grafana/loki/pkg/storage/chunk/encryption"
 	"github.com/grafana/loki/pkg/storage/chunk/local"
 	"github.com/grafana/loki/pkg/storage/chunk/objectclient"
 	"github.com/grafana/loki/pkg/storage/chunk/storage"
@@ -26,6 +30,7 @@ import (
 	"github.com/grafana/loki/pkg/storage/stores/shipper/compactor/retention"
 	shipper_storage "github.com/grafana/loki/pkg/storage/stores/shipper/storage"
 	shipper_util "github.com/grafana/loki/pkg/storage/stores/shipper/util"
+	"github.com/grafana/loki/pkg/storage/stores/tsdb"
 	"github.com/grafana/loki/pkg/util"
 	util_log "github.com/grafana/loki/pkg/util/log"
 )
@@ -50,12 +55,17 @@ const (
 	// ringNumTokens sets our single token in the ring,
 	// we only need to insert 1 token to be used for leader election purposes.
 	ringNumTokens = 1
+
+	// ringNumTokensSharded is the number of tokens of each instance when the tables are sharded across the ring,
+	// so that they get evenly spread between the instances.
+	ringNumTokensSharded = 128
 )
 
 type Config struct {
 	WorkingDirectory          string          `yaml:"working_directory"`
 	SharedStoreType           string          `yaml:"shared_store"`
 	SharedStoreKeyPrefix      string          `yaml:"shared_store_key_prefix"`
+	TSDBSharedStoreKeyPrefix  string          `yaml:"tsdb_shared_store_key_prefix"`
 	CompactionInterval        time.Duration   `yaml:"compaction_interval"`
 	ApplyRetentionInterval    time.Duration   `yaml:"apply_retention_interval"`
 	RetentionEnabled          bool            `yaml:"retention_enabled"`
@@ -63,6 +73,16 @@ type Config struct {
 	RetentionDeleteWorkCount  int             `yaml:"retention_delete_worker_count"`
 	DeleteRequestCancelPeriod time.Duration   `yaml:"delete_request_cancel_period"`
 	MaxCompactionParallelism  int             `yaml:"max_compaction_parallelism"`
+	ChunkMergeEnabled         bool            `yaml:"chunk_merge_enabled"`
+	ChunkMergeInterval        time.Duration   `yaml:"chunk_merge_interval"`
+	ChunkMergeMaxTableAge     time.Duration   `yaml:"chunk_merge_max_table_age"`
+	ChunkMergeSmallChunkSize  int             `yaml:"chunk_merge_small_chunk_size"`
+	ChunkMergeTargetChunkSize int             `yaml:"chunk_merge_target_chunk_size"`
+	ChunkTieringEnabled       bool            `yaml:"chunk_tiering_enabled"`
+	ChunkTieringInterval      time.Duration   `yaml:"chunk_tiering_interval"`
+	ChunkTieringLookback      time.Duration   `yaml:"chunk_tiering_lookback_period"`
+	ChunkTieringDeleteDelay   time.Duration   `yaml:"chunk_tiering_delete_delay"`
+	ShardingEnabled           bool            `yaml:"sharding_enabled"`
 	CompactorRing             util.RingConfig `yaml:"compactor_ring,omitempty"`
 }
 
@@ -71,6 +91,7 @@ func (cfg *Config) RegisterFlags(f *flag.FlagSet) {
 	f.StringVar(&cfg.WorkingDirectory, "boltdb.shipper.compactor.working-directory", "", "Directory where files can be downloaded for compaction.")
 	f.StringVar(&cfg.SharedStoreType, "boltdb.shipper.compactor.shared-store", "", "Shared store used for storing boltdb files. Supported types: gcs, s3, azure, swift, filesystem")
 	f.StringVar(&cfg.SharedStoreKeyPrefix, "boltdb.shipper.compactor.shared-store.key-prefix", "index/", "Prefix to add to Object Keys in Shared store. Path separator(if any) should always be a '/'. Prefix should never start with a separator but should always end with it.")
+	f.StringVar(&cfg.TSDBSharedStoreKeyPrefix, "boltdb.shipper.compactor.tsdb-shared-store.key-prefix", "tsdb_index/", "Prefix to add to Object Keys of TSDB index files in Shared store. It should be the same as the key prefix configured for the tsdb shipper.")
 	f.DurationVar(&cfg.CompactionInterval, "boltdb.shipper.compactor.compaction-interval", 10*time.Minute, "Interval at which to re-run the compaction operation.")
 	f.DurationVar(&cfg.ApplyRetentionInterval, "boltdb.shipper.compactor.apply-retention-interval", 0, "Interval at which to apply/enforce retention. 0 means run at same interval as compaction. If non-zero, it should always be a multiple of compaction interval.")
 	f.DurationVar(&cfg.RetentionDeleteDelay, "boltdb.shipper.compactor.retention-delete-delay", 2*time.Hour, "Delay after which chunks will be fully deleted during retention.")
@@ -78,6 +99,16 @@ func (cfg *Config) RegisterFlags(f *flag.FlagSet) {
 	f.IntVar(&cfg.RetentionDeleteWorkCount, "boltdb.shipper.compactor.retention-delete-worker-count", 150, "The total amount of worker to use to delete chunks.")
 	f.DurationVar(&cfg.DeleteRequestCancelPeriod, "boltdb.shipper.compactor.delete-request-cancel-period", 24*time.Hour, "Allow cancellation of delete request until duration after they are created. Data would be deleted only after delete requests have been older than this duration. Ideally this should be set to at least 24h.")
 	f.IntVar(&cfg.MaxCompactionParallelism, "boltdb.shipper.compactor.max-compaction-parallelism", 1, "Maximum number of tables to compact in parallel. While increasing this value, please make sure compactor has enough disk space allocated to be able to store and compact as many tables.")
+	f.BoolVar(&cfg.ChunkMergeEnabled, "boltdb.shipper.compactor.chunk-merge-enabled", false, "(Experimental) Merge the adjacent small chunks of each stream into chunks of the target size. The merged chunks are deleted after the retention delete delay.")
+	f.DurationVar(&cfg.ChunkMergeInterval, "boltdb.shipper.compactor.chunk-merge-interval", 24*time.Hour, "Interval at which to merge the small chunks. It should always be a multiple of compaction interval.")
+	f.DurationVar(&cfg.ChunkMergeMaxTableAge, "boltdb.shipper.compactor.chunk-merge-max-table-age", 48*time.Hour, "Only the tables which ended within this duration get their small chunks merged. Merging requires downloading the chunks of every stream having multiple chunks in the table so older tables, which were already merged, are skipped.")
+	f.IntVar(&cfg.ChunkMergeSmallChunkSize, "boltdb.shipper.compactor.chunk-merge-small-chunk-size", 256*1024, "Chunks with a compressed size below this value are merged with the adjacent small chunks of the same stream.")
+	f.IntVar(&cfg.ChunkMergeTargetChunkSize, "boltdb.shipper.compactor.chunk-merge-target-chunk-size", 1572864, "Target compressed size of the chunks built by merging small chunks.")
+	f.BoolVar(&cfg.ChunkTieringEnabled, "boltdb.shipper.compactor.chunk-tiering-enabled", false, "(Experimental) Move the chunks older than the cold_tier_after limit of their tenant to the cold tier object store. Requires -store.cold-tier.object-store to be set.")
+	f.DurationVar(&cfg.ChunkTieringInterval, "boltdb.shipper.compactor.chunk-tiering-interval", 24*time.Hour, "Interval at which to move the old chunks to the cold tier. It should always be a multiple of compaction interval.")
+	f.DurationVar(&cfg.ChunkTieringLookback, "boltdb.shipper.compactor.chunk-tiering-lookback-period", 72*time.Hour, "Only the tables which ended within this duration after the largest cold_tier_after limit get their chunks moved to the cold tier. Older tables were already processed by previous runs.")
+	f.DurationVar(&cfg.ChunkTieringDeleteDelay, "boltdb.shipper.compactor.chunk-tiering-delete-delay", 24*time.Hour, "Delay after which the primary copies of the chunks moved to the cold tier are deleted.")
+	f.BoolVar(&cfg.ShardingEnabled, "boltdb.shipper.compactor.sharding-enabled", false, "(Experimental) Shard the tables across all the compactors of the ring instead of having the leader compact them all. Delete requests can then only be added or cancelled on the leader. All the compactors of the ring must have the same value.")
 	cfg.CompactorRing.RegisterFlagsWithPrefix("boltdb.shipper.compactor.", "collectors/", f)
 }
 
@@ -90,35 +121,60 @@ func (cfg *Config) Validate() error {
 		return errors.New("interval for applying retention should either be set to a 0 or a multiple of compaction interval")
 	}
 
+	if cfg.ChunkMergeEnabled {
+		if cfg.ChunkMergeInterval%cfg.CompactionInterval != 0 {
+			return errors.New("interval for merging chunks should be a multiple of compaction interval")
+		}
+		if cfg.ChunkMergeSmallChunkSize <= 0 || cfg.ChunkMergeTargetChunkSize <= cfg.ChunkMergeSmallChunkSize {
+			return errors.New("chunk merge target chunk size should be greater than the small chunk size")
+		}
+	}
+
+	if cfg.ChunkTieringEnabled && cfg.ChunkTieringInterval%cfg.CompactionInterval != 0 {
+		return errors.New("interval for tiering chunks should be a multiple of compaction interval")
+	}
+
+	if err := shipper_util.ValidateSharedStoreKeyPrefix(cfg.TSDBSharedStoreKeyPrefix); err != nil {
+		return err
+	}
+
 	return shipper_util.ValidateSharedStoreKeyPrefix(cfg.SharedStoreKeyPrefix)
 }
 
 type Compactor struct {
 	services.Service
 
-	cfg                   Config
-	indexStorageClient    shipper_storage.Client
-	tableMarker           retention.TableMarker
-	sweeper               *retention.Sweeper
-	deleteRequestsStore   deletion.DeleteRequestsStore
-	DeleteRequestsHandler *deletion.DeleteRequestHandler
-	deleteRequestsManager *deletion.DeleteRequestsManager
-	expirationChecker     retention.ExpirationChecker
-	metrics               *metrics
-	running               bool
-	wg                    sync.WaitGroup
-
-	// Ring used for running a single compactor
+	cfg                Config
+	indexStorageClient shipper_storage.Client
+	// tsdbIndexStorageClient is only set when any of the periods uses tsdb index.
+	tsdbIndexStorageClient shipper_storage.Client
+	tableMarker            retention.TableMarker
+	chunkMerger            retention.TableMarker
+	chunkTierer            *retention.ChunkTierer
+	sweeper                *retention.Sweeper
+	coldTierSweeper        *retention.Sweeper
+	deleteRequestsStore    deletion.DeleteRequestsStore
+	DeleteRequestsHandler  *deletion.DeleteRequestHandler
+	deleteRequestsManager  *deletion.DeleteRequestsManager
+	tablesProgress         *deletion.TablesProgress
+	expirationChecker      retention.ExpirationChecker
+	metrics                *metrics
+	running                bool
+	wg                     sync.WaitGroup
+
+	// Ring used for running a single compactor, or for sharding the tables when sharding is enabled.
 	ringLifecycler *ring.BasicLifecycler
 	ring           *ring.Ring
 	ringPollPeriod time.Duration
+	ringNumTokens  int
+	ringLastState  ring.ReplicationSet
 
 	// Subservices manager.
 	subservices        *services.Manager
 	subservicesWatcher *services.FailureWatcher
 }
 
-func NewCompactor(cfg Config, storageConfig storage.Config, schemaConfig loki_storage.SchemaConfig, limits retention.Limits, clientMetrics storage.ClientMetrics, r prometheus.Registerer) (*Compactor, error) {
+func NewCompactor(cfg Config, storageConfig storage.Config, schemaConfig loki_storage.SchemaConfig, limits retention.Limits, clientMetrics storage.ClientMetrics, bloomStore *bloom.Store, r prometheus.Registerer) (*Compactor, error) {
 	if cfg.SharedStoreType == "" {
 		return nil, errors.New("compactor shared_store_type must be specified")
 	}
@@ -126,6 +182,10 @@ func NewCompactor(cfg Config, storageConfig storage.Config, schemaConfig loki_st
 	compactor := &Compactor{
 		cfg:            cfg,
 		ringPollPeriod: 5 * time.Second,
+		ringNumTokens:  ringNumTokens,
+	}
+	if cfg.ShardingEnabled {
+		compactor.ringNumTokens = ringNumTokensSharded
 	}
 
 	ringStore, err := kv.NewClient(
@@ -137,7 +197,7 @@ func NewCompactor(cfg Config, storageConfig storage.Config, schemaConfig loki_st
 	if err != nil {
 		return nil, errors.Wrap(err, "create KV store client")
 	}
-	lifecyclerCfg, err := cfg.CompactorRing.ToLifecyclerConfig(ringNumTokens, util_log.Logger)
+	lifecyclerCfg, err := cfg.CompactorRing.ToLifecyclerConfig(compactor.ringNumTokens, util_log.Logger)
 	if err != nil {
 		return nil, errors.Wrap(err, "invalid ring lifecycler config")
 	}
@@ -167,7 +227,7 @@ func NewCompactor(cfg Config, storageConfig storage.Config, schemaConfig loki_st
 	compactor.subservicesWatcher = services.NewFailureWatcher()
 	compactor.subservicesWatcher.WatchManager(compactor.subservices)
 
-	if err := compactor.init(storageConfig, schemaConfig, limits, clientMetrics, r); err != nil {
+	if err := compactor.init(storageConfig, schemaConfig, limits, clientMetrics, bloomStore, r); err != nil {
 		return nil, err
 	}
 
@@ -175,7 +235,7 @@ func NewCompactor(cfg Config, storageConfig storage.Config, schemaConfig loki_st
 	return compactor, nil
 }
 
-func (c *Compactor) init(storageConfig storage.Config, schemaConfig loki_storage.SchemaConfig, limits retention.Limits, clientMetrics storage.ClientMetrics, r prometheus.Registerer) error {
+func (c *Compactor) init(storageConfig storage.Config, schemaConfig loki_storage.SchemaConfig, limits retention.Limits, clientMetrics storage.ClientMetrics, bloomStore *bloom.Store, r prometheus.Registerer) error {
 	objectClient, err := storage.NewObjectClient(c.cfg.SharedStoreType, storageConfig, clientMetrics)
 	if err != nil {
 		return err
@@ -186,31 +246,97 @@ func (c *Compactor) init(storageConfig storage.Config, schemaConfig loki_storage
 		return err
 	}
 	c.indexStorageClient = shipper_storage.NewIndexStorageClient(objectClient, c.cfg.SharedStoreKeyPrefix)
+	for _, periodConfig := range schemaConfig.Configs {
+		if periodConfig.IndexType == tsdb.TSDBType {
+			c.tsdbIndexStorageClient = shipper_storage.NewIndexStorageClient(objectClient, c.cfg.TSDBSharedStoreKeyPrefix)
+			break
+		}
+	}
 	c.metrics = newMetrics(r)
 
-	if c.cfg.RetentionEnabled {
-		var encoder objectclient.KeyEncoder
-		if _, ok := objectClient.(*local.FSObjectClient); ok {
-			encoder = objectclient.Base64Encoder
-		}
+	if !c.cfg.RetentionEnabled && !c.cfg.ChunkMergeEnabled && !c.cfg.ChunkTieringEnabled {
+		return nil
+	}
 
-		chunkClient := objectclient.NewClient(objectClient, encoder, schemaConfig.SchemaConfig)
+	var encoder objectclient.KeyEncoder
+	tenantFromKey := encryption.TenantFromChunkKey
+	if _, ok := objectClient.(*local.FSObjectClient); ok {
+		encoder = objectclient.Base64Encoder
+		tenantFromKey = encryption.TenantFromBase64ChunkKey
+	}
 
-		retentionWorkDir := filepath.Join(c.cfg.WorkingDirectory, "retention")
-		c.sweeper, err = retention.NewSweeper(retentionWorkDir, chunkClient, c.cfg.RetentionDeleteWorkCount, c.cfg.RetentionDeleteDelay, r)
+	// chunks moved to the cold tier must be found, and deleted, in either tier.
+	var coldObjectClient chunk.ObjectClient
+	chunkStoreClient := objectClient
+	if storageConfig.ColdTier.IsEnabled() {
+		coldObjectClient, err = storage.NewColdTierObjectClient(storageConfig, clientMetrics)
 		if err != nil {
 			return err
 		}
+		chunkStoreClient = objectclient.NewTieredObjectClient(objectClient, coldObjectClient)
+	} else if c.cfg.ChunkTieringEnabled {
+		return errors.New("chunk tiering requires the cold tier object store to be configured")
+	}
 
-		deletionWorkDir := filepath.Join(c.cfg.WorkingDirectory, "deletion")
+	// chunks rewritten by retention or merged must be encrypted like the ones flushed by the ingesters.
+	chunkObjectClient, err := encryption.WrapObjectClient(storageConfig.Encryption, chunkStoreClient, tenantFromKey)
+	if err != nil {
+		return err
+	}
+	var chunkClient chunk.Client = objectclient.NewClient(chunkObjectClient, encoder, schemaConfig.SchemaConfig)
+	if bloomStore != nil {
+		// the chunks rewritten or merged get their bloom filters built, and the deleted ones get theirs deleted.
+		// Chunks moved to the cold tier keep their filters, which are deleted along with them by retention.
+		chunkClient = bloom.NewChunkClient(chunkClient, bloomStore, schemaConfig.SchemaConfig)
+	}
 
-		c.deleteRequestsStore, err = deletion.NewDeleteStore(deletionWorkDir, c.indexStorageClient)
+	// chunks merged by the chunk merger are marked for deletion just like the ones deleted by retention.
+	retentionWorkDir := filepath.Join(c.cfg.WorkingDirectory, "retention")
+	c.sweeper, err = retention.NewSweeper(retentionWorkDir, chunkClient, c.cfg.RetentionDeleteWorkCount, c.cfg.RetentionDeleteDelay, r)
+	if err != nil {
+		return err
+	}
+
+	if c.cfg.ChunkMergeEnabled {
+		c.chunkMerger, err = retention.NewChunkMerger(retentionWorkDir, schemaConfig, chunkClient, c.cfg.ChunkMergeSmallChunkSize, c.cfg.ChunkMergeTargetChunkSize, r)
 		if err != nil {
 			return err
 		}
+	}
+
+	if c.cfg.ChunkTieringEnabled {
+		// the copies are made as is, encrypted or not, and only the primary copies are deleted once moved.
+		coldTierWorkDir := filepath.Join(c.cfg.WorkingDirectory, "cold-tier")
+		c.chunkTierer, err = retention.NewChunkTierer(coldTierWorkDir, schemaConfig, objectClient, coldObjectClient, encoder, limits, c.cfg.ChunkTieringLookback, r)
+		if err != nil {
+			return err
+		}
+		c.coldTierSweeper, err = retention.NewColdTierSweeper(coldTierWorkDir, objectclient.NewClient(objectClient, encoder, schemaConfig.SchemaConfig), c.cfg.RetentionDeleteWorkCount, c.cfg.ChunkTieringDeleteDelay, r)
+		if err != nil {
+			return err
+		}
+	}
+
+	if c.cfg.RetentionEnabled {
+		deletionWorkDir := filepath.Join(c.cfg.WorkingDirectory, "deletion")
+
+		if c.cfg.ShardingEnabled {
+			// only the leader updates the delete requests, which get applied by every instance to the tables it owns.
+			c.deleteRequestsStore, err = deletion.NewSyncedDeleteStore(deletionWorkDir, c.indexStorageClient, c.isDeleteRequestsLeader)
+			if err != nil {
+				return err
+			}
+			c.tablesProgress = deletion.NewTablesProgress(c.indexStorageClient)
+			c.deleteRequestsManager = deletion.NewShardedDeleteRequestsManager(c.deleteRequestsStore, c.cfg.DeleteRequestCancelPeriod, c.tablesProgress, c.isDeleteRequestsLeader, r)
+		} else {
+			c.deleteRequestsStore, err = deletion.NewDeleteStore(deletionWorkDir, c.indexStorageClient)
+			if err != nil {
+				return err
+			}
+			c.deleteRequestsManager = deletion.NewDeleteRequestsManager(c.deleteRequestsStore, c.cfg.DeleteRequestCancelPeriod, r)
+		}
 
 		c.DeleteRequestsHandler = deletion.NewDeleteRequestHandler(c.deleteRequestsStore, time.Hour, r)
-		c.deleteRequestsManager = deletion.NewDeleteRequestsManager(c.deleteRequestsStore, c.cfg.DeleteRequestCancelPeriod, r)
 
 		c.expirationChecker = newExpirationChecker(retention.NewExpirationChecker(limits), c.deleteRequestsManager)
 
@@ -293,19 +419,18 @@ func (c *Compactor) loop(ctx context.Context) error {
 			level.Info(util_log.Logger).Log("msg", "compactor exiting")
 			return nil
 		case <-syncTicker.C:
-			bufDescs, bufHosts, bufZones := ring.MakeBuffersForGet()
-			rs, err := c.ring.Get(ringKeyOfLeader, ring.Write, bufDescs, bufHosts, bufZones)
-			if err != nil {
-				level.Error(util_log.Logger).Log("msg", "error asking ring for who should run the compactor, will check again", "err", err)
-				continue
+			// every instance compacts the tables it owns when sharding is enabled.
+			shouldRun := c.cfg.ShardingEnabled
+			if !shouldRun {
+				leader, err := c.isLeader()
+				if err != nil {
+					level.Error(util_log.Logger).Log("msg", "error asking ring for who should run the compactor, will check again", "err", err)
+					continue
+				}
+				shouldRun = leader
 			}
 
-			addrs := rs.GetAddresses()
-			if len(addrs) != 1 {
-				level.Error(util_log.Logger).Log("msg", "too many addresses (more that one) return when asking the ring who should run the compactor, will check again")
-				continue
-			}
-			if c.ringLifecycler.GetInstanceAddr() == addrs[0] {
+			if shouldRun {
 				// If not running, start
 				if !c.running {
 					level.Info(util_log.Logger).Log("msg", "this instance has been chosen to run the compactor, starting compactor")
@@ -345,14 +470,36 @@ func (c *Compactor) runCompactions(ctx context.Context) {
 	}
 
 	lastRetentionRunAt := time.Unix(0, 0)
+	lastChunkMergeRunAt := time.Unix(0, 0)
+	lastChunkTieringRunAt := time.Unix(0, 0)
 	runCompaction := func() {
+		if c.cfg.ShardingEnabled && c.ringChanged() {
+			// the previous owners of the tables which changed hands may still be compacting them. They check that they
+			// still own a table before each step changing it, so leave them one compaction interval to finish the
+			// step they are in.
+			level.Info(util_log.Logger).Log("msg", "compactor ring changed, skipping this compaction to hand off the tables")
+			return
+		}
+
 		applyRetention := false
 		if c.cfg.RetentionEnabled && time.Since(lastRetentionRunAt) >= c.cfg.ApplyRetentionInterval {
 			level.Info(util_log.Logger).Log("msg", "applying retention with compaction")
 			applyRetention = true
 		}
 
-		err := c.RunCompaction(ctx, applyRetention)
+		mergeChunks := false
+		if c.cfg.ChunkMergeEnabled && time.Since(lastChunkMergeRunAt) >= c.cfg.ChunkMergeInterval {
+			level.Info(util_log.Logger).Log("msg", "merging small chunks with compaction")
+			mergeChunks = true
+		}
+
+		tierChunks := false
+		if c.cfg.ChunkTieringEnabled && time.Since(lastChunkTieringRunAt) >= c.cfg.ChunkTieringInterval {
+			level.Info(util_log.Logger).Log("msg", "moving old chunks to the cold tier with compaction")
+			tierChunks = true
+		}
+
+		err := c.RunCompaction(ctx, applyRetention, mergeChunks, tierChunks)
 		if err != nil {
 			level.Error(util_log.Logger).Log("msg", "failed to run compaction", "err", err)
 		}
@@ -360,6 +507,12 @@ func (c *Compactor) runCompactions(ctx context.Context) {
 		if applyRetention {
 			lastRetentionRunAt = time.Now()
 		}
+		if mergeChunks {
+			lastChunkMergeRunAt = time.Now()
+		}
+		if tierChunks {
+			lastChunkTieringRunAt = time.Now()
+		}
 	}
 
 	c.wg.Add(1)
@@ -379,7 +532,7 @@ func (c *Compactor) runCompactions(ctx context.Context) {
 			}
 		}
 	}()
-	if c.cfg.RetentionEnabled {
+	if c.sweeper != nil {
 		c.wg.Add(1)
 		go func() {
 			// starts the chunk sweeper
@@ -391,6 +544,18 @@ func (c *Compactor) runCompactions(ctx context.Context) {
 			<-ctx.Done()
 		}()
 	}
+	if c.coldTierSweeper != nil {
+		c.wg.Add(1)
+		go func() {
+			// starts the sweeper of the chunks moved to the cold tier
+			defer func() {
+				c.coldTierSweeper.Stop()
+				c.wg.Done()
+			}()
+			c.coldTierSweeper.Start()
+			<-ctx.Done()
+		}()
+	}
 	level.Info(util_log.Logger).Log("msg", "compactor started")
 }
 
@@ -398,13 +563,22 @@ func (c *Compactor) stopping(_ error) error {
 	return services.StopManagerAndAwaitStopped(context.Background(), c.subservices)
 }
 
-func (c *Compactor) CompactTable(ctx context.Context, tableName string, applyRetention bool) error {
+func (c *Compactor) CompactTable(ctx context.Context, tableName string, applyRetention, mergeChunks, tierChunks bool) error {
+	var chunkTierer retention.TableMarker
+	if c.chunkTierer != nil {
+		chunkTierer = c.chunkTierer
+	}
 	table, err := newTable(ctx, filepath.Join(c.cfg.WorkingDirectory, tableName), c.indexStorageClient,
-		c.tableMarker, c.expirationChecker)
+		c.tableMarker, c.expirationChecker, c.chunkMerger, chunkTierer)
 	if err != nil {
 		level.Error(util_log.Logger).Log("msg", "failed to initialize table for compaction", "table", tableName, "err", err)
 		return err
 	}
+	if c.cfg.ShardingEnabled {
+		table.ownsTable = func() (bool, error) {
+			return c.ownsTable(tableName)
+		}
+	}
 
 	interval := retention.ExtractIntervalFromTableName(tableName)
 	intervalMayHaveExpiredChunks := false
@@ -412,19 +586,55 @@ func (c *Compactor) CompactTable(ctx context.Context, tableName string, applyRet
 		intervalMayHaveExpiredChunks = c.expirationChecker.IntervalMayHaveExpiredChunks(interval, "")
 	}
 
-	err = table.compact(intervalMayHaveExpiredChunks)
+	// only merge the chunks of the tables which are not receiving writes anymore and were not merged enough times already.
+	tableMustBeMerged := mergeChunks && c.chunkMerger != nil &&
+		interval.End.Before(model.Now()) && interval.End.After(model.Now().Add(-c.cfg.ChunkMergeMaxTableAge))
+
+	// only tier the chunks of the tables which may have chunks old enough for a tenant and were not processed by previous runs.
+	tableMustBeTiered := tierChunks && c.chunkTierer != nil && c.chunkTierer.IntervalMayHaveColdChunks(interval)
+
+	err = table.compact(intervalMayHaveExpiredChunks, tableMustBeMerged, tableMustBeTiered)
+	if err == errTableNotOwned {
+		return err
+	}
 	if err != nil {
 		level.Error(util_log.Logger).Log("msg", "failed to compact files", "table", tableName, "err", err)
 		return err
 	}
+
+	// let the leader know the delete requests were applied to this table.
+	if intervalMayHaveExpiredChunks && c.tablesProgress != nil {
+		err = c.tablesProgress.RecordTableProcessed(ctx, tableName, c.deleteRequestsManager.DeleteRequestsToProcess())
+		if err != nil {
+			level.Error(util_log.Logger).Log("msg", "failed to record delete requests progress", "table", tableName, "err", err)
+			return err
+		}
+	}
 	return nil
 }
 
-func (c *Compactor) RunCompaction(ctx context.Context, applyRetention bool) error {
+// compactTSDBTable merges the TSDB index files of each tenant in the table. Retention is not applied to TSDB index yet,
+// which is why enabling it is rejected by the config validation when a period uses TSDB.
+func (c *Compactor) compactTSDBTable(ctx context.Context, tableName string) error {
+	err := tsdb.CompactTable(ctx, c.tsdbIndexStorageClient, tableName, filepath.Join(c.cfg.WorkingDirectory, "tsdb"))
+	if err != nil {
+		level.Error(util_log.Logger).Log("msg", "failed to compact tsdb index files", "table", tableName, "err", err)
+		return err
+	}
+	return nil
+}
+
+func (c *Compactor) RunCompaction(ctx context.Context, applyRetention, mergeChunks, tierChunks bool) error {
 	status := statusSuccess
 	start := time.Now()
 
 	if c.cfg.RetentionEnabled {
+		if store, ok := c.deleteRequestsStore.(deletion.SyncedDeleteRequestsStore); ok {
+			// load the delete requests added on the leader since the last sync.
+			if err := store.Sync(); err != nil {
+				level.Error(util_log.Logger).Log("msg", "failed to sync delete requests", "err", err)
+			}
+		}
 		c.expirationChecker.MarkPhaseStarted()
 	}
 
@@ -457,7 +667,16 @@ func (c *Compactor) RunCompaction(ctx context.Context, applyRetention bool) erro
 		return err
 	}
 
-	compactTablesChan := make(chan string)
+	var tsdbTables []string
+	if c.tsdbIndexStorageClient != nil {
+		tsdbTables, err = c.tsdbIndexStorageClient.ListTables(ctx)
+		if err != nil {
+			status = statusFailure
+			return err
+		}
+	}
+
+	compactTablesChan := make(chan tableToCompact)
 	errChan := make(chan error)
 
 	for i := 0; i < c.cfg.MaxCompactionParallelism; i++ {
@@ -469,17 +688,39 @@ func (c *Compactor) RunCompaction(ctx context.Context, applyRetention bool) erro
 
 			for {
 				select {
-				case tableName, ok := <-compactTablesChan:
+				case table, ok := <-compactTablesChan:
 					if !ok {
 						return
 					}
 
-					level.Info(util_log.Logger).Log("msg", "compacting table", "table-name", tableName)
-					err = c.CompactTable(ctx, tableName, applyRetention)
+					if c.cfg.ShardingEnabled {
+						// ownership is checked right before compacting since the ring could have changed during the run.
+						owned, err := c.ownsTable(table.name)
+						if err != nil {
+							level.Error(util_log.Logger).Log("msg", "failed to check table ownership, skipping it", "table-name", table.name, "err", err)
+							continue
+						}
+						if !owned {
+							continue
+						}
+					}
+
+					level.Info(util_log.Logger).Log("msg", "compacting table", "table-name", table.name, "tsdb", table.tsdb)
+					if table.tsdb {
+						err = c.compactTSDBTable(ctx, table.name)
+					} else {
+						err = c.CompactTable(ctx, table.name, applyRetention, mergeChunks, tierChunks)
+					}
+					if err == errTableNotOwned {
+						// the table got handed off while being compacted, its new owner takes over from here.
+						level.Info(util_log.Logger).Log("msg", "stopped compacting table handed off to another compactor", "table-name", table.name)
+						err = nil
+						continue
+					}
 					if err != nil {
 						return
 					}
-					level.Info(util_log.Logger).Log("msg", "finished compacting table", "table-name", tableName)
+					level.Info(util_log.Logger).Log("msg", "finished compacting table", "table-name", table.name, "tsdb", table.tsdb)
 				case <-ctx.Done():
 					return
 				}
@@ -495,7 +736,15 @@ func (c *Compactor) RunCompaction(ctx context.Context, applyRetention bool) erro
 			}
 
 			select {
-			case compactTablesChan <- tableName:
+			case compactTablesChan <- tableToCompact{name: tableName}:
+			case <-ctx.Done():
+				return
+			}
+		}
+
+		for _, tableName := range tsdbTables {
+			select {
+			case compactTablesChan <- tableToCompact{name: tableName, tsdb: true}:
 			case <-ctx.Done():
 				return
 			}
@@ -517,6 +766,56 @@ func (c *Compactor) RunCompaction(ctx context.Context, applyRetention bool) erro
 	return firstErr
 }
 
+// isLeader returns whether this instance is the one running the compactor, or accepting the delete requests when sharding is enabled.
+func (c *Compactor) isLeader() (bool, error) {
+	bufDescs, bufHosts, bufZones := ring.MakeBuffersForGet()
+	rs, err := c.ring.Get(ringKeyOfLeader, ring.Write, bufDescs, bufHosts, bufZones)
+	if err != nil {
+		return false, err
+	}
+
+	addrs := rs.GetAddresses()
+	if len(addrs) != 1 {
+		return false, errors.New("too many addresses (more that one) return when asking the ring who should run the compactor")
+	}
+	return c.ringLifecycler.GetInstanceAddr() == addrs[0], nil
+}
+
+func (c *Compactor) isDeleteRequestsLeader() bool {
+	leader, err := c.isLeader()
+	if err != nil {
+		level.Error(util_log.Logger).Log("msg", "error asking ring for the compactor leader", "err", err)
+		return false
+	}
+	return leader
+}
+
+// ownsTable returns whether this instance must compact the given table when sharding is enabled.
+func (c *Compactor) ownsTable(tableName string) (bool, error) {
+	return util.IsInReplicationSet(c.ring, tokenForTable(tableName), c.ringLifecycler.GetInstanceAddr())
+}
+
+// ringChanged returns whether the healthy instances of the ring changed since the last call.
+func (c *Compactor) ringChanged() bool {
+	// We ignore the error because in case of error it will return an empty
+	// replication set which we use to compare with the previous state.
+	currRingState, _ := c.ring.GetAllHealthy(ring.Write)
+	changed := c.ringLastState.Instances != nil && ring.HasReplicationSetChanged(c.ringLastState, currRingState)
+	c.ringLastState = currRingState
+	return changed
+}
+
+func tokenForTable(tableName string) uint32 {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(tableName))
+	return h.Sum32()
+}
+
+type tableToCompact struct {
+	name string
+	tsdb bool
+}
+
 type expirationChecker struct {
 	retentionExpiryChecker retention.ExpirationChecker
 	deletionExpiryChecker  retention.ExpirationChecker
@@ -567,7 +866,7 @@ func (c *Compactor) OnRingInstanceRegister(_ *ring.BasicLifecycler, ringDesc rin
 	}
 
 	takenTokens := ringDesc.GetTokens()
-	newTokens := ring.GenerateTokens(ringNumTokens-len(tokens), takenTokens)
+	newTokens := ring.GenerateTokens(c.ringNumTokens-len(tokens), takenTokens)
 
 	// Tokens sorting will be enforced by the parent caller.
 	tokens = append(tokens, newTokens...)
@@ -2,13 +2,19 @@ package compactor
 
 import (
 	"context"
+	"fmt"
 	"io/ioutil"
 	"os"
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/go-kit/log"
 	"github.com/grafana/dskit/flagext"
+	"github.com/grafana/dskit/kv"
+	"github.com/grafana/dskit/kv/consul"
+	"github.com/grafana/dskit/ring"
 	"github.com/stretchr/testify/require"
 
 	loki_storage "github.com/grafana/loki/pkg/storage"
@@ -16,6 +22,7 @@ import (
 	"github.com/grafana/loki/pkg/storage/chunk/storage"
 	"github.com/grafana/loki/pkg/storage/stores/shipper/testutil"
 	loki_net "github.com/grafana/loki/pkg/util/net"
+	"github.com/grafana/loki/pkg/util/test"
 )
 
 func setupTestCompactor(t *testing.T, tempDir string, clientMetrics storage.ClientMetrics) *Compactor {
@@ -31,7 +38,7 @@ func setupTestCompactor(t *testing.T, tempDir string, clientMetrics storage.Clie
 
 	require.NoError(t, cfg.Validate())
 
-	c, err := NewCompactor(cfg, storage.Config{FSConfig: local.FSConfig{Directory: tempDir}}, loki_storage.SchemaConfig{}, nil, clientMetrics, nil)
+	c, err := NewCompactor(cfg, storage.Config{FSConfig: local.FSConfig{Directory: tempDir}}, loki_storage.SchemaConfig{}, nil, clientMetrics, nil, nil)
 	require.NoError(t, err)
 
 	return c
@@ -113,7 +120,7 @@ func TestCompactor_RunCompaction(t *testing.T) {
 	cm := storage.NewClientMetrics()
 	defer cm.Unregister()
 	compactor := setupTestCompactor(t, tempDir, cm)
-	err = compactor.RunCompaction(context.Background(), true)
+	err = compactor.RunCompaction(context.Background(), true, false, false)
 	require.NoError(t, err)
 
 	for name := range tables {
@@ -127,3 +134,80 @@ func TestCompactor_RunCompaction(t *testing.T) {
 		compareCompactedTable(t, filepath.Join(tablesPath, name), filepath.Join(tablesCopyPath, name))
 	}
 }
+
+func TestCompactor_RunCompactionSharded(t *testing.T) {
+	tempDir := t.TempDir()
+	tablesPath := filepath.Join(tempDir, "index")
+
+	var tableNames []string
+	for i := 0; i < 10; i++ {
+		tableName := fmt.Sprintf("table%d", i)
+		tableNames = append(tableNames, tableName)
+		testutil.SetupDBsAtPath(t, filepath.Join(tablesPath, tableName), map[string]testutil.DBConfig{
+			"db1": {DBRecords: testutil.DBRecords{Start: 0, NumRecords: 10}},
+			"db2": {DBRecords: testutil.DBRecords{Start: 10, NumRecords: 10}},
+		}, nil)
+	}
+
+	ringStore, closer := consul.NewInMemoryClient(ring.GetCodec(), log.NewNopLogger(), nil)
+	t.Cleanup(func() { require.NoError(t, closer.Close()) })
+
+	cm := storage.NewClientMetrics()
+	defer cm.Unregister()
+
+	compactors := make([]*Compactor, 0, 2)
+	for i := 1; i <= 2; i++ {
+		cfg := Config{}
+		flagext.DefaultValues(&cfg)
+		cfg.WorkingDirectory = filepath.Join(tempDir, fmt.Sprintf("%s-%d", workingDirName, i))
+		cfg.SharedStoreType = "filesystem"
+		cfg.ShardingEnabled = true
+		cfg.CompactorRing.KVStore = kv.Config{Mock: ringStore}
+		cfg.CompactorRing.InstanceID = fmt.Sprintf("compactor-%d", i)
+		cfg.CompactorRing.InstanceAddr = "127.0.0.1"
+		cfg.CompactorRing.InstancePort = i
+		require.NoError(t, cfg.Validate())
+
+		c, err := NewCompactor(cfg, storage.Config{FSConfig: local.FSConfig{Directory: tempDir}}, loki_storage.SchemaConfig{}, nil, cm, nil, nil)
+		require.NoError(t, err)
+		require.NoError(t, c.starting(context.Background()))
+		t.Cleanup(func() { require.NoError(t, c.stopping(nil)) })
+		compactors = append(compactors, c)
+	}
+
+	for _, c := range compactors {
+		c := c
+		test.Poll(t, 5*time.Second, 2, func() interface{} {
+			return c.ring.InstancesCount()
+		})
+	}
+
+	// each table is compacted by the single instance owning it.
+	owners := map[string]int{}
+	for _, tableName := range tableNames {
+		for i, c := range compactors {
+			owned, err := c.ownsTable(tableName)
+			require.NoError(t, err)
+			if owned {
+				_, ok := owners[tableName]
+				require.False(t, ok, "table %s owned by multiple compactors", tableName)
+				owners[tableName] = i
+			}
+		}
+		require.Contains(t, owners, tableName)
+	}
+
+	for i, c := range compactors {
+		require.NoError(t, c.RunCompaction(context.Background(), false, false, false))
+
+		for _, tableName := range tableNames {
+			files, err := ioutil.ReadDir(filepath.Join(tablesPath, tableName))
+			require.NoError(t, err)
+			if owners[tableName] <= i {
+				require.Len(t, files, 1, "table %s", tableName)
+			} else {
+				require.Len(t, files, 2, "table %s", tableName)
+			}
+		}
+	}
+}
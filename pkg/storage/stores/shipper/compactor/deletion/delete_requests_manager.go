@@ -28,9 +28,13 @@ type DeleteRequestsManager struct {
 	// WARN: If by any chance we change deleteRequestsToProcessMtx to sync.RWMutex to be able to check multiple chunks at a time,
 	// please take care of chunkIntervalsToRetain which should be unique per chunk.
 	deleteRequestsToProcessMtx sync.Mutex
-	metrics                    *deleteRequestsManagerMetrics
-	wg                         sync.WaitGroup
-	done                       chan struct{}
+	// tablesProgress and isLeader are only set when the compactor is sharded. Then each instance applies the delete requests
+	// to the tables it owns, and only the leader marks them as processed once they were applied to all their tables.
+	tablesProgress *TablesProgress
+	isLeader       func() bool
+	metrics        *deleteRequestsManagerMetrics
+	wg             sync.WaitGroup
+	done           chan struct{}
 }
 
 func NewDeleteRequestsManager(store DeleteRequestsStore, deleteRequestCancelPeriod time.Duration, registerer prometheus.Registerer) *DeleteRequestsManager {
@@ -46,6 +50,15 @@ func NewDeleteRequestsManager(store DeleteRequestsStore, deleteRequestCancelPeri
 	return dm
 }
 
+// NewShardedDeleteRequestsManager makes a DeleteRequestsManager for a sharded compactor, where the delete requests are
+// marked as processed by the leader only, once all the instances recorded them as applied to the tables they cover.
+func NewShardedDeleteRequestsManager(store DeleteRequestsStore, deleteRequestCancelPeriod time.Duration, tablesProgress *TablesProgress, isLeader func() bool, registerer prometheus.Registerer) *DeleteRequestsManager {
+	dm := NewDeleteRequestsManager(store, deleteRequestCancelPeriod, registerer)
+	dm.tablesProgress = tablesProgress
+	dm.isLeader = isLeader
+	return dm
+}
+
 func (d *DeleteRequestsManager) loop() {
 	ticker := time.NewTicker(5 * time.Minute)
 	defer ticker.Stop()
@@ -182,11 +195,33 @@ func (d *DeleteRequestsManager) MarkPhaseFailed() {
 	d.deleteRequestsToProcess = d.deleteRequestsToProcess[:0]
 }
 
+// DeleteRequestsToProcess returns the delete requests loaded for the current phase.
+func (d *DeleteRequestsManager) DeleteRequestsToProcess() []DeleteRequest {
+	d.deleteRequestsToProcessMtx.Lock()
+	defer d.deleteRequestsToProcessMtx.Unlock()
+
+	return append([]DeleteRequest(nil), d.deleteRequestsToProcess...)
+}
+
 func (d *DeleteRequestsManager) MarkPhaseFinished() {
 	d.deleteRequestsToProcessMtx.Lock()
 	defer d.deleteRequestsToProcessMtx.Unlock()
 
-	for _, deleteRequest := range d.deleteRequestsToProcess {
+	processed := d.deleteRequestsToProcess
+	if d.tablesProgress != nil {
+		if !d.isLeader() {
+			return
+		}
+
+		var err error
+		processed, err = d.tablesProgress.ProcessedRequests(context.Background(), d.deleteRequestsToProcess)
+		if err != nil {
+			level.Error(util_log.Logger).Log("msg", "failed to check which delete requests were applied to all their tables", "err", err)
+			return
+		}
+	}
+
+	for _, deleteRequest := range processed {
 		if err := d.deleteRequestsStore.UpdateStatus(context.Background(), deleteRequest.UserID, deleteRequest.RequestID, StatusProcessed); err != nil {
 			level.Error(util_log.Logger).Log("msg", fmt.Sprintf("failed to mark delete request %s for user %s as processed", deleteRequest.RequestID, deleteRequest.UserID), "err", err)
 		}
@@ -36,7 +36,10 @@ const (
 	DeleteRequestsTableName = "delete_requests"
 )
 
-var ErrDeleteRequestNotFound = errors.New("could not find matching delete request")
+var (
+	ErrDeleteRequestNotFound = errors.New("could not find matching delete request")
+	ErrNotLeader             = errors.New("delete requests can only be updated by the compactor leader")
+)
 
 type DeleteRequestsStore interface {
 	AddDeleteRequest(ctx context.Context, userID string, startTime, endTime model.Time, selectors []string) error
@@ -48,19 +51,42 @@ type DeleteRequestsStore interface {
 	Stop()
 }
 
+// SyncedDeleteRequestsStore is a DeleteRequestsStore shared by the instances of a sharded compactor.
+type SyncedDeleteRequestsStore interface {
+	DeleteRequestsStore
+	// Sync uploads the delete requests when this instance is the leader, or downloads the latest ones uploaded by the leader otherwise.
+	Sync() error
+}
+
 // deleteRequestsStore provides all the methods required to manage lifecycle of delete request and things related to it.
 type deleteRequestsStore struct {
 	indexClient chunk.IndexClient
+	table       *deleteRequestsTable
 }
 
 // NewDeleteStore creates a store for managing delete requests.
 func NewDeleteStore(workingDirectory string, indexStorageClient storage.Client) (DeleteRequestsStore, error) {
-	indexClient, err := newDeleteRequestsTable(workingDirectory, indexStorageClient)
+	table, err := newDeleteRequestsTable(workingDirectory, indexStorageClient, nil)
 	if err != nil {
 		return nil, err
 	}
 
-	return &deleteRequestsStore{indexClient: indexClient}, nil
+	return &deleteRequestsStore{indexClient: table, table: table}, nil
+}
+
+// NewSyncedDeleteStore creates a store for managing delete requests which can only be updated while isLeader returns true.
+// The other instances keep a read-only copy of the delete requests uploaded by the leader.
+func NewSyncedDeleteStore(workingDirectory string, indexStorageClient storage.Client, isLeader func() bool) (SyncedDeleteRequestsStore, error) {
+	table, err := newDeleteRequestsTable(workingDirectory, indexStorageClient, isLeader)
+	if err != nil {
+		return nil, err
+	}
+
+	return &deleteRequestsStore{indexClient: table, table: table}, nil
+}
+
+func (ds *deleteRequestsStore) Sync() error {
+	return ds.table.sync()
 }
 
 func (ds *deleteRequestsStore) Stop() {
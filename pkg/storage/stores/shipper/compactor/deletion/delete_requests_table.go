@@ -25,7 +25,13 @@ type deleteRequestsTable struct {
 	indexStorageClient storage.Client
 	dbPath             string
 
+	// isLeader is only set when the compactor is sharded. Then only the leader writes and uploads the delete requests
+	// while the other instances keep downloading the copy uploaded by the leader.
+	isLeader       func() bool
+	syncedAsLeader bool
+
 	boltdbIndexClient *local.BoltIndexClient
+	dbMtx             sync.RWMutex
 	db                *bbolt.DB
 	done              chan struct{}
 	wg                sync.WaitGroup
@@ -33,7 +39,7 @@ type deleteRequestsTable struct {
 
 const deleteRequestsIndexFileName = DeleteRequestsTableName + ".gz"
 
-func newDeleteRequestsTable(workingDirectory string, indexStorageClient storage.Client) (chunk.IndexClient, error) {
+func newDeleteRequestsTable(workingDirectory string, indexStorageClient storage.Client, isLeader func() bool) (*deleteRequestsTable, error) {
 	dbPath := filepath.Join(workingDirectory, DeleteRequestsTableName, DeleteRequestsTableName)
 	boltdbIndexClient, err := local.NewBoltDBIndexClient(local.BoltDBConfig{Directory: filepath.Dir(dbPath)})
 	if err != nil {
@@ -44,6 +50,7 @@ func newDeleteRequestsTable(workingDirectory string, indexStorageClient storage.
 		indexStorageClient: indexStorageClient,
 		dbPath:             dbPath,
 		boltdbIndexClient:  boltdbIndexClient,
+		isLeader:           isLeader,
 		done:               make(chan struct{}),
 	}
 
@@ -88,8 +95,8 @@ func (t *deleteRequestsTable) loop() {
 	for {
 		select {
 		case <-uploadTicker.C:
-			if err := t.uploadFile(); err != nil {
-				level.Error(util_log.Logger).Log("msg", "failed to upload delete requests file", "err", err)
+			if err := t.sync(); err != nil {
+				level.Error(util_log.Logger).Log("msg", "failed to sync delete requests file", "err", err)
 			}
 		case <-t.done:
 			return
@@ -97,6 +104,72 @@ func (t *deleteRequestsTable) loop() {
 	}
 }
 
+// sync uploads the delete requests when this instance writes them, or downloads the copy uploaded by the leader otherwise.
+func (t *deleteRequestsTable) sync() error {
+	if t.isLeader == nil {
+		return t.uploadFile()
+	}
+
+	if !t.isLeader() {
+		t.setSyncedAsLeader(false)
+		return t.download()
+	}
+
+	if !t.writable() {
+		// the previous leader may have updated the delete requests since we last downloaded them.
+		if err := t.download(); err != nil {
+			return err
+		}
+		t.setSyncedAsLeader(true)
+	}
+	return t.uploadFile()
+}
+
+func (t *deleteRequestsTable) setSyncedAsLeader(synced bool) {
+	t.dbMtx.Lock()
+	defer t.dbMtx.Unlock()
+	t.syncedAsLeader = synced
+}
+
+// writable returns whether this instance can update the delete requests.
+func (t *deleteRequestsTable) writable() bool {
+	if t.isLeader == nil {
+		return true
+	}
+
+	t.dbMtx.RLock()
+	defer t.dbMtx.RUnlock()
+	return t.syncedAsLeader && t.isLeader()
+}
+
+// download replaces the local delete requests with the latest copy uploaded to the storage, if any.
+func (t *deleteRequestsTable) download() error {
+	tempFilePath := fmt.Sprintf("%s%s", t.dbPath, tempFileSuffix)
+	err := shipper_util.DownloadFileFromStorage(tempFilePath, true,
+		true, shipper_util.LoggerWithFilename(util_log.Logger, deleteRequestsIndexFileName), func() (io.ReadCloser, error) {
+			return t.indexStorageClient.GetFile(context.Background(), DeleteRequestsTableName, deleteRequestsIndexFileName)
+		})
+	if err != nil {
+		_ = os.Remove(tempFilePath)
+		if t.indexStorageClient.IsFileNotFoundErr(err) {
+			return nil
+		}
+		return err
+	}
+
+	t.dbMtx.Lock()
+	defer t.dbMtx.Unlock()
+
+	if err := t.db.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tempFilePath, t.dbPath); err != nil {
+		return err
+	}
+	t.db, err = shipper_util.SafeOpenBoltdbFile(t.dbPath)
+	return err
+}
+
 func (t *deleteRequestsTable) uploadFile() error {
 	level.Debug(util_log.Logger).Log("msg", "uploading delete requests db")
 
@@ -116,6 +189,7 @@ func (t *deleteRequestsTable) uploadFile() error {
 		}
 	}()
 
+	t.dbMtx.RLock()
 	err = t.db.View(func(tx *bbolt.Tx) (err error) {
 		compressedWriter := chunkenc.Gzip.GetWriter(f)
 		defer chunkenc.Gzip.PutWriter(compressedWriter)
@@ -130,6 +204,7 @@ func (t *deleteRequestsTable) uploadFile() error {
 		_, err = tx.WriteTo(compressedWriter)
 		return
 	})
+	t.dbMtx.RUnlock()
 	if err != nil {
 		return err
 	}
@@ -150,8 +225,10 @@ func (t *deleteRequestsTable) Stop() {
 	close(t.done)
 	t.wg.Wait()
 
-	if err := t.uploadFile(); err != nil {
-		level.Error(util_log.Logger).Log("msg", "failed to upload delete requests file during shutdown", "err", err)
+	if t.writable() {
+		if err := t.uploadFile(); err != nil {
+			level.Error(util_log.Logger).Log("msg", "failed to upload delete requests file during shutdown", "err", err)
+		}
 	}
 
 	if err := t.db.Close(); err != nil {
@@ -170,6 +247,12 @@ func (t *deleteRequestsTable) BatchWrite(ctx context.Context, batch chunk.WriteB
 	if !ok {
 		return errors.New("invalid write batch")
 	}
+	if !t.writable() {
+		return ErrNotLeader
+	}
+
+	t.dbMtx.RLock()
+	defer t.dbMtx.RUnlock()
 
 	for _, tableWrites := range boltWriteBatch.Writes {
 		if err := t.boltdbIndexClient.WriteToDB(ctx, t.db, local.IndexBucketName, tableWrites); err != nil {
@@ -181,6 +264,9 @@ func (t *deleteRequestsTable) BatchWrite(ctx context.Context, batch chunk.WriteB
 }
 
 func (t *deleteRequestsTable) QueryPages(ctx context.Context, queries []chunk.IndexQuery, callback func(chunk.IndexQuery, chunk.ReadBatch) (shouldContinue bool)) error {
+	t.dbMtx.RLock()
+	defer t.dbMtx.RUnlock()
+
 	for _, query := range queries {
 		if err := t.boltdbIndexClient.QueryDB(ctx, t.db, local.IndexBucketName, query, callback); err != nil {
 			return err
@@ -31,11 +31,11 @@ func TestDeleteRequestsTable(t *testing.T) {
 		Directory: objectStorePath,
 	})
 	require.NoError(t, err)
-	indexClient, err := newDeleteRequestsTable(workingDir, storage.NewIndexStorageClient(objectClient, ""))
+	indexClient, err := newDeleteRequestsTable(workingDir, storage.NewIndexStorageClient(objectClient, ""), nil)
 	require.NoError(t, err)
 
 	// see if delete requests db was created
-	testDeleteRequestsTable := indexClient.(*deleteRequestsTable)
+	testDeleteRequestsTable := indexClient
 	require.NotEmpty(t, testDeleteRequestsTable.dbPath)
 	require.FileExists(t, testDeleteRequestsTable.dbPath)
 
@@ -70,11 +70,11 @@ func TestDeleteRequestsTable(t *testing.T) {
 	require.NoError(t, err)
 
 	// re-create table to see if the db gets downloaded locally since it does not exist anymore
-	indexClient, err = newDeleteRequestsTable(workingDir, storage.NewIndexStorageClient(objectClient, ""))
+	indexClient, err = newDeleteRequestsTable(workingDir, storage.NewIndexStorageClient(objectClient, ""), nil)
 	require.NoError(t, err)
 	defer indexClient.Stop()
 
-	testDeleteRequestsTable = indexClient.(*deleteRequestsTable)
+	testDeleteRequestsTable = indexClient
 	require.NotEmpty(t, testDeleteRequestsTable.dbPath)
 
 	// validate records in local db
@@ -0,0 +1,120 @@
+package deletion
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+
+	"github.com/grafana/loki/pkg/storage/stores/shipper/compactor/retention"
+	"github.com/grafana/loki/pkg/storage/stores/shipper/storage"
+)
+
+const tableProgressFilePrefix = "progress-"
+
+// TablesProgress tracks which delete requests got applied to which tables when the tables are compacted by
+// different instances of a sharded compactor. The progress of each table is stored next to the delete requests
+// so that the leader can tell when a delete request was applied to all the tables it covers.
+type TablesProgress struct {
+	indexStorageClient storage.Client
+}
+
+// NewTablesProgress makes a TablesProgress storing the progress of the tables in the given index storage.
+func NewTablesProgress(indexStorageClient storage.Client) *TablesProgress {
+	return &TablesProgress{indexStorageClient: indexStorageClient}
+}
+
+// RecordTableProcessed records that the given delete requests were applied to the table.
+// It replaces the requests recorded by the previous runs, which are either processed by now or still given again.
+func (p *TablesProgress) RecordTableProcessed(ctx context.Context, tableName string, deleteRequests []DeleteRequest) error {
+	ids := make([]string, 0, len(deleteRequests))
+	for _, deleteRequest := range deleteRequests {
+		ids = append(ids, progressID(deleteRequest))
+	}
+
+	buf, err := json.Marshal(ids)
+	if err != nil {
+		return err
+	}
+
+	return p.indexStorageClient.PutFile(ctx, DeleteRequestsTableName, tableProgressFilePrefix+tableName, bytes.NewReader(buf))
+}
+
+// ProcessedRequests returns the delete requests which were applied to all the index tables they cover.
+func (p *TablesProgress) ProcessedRequests(ctx context.Context, deleteRequests []DeleteRequest) ([]DeleteRequest, error) {
+	if len(deleteRequests) == 0 {
+		return nil, nil
+	}
+
+	tables, err := p.indexStorageClient.ListTables(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	tablesProgress := map[string]map[string]struct{}{}
+	processed := make([]DeleteRequest, 0, len(deleteRequests))
+
+outer:
+	for _, deleteRequest := range deleteRequests {
+		for _, tableName := range tables {
+			if tableName == DeleteRequestsTableName {
+				continue
+			}
+
+			interval := retention.ExtractIntervalFromTableName(tableName)
+			if interval.Start > deleteRequest.EndTime || interval.End < deleteRequest.StartTime {
+				continue
+			}
+
+			tableProgress, ok := tablesProgress[tableName]
+			if !ok {
+				tableProgress, err = p.tableProgress(ctx, tableName)
+				if err != nil {
+					return nil, err
+				}
+				tablesProgress[tableName] = tableProgress
+			}
+
+			if _, ok := tableProgress[progressID(deleteRequest)]; !ok {
+				continue outer
+			}
+		}
+
+		processed = append(processed, deleteRequest)
+	}
+
+	return processed, nil
+}
+
+// tableProgress returns the delete requests recorded as applied to the table.
+func (p *TablesProgress) tableProgress(ctx context.Context, tableName string) (map[string]struct{}, error) {
+	rc, err := p.indexStorageClient.GetFile(ctx, DeleteRequestsTableName, tableProgressFilePrefix+tableName)
+	if err != nil {
+		if p.indexStorageClient.IsFileNotFoundErr(err) {
+			return map[string]struct{}{}, nil
+		}
+		return nil, err
+	}
+	defer rc.Close()
+
+	buf, err := ioutil.ReadAll(rc)
+	if err != nil {
+		return nil, err
+	}
+
+	var ids []string
+	if err := json.Unmarshal(buf, &ids); err != nil {
+		return nil, fmt.Errorf("failed to decode delete requests progress of table %s: %w", tableName, err)
+	}
+
+	progress := make(map[string]struct{}, len(ids))
+	for _, id := range ids {
+		progress[id] = struct{}{}
+	}
+	return progress, nil
+}
+
+func progressID(deleteRequest DeleteRequest) string {
+	return deleteRequest.UserID + ":" + deleteRequest.RequestID
+}
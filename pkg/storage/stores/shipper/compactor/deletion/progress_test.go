@@ -0,0 +1,55 @@
+package deletion
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/loki/pkg/storage/chunk/local"
+	"github.com/grafana/loki/pkg/storage/stores/shipper/storage"
+)
+
+func TestTablesProgress(t *testing.T) {
+	objectClient, err := local.NewFSObjectClient(local.FSConfig{Directory: t.TempDir()})
+	require.NoError(t, err)
+	indexStorageClient := storage.NewIndexStorageClient(objectClient, "")
+
+	for _, tableName := range []string{"index_19000", "index_19001", "index_19002"} {
+		require.NoError(t, indexStorageClient.PutFile(context.Background(), tableName, "db", bytes.NewReader([]byte("index"))))
+	}
+
+	day := 24 * time.Hour
+	tableStart := model.TimeFromUnix(19000 * 86400)
+	// covers the first two tables.
+	twoTables := DeleteRequest{UserID: "1", RequestID: "a", StartTime: tableStart.Add(time.Hour), EndTime: tableStart.Add(day + time.Hour)}
+	// covers the first table only.
+	oneTable := DeleteRequest{UserID: "1", RequestID: "b", StartTime: tableStart, EndTime: tableStart.Add(time.Hour)}
+	// covers no table.
+	noTable := DeleteRequest{UserID: "2", RequestID: "a", StartTime: tableStart.Add(-2 * day), EndTime: tableStart.Add(-day)}
+	requests := []DeleteRequest{twoTables, oneTable, noTable}
+
+	progress := NewTablesProgress(indexStorageClient)
+	processed, err := progress.ProcessedRequests(context.Background(), requests)
+	require.NoError(t, err)
+	require.Equal(t, []DeleteRequest{noTable}, processed)
+
+	require.NoError(t, progress.RecordTableProcessed(context.Background(), "index_19000", requests))
+	processed, err = progress.ProcessedRequests(context.Background(), requests)
+	require.NoError(t, err)
+	require.Equal(t, []DeleteRequest{oneTable, noTable}, processed)
+
+	require.NoError(t, progress.RecordTableProcessed(context.Background(), "index_19001", []DeleteRequest{twoTables}))
+	processed, err = progress.ProcessedRequests(context.Background(), requests)
+	require.NoError(t, err)
+	require.Equal(t, requests, processed)
+
+	// the progress of a table is replaced by the next run.
+	require.NoError(t, progress.RecordTableProcessed(context.Background(), "index_19000", nil))
+	processed, err = progress.ProcessedRequests(context.Background(), requests)
+	require.NoError(t, err)
+	require.Equal(t, []DeleteRequest{noTable}, processed)
+}
@@ -2,6 +2,7 @@ package deletion
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 	"time"
 
@@ -91,6 +92,10 @@ func (dm *DeleteRequestHandler) AddDeleteRequestHandler(w http.ResponseWriter, r
 	}
 
 	if err := dm.deleteRequestsStore.AddDeleteRequest(ctx, userID, model.Time(startTime), model.Time(endTime), match); err != nil {
+		if errors.Is(err, ErrNotLeader) {
+			serverutil.JSONError(w, http.StatusServiceUnavailable, err.Error())
+			return
+		}
 		level.Error(util_log.Logger).Log("msg", "error adding delete request to the store", "err", err)
 		serverutil.JSONError(w, http.StatusInternalServerError, err.Error())
 		return
@@ -157,6 +162,10 @@ func (dm *DeleteRequestHandler) CancelDeleteRequestHandler(w http.ResponseWriter
 	}
 
 	if err := dm.deleteRequestsStore.RemoveDeleteRequest(ctx, userID, requestID, deleteRequest.CreatedAt, deleteRequest.StartTime, deleteRequest.EndTime); err != nil {
+		if errors.Is(err, ErrNotLeader) {
+			serverutil.JSONError(w, http.StatusServiceUnavailable, err.Error())
+			return
+		}
 		level.Error(util_log.Logger).Log("msg", "error cancelling the delete request", "err", err)
 		serverutil.JSONError(w, http.StatusInternalServerError, err.Error())
 		return
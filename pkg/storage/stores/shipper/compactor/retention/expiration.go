@@ -30,6 +30,7 @@ type expirationChecker struct {
 type Limits interface {
 	RetentionPeriod(userID string) time.Duration
 	StreamRetention(userID string) []validation.StreamRetention
+	ColdTierAfter(userID string) time.Duration
 	AllByUserID() map[string]*validation.Limits
 	DefaultLimits() *validation.Limits
 }
@@ -14,12 +14,14 @@ import (
 type retentionLimit struct {
 	retentionPeriod time.Duration
 	streamRetention []validation.StreamRetention
+	coldTierAfter   time.Duration
 }
 
 func (r retentionLimit) convertToValidationLimit() *validation.Limits {
 	return &validation.Limits{
 		RetentionPeriod: model.Duration(r.retentionPeriod),
 		StreamRetention: r.streamRetention,
+		ColdTierAfter:   model.Duration(r.coldTierAfter),
 	}
 }
 
@@ -36,6 +38,10 @@ func (f fakeLimits) StreamRetention(userID string) []validation.StreamRetention
 	return f.perTenant[userID].streamRetention
 }
 
+func (f fakeLimits) ColdTierAfter(userID string) time.Duration {
+	return f.perTenant[userID].coldTierAfter
+}
+
 func (f fakeLimits) DefaultLimits() *validation.Limits {
 	return f.defaultLimit.convertToValidationLimit()
 }
@@ -0,0 +1,411 @@
+package retention
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/go-kit/log/level"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/model"
+	"go.etcd.io/bbolt"
+
+	"github.com/grafana/loki/pkg/chunkenc"
+	"github.com/grafana/loki/pkg/logproto"
+	"github.com/grafana/loki/pkg/logql/log"
+	"github.com/grafana/loki/pkg/storage"
+	"github.com/grafana/loki/pkg/storage/chunk"
+	"github.com/grafana/loki/pkg/storage/chunk/local"
+	loki_util "github.com/grafana/loki/pkg/util"
+	util_log "github.com/grafana/loki/pkg/util/log"
+)
+
+const mergedChunkBlockSize = 256 * 1024
+
+// ChunkMerger merges the adjacent small chunks of each stream of a table into chunks of the target size.
+// The merged chunks are uploaded first, then the index is updated within a single transaction and the source chunks
+// are marked for deletion, so that they get removed by the Sweeper like the chunks deleted by retention.
+type ChunkMerger struct {
+	workingDirectory string
+	config           storage.SchemaConfig
+	chunkClient      chunk.Client
+	smallChunkSize   int
+	targetChunkSize  int
+	metrics          *mergerMetrics
+}
+
+func NewChunkMerger(workingDirectory string, config storage.SchemaConfig, chunkClient chunk.Client, smallChunkSize, targetChunkSize int, r prometheus.Registerer) (*ChunkMerger, error) {
+	if err := validatePeriods(config); err != nil {
+		return nil, err
+	}
+	return &ChunkMerger{
+		workingDirectory: workingDirectory,
+		config:           config,
+		chunkClient:      chunkClient,
+		smallChunkSize:   smallChunkSize,
+		targetChunkSize:  targetChunkSize,
+		metrics:          newMergerMetrics(r),
+	}, nil
+}
+
+// MarkForDelete merges the small chunks of a given table and marks the merged ones for deletion.
+// A table never becomes empty by merging its chunks.
+func (m *ChunkMerger) MarkForDelete(ctx context.Context, tableName string, db *bbolt.DB) (bool, bool, error) {
+	start := time.Now()
+	status := statusSuccess
+	defer func() {
+		m.metrics.tableProcessedDurationSeconds.WithLabelValues(status).Observe(time.Since(start).Seconds())
+		level.Debug(util_log.Logger).Log("msg", "finished to merge chunks of table", "table", tableName, "duration", time.Since(start))
+	}()
+	level.Debug(util_log.Logger).Log("msg", "starting to merge chunks of table", "table", tableName)
+
+	modified, err := m.mergeTable(ctx, tableName, db)
+	if err != nil {
+		status = statusFailure
+		return false, false, err
+	}
+	return false, modified, nil
+}
+
+func (m *ChunkMerger) mergeTable(ctx context.Context, tableName string, db *bbolt.DB) (bool, error) {
+	schemaCfg, ok := schemaPeriodForTable(m.config, tableName)
+	if !ok {
+		return false, fmt.Errorf("could not find schema for table: %s", tableName)
+	}
+
+	var streams []*mergeStream
+	err := db.View(func(tx *bbolt.Tx) error {
+		bucket := tx.Bucket(local.IndexBucketName)
+		if bucket == nil {
+			return nil
+		}
+
+		chunkIt, err := newChunkIndexIterator(bucket, schemaCfg)
+		if err != nil {
+			return fmt.Errorf("failed to create chunk index iterator: %w", err)
+		}
+		streams, err = collectMergeCandidates(chunkIt, ExtractIntervalFromTableName(tableName))
+		return err
+	})
+	if err != nil || len(streams) == 0 {
+		return false, err
+	}
+
+	// the merged chunks are built and uploaded before opening the write transaction, which only updates the index,
+	// so that the table isn't locked for the whole time spent fetching and uploading chunks.
+	var merges []chunkMerge
+	for _, stream := range streams {
+		if ctx.Err() != nil {
+			return false, ctx.Err()
+		}
+		streamMerges, err := m.mergeStream(ctx, stream)
+		if err != nil {
+			return false, err
+		}
+		merges = append(merges, streamMerges...)
+	}
+	if len(merges) == 0 {
+		return false, nil
+	}
+
+	markerWriter, err := NewMarkerStorageWriter(m.workingDirectory)
+	if err != nil {
+		return false, fmt.Errorf("failed to create marker writer: %w", err)
+	}
+
+	err = db.Update(func(tx *bbolt.Tx) error {
+		bucket := tx.Bucket(local.IndexBucketName)
+		if bucket == nil {
+			return errors.New("index bucket removed while merging chunks")
+		}
+
+		chunkRewriter, err := newChunkRewriter(m.chunkClient, schemaCfg, tableName, bucket)
+		if err != nil {
+			return err
+		}
+		for _, merge := range merges {
+			if err := merge.updateIndex(chunkRewriter); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+
+	// the chunks removed from the index are only marked for deletion once the transaction is committed.
+	// If it failed, the merged chunks are not indexed and get deleted instead.
+	keyCfg := chunk.SchemaConfig{Configs: []chunk.PeriodConfig{schemaCfg}}
+	for _, merge := range merges {
+		chunks := merge.run
+		if err != nil {
+			chunks = merge.newChunks
+		}
+		for _, c := range chunks {
+			if putErr := markerWriter.Put([]byte(keyCfg.ExternalKey(c))); putErr != nil && err == nil {
+				err = putErr
+			}
+		}
+	}
+	if closeErr := markerWriter.Close(); closeErr != nil && err == nil {
+		err = fmt.Errorf("failed to close marker writer: %w", closeErr)
+	}
+	if err != nil {
+		return false, err
+	}
+
+	for _, merge := range merges {
+		m.metrics.chunksMergedTotal.Add(float64(len(merge.run)))
+		m.metrics.chunksCreatedTotal.Add(float64(len(merge.newChunks)))
+	}
+	return true, nil
+}
+
+// chunkMerge is a run of adjacent small chunks of a stream along with the uploaded chunks replacing it.
+type chunkMerge struct {
+	run       []chunk.Chunk
+	newChunks []chunk.Chunk
+}
+
+// updateIndex indexes the new chunks and removes the chunks of the run from the index.
+func (cm chunkMerge) updateIndex(rewriter *chunkRewriter) error {
+	for _, c := range cm.newChunks {
+		if _, err := rewriter.putChunkIndexEntries(c, rewriter.scfg.ExternalKey(c)); err != nil {
+			return err
+		}
+	}
+	for _, c := range cm.run {
+		if err := rewriter.deleteChunkIndexEntries(c, rewriter.scfg.ExternalKey(c)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// mergeCandidate is a chunk of a stream fully covered by the table being processed.
+type mergeCandidate struct {
+	chunkID       string
+	from, through model.Time
+}
+
+type mergeStream struct {
+	userID string
+	chunks []mergeCandidate
+}
+
+// collectMergeCandidates returns the streams having at least two chunks fully covered by the table interval.
+// Chunks also indexed in other tables are left untouched since their entries would have to be updated in all of them.
+func collectMergeCandidates(chunkIt ChunkEntryIterator, tableInterval model.Interval) ([]*mergeStream, error) {
+	streams := map[string]*mergeStream{}
+
+	for chunkIt.Next() {
+		c := chunkIt.Entry()
+		if c.From < tableInterval.Start || c.Through > tableInterval.End {
+			continue
+		}
+		key := string(c.UserID) + separator + string(c.SeriesID)
+		s, ok := streams[key]
+		if !ok {
+			s = &mergeStream{userID: string(c.UserID)}
+			streams[key] = s
+		}
+		s.chunks = append(s.chunks, mergeCandidate{
+			chunkID: string(c.ChunkID),
+			from:    c.From,
+			through: c.Through,
+		})
+	}
+	if chunkIt.Err() != nil {
+		return nil, chunkIt.Err()
+	}
+
+	keys := make([]string, 0, len(streams))
+	for key, s := range streams {
+		if len(s.chunks) > 1 {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	res := make([]*mergeStream, 0, len(keys))
+	for _, key := range keys {
+		s := streams[key]
+		sort.Slice(s.chunks, func(i, j int) bool {
+			return s.chunks[i].from < s.chunks[j].from
+		})
+		res = append(res, s)
+	}
+	return res, nil
+}
+
+// mergeStream fetches the chunks of the stream and merges each run of adjacent small chunks.
+// Overlapping chunks are never merged together to keep the entries of the new chunks ordered.
+func (m *ChunkMerger) mergeStream(ctx context.Context, stream *mergeStream) ([]chunkMerge, error) {
+	chks, err := m.fetchChunks(ctx, stream)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		merges []chunkMerge
+		run    []chunk.Chunk
+	)
+	flushRun := func() error {
+		if len(run) > 1 {
+			merge, merged, err := m.mergeRun(ctx, run)
+			if err != nil {
+				return err
+			}
+			if merged {
+				merges = append(merges, merge)
+			}
+		}
+		run = nil
+		return nil
+	}
+
+	for _, c := range chks {
+		if c.Data.Size() >= m.smallChunkSize {
+			if err := flushRun(); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if len(run) > 0 && c.From <= run[len(run)-1].Through {
+			if err := flushRun(); err != nil {
+				return nil, err
+			}
+		}
+		run = append(run, c)
+	}
+	if err := flushRun(); err != nil {
+		return nil, err
+	}
+	return merges, nil
+}
+
+func (m *ChunkMerger) fetchChunks(ctx context.Context, stream *mergeStream) ([]chunk.Chunk, error) {
+	chks := make([]chunk.Chunk, 0, len(stream.chunks))
+	for _, c := range stream.chunks {
+		chk, err := chunk.ParseExternalKey(stream.userID, c.chunkID)
+		if err != nil {
+			return nil, err
+		}
+		chks = append(chks, chk)
+	}
+
+	fetched, err := m.chunkClient.GetChunks(ctx, chks)
+	if err != nil {
+		return nil, err
+	}
+	if len(fetched) != len(chks) {
+		return nil, fmt.Errorf("expected %d chunks but found %d in storage", len(chks), len(fetched))
+	}
+
+	sort.Slice(fetched, func(i, j int) bool {
+		return fetched[i].From < fetched[j].From
+	})
+	return fetched, nil
+}
+
+// mergeRun re-encodes the chunks of a run into as few chunks as possible and uploads them.
+// The run is left untouched if merging it would not reduce the number of chunks.
+func (m *ChunkMerger) mergeRun(ctx context.Context, run []chunk.Chunk) (chunkMerge, bool, error) {
+	newChunks, err := m.buildChunks(ctx, run)
+	if err != nil {
+		return chunkMerge{}, false, err
+	}
+	if len(newChunks) >= len(run) {
+		return chunkMerge{}, false, nil
+	}
+
+	for i := range newChunks {
+		if err := newChunks[i].Encode(); err != nil {
+			return chunkMerge{}, false, err
+		}
+	}
+	if err := m.chunkClient.PutChunks(ctx, newChunks); err != nil {
+		return chunkMerge{}, false, err
+	}
+	return chunkMerge{run: run, newChunks: newChunks}, true, nil
+}
+
+// buildChunks appends the entries of the run into new chunks, cutting a new one every time the target size is reached.
+func (m *ChunkMerger) buildChunks(ctx context.Context, run []chunk.Chunk) ([]chunk.Chunk, error) {
+	var (
+		newChunks     []chunk.Chunk
+		current       *chunkenc.MemChunk
+		from, through time.Time
+	)
+
+	cut := func() error {
+		if current == nil || current.Size() == 0 {
+			return nil
+		}
+		if err := current.Close(); err != nil {
+			return err
+		}
+		first := run[0]
+		firstTime, lastTime := loki_util.RoundToMilliseconds(from, through)
+		newChunks = append(newChunks, chunk.NewChunk(
+			first.UserID, first.Fingerprint, first.Metric,
+			chunkenc.NewFacade(current, mergedChunkBlockSize, m.targetChunkSize),
+			firstTime,
+			lastTime,
+		))
+		current = nil
+		return nil
+	}
+
+	// The merged chunks keep the structured metadata of the entries if any of the chunks has some.
+	headFmt := chunkenc.UnorderedHeadBlockFmt
+	for _, c := range run {
+		if facade, ok := c.Data.(*chunkenc.Facade); ok {
+			if mc, ok := facade.LokiChunk().(*chunkenc.MemChunk); ok && mc.HeadFormat() == chunkenc.UnorderedWithStructuredMetadataHeadBlockFmt {
+				headFmt = chunkenc.UnorderedWithStructuredMetadataHeadBlockFmt
+			}
+		}
+	}
+
+	for _, c := range run {
+		facade, ok := c.Data.(*chunkenc.Facade)
+		if !ok || facade.LokiChunk() == nil {
+			return nil, errors.New("invalid chunk type")
+		}
+		lokiChunk := facade.LokiChunk()
+
+		it, err := lokiChunk.Iterator(ctx, time.Unix(0, 0), time.Unix(0, math.MaxInt64), logproto.FORWARD, log.NewNoopPipeline().ForStream(nil))
+		if err != nil {
+			return nil, err
+		}
+		for it.Next() {
+			entry := it.Entry()
+			if current != nil && !current.SpaceFor(&entry) {
+				if err := cut(); err != nil {
+					it.Close()
+					return nil, err
+				}
+			}
+			if current == nil {
+				current = chunkenc.NewMemChunk(lokiChunk.Encoding(), headFmt, mergedChunkBlockSize, m.targetChunkSize)
+				from = entry.Timestamp
+			}
+			if err := current.Append(&entry); err != nil {
+				it.Close()
+				return nil, err
+			}
+			through = entry.Timestamp
+		}
+		err = it.Error()
+		it.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err := cut(); err != nil {
+		return nil, err
+	}
+	return newChunks, nil
+}
@@ -0,0 +1,209 @@
+package retention
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/stretchr/testify/require"
+	"go.etcd.io/bbolt"
+
+	"github.com/grafana/loki/pkg/chunkenc"
+	"github.com/grafana/loki/pkg/logproto"
+	"github.com/grafana/loki/pkg/logql/log"
+	"github.com/grafana/loki/pkg/storage/bloom"
+	"github.com/grafana/loki/pkg/storage/chunk"
+	"github.com/grafana/loki/pkg/storage/chunk/local"
+	"github.com/grafana/loki/pkg/storage/chunk/objectclient"
+	"github.com/grafana/loki/pkg/storage/chunk/storage"
+)
+
+func TestChunkMerger(t *testing.T) {
+	for _, tt := range allSchemas {
+		tt := tt
+		t.Run(tt.schema, func(t *testing.T) {
+			cm := storage.NewClientMetrics()
+			defer cm.Unregister()
+			store := newTestStore(t, cm)
+
+			from := tt.from.Add(2 * time.Hour)
+			smallLbs := labels.Labels{labels.Label{Name: "foo", Value: "bar"}}
+			overlappingLbs := labels.Labels{labels.Label{Name: "foo", Value: "baz"}}
+			small := []chunk.Chunk{
+				createChunk(t, "1", smallLbs, from, from.Add(10*time.Minute)),
+				createChunk(t, "1", smallLbs, from.Add(15*time.Minute), from.Add(25*time.Minute)),
+				createChunk(t, "1", smallLbs, from.Add(30*time.Minute), from.Add(40*time.Minute)),
+			}
+			overlapping := []chunk.Chunk{
+				createChunk(t, "1", overlappingLbs, from, from.Add(20*time.Minute)),
+				createChunk(t, "1", overlappingLbs, from.Add(10*time.Minute), from.Add(30*time.Minute)),
+			}
+			// chunks indexed in multiple tables are never merged.
+			spanning := []chunk.Chunk{
+				createChunk(t, "2", smallLbs, from.Add(-4*time.Hour), from),
+				createChunk(t, "2", smallLbs, from.Add(time.Minute), from.Add(10*time.Minute)),
+			}
+			require.NoError(t, store.Put(context.TODO(), append(append(small, overlapping...), spanning...)))
+			store.Stop()
+
+			workDir := t.TempDir()
+			chunkClient := objectclient.NewClient(newTestObjectClient(store.chunkDir, cm), objectclient.Base64Encoder, schemaCfg.SchemaConfig)
+			merger, err := NewChunkMerger(workDir, schemaCfg, chunkClient, 256*1024, 1500*1024, prometheus.NewRegistry())
+			require.NoError(t, err)
+
+			for _, indexTable := range store.indexTables() {
+				empty, _, err := merger.MarkForDelete(context.Background(), indexTable.name, indexTable.DB)
+				require.NoError(t, err)
+				require.False(t, empty)
+				require.NoError(t, indexTable.DB.Close())
+			}
+
+			store.open()
+			defer store.Stop()
+
+			chunks := store.GetChunks("1", from, from.Add(40*time.Minute), small[0].Metric)
+			require.Len(t, chunks, 1)
+			require.Equal(t, from, chunks[0].From)
+			require.Equal(t, from.Add(40*time.Minute), chunks[0].Through)
+			require.Equal(t, 33, countEntries(t, chunks[0]))
+
+			require.Len(t, chunkKeys(store, store.GetChunks("1", from, from.Add(30*time.Minute), overlapping[0].Metric)), 2)
+			require.Len(t, chunkKeys(store, store.GetChunks("2", from.Add(-4*time.Hour), from.Add(10*time.Minute), spanning[0].Metric)), 2)
+
+			// only the merged chunks are marked for deletion.
+			expectedMarks := []string{}
+			for _, c := range small {
+				expectedMarks = append(expectedMarks, store.schemaCfg.ExternalKey(c))
+			}
+			sort.Strings(expectedMarks)
+			require.Equal(t, expectedMarks, readMarks(t, workDir))
+		})
+	}
+}
+
+// txCheckingChunkClient fails the chunk uploads done while the write transaction of the table is open.
+type txCheckingChunkClient struct {
+	chunk.Client
+	db *bbolt.DB
+}
+
+func (c *txCheckingChunkClient) PutChunks(ctx context.Context, chunks []chunk.Chunk) error {
+	done := make(chan error, 1)
+	go func() {
+		done <- c.db.Update(func(*bbolt.Tx) error { return nil })
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			return err
+		}
+	case <-time.After(5 * time.Second):
+		return errors.New("chunks uploaded within the write transaction of the table")
+	}
+	return c.Client.PutChunks(ctx, chunks)
+}
+
+func TestChunkMerger_UploadsOutsideTransaction(t *testing.T) {
+	tt := allSchemas[len(allSchemas)-1]
+	cm := storage.NewClientMetrics()
+	defer cm.Unregister()
+	store := newTestStore(t, cm)
+
+	from := tt.from.Add(2 * time.Hour)
+	lbs := labels.Labels{labels.Label{Name: "foo", Value: "bar"}}
+	small := []chunk.Chunk{
+		createChunk(t, "1", lbs, from, from.Add(10*time.Minute)),
+		createChunk(t, "1", lbs, from.Add(15*time.Minute), from.Add(25*time.Minute)),
+	}
+	require.NoError(t, store.Put(context.TODO(), small))
+	store.Stop()
+
+	blooms, err := local.NewFSObjectClient(local.FSConfig{Directory: t.TempDir()})
+	require.NoError(t, err)
+	bloomStore := bloom.NewStore(bloom.Config{
+		Enabled:              true,
+		SharedStoreKeyPrefix: "blooms/",
+		NGramLength:          4,
+		FalsePositiveRate:    0.01,
+		MaxConcurrency:       2,
+	}, blooms, prometheus.NewRegistry())
+	txChecking := &txCheckingChunkClient{Client: objectclient.NewClient(newTestObjectClient(store.chunkDir, cm), objectclient.Base64Encoder, schemaCfg.SchemaConfig)}
+	chunkClient := bloom.NewChunkClient(txChecking, bloomStore, schemaCfg.SchemaConfig)
+
+	workDir := t.TempDir()
+	merger, err := NewChunkMerger(workDir, schemaCfg, chunkClient, 256*1024, 1500*1024, prometheus.NewRegistry())
+	require.NoError(t, err)
+
+	for _, indexTable := range store.indexTables() {
+		txChecking.db = indexTable.DB
+		_, _, err := merger.MarkForDelete(context.Background(), indexTable.name, indexTable.DB)
+		require.NoError(t, err)
+		require.NoError(t, indexTable.DB.Close())
+	}
+
+	store.open()
+	defer store.Stop()
+
+	chunks := store.GetChunks("1", from, from.Add(25*time.Minute), small[0].Metric)
+	require.Len(t, chunks, 1)
+	merged := store.schemaCfg.ExternalKey(chunks[0])
+
+	// the merged chunk gets a bloom filter and the filters of the chunks it replaces go away with them.
+	marks := readMarks(t, workDir)
+	require.Len(t, marks, 2)
+	for _, mark := range marks {
+		require.NoError(t, chunkClient.DeleteChunk(context.Background(), "1", mark))
+	}
+	filters, err := bloomStore.GetChunks(context.Background(), append([]string{merged}, marks...))
+	require.NoError(t, err)
+	require.NotNil(t, filters[0])
+	require.Nil(t, filters[1])
+	require.Nil(t, filters[2])
+}
+
+// chunkKeys returns the distinct keys of the chunks, which get returned once per table indexing them.
+func chunkKeys(store *testStore, chunks []chunk.Chunk) map[string]struct{} {
+	keys := map[string]struct{}{}
+	for _, c := range chunks {
+		keys[store.schemaCfg.ExternalKey(c)] = struct{}{}
+	}
+	return keys
+}
+
+func countEntries(t *testing.T, c chunk.Chunk) int {
+	t.Helper()
+	it, err := c.Data.(*chunkenc.Facade).LokiChunk().Iterator(context.Background(), time.Unix(0, 0), time.Unix(0, math.MaxInt64), logproto.FORWARD, log.NewNoopPipeline().ForStream(nil))
+	require.NoError(t, err)
+	defer it.Close()
+
+	count := 0
+	for it.Next() {
+		count++
+	}
+	require.NoError(t, it.Error())
+	return count
+}
+
+func readMarks(t *testing.T, workDir string) []string {
+	t.Helper()
+	p, err := newMarkerStorageReader(workDir, 1, 0, sweepMetrics)
+	require.NoError(t, err)
+
+	paths, _, err := p.availablePath()
+	require.NoError(t, err)
+
+	marks := []string{}
+	for _, path := range paths {
+		require.NoError(t, p.processPath(path, func(_ context.Context, chunkID []byte) error {
+			marks = append(marks, string(chunkID))
+			return nil
+		}))
+	}
+	sort.Strings(marks)
+	return marks
+}
@@ -10,6 +10,8 @@ const (
 	statusSuccess  = "success"
 	statusNotFound = "notfound"
 
+	statusChecksumMismatch = "checksum_mismatch"
+
 	tableActionModified = "modified"
 	tableActionDeleted  = "deleted"
 	tableActionNone     = "none"
@@ -22,27 +24,32 @@ type sweeperMetrics struct {
 	markerFilesDeletedTotal    prometheus.Counter
 }
 
-func newSweeperMetrics(r prometheus.Registerer) *sweeperMetrics {
+// newSweeperMetrics creates the metrics of the named sweeper, e.g. retention_sweeper_marker_files_current.
+func newSweeperMetrics(name string, r prometheus.Registerer) *sweeperMetrics {
 	return &sweeperMetrics{
 		deleteChunkDurationSeconds: promauto.With(r).NewHistogramVec(prometheus.HistogramOpts{
 			Namespace: "loki_boltdb_shipper",
-			Name:      "retention_sweeper_chunk_deleted_duration_seconds",
+			Subsystem: name + "_sweeper",
+			Name:      "chunk_deleted_duration_seconds",
 			Help:      "Time (in seconds) spent in deleting chunk",
 			Buckets:   prometheus.ExponentialBuckets(0.1, 2, 8),
 		}, []string{"status"}),
 		markerFilesCurrent: promauto.With(r).NewGauge(prometheus.GaugeOpts{
 			Namespace: "loki_boltdb_shipper",
-			Name:      "retention_sweeper_marker_files_current",
+			Subsystem: name + "_sweeper",
+			Name:      "marker_files_current",
 			Help:      "The current total of marker files valid for deletion.",
 		}),
 		markerFileCurrentTime: promauto.With(r).NewGauge(prometheus.GaugeOpts{
 			Namespace: "loki_boltdb_shipper",
-			Name:      "retention_sweeper_marker_file_processing_current_time",
+			Subsystem: name + "_sweeper",
+			Name:      "marker_file_processing_current_time",
 			Help:      "The current time of creation of the marker file being processed.",
 		}),
 		markerFilesDeletedTotal: promauto.With(r).NewCounter(prometheus.CounterOpts{
 			Namespace: "loki_boltdb_shipper",
-			Name:      "retention_sweeper_marker_files_deleted_total",
+			Subsystem: name + "_sweeper",
+			Name:      "marker_files_deleted_total",
 			Help:      "The total of marker files deleted after being fully processed.",
 		}),
 	}
@@ -74,3 +81,51 @@ func newMarkerMetrics(r prometheus.Registerer) *markerMetrics {
 		}, []string{"table", "status"}),
 	}
 }
+
+type mergerMetrics struct {
+	chunksMergedTotal             prometheus.Counter
+	chunksCreatedTotal            prometheus.Counter
+	tableProcessedDurationSeconds *prometheus.HistogramVec
+}
+
+func newMergerMetrics(r prometheus.Registerer) *mergerMetrics {
+	return &mergerMetrics{
+		chunksMergedTotal: promauto.With(r).NewCounter(prometheus.CounterOpts{
+			Namespace: "loki_boltdb_shipper",
+			Name:      "chunk_merger_source_chunks_total",
+			Help:      "Total count of small chunks merged and marked for deletion.",
+		}),
+		chunksCreatedTotal: promauto.With(r).NewCounter(prometheus.CounterOpts{
+			Namespace: "loki_boltdb_shipper",
+			Name:      "chunk_merger_created_chunks_total",
+			Help:      "Total count of chunks created by merging small chunks.",
+		}),
+		tableProcessedDurationSeconds: promauto.With(r).NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: "loki_boltdb_shipper",
+			Name:      "chunk_merger_table_processed_duration_seconds",
+			Help:      "Time (in seconds) spent in merging the small chunks of a table",
+			Buckets:   []float64{1, 2.5, 5, 10, 20, 40, 90, 360, 600, 1800},
+		}, []string{"status"}),
+	}
+}
+
+type tiererMetrics struct {
+	chunksMovedTotal              *prometheus.CounterVec
+	tableProcessedDurationSeconds *prometheus.HistogramVec
+}
+
+func newTiererMetrics(r prometheus.Registerer) *tiererMetrics {
+	return &tiererMetrics{
+		chunksMovedTotal: promauto.With(r).NewCounterVec(prometheus.CounterOpts{
+			Namespace: "loki_boltdb_shipper",
+			Name:      "chunk_tierer_chunks_moved_total",
+			Help:      "Total count of chunks copied to the cold tier, by status. Chunks failing the checksum verification are left in the primary store.",
+		}, []string{"status"}),
+		tableProcessedDurationSeconds: promauto.With(r).NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: "loki_boltdb_shipper",
+			Name:      "chunk_tierer_table_processed_duration_seconds",
+			Help:      "Time (in seconds) spent in moving the old chunks of a table to the cold tier",
+			Buckets:   []float64{1, 2.5, 5, 10, 20, 40, 90, 360, 600, 1800},
+		}, []string{"status"}),
+	}
+}
@@ -218,7 +218,17 @@ type Sweeper struct {
 }
 
 func NewSweeper(workingDir string, deleteClient ChunkClient, deleteWorkerCount int, minAgeDelete time.Duration, r prometheus.Registerer) (*Sweeper, error) {
-	m := newSweeperMetrics(r)
+	return newSweeper("retention", workingDir, deleteClient, deleteWorkerCount, minAgeDelete, r)
+}
+
+// NewColdTierSweeper makes a Sweeper deleting the primary copies of the chunks moved to the cold tier by the ChunkTierer.
+// The deleteClient must only delete from the primary store.
+func NewColdTierSweeper(workingDir string, deleteClient ChunkClient, deleteWorkerCount int, minAgeDelete time.Duration, r prometheus.Registerer) (*Sweeper, error) {
+	return newSweeper("cold_tier", workingDir, deleteClient, deleteWorkerCount, minAgeDelete, r)
+}
+
+func newSweeper(name, workingDir string, deleteClient ChunkClient, deleteWorkerCount int, minAgeDelete time.Duration, r prometheus.Registerer) (*Sweeper, error) {
+	m := newSweeperMetrics(name, r)
 	p, err := newMarkerStorageReader(workingDir, deleteWorkerCount, minAgeDelete, m)
 	if err != nil {
 		return nil, err
@@ -343,24 +353,11 @@ func (c *chunkRewriter) rewriteChunk(ctx context.Context, ce ChunkEntry, interva
 			return false, err
 		}
 
-		entries, err := c.seriesStoreSchema.GetChunkWriteEntries(interval.Start, interval.End, userID, "logs", newChunk.Metric, c.scfg.ExternalKey(newChunk))
+		uploadChunk, err := c.putChunkIndexEntries(newChunk, c.scfg.ExternalKey(newChunk))
 		if err != nil {
 			return false, err
 		}
 
-		uploadChunk := false
-
-		for _, entry := range entries {
-			// write an entry only if it belongs to this table
-			if entry.TableName == c.tableName {
-				key := entry.HashValue + separator + string(entry.RangeValue)
-				if err := c.bucket.Put([]byte(key), nil); err != nil {
-					return false, err
-				}
-				uploadChunk = true
-			}
-		}
-
 		// upload chunk only if an entry was written
 		if uploadChunk {
 			err = c.chunkClient.PutChunks(ctx, []chunk.Chunk{newChunk})
@@ -373,3 +370,42 @@ func (c *chunkRewriter) rewriteChunk(ctx context.Context, ce ChunkEntry, interva
 
 	return wroteChunks, nil
 }
+
+// putChunkIndexEntries writes the index entries of the chunk which belong to this table and returns whether any was written.
+func (c *chunkRewriter) putChunkIndexEntries(chk chunk.Chunk, chunkID string) (bool, error) {
+	entries, err := c.seriesStoreSchema.GetChunkWriteEntries(chk.From, chk.Through, chk.UserID, logMetricName, chk.Metric, chunkID)
+	if err != nil {
+		return false, err
+	}
+
+	wroteEntries := false
+	for _, entry := range entries {
+		// write an entry only if it belongs to this table
+		if entry.TableName == c.tableName {
+			key := entry.HashValue + separator + string(entry.RangeValue)
+			if err := c.bucket.Put([]byte(key), nil); err != nil {
+				return false, err
+			}
+			wroteEntries = true
+		}
+	}
+	return wroteEntries, nil
+}
+
+// deleteChunkIndexEntries deletes the index entries of the chunk which belong to this table.
+func (c *chunkRewriter) deleteChunkIndexEntries(chk chunk.Chunk, chunkID string) error {
+	entries, err := c.seriesStoreSchema.GetChunkWriteEntries(chk.From, chk.Through, chk.UserID, logMetricName, chk.Metric, chunkID)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		if entry.TableName == c.tableName {
+			key := entry.HashValue + separator + string(entry.RangeValue)
+			if err := c.bucket.Delete([]byte(key)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
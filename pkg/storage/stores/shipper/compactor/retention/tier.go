@@ -0,0 +1,247 @@
+package retention
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"hash/crc32"
+	"io/ioutil"
+	"sync"
+	"time"
+
+	"github.com/go-kit/log/level"
+	"github.com/grafana/dskit/concurrency"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/model"
+	"go.etcd.io/bbolt"
+
+	"github.com/grafana/loki/pkg/storage"
+	"github.com/grafana/loki/pkg/storage/chunk"
+	"github.com/grafana/loki/pkg/storage/chunk/local"
+	"github.com/grafana/loki/pkg/storage/chunk/objectclient"
+	util_log "github.com/grafana/loki/pkg/util/log"
+)
+
+const tierChunksConcurrency = 16
+
+var castagnoliTable = crc32.MakeTable(crc32.Castagnoli)
+
+// ChunkTierer copies the chunks older than the cold_tier_after limit of their tenant from the primary object store
+// to the cold one. Each copy is read back and verified against the checksum of the original before the primary copy
+// gets marked for deletion, so that it is removed by a Sweeper after the delete delay.
+// The index is left untouched: the chunks are read from the cold store when they are not found in the primary one.
+type ChunkTierer struct {
+	workingDirectory string
+	config           storage.SchemaConfig
+	hot, cold        chunk.ObjectClient
+	keyEncoder       objectclient.KeyEncoder
+	limits           Limits
+	lookbackPeriod   time.Duration
+	metrics          *tiererMetrics
+}
+
+// NewChunkTierer makes a ChunkTierer moving chunks from the hot object store to the cold one. The chunks of the tables which
+// ended more than lookbackPeriod before the oldest age a tenant gets its chunks moved at are not considered anymore.
+func NewChunkTierer(workingDirectory string, config storage.SchemaConfig, hot, cold chunk.ObjectClient, keyEncoder objectclient.KeyEncoder, limits Limits, lookbackPeriod time.Duration, r prometheus.Registerer) (*ChunkTierer, error) {
+	if err := validatePeriods(config); err != nil {
+		return nil, err
+	}
+	return &ChunkTierer{
+		workingDirectory: workingDirectory,
+		config:           config,
+		hot:              hot,
+		cold:             cold,
+		keyEncoder:       keyEncoder,
+		limits:           limits,
+		lookbackPeriod:   lookbackPeriod,
+		metrics:          newTiererMetrics(r),
+	}, nil
+}
+
+// IntervalMayHaveColdChunks returns whether the interval of a table may have chunks to move to the cold tier.
+func (t *ChunkTierer) IntervalMayHaveColdChunks(interval model.Interval) bool {
+	var minAge, maxAge time.Duration
+	for _, l := range append([]time.Duration{time.Duration(t.limits.DefaultLimits().ColdTierAfter)}, t.tenantsColdTierAfter()...) {
+		if l <= 0 {
+			continue
+		}
+		if minAge == 0 || l < minAge {
+			minAge = l
+		}
+		if l > maxAge {
+			maxAge = l
+		}
+	}
+	if maxAge == 0 {
+		return false
+	}
+	now := model.Now()
+	return interval.End.Before(now.Add(-minAge)) && interval.End.After(now.Add(-maxAge-t.lookbackPeriod))
+}
+
+func (t *ChunkTierer) tenantsColdTierAfter() []time.Duration {
+	var res []time.Duration
+	for _, l := range t.limits.AllByUserID() {
+		if l != nil {
+			res = append(res, time.Duration(l.ColdTierAfter))
+		}
+	}
+	return res
+}
+
+// MarkForDelete moves the old chunks of a given table to the cold tier and marks their primary copies for deletion.
+// The index is never modified.
+func (t *ChunkTierer) MarkForDelete(ctx context.Context, tableName string, db *bbolt.DB) (bool, bool, error) {
+	start := time.Now()
+	status := statusSuccess
+	defer func() {
+		t.metrics.tableProcessedDurationSeconds.WithLabelValues(status).Observe(time.Since(start).Seconds())
+		level.Debug(util_log.Logger).Log("msg", "finished to move chunks of table to the cold tier", "table", tableName, "duration", time.Since(start))
+	}()
+	level.Debug(util_log.Logger).Log("msg", "starting to move chunks of table to the cold tier", "table", tableName)
+
+	if err := t.tierTable(ctx, tableName, db); err != nil {
+		status = statusFailure
+		return false, false, err
+	}
+	return false, false, nil
+}
+
+func (t *ChunkTierer) tierTable(ctx context.Context, tableName string, db *bbolt.DB) error {
+	schemaCfg, ok := schemaPeriodForTable(t.config, tableName)
+	if !ok {
+		return fmt.Errorf("could not find schema for table: %s", tableName)
+	}
+
+	var keys []string
+	err := db.View(func(tx *bbolt.Tx) error {
+		bucket := tx.Bucket(local.IndexBucketName)
+		if bucket == nil {
+			return nil
+		}
+
+		chunkIt, err := newChunkIndexIterator(bucket, schemaCfg)
+		if err != nil {
+			return fmt.Errorf("failed to create chunk index iterator: %w", err)
+		}
+		keys, err = t.collectColdChunks(chunkIt, ExtractIntervalFromTableName(tableName))
+		return err
+	})
+	if err != nil || len(keys) == 0 {
+		return err
+	}
+
+	markerWriter, err := NewMarkerStorageWriter(t.workingDirectory)
+	if err != nil {
+		return fmt.Errorf("failed to create marker writer: %w", err)
+	}
+
+	var mtx sync.Mutex
+	jobs := make([]interface{}, 0, len(keys))
+	for _, key := range keys {
+		jobs = append(jobs, key)
+	}
+	err = concurrency.ForEach(ctx, jobs, tierChunksConcurrency, func(ctx context.Context, job interface{}) error {
+		key := job.(string)
+		moved, err := t.moveChunk(ctx, key)
+		if err != nil || !moved {
+			return err
+		}
+
+		mtx.Lock()
+		defer mtx.Unlock()
+		return markerWriter.Put([]byte(key))
+	})
+	if closeErr := markerWriter.Close(); closeErr != nil && err == nil {
+		err = fmt.Errorf("failed to close marker writer: %w", closeErr)
+	}
+	return err
+}
+
+// collectColdChunks returns the external keys of the chunks old enough to be moved to the cold tier.
+// Chunks indexed in multiple tables are only considered in the last one, which is the one where they end.
+func (t *ChunkTierer) collectColdChunks(chunkIt ChunkEntryIterator, tableInterval model.Interval) ([]string, error) {
+	now := model.Now()
+	coldBefore := map[string]model.Time{}
+	seen := map[string]struct{}{}
+	var keys []string
+
+	for chunkIt.Next() {
+		c := chunkIt.Entry()
+		if c.Through > tableInterval.End {
+			continue
+		}
+
+		userID := string(c.UserID)
+		before, ok := coldBefore[userID]
+		if !ok {
+			if after := t.limits.ColdTierAfter(userID); after > 0 {
+				before = now.Add(-after)
+			}
+			coldBefore[userID] = before
+		}
+		if c.Through >= before {
+			continue
+		}
+
+		chk, err := chunk.ParseExternalKey(userID, string(c.ChunkID))
+		if err != nil {
+			return nil, err
+		}
+		key := t.config.ExternalKey(chk)
+		if _, ok := seen[key]; ok {
+			continue
+		}
+		seen[key] = struct{}{}
+		keys = append(keys, key)
+	}
+	return keys, chunkIt.Err()
+}
+
+// moveChunk copies a chunk from the hot store to the cold one and verifies the copy.
+// It returns false when the chunk is not in the hot store anymore.
+func (t *ChunkTierer) moveChunk(ctx context.Context, key string) (bool, error) {
+	objectKey := key
+	if t.keyEncoder != nil {
+		objectKey = t.keyEncoder(key)
+	}
+
+	buf, err := readObject(ctx, t.hot, objectKey)
+	if err != nil && t.hot.IsObjectNotFoundErr(err) {
+		// already moved, or deleted in the meantime.
+		return false, nil
+	}
+	if err != nil {
+		t.metrics.chunksMovedTotal.WithLabelValues(statusFailure).Inc()
+		return false, err
+	}
+
+	if err := t.cold.PutObject(ctx, objectKey, bytes.NewReader(buf)); err != nil {
+		t.metrics.chunksMovedTotal.WithLabelValues(statusFailure).Inc()
+		return false, err
+	}
+
+	copied, err := readObject(ctx, t.cold, objectKey)
+	if err != nil {
+		t.metrics.chunksMovedTotal.WithLabelValues(statusFailure).Inc()
+		return false, err
+	}
+	if crc32.Checksum(copied, castagnoliTable) != crc32.Checksum(buf, castagnoliTable) {
+		// keep the primary copy and let the next run try again.
+		t.metrics.chunksMovedTotal.WithLabelValues(statusChecksumMismatch).Inc()
+		level.Warn(util_log.Logger).Log("msg", "checksum mismatch of the chunk copied to the cold tier", "chunkID", key)
+		return false, nil
+	}
+
+	t.metrics.chunksMovedTotal.WithLabelValues(statusSuccess).Inc()
+	return true, nil
+}
+
+func readObject(ctx context.Context, client chunk.ObjectClient, objectKey string) ([]byte, error) {
+	rc, _, err := client.GetObject(ctx, objectKey)
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	return ioutil.ReadAll(rc)
+}
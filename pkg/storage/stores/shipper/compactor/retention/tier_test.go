@@ -0,0 +1,135 @@
+package retention
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"io/ioutil"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/loki/pkg/storage/chunk"
+	"github.com/grafana/loki/pkg/storage/chunk/objectclient"
+	"github.com/grafana/loki/pkg/storage/chunk/storage"
+)
+
+func TestChunkTierer(t *testing.T) {
+	for _, tt := range allSchemas {
+		tt := tt
+		t.Run(tt.schema, func(t *testing.T) {
+			cm := storage.NewClientMetrics()
+			defer cm.Unregister()
+			store := newTestStore(t, cm)
+
+			from := tt.from.Add(2 * time.Hour)
+			lbs := labels.Labels{labels.Label{Name: "foo", Value: "bar"}}
+			cold := []chunk.Chunk{
+				createChunk(t, "1", lbs, from, from.Add(10*time.Minute)),
+				// indexed in two tables, it is moved once.
+				createChunk(t, "1", lbs, from.Add(-4*time.Hour), from),
+			}
+			hot := []chunk.Chunk{
+				createChunk(t, "2", lbs, from, from.Add(10*time.Minute)),
+			}
+			require.NoError(t, store.Put(context.TODO(), append(cold, hot...)))
+			store.Stop()
+
+			hotClient := newTestObjectClient(store.chunkDir, cm)
+			coldClient := newTestObjectClient(t.TempDir(), cm)
+			limits := &fakeLimits{perTenant: map[string]retentionLimit{"1": {coldTierAfter: time.Hour}}}
+
+			workDir := t.TempDir()
+			tierer, err := NewChunkTierer(workDir, schemaCfg, hotClient, coldClient, objectclient.Base64Encoder, limits, 0, prometheus.NewRegistry())
+			require.NoError(t, err)
+			for _, indexTable := range store.indexTables() {
+				empty, modified, err := tierer.MarkForDelete(context.Background(), indexTable.name, indexTable.DB)
+				require.NoError(t, err)
+				require.False(t, empty)
+				require.False(t, modified)
+				require.NoError(t, indexTable.DB.Close())
+			}
+			require.Equal(t, float64(len(cold)), testutil.ToFloat64(tierer.metrics.chunksMovedTotal.WithLabelValues(statusSuccess)))
+
+			// only the primary copies of the moved chunks are marked for deletion.
+			expectedMarks := []string{}
+			for _, c := range cold {
+				expectedMarks = append(expectedMarks, store.schemaCfg.ExternalKey(c))
+			}
+			sort.Strings(expectedMarks)
+			marks := readMarks(t, workDir)
+			require.Equal(t, expectedMarks, marks)
+
+			hotChunks := objectclient.NewClient(hotClient, objectclient.Base64Encoder, schemaCfg.SchemaConfig)
+			for _, mark := range marks {
+				require.NoError(t, hotChunks.DeleteChunk(context.Background(), "1", mark))
+			}
+
+			// the chunks are read from either tier.
+			tiered := objectclient.NewClient(objectclient.NewTieredObjectClient(hotClient, coldClient), objectclient.Base64Encoder, schemaCfg.SchemaConfig)
+			for _, c := range append(cold, hot...) {
+				fetched, err := tiered.GetChunks(context.Background(), []chunk.Chunk{chunkRef(t, c)})
+				require.NoError(t, err)
+				require.Len(t, fetched, 1)
+			}
+			_, err = hotChunks.GetChunks(context.Background(), []chunk.Chunk{chunkRef(t, cold[0])})
+			require.True(t, hotChunks.IsChunkNotFoundErr(errors.Cause(err)))
+		})
+	}
+}
+
+func TestChunkTierer_ChecksumMismatch(t *testing.T) {
+	cm := storage.NewClientMetrics()
+	defer cm.Unregister()
+	store := newTestStore(t, cm)
+
+	from := allSchemas[len(allSchemas)-1].from.Add(2 * time.Hour)
+	c := createChunk(t, "1", labels.Labels{labels.Label{Name: "foo", Value: "bar"}}, from, from.Add(10*time.Minute))
+	require.NoError(t, store.Put(context.TODO(), []chunk.Chunk{c}))
+	store.Stop()
+
+	hotClient := newTestObjectClient(store.chunkDir, cm)
+	coldClient := &corruptingObjectClient{ObjectClient: newTestObjectClient(t.TempDir(), cm)}
+	limits := &fakeLimits{perTenant: map[string]retentionLimit{"1": {coldTierAfter: time.Hour}}}
+
+	workDir := t.TempDir()
+	tierer, err := NewChunkTierer(workDir, schemaCfg, hotClient, coldClient, objectclient.Base64Encoder, limits, 0, prometheus.NewRegistry())
+	require.NoError(t, err)
+	for _, indexTable := range store.indexTables() {
+		_, _, err := tierer.MarkForDelete(context.Background(), indexTable.name, indexTable.DB)
+		require.NoError(t, err)
+		require.NoError(t, indexTable.DB.Close())
+	}
+
+	// the primary copy is kept when the copy doesn't match.
+	require.Equal(t, float64(1), testutil.ToFloat64(tierer.metrics.chunksMovedTotal.WithLabelValues(statusChecksumMismatch)))
+	require.Empty(t, readMarks(t, workDir))
+}
+
+// chunkRef returns the chunk as parsed from its key, without its data.
+func chunkRef(t *testing.T, c chunk.Chunk) chunk.Chunk {
+	t.Helper()
+	ref, err := chunk.ParseExternalKey(c.UserID, schemaCfg.ExternalKey(c))
+	require.NoError(t, err)
+	return ref
+}
+
+// corruptingObjectClient flips a bit of the objects put in it.
+type corruptingObjectClient struct {
+	chunk.ObjectClient
+}
+
+func (c *corruptingObjectClient) PutObject(ctx context.Context, objectKey string, object io.ReadSeeker) error {
+	buf, err := ioutil.ReadAll(object)
+	if err != nil {
+		return err
+	}
+	buf[len(buf)-1] ^= 1
+	return c.ObjectClient.PutObject(ctx, objectKey, bytes.NewReader(buf))
+}
@@ -90,7 +90,7 @@ var (
 		{"v11", schemaCfg.Configs[2].From.Time, schemaCfg.Configs[2]},
 	}
 
-	sweepMetrics = newSweeperMetrics(prometheus.DefaultRegisterer)
+	sweepMetrics = newSweeperMetrics("retention", prometheus.DefaultRegisterer)
 )
 
 func newChunkEntry(userID, labels string, from, through model.Time) ChunkEntry {
@@ -42,6 +42,10 @@ type indexEntry struct {
 	k, v []byte
 }
 
+// errTableNotOwned is returned when a compactor stops compacting a table because the table got handed off to
+// another compactor of the ring.
+var errTableNotOwned = errors.New("table is not owned by this compactor anymore")
+
 type tableExpirationChecker interface {
 	IntervalMayHaveExpiredChunks(interval model.Interval, userID string) bool
 }
@@ -52,6 +56,8 @@ type table struct {
 	indexStorageClient storage.Client
 	tableMarker        retention.TableMarker
 	expirationChecker  tableExpirationChecker
+	chunkMerger        retention.TableMarker
+	chunkTierer        retention.TableMarker
 
 	baseUserIndexSet, baseCommonIndexSet storage.IndexSet
 
@@ -63,11 +69,15 @@ type table struct {
 	seedSourceFileIdx     int
 	logger                log.Logger
 
+	// ownsTable, when set, is called before each step changing the index or the chunks of the table so that
+	// compactors which lost the table stop before applying any change.
+	ownsTable func() (bool, error)
+
 	ctx context.Context
 }
 
 func newTable(ctx context.Context, workingDirectory string, indexStorageClient storage.Client,
-	tableMarker retention.TableMarker, expirationChecker tableExpirationChecker) (*table, error) {
+	tableMarker retention.TableMarker, expirationChecker tableExpirationChecker, chunkMerger, chunkTierer retention.TableMarker) (*table, error) {
 	err := chunk_util.EnsureDirectory(workingDirectory)
 	if err != nil {
 		return nil, err
@@ -80,6 +90,8 @@ func newTable(ctx context.Context, workingDirectory string, indexStorageClient s
 		indexStorageClient: indexStorageClient,
 		tableMarker:        tableMarker,
 		expirationChecker:  expirationChecker,
+		chunkMerger:        chunkMerger,
+		chunkTierer:        chunkTierer,
 		indexSets:          map[string]*indexSet{},
 		baseUserIndexSet:   storage.NewIndexSet(indexStorageClient, true),
 		baseCommonIndexSet: storage.NewIndexSet(indexStorageClient, false),
@@ -90,7 +102,7 @@ func newTable(ctx context.Context, workingDirectory string, indexStorageClient s
 	return &table, nil
 }
 
-func (t *table) compact(applyRetention bool) error {
+func (t *table) compact(applyRetention, mergeChunks, tierChunks bool) error {
 	indexFiles, usersWithPerUserIndex, err := t.indexStorageClient.ListFiles(t.ctx, t.name)
 	if err != nil {
 		return err
@@ -129,8 +141,8 @@ func (t *table) compact(applyRetention bool) error {
 		if err := t.compactFiles(indexFiles); err != nil {
 			return err
 		}
-	} else if len(indexFiles) == 1 && (applyRetention || mustRecreateCompactedDB(indexFiles)) {
-		// initialize common compacted db if we need to apply retention, or we need to recreate it
+	} else if len(indexFiles) == 1 && (applyRetention || mergeChunks || tierChunks || mustRecreateCompactedDB(indexFiles)) {
+		// initialize common compacted db if we need to apply retention, merge or tier chunks, or we need to recreate it
 		t.seedSourceFileIdx = 0
 		downloadAt := filepath.Join(t.workingDirectory, indexFiles[0].Name)
 		err = shipper_util.DownloadFileFromStorage(downloadAt, shipper_util.IsCompressedFile(indexFiles[0].Name),
@@ -159,15 +171,57 @@ func (t *table) compact(applyRetention bool) error {
 	}
 
 	if applyRetention {
+		if err := t.checkOwnership(); err != nil {
+			return err
+		}
 		err := t.applyRetention()
 		if err != nil {
 			return err
 		}
 	}
 
+	if mergeChunks {
+		if err := t.checkOwnership(); err != nil {
+			return err
+		}
+		err := t.runOnAllIndexSets(t.chunkMerger)
+		if err != nil {
+			return err
+		}
+	}
+
+	if tierChunks {
+		if err := t.checkOwnership(); err != nil {
+			return err
+		}
+		err := t.runOnAllIndexSets(t.chunkTierer)
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := t.checkOwnership(); err != nil {
+		return err
+	}
 	return t.done()
 }
 
+// checkOwnership returns errTableNotOwned if the table got handed off to another compactor.
+func (t *table) checkOwnership() error {
+	if t.ownsTable == nil {
+		return nil
+	}
+
+	owned, err := t.ownsTable()
+	if err != nil {
+		return err
+	}
+	if !owned {
+		return errTableNotOwned
+	}
+	return nil
+}
+
 // done takes care of final operations which includes:
 // - initializing user index sets which requires recreation of files
 // - call indexSet.done() on all the index sets.
@@ -237,6 +291,24 @@ func (t *table) applyRetention() error {
 	return nil
 }
 
+// runOnAllIndexSets initializes all the index sets and runs the marker on them, e.g. to merge or tier the chunks they index.
+func (t *table) runOnAllIndexSets(marker retention.TableMarker) error {
+	for _, userID := range t.usersWithPerUserIndex {
+		if _, err := t.getOrCreateUserIndex(userID); err != nil {
+			return err
+		}
+	}
+
+	for _, is := range t.indexSets {
+		err := is.runRetention(marker)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 // compactFiles compacts the given files into a single file.
 func (t *table) compactFiles(files []storage.IndexFile) error {
 	var err error
@@ -211,10 +211,10 @@ func TestTable_Compaction(t *testing.T) {
 			require.NoError(t, err)
 
 			table, err := newTable(context.Background(), tableWorkingDirectory, storage.NewIndexStorageClient(objectClient, ""),
-				nil, nil)
+				nil, nil, nil, nil)
 			require.NoError(t, err)
 
-			require.NoError(t, table.compact(false))
+			require.NoError(t, table.compact(false, false, false))
 
 			numUserIndexSets, numCommonIndexSets := 0, 0
 			for _, is := range table.indexSets {
@@ -260,10 +260,10 @@ func TestTable_Compaction(t *testing.T) {
 
 			// running compaction again should not do anything.
 			table, err = newTable(context.Background(), tableWorkingDirectory, storage.NewIndexStorageClient(objectClient, ""),
-				nil, nil)
+				nil, nil, nil, nil)
 			require.NoError(t, err)
 
-			require.NoError(t, table.compact(false))
+			require.NoError(t, table.compact(false, false, false))
 
 			for _, is := range table.indexSets {
 				require.False(t, is.uploadCompactedDB)
@@ -373,10 +373,10 @@ func TestTable_CompactionRetention(t *testing.T) {
 				table, err := newTable(context.Background(), tableWorkingDirectory, storage.NewIndexStorageClient(objectClient, ""),
 					tt.tableMarker, IntervalMayHaveExpiredChunksFunc(func(interval model.Interval, userID string) bool {
 						return true
-					}))
+					}), nil, nil)
 				require.NoError(t, err)
 
-				require.NoError(t, table.compact(true))
+				require.NoError(t, table.compact(true, false, false))
 				tt.assert(t, objectStoragePath, tableName)
 			})
 		}
@@ -455,11 +455,11 @@ func TestTable_CompactionFailure(t *testing.T) {
 	objectClient, err := local.NewFSObjectClient(local.FSConfig{Directory: objectStoragePath})
 	require.NoError(t, err)
 
-	table, err := newTable(context.Background(), tableWorkingDirectory, storage.NewIndexStorageClient(objectClient, ""), nil, nil)
+	table, err := newTable(context.Background(), tableWorkingDirectory, storage.NewIndexStorageClient(objectClient, ""), nil, nil, nil, nil)
 	require.NoError(t, err)
 
 	// compaction should fail due to a non-boltdb file.
-	require.Error(t, table.compact(false))
+	require.Error(t, table.compact(false, false, false))
 
 	// ensure that files in storage are intact.
 	files, err := ioutil.ReadDir(tablePathInStorage)
@@ -472,9 +472,9 @@ func TestTable_CompactionFailure(t *testing.T) {
 	// remove the non-boltdb file and ensure that compaction succeeds now.
 	require.NoError(t, os.Remove(filepath.Join(tablePathInStorage, "fail.txt")))
 
-	table, err = newTable(context.Background(), tableWorkingDirectory, storage.NewIndexStorageClient(objectClient, ""), nil, nil)
+	table, err = newTable(context.Background(), tableWorkingDirectory, storage.NewIndexStorageClient(objectClient, ""), nil, nil, nil, nil)
 	require.NoError(t, err)
-	require.NoError(t, table.compact(false))
+	require.NoError(t, table.compact(false, false, false))
 
 	// ensure that we have cleanup the local working directory after successful compaction.
 	require.NoFileExists(t, tableWorkingDirectory)
@@ -545,6 +545,52 @@ func readDB(t *testing.T, db *bbolt.DB) map[string]map[string]string {
 	return dbRecords
 }
 
+func TestTable_CompactionHandedOff(t *testing.T) {
+	for name, ownedChecks := range map[string]int{
+		"handed off before applying retention": 0,
+		"handed off before uploading":          1,
+	} {
+		ownedChecks := ownedChecks
+		t.Run(name, func(t *testing.T) {
+			tempDir := t.TempDir()
+			tableName := fmt.Sprintf("%s12345", tableName)
+			objectStoragePath := filepath.Join(tempDir, objectsStorageDirName)
+			tablePathInStorage := filepath.Join(objectStoragePath, tableName)
+			tableWorkingDirectory := filepath.Join(tempDir, workingDirName, tableName)
+
+			testutil.SetupTable(t, tablePathInStorage, testutil.DBsConfig{NumUnCompactedDBs: 10}, testutil.PerUserDBsConfig{})
+
+			objectClient, err := local.NewFSObjectClient(local.FSConfig{Directory: objectStoragePath})
+			require.NoError(t, err)
+
+			retentionApplied := false
+			table, err := newTable(context.Background(), tableWorkingDirectory, storage.NewIndexStorageClient(objectClient, ""),
+				TableMarkerFunc(func(ctx context.Context, tableName string, db *bbolt.DB) (bool, bool, error) {
+					retentionApplied = true
+					return false, false, nil
+				}), IntervalMayHaveExpiredChunksFunc(func(interval model.Interval, userID string) bool {
+					return true
+				}), nil, nil)
+			require.NoError(t, err)
+
+			// the table gets handed off after the given number of ownership checks.
+			table.ownsTable = func() (bool, error) {
+				ownedChecks--
+				return ownedChecks >= 0, nil
+			}
+
+			require.Equal(t, errTableNotOwned, table.compact(true, false, false))
+			require.Equal(t, name == "handed off before uploading", retentionApplied)
+
+			// nothing gets uploaded nor removed from the storage.
+			files, err := ioutil.ReadDir(tablePathInStorage)
+			require.NoError(t, err)
+			require.Len(t, files, 10)
+			require.NoFileExists(t, tableWorkingDirectory)
+		})
+	}
+}
+
 func TestTable_RecreateCompactedDB(t *testing.T) {
 	for name, tt := range map[string]struct {
 		dbCount                   int
@@ -682,10 +728,10 @@ func TestTable_RecreateCompactedDB(t *testing.T) {
 			table, err := newTable(context.Background(), tableWorkingDirectory, storage.NewIndexStorageClient(objectClient, ""),
 				tt.tableMarker, IntervalMayHaveExpiredChunksFunc(func(interval model.Interval, userID string) bool {
 					return true
-				}))
+				}), nil, nil)
 			require.NoError(t, err)
 
-			require.NoError(t, table.compact(true))
+			require.NoError(t, table.compact(true, false, false))
 			for _, indexSet := range table.indexSets {
 				require.Equal(t, tt.expectedIndexSetState.recreateCompactedDB, indexSet.compactedDBRecreated, fmt.Sprint(indexSet))
 				require.Equal(t, tt.expectedIndexSetState.uploadCompactedDB, indexSet.uploadCompactedDB)
@@ -707,10 +753,10 @@ func TestTable_RecreateCompactedDB(t *testing.T) {
 				table, err := newTable(context.Background(), tableWorkingDirectory, storage.NewIndexStorageClient(objectClient, ""),
 					tt.tableMarker, IntervalMayHaveExpiredChunksFunc(func(interval model.Interval, userID string) bool {
 						return true
-					}))
+					}), nil, nil)
 				require.NoError(t, err)
 
-				require.NoError(t, table.compact(true))
+				require.NoError(t, table.compact(true, false, false))
 				for _, indexSet := range table.indexSets {
 					require.Equal(t, false, indexSet.compactedDBRecreated)
 					require.Equal(t, false, indexSet.uploadCompactedDB)
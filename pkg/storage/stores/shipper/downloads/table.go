@@ -54,6 +54,10 @@ type Table struct {
 	logger       log.Logger
 	indexSets    map[string]IndexSet
 	indexSetsMtx sync.RWMutex
+
+	// ownsTenant is set when the index of the tenants is sharded, e.g. across index gateways,
+	// in which case only the index of the owned tenants is kept in sync.
+	ownsTenant func(userID string) bool
 }
 
 // NewTable just creates an instance of Table without trying to load files from local storage or object store.
@@ -220,6 +224,10 @@ func (t *Table) DropUnusedIndex(ttl time.Duration, now time.Time) (bool, error)
 func (t *Table) Sync(ctx context.Context) error {
 	level.Debug(t.logger).Log("msg", fmt.Sprintf("syncing files for table %s", t.name))
 
+	if err := t.dropUnownedIndexSets(); err != nil {
+		return err
+	}
+
 	t.indexSetsMtx.RLock()
 	defer t.indexSetsMtx.RUnlock()
 
@@ -232,6 +240,30 @@ func (t *Table) Sync(ctx context.Context) error {
 	return nil
 }
 
+// dropUnownedIndexSets drops the index sets of the tenants not owned anymore, e.g. after the ring changed.
+func (t *Table) dropUnownedIndexSets() error {
+	if t.ownsTenant == nil {
+		return nil
+	}
+
+	t.indexSetsMtx.Lock()
+	defer t.indexSetsMtx.Unlock()
+
+	for userID, indexSet := range t.indexSets {
+		if userID == "" || t.ownsTenant(userID) {
+			continue
+		}
+
+		level.Info(t.logger).Log("msg", fmt.Sprintf("dropping index set %s of a tenant not owned anymore", userID))
+		if err := indexSet.DropAllDBs(); err != nil {
+			return err
+		}
+		delete(t.indexSets, userID)
+	}
+
+	return nil
+}
+
 func (t *Table) getOrCreateIndexSet(id string, async bool) (IndexSet, error) {
 	t.indexSetsMtx.RLock()
 	indexSet, ok := t.indexSets[id]
@@ -295,6 +327,9 @@ func (t *Table) EnsureQueryReadiness(ctx context.Context) error {
 	missingUserIDs := make([]string, 0, len(userIDs))
 	t.indexSetsMtx.RLock()
 	for _, userID := range userIDs {
+		if t.ownsTenant != nil && !t.ownsTenant(userID) {
+			continue
+		}
 		if userIndexSet, ok := t.indexSets[userID]; !ok {
 			missingUserIDs = append(missingUserIDs, userID)
 		} else {
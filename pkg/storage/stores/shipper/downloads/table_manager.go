@@ -30,6 +30,9 @@ type Config struct {
 	SyncInterval      time.Duration
 	CacheTTL          time.Duration
 	QueryReadyNumDays int
+	// OwnsTenant is optional. When set, only the per tenant index of the tenants it returns true for
+	// is downloaded for query readiness and kept in sync, the index of the other tenants is dropped.
+	OwnsTenant func(userID string) bool
 }
 
 type TableManager struct {
@@ -173,6 +176,7 @@ func (tm *TableManager) getOrCreateTable(tableName string) (*Table, error) {
 			}
 
 			table = NewTable(tableName, filepath.Join(tm.cfg.CacheDir, tableName), tm.indexStorageClient, tm.boltIndexClient, tm.metrics)
+			table.ownsTenant = tm.cfg.OwnsTenant
 			tm.tables[tableName] = table
 		}
 		tm.tablesMtx.Unlock()
@@ -272,6 +276,7 @@ func (tm *TableManager) ensureQueryReadiness() error {
 		if err != nil {
 			return err
 		}
+		table.ownsTenant = tm.cfg.OwnsTenant
 
 		tm.tablesMtx.Lock()
 		tm.tables[tableName] = table
@@ -341,6 +346,7 @@ func (tm *TableManager) loadLocalTables() error {
 		if err != nil {
 			return err
 		}
+		table.ownsTenant = tm.cfg.OwnsTenant
 
 		tm.tables[fileInfo.Name()] = table
 	}
@@ -298,6 +298,56 @@ func TestTable_EnsureQueryReadiness(t *testing.T) {
 	}
 }
 
+func TestTable_OwnsTenant(t *testing.T) {
+	tempDir := t.TempDir()
+
+	dbsToSetup := map[string]testutil.DBConfig{
+		"db1": {
+			CompressFile: true,
+			DBRecords: testutil.DBRecords{
+				Start:      0,
+				NumRecords: 10,
+			},
+		},
+	}
+
+	objectStoragePath := filepath.Join(tempDir, objectsStorageDirName)
+	tablePathInStorage := filepath.Join(objectStoragePath, tableName)
+	testutil.SetupDBsAtPath(t, tablePathInStorage, dbsToSetup, nil)
+	testutil.SetupDBsAtPath(t, filepath.Join(tablePathInStorage, "user1"), dbsToSetup, nil)
+	testutil.SetupDBsAtPath(t, filepath.Join(tablePathInStorage, "user2"), dbsToSetup, nil)
+
+	boltDBIndexClient, storageClient := buildTestClients(t, tempDir)
+	defer boltDBIndexClient.Stop()
+
+	cachePath := filepath.Join(tempDir, cacheDirName)
+	table := NewTable(tableName, cachePath, storageClient, boltDBIndexClient, newMetrics(nil))
+	defer table.Close()
+
+	ownedTenants := map[string]bool{"user1": true}
+	table.ownsTenant = func(userID string) bool {
+		return ownedTenants[userID]
+	}
+
+	// EnsureQueryReadiness should only initialize the index of the owned tenants.
+	require.NoError(t, table.EnsureQueryReadiness(context.Background()))
+	require.Len(t, table.indexSets, 2)
+	ensureIndexSetExistsInTable(t, table, "")
+	ensureIndexSetExistsInTable(t, table, "user1")
+
+	// Sync should drop the index of the tenants not owned anymore, but never the common index.
+	ownedTenants = map[string]bool{"user2": true}
+	require.NoError(t, table.Sync(context.Background()))
+	require.Len(t, table.indexSets, 1)
+	ensureIndexSetExistsInTable(t, table, "")
+	require.NoDirExists(t, filepath.Join(cachePath, "user1"))
+
+	require.NoError(t, table.EnsureQueryReadiness(context.Background()))
+	require.Len(t, table.indexSets, 2)
+	ensureIndexSetExistsInTable(t, table, "")
+	ensureIndexSetExistsInTable(t, table, "user2")
+}
+
 func TestTable_Sync(t *testing.T) {
 	tempDir := t.TempDir()
 
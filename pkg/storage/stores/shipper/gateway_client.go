@@ -4,29 +4,51 @@ import (
 	"context"
 	"flag"
 	"fmt"
+	"hash/fnv"
 	"io"
+	"math/rand"
+	"time"
 
 	"github.com/go-kit/log/level"
 	"github.com/grafana/dskit/grpcclient"
+	"github.com/grafana/dskit/ring"
+	ring_client "github.com/grafana/dskit/ring/client"
+	"github.com/grafana/dskit/services"
 	"github.com/pkg/errors"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promauto"
 	"github.com/weaveworks/common/instrument"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/health/grpc_health_v1"
 
 	"github.com/grafana/loki/pkg/storage/chunk"
 	"github.com/grafana/loki/pkg/storage/chunk/util"
 	"github.com/grafana/loki/pkg/storage/stores/shipper/indexgateway/indexgatewaypb"
 	shipper_util "github.com/grafana/loki/pkg/storage/stores/shipper/util"
+	"github.com/grafana/loki/pkg/tenant"
 	util_log "github.com/grafana/loki/pkg/util/log"
 	util_math "github.com/grafana/loki/pkg/util/math"
 )
 
 const maxQueriesPerGoroutine = 100
 
+// IndexGatewayRingOp is the operation used to find the index gateways owning a tenant.
+var IndexGatewayRingOp = ring.NewOp([]ring.InstanceState{ring.ACTIVE}, nil)
+
+// IndexGatewayTenantToken returns the token of a tenant in the index gateway ring.
+func IndexGatewayTenantToken(tenantID string) uint32 {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(tenantID))
+	return h.Sum32()
+}
+
 type IndexGatewayClientConfig struct {
 	Address          string            `yaml:"server_address,omitempty"`
 	GRPCClientConfig grpcclient.Config `yaml:"grpc_client_config"`
+
+	// Ring is set when the index gateways run in ring mode. The queries of each tenant are then sent to
+	// one of the gateways owning it, instead of the server address.
+	Ring ring.ReadRing `yaml:"-"`
 }
 
 // RegisterFlags registers flags.
@@ -47,6 +69,9 @@ type GatewayClient struct {
 	storeGatewayClientRequestDuration *prometheus.HistogramVec
 	conn                              *grpc.ClientConn
 	grpcClient                        indexgatewaypb.IndexGatewayClient
+
+	// pool is only set in ring mode, with a client for each of the index gateways.
+	pool *ring_client.Pool
 }
 
 func NewGatewayClient(cfg IndexGatewayClientConfig, r prometheus.Registerer) (*GatewayClient, error) {
@@ -65,6 +90,14 @@ func NewGatewayClient(cfg IndexGatewayClientConfig, r prometheus.Registerer) (*G
 		return nil, err
 	}
 
+	if cfg.Ring != nil {
+		sgClient.pool = newIndexGatewayClientPool(cfg.Ring, dialOpts, r)
+		if err := services.StartAndAwaitRunning(context.Background(), sgClient.pool); err != nil {
+			return nil, errors.Wrap(err, "failed to start index gateway client pool")
+		}
+		return sgClient, nil
+	}
+
 	sgClient.conn, err = grpc.Dial(cfg.Address, dialOpts...)
 	if err != nil {
 		return nil, err
@@ -75,6 +108,12 @@ func NewGatewayClient(cfg IndexGatewayClientConfig, r prometheus.Registerer) (*G
 }
 
 func (s *GatewayClient) Stop() {
+	if s.pool != nil {
+		if err := services.StopAndAwaitTerminated(context.Background(), s.pool); err != nil {
+			level.Error(util_log.Logger).Log("msg", "failed to stop index gateway client pool", "err", err)
+		}
+		return
+	}
 	s.conn.Close()
 }
 
@@ -114,30 +153,84 @@ func (s *GatewayClient) doQueries(ctx context.Context, queries []chunk.IndexQuer
 		})
 	}
 
-	streamer, err := s.grpcClient.QueryIndex(ctx, &indexgatewaypb.QueryIndexRequest{Queries: gatewayQueries})
+	req := &indexgatewaypb.QueryIndexRequest{Queries: gatewayQueries}
+	if s.pool == nil {
+		_, err := s.queryIndex(ctx, s.grpcClient, req, queryKeyQueryMap, callback)
+		return err
+	}
+
+	return s.ringModeQueryIndex(ctx, req, queryKeyQueryMap, callback)
+}
+
+// ringModeQueryIndex sends the queries to one of the index gateways owning the tenant, failing over to the
+// other replicas as long as no response was received.
+func (s *GatewayClient) ringModeQueryIndex(ctx context.Context, req *indexgatewaypb.QueryIndexRequest, queryKeyQueryMap map[string]chunk.IndexQuery, callback util.Callback) error {
+	tenantID, err := tenant.TenantID(ctx)
 	if err != nil {
 		return err
 	}
 
+	bufDescs, bufHosts, bufZones := ring.MakeBuffersForGet()
+	rs, err := s.cfg.Ring.Get(IndexGatewayTenantToken(tenantID), IndexGatewayRingOp, bufDescs, bufHosts, bufZones)
+	if err != nil {
+		return errors.Wrap(err, "index gateway ring get")
+	}
+
+	// spread the load of the tenant between the replicas.
+	addrs := rs.GetAddresses()
+	rand.Shuffle(len(addrs), func(i, j int) {
+		addrs[i], addrs[j] = addrs[j], addrs[i]
+	})
+
+	var lastErr error
+	for _, addr := range addrs {
+		client, err := s.pool.GetClientFor(addr)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+
+		responded, err := s.queryIndex(ctx, client.(indexgatewaypb.IndexGatewayClient), req, queryKeyQueryMap, callback)
+		if err == nil || responded || ctx.Err() != nil {
+			return err
+		}
+
+		level.Warn(util_log.Logger).Log("msg", "failed to query index gateway, trying the next replica", "addr", addr, "err", err)
+		lastErr = err
+	}
+
+	return lastErr
+}
+
+// queryIndex runs the queries on the given index gateway.
+// It returns whether any response was passed to the callback, in which case the queries can't be retried.
+func (s *GatewayClient) queryIndex(ctx context.Context, client indexgatewaypb.IndexGatewayClient, req *indexgatewaypb.QueryIndexRequest, queryKeyQueryMap map[string]chunk.IndexQuery, callback util.Callback) (bool, error) {
+	streamer, err := client.QueryIndex(ctx, req)
+	if err != nil {
+		return false, err
+	}
+
+	responded := false
 	for {
 		resp, err := streamer.Recv()
 		if err == io.EOF {
 			break
 		}
 		if err != nil {
-			return errors.WithStack(err)
+			return responded, errors.WithStack(err)
 		}
 		query, ok := queryKeyQueryMap[resp.QueryKey]
 		if !ok {
 			level.Error(util_log.Logger).Log("msg", fmt.Sprintf("unexpected %s QueryKey received, expected queries %s", resp.QueryKey, fmt.Sprint(queryKeyQueryMap)))
-			return fmt.Errorf("unexpected %s QueryKey received", resp.QueryKey)
+			return responded, fmt.Errorf("unexpected %s QueryKey received", resp.QueryKey)
 		}
+		responded = true
 		if !callback(query, &readBatch{resp}) {
-			return nil
+			return responded, nil
 		}
 	}
 
-	return nil
+	return responded, nil
 }
 
 func (s *GatewayClient) NewWriteBatch() chunk.WriteBatch {
@@ -148,6 +241,50 @@ func (s *GatewayClient) BatchWrite(ctx context.Context, batch chunk.WriteBatch)
 	panic("unsupported")
 }
 
+func newIndexGatewayClientPool(gatewayRing ring.ReadRing, dialOpts []grpc.DialOption, r prometheus.Registerer) *ring_client.Pool {
+	// We prefer sane defaults instead of exposing further config options.
+	poolCfg := ring_client.PoolConfig{
+		CheckInterval:      time.Minute,
+		HealthCheckEnabled: true,
+		HealthCheckTimeout: 10 * time.Second,
+	}
+
+	clientsCount := promauto.With(r).NewGauge(prometheus.GaugeOpts{
+		Namespace: "loki_boltdb_shipper",
+		Name:      "index_gateway_clients",
+		Help:      "The current number of index gateway clients in the pool.",
+	})
+
+	factory := func(addr string) (ring_client.PoolClient, error) {
+		conn, err := grpc.Dial(addr, dialOpts...)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to dial index gateway %s", addr)
+		}
+
+		return &indexGatewayClient{
+			IndexGatewayClient: indexgatewaypb.NewIndexGatewayClient(conn),
+			HealthClient:       grpc_health_v1.NewHealthClient(conn),
+			conn:               conn,
+		}, nil
+	}
+
+	return ring_client.NewPool("index-gateway", poolCfg, ring_client.NewRingServiceDiscovery(gatewayRing), factory, clientsCount, util_log.Logger)
+}
+
+type indexGatewayClient struct {
+	indexgatewaypb.IndexGatewayClient
+	grpc_health_v1.HealthClient
+	conn *grpc.ClientConn
+}
+
+func (c *indexGatewayClient) Close() error {
+	return c.conn.Close()
+}
+
+func (c *indexGatewayClient) String() string {
+	return c.conn.Target()
+}
+
 type readBatch struct {
 	*indexgatewaypb.QueryIndexResponse
 }
@@ -7,8 +7,14 @@ import (
 	"log"
 	"net"
 	"testing"
+	"time"
 
+	gokitlog "github.com/go-kit/log"
 	"github.com/grafana/dskit/flagext"
+	"github.com/grafana/dskit/kv"
+	"github.com/grafana/dskit/kv/consul"
+	"github.com/grafana/dskit/ring"
+	"github.com/grafana/dskit/services"
 
 	"github.com/stretchr/testify/require"
 	"github.com/weaveworks/common/user"
@@ -17,6 +23,7 @@ import (
 	"github.com/grafana/loki/pkg/storage/chunk"
 	"github.com/grafana/loki/pkg/storage/stores/shipper/indexgateway/indexgatewaypb"
 	"github.com/grafana/loki/pkg/storage/stores/shipper/util"
+	"github.com/grafana/loki/pkg/util/test"
 )
 
 const (
@@ -32,9 +39,16 @@ const (
 	valuePrefix      = "value"
 )
 
-type mockIndexGatewayServer struct{}
+type mockIndexGatewayServer struct {
+	indexgatewaypb.UnimplementedIndexGatewayServer
+	failQueries bool
+}
 
 func (m mockIndexGatewayServer) QueryIndex(request *indexgatewaypb.QueryIndexRequest, server indexgatewaypb.IndexGateway_QueryIndexServer) error {
+	if m.failQueries {
+		return errors.New("index gateway unavailable")
+	}
+
 	for i, query := range request.Queries {
 		resp := indexgatewaypb.QueryIndexResponse{
 			QueryKey: "",
@@ -81,7 +95,10 @@ func (m mockIndexGatewayServer) QueryIndex(request *indexgatewaypb.QueryIndexReq
 }
 
 func createTestGrpcServer(t *testing.T) (func(), string) {
-	var server mockIndexGatewayServer
+	return createTestGrpcServerWithMock(t, mockIndexGatewayServer{})
+}
+
+func createTestGrpcServerWithMock(t *testing.T, server mockIndexGatewayServer) (func(), string) {
 	lis, err := net.Listen("tcp", "localhost:0")
 	require.NoError(t, err)
 	s := grpc.NewServer()
@@ -112,6 +129,10 @@ func TestGatewayClient(t *testing.T) {
 
 	ctx := user.InjectOrgID(context.Background(), "fake")
 
+	queryGatewayClient(t, ctx, gatewayClient)
+}
+
+func queryGatewayClient(t *testing.T, ctx context.Context, gatewayClient *GatewayClient) {
 	queries := []chunk.IndexQuery{}
 	for i := 0; i < 10; i++ {
 		queries = append(queries, chunk.IndexQuery{
@@ -124,7 +145,7 @@ func TestGatewayClient(t *testing.T) {
 	}
 
 	numCallbacks := 0
-	err = gatewayClient.QueryPages(ctx, queries, func(query chunk.IndexQuery, batch chunk.ReadBatch) (shouldContinue bool) {
+	err := gatewayClient.QueryPages(ctx, queries, func(query chunk.IndexQuery, batch chunk.ReadBatch) (shouldContinue bool) {
 		itr := batch.Iterator()
 
 		for j := 0; j <= numCallbacks; j++ {
@@ -141,3 +162,48 @@ func TestGatewayClient(t *testing.T) {
 
 	require.Equal(t, len(queries), numCallbacks)
 }
+
+func TestGatewayClient_RingMode(t *testing.T) {
+	cleanupHealthy, healthyAddress := createTestGrpcServer(t)
+	defer cleanupHealthy()
+	cleanupFailing, failingAddress := createTestGrpcServerWithMock(t, mockIndexGatewayServer{failQueries: true})
+	defer cleanupFailing()
+
+	store, closer := consul.NewInMemoryClient(ring.GetCodec(), gokitlog.NewNopLogger(), nil)
+	defer closer.Close()
+	desc := ring.NewDesc()
+	desc.AddIngester("healthy", healthyAddress, "", []uint32{1}, ring.ACTIVE, time.Now())
+	desc.AddIngester("failing", failingAddress, "", []uint32{2}, ring.ACTIVE, time.Now())
+	require.NoError(t, store.CAS(context.Background(), "index-gateway", func(_ interface{}) (interface{}, bool, error) {
+		return desc, true, nil
+	}))
+
+	var ringCfg ring.Config
+	flagext.DefaultValues(&ringCfg)
+	ringCfg.KVStore = kv.Config{Mock: store}
+	ringCfg.ReplicationFactor = 2
+
+	gatewayRing, err := ring.New(ringCfg, "index-gateway", "index-gateway", gokitlog.NewNopLogger(), nil)
+	require.NoError(t, err)
+	require.NoError(t, services.StartAndAwaitRunning(context.Background(), gatewayRing))
+	defer services.StopAndAwaitTerminated(context.Background(), gatewayRing) //nolint:errcheck
+
+	test.Poll(t, time.Second, 2, func() interface{} {
+		return gatewayRing.InstancesCount()
+	})
+
+	var cfg IndexGatewayClientConfig
+	flagext.DefaultValues(&cfg)
+	cfg.Ring = gatewayRing
+
+	gatewayClient, err := NewGatewayClient(cfg, nil)
+	require.NoError(t, err)
+	defer gatewayClient.Stop()
+
+	ctx := user.InjectOrgID(context.Background(), "fake")
+
+	// the queries fail over to the healthy index gateway whichever replica is tried first.
+	for i := 0; i < 10; i++ {
+		queryGatewayClient(t, ctx, gatewayClient)
+	}
+}
@@ -0,0 +1,81 @@
+package indexgateway
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+
+	"github.com/grafana/loki/pkg/util"
+)
+
+// Mode is the way the index gateways are deployed.
+type Mode string
+
+const (
+	// SimpleMode is the mode where every index gateway serves the index of all the tenants,
+	// and the clients reach them through a single server address.
+	SimpleMode Mode = "simple"
+
+	// RingMode is the mode where the tenants are sharded across the index gateways of a ring,
+	// each of them only keeping the index of the tenants it owns.
+	RingMode Mode = "ring"
+)
+
+// Set implements flag.Value.
+func (m *Mode) Set(v string) error {
+	switch Mode(v) {
+	case SimpleMode, RingMode:
+		*m = Mode(v)
+		return nil
+	default:
+		return fmt.Errorf("mode %q not supported, supported modes: %s, %s", v, SimpleMode, RingMode)
+	}
+}
+
+// String implements flag.Value.
+func (m Mode) String() string {
+	return string(m)
+}
+
+// UnmarshalYAML implements yaml.Unmarshaler.
+func (m *Mode) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var v string
+	if err := unmarshal(&v); err != nil {
+		return err
+	}
+	return m.Set(v)
+}
+
+// RingCfg is the config of the ring the index gateways shard the tenants with.
+type RingCfg struct {
+	util.RingConfig `yaml:",inline"`
+
+	ReplicationFactor int `yaml:"replication_factor"`
+}
+
+// RegisterFlagsWithPrefix registers all the index gateway ring flags with the given prefix.
+func (cfg *RingCfg) RegisterFlagsWithPrefix(prefix, storePrefix string, f *flag.FlagSet) {
+	cfg.RingConfig.RegisterFlagsWithPrefix(prefix, storePrefix, f)
+	f.IntVar(&cfg.ReplicationFactor, prefix+"ring.replication-factor", 3, "Number of index gateways each tenant is assigned to. Queries fail over to the other gateways of the tenant when one of them is unavailable.")
+}
+
+// Config configures the index gateways.
+type Config struct {
+	Mode Mode    `yaml:"mode"`
+	Ring RingCfg `yaml:"ring,omitempty"`
+}
+
+// RegisterFlags registers the index gateway flags.
+func (cfg *Config) RegisterFlags(f *flag.FlagSet) {
+	cfg.Mode = SimpleMode
+	f.Var(&cfg.Mode, "index-gateway.mode", "Deployment mode of the index gateways. Supported values: simple, ring. In ring mode the tenants are sharded across the index gateways, which only keep the per tenant index of the tenants they own, and the clients route the queries of each tenant to one of its gateways.")
+	cfg.Ring.RegisterFlagsWithPrefix("index-gateway.", "collectors/", f)
+}
+
+// Validate validates the index gateway config.
+func (cfg *Config) Validate() error {
+	if cfg.Mode == RingMode && cfg.Ring.ReplicationFactor < 1 {
+		return errors.New("index gateway ring replication factor must be >= 1")
+	}
+	return nil
+}
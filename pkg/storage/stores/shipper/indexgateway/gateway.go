@@ -1,34 +1,62 @@
 package indexgateway
 
 import (
+	"context"
+	"errors"
+
 	"github.com/grafana/dskit/services"
+	"github.com/prometheus/common/model"
+	"github.com/weaveworks/common/user"
 
 	"github.com/grafana/loki/pkg/storage/chunk"
 	"github.com/grafana/loki/pkg/storage/stores/shipper"
 	"github.com/grafana/loki/pkg/storage/stores/shipper/indexgateway/indexgatewaypb"
 	"github.com/grafana/loki/pkg/storage/stores/shipper/util"
+	"github.com/grafana/loki/pkg/storage/stores/tsdb"
 )
 
 const maxIndexEntriesPerResponse = 1000
 
+var (
+	errBoltDBShipperNotRunning = errors.New("boltdb shipper is not running on the index gateway")
+	errTSDBShipperNotRunning   = errors.New("tsdb shipper is not running on the index gateway")
+)
+
 type gateway struct {
 	services.Service
 
 	shipper chunk.IndexClient
+	tsdb    tsdb.IndexClient
 }
 
-func NewIndexGateway(shipperIndexClient *shipper.Shipper) *gateway {
-	g := &gateway{
-		shipper: shipperIndexClient,
+// NewIndexGateway creates an Index Gateway serving queries from the given shippers.
+// Either of them can be nil when the corresponding index type is not in use.
+func NewIndexGateway(shipperIndexClient *shipper.Shipper, tsdbIndexClient *tsdb.Shipper) *gateway {
+	g := &gateway{}
+	// avoid storing typed nil pointers in the interfaces.
+	if shipperIndexClient != nil {
+		g.shipper = shipperIndexClient
+	}
+	if tsdbIndexClient != nil {
+		g.tsdb = tsdbIndexClient
 	}
 	g.Service = services.NewIdleService(nil, func(failureCase error) error {
-		g.shipper.Stop()
+		if g.shipper != nil {
+			g.shipper.Stop()
+		}
+		if g.tsdb != nil {
+			g.tsdb.Stop()
+		}
 		return nil
 	})
 	return g
 }
 
 func (g gateway) QueryIndex(request *indexgatewaypb.QueryIndexRequest, server indexgatewaypb.IndexGateway_QueryIndexServer) error {
+	if g.shipper == nil {
+		return errBoltDBShipperNotRunning
+	}
+
 	var outerErr error
 	var innerErr error
 
@@ -92,3 +120,82 @@ func (g *gateway) sendBatch(server indexgatewaypb.IndexGateway_QueryIndexServer,
 
 	return nil
 }
+
+func (g *gateway) GetChunkRef(ctx context.Context, req *indexgatewaypb.GetChunkRefRequest) (*indexgatewaypb.GetChunkRefResponse, error) {
+	if g.tsdb == nil {
+		return nil, errTSDBShipperNotRunning
+	}
+
+	userID, err := user.ExtractOrgID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	matchers, err := tsdb.ParseMatchers(req.Matchers)
+	if err != nil {
+		return nil, err
+	}
+
+	refs, err := g.tsdb.GetChunkRefs(ctx, userID, req.TableNames, model.Time(req.From), model.Time(req.Through), matchers...)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &indexgatewaypb.GetChunkRefResponse{
+		Refs: make([]*indexgatewaypb.ChunkRef, 0, len(refs)),
+	}
+	for _, ref := range refs {
+		resp.Refs = append(resp.Refs, &indexgatewaypb.ChunkRef{
+			Fingerprint: uint64(ref.Fingerprint),
+			From:        int64(ref.Start),
+			Through:     int64(ref.End),
+			Checksum:    ref.Checksum,
+			Kb:          ref.KB,
+		})
+	}
+	return resp, nil
+}
+
+func (g *gateway) LabelNames(ctx context.Context, req *indexgatewaypb.LabelNamesRequest) (*indexgatewaypb.LabelResponse, error) {
+	if g.tsdb == nil {
+		return nil, errTSDBShipperNotRunning
+	}
+
+	userID, err := user.ExtractOrgID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	matchers, err := tsdb.ParseMatchers(req.Matchers)
+	if err != nil {
+		return nil, err
+	}
+
+	names, err := g.tsdb.LabelNames(ctx, userID, req.TableNames, model.Time(req.From), model.Time(req.Through), matchers...)
+	if err != nil {
+		return nil, err
+	}
+	return &indexgatewaypb.LabelResponse{Values: names}, nil
+}
+
+func (g *gateway) LabelValues(ctx context.Context, req *indexgatewaypb.LabelValuesRequest) (*indexgatewaypb.LabelResponse, error) {
+	if g.tsdb == nil {
+		return nil, errTSDBShipperNotRunning
+	}
+
+	userID, err := user.ExtractOrgID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	matchers, err := tsdb.ParseMatchers(req.Matchers)
+	if err != nil {
+		return nil, err
+	}
+
+	values, err := g.tsdb.LabelValues(ctx, userID, req.TableNames, model.Time(req.From), model.Time(req.Through), req.Name, matchers...)
+	if err != nil {
+		return nil, err
+	}
+	return &indexgatewaypb.LabelResponse{Values: values}, nil
+}
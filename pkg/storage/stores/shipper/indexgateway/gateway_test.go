@@ -1,15 +1,24 @@
 package indexgateway
 
 import (
+	"context"
 	"fmt"
+	"net"
 	"testing"
 
+	"github.com/grafana/dskit/flagext"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/model/labels"
 	"github.com/stretchr/testify/require"
+	"github.com/weaveworks/common/middleware"
 	"google.golang.org/grpc"
 
 	"github.com/grafana/loki/pkg/storage/chunk"
+	"github.com/grafana/loki/pkg/storage/stores/shipper"
 	"github.com/grafana/loki/pkg/storage/stores/shipper/indexgateway/indexgatewaypb"
 	"github.com/grafana/loki/pkg/storage/stores/shipper/util"
+	"github.com/grafana/loki/pkg/storage/stores/tsdb"
 	util_math "github.com/grafana/loki/pkg/util/math"
 )
 
@@ -117,3 +126,82 @@ func TestGateway_sendBatch(t *testing.T) {
 		require.Len(t, expectedRanges, 0)
 	}
 }
+
+type mockTSDBIndexClient struct {
+	userID        string
+	tableNames    []string
+	from, through model.Time
+	name          string
+	matchers      []*labels.Matcher
+}
+
+func (m *mockTSDBIndexClient) record(userID string, tableNames []string, from, through model.Time, name string, matchers []*labels.Matcher) {
+	m.userID, m.tableNames, m.from, m.through, m.name, m.matchers = userID, tableNames, from, through, name, matchers
+}
+
+func (m *mockTSDBIndexClient) GetChunkRefs(_ context.Context, userID string, tableNames []string, from, through model.Time, matchers ...*labels.Matcher) ([]tsdb.ChunkRef, error) {
+	m.record(userID, tableNames, from, through, "", matchers)
+	return []tsdb.ChunkRef{
+		{User: userID, Fingerprint: 1, Start: from, End: through, Checksum: 2, KB: 3},
+	}, nil
+}
+
+func (m *mockTSDBIndexClient) LabelNames(_ context.Context, userID string, tableNames []string, from, through model.Time, matchers ...*labels.Matcher) ([]string, error) {
+	m.record(userID, tableNames, from, through, "", matchers)
+	return []string{"app", "env"}, nil
+}
+
+func (m *mockTSDBIndexClient) LabelValues(_ context.Context, userID string, tableNames []string, from, through model.Time, name string, matchers ...*labels.Matcher) ([]string, error) {
+	m.record(userID, tableNames, from, through, name, matchers)
+	return []string{"bar", "foo"}, nil
+}
+
+func (m *mockTSDBIndexClient) Stop() {}
+
+func TestGateway_TSDB(t *testing.T) {
+	index := &mockTSDBIndexClient{}
+
+	lis, err := net.Listen("tcp", "localhost:0")
+	require.NoError(t, err)
+	s := grpc.NewServer(grpc.UnaryInterceptor(middleware.ServerUserHeaderInterceptor))
+	indexgatewaypb.RegisterIndexGatewayServer(s, &gateway{tsdb: index})
+	go func() {
+		_ = s.Serve(lis)
+	}()
+	defer s.GracefulStop()
+
+	var cfg shipper.IndexGatewayClientConfig
+	flagext.DefaultValues(&cfg)
+	cfg.Address = lis.Addr().String()
+
+	client, err := tsdb.NewGatewayClient(cfg, prometheus.NewRegistry())
+	require.NoError(t, err)
+	defer client.Stop()
+
+	ctx := context.Background()
+	tableNames := []string{"index_1", "index_2"}
+	matchers := []*labels.Matcher{
+		labels.MustNewMatcher(labels.MatchEqual, "app", "foo"),
+		labels.MustNewMatcher(labels.MatchRegexp, "env", "dev|prod"),
+	}
+
+	refs, err := client.GetChunkRefs(ctx, "fake", tableNames, 10, 20, matchers...)
+	require.NoError(t, err)
+	require.Equal(t, []tsdb.ChunkRef{{User: "fake", Fingerprint: 1, Start: 10, End: 20, Checksum: 2, KB: 3}}, refs)
+	require.Equal(t, &mockTSDBIndexClient{userID: "fake", tableNames: tableNames, from: 10, through: 20, matchers: matchers}, index)
+
+	// no matchers are sent as an empty selector.
+	names, err := client.LabelNames(ctx, "fake", tableNames, 10, 20)
+	require.NoError(t, err)
+	require.Equal(t, []string{"app", "env"}, names)
+	require.Equal(t, &mockTSDBIndexClient{userID: "fake", tableNames: tableNames, from: 10, through: 20}, index)
+
+	values, err := client.LabelValues(ctx, "fake", tableNames, 10, 20, "app", matchers[1])
+	require.NoError(t, err)
+	require.Equal(t, []string{"bar", "foo"}, values)
+	require.Equal(t, &mockTSDBIndexClient{userID: "fake", tableNames: tableNames, from: 10, through: 20, name: "app", matchers: matchers[1:]}, index)
+
+	// queries for an index type not served by the gateway fail.
+	_, err = (&gateway{}).GetChunkRef(ctx, &indexgatewaypb.GetChunkRefRequest{})
+	require.Equal(t, errTSDBShipperNotRunning, err)
+}
@@ -0,0 +1,171 @@
+package indexgateway
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/grafana/dskit/kv"
+	"github.com/grafana/dskit/ring"
+	"github.com/grafana/dskit/services"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/grafana/loki/pkg/storage/stores/shipper"
+)
+
+const (
+	// ringKey is the key under which we store the index gateways ring in the KVStore.
+	ringKey = "index-gateway"
+
+	// ringName is the name of the ring used by the index gateways.
+	ringName = "index-gateway"
+
+	// ringNumTokens is the number of tokens of each index gateway, enough to spread the tenants evenly.
+	ringNumTokens = 128
+
+	// ringAutoForgetUnhealthyPeriods is how many consecutive timeout periods an unhealthy instance
+	// in the ring will be automatically removed.
+	ringAutoForgetUnhealthyPeriods = 10
+)
+
+// ManagerMode is the way a RingManager takes part in the ring.
+type ManagerMode int
+
+const (
+	// ClientMode only watches the ring, to route the queries to the index gateways.
+	ClientMode ManagerMode = iota
+	// ServerMode also registers this instance in the ring, as an index gateway.
+	ServerMode
+)
+
+// RingManager manages the ring of the index gateways when they run in ring mode.
+// Index gateways register themselves in it while their clients only watch it.
+type RingManager struct {
+	services.Service
+
+	mode   ManagerMode
+	logger log.Logger
+
+	Ring           *ring.Ring
+	ringLifecycler *ring.BasicLifecycler
+
+	subservices        *services.Manager
+	subservicesWatcher *services.FailureWatcher
+}
+
+// NewRingManager creates the RingManager of the index gateways ring.
+func NewRingManager(mode ManagerMode, cfg Config, logger log.Logger, r prometheus.Registerer) (*RingManager, error) {
+	rm := &RingManager{
+		mode:   mode,
+		logger: logger,
+	}
+
+	ringStore, err := kv.NewClient(
+		cfg.Ring.KVStore,
+		ring.GetCodec(),
+		kv.RegistererWithKVName(prometheus.WrapRegistererWithPrefix("loki_", r), "index-gateway"),
+		logger,
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, "create KV store client")
+	}
+
+	rm.Ring, err = ring.NewWithStoreClientAndStrategy(cfg.Ring.ToRingConfig(cfg.Ring.ReplicationFactor), ringName, ringKey, ringStore, ring.NewIgnoreUnhealthyInstancesReplicationStrategy(), prometheus.WrapRegistererWithPrefix("cortex_", r), logger)
+	if err != nil {
+		return nil, errors.Wrap(err, "create ring client")
+	}
+
+	subservices := []services.Service{rm.Ring}
+	if mode == ServerMode {
+		lifecyclerCfg, err := cfg.Ring.ToLifecyclerConfig(ringNumTokens, logger)
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid ring lifecycler config")
+		}
+
+		// Define lifecycler delegates in reverse order (last to be called defined first because they're
+		// chained via "next delegate").
+		delegate := ring.BasicLifecyclerDelegate(rm)
+		delegate = ring.NewLeaveOnStoppingDelegate(delegate, logger)
+		delegate = ring.NewTokensPersistencyDelegate(cfg.Ring.TokensFilePath, ring.ACTIVE, delegate, logger)
+		delegate = ring.NewAutoForgetDelegate(ringAutoForgetUnhealthyPeriods*cfg.Ring.HeartbeatTimeout, delegate, logger)
+
+		rm.ringLifecycler, err = ring.NewBasicLifecycler(lifecyclerCfg, ringName, ringKey, ringStore, delegate, logger, r)
+		if err != nil {
+			return nil, errors.Wrap(err, "create ring lifecycler")
+		}
+		subservices = append(subservices, rm.ringLifecycler)
+	}
+
+	rm.subservices, err = services.NewManager(subservices...)
+	if err != nil {
+		return nil, err
+	}
+	rm.subservicesWatcher = services.NewFailureWatcher()
+	rm.subservicesWatcher.WatchManager(rm.subservices)
+
+	rm.Service = services.NewBasicService(rm.starting, rm.running, rm.stopping)
+	return rm, nil
+}
+
+func (rm *RingManager) starting(ctx context.Context) error {
+	return services.StartManagerAndAwaitHealthy(ctx, rm.subservices)
+}
+
+func (rm *RingManager) running(ctx context.Context) error {
+	select {
+	case <-ctx.Done():
+		return nil
+	case err := <-rm.subservicesWatcher.Chan():
+		return errors.Wrap(err, "index gateway ring subservice failed")
+	}
+}
+
+func (rm *RingManager) stopping(_ error) error {
+	return services.StopManagerAndAwaitStopped(context.Background(), rm.subservices)
+}
+
+// OwnsTenant returns whether this index gateway is one of the gateways owning the tenant.
+// Tenants are assumed to be owned until the ring can tell otherwise, to avoid dropping their index on ring errors.
+func (rm *RingManager) OwnsTenant(tenantID string) bool {
+	if rm.ringLifecycler == nil {
+		return false
+	}
+
+	bufDescs, bufHosts, bufZones := ring.MakeBuffersForGet()
+	rs, err := rm.Ring.Get(shipper.IndexGatewayTenantToken(tenantID), shipper.IndexGatewayRingOp, bufDescs, bufHosts, bufZones)
+	if err != nil {
+		level.Warn(rm.logger).Log("msg", "failed to check the ownership of a tenant, keeping its index", "tenant", tenantID, "err", err)
+		return true
+	}
+	return rs.Includes(rm.ringLifecycler.GetInstanceAddr())
+}
+
+// ServeHTTP serves the status page of the ring.
+func (rm *RingManager) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	rm.Ring.ServeHTTP(w, req)
+}
+
+// OnRingInstanceRegister implements ring.BasicLifecyclerDelegate.
+func (rm *RingManager) OnRingInstanceRegister(_ *ring.BasicLifecycler, ringDesc ring.Desc, instanceExists bool, _ string, instanceDesc ring.InstanceDesc) (ring.InstanceState, ring.Tokens) {
+	// Index gateways download the index they are queried for on demand so, whatever is the state,
+	// we set it ACTIVE right away, while we keep existing tokens (if any).
+	var tokens []uint32
+	if instanceExists {
+		tokens = instanceDesc.GetTokens()
+	}
+
+	takenTokens := ringDesc.GetTokens()
+	newTokens := ring.GenerateTokens(ringNumTokens-len(tokens), takenTokens)
+
+	// Tokens sorting will be enforced by the parent caller.
+	tokens = append(tokens, newTokens...)
+
+	return ring.ACTIVE, tokens
+}
+
+func (rm *RingManager) OnRingInstanceTokens(_ *ring.BasicLifecycler, _ ring.Tokens) {}
+func (rm *RingManager) OnRingInstanceStopping(_ *ring.BasicLifecycler)              {}
+func (rm *RingManager) OnRingInstanceHeartbeat(_ *ring.BasicLifecycler, _ *ring.Desc, _ *ring.InstanceDesc) {
+}
@@ -66,6 +66,8 @@ type Config struct {
 	IngesterName             string                   `yaml:"-"`
 	Mode                     int                      `yaml:"-"`
 	IngesterDBRetainPeriod   time.Duration            `yaml:"-"`
+	// OwnsTenant is set when the index gateways run in ring mode, to only keep the index of the owned tenants.
+	OwnsTenant func(tenantID string) bool `yaml:"-"`
 }
 
 // RegisterFlags registers flags.
@@ -156,6 +158,7 @@ func (s *Shipper) init(storageClient chunk.ObjectClient, registerer prometheus.R
 			SyncInterval:      s.cfg.ResyncInterval,
 			CacheTTL:          s.cfg.CacheTTL,
 			QueryReadyNumDays: s.cfg.QueryReadyNumDays,
+			OwnsTenant:        s.cfg.OwnsTenant,
 		}
 		downloadsManager, err := downloads.NewTableManager(cfg, s.boltDBIndexClient, indexStorageClient, registerer)
 		if err != nil {
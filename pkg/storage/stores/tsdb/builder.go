@@ -0,0 +1,144 @@
+package tsdb
+
+import (
+	"context"
+	"os"
+	"sort"
+	"strconv"
+
+	"github.com/pkg/errors"
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/storage"
+	"github.com/prometheus/prometheus/tsdb/chunks"
+	"github.com/prometheus/prometheus/tsdb/index"
+)
+
+// fingerprintLabel is a reserved label only written for series whose fingerprint is not the hash of their labels,
+// which happens when the ingester had to remap a colliding fingerprint. It is never returned by queries.
+const fingerprintLabel = "__tsdb_fingerprint__"
+
+// Builder collects series and their chunks in memory and writes them out as a single TSDB index file.
+type Builder struct {
+	streams map[string]*stream
+}
+
+type stream struct {
+	labels labels.Labels
+	fp     model.Fingerprint
+	chunks ChunkMetas
+}
+
+func NewBuilder() *Builder {
+	return &Builder{streams: map[string]*stream{}}
+}
+
+// AddSeries adds chunks of the series identified by ls and fp to the index being built.
+func (b *Builder) AddSeries(ls labels.Labels, fp model.Fingerprint, chks []ChunkMeta) {
+	stored := toStoredLabels(ls, fp)
+	key := stored.String()
+
+	s, ok := b.streams[key]
+	if !ok {
+		s = &stream{labels: stored, fp: fp}
+		b.streams[key] = s
+	}
+	s.chunks = append(s.chunks, chks...)
+}
+
+// Empty returns true if no series have been added to the Builder.
+func (b *Builder) Empty() bool {
+	return len(b.streams) == 0
+}
+
+// Build writes the index to the given path. The file is first written to a temporary path and then renamed so that
+// a partially written index is never left at path.
+func (b *Builder) Build(ctx context.Context, path string) (err error) {
+	tmpPath := path + ".building"
+	defer func() {
+		if err != nil {
+			_ = os.Remove(tmpPath)
+		}
+	}()
+
+	writer, err := index.NewWriter(ctx, tmpPath)
+	if err != nil {
+		return err
+	}
+
+	streams := make([]*stream, 0, len(b.streams))
+	symbolsMap := map[string]struct{}{}
+	for _, s := range b.streams {
+		streams = append(streams, s)
+		for _, l := range s.labels {
+			symbolsMap[l.Name] = struct{}{}
+			symbolsMap[l.Value] = struct{}{}
+		}
+	}
+
+	symbols := make([]string, 0, len(symbolsMap))
+	for s := range symbolsMap {
+		symbols = append(symbols, s)
+	}
+	sort.Strings(symbols)
+
+	for _, s := range symbols {
+		if err := writer.AddSymbol(s); err != nil {
+			_ = writer.Close()
+			return err
+		}
+	}
+
+	sort.Slice(streams, func(i, j int) bool {
+		return labels.Compare(streams[i].labels, streams[j].labels) < 0
+	})
+
+	for i, s := range streams {
+		s.chunks = s.chunks.finalize()
+		metas := make([]chunks.Meta, 0, len(s.chunks))
+		for _, chk := range s.chunks {
+			metas = append(metas, chk.toPromMeta())
+		}
+
+		if err := writer.AddSeries(storage.SeriesRef(i), s.labels, metas...); err != nil {
+			_ = writer.Close()
+			return errors.Wrapf(err, "adding series %s", s.labels)
+		}
+	}
+
+	if err := writer.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmpPath, path)
+}
+
+// toStoredLabels returns the labels as they are written to the index: without the metric name,
+// and with the reserved fingerprint label when the fingerprint can't be derived from the labels.
+func toStoredLabels(ls labels.Labels, fp model.Fingerprint) labels.Labels {
+	b := labels.NewBuilder(ls)
+	b.Del(labels.MetricName)
+	b.Del(fingerprintLabel)
+	stored := b.Labels()
+
+	if hash, _ := stored.HashWithoutLabels(nil); model.Fingerprint(hash) != fp {
+		b.Set(fingerprintLabel, strconv.FormatUint(uint64(fp), 16))
+		stored = b.Labels()
+	}
+	return stored
+}
+
+// fromStoredLabels is the inverse of toStoredLabels, it returns the labels without the reserved fingerprint
+// label along with the fingerprint of the series.
+func fromStoredLabels(stored labels.Labels) (labels.Labels, model.Fingerprint, error) {
+	if v := stored.Get(fingerprintLabel); v != "" {
+		fp, err := strconv.ParseUint(v, 16, 64)
+		if err != nil {
+			return nil, 0, errors.Wrapf(err, "invalid fingerprint in series %s", stored)
+		}
+		return labels.NewBuilder(stored).Del(fingerprintLabel).Labels(), model.Fingerprint(fp), nil
+	}
+
+	hash, _ := stored.HashWithoutLabels(nil)
+	return stored, model.Fingerprint(hash), nil
+}
@@ -0,0 +1,157 @@
+package tsdb
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/loki/pkg/querier/astmapper"
+)
+
+type series struct {
+	labels labels.Labels
+	fp     model.Fingerprint
+	chunks []ChunkMeta
+}
+
+func fingerprint(ls labels.Labels) model.Fingerprint {
+	hash, _ := labels.NewBuilder(ls).Del(labels.MetricName).Labels().HashWithoutLabels(nil)
+	return model.Fingerprint(hash)
+}
+
+func collectSeries(t *testing.T, idx Index, shard *astmapper.ShardAnnotation, from, through model.Time, matchers ...*labels.Matcher) []series {
+	var res []series
+	err := idx.forSeries(context.Background(), shard, from, through, func(ls labels.Labels, fp model.Fingerprint, chks []ChunkMeta) {
+		res = append(res, series{
+			labels: ls.Copy(),
+			fp:     fp,
+			chunks: append([]ChunkMeta(nil), chks...),
+		})
+	}, matchers...)
+	require.NoError(t, err)
+	return res
+}
+
+func buildIndex(t *testing.T, path string, in ...series) *TSDBIndex {
+	b := NewBuilder()
+	for _, s := range in {
+		b.AddSeries(s.labels, s.fp, s.chunks)
+	}
+	require.NoError(t, b.Build(context.Background(), path))
+
+	idx, err := LoadTSDBIndex(path)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		require.NoError(t, idx.Close())
+	})
+	return idx
+}
+
+func TestBuilder_Build(t *testing.T) {
+	foo := labels.FromStrings("__name__", "logs", "app", "foo")
+	bar := labels.FromStrings("app", "bar", "env", "prod")
+	// the fingerprint of a series which collided with another one in the ingester does not match its labels.
+	collided := labels.FromStrings("app", "baz")
+
+	idx := buildIndex(t, filepath.Join(t.TempDir(), "index.tsdb"),
+		series{labels: foo, fp: fingerprint(foo), chunks: []ChunkMeta{
+			{Checksum: 2, MinTime: 20, MaxTime: 30, KB: 1},
+			{Checksum: 1, MinTime: 0, MaxTime: 10, KB: 2},
+		}},
+		series{labels: bar, fp: fingerprint(bar), chunks: []ChunkMeta{
+			{Checksum: 3, MinTime: 5, MaxTime: 15, KB: 3},
+		}},
+		// chunks of the same series added again are deduplicated.
+		series{labels: bar, fp: fingerprint(bar), chunks: []ChunkMeta{
+			{Checksum: 3, MinTime: 5, MaxTime: 15, KB: 3},
+		}},
+		series{labels: collided, fp: 42, chunks: []ChunkMeta{
+			{Checksum: 4, MinTime: 40, MaxTime: 50, KB: 4},
+		}},
+	)
+
+	for _, tc := range []struct {
+		name          string
+		from, through model.Time
+		matchers      []*labels.Matcher
+		expected      []series
+	}{
+		{
+			name:    "all series",
+			from:    model.Earliest,
+			through: model.Latest,
+			expected: []series{
+				{labels: bar, fp: fingerprint(bar), chunks: []ChunkMeta{{Checksum: 3, MinTime: 5, MaxTime: 15, KB: 3}}},
+				{labels: collided, fp: 42, chunks: []ChunkMeta{{Checksum: 4, MinTime: 40, MaxTime: 50, KB: 4}}},
+				{labels: labels.FromStrings("app", "foo"), fp: fingerprint(foo), chunks: []ChunkMeta{
+					{Checksum: 1, MinTime: 0, MaxTime: 10, KB: 2},
+					{Checksum: 2, MinTime: 20, MaxTime: 30, KB: 1},
+				}},
+			},
+		},
+		{
+			name:     "matchers",
+			from:     model.Earliest,
+			through:  model.Latest,
+			matchers: []*labels.Matcher{labels.MustNewMatcher(labels.MatchRegexp, "app", "foo|baz")},
+			expected: []series{
+				{labels: collided, fp: 42, chunks: []ChunkMeta{{Checksum: 4, MinTime: 40, MaxTime: 50, KB: 4}}},
+				{labels: labels.FromStrings("app", "foo"), fp: fingerprint(foo), chunks: []ChunkMeta{
+					{Checksum: 1, MinTime: 0, MaxTime: 10, KB: 2},
+					{Checksum: 2, MinTime: 20, MaxTime: 30, KB: 1},
+				}},
+			},
+		},
+		{
+			name:    "time range",
+			from:    12,
+			through: 25,
+			expected: []series{
+				{labels: bar, fp: fingerprint(bar), chunks: []ChunkMeta{{Checksum: 3, MinTime: 5, MaxTime: 15, KB: 3}}},
+				{labels: labels.FromStrings("app", "foo"), fp: fingerprint(foo), chunks: []ChunkMeta{
+					{Checksum: 2, MinTime: 20, MaxTime: 30, KB: 1},
+				}},
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			require.ElementsMatch(t, tc.expected, collectSeries(t, idx, nil, tc.from, tc.through, tc.matchers...))
+		})
+	}
+
+	t.Run("shards", func(t *testing.T) {
+		var all []series
+		for i := 0; i < 4; i++ {
+			shard := &astmapper.ShardAnnotation{Shard: i, Of: 4}
+			for _, s := range collectSeries(t, idx, shard, model.Earliest, model.Latest) {
+				require.Equal(t, uint32(i), seriesShardHash(s.labels)%4)
+				all = append(all, s)
+			}
+		}
+		require.Len(t, all, 3)
+	})
+}
+
+func TestStoredLabels(t *testing.T) {
+	ls := labels.FromStrings("__name__", "logs", "app", "foo")
+
+	stored := toStoredLabels(ls, fingerprint(ls))
+	require.Equal(t, labels.FromStrings("app", "foo"), stored)
+
+	restored, fp, err := fromStoredLabels(stored)
+	require.NoError(t, err)
+	require.Equal(t, labels.FromStrings("app", "foo"), restored)
+	require.Equal(t, fingerprint(ls), fp)
+
+	stored = toStoredLabels(ls, 42)
+	require.Equal(t, "2a", stored.Get(fingerprintLabel))
+
+	restored, fp, err = fromStoredLabels(stored)
+	require.NoError(t, err)
+	require.Equal(t, labels.FromStrings("app", "foo"), restored)
+	require.Equal(t, model.Fingerprint(42), fp)
+}
@@ -0,0 +1,89 @@
+package tsdb
+
+import (
+	"sort"
+
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/tsdb/chunks"
+)
+
+// ChunkRef identifies a chunk by everything that is needed to build its object store key,
+// along with its size so that queries can be planned without fetching the chunk.
+type ChunkRef struct {
+	User        string
+	Fingerprint model.Fingerprint
+	Start, End  model.Time
+	Checksum    uint32
+	// KB is the encoded size of the chunk in kilobytes, rounded up.
+	KB uint32
+}
+
+// ChunkMeta is the per-chunk metadata stored with each series in a TSDB index.
+type ChunkMeta struct {
+	Checksum         uint32
+	MinTime, MaxTime int64
+	KB               uint32
+}
+
+// newChunkMeta converts a Prometheus chunk meta read from an index back into a ChunkMeta.
+// The checksum and size are packed into the chunk reference, which is otherwise unused by us.
+func newChunkMeta(m chunks.Meta) ChunkMeta {
+	return ChunkMeta{
+		Checksum: uint32(m.Ref >> 32),
+		KB:       uint32(m.Ref),
+		MinTime:  m.MinTime,
+		MaxTime:  m.MaxTime,
+	}
+}
+
+func (c ChunkMeta) toPromMeta() chunks.Meta {
+	return chunks.Meta{
+		Ref:     chunks.ChunkRef(uint64(c.Checksum)<<32 | uint64(c.KB)),
+		MinTime: c.MinTime,
+		MaxTime: c.MaxTime,
+	}
+}
+
+func (c ChunkMeta) overlaps(from, through model.Time) bool {
+	return c.MinTime <= int64(through) && int64(from) <= c.MaxTime
+}
+
+// kbFromBytes returns the size in kilobytes, rounded up, of n bytes.
+func kbFromBytes(n int) uint32 {
+	return uint32((n + 1023) / 1024)
+}
+
+// ChunkMetas is a sortable list of ChunkMeta.
+type ChunkMetas []ChunkMeta
+
+func (c ChunkMetas) Len() int      { return len(c) }
+func (c ChunkMetas) Swap(i, j int) { c[i], c[j] = c[j], c[i] }
+func (c ChunkMetas) Less(i, j int) bool {
+	a, b := c[i], c[j]
+	if a.MinTime != b.MinTime {
+		return a.MinTime < b.MinTime
+	}
+	if a.MaxTime != b.MaxTime {
+		return a.MaxTime < b.MaxTime
+	}
+	return a.Checksum < b.Checksum
+}
+
+// finalize sorts the chunks and removes duplicates, which are common since every replica
+// flushing a chunk adds it to its own index.
+func (c ChunkMetas) finalize() ChunkMetas {
+	sort.Sort(c)
+	if len(c) < 2 {
+		return c
+	}
+
+	res := c[:1]
+	for _, m := range c[1:] {
+		last := res[len(res)-1]
+		if m.MinTime == last.MinTime && m.MaxTime == last.MaxTime && m.Checksum == last.Checksum {
+			continue
+		}
+		res = append(res, m)
+	}
+	return res
+}
@@ -0,0 +1,131 @@
+package tsdb
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/go-kit/log/level"
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/model/labels"
+
+	chunk_util "github.com/grafana/loki/pkg/storage/chunk/util"
+	"github.com/grafana/loki/pkg/storage/stores/shipper/storage"
+	util_log "github.com/grafana/loki/pkg/util/log"
+)
+
+// CompactTable merges the index files of each tenant in the table into a single one, which replaces them in the storage.
+func CompactTable(ctx context.Context, storageClient storage.Client, tableName, workingDir string) error {
+	_, users, err := storageClient.ListFiles(ctx, tableName)
+	if err != nil {
+		return err
+	}
+
+	tableDir := filepath.Join(workingDir, tableName)
+	defer func() {
+		if err := os.RemoveAll(tableDir); err != nil {
+			level.Error(util_log.Logger).Log("msg", "failed to remove tsdb compaction working directory", "path", tableDir, "err", err)
+		}
+	}()
+
+	for _, userID := range users {
+		if err := compactUserIndex(ctx, storageClient, tableName, userID, filepath.Join(tableDir, userID)); err != nil {
+			return fmt.Errorf("failed to compact index files of user %s in table %s: %w", userID, tableName, err)
+		}
+	}
+
+	return nil
+}
+
+func compactUserIndex(ctx context.Context, storageClient storage.Client, tableName, userID, dir string) error {
+	files, err := storageClient.ListUserFiles(ctx, tableName, userID)
+	if err != nil {
+		return err
+	}
+
+	var names []string
+	for _, file := range files {
+		if strings.HasSuffix(file.Name, indexFileSuffix) {
+			names = append(names, file.Name)
+		}
+	}
+	if len(names) < 2 {
+		return nil
+	}
+
+	if err := chunk_util.EnsureDirectory(dir); err != nil {
+		return err
+	}
+
+	level.Info(util_log.Logger).Log("msg", "compacting tsdb index files", "table", tableName, "user", userID, "files", len(names))
+
+	builder := NewBuilder()
+	var compacted []string
+	for _, name := range names {
+		idx, err := downloadIndex(ctx, storageClient, tableName, userID, name, dir)
+		if err != nil {
+			if storageClient.IsFileNotFoundErr(err) {
+				// the file was replaced by the ingester which uploaded it since we listed it, the new one will be compacted next time.
+				continue
+			}
+			return err
+		}
+
+		err = idx.forSeries(ctx, nil, model.Earliest, model.Latest, func(ls labels.Labels, fp model.Fingerprint, chks []ChunkMeta) {
+			builder.AddSeries(ls.Copy(), fp, chks)
+		})
+		if closeErr := idx.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			return err
+		}
+		compacted = append(compacted, name)
+	}
+
+	if len(compacted) < 2 {
+		return nil
+	}
+
+	compactedName := fmt.Sprintf("compactor-%d%s", time.Now().UnixNano(), indexFileSuffix)
+	compactedPath := filepath.Join(dir, compactedName)
+	if err := builder.Build(ctx, compactedPath); err != nil {
+		return err
+	}
+
+	f, err := os.Open(compactedPath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if err := storageClient.PutUserFile(ctx, tableName, userID, compactedName, f); err != nil {
+		return err
+	}
+
+	for _, name := range compacted {
+		if err := storageClient.DeleteUserFile(ctx, tableName, userID, name); err != nil && !storageClient.IsFileNotFoundErr(err) {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func downloadIndex(ctx context.Context, storageClient storage.Client, tableName, userID, fileName, dir string) (*TSDBIndex, error) {
+	rc, err := storageClient.GetUserFile(ctx, tableName, userID, fileName)
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+
+	path := filepath.Join(dir, fileName)
+	if err := writeFile(path, rc); err != nil {
+		return nil, err
+	}
+
+	return LoadTSDBIndex(path)
+}
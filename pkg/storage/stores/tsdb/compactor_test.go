@@ -0,0 +1,73 @@
+package tsdb
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompactTable(t *testing.T) {
+	tempDir := t.TempDir()
+	storageClient := newStorageClient(t, filepath.Join(tempDir, "storage"))
+
+	foo := labels.FromStrings("app", "foo")
+	bar := labels.FromStrings("app", "bar")
+
+	put := func(userID, name string, in ...series) {
+		path := filepath.Join(tempDir, fmt.Sprintf("%s-%s", userID, name))
+		b := NewBuilder()
+		for _, s := range in {
+			b.AddSeries(s.labels, s.fp, s.chunks)
+		}
+		require.NoError(t, b.Build(context.Background(), path))
+
+		f, err := os.Open(path)
+		require.NoError(t, err)
+		defer f.Close()
+		require.NoError(t, storageClient.PutUserFile(context.Background(), tableName, userID, name, f))
+	}
+
+	put("user1", "ingester-1-1.tsdb",
+		series{labels: foo, fp: fingerprint(foo), chunks: []ChunkMeta{{Checksum: 1, MinTime: 0, MaxTime: 10, KB: 1}}},
+	)
+	put("user1", "ingester-2-1.tsdb",
+		series{labels: foo, fp: fingerprint(foo), chunks: []ChunkMeta{
+			{Checksum: 1, MinTime: 0, MaxTime: 10, KB: 1},
+			{Checksum: 2, MinTime: 20, MaxTime: 30, KB: 1},
+		}},
+		series{labels: bar, fp: fingerprint(bar), chunks: []ChunkMeta{{Checksum: 3, MinTime: 5, MaxTime: 15, KB: 1}}},
+	)
+	// a single file is left as it is.
+	put("user2", "ingester-1-1.tsdb",
+		series{labels: bar, fp: fingerprint(bar), chunks: []ChunkMeta{{Checksum: 4, MinTime: 5, MaxTime: 15, KB: 1}}},
+	)
+
+	workingDir := filepath.Join(tempDir, "compactor")
+	require.NoError(t, CompactTable(context.Background(), storageClient, tableName, workingDir))
+
+	files, err := storageClient.ListUserFiles(context.Background(), tableName, "user1")
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+	require.True(t, strings.HasPrefix(files[0].Name, "compactor-"))
+
+	require.ElementsMatch(t, []series{
+		{labels: foo, fp: fingerprint(foo), chunks: []ChunkMeta{
+			{Checksum: 1, MinTime: 0, MaxTime: 10, KB: 1},
+			{Checksum: 2, MinTime: 20, MaxTime: 30, KB: 1},
+		}},
+		{labels: bar, fp: fingerprint(bar), chunks: []ChunkMeta{{Checksum: 3, MinTime: 5, MaxTime: 15, KB: 1}}},
+	}, uploadedSeries(t, storageClient, tableName, "user1"))
+
+	files, err = storageClient.ListUserFiles(context.Background(), tableName, "user2")
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+	require.Equal(t, "ingester-1-1.tsdb", files[0].Name)
+
+	require.NoDirExists(t, filepath.Join(workingDir, tableName))
+}
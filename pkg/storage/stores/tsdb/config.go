@@ -0,0 +1,48 @@
+package tsdb
+
+import (
+	"flag"
+	"time"
+
+	"github.com/grafana/loki/pkg/storage/stores/shipper"
+	shipper_util "github.com/grafana/loki/pkg/storage/stores/shipper/util"
+)
+
+const (
+	// TSDBType holds the index type for using per tenant TSDB index files which are shipped to a shared storage.
+	TSDBType = "tsdb"
+
+	// UploadInterval defines interval for when we check if there are new index files to build and upload.
+	UploadInterval = 1 * time.Minute
+
+	indexFileSuffix = ".tsdb"
+)
+
+type Config struct {
+	ActiveIndexDirectory     string                           `yaml:"active_index_directory"`
+	SharedStoreType          string                           `yaml:"shared_store"`
+	SharedStoreKeyPrefix     string                           `yaml:"shared_store_key_prefix"`
+	CacheLocation            string                           `yaml:"cache_location"`
+	CacheTTL                 time.Duration                    `yaml:"cache_ttl"`
+	ResyncInterval           time.Duration                    `yaml:"resync_interval"`
+	IndexGatewayClientConfig shipper.IndexGatewayClientConfig `yaml:"index_gateway_client"`
+	IngesterName             string                           `yaml:"-"`
+	Mode                     int                              `yaml:"-"`
+	IngesterHeadRetainPeriod time.Duration                    `yaml:"-"`
+}
+
+// RegisterFlags registers flags.
+func (cfg *Config) RegisterFlags(f *flag.FlagSet) {
+	cfg.IndexGatewayClientConfig.RegisterFlagsWithPrefix("tsdb.shipper.index-gateway-client", f)
+
+	f.StringVar(&cfg.ActiveIndexDirectory, "tsdb.shipper.active-index-directory", "", "Directory where ingesters would keep the TSDB heads which are periodically built into index files and uploaded by shipper to configured storage")
+	f.StringVar(&cfg.SharedStoreType, "tsdb.shipper.shared-store", "", "Shared store for keeping TSDB index files. Supported types: gcs, s3, azure, filesystem")
+	f.StringVar(&cfg.SharedStoreKeyPrefix, "tsdb.shipper.shared-store.key-prefix", "tsdb_index/", "Prefix to add to Object Keys in Shared store. Path separator(if any) should always be a '/'. Prefix should never start with a separator but should always end with it")
+	f.StringVar(&cfg.CacheLocation, "tsdb.shipper.cache-location", "", "Cache location for restoring TSDB index files for queries")
+	f.DurationVar(&cfg.CacheTTL, "tsdb.shipper.cache-ttl", 24*time.Hour, "TTL for TSDB index files restored in cache for queries")
+	f.DurationVar(&cfg.ResyncInterval, "tsdb.shipper.resync-interval", 5*time.Minute, "Resync downloaded files with the storage")
+}
+
+func (cfg *Config) Validate() error {
+	return shipper_util.ValidateSharedStoreKeyPrefix(cfg.SharedStoreKeyPrefix)
+}
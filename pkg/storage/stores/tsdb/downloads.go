@@ -0,0 +1,297 @@
+package tsdb
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-kit/log/level"
+
+	chunk_util "github.com/grafana/loki/pkg/storage/chunk/util"
+	"github.com/grafana/loki/pkg/storage/stores/shipper/storage"
+	"github.com/grafana/loki/pkg/util"
+	util_log "github.com/grafana/loki/pkg/util/log"
+)
+
+// downloadsManager keeps a local copy of the index files of the tables and tenants being queried, syncing them
+// with the storage every sync interval. Index files of a tenant for a table are dropped when not queried for the cache TTL.
+type downloadsManager struct {
+	cacheDir      string
+	syncInterval  time.Duration
+	cacheTTL      time.Duration
+	storageClient storage.Client
+	metrics       *metrics
+
+	indexSets    map[string]*indexSet
+	indexSetsMtx sync.RWMutex
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func newDownloadsManager(cacheDir string, syncInterval, cacheTTL time.Duration, storageClient storage.Client, metrics *metrics) (*downloadsManager, error) {
+	// index files are immutable and cheap to download again, so we do not bother reusing files from previous runs.
+	if err := os.RemoveAll(cacheDir); err != nil {
+		return nil, err
+	}
+	if err := chunk_util.EnsureDirectory(cacheDir); err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	dm := &downloadsManager{
+		cacheDir:      cacheDir,
+		syncInterval:  syncInterval,
+		cacheTTL:      cacheTTL,
+		storageClient: storageClient,
+		metrics:       metrics,
+		indexSets:     map[string]*indexSet{},
+		ctx:           ctx,
+		cancel:        cancel,
+	}
+
+	dm.wg.Add(1)
+	go dm.loop()
+	return dm, nil
+}
+
+func (dm *downloadsManager) loop() {
+	defer dm.wg.Done()
+
+	ticker := time.NewTicker(dm.syncInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			dm.syncIndexSets(dm.ctx)
+			dm.cleanupCache()
+		case <-dm.ctx.Done():
+			return
+		}
+	}
+}
+
+func (dm *downloadsManager) Stop() {
+	dm.cancel()
+	dm.wg.Wait()
+
+	dm.indexSetsMtx.Lock()
+	defer dm.indexSetsMtx.Unlock()
+
+	for _, is := range dm.indexSets {
+		is.close()
+	}
+}
+
+// indices returns the downloaded indices of the given table and tenant, downloading them first if we do not have them yet.
+// The returned function must be called once the indices are not used anymore.
+func (dm *downloadsManager) indices(ctx context.Context, tableName, userID string) ([]Index, func(), error) {
+	is, err := dm.getOrCreateIndexSet(ctx, tableName, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	is.mtx.RLock()
+	is.lastUsedAt = time.Now()
+
+	indices := make([]Index, 0, len(is.indices))
+	for _, idx := range is.indices {
+		indices = append(indices, idx)
+	}
+	return indices, is.mtx.RUnlock, nil
+}
+
+func (dm *downloadsManager) getOrCreateIndexSet(ctx context.Context, tableName, userID string) (*indexSet, error) {
+	key := filepath.Join(tableName, userID)
+
+	dm.indexSetsMtx.RLock()
+	is, ok := dm.indexSets[key]
+	dm.indexSetsMtx.RUnlock()
+	if ok {
+		return is, nil
+	}
+
+	dm.indexSetsMtx.Lock()
+	is, ok = dm.indexSets[key]
+	if ok {
+		dm.indexSetsMtx.Unlock()
+		return is, nil
+	}
+
+	is = &indexSet{
+		tableName:     tableName,
+		userID:        userID,
+		dir:           filepath.Join(dm.cacheDir, tableName, userID),
+		storageClient: dm.storageClient,
+		indices:       map[string]*TSDBIndex{},
+		lastUsedAt:    time.Now(),
+	}
+	// hold the lock of the index set until it is synced for the first time so that concurrent queries wait for it.
+	is.mtx.Lock()
+	dm.indexSets[key] = is
+	dm.indexSetsMtx.Unlock()
+
+	err := is.syncLocked(ctx)
+	is.mtx.Unlock()
+	if err != nil {
+		dm.indexSetsMtx.Lock()
+		delete(dm.indexSets, key)
+		dm.indexSetsMtx.Unlock()
+		is.close()
+		return nil, err
+	}
+
+	return is, nil
+}
+
+func (dm *downloadsManager) syncIndexSets(ctx context.Context) {
+	dm.indexSetsMtx.RLock()
+	indexSets := make([]*indexSet, 0, len(dm.indexSets))
+	for _, is := range dm.indexSets {
+		indexSets = append(indexSets, is)
+	}
+	dm.indexSetsMtx.RUnlock()
+
+	status := statusSuccess
+	for _, is := range indexSets {
+		if err := is.sync(ctx); err != nil {
+			status = statusFailure
+			level.Error(util_log.Logger).Log("msg", "failed to sync tsdb index files", "table", is.tableName, "user", is.userID, "err", err)
+		}
+	}
+
+	dm.metrics.tablesSyncOperationTotal.WithLabelValues(status).Inc()
+}
+
+func (dm *downloadsManager) cleanupCache() {
+	dm.indexSetsMtx.Lock()
+	defer dm.indexSetsMtx.Unlock()
+
+	for key, is := range dm.indexSets {
+		is.mtx.Lock()
+		expired := time.Since(is.lastUsedAt) > dm.cacheTTL
+		is.mtx.Unlock()
+		if !expired {
+			continue
+		}
+
+		level.Info(util_log.Logger).Log("msg", "dropping tsdb index files past their cache ttl", "table", is.tableName, "user", is.userID)
+		is.close()
+		delete(dm.indexSets, key)
+	}
+}
+
+// indexSet holds the downloaded index files of a tenant for a table.
+type indexSet struct {
+	tableName     string
+	userID        string
+	dir           string
+	storageClient storage.Client
+
+	mtx        sync.RWMutex
+	indices    map[string]*TSDBIndex
+	lastUsedAt time.Time
+}
+
+func (is *indexSet) sync(ctx context.Context) error {
+	is.mtx.Lock()
+	defer is.mtx.Unlock()
+
+	return is.syncLocked(ctx)
+}
+
+// syncLocked downloads the files we do not have yet and drops the ones which were removed from the storage.
+// Index files are never modified once uploaded so we only need to compare their names.
+func (is *indexSet) syncLocked(ctx context.Context) error {
+	if err := chunk_util.EnsureDirectory(is.dir); err != nil {
+		return err
+	}
+
+	files, err := is.storageClient.ListUserFiles(ctx, is.tableName, is.userID)
+	if err != nil {
+		return err
+	}
+
+	listed := make(map[string]struct{}, len(files))
+	for _, file := range files {
+		if !strings.HasSuffix(file.Name, indexFileSuffix) {
+			continue
+		}
+		listed[file.Name] = struct{}{}
+
+		if _, ok := is.indices[file.Name]; ok {
+			continue
+		}
+
+		idx, err := downloadIndex(ctx, is.storageClient, is.tableName, is.userID, file.Name, is.dir)
+		if err != nil {
+			if is.storageClient.IsFileNotFoundErr(err) {
+				// the file got compacted away since we listed it.
+				delete(listed, file.Name)
+				continue
+			}
+			return err
+		}
+		is.indices[file.Name] = idx
+	}
+
+	for name, idx := range is.indices {
+		if _, ok := listed[name]; ok {
+			continue
+		}
+		if err := idx.Close(); err != nil {
+			level.Error(util_log.Logger).Log("msg", "failed to close index file", "path", idx.Path(), "err", err)
+		}
+		if err := os.Remove(idx.Path()); err != nil {
+			level.Error(util_log.Logger).Log("msg", "failed to remove index file", "path", idx.Path(), "err", err)
+		}
+		delete(is.indices, name)
+	}
+
+	return nil
+}
+
+// writeFile writes the contents of r to a temp file which is then renamed to path.
+func writeFile(path string, r io.Reader) (err error) {
+	f, err := ioutil.TempFile(filepath.Dir(path), filepath.Base(path)+".tmp")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = os.Remove(f.Name())
+		}
+	}()
+
+	_, err = io.Copy(f, r)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(f.Name(), path)
+}
+
+func (is *indexSet) close() {
+	is.mtx.Lock()
+	defer is.mtx.Unlock()
+
+	var errs util.MultiError
+	for _, idx := range is.indices {
+		errs.Add(idx.Close())
+	}
+	errs.Add(os.RemoveAll(is.dir))
+	if err := errs.Err(); err != nil {
+		level.Error(util_log.Logger).Log("msg", "failed to close tsdb index files", "table", is.tableName, "user", is.userID, "err", err)
+	}
+	is.indices = map[string]*TSDBIndex{}
+}
@@ -0,0 +1,132 @@
+package tsdb
+
+import (
+	"context"
+	"strings"
+
+	"github.com/grafana/dskit/grpcclient"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/promql/parser"
+	"github.com/weaveworks/common/instrument"
+	"github.com/weaveworks/common/user"
+	"google.golang.org/grpc"
+
+	"github.com/grafana/loki/pkg/storage/stores/shipper"
+	"github.com/grafana/loki/pkg/storage/stores/shipper/indexgateway/indexgatewaypb"
+)
+
+// GatewayClient queries the TSDB index through the Index Gateway.
+type GatewayClient struct {
+	conn       *grpc.ClientConn
+	grpcClient indexgatewaypb.IndexGatewayClient
+}
+
+func NewGatewayClient(cfg shipper.IndexGatewayClientConfig, r prometheus.Registerer) (*GatewayClient, error) {
+	requestDuration := promauto.With(r).NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "loki_tsdb_shipper",
+		Name:      "index_gateway_request_duration_seconds",
+		Help:      "Time (in seconds) spent serving requests when using tsdb shipper index gateway",
+		Buckets:   instrument.DefBuckets,
+	}, []string{"operation", "status_code"})
+
+	dialOpts, err := cfg.GRPCClientConfig.DialOption(grpcclient.Instrument(requestDuration))
+	if err != nil {
+		return nil, err
+	}
+
+	conn, err := grpc.Dial(cfg.Address, dialOpts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &GatewayClient{
+		conn:       conn,
+		grpcClient: indexgatewaypb.NewIndexGatewayClient(conn),
+	}, nil
+}
+
+func (c *GatewayClient) GetChunkRefs(ctx context.Context, userID string, tableNames []string, from, through model.Time, matchers ...*labels.Matcher) ([]ChunkRef, error) {
+	resp, err := c.grpcClient.GetChunkRef(user.InjectOrgID(ctx, userID), &indexgatewaypb.GetChunkRefRequest{
+		TableNames: tableNames,
+		From:       int64(from),
+		Through:    int64(through),
+		Matchers:   matchersToString(matchers),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	refs := make([]ChunkRef, 0, len(resp.Refs))
+	for _, ref := range resp.Refs {
+		refs = append(refs, ChunkRef{
+			User:        userID,
+			Fingerprint: model.Fingerprint(ref.Fingerprint),
+			Start:       model.Time(ref.From),
+			End:         model.Time(ref.Through),
+			Checksum:    ref.Checksum,
+			KB:          ref.Kb,
+		})
+	}
+	return refs, nil
+}
+
+func (c *GatewayClient) LabelNames(ctx context.Context, userID string, tableNames []string, from, through model.Time, matchers ...*labels.Matcher) ([]string, error) {
+	resp, err := c.grpcClient.LabelNames(user.InjectOrgID(ctx, userID), &indexgatewaypb.LabelNamesRequest{
+		TableNames: tableNames,
+		From:       int64(from),
+		Through:    int64(through),
+		Matchers:   matchersToString(matchers),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resp.Values, nil
+}
+
+func (c *GatewayClient) LabelValues(ctx context.Context, userID string, tableNames []string, from, through model.Time, name string, matchers ...*labels.Matcher) ([]string, error) {
+	resp, err := c.grpcClient.LabelValues(user.InjectOrgID(ctx, userID), &indexgatewaypb.LabelValuesRequest{
+		TableNames: tableNames,
+		From:       int64(from),
+		Through:    int64(through),
+		Name:       name,
+		Matchers:   matchersToString(matchers),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resp.Values, nil
+}
+
+func (c *GatewayClient) Stop() {
+	c.conn.Close()
+}
+
+// matchersToString encodes matchers as a selector, or an empty string when there are none
+// since an empty selector can't be parsed back.
+func matchersToString(matchers []*labels.Matcher) string {
+	if len(matchers) == 0 {
+		return ""
+	}
+
+	out := strings.Builder{}
+	out.WriteRune('{')
+	for idx, m := range matchers {
+		if idx > 0 {
+			out.WriteRune(',')
+		}
+		out.WriteString(m.String())
+	}
+	out.WriteRune('}')
+	return out.String()
+}
+
+// ParseMatchers decodes the matchers sent to the Index Gateway.
+func ParseMatchers(s string) ([]*labels.Matcher, error) {
+	if s == "" {
+		return nil, nil
+	}
+	return parser.ParseMetricSelector(s)
+}
@@ -0,0 +1,101 @@
+package tsdb
+
+import (
+	"context"
+	"sync"
+
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/model/labels"
+
+	"github.com/grafana/loki/pkg/querier/astmapper"
+)
+
+// Head is an in-memory, append only Index of series of a single tenant for a single table. It is periodically
+// written out as a TSDB index file by the HeadManager.
+type Head struct {
+	mtx    sync.RWMutex
+	series map[model.Fingerprint][]*headStream
+}
+
+// headStream is a stream of the Head along with the hash picking its query shard, computed once when added.
+type headStream struct {
+	stream
+	shardHash uint32
+}
+
+func newHead() *Head {
+	return &Head{series: map[model.Fingerprint][]*headStream{}}
+}
+
+// Append adds a chunk of the series identified by ls and fp to the Head.
+func (h *Head) Append(ls labels.Labels, fp model.Fingerprint, chk ChunkMeta) {
+	stored := labels.NewBuilder(ls).Del(labels.MetricName).Labels()
+
+	h.mtx.Lock()
+	defer h.mtx.Unlock()
+
+	for _, s := range h.series[fp] {
+		if labels.Equal(s.labels, stored) {
+			s.chunks = append(s.chunks, chk)
+			return
+		}
+	}
+
+	h.series[fp] = append(h.series[fp], &headStream{
+		stream: stream{
+			labels: stored,
+			fp:     fp,
+			chunks: ChunkMetas{chk},
+		},
+		shardHash: seriesShardHash(stored),
+	})
+}
+
+// builder returns a Builder holding all the series of the Head.
+func (h *Head) builder() *Builder {
+	h.mtx.RLock()
+	defer h.mtx.RUnlock()
+
+	b := NewBuilder()
+	for _, streams := range h.series {
+		for _, s := range streams {
+			b.AddSeries(s.labels, s.fp, s.chunks)
+		}
+	}
+	return b
+}
+
+func (h *Head) forSeries(ctx context.Context, shard *astmapper.ShardAnnotation, from, through model.Time, fn func(labels.Labels, model.Fingerprint, []ChunkMeta), matchers ...*labels.Matcher) error {
+	h.mtx.RLock()
+	defer h.mtx.RUnlock()
+
+	for fp, streams := range h.series {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+	outer:
+		for _, s := range streams {
+			if !inShard(shard, s.shardHash) {
+				continue
+			}
+			for _, m := range matchers {
+				if !m.Matches(s.labels.Get(m.Name)) {
+					continue outer
+				}
+			}
+
+			chks := filterChunkMetas(s.chunks, from, through)
+			if len(chks) == 0 {
+				continue
+			}
+			fn(s.labels, fp, chks)
+		}
+	}
+
+	return nil
+}
+
+func (h *Head) Close() error {
+	return nil
+}
@@ -0,0 +1,142 @@
+package tsdb
+
+import (
+	"encoding/binary"
+	"hash/crc32"
+	"io/ioutil"
+	"os"
+
+	"github.com/go-kit/log/level"
+	"github.com/pkg/errors"
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/tsdb/encoding"
+
+	util_log "github.com/grafana/loki/pkg/util/log"
+)
+
+var castagnoliTable = crc32.MakeTable(crc32.Castagnoli)
+
+// headLog is an append only log of all the chunks added to the heads of a table.
+// It is replayed on startup so that index entries of chunks flushed before a crash or a restart are not lost
+// before they get uploaded. Each record is framed with its length and checksum so that a torn write at the end
+// of the log is detected and dropped.
+type headLog struct {
+	f   *os.File
+	buf encoding.Encbuf
+}
+
+// openHeadLog replays the log at path, if any, calling fn for each record and then opens it for appending.
+func openHeadLog(path string, fn func(userID string, ls labels.Labels, fp model.Fingerprint, chk ChunkMeta)) (*headLog, error) {
+	validSize, err := replayHeadLog(path, fn)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY, 0666)
+	if err != nil {
+		return nil, err
+	}
+
+	// drop any partially written record at the end of the log.
+	if err := f.Truncate(validSize); err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+	if _, err := f.Seek(validSize, 0); err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+
+	return &headLog{f: f}, nil
+}
+
+func replayHeadLog(path string, fn func(userID string, ls labels.Labels, fp model.Fingerprint, chk ChunkMeta)) (int64, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return 0, nil
+		}
+		return 0, err
+	}
+
+	var offset int
+	for offset+8 <= len(b) {
+		size := int(binary.BigEndian.Uint32(b[offset:]))
+		checksum := binary.BigEndian.Uint32(b[offset+4:])
+		if offset+8+size > len(b) {
+			break
+		}
+
+		payload := b[offset+8 : offset+8+size]
+		if crc32.Checksum(payload, castagnoliTable) != checksum {
+			break
+		}
+
+		userID, ls, fp, chk, err := decodeHeadLogRecord(payload)
+		if err != nil {
+			return 0, errors.Wrapf(err, "decoding record at offset %d of %s", offset, path)
+		}
+		fn(userID, ls, fp, chk)
+		offset += 8 + size
+	}
+
+	if offset != len(b) {
+		level.Warn(util_log.Logger).Log("msg", "dropping corrupted tail of tsdb head log", "path", path, "bytes", len(b)-offset)
+	}
+
+	return int64(offset), nil
+}
+
+// Log appends a record for the chunk to the log.
+func (l *headLog) Log(userID string, ls labels.Labels, fp model.Fingerprint, chk ChunkMeta) error {
+	l.buf.Reset()
+	// reserve space for the length and checksum of the record.
+	l.buf.PutBE32(0)
+	l.buf.PutBE32(0)
+
+	l.buf.PutUvarintStr(userID)
+	l.buf.PutBE64(uint64(fp))
+	l.buf.PutUvarint(len(ls))
+	for _, lbl := range ls {
+		l.buf.PutUvarintStr(lbl.Name)
+		l.buf.PutUvarintStr(lbl.Value)
+	}
+	l.buf.PutVarint64(chk.MinTime)
+	l.buf.PutVarint64(chk.MaxTime)
+	l.buf.PutBE32(chk.Checksum)
+	l.buf.PutBE32(chk.KB)
+
+	b := l.buf.Get()
+	binary.BigEndian.PutUint32(b, uint32(len(b)-8))
+	binary.BigEndian.PutUint32(b[4:], crc32.Checksum(b[8:], castagnoliTable))
+
+	_, err := l.f.Write(b)
+	return err
+}
+
+func (l *headLog) Close() error {
+	return l.f.Close()
+}
+
+func decodeHeadLogRecord(b []byte) (string, labels.Labels, model.Fingerprint, ChunkMeta, error) {
+	d := encoding.Decbuf{B: b}
+
+	userID := d.UvarintStr()
+	fp := model.Fingerprint(d.Be64())
+
+	n := d.Uvarint()
+	ls := make(labels.Labels, 0, n)
+	for i := 0; i < n; i++ {
+		ls = append(ls, labels.Label{Name: d.UvarintStr(), Value: d.UvarintStr()})
+	}
+
+	chk := ChunkMeta{
+		MinTime:  d.Varint64(),
+		MaxTime:  d.Varint64(),
+		Checksum: d.Be32(),
+		KB:       d.Be32(),
+	}
+
+	return userID, ls, fp, chk, d.Err()
+}
@@ -0,0 +1,66 @@
+package tsdb
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/stretchr/testify/require"
+)
+
+type headLogRecord struct {
+	userID string
+	labels labels.Labels
+	fp     model.Fingerprint
+	chunk  ChunkMeta
+}
+
+func replayRecords(t *testing.T, path string) ([]headLogRecord, *headLog) {
+	var records []headLogRecord
+	log, err := openHeadLog(path, func(userID string, ls labels.Labels, fp model.Fingerprint, chk ChunkMeta) {
+		records = append(records, headLogRecord{userID: userID, labels: ls, fp: fp, chunk: chk})
+	})
+	require.NoError(t, err)
+	return records, log
+}
+
+func TestHeadLog(t *testing.T) {
+	path := filepath.Join(t.TempDir(), headLogFileName)
+
+	records, log := replayRecords(t, path)
+	require.Empty(t, records)
+
+	expected := []headLogRecord{
+		{userID: "user1", labels: labels.FromStrings("app", "foo"), fp: 1, chunk: ChunkMeta{Checksum: 1, MinTime: -10, MaxTime: 10, KB: 1}},
+		{userID: "user2", labels: labels.FromStrings("app", "bar", "env", "dev"), fp: 2, chunk: ChunkMeta{Checksum: 2, MinTime: 20, MaxTime: 30, KB: 2}},
+	}
+	for _, r := range expected {
+		require.NoError(t, log.Log(r.userID, r.labels, r.fp, r.chunk))
+	}
+	require.NoError(t, log.Close())
+
+	records, log = replayRecords(t, path)
+	require.Equal(t, expected, records)
+	require.NoError(t, log.Close())
+
+	// simulate a crash while writing a record.
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0666)
+	require.NoError(t, err)
+	_, err = f.Write([]byte{0, 0, 0, 100, 1, 2, 3})
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	records, log = replayRecords(t, path)
+	require.Equal(t, expected, records)
+
+	// records appended after the torn one was dropped are replayed as well.
+	next := headLogRecord{userID: "user1", labels: labels.FromStrings("app", "foo"), fp: 1, chunk: ChunkMeta{Checksum: 3, MinTime: 40, MaxTime: 50, KB: 3}}
+	require.NoError(t, log.Log(next.userID, next.labels, next.fp, next.chunk))
+	require.NoError(t, log.Close())
+
+	records, log = replayRecords(t, path)
+	require.Equal(t, append(expected, next), records)
+	require.NoError(t, log.Close())
+}
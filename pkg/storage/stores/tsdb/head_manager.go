@@ -0,0 +1,519 @@
+package tsdb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/go-kit/log/level"
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/model/labels"
+
+	chunk_util "github.com/grafana/loki/pkg/storage/chunk/util"
+	"github.com/grafana/loki/pkg/storage/stores/shipper/storage"
+	"github.com/grafana/loki/pkg/storage/stores/shipper/uploads"
+	"github.com/grafana/loki/pkg/util"
+	util_log "github.com/grafana/loki/pkg/util/log"
+)
+
+const headLogFileName = "head.log"
+
+var errHeadTableDropped = errors.New("head table dropped")
+
+// HeadManager keeps the in-memory heads of the tables being written to by the ingester. Heads of a table are sharded
+// by time, the same way as boltdb-shipper shards its dbs, and a shard is built into a TSDB index file per tenant and
+// uploaded once it stops being active. Shards are dropped once they have been uploaded for the retain period.
+type HeadManager struct {
+	dir            string
+	uploader       string
+	uploadInterval time.Duration
+	retainPeriod   time.Duration
+	storageClient  storage.Client
+	metrics        *metrics
+
+	tables    map[string]*headTable
+	tablesMtx sync.RWMutex
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func NewHeadManager(dir, uploader string, uploadInterval, retainPeriod time.Duration, storageClient storage.Client, metrics *metrics) (*HeadManager, error) {
+	if err := chunk_util.EnsureDirectory(dir); err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	hm := &HeadManager{
+		dir:            dir,
+		uploader:       uploader,
+		uploadInterval: uploadInterval,
+		retainPeriod:   retainPeriod,
+		storageClient:  storageClient,
+		metrics:        metrics,
+		tables:         map[string]*headTable{},
+		ctx:            ctx,
+		cancel:         cancel,
+	}
+
+	if err := hm.loadTables(); err != nil {
+		cancel()
+		return nil, err
+	}
+
+	hm.wg.Add(1)
+	go hm.loop()
+	return hm, nil
+}
+
+func (hm *HeadManager) loop() {
+	defer hm.wg.Done()
+
+	hm.uploadTables(hm.ctx, false)
+
+	ticker := time.NewTicker(hm.uploadInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			hm.uploadTables(hm.ctx, false)
+		case <-hm.ctx.Done():
+			return
+		}
+	}
+}
+
+// Stop stops the upload loop and does a final upload of all the heads with pending changes, including the active ones.
+func (hm *HeadManager) Stop() {
+	hm.cancel()
+	hm.wg.Wait()
+
+	hm.uploadTables(context.Background(), true)
+
+	hm.tablesMtx.Lock()
+	defer hm.tablesMtx.Unlock()
+
+	for _, table := range hm.tables {
+		table.close()
+	}
+}
+
+// Append adds a chunk of the series identified by ls and fp to the active head of the given table and tenant.
+func (hm *HeadManager) Append(tableName, userID string, ls labels.Labels, fp model.Fingerprint, chk ChunkMeta) error {
+	return hm.append(tableName, headShard(time.Now()), userID, ls, fp, chk)
+}
+
+func (hm *HeadManager) append(tableName string, shard int64, userID string, ls labels.Labels, fp model.Fingerprint, chk ChunkMeta) error {
+	for {
+		table, err := hm.getOrCreateTable(tableName)
+		if err != nil {
+			return err
+		}
+
+		// the table might get dropped between us getting it and appending to it, in which case we need a new one.
+		if err := table.append(shard, userID, ls, fp, chk); err != errHeadTableDropped {
+			return err
+		}
+	}
+}
+
+// indices returns the heads of all the shards of the given table and tenant.
+func (hm *HeadManager) indices(tableName, userID string) []Index {
+	hm.tablesMtx.RLock()
+	table, ok := hm.tables[tableName]
+	hm.tablesMtx.RUnlock()
+	if !ok {
+		return nil
+	}
+
+	table.mtx.RLock()
+	defer table.mtx.RUnlock()
+
+	var indices []Index
+	for _, s := range table.shards {
+		if head, ok := s.heads[userID]; ok {
+			indices = append(indices, head)
+		}
+	}
+	return indices
+}
+
+func (hm *HeadManager) getOrCreateTable(tableName string) (*headTable, error) {
+	hm.tablesMtx.RLock()
+	table, ok := hm.tables[tableName]
+	hm.tablesMtx.RUnlock()
+	if ok {
+		return table, nil
+	}
+
+	hm.tablesMtx.Lock()
+	defer hm.tablesMtx.Unlock()
+
+	table, ok = hm.tables[tableName]
+	if !ok {
+		var err error
+		table, err = openHeadTable(filepath.Join(hm.dir, tableName))
+		if err != nil {
+			return nil, err
+		}
+		hm.tables[tableName] = table
+	}
+
+	return table, nil
+}
+
+// uploadTables uploads the heads of the shards which are not active anymore, or of all the shards when force is true.
+func (hm *HeadManager) uploadTables(ctx context.Context, force bool) {
+	hm.tablesMtx.RLock()
+	tables := make([]*headTable, 0, len(hm.tables))
+	for _, table := range hm.tables {
+		tables = append(tables, table)
+	}
+	hm.tablesMtx.RUnlock()
+
+	level.Info(util_log.Logger).Log("msg", "uploading tsdb heads")
+
+	status := statusSuccess
+	for _, table := range tables {
+		if err := table.upload(ctx, hm.uploader, hm.storageClient, force); err != nil {
+			status = statusFailure
+			level.Error(util_log.Logger).Log("msg", "failed to upload tsdb heads", "table", table.name, "err", err)
+			continue
+		}
+
+		hm.tablesMtx.Lock()
+		dropped, err := table.dropExpiredShards(hm.retainPeriod)
+		if err != nil {
+			level.Error(util_log.Logger).Log("msg", "failed to drop tsdb heads past their retain period", "table", table.name, "err", err)
+		}
+		if dropped {
+			level.Info(util_log.Logger).Log("msg", "dropped tsdb heads past their retain period", "table", table.name)
+			delete(hm.tables, table.name)
+		}
+		hm.tablesMtx.Unlock()
+	}
+
+	hm.metrics.tablesUploadOperationTotal.WithLabelValues(status).Inc()
+}
+
+func (hm *HeadManager) loadTables() error {
+	filesInfo, err := ioutil.ReadDir(hm.dir)
+	if err != nil {
+		return err
+	}
+
+	// regex matching table name patters, i.e prefix+period_number
+	re, err := regexp.Compile(`.+[0-9]+$`)
+	if err != nil {
+		return err
+	}
+
+	for _, fileInfo := range filesInfo {
+		if !fileInfo.IsDir() || !re.MatchString(fileInfo.Name()) {
+			continue
+		}
+
+		level.Info(util_log.Logger).Log("msg", fmt.Sprintf("loading tsdb heads of table %s", fileInfo.Name()))
+		table, err := openHeadTable(filepath.Join(hm.dir, fileInfo.Name()))
+		if err != nil {
+			return err
+		}
+		hm.tables[fileInfo.Name()] = table
+	}
+
+	return nil
+}
+
+// headShard returns the shard of the heads which chunks added at t go to.
+func headShard(t time.Time) int64 {
+	return t.Truncate(uploads.ShardDBsByDuration).Unix()
+}
+
+// oldestActiveHeadShard returns the oldest shard which could still be getting chunks.
+// Shards older than it are considered rotated and get uploaded.
+// We give a minute of slack to shards which just stopped being active to let pending appends finish.
+func oldestActiveHeadShard() int64 {
+	return headShard(time.Now().Add(-time.Minute))
+}
+
+// headTable holds the heads of all the tenants for a table, sharded by time.
+type headTable struct {
+	name string
+	path string
+
+	mtx     sync.RWMutex
+	shards  map[int64]*headTableShard
+	dropped bool
+	// number of uploads in flight, the table can't be dropped while its files are being built or uploaded.
+	uploading int
+}
+
+// headTableShard holds the heads of all the tenants for a shard of a table along with the log of chunks added to them.
+type headTableShard struct {
+	shard int64
+	path  string
+	log   *headLog
+	heads map[string]*Head
+	// dirty is set when chunks got added to the heads since they were last uploaded.
+	dirty bool
+	// uploadedAt is the time the heads were uploaded after the shard got rotated.
+	uploadedAt time.Time
+}
+
+// openHeadTable opens the table at the given path, restoring the heads of all its shards from their logs.
+func openHeadTable(path string) (*headTable, error) {
+	if err := chunk_util.EnsureDirectory(path); err != nil {
+		return nil, err
+	}
+
+	t := &headTable{
+		name:   filepath.Base(path),
+		path:   path,
+		shards: map[int64]*headTableShard{},
+	}
+
+	filesInfo, err := ioutil.ReadDir(path)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, fileInfo := range filesInfo {
+		shard, err := strconv.ParseInt(fileInfo.Name(), 10, 64)
+		if err != nil || !fileInfo.IsDir() {
+			level.Warn(util_log.Logger).Log("msg", "removing unexpected file from tsdb head table", "table", t.name, "file", fileInfo.Name())
+			if err := os.RemoveAll(filepath.Join(path, fileInfo.Name())); err != nil {
+				t.close()
+				return nil, err
+			}
+			continue
+		}
+
+		s, err := openHeadTableShard(filepath.Join(path, fileInfo.Name()), shard)
+		if err != nil {
+			t.close()
+			return nil, err
+		}
+		t.shards[shard] = s
+	}
+
+	return t, nil
+}
+
+// openHeadTableShard opens the shard at the given path, restoring the heads from its log.
+// All the restored heads are considered dirty, so they get uploaded again.
+func openHeadTableShard(path string, shard int64) (*headTableShard, error) {
+	if err := chunk_util.EnsureDirectory(path); err != nil {
+		return nil, err
+	}
+
+	// remove the index files left behind by uploads interrupted by a crash.
+	filesInfo, err := ioutil.ReadDir(path)
+	if err != nil {
+		return nil, err
+	}
+	for _, fileInfo := range filesInfo {
+		if fileInfo.Name() == headLogFileName {
+			continue
+		}
+		if err := os.RemoveAll(filepath.Join(path, fileInfo.Name())); err != nil {
+			return nil, err
+		}
+	}
+
+	s := &headTableShard{
+		shard: shard,
+		path:  path,
+		heads: map[string]*Head{},
+	}
+
+	log, err := openHeadLog(filepath.Join(path, headLogFileName), func(userID string, ls labels.Labels, fp model.Fingerprint, chk ChunkMeta) {
+		s.head(userID).Append(ls, fp, chk)
+		s.dirty = true
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.log = log
+
+	return s, nil
+}
+
+// head must be called with the table mtx held or before the shard is shared.
+func (s *headTableShard) head(userID string) *Head {
+	head, ok := s.heads[userID]
+	if !ok {
+		head = newHead()
+		s.heads[userID] = head
+	}
+	return head
+}
+
+func (t *headTable) append(shard int64, userID string, ls labels.Labels, fp model.Fingerprint, chk ChunkMeta) error {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+
+	if t.dropped {
+		return errHeadTableDropped
+	}
+
+	s, ok := t.shards[shard]
+	if !ok {
+		var err error
+		s, err = openHeadTableShard(filepath.Join(t.path, strconv.FormatInt(shard, 10)), shard)
+		if err != nil {
+			return err
+		}
+		t.shards[shard] = s
+	}
+
+	if err := s.log.Log(userID, ls, fp, chk); err != nil {
+		return err
+	}
+
+	s.head(userID).Append(ls, fp, chk)
+	s.dirty = true
+	return nil
+}
+
+// upload builds and uploads an index file for each head of the dirty shards which got rotated, or of all
+// the dirty shards when force is true. Shards which got rotated are uploaded only once.
+func (t *headTable) upload(ctx context.Context, uploader string, storageClient storage.Client, force bool) error {
+	oldestActiveShard := oldestActiveHeadShard()
+
+	t.mtx.Lock()
+	t.uploading++
+	var toUpload []*headTableShard
+	heads := map[*headTableShard]map[string]*Head{}
+	for _, s := range t.shards {
+		if !s.dirty || (!force && s.shard >= oldestActiveShard) {
+			continue
+		}
+
+		toUpload = append(toUpload, s)
+		heads[s] = make(map[string]*Head, len(s.heads))
+		for userID, head := range s.heads {
+			heads[s][userID] = head
+		}
+		// chunks added while we upload would mark the shard dirty again.
+		s.dirty = false
+	}
+	t.mtx.Unlock()
+
+	defer func() {
+		t.mtx.Lock()
+		t.uploading--
+		t.mtx.Unlock()
+	}()
+
+	var errs util.MultiError
+	for _, s := range toUpload {
+		var failed bool
+		for userID, head := range heads[s] {
+			if err := t.uploadHead(ctx, uploader, s, userID, head, storageClient); err != nil {
+				failed = true
+				errs.Add(err)
+			}
+		}
+
+		t.mtx.Lock()
+		if failed {
+			s.dirty = true
+		} else if s.shard < oldestActiveShard {
+			s.uploadedAt = time.Now()
+		}
+		t.mtx.Unlock()
+	}
+
+	return errs.Err()
+}
+
+// uploadHead builds an index file from the head and uploads it. The name of the file only depends on the uploader and
+// the shard so that uploading the same head again, e.g. after a restart, replaces the file uploaded previously.
+func (t *headTable) uploadHead(ctx context.Context, uploader string, s *headTableShard, userID string, head *Head, storageClient storage.Client) error {
+	dir := filepath.Join(s.path, userID)
+	if err := chunk_util.EnsureDirectory(dir); err != nil {
+		return err
+	}
+
+	fileName := fmt.Sprintf("%s-%d%s", uploader, s.shard, indexFileSuffix)
+	filePath := filepath.Join(dir, fileName)
+	if err := head.builder().Build(ctx, filePath); err != nil {
+		return err
+	}
+	defer func() {
+		if err := os.Remove(filePath); err != nil {
+			level.Error(util_log.Logger).Log("msg", "failed to remove uploaded index file", "path", filePath, "err", err)
+		}
+	}()
+
+	f, err := os.Open(filePath)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := f.Close(); err != nil {
+			level.Error(util_log.Logger).Log("msg", "failed to close index file", "path", filePath, "err", err)
+		}
+	}()
+
+	level.Debug(util_log.Logger).Log("msg", "uploading tsdb index", "table", t.name, "user", userID, "file", fileName)
+	return storageClient.PutUserFile(ctx, t.name, userID, fileName, f)
+}
+
+// dropExpiredShards closes the log and removes the local files of the shards which were uploaded for more than the
+// retain period and got no chunks since then. The whole table gets dropped once it has no shards left and no upload
+// is in flight.
+func (t *headTable) dropExpiredShards(retainPeriod time.Duration) (bool, error) {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+
+	if t.uploading > 0 {
+		return false, nil
+	}
+
+	var errs util.MultiError
+	for shard, s := range t.shards {
+		if s.dirty || s.uploadedAt.IsZero() || time.Since(s.uploadedAt) <= retainPeriod {
+			continue
+		}
+
+		delete(t.shards, shard)
+		if err := s.log.Close(); err != nil {
+			errs.Add(err)
+		}
+		if err := os.RemoveAll(s.path); err != nil {
+			errs.Add(err)
+		}
+	}
+
+	if len(t.shards) > 0 {
+		return false, errs.Err()
+	}
+
+	t.dropped = true
+	if err := os.RemoveAll(t.path); err != nil {
+		errs.Add(err)
+	}
+	return true, errs.Err()
+}
+
+// close closes the logs of all the shards of the table.
+func (t *headTable) close() {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+
+	for _, s := range t.shards {
+		if err := s.log.Close(); err != nil {
+			level.Error(util_log.Logger).Log("msg", "failed to close head log", "table", t.name, "shard", s.shard, "err", err)
+		}
+	}
+}
@@ -0,0 +1,153 @@
+package tsdb
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/loki/pkg/storage/chunk/local"
+	"github.com/grafana/loki/pkg/storage/stores/shipper/storage"
+)
+
+const (
+	tableName = "index_1"
+	userID    = "user"
+)
+
+func newStorageClient(t *testing.T, dir string) storage.Client {
+	objectClient, err := local.NewFSObjectClient(local.FSConfig{Directory: dir})
+	require.NoError(t, err)
+	return storage.NewIndexStorageClient(objectClient, "tsdb_index/")
+}
+
+// newTestHeadManager creates a HeadManager which only uploads when stopped.
+func newTestHeadManager(t *testing.T, dir string, retainPeriod time.Duration, storageClient storage.Client) *HeadManager {
+	hm, err := NewHeadManager(dir, "ingester", time.Hour, retainPeriod, storageClient, newMetrics(prometheus.NewRegistry()))
+	require.NoError(t, err)
+	return hm
+}
+
+// uploadedSeries loads all the index files uploaded for the tenant and returns their series.
+func uploadedSeries(t *testing.T, storageClient storage.Client, tableName, userID string) []series {
+	files, err := storageClient.ListUserFiles(context.Background(), tableName, userID)
+	require.NoError(t, err)
+
+	var result []series
+	for _, file := range files {
+		idx, err := downloadIndex(context.Background(), storageClient, tableName, userID, file.Name, t.TempDir())
+		require.NoError(t, err)
+		result = append(result, collectSeries(t, idx, nil, model.Earliest, model.Latest)...)
+		require.NoError(t, idx.Close())
+	}
+	return result
+}
+
+// stopUploadLoop stops the upload loop of hm so that it doesn't race with the uploads done by the test.
+func stopUploadLoop(hm *HeadManager) {
+	hm.cancel()
+	hm.wg.Wait()
+}
+
+func TestHeadManager(t *testing.T) {
+	tempDir := t.TempDir()
+	activeDir := filepath.Join(tempDir, "active")
+	storageClient := newStorageClient(t, filepath.Join(tempDir, "storage"))
+
+	foo := labels.FromStrings("app", "foo")
+	bar := labels.FromStrings("app", "bar")
+
+	hm := newTestHeadManager(t, activeDir, time.Hour, storageClient)
+	require.NoError(t, hm.Append(tableName, userID, foo, fingerprint(foo), ChunkMeta{Checksum: 1, MinTime: 0, MaxTime: 10, KB: 1}))
+
+	// the head is queryable before being uploaded.
+	indices := hm.indices(tableName, userID)
+	require.Len(t, indices, 1)
+	require.Equal(t, []series{
+		{labels: foo, fp: fingerprint(foo), chunks: []ChunkMeta{{Checksum: 1, MinTime: 0, MaxTime: 10, KB: 1}}},
+	}, collectSeries(t, indices[0], nil, model.Earliest, model.Latest))
+	require.Empty(t, hm.indices(tableName, "other"))
+
+	hm.Stop()
+	require.Equal(t, []series{
+		{labels: foo, fp: fingerprint(foo), chunks: []ChunkMeta{{Checksum: 1, MinTime: 0, MaxTime: 10, KB: 1}}},
+	}, uploadedSeries(t, storageClient, tableName, userID))
+
+	// the head is restored from its log and the file built from it gets replaced by the next upload of the same shard.
+	hm = newTestHeadManager(t, activeDir, time.Hour, storageClient)
+	require.NoError(t, hm.Append(tableName, userID, bar, fingerprint(bar), ChunkMeta{Checksum: 2, MinTime: 20, MaxTime: 30, KB: 2}))
+	hm.Stop()
+
+	require.ElementsMatch(t, []series{
+		{labels: foo, fp: fingerprint(foo), chunks: []ChunkMeta{{Checksum: 1, MinTime: 0, MaxTime: 10, KB: 1}}},
+		{labels: bar, fp: fingerprint(bar), chunks: []ChunkMeta{{Checksum: 2, MinTime: 20, MaxTime: 30, KB: 2}}},
+	}, uploadedSeries(t, storageClient, tableName, userID))
+}
+
+func TestHeadManager_UploadsRotatedHeads(t *testing.T) {
+	tempDir := t.TempDir()
+	activeDir := filepath.Join(tempDir, "active")
+	storageClient := newStorageClient(t, filepath.Join(tempDir, "storage"))
+
+	foo := labels.FromStrings("app", "foo")
+	bar := labels.FromStrings("app", "bar")
+
+	hm := newTestHeadManager(t, activeDir, time.Hour, storageClient)
+	stopUploadLoop(hm)
+
+	rotatedShard := headShard(time.Now().Add(-time.Hour))
+	require.NoError(t, hm.append(tableName, rotatedShard, userID, foo, fingerprint(foo), ChunkMeta{Checksum: 1, MinTime: 0, MaxTime: 10, KB: 1}))
+	require.NoError(t, hm.Append(tableName, userID, bar, fingerprint(bar), ChunkMeta{Checksum: 2, MinTime: 20, MaxTime: 30, KB: 2}))
+
+	// only the rotated head gets uploaded.
+	hm.uploadTables(context.Background(), false)
+	require.Equal(t, []series{
+		{labels: foo, fp: fingerprint(foo), chunks: []ChunkMeta{{Checksum: 1, MinTime: 0, MaxTime: 10, KB: 1}}},
+	}, uploadedSeries(t, storageClient, tableName, userID))
+
+	// the rotated head is uploaded only once, so removing its file from the store must not get it uploaded again.
+	files, err := storageClient.ListUserFiles(context.Background(), tableName, userID)
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+	require.NoError(t, storageClient.DeleteUserFile(context.Background(), tableName, userID, files[0].Name))
+	hm.uploadTables(context.Background(), false)
+	require.Empty(t, uploadedSeries(t, storageClient, tableName, userID))
+
+	// both the heads stay queryable until the uploaded one goes past its retain period.
+	require.Len(t, hm.indices(tableName, userID), 2)
+
+	// stopping uploads the active head as well.
+	hm.Stop()
+	require.Equal(t, []series{
+		{labels: bar, fp: fingerprint(bar), chunks: []ChunkMeta{{Checksum: 2, MinTime: 20, MaxTime: 30, KB: 2}}},
+	}, uploadedSeries(t, storageClient, tableName, userID))
+}
+
+func TestHeadManager_DropExpiredTables(t *testing.T) {
+	tempDir := t.TempDir()
+	activeDir := filepath.Join(tempDir, "active")
+	storageClient := newStorageClient(t, filepath.Join(tempDir, "storage"))
+
+	foo := labels.FromStrings("app", "foo")
+
+	hm := newTestHeadManager(t, activeDir, 0, storageClient)
+	stopUploadLoop(hm)
+
+	require.NoError(t, hm.append(tableName, headShard(time.Now().Add(-time.Hour)), userID, foo, fingerprint(foo), ChunkMeta{Checksum: 1, MinTime: 0, MaxTime: 10, KB: 1}))
+
+	// the rotated shard gets dropped right after its upload, which leaves the table without shards.
+	hm.uploadTables(context.Background(), false)
+	require.Empty(t, hm.indices(tableName, userID))
+	require.NoDirExists(t, filepath.Join(activeDir, tableName))
+	require.Len(t, uploadedSeries(t, storageClient, tableName, userID), 1)
+
+	// appending to a dropped table starts a new one.
+	require.NoError(t, hm.Append(tableName, userID, foo, fingerprint(foo), ChunkMeta{Checksum: 2, MinTime: 20, MaxTime: 30, KB: 1}))
+	require.Len(t, hm.indices(tableName, userID), 1)
+	hm.Stop()
+}
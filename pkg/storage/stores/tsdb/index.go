@@ -0,0 +1,153 @@
+package tsdb
+
+import (
+	"context"
+	"sort"
+
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/model/labels"
+
+	"github.com/grafana/loki/pkg/querier/astmapper"
+	"github.com/grafana/loki/pkg/storage/chunk"
+)
+
+// Index is a queryable set of series belonging to a single tenant.
+type Index interface {
+	// forSeries calls fn for every series matching all the matchers and the shard, if any, with the chunks of the series
+	// overlapping the from/through range. Series without any chunk in the range are skipped.
+	// fn must not retain the labels or chunks passed to it.
+	forSeries(ctx context.Context, shard *astmapper.ShardAnnotation, from, through model.Time, fn func(labels.Labels, model.Fingerprint, []ChunkMeta), matchers ...*labels.Matcher) error
+	Close() error
+}
+
+// splitMatchers removes the metric name and shard matchers, which are not stored in the index, from the matchers.
+func splitMatchers(matchers []*labels.Matcher) (*astmapper.ShardAnnotation, []*labels.Matcher, error) {
+	shard, _, err := astmapper.ShardFromMatchers(matchers)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	filtered := make([]*labels.Matcher, 0, len(matchers))
+	for _, m := range matchers {
+		if m.Name == labels.MetricName || m.Name == astmapper.ShardLabel {
+			continue
+		}
+		filtered = append(filtered, m)
+	}
+	return shard, filtered, nil
+}
+
+// logsMetricName is the metric name of the series of logs, which isn't stored in the index but is part of their series ID.
+var logsMetricName = labels.Label{Name: labels.MetricName, Value: "logs"}
+
+// seriesShardHash returns the hash of the stored labels of a series used to pick its query shard,
+// which is the same as the one of the ingesters and of the boltdb schemas.
+func seriesShardHash(stored labels.Labels) uint32 {
+	ls := make(labels.Labels, 0, len(stored)+1)
+	ls = append(ls, logsMetricName)
+	ls = append(ls, stored...)
+	return chunk.LabelsSeriesIDHash(ls)
+}
+
+func inShard(shard *astmapper.ShardAnnotation, shardHash uint32) bool {
+	return shard == nil || shardHash%uint32(shard.Of) == uint32(shard.Shard)
+}
+
+func filterChunkMetas(chks []ChunkMeta, from, through model.Time) []ChunkMeta {
+	filtered := make([]ChunkMeta, 0, len(chks))
+	for _, chk := range chks {
+		if chk.overlaps(from, through) {
+			filtered = append(filtered, chk)
+		}
+	}
+	return filtered
+}
+
+// getChunkRefs returns deduplicated refs of the chunks from all the indices which match the matchers.
+func getChunkRefs(ctx context.Context, indices []Index, userID string, from, through model.Time, matchers ...*labels.Matcher) ([]ChunkRef, error) {
+	shard, matchers, err := splitMatchers(matchers)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := map[ChunkRef]struct{}{}
+	var refs []ChunkRef
+	for _, idx := range indices {
+		err := idx.forSeries(ctx, shard, from, through, func(_ labels.Labels, fp model.Fingerprint, chks []ChunkMeta) {
+			for _, chk := range chks {
+				ref := ChunkRef{
+					User:        userID,
+					Fingerprint: fp,
+					Start:       model.Time(chk.MinTime),
+					End:         model.Time(chk.MaxTime),
+					Checksum:    chk.Checksum,
+					KB:          chk.KB,
+				}
+				if _, ok := seen[ref]; ok {
+					continue
+				}
+				seen[ref] = struct{}{}
+				refs = append(refs, ref)
+			}
+		}, matchers...)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return refs, nil
+}
+
+// labelNames returns the sorted names of labels of series from all the indices which match the matchers.
+func labelNames(ctx context.Context, indices []Index, from, through model.Time, matchers ...*labels.Matcher) ([]string, error) {
+	shard, matchers, err := splitMatchers(matchers)
+	if err != nil {
+		return nil, err
+	}
+
+	names := map[string]struct{}{}
+	for _, idx := range indices {
+		err := idx.forSeries(ctx, shard, from, through, func(ls labels.Labels, _ model.Fingerprint, _ []ChunkMeta) {
+			for _, l := range ls {
+				names[l.Name] = struct{}{}
+			}
+		}, matchers...)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return sortedKeys(names), nil
+}
+
+// labelValues returns the sorted values of the given label of series from all the indices which match the matchers.
+func labelValues(ctx context.Context, indices []Index, from, through model.Time, name string, matchers ...*labels.Matcher) ([]string, error) {
+	shard, matchers, err := splitMatchers(matchers)
+	if err != nil {
+		return nil, err
+	}
+
+	// only series having the label are of interest.
+	matchers = append(matchers, labels.MustNewMatcher(labels.MatchNotEqual, name, ""))
+
+	values := map[string]struct{}{}
+	for _, idx := range indices {
+		err := idx.forSeries(ctx, shard, from, through, func(ls labels.Labels, _ model.Fingerprint, _ []ChunkMeta) {
+			values[ls.Get(name)] = struct{}{}
+		}, matchers...)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return sortedKeys(values), nil
+}
+
+func sortedKeys(m map[string]struct{}) []string {
+	res := make([]string, 0, len(m))
+	for k := range m {
+		res = append(res, k)
+	}
+	sort.Strings(res)
+	return res
+}
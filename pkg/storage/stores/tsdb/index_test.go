@@ -0,0 +1,72 @@
+package tsdb
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/stretchr/testify/require"
+
+	ingester_index "github.com/grafana/loki/pkg/ingester/index"
+	"github.com/grafana/loki/pkg/logproto"
+	"github.com/grafana/loki/pkg/querier/astmapper"
+	"github.com/grafana/loki/pkg/storage/chunk"
+)
+
+// TestShardingMatchesIngestersAndSchema checks that a series is in the same query shard whether it comes from the ingesters,
+// the boltdb index or the tsdb index, otherwise a sharded query would count it twice or miss it.
+func TestShardingMatchesIngestersAndSchema(t *testing.T) {
+	const shards = 16
+
+	schema, err := chunk.PeriodConfig{Schema: "v11", RowShards: shards, IndexTables: chunk.PeriodicTableConfig{Prefix: "index_"}}.CreateSchema()
+	require.NoError(t, err)
+	seriesSchema := schema.(chunk.SeriesStoreSchema)
+
+	ingesterIndex := ingester_index.NewWithShards(32)
+	head := newHead()
+	boltdbShards := map[model.Fingerprint]int{}
+
+	for i := 0; i < 100; i++ {
+		ls := labels.FromStrings("app", fmt.Sprintf("app-%d", i), "namespace", "loki")
+		fp := model.Fingerprint(ls.Hash())
+		metric := labels.NewBuilder(ls).Set(labels.MetricName, "logs").Labels()
+
+		ingesterIndex.Add(logproto.FromLabelsToLabelAdapters(ls), fp)
+		head.Append(metric, fp, ChunkMeta{Checksum: uint32(i), MinTime: 0, MaxTime: 10})
+
+		_, entries, err := seriesSchema.GetCacheKeysAndLabelWriteEntries(0, 10, "user", "logs", metric, "chunk")
+		require.NoError(t, err)
+		shard, err := strconv.Atoi(strings.SplitN(entries[0][0].HashValue, ":", 2)[0])
+		require.NoError(t, err)
+		boltdbShards[fp] = shard
+	}
+
+	total := 0
+	for i := 0; i < shards; i++ {
+		shard := &astmapper.ShardAnnotation{Shard: i, Of: shards}
+
+		ingesterFps, err := ingesterIndex.Lookup(nil, shard)
+		require.NoError(t, err)
+
+		var boltdbFps []model.Fingerprint
+		for fp, s := range boltdbShards {
+			if s == i {
+				boltdbFps = append(boltdbFps, fp)
+			}
+		}
+
+		var tsdbFps []model.Fingerprint
+		require.NoError(t, head.forSeries(context.Background(), shard, model.Earliest, model.Latest, func(_ labels.Labels, fp model.Fingerprint, _ []ChunkMeta) {
+			tsdbFps = append(tsdbFps, fp)
+		}))
+
+		require.ElementsMatch(t, ingesterFps, tsdbFps, "shard %d", i)
+		require.ElementsMatch(t, boltdbFps, tsdbFps, "shard %d", i)
+		total += len(tsdbFps)
+	}
+	require.Equal(t, 100, total)
+}
@@ -0,0 +1,31 @@
+package tsdb
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+const (
+	statusFailure = "failure"
+	statusSuccess = "success"
+)
+
+type metrics struct {
+	tablesUploadOperationTotal *prometheus.CounterVec
+	tablesSyncOperationTotal   *prometheus.CounterVec
+}
+
+func newMetrics(r prometheus.Registerer) *metrics {
+	return &metrics{
+		tablesUploadOperationTotal: promauto.With(r).NewCounterVec(prometheus.CounterOpts{
+			Namespace: "loki_tsdb_shipper",
+			Name:      "tables_upload_operation_total",
+			Help:      "Total number of upload operations done by status",
+		}, []string{"status"}),
+		tablesSyncOperationTotal: promauto.With(r).NewCounterVec(prometheus.CounterOpts{
+			Namespace: "loki_tsdb_shipper",
+			Name:      "tables_sync_operation_total",
+			Help:      "Total number of tables sync operations done by status",
+		}, []string{"status"}),
+	}
+}
@@ -0,0 +1,181 @@
+package tsdb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"sync"
+	"time"
+
+	"github.com/go-kit/log/level"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/model/labels"
+
+	"github.com/grafana/loki/pkg/storage/chunk"
+	chunk_util "github.com/grafana/loki/pkg/storage/chunk/util"
+	"github.com/grafana/loki/pkg/storage/stores/shipper"
+	"github.com/grafana/loki/pkg/storage/stores/shipper/storage"
+	util_log "github.com/grafana/loki/pkg/util/log"
+)
+
+var errReadOnly = errors.New("tsdb shipper is running in read only mode")
+
+// IndexClient is used for querying the TSDB index of a tenant across tables.
+type IndexClient interface {
+	GetChunkRefs(ctx context.Context, userID string, tableNames []string, from, through model.Time, matchers ...*labels.Matcher) ([]ChunkRef, error)
+	LabelNames(ctx context.Context, userID string, tableNames []string, from, through model.Time, matchers ...*labels.Matcher) ([]string, error)
+	LabelValues(ctx context.Context, userID string, tableNames []string, from, through model.Time, name string, matchers ...*labels.Matcher) ([]string, error)
+	Stop()
+}
+
+// IndexWriter is used for adding flushed chunks to the TSDB index.
+type IndexWriter interface {
+	Append(tableName, userID string, ls labels.Labels, fp model.Fingerprint, chk ChunkMeta) error
+}
+
+// Shipper writes chunks to per tenant TSDB heads which are built into index files and uploaded to the shared store,
+// and serves queries from the heads along with the index files it downloads from the shared store.
+type Shipper struct {
+	cfg              Config
+	headManager      *HeadManager
+	downloadsManager *downloadsManager
+
+	stopOnce sync.Once
+}
+
+// NewShipper creates a shipper for syncing TSDB index files with a store.
+func NewShipper(cfg Config, storageClient chunk.ObjectClient, registerer prometheus.Registerer) (*Shipper, error) {
+	s := &Shipper{cfg: cfg}
+	indexStorageClient := storage.NewIndexStorageClient(storageClient, cfg.SharedStoreKeyPrefix)
+	metrics := newMetrics(registerer)
+
+	if cfg.Mode != shipper.ModeReadOnly {
+		uploader, err := s.getUploaderName()
+		if err != nil {
+			return nil, err
+		}
+
+		s.headManager, err = NewHeadManager(cfg.ActiveIndexDirectory, uploader, UploadInterval, cfg.IngesterHeadRetainPeriod, indexStorageClient, metrics)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if cfg.Mode != shipper.ModeWriteOnly {
+		var err error
+		s.downloadsManager, err = newDownloadsManager(cfg.CacheLocation, cfg.ResyncInterval, cfg.CacheTTL, indexStorageClient, metrics)
+		if err != nil {
+			if s.headManager != nil {
+				s.headManager.Stop()
+			}
+			return nil, err
+		}
+	}
+
+	level.Info(util_log.Logger).Log("msg", fmt.Sprintf("starting tsdb shipper in %d mode", cfg.Mode))
+	return s, nil
+}
+
+// we would persist uploader name in <active-index-directory>/uploader/name file so that we use same name on subsequent restarts to
+// avoid uploading same files again with different name. If the filed does not exist we would create one with uploader name set to
+// ingester name and startup timestamp so that we randomise the name and do not override files from other ingesters.
+func (s *Shipper) getUploaderName() (string, error) {
+	uploader := fmt.Sprintf("%s-%d", s.cfg.IngesterName, time.Now().UnixNano())
+
+	uploaderFilePath := path.Join(s.cfg.ActiveIndexDirectory, "uploader", "name")
+	if err := chunk_util.EnsureDirectory(path.Dir(uploaderFilePath)); err != nil {
+		return "", err
+	}
+
+	_, err := os.Stat(uploaderFilePath)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			return "", err
+		}
+		if err := ioutil.WriteFile(uploaderFilePath, []byte(uploader), 0666); err != nil {
+			return "", err
+		}
+	} else {
+		ub, err := ioutil.ReadFile(uploaderFilePath)
+		if err != nil {
+			return "", err
+		}
+		uploader = string(ub)
+	}
+
+	return uploader, nil
+}
+
+func (s *Shipper) Append(tableName, userID string, ls labels.Labels, fp model.Fingerprint, chk ChunkMeta) error {
+	if s.headManager == nil {
+		return errReadOnly
+	}
+
+	return s.headManager.Append(tableName, userID, ls, fp, chk)
+}
+
+func (s *Shipper) GetChunkRefs(ctx context.Context, userID string, tableNames []string, from, through model.Time, matchers ...*labels.Matcher) ([]ChunkRef, error) {
+	var refs []ChunkRef
+	err := s.forIndices(ctx, userID, tableNames, func(indices []Index) (err error) {
+		refs, err = getChunkRefs(ctx, indices, userID, from, through, matchers...)
+		return err
+	})
+	return refs, err
+}
+
+func (s *Shipper) LabelNames(ctx context.Context, userID string, tableNames []string, from, through model.Time, matchers ...*labels.Matcher) ([]string, error) {
+	var names []string
+	err := s.forIndices(ctx, userID, tableNames, func(indices []Index) (err error) {
+		names, err = labelNames(ctx, indices, from, through, matchers...)
+		return err
+	})
+	return names, err
+}
+
+func (s *Shipper) LabelValues(ctx context.Context, userID string, tableNames []string, from, through model.Time, name string, matchers ...*labels.Matcher) ([]string, error) {
+	var values []string
+	err := s.forIndices(ctx, userID, tableNames, func(indices []Index) (err error) {
+		values, err = labelValues(ctx, indices, from, through, name, matchers...)
+		return err
+	})
+	return values, err
+}
+
+// forIndices calls fn with the heads and downloaded index files of the tenant for the given tables.
+func (s *Shipper) forIndices(ctx context.Context, userID string, tableNames []string, fn func([]Index) error) error {
+	var indices []Index
+	for _, tableName := range tableNames {
+		if s.headManager != nil {
+			indices = append(indices, s.headManager.indices(tableName, userID)...)
+		}
+
+		if s.downloadsManager != nil {
+			downloaded, release, err := s.downloadsManager.indices(ctx, tableName, userID)
+			if err != nil {
+				return err
+			}
+			defer release()
+			indices = append(indices, downloaded...)
+		}
+	}
+
+	return fn(indices)
+}
+
+func (s *Shipper) Stop() {
+	s.stopOnce.Do(s.stop)
+}
+
+func (s *Shipper) stop() {
+	if s.headManager != nil {
+		s.headManager.Stop()
+	}
+
+	if s.downloadsManager != nil {
+		s.downloadsManager.Stop()
+	}
+}
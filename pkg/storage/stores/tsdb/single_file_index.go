@@ -0,0 +1,89 @@
+package tsdb
+
+import (
+	"context"
+
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/tsdb"
+	"github.com/prometheus/prometheus/tsdb/chunks"
+	"github.com/prometheus/prometheus/tsdb/index"
+
+	"github.com/grafana/loki/pkg/querier/astmapper"
+)
+
+// TSDBIndex is an Index backed by a single TSDB index file.
+type TSDBIndex struct {
+	path   string
+	reader *index.Reader
+}
+
+// LoadTSDBIndex opens the TSDB index file at the given path.
+func LoadTSDBIndex(path string) (*TSDBIndex, error) {
+	reader, err := index.NewFileReader(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return &TSDBIndex{path: path, reader: reader}, nil
+}
+
+func (i *TSDBIndex) Path() string {
+	return i.path
+}
+
+func (i *TSDBIndex) Close() error {
+	return i.reader.Close()
+}
+
+func (i *TSDBIndex) forSeries(ctx context.Context, shard *astmapper.ShardAnnotation, from, through model.Time, fn func(labels.Labels, model.Fingerprint, []ChunkMeta), matchers ...*labels.Matcher) error {
+	var (
+		p   index.Postings
+		err error
+	)
+	if len(matchers) == 0 {
+		p, err = i.reader.Postings(index.AllPostingsKey())
+	} else {
+		p, err = tsdb.PostingsForMatchers(i.reader, matchers...)
+	}
+	if err != nil {
+		return err
+	}
+
+	var (
+		ls    labels.Labels
+		metas []chunks.Meta
+		chks  []ChunkMeta
+	)
+	for p.Next() {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		if err := i.reader.Series(p.At(), &ls, &metas); err != nil {
+			return err
+		}
+
+		series, fp, err := fromStoredLabels(ls)
+		if err != nil {
+			return err
+		}
+		if shard != nil && !inShard(shard, seriesShardHash(series)) {
+			continue
+		}
+
+		chks = chks[:0]
+		for _, meta := range metas {
+			if chk := newChunkMeta(meta); chk.overlaps(from, through) {
+				chks = append(chks, chk)
+			}
+		}
+		if len(chks) == 0 {
+			continue
+		}
+
+		fn(series, fp, chks)
+	}
+
+	return p.Err()
+}
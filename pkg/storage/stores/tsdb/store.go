@@ -0,0 +1,256 @@
+package tsdb
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/cortexproject/cortex/pkg/util/validation"
+	"github.com/go-kit/log/level"
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/model/labels"
+
+	"github.com/grafana/loki/pkg/querier/astmapper"
+	"github.com/grafana/loki/pkg/storage/chunk"
+	"github.com/grafana/loki/pkg/storage/chunk/cache"
+	"github.com/grafana/loki/pkg/util/spanlogger"
+)
+
+// store is a chunk.Store for a period using the TSDB index. Chunk refs are read straight from the index,
+// without any further lookups, and flushed chunks are added to the TSDB heads of the ingester.
+type store struct {
+	schemaCfg chunk.SchemaConfig
+	periodCfg chunk.PeriodConfig
+	index     IndexClient
+	writer    IndexWriter
+	chunks    chunk.Client
+	limits    chunk.StoreLimits
+	fetcher   *chunk.Fetcher
+}
+
+// NewStore creates a chunk.Store for the period using the given index client. writer can be nil in which case the
+// store is read only.
+func NewStore(cfg chunk.StoreConfig, schemaCfg chunk.SchemaConfig, periodCfg chunk.PeriodConfig, index IndexClient, writer IndexWriter, chunks chunk.Client, limits chunk.StoreLimits, chunksCache cache.Cache) (chunk.Store, error) {
+	fetcher, err := chunk.NewChunkFetcher(chunksCache, cfg.ChunkCacheStubs(), schemaCfg, chunks, cfg.ChunkCacheConfig.AsyncCacheWriteBackConcurrency, cfg.ChunkCacheConfig.AsyncCacheWriteBackBufferSize)
+	if err != nil {
+		return nil, err
+	}
+
+	return &store{
+		schemaCfg: schemaCfg,
+		periodCfg: periodCfg,
+		index:     index,
+		writer:    writer,
+		chunks:    chunks,
+		limits:    limits,
+		fetcher:   fetcher,
+	}, nil
+}
+
+func (s *store) Put(ctx context.Context, chunks []chunk.Chunk) error {
+	for _, chk := range chunks {
+		if err := s.PutOne(ctx, chk.From, chk.Through, chk); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *store) PutOne(ctx context.Context, from, through model.Time, chk chunk.Chunk) error {
+	if s.writer == nil {
+		return errReadOnly
+	}
+
+	encoded, err := chk.Encoded()
+	if err != nil {
+		return err
+	}
+
+	if err := s.chunks.PutChunks(ctx, []chunk.Chunk{chk}); err != nil {
+		return err
+	}
+
+	meta := ChunkMeta{
+		Checksum: chk.Checksum,
+		MinTime:  int64(chk.From),
+		MaxTime:  int64(chk.Through),
+		KB:       kbFromBytes(len(encoded)),
+	}
+	for _, tableName := range s.tables(from, through) {
+		if err := s.writer.Append(tableName, chk.UserID, chk.Metric, chk.Fingerprint, meta); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *store) Get(ctx context.Context, userID string, from, through model.Time, matchers ...*labels.Matcher) ([]chunk.Chunk, error) {
+	log, ctx := spanlogger.New(ctx, "TSDBStore.Get")
+	defer log.Span.Finish()
+
+	chks, fetchers, err := s.GetChunkRefs(ctx, userID, from, through, matchers...)
+	if err != nil {
+		return nil, err
+	}
+	if len(chks) == 0 {
+		return nil, nil
+	}
+
+	chunks := chks[0]
+	// Protect ourselves against OOMing.
+	maxChunksPerQuery := s.limits.MaxChunksPerQueryFromStore(userID)
+	if maxChunksPerQuery > 0 && len(chunks) > maxChunksPerQuery {
+		err := chunk.QueryError(fmt.Sprintf("Query %v fetched too many chunks (%d > %d)", matchers, len(chunks), maxChunksPerQuery))
+		level.Error(log).Log("err", err)
+		return nil, err
+	}
+
+	keys := make([]string, 0, len(chunks))
+	for _, chk := range chunks {
+		keys = append(keys, s.schemaCfg.ExternalKey(chk))
+	}
+	allChunks, err := fetchers[0].FetchChunks(ctx, chunks, keys)
+	if err != nil {
+		level.Error(log).Log("msg", "FetchChunks", "err", err)
+		return nil, err
+	}
+
+	// inject artificial __cortex_shard__ labels if present in the query, all the refs we got match the shard.
+	shard, _, err := astmapper.ShardFromMatchers(matchers)
+	if err != nil {
+		return nil, err
+	}
+	if shard != nil {
+		l := shard.Label()
+		for i := range allChunks {
+			allChunks[i].Metric = labels.NewBuilder(allChunks[i].Metric).Set(l.Name, l.Value).Labels()
+		}
+	}
+
+	return allChunks, nil
+}
+
+func (s *store) GetChunkRefs(ctx context.Context, userID string, from, through model.Time, matchers ...*labels.Matcher) ([][]chunk.Chunk, []*chunk.Fetcher, error) {
+	if ctx.Err() != nil {
+		return nil, nil, ctx.Err()
+	}
+	log, ctx := spanlogger.New(ctx, "TSDBStore.GetChunkRefs")
+	defer log.Span.Finish()
+
+	if shortcut, err := s.validateQueryTimeRange(userID, &from, &through); err != nil {
+		return nil, nil, err
+	} else if shortcut {
+		return nil, nil, nil
+	}
+
+	refs, err := s.index.GetChunkRefs(ctx, userID, s.tables(from, through), from, through, matchers...)
+	if err != nil {
+		return nil, nil, err
+	}
+	level.Debug(log).Log("chunk-refs", len(refs))
+
+	// We should return an empty chunks slice if there are no chunks.
+	if len(refs) == 0 {
+		return [][]chunk.Chunk{}, []*chunk.Fetcher{}, nil
+	}
+
+	chunks := make([]chunk.Chunk, 0, len(refs))
+	for _, ref := range refs {
+		chunks = append(chunks, chunk.Chunk{
+			Fingerprint: ref.Fingerprint,
+			UserID:      ref.User,
+			From:        ref.Start,
+			Through:     ref.End,
+			Checksum:    ref.Checksum,
+			ChecksumSet: true,
+		})
+	}
+
+	return [][]chunk.Chunk{chunks}, []*chunk.Fetcher{s.fetcher}, nil
+}
+
+func (s *store) LabelValuesForMetricName(ctx context.Context, userID string, from, through model.Time, metricName string, labelName string, matchers ...*labels.Matcher) ([]string, error) {
+	log, ctx := spanlogger.New(ctx, "TSDBStore.LabelValuesForMetricName")
+	defer log.Span.Finish()
+
+	if shortcut, err := s.validateQueryTimeRange(userID, &from, &through); err != nil {
+		return nil, err
+	} else if shortcut {
+		return nil, nil
+	}
+
+	return s.index.LabelValues(ctx, userID, s.tables(from, through), from, through, labelName, matchers...)
+}
+
+func (s *store) LabelNamesForMetricName(ctx context.Context, userID string, from, through model.Time, metricName string, matchers ...*labels.Matcher) ([]string, error) {
+	log, ctx := spanlogger.New(ctx, "TSDBStore.LabelNamesForMetricName")
+	defer log.Span.Finish()
+
+	if shortcut, err := s.validateQueryTimeRange(userID, &from, &through); err != nil {
+		return nil, err
+	} else if shortcut {
+		return nil, nil
+	}
+
+	return s.index.LabelNames(ctx, userID, s.tables(from, through), from, through, matchers...)
+}
+
+func (s *store) GetChunkFetcher(_ model.Time) *chunk.Fetcher {
+	return s.fetcher
+}
+
+// DeleteChunk is not supported since chunks are never removed from TSDB index files once written.
+func (s *store) DeleteChunk(ctx context.Context, from, through model.Time, userID, chunkID string, metric labels.Labels, partiallyDeletedInterval *model.Interval) error {
+	return chunk.ErrNotSupported
+}
+
+func (s *store) DeleteSeriesIDs(ctx context.Context, from, through model.Time, userID string, metric labels.Labels) error {
+	// SeriesID is something which is only used in SeriesStore so we need not do anything here
+	return nil
+}
+
+// Stop stops the store along with its index client, which is shared by the stores of all the periods
+// using TSDB and expected to tolerate being stopped more than once.
+func (s *store) Stop() {
+	s.fetcher.Stop()
+	s.chunks.Stop()
+	s.index.Stop()
+}
+
+// validateQueryTimeRange validates the query time range, shortening it if it ends in the future.
+// It returns true if there is nothing to query.
+func (s *store) validateQueryTimeRange(userID string, from, through *model.Time) (bool, error) {
+	if *through < *from {
+		return false, chunk.QueryError(fmt.Sprintf("invalid query, through < from (%s < %s)", through, from))
+	}
+
+	maxQueryLength := s.limits.MaxQueryLength(userID)
+	if maxQueryLength > 0 && (*through).Sub(*from) > maxQueryLength {
+		return false, chunk.QueryError(fmt.Sprintf(validation.ErrQueryTooLong, (*through).Sub(*from), maxQueryLength))
+	}
+
+	now := model.Now()
+	if from.After(now) {
+		return true, nil
+	}
+	if through.After(now.Add(5 * time.Minute)) {
+		*through = now
+	}
+
+	return false, nil
+}
+
+// tables returns the names of the index tables of the period overlapping the from/through range.
+func (s *store) tables(from, through model.Time) []string {
+	cfg := s.periodCfg.IndexTables
+	if cfg.Period == 0 {
+		return []string{cfg.Prefix}
+	}
+
+	periodSecs := int64(cfg.Period / time.Second)
+	var tables []string
+	for i := from.Unix() / periodSecs; i <= through.Unix()/periodSecs; i++ {
+		tables = append(tables, cfg.TableFor(model.TimeFromUnix(i*periodSecs)))
+	}
+	return tables
+}
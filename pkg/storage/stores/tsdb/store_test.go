@@ -0,0 +1,145 @@
+package tsdb
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/loki/pkg/chunkenc"
+	"github.com/grafana/loki/pkg/logproto"
+	"github.com/grafana/loki/pkg/storage/chunk"
+	"github.com/grafana/loki/pkg/storage/chunk/cache"
+	"github.com/grafana/loki/pkg/storage/chunk/local"
+	"github.com/grafana/loki/pkg/storage/chunk/objectclient"
+	"github.com/grafana/loki/pkg/storage/stores/shipper"
+)
+
+type fakeLimits struct{}
+
+func (fakeLimits) MaxChunksPerQueryFromStore(string) int { return 0 }
+func (fakeLimits) MaxQueryLength(string) time.Duration   { return 0 }
+
+func newTestChunk(t *testing.T, ls labels.Labels, from time.Time, lines ...string) chunk.Chunk {
+	memChunk := chunkenc.NewMemChunk(chunkenc.EncGZIP, chunkenc.UnorderedHeadBlockFmt, 256*1024, 0)
+	for i, line := range lines {
+		require.NoError(t, memChunk.Append(&logproto.Entry{Timestamp: from.Add(time.Duration(i) * time.Millisecond), Line: line}))
+	}
+	require.NoError(t, memChunk.Close())
+
+	through := from.Add(time.Duration(len(lines)-1) * time.Millisecond)
+	c := chunk.NewChunk(userID, fingerprint(ls), ls, chunkenc.NewFacade(memChunk, 0, 0), model.TimeFromUnixNano(from.UnixNano()), model.TimeFromUnixNano(through.UnixNano()))
+	require.NoError(t, c.Encode())
+	return c
+}
+
+func TestStore(t *testing.T) {
+	tempDir := t.TempDir()
+
+	objectClient, err := local.NewFSObjectClient(local.FSConfig{Directory: filepath.Join(tempDir, "storage")})
+	require.NoError(t, err)
+
+	periodCfg := chunk.PeriodConfig{
+		From:       chunk.DayTime{Time: 0},
+		IndexType:  TSDBType,
+		ObjectType: "filesystem",
+		Schema:     "v11",
+		IndexTables: chunk.PeriodicTableConfig{
+			Prefix: "index_",
+			Period: 24 * time.Hour,
+		},
+		RowShards: 16,
+	}
+	schemaCfg := chunk.SchemaConfig{Configs: []chunk.PeriodConfig{periodCfg}}
+	chunksClient := objectclient.NewClient(objectClient, objectclient.Base64Encoder, schemaCfg)
+
+	storeCfg := chunk.StoreConfig{}
+	storeCfg.ChunkCacheConfig.AsyncCacheWriteBackConcurrency = 1
+	storeCfg.ChunkCacheConfig.AsyncCacheWriteBackBufferSize = 10
+
+	shipperCfg := Config{
+		ActiveIndexDirectory:     filepath.Join(tempDir, "active"),
+		CacheLocation:            filepath.Join(tempDir, "cache"),
+		CacheTTL:                 time.Hour,
+		ResyncInterval:           time.Hour,
+		IngesterName:             "ingester",
+		Mode:                     shipper.ModeReadWrite,
+		IngesterHeadRetainPeriod: time.Hour,
+	}
+
+	newStore := func(mode int) chunk.Store {
+		cfg := shipperCfg
+		cfg.Mode = mode
+		indexShipper, err := NewShipper(cfg, objectClient, prometheus.NewRegistry())
+		require.NoError(t, err)
+
+		var writer IndexWriter
+		if mode != shipper.ModeReadOnly {
+			writer = indexShipper
+		}
+		s, err := NewStore(storeCfg, schemaCfg, periodCfg, indexShipper, writer, chunksClient, fakeLimits{}, cache.NewNoopCache())
+		require.NoError(t, err)
+		return s
+	}
+
+	now := time.Now().Truncate(time.Hour)
+	foo := labels.FromStrings("__name__", "logs", "app", "foo", "env", "dev")
+	bar := labels.FromStrings("__name__", "logs", "app", "bar", "env", "prod")
+	fooChunk := newTestChunk(t, foo, now.Add(-2*time.Hour), "foo 1", "foo 2")
+	barChunk := newTestChunk(t, bar, now.Add(-time.Hour), "bar 1")
+
+	ctx := context.Background()
+	from, through := model.TimeFromUnixNano(now.Add(-3*time.Hour).UnixNano()), model.TimeFromUnixNano(now.UnixNano())
+
+	check := func(t *testing.T, s chunk.Store) {
+		chks, err := s.Get(ctx, userID, from, through, labels.MustNewMatcher(labels.MatchEqual, "app", "foo"))
+		require.NoError(t, err)
+		require.Len(t, chks, 1)
+		require.Equal(t, schemaCfg.ExternalKey(fooChunk), schemaCfg.ExternalKey(chks[0]))
+		require.Equal(t, foo, chks[0].Metric)
+
+		chks, err = s.Get(ctx, userID, from, through, labels.MustNewMatcher(labels.MatchRegexp, "env", ".+"))
+		require.NoError(t, err)
+		require.Len(t, chks, 2)
+
+		// chunks outside of the query range are not returned.
+		chks, err = s.Get(ctx, userID, model.TimeFromUnixNano(now.Add(-90*time.Minute).UnixNano()), through, labels.MustNewMatcher(labels.MatchRegexp, "env", ".+"))
+		require.NoError(t, err)
+		require.Len(t, chks, 1)
+		require.Equal(t, schemaCfg.ExternalKey(barChunk), schemaCfg.ExternalKey(chks[0]))
+
+		names, err := s.LabelNamesForMetricName(ctx, userID, from, through, "logs")
+		require.NoError(t, err)
+		require.Equal(t, []string{"app", "env"}, names)
+
+		values, err := s.LabelValuesForMetricName(ctx, userID, from, through, "logs", "app")
+		require.NoError(t, err)
+		require.Equal(t, []string{"bar", "foo"}, values)
+
+		values, err = s.LabelValuesForMetricName(ctx, userID, from, through, "logs", "app", labels.MustNewMatcher(labels.MatchEqual, "env", "prod"))
+		require.NoError(t, err)
+		require.Equal(t, []string{"bar"}, values)
+	}
+
+	// chunks are served from the heads of the ingester.
+	ingesterStore := newStore(shipper.ModeReadWrite)
+	require.NoError(t, ingesterStore.Put(ctx, []chunk.Chunk{fooChunk, barChunk}))
+	t.Run("heads", func(t *testing.T) {
+		check(t, ingesterStore)
+	})
+
+	// stopping the ingester uploads the index built from its heads.
+	ingesterStore.Stop()
+
+	querierStore := newStore(shipper.ModeReadOnly)
+	defer querierStore.Stop()
+	t.Run("downloaded index", func(t *testing.T) {
+		check(t, querierStore)
+	})
+	require.Equal(t, errReadOnly, querierStore.Put(ctx, []chunk.Chunk{fooChunk}))
+}
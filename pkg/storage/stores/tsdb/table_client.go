@@ -0,0 +1,62 @@
+package tsdb
+
+import (
+	"context"
+
+	"github.com/grafana/loki/pkg/storage/chunk"
+	"github.com/grafana/loki/pkg/storage/stores/shipper/storage"
+)
+
+// tableClient lets the table manager delete tables of TSDB index files, which unlike boltdb-shipper are
+// stored per tenant within the tables.
+type tableClient struct {
+	indexStorageClient storage.Client
+}
+
+func NewTableClient(objectClient chunk.ObjectClient, storageKeyPrefix string) chunk.TableClient {
+	return &tableClient{storage.NewIndexStorageClient(objectClient, storageKeyPrefix)}
+}
+
+func (t *tableClient) ListTables(ctx context.Context) ([]string, error) {
+	return t.indexStorageClient.ListTables(ctx)
+}
+
+func (t *tableClient) CreateTable(ctx context.Context, desc chunk.TableDesc) error {
+	return nil
+}
+
+func (t *tableClient) Stop() {
+	t.indexStorageClient.Stop()
+}
+
+func (t *tableClient) DeleteTable(ctx context.Context, tableName string) error {
+	_, users, err := t.indexStorageClient.ListFiles(ctx, tableName)
+	if err != nil {
+		return err
+	}
+
+	for _, userID := range users {
+		files, err := t.indexStorageClient.ListUserFiles(ctx, tableName, userID)
+		if err != nil {
+			return err
+		}
+
+		for _, file := range files {
+			if err := t.indexStorageClient.DeleteUserFile(ctx, tableName, userID, file.Name); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (t *tableClient) DescribeTable(ctx context.Context, name string) (desc chunk.TableDesc, isActive bool, err error) {
+	return chunk.TableDesc{
+		Name: name,
+	}, true, nil
+}
+
+func (t *tableClient) UpdateTable(ctx context.Context, current, expected chunk.TableDesc) error {
+	return nil
+}
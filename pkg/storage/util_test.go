@@ -173,7 +173,7 @@ func (m *mockChunkStore) LabelValuesForMetricName(ctx context.Context, userID st
 	return nil, nil
 }
 
-func (m *mockChunkStore) LabelNamesForMetricName(ctx context.Context, userID string, from, through model.Time, metricName string) ([]string, error) {
+func (m *mockChunkStore) LabelNamesForMetricName(ctx context.Context, userID string, from, through model.Time, metricName string, matchers ...*labels.Matcher) ([]string, error) {
 	return nil, nil
 }
 
@@ -49,6 +49,8 @@ var queryTests = []struct {
 						"chunksDownloadTime": 0,
 						"totalChunksRef": 0,
 						"totalChunksDownloaded": 0,
+						"totalBloomFiltersDownloaded": 0,
+						"totalChunksSkippedByBloom": 0,
 						"chunk" :{
 							"compressedBytes": 0,
 							"decompressedBytes": 0,
@@ -68,6 +70,8 @@ var queryTests = []struct {
 						"chunksDownloadTime": 0,
 						"totalChunksRef": 0,
 						"totalChunksDownloaded": 0,
+						"totalBloomFiltersDownloaded": 0,
+						"totalChunksSkippedByBloom": 0,
 						"chunk" :{
 							"compressedBytes": 0,
 							"decompressedBytes": 0,
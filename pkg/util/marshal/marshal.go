@@ -86,3 +86,25 @@ type seriesResponseAdapter struct {
 	Status string              `json:"status"`
 	Data   []map[string]string `json:"data"`
 }
+
+// WritePatternsResponseJSON marshals a logproto.QueryPatternsResponse to v1 loghttp JSON and then
+// writes it to the provided io.Writer.
+func WritePatternsResponseJSON(r logproto.QueryPatternsResponse, w io.Writer) error {
+	v1Response := loghttp.PatternsResponse{
+		Status: "success",
+		Data:   make([]loghttp.PatternSeries, 0, len(r.Series)),
+	}
+
+	for _, series := range r.Series {
+		samples := make([]loghttp.PatternSample, 0, len(series.Samples))
+		for _, s := range series.Samples {
+			samples = append(samples, loghttp.PatternSample{Timestamp: s.Timestamp / 1e3, Count: s.Value})
+		}
+		v1Response.Data = append(v1Response.Data, loghttp.PatternSeries{
+			Pattern: series.Pattern,
+			Samples: samples,
+		})
+	}
+
+	return jsoniter.NewEncoder(w).Encode(v1Response)
+}
@@ -55,6 +55,8 @@ var queryTests = []struct {
 							"chunksDownloadTime": 0,
 							"totalChunksRef": 0,
 							"totalChunksDownloaded": 0,
+							"totalBloomFiltersDownloaded": 0,
+							"totalChunksSkippedByBloom": 0,
 							"chunk" :{
 								"compressedBytes": 0,
 								"decompressedBytes": 0,
@@ -74,6 +76,8 @@ var queryTests = []struct {
 							"chunksDownloadTime": 0,
 							"totalChunksRef": 0,
 							"totalChunksDownloaded": 0,
+							"totalBloomFiltersDownloaded": 0,
+							"totalChunksSkippedByBloom": 0,
 							"chunk" :{
 								"compressedBytes": 0,
 								"decompressedBytes": 0,
@@ -163,6 +167,8 @@ var queryTests = []struct {
 						"chunksDownloadTime": 0,
 						"totalChunksRef": 0,
 						"totalChunksDownloaded": 0,
+						"totalBloomFiltersDownloaded": 0,
+						"totalChunksSkippedByBloom": 0,
 						"chunk" :{
 							"compressedBytes": 0,
 							"decompressedBytes": 0,
@@ -182,6 +188,8 @@ var queryTests = []struct {
 						"chunksDownloadTime": 0,
 						"totalChunksRef": 0,
 						"totalChunksDownloaded": 0,
+						"totalBloomFiltersDownloaded": 0,
+						"totalChunksSkippedByBloom": 0,
 						"chunk" :{
 							"compressedBytes": 0,
 							"decompressedBytes": 0,
@@ -288,6 +296,8 @@ var queryTests = []struct {
 						"chunksDownloadTime": 0,
 						"totalChunksRef": 0,
 						"totalChunksDownloaded": 0,
+						"totalBloomFiltersDownloaded": 0,
+						"totalChunksSkippedByBloom": 0,
 						"chunk" :{
 							"compressedBytes": 0,
 							"decompressedBytes": 0,
@@ -307,6 +317,8 @@ var queryTests = []struct {
 						"chunksDownloadTime": 0,
 						"totalChunksRef": 0,
 						"totalChunksDownloaded": 0,
+						"totalBloomFiltersDownloaded": 0,
+						"totalChunksSkippedByBloom": 0,
 						"chunk" :{
 							"compressedBytes": 0,
 							"decompressedBytes": 0,
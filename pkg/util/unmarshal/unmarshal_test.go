@@ -7,6 +7,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/prometheus/prometheus/model/labels"
 	"github.com/stretchr/testify/require"
 
 	"github.com/grafana/loki/pkg/loghttp"
@@ -45,6 +46,37 @@ var pushTests = []struct {
 			]
 		}`,
 	},
+	{
+		[]logproto.Stream{
+			{
+				Entries: []logproto.Entry{
+					{
+						Timestamp:          time.Unix(0, 123456789012345),
+						Line:               "super line",
+						StructuredMetadata: labels.Labels{{Name: "pod_uid", Value: "7f3a"}, {Name: "trace_id", Value: "abc"}},
+					},
+					{
+						Timestamp: time.Unix(0, 123456789012346),
+						Line:      "super line 2",
+					},
+				},
+				Labels: `{test="test"}`,
+			},
+		},
+		`{
+			"streams": [
+				{
+					"stream": {
+						"test": "test"
+					},
+					"values":[
+						[ "123456789012345", "super line", { "trace_id": "abc", "pod_uid": "7f3a" } ],
+						[ "123456789012346", "super line 2" ]
+					]
+				}
+			]
+		}`,
+	},
 }
 
 func Test_DecodePushRequest(t *testing.T) {
@@ -59,12 +59,17 @@ type Limits struct {
 	MaxLineSize            flagext.ByteSize `yaml:"max_line_size" json:"max_line_size"`
 	MaxLineSizeTruncate    bool             `yaml:"max_line_size_truncate" json:"max_line_size_truncate"`
 
+	AllowStructuredMetadata bool `yaml:"allow_structured_metadata" json:"allow_structured_metadata"`
+
 	// Ingester enforced limits.
 	MaxLocalStreamsPerUser  int              `yaml:"max_streams_per_user" json:"max_streams_per_user"`
 	MaxGlobalStreamsPerUser int              `yaml:"max_global_streams_per_user" json:"max_global_streams_per_user"`
 	UnorderedWrites         bool             `yaml:"unordered_writes" json:"unordered_writes"`
 	PerStreamRateLimit      flagext.ByteSize `yaml:"per_stream_rate_limit" json:"per_stream_rate_limit"`
 	PerStreamRateLimitBurst flagext.ByteSize `yaml:"per_stream_rate_limit_burst" json:"per_stream_rate_limit_burst"`
+	MaxIngesterQueryBytes   flagext.ByteSize `yaml:"max_ingester_query_bytes" json:"max_ingester_query_bytes"`
+	MaxIngesterTailBytes    flagext.ByteSize `yaml:"max_ingester_tail_bytes_per_second" json:"max_ingester_tail_bytes_per_second"`
+	MaxPatternStreams       int              `yaml:"max_pattern_streams_per_user" json:"max_pattern_streams_per_user"`
 
 	// Querier enforced limits.
 	MaxChunksPerQuery          int            `yaml:"max_chunks_per_query" json:"max_chunks_per_query"`
@@ -111,6 +116,9 @@ type Limits struct {
 	RetentionPeriod model.Duration    `yaml:"retention_period" json:"retention_period"`
 	StreamRetention []StreamRetention `yaml:"retention_stream,omitempty" json:"retention_stream,omitempty"`
 
+	// Per tenant age of the chunks moved to the cold object store.
+	ColdTierAfter model.Duration `yaml:"cold_tier_after" json:"cold_tier_after"`
+
 	// Config for overrides, convenient if it goes here.
 	PerTenantOverrideConfig string         `yaml:"per_tenant_override_config" json:"per_tenant_override_config"`
 	PerTenantOverridePeriod model.Duration `yaml:"per_tenant_override_period" json:"per_tenant_override_period"`
@@ -130,6 +138,7 @@ func (l *Limits) RegisterFlags(f *flag.FlagSet) {
 	f.Float64Var(&l.IngestionBurstSizeMB, "distributor.ingestion-burst-size-mb", 6, "Per-user allowed ingestion burst size (in sample size). Units in MB.")
 	f.Var(&l.MaxLineSize, "distributor.max-line-size", "maximum line length allowed, i.e. 100mb. Default (0) means unlimited.")
 	f.BoolVar(&l.MaxLineSizeTruncate, "distributor.max-line-size-truncate", false, "Whether to truncate lines that exceed max_line_size")
+	f.BoolVar(&l.AllowStructuredMetadata, "validation.allow-structured-metadata", false, "Allow the entries to carry structured metadata, key/value pairs stored next to each line in the V4 chunk format without being indexed. Requires unordered writes. Entries with structured metadata are rejected when disabled.")
 	f.IntVar(&l.MaxLabelNameLength, "validation.max-length-label-name", 1024, "Maximum length accepted for label names")
 	f.IntVar(&l.MaxLabelValueLength, "validation.max-length-label-value", 2048, "Maximum length accepted for label value. This setting also applies to the metric name")
 	f.IntVar(&l.MaxLabelNamesPerSeries, "validation.max-label-names-per-series", 30, "Maximum number of label names per series.")
@@ -150,6 +159,10 @@ func (l *Limits) RegisterFlags(f *flag.FlagSet) {
 	f.Var(&l.PerStreamRateLimit, "ingester.per-stream-rate-limit", "Maximum byte rate per second per stream, also expressible in human readable forms (1MB, 256KB, etc).")
 	_ = l.PerStreamRateLimitBurst.Set(strconv.Itoa(defaultPerStreamBurstLimit))
 	f.Var(&l.PerStreamRateLimitBurst, "ingester.per-stream-rate-limit-burst", "Maximum burst bytes per stream, also expressible in human readable forms (1MB, 256KB, etc).")
+	f.Var(&l.MaxIngesterQueryBytes, "ingester.max-query-bytes", "Maximum number of bytes a single query may process in an ingester, also expressible in human readable forms (1MB, 256KB, etc). Queries exceeding it are cancelled. 0 to disable.")
+	f.Var(&l.MaxIngesterTailBytes, "ingester.max-tail-bytes-per-second", "Maximum number of bytes each tail request may process per second in an ingester, also expressible in human readable forms (1MB, 256KB, etc). The entries pushed once it is reached are dropped until the next second. 0 to disable.")
+
+	f.IntVar(&l.MaxPatternStreams, "ingester.max-pattern-streams-per-user", 1000, "Maximum number of streams of a tenant detecting patterns in each ingester, the streams created above it being left without patterns. 0 to disable.")
 
 	f.IntVar(&l.MaxChunksPerQuery, "store.query-chunk-limit", 2e6, "Maximum number of chunks that can be fetched in a single query.")
 
@@ -181,6 +194,7 @@ func (l *Limits) RegisterFlags(f *flag.FlagSet) {
 	f.StringVar(&l.PerTenantOverrideConfig, "limits.per-user-override-config", "", "File name of per-user overrides.")
 	_ = l.RetentionPeriod.Set("744h")
 	f.Var(&l.RetentionPeriod, "store.retention", "How long before chunks will be deleted from the store. (requires compactor retention enabled).")
+	f.Var(&l.ColdTierAfter, "store.cold-tier-after", "How long before chunks are moved to the cold object store. 0 to keep them in the primary object store. (requires the cold tier to be configured and compactor chunk tiering enabled).")
 
 	_ = l.PerTenantOverridePeriod.Set("10s")
 	f.Var(&l.PerTenantOverridePeriod, "limits.per-user-override-period", "Period with this to reload the overrides.")
@@ -400,6 +414,11 @@ func (o *Overrides) MaxLineSizeTruncate(userID string) bool {
 	return o.getOverridesForUser(userID).MaxLineSizeTruncate
 }
 
+// AllowStructuredMetadata returns whether the entries of the user can carry structured metadata.
+func (o *Overrides) AllowStructuredMetadata(userID string) bool {
+	return o.getOverridesForUser(userID).AllowStructuredMetadata
+}
+
 // MaxEntriesLimitPerQuery returns the limit to number of entries the querier should return per query.
 func (o *Overrides) MaxEntriesLimitPerQuery(userID string) int {
 	return o.getOverridesForUser(userID).MaxEntriesLimitPerQuery
@@ -505,6 +524,11 @@ func (o *Overrides) RetentionPeriod(userID string) time.Duration {
 	return time.Duration(o.getOverridesForUser(userID).RetentionPeriod)
 }
 
+// ColdTierAfter returns the age after which the chunks of a given user are moved to the cold object store.
+func (o *Overrides) ColdTierAfter(userID string) time.Duration {
+	return time.Duration(o.getOverridesForUser(userID).ColdTierAfter)
+}
+
 // StreamRetention returns the retention period for a given user.
 func (o *Overrides) StreamRetention(userID string) []StreamRetention {
 	return o.getOverridesForUser(userID).StreamRetention
@@ -527,6 +551,21 @@ func (o *Overrides) PerStreamRateLimit(userID string) RateLimit {
 	}
 }
 
+// MaxIngesterQueryBytes returns the maximum number of bytes a query may process in an ingester.
+func (o *Overrides) MaxIngesterQueryBytes(userID string) int {
+	return o.getOverridesForUser(userID).MaxIngesterQueryBytes.Val()
+}
+
+// MaxIngesterTailBytes returns the maximum number of bytes a tail request may process per second in an ingester.
+func (o *Overrides) MaxIngesterTailBytes(userID string) int {
+	return o.getOverridesForUser(userID).MaxIngesterTailBytes.Val()
+}
+
+// MaxPatternStreams returns the maximum number of streams of the user detecting patterns in an ingester.
+func (o *Overrides) MaxPatternStreams(userID string) int {
+	return o.getOverridesForUser(userID).MaxPatternStreams
+}
+
 func (o *Overrides) getOverridesForUser(userID string) *Limits {
 	if o.tenantLimits != nil {
 		l := o.tenantLimits.TenantLimits(userID)
@@ -50,6 +50,12 @@ const (
 	// DuplicateLabelNames is a reason for discarding a log line which has duplicate label names
 	DuplicateLabelNames         = "duplicate_label_names"
 	DuplicateLabelNamesErrorMsg = "stream '%s' has duplicate label name: '%s'"
+	// DisallowedStructuredMetadata is a reason for discarding a log line which has structured metadata while the feature is disabled
+	DisallowedStructuredMetadata         = "disallowed_structured_metadata"
+	DisallowedStructuredMetadataErrorMsg = "stream '%s' includes structured metadata, but this feature is disallowed. Please see `limits_config.allow_structured_metadata` or contact your Loki administrator to enable it."
+	// InvalidStructuredMetadata is a reason for discarding a log line which has invalid structured metadata
+	InvalidStructuredMetadata         = "invalid_structured_metadata"
+	InvalidStructuredMetadataErrorMsg = "stream '%s' has invalid structured metadata name: '%s'"
 )
 
 type ErrStreamRateLimit struct {